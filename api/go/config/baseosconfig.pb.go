@@ -23,6 +23,53 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type BaseOSDeltaFormat int32
+
+const (
+	BaseOSDeltaFormat_BASE_OS_DELTA_FORMAT_UNSPECIFIED BaseOSDeltaFormat = 0
+	// bsdiff 4.x patch (BSDIFF40 header, bzip2 compressed blocks)
+	BaseOSDeltaFormat_BASE_OS_DELTA_FORMAT_BSDIFF BaseOSDeltaFormat = 1
+)
+
+// Enum value maps for BaseOSDeltaFormat.
+var (
+	BaseOSDeltaFormat_name = map[int32]string{
+		0: "BASE_OS_DELTA_FORMAT_UNSPECIFIED",
+		1: "BASE_OS_DELTA_FORMAT_BSDIFF",
+	}
+	BaseOSDeltaFormat_value = map[string]int32{
+		"BASE_OS_DELTA_FORMAT_UNSPECIFIED": 0,
+		"BASE_OS_DELTA_FORMAT_BSDIFF":      1,
+	}
+)
+
+func (x BaseOSDeltaFormat) Enum() *BaseOSDeltaFormat {
+	p := new(BaseOSDeltaFormat)
+	*p = x
+	return p
+}
+
+func (x BaseOSDeltaFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BaseOSDeltaFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_config_baseosconfig_proto_enumTypes[0].Descriptor()
+}
+
+func (BaseOSDeltaFormat) Type() protoreflect.EnumType {
+	return &file_config_baseosconfig_proto_enumTypes[0]
+}
+
+func (x BaseOSDeltaFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BaseOSDeltaFormat.Descriptor instead.
+func (BaseOSDeltaFormat) EnumDescriptor() ([]byte, []int) {
+	return file_config_baseosconfig_proto_rawDescGZIP(), []int{0}
+}
+
 // OS version key and value pair
 type OSKeyTags struct {
 	state         protoimpl.MessageState
@@ -114,6 +161,15 @@ type BaseOSConfig struct {
 	Activate      bool     `protobuf:"varint,4,opt,name=activate,proto3" json:"activate,omitempty"`
 	BaseOSVersion string   `protobuf:"bytes,10,opt,name=baseOSVersion,proto3" json:"baseOSVersion,omitempty"` // deprecated 11; OSVerDetails baseOSDetails
 	VolumeID      string   `protobuf:"bytes,12,opt,name=volumeID,proto3" json:"volumeID,omitempty"`           // UUID for Volume with BaseOS image
+	// Binary patches which reconstruct the image of this BaseOS from
+	// the image of an older release. If one of them applies to the image
+	// in the current partition, EVE downloads the patch instead of the full
+	// image. EVE falls back to the full image (drives) on any error.
+	Deltas []*BaseOSDelta `protobuf:"bytes,13,rep,name=deltas,proto3" json:"deltas,omitempty"`
+	// Checks which have to pass in the testing window after the device
+	// boots this BaseOS, in addition to the connectivity to the controller.
+	// If any of them fails, EVE falls back to the previous BaseOS.
+	HealthGates *BaseOSHealthGates `protobuf:"bytes,14,opt,name=health_gates,json=healthGates,proto3" json:"health_gates,omitempty"`
 }
 
 func (x *BaseOSConfig) Reset() {
@@ -183,6 +239,318 @@ func (x *BaseOSConfig) GetVolumeID() string {
 	return ""
 }
 
+func (x *BaseOSConfig) GetDeltas() []*BaseOSDelta {
+	if x != nil {
+		return x.Deltas
+	}
+	return nil
+}
+
+func (x *BaseOSConfig) GetHealthGates() *BaseOSHealthGates {
+	if x != nil {
+		return x.HealthGates
+	}
+	return nil
+}
+
+type BaseOSDelta struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Patch artifact
+	Patch  *Drive            `protobuf:"bytes,1,opt,name=patch,proto3" json:"patch,omitempty"`
+	Format BaseOSDeltaFormat `protobuf:"varint,2,opt,name=format,proto3,enum=org.lfedge.eve.config.BaseOSDeltaFormat" json:"format,omitempty"`
+	// Release the patch applies to. It has to match the version
+	// of the image in the current partition.
+	BaseVersion string `protobuf:"bytes,3,opt,name=base_version,json=baseVersion,proto3" json:"base_version,omitempty"`
+	// Size and sha256 of the image of base_version as written
+	// to the partition.
+	BaseSizeBytes uint64 `protobuf:"varint,4,opt,name=base_size_bytes,json=baseSizeBytes,proto3" json:"base_size_bytes,omitempty"`
+	BaseSha256    string `protobuf:"bytes,5,opt,name=base_sha256,json=baseSha256,proto3" json:"base_sha256,omitempty"`
+	// sha256 of the reconstructed image.
+	TargetSha256 string `protobuf:"bytes,6,opt,name=target_sha256,json=targetSha256,proto3" json:"target_sha256,omitempty"`
+}
+
+func (x *BaseOSDelta) Reset() {
+	*x = BaseOSDelta{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_config_baseosconfig_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BaseOSDelta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BaseOSDelta) ProtoMessage() {}
+
+func (x *BaseOSDelta) ProtoReflect() protoreflect.Message {
+	mi := &file_config_baseosconfig_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BaseOSDelta.ProtoReflect.Descriptor instead.
+func (*BaseOSDelta) Descriptor() ([]byte, []int) {
+	return file_config_baseosconfig_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BaseOSDelta) GetPatch() *Drive {
+	if x != nil {
+		return x.Patch
+	}
+	return nil
+}
+
+func (x *BaseOSDelta) GetFormat() BaseOSDeltaFormat {
+	if x != nil {
+		return x.Format
+	}
+	return BaseOSDeltaFormat_BASE_OS_DELTA_FORMAT_UNSPECIFIED
+}
+
+func (x *BaseOSDelta) GetBaseVersion() string {
+	if x != nil {
+		return x.BaseVersion
+	}
+	return ""
+}
+
+func (x *BaseOSDelta) GetBaseSizeBytes() uint64 {
+	if x != nil {
+		return x.BaseSizeBytes
+	}
+	return 0
+}
+
+func (x *BaseOSDelta) GetBaseSha256() string {
+	if x != nil {
+		return x.BaseSha256
+	}
+	return ""
+}
+
+func (x *BaseOSDelta) GetTargetSha256() string {
+	if x != nil {
+		return x.TargetSha256
+	}
+	return ""
+}
+
+type BaseOSHealthGates struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// App instances which have to be running
+	AppInstanceUuids []string `protobuf:"bytes,1,rep,name=app_instance_uuids,json=appInstanceUuids,proto3" json:"app_instance_uuids,omitempty"`
+	// Network instances which have to be activated without errors
+	NetworkInstanceUuids []string             `protobuf:"bytes,2,rep,name=network_instance_uuids,json=networkInstanceUuids,proto3" json:"network_instance_uuids,omitempty"`
+	HttpProbes           []*BaseOSHttpProbe   `protobuf:"bytes,3,rep,name=http_probes,json=httpProbes,proto3" json:"http_probes,omitempty"`
+	Scripts              []*BaseOSScriptCheck `protobuf:"bytes,4,rep,name=scripts,proto3" json:"scripts,omitempty"`
+}
+
+func (x *BaseOSHealthGates) Reset() {
+	*x = BaseOSHealthGates{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_config_baseosconfig_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BaseOSHealthGates) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BaseOSHealthGates) ProtoMessage() {}
+
+func (x *BaseOSHealthGates) ProtoReflect() protoreflect.Message {
+	mi := &file_config_baseosconfig_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BaseOSHealthGates.ProtoReflect.Descriptor instead.
+func (*BaseOSHealthGates) Descriptor() ([]byte, []int) {
+	return file_config_baseosconfig_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BaseOSHealthGates) GetAppInstanceUuids() []string {
+	if x != nil {
+		return x.AppInstanceUuids
+	}
+	return nil
+}
+
+func (x *BaseOSHealthGates) GetNetworkInstanceUuids() []string {
+	if x != nil {
+		return x.NetworkInstanceUuids
+	}
+	return nil
+}
+
+func (x *BaseOSHealthGates) GetHttpProbes() []*BaseOSHttpProbe {
+	if x != nil {
+		return x.HttpProbes
+	}
+	return nil
+}
+
+func (x *BaseOSHealthGates) GetScripts() []*BaseOSScriptCheck {
+	if x != nil {
+		return x.Scripts
+	}
+	return nil
+}
+
+// HTTP(S) GET request sent from the network instance
+type BaseOSHttpProbe struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NetworkInstanceUuid string `protobuf:"bytes,1,opt,name=network_instance_uuid,json=networkInstanceUuid,proto3" json:"network_instance_uuid,omitempty"`
+	Url                 string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	// Expected HTTP status code; any 2xx if not set
+	ExpectedStatus uint32 `protobuf:"varint,3,opt,name=expected_status,json=expectedStatus,proto3" json:"expected_status,omitempty"`
+}
+
+func (x *BaseOSHttpProbe) Reset() {
+	*x = BaseOSHttpProbe{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_config_baseosconfig_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BaseOSHttpProbe) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BaseOSHttpProbe) ProtoMessage() {}
+
+func (x *BaseOSHttpProbe) ProtoReflect() protoreflect.Message {
+	mi := &file_config_baseosconfig_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BaseOSHttpProbe.ProtoReflect.Descriptor instead.
+func (*BaseOSHttpProbe) Descriptor() ([]byte, []int) {
+	return file_config_baseosconfig_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BaseOSHttpProbe) GetNetworkInstanceUuid() string {
+	if x != nil {
+		return x.NetworkInstanceUuid
+	}
+	return ""
+}
+
+func (x *BaseOSHttpProbe) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *BaseOSHttpProbe) GetExpectedStatus() uint32 {
+	if x != nil {
+		return x.ExpectedStatus
+	}
+	return 0
+}
+
+// Command executed in the container of a running app instance
+type BaseOSScriptCheck struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AppInstanceUuid string `protobuf:"bytes,1,opt,name=app_instance_uuid,json=appInstanceUuid,proto3" json:"app_instance_uuid,omitempty"`
+	// Command and its arguments; it has to exit with status 0
+	Command []string `protobuf:"bytes,2,rep,name=command,proto3" json:"command,omitempty"`
+	// Time in seconds the command has to complete in; 30 if not set.
+	// It is bounded by the testing time of the new version.
+	Timeout uint32 `protobuf:"varint,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+}
+
+func (x *BaseOSScriptCheck) Reset() {
+	*x = BaseOSScriptCheck{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_config_baseosconfig_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BaseOSScriptCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BaseOSScriptCheck) ProtoMessage() {}
+
+func (x *BaseOSScriptCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_config_baseosconfig_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BaseOSScriptCheck.ProtoReflect.Descriptor instead.
+func (*BaseOSScriptCheck) Descriptor() ([]byte, []int) {
+	return file_config_baseosconfig_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BaseOSScriptCheck) GetAppInstanceUuid() string {
+	if x != nil {
+		return x.AppInstanceUuid
+	}
+	return ""
+}
+
+func (x *BaseOSScriptCheck) GetCommand() []string {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *BaseOSScriptCheck) GetTimeout() uint32 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
 type BaseOS struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -204,7 +572,7 @@ type BaseOS struct {
 func (x *BaseOS) Reset() {
 	*x = BaseOS{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_config_baseosconfig_proto_msgTypes[3]
+		mi := &file_config_baseosconfig_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -217,7 +585,7 @@ func (x *BaseOS) String() string {
 func (*BaseOS) ProtoMessage() {}
 
 func (x *BaseOS) ProtoReflect() protoreflect.Message {
-	mi := &file_config_baseosconfig_proto_msgTypes[3]
+	mi := &file_config_baseosconfig_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -230,7 +598,7 @@ func (x *BaseOS) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BaseOS.ProtoReflect.Descriptor instead.
 func (*BaseOS) Descriptor() ([]byte, []int) {
-	return file_config_baseosconfig_proto_rawDescGZIP(), []int{3}
+	return file_config_baseosconfig_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *BaseOS) GetContentTreeUuid() string {
@@ -257,7 +625,7 @@ var file_config_baseosconfig_proto_rawDesc = []byte{
 	0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x14, 0x63, 0x6f, 0x6e, 0x66,
 	0x69, 0x67, 0x2f, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
 	0x22, 0x0b, 0x0a, 0x09, 0x4f, 0x53, 0x4b, 0x65, 0x79, 0x54, 0x61, 0x67, 0x73, 0x22, 0x0e, 0x0a,
-	0x0c, 0x4f, 0x53, 0x56, 0x65, 0x72, 0x44, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x22, 0xf1, 0x01,
+	0x0c, 0x4f, 0x53, 0x56, 0x65, 0x72, 0x44, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x22, 0xfa, 0x02,
 	0x0a, 0x0c, 0x42, 0x61, 0x73, 0x65, 0x4f, 0x53, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x4d,
 	0x0a, 0x0e, 0x75, 0x75, 0x69, 0x64, 0x61, 0x6e, 0x64, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
 	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65,
@@ -273,19 +641,83 @@ var file_config_baseosconfig_proto_rawDesc = []byte{
 	0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x62, 0x61, 0x73, 0x65, 0x4f, 0x53, 0x56, 0x65,
 	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x49,
 	0x44, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x49,
-	0x44, 0x22, 0x7c, 0x0a, 0x06, 0x42, 0x61, 0x73, 0x65, 0x4f, 0x53, 0x12, 0x2a, 0x0a, 0x11, 0x63,
-	0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x75, 0x75, 0x69, 0x64,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54,
-	0x72, 0x65, 0x65, 0x55, 0x75, 0x69, 0x64, 0x12, 0x46, 0x0a, 0x0c, 0x72, 0x65, 0x74, 0x72, 0x79,
-	0x5f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e,
-	0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63,
-	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4f, 0x70, 0x73, 0x43,
-	0x6d, 0x64, 0x52, 0x0b, 0x72, 0x65, 0x74, 0x72, 0x79, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x42,
-	0x3d, 0x0a, 0x15, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76,
-	0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5a, 0x24, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
-	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x66, 0x2d, 0x65, 0x64, 0x67, 0x65, 0x2f, 0x65, 0x76, 0x65,
-	0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x6f, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x44, 0x12, 0x3a, 0x0a, 0x06, 0x64, 0x65, 0x6c, 0x74, 0x61, 0x73, 0x18, 0x0d, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x22, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65,
+	0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x42, 0x61, 0x73, 0x65, 0x4f, 0x53,
+	0x44, 0x65, 0x6c, 0x74, 0x61, 0x52, 0x06, 0x64, 0x65, 0x6c, 0x74, 0x61, 0x73, 0x12, 0x4b, 0x0a,
+	0x0c, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x5f, 0x67, 0x61, 0x74, 0x65, 0x73, 0x18, 0x0e, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65,
+	0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x42, 0x61, 0x73, 0x65,
+	0x4f, 0x53, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x47, 0x61, 0x74, 0x65, 0x73, 0x52, 0x0b, 0x68,
+	0x65, 0x61, 0x6c, 0x74, 0x68, 0x47, 0x61, 0x74, 0x65, 0x73, 0x22, 0x94, 0x02, 0x0a, 0x0b, 0x42,
+	0x61, 0x73, 0x65, 0x4f, 0x53, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x12, 0x32, 0x0a, 0x05, 0x70, 0x61,
+	0x74, 0x63, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6f, 0x72, 0x67, 0x2e,
+	0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x2e, 0x44, 0x72, 0x69, 0x76, 0x65, 0x52, 0x05, 0x70, 0x61, 0x74, 0x63, 0x68, 0x12, 0x40,
+	0x0a, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x28,
+	0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x42, 0x61, 0x73, 0x65, 0x4f, 0x53, 0x44, 0x65, 0x6c,
+	0x74, 0x61, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x52, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74,
+	0x12, 0x21, 0x0a, 0x0c, 0x62, 0x61, 0x73, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x62, 0x61, 0x73, 0x65, 0x56, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x26, 0x0a, 0x0f, 0x62, 0x61, 0x73, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65,
+	0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x62, 0x61,
+	0x73, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x62,
+	0x61, 0x73, 0x65, 0x5f, 0x73, 0x68, 0x61, 0x32, 0x35, 0x36, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x62, 0x61, 0x73, 0x65, 0x53, 0x68, 0x61, 0x32, 0x35, 0x36, 0x12, 0x23, 0x0a, 0x0d,
+	0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x5f, 0x73, 0x68, 0x61, 0x32, 0x35, 0x36, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0c, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x53, 0x68, 0x61, 0x32, 0x35,
+	0x36, 0x22, 0x84, 0x02, 0x0a, 0x11, 0x42, 0x61, 0x73, 0x65, 0x4f, 0x53, 0x48, 0x65, 0x61, 0x6c,
+	0x74, 0x68, 0x47, 0x61, 0x74, 0x65, 0x73, 0x12, 0x2c, 0x0a, 0x12, 0x61, 0x70, 0x70, 0x5f, 0x69,
+	0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x75, 0x75, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x10, 0x61, 0x70, 0x70, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65,
+	0x55, 0x75, 0x69, 0x64, 0x73, 0x12, 0x34, 0x0a, 0x16, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b,
+	0x5f, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x75, 0x75, 0x69, 0x64, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x14, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x6e,
+	0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x55, 0x75, 0x69, 0x64, 0x73, 0x12, 0x47, 0x0a, 0x0b, 0x68,
+	0x74, 0x74, 0x70, 0x5f, 0x70, 0x72, 0x6f, 0x62, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x26, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76,
+	0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x42, 0x61, 0x73, 0x65, 0x4f, 0x53, 0x48,
+	0x74, 0x74, 0x70, 0x50, 0x72, 0x6f, 0x62, 0x65, 0x52, 0x0a, 0x68, 0x74, 0x74, 0x70, 0x50, 0x72,
+	0x6f, 0x62, 0x65, 0x73, 0x12, 0x42, 0x0a, 0x07, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x73, 0x18,
+	0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64,
+	0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x42, 0x61,
+	0x73, 0x65, 0x4f, 0x53, 0x53, 0x63, 0x72, 0x69, 0x70, 0x74, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52,
+	0x07, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x73, 0x22, 0x80, 0x01, 0x0a, 0x0f, 0x42, 0x61, 0x73,
+	0x65, 0x4f, 0x53, 0x48, 0x74, 0x74, 0x70, 0x50, 0x72, 0x6f, 0x62, 0x65, 0x12, 0x32, 0x0a, 0x15,
+	0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x5f, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65,
+	0x5f, 0x75, 0x75, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x13, 0x6e, 0x65, 0x74,
+	0x77, 0x6f, 0x72, 0x6b, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x55, 0x75, 0x69, 0x64,
+	0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75,
+	0x72, 0x6c, 0x12, 0x27, 0x0a, 0x0f, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x65, 0x78, 0x70,
+	0x65, 0x63, 0x74, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x73, 0x0a, 0x11, 0x42,
+	0x61, 0x73, 0x65, 0x4f, 0x53, 0x53, 0x63, 0x72, 0x69, 0x70, 0x74, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x12, 0x2a, 0x0a, 0x11, 0x61, 0x70, 0x70, 0x5f, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65,
+	0x5f, 0x75, 0x75, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x61, 0x70, 0x70,
+	0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x55, 0x75, 0x69, 0x64, 0x12, 0x18, 0x0a, 0x07,
+	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x63,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75,
+	0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74,
+	0x22, 0x7c, 0x0a, 0x06, 0x42, 0x61, 0x73, 0x65, 0x4f, 0x53, 0x12, 0x2a, 0x0a, 0x11, 0x63, 0x6f,
+	0x6e, 0x74, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x72, 0x65, 0x65, 0x5f, 0x75, 0x75, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x72,
+	0x65, 0x65, 0x55, 0x75, 0x69, 0x64, 0x12, 0x46, 0x0a, 0x0c, 0x72, 0x65, 0x74, 0x72, 0x79, 0x5f,
+	0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x6f,
+	0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4f, 0x70, 0x73, 0x43, 0x6d,
+	0x64, 0x52, 0x0b, 0x72, 0x65, 0x74, 0x72, 0x79, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x2a, 0x5a,
+	0x0a, 0x11, 0x42, 0x61, 0x73, 0x65, 0x4f, 0x53, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x46, 0x6f, 0x72,
+	0x6d, 0x61, 0x74, 0x12, 0x24, 0x0a, 0x20, 0x42, 0x41, 0x53, 0x45, 0x5f, 0x4f, 0x53, 0x5f, 0x44,
+	0x45, 0x4c, 0x54, 0x41, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x55, 0x4e, 0x53, 0x50,
+	0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x1f, 0x0a, 0x1b, 0x42, 0x41, 0x53,
+	0x45, 0x5f, 0x4f, 0x53, 0x5f, 0x44, 0x45, 0x4c, 0x54, 0x41, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41,
+	0x54, 0x5f, 0x42, 0x53, 0x44, 0x49, 0x46, 0x46, 0x10, 0x01, 0x42, 0x3d, 0x0a, 0x15, 0x6f, 0x72,
+	0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x5a, 0x24, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
+	0x6c, 0x66, 0x2d, 0x65, 0x64, 0x67, 0x65, 0x2f, 0x65, 0x76, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f,
+	0x67, 0x6f, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
 }
 
 var (
@@ -300,25 +732,37 @@ func file_config_baseosconfig_proto_rawDescGZIP() []byte {
 	return file_config_baseosconfig_proto_rawDescData
 }
 
-var file_config_baseosconfig_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_config_baseosconfig_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_config_baseosconfig_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_config_baseosconfig_proto_goTypes = []interface{}{
-	(*OSKeyTags)(nil),      // 0: org.lfedge.eve.config.OSKeyTags
-	(*OSVerDetails)(nil),   // 1: org.lfedge.eve.config.OSVerDetails
-	(*BaseOSConfig)(nil),   // 2: org.lfedge.eve.config.BaseOSConfig
-	(*BaseOS)(nil),         // 3: org.lfedge.eve.config.BaseOS
-	(*UUIDandVersion)(nil), // 4: org.lfedge.eve.config.UUIDandVersion
-	(*Drive)(nil),          // 5: org.lfedge.eve.config.Drive
-	(*DeviceOpsCmd)(nil),   // 6: org.lfedge.eve.config.DeviceOpsCmd
+	(BaseOSDeltaFormat)(0),    // 0: org.lfedge.eve.config.BaseOSDeltaFormat
+	(*OSKeyTags)(nil),         // 1: org.lfedge.eve.config.OSKeyTags
+	(*OSVerDetails)(nil),      // 2: org.lfedge.eve.config.OSVerDetails
+	(*BaseOSConfig)(nil),      // 3: org.lfedge.eve.config.BaseOSConfig
+	(*BaseOSDelta)(nil),       // 4: org.lfedge.eve.config.BaseOSDelta
+	(*BaseOSHealthGates)(nil), // 5: org.lfedge.eve.config.BaseOSHealthGates
+	(*BaseOSHttpProbe)(nil),   // 6: org.lfedge.eve.config.BaseOSHttpProbe
+	(*BaseOSScriptCheck)(nil), // 7: org.lfedge.eve.config.BaseOSScriptCheck
+	(*BaseOS)(nil),            // 8: org.lfedge.eve.config.BaseOS
+	(*UUIDandVersion)(nil),    // 9: org.lfedge.eve.config.UUIDandVersion
+	(*Drive)(nil),             // 10: org.lfedge.eve.config.Drive
+	(*DeviceOpsCmd)(nil),      // 11: org.lfedge.eve.config.DeviceOpsCmd
 }
 var file_config_baseosconfig_proto_depIdxs = []int32{
-	4, // 0: org.lfedge.eve.config.BaseOSConfig.uuidandversion:type_name -> org.lfedge.eve.config.UUIDandVersion
-	5, // 1: org.lfedge.eve.config.BaseOSConfig.drives:type_name -> org.lfedge.eve.config.Drive
-	6, // 2: org.lfedge.eve.config.BaseOS.retry_update:type_name -> org.lfedge.eve.config.DeviceOpsCmd
-	3, // [3:3] is the sub-list for method output_type
-	3, // [3:3] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	9,  // 0: org.lfedge.eve.config.BaseOSConfig.uuidandversion:type_name -> org.lfedge.eve.config.UUIDandVersion
+	10, // 1: org.lfedge.eve.config.BaseOSConfig.drives:type_name -> org.lfedge.eve.config.Drive
+	4,  // 2: org.lfedge.eve.config.BaseOSConfig.deltas:type_name -> org.lfedge.eve.config.BaseOSDelta
+	5,  // 3: org.lfedge.eve.config.BaseOSConfig.health_gates:type_name -> org.lfedge.eve.config.BaseOSHealthGates
+	10, // 4: org.lfedge.eve.config.BaseOSDelta.patch:type_name -> org.lfedge.eve.config.Drive
+	0,  // 5: org.lfedge.eve.config.BaseOSDelta.format:type_name -> org.lfedge.eve.config.BaseOSDeltaFormat
+	6,  // 6: org.lfedge.eve.config.BaseOSHealthGates.http_probes:type_name -> org.lfedge.eve.config.BaseOSHttpProbe
+	7,  // 7: org.lfedge.eve.config.BaseOSHealthGates.scripts:type_name -> org.lfedge.eve.config.BaseOSScriptCheck
+	11, // 8: org.lfedge.eve.config.BaseOS.retry_update:type_name -> org.lfedge.eve.config.DeviceOpsCmd
+	9,  // [9:9] is the sub-list for method output_type
+	9,  // [9:9] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_config_baseosconfig_proto_init() }
@@ -366,6 +810,54 @@ func file_config_baseosconfig_proto_init() {
 			}
 		}
 		file_config_baseosconfig_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BaseOSDelta); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_config_baseosconfig_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BaseOSHealthGates); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_config_baseosconfig_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BaseOSHttpProbe); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_config_baseosconfig_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BaseOSScriptCheck); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_config_baseosconfig_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*BaseOS); i {
 			case 0:
 				return &v.state
@@ -383,13 +875,14 @@ func file_config_baseosconfig_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_config_baseosconfig_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   4,
+			NumEnums:      1,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_config_baseosconfig_proto_goTypes,
 		DependencyIndexes: file_config_baseosconfig_proto_depIdxs,
+		EnumInfos:         file_config_baseosconfig_proto_enumTypes,
 		MessageInfos:      file_config_baseosconfig_proto_msgTypes,
 	}.Build()
 	File_config_baseosconfig_proto = out.File
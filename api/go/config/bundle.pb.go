@@ -0,0 +1,287 @@
+// Copyright(c) 2022 Zededa, Inc.
+// All rights reserved.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.26.0
+// 	protoc        v3.18.1
+// source: config/bundle.proto
+
+package config
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// UpdateBundleManifest describes an update bundle which is applied to the
+// device without the controller, from a USB stick or from the local profile
+// server. The manifest is carried as the payload of an AuthContainer signed
+// by the controller signing certificate, the same way as the controller
+// signs the configuration.
+type UpdateBundleManifest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// UUID of the device the bundle is built for
+	DeviceUuid string `protobuf:"bytes,1,opt,name=device_uuid,json=deviceUuid,proto3" json:"device_uuid,omitempty"`
+	// Sequence number of the bundle. EVE applies a bundle only if its
+	// sequence is higher than the sequence of the last applied bundle.
+	Sequence uint64 `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// Device configuration to apply. It refers the base OS and app images
+	// by their sha256 in content trees.
+	Config *EdgeDevConfig `protobuf:"bytes,3,opt,name=config,proto3" json:"config,omitempty"`
+	// Images carried in the bundle
+	Blobs []*BundleBlob `protobuf:"bytes,4,rep,name=blobs,proto3" json:"blobs,omitempty"`
+}
+
+func (x *UpdateBundleManifest) Reset() {
+	*x = UpdateBundleManifest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_config_bundle_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateBundleManifest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateBundleManifest) ProtoMessage() {}
+
+func (x *UpdateBundleManifest) ProtoReflect() protoreflect.Message {
+	mi := &file_config_bundle_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateBundleManifest.ProtoReflect.Descriptor instead.
+func (*UpdateBundleManifest) Descriptor() ([]byte, []int) {
+	return file_config_bundle_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *UpdateBundleManifest) GetDeviceUuid() string {
+	if x != nil {
+		return x.DeviceUuid
+	}
+	return ""
+}
+
+func (x *UpdateBundleManifest) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *UpdateBundleManifest) GetConfig() *EdgeDevConfig {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+func (x *UpdateBundleManifest) GetBlobs() []*BundleBlob {
+	if x != nil {
+		return x.Blobs
+	}
+	return nil
+}
+
+// BundleBlob is an image carried in the bundle, stored as
+// blobs/<sha256> next to the manifest.
+type BundleBlob struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// sha256 of the image, lowercase hex
+	Sha256    string `protobuf:"bytes,1,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	SizeBytes uint64 `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	// Media type of the image, as the Content-Type returned by an OCI
+	// registry. Required for the manifest (or index) of container images.
+	MediaType string `protobuf:"bytes,3,opt,name=media_type,json=mediaType,proto3" json:"media_type,omitempty"`
+}
+
+func (x *BundleBlob) Reset() {
+	*x = BundleBlob{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_config_bundle_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BundleBlob) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BundleBlob) ProtoMessage() {}
+
+func (x *BundleBlob) ProtoReflect() protoreflect.Message {
+	mi := &file_config_bundle_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BundleBlob.ProtoReflect.Descriptor instead.
+func (*BundleBlob) Descriptor() ([]byte, []int) {
+	return file_config_bundle_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BundleBlob) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *BundleBlob) GetSizeBytes() uint64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *BundleBlob) GetMediaType() string {
+	if x != nil {
+		return x.MediaType
+	}
+	return ""
+}
+
+var File_config_bundle_proto protoreflect.FileDescriptor
+
+var file_config_bundle_proto_rawDesc = []byte{
+	0x0a, 0x13, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2f, 0x62, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x15, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67,
+	0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x1a, 0x16, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x2f, 0x64, 0x65, 0x76, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x22, 0xca, 0x01, 0x0a, 0x14, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x42,
+	0x75, 0x6e, 0x64, 0x6c, 0x65, 0x4d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a,
+	0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x75, 0x75, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x55, 0x75, 0x69, 0x64, 0x12, 0x1a,
+	0x0a, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x3c, 0x0a, 0x06, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x6f, 0x72, 0x67,
+	0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2e, 0x45, 0x64, 0x67, 0x65, 0x44, 0x65, 0x76, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x52, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x37, 0x0a, 0x05, 0x62, 0x6c, 0x6f, 0x62,
+	0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66,
+	0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e,
+	0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x62, 0x52, 0x05, 0x62, 0x6c, 0x6f, 0x62,
+	0x73, 0x22, 0x62, 0x0a, 0x0a, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x42, 0x6c, 0x6f, 0x62, 0x12,
+	0x16, 0x0a, 0x06, 0x73, 0x68, 0x61, 0x32, 0x35, 0x36, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x73, 0x68, 0x61, 0x32, 0x35, 0x36, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x69, 0x7a, 0x65, 0x5f,
+	0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x73, 0x69, 0x7a,
+	0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x5f,
+	0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x65, 0x64, 0x69,
+	0x61, 0x54, 0x79, 0x70, 0x65, 0x42, 0x3d, 0x0a, 0x15, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65,
+	0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5a, 0x24,
+	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x66, 0x2d, 0x65, 0x64,
+	0x67, 0x65, 0x2f, 0x65, 0x76, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x6f, 0x2f, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_config_bundle_proto_rawDescOnce sync.Once
+	file_config_bundle_proto_rawDescData = file_config_bundle_proto_rawDesc
+)
+
+func file_config_bundle_proto_rawDescGZIP() []byte {
+	file_config_bundle_proto_rawDescOnce.Do(func() {
+		file_config_bundle_proto_rawDescData = protoimpl.X.CompressGZIP(file_config_bundle_proto_rawDescData)
+	})
+	return file_config_bundle_proto_rawDescData
+}
+
+var file_config_bundle_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_config_bundle_proto_goTypes = []interface{}{
+	(*UpdateBundleManifest)(nil), // 0: org.lfedge.eve.config.UpdateBundleManifest
+	(*BundleBlob)(nil),           // 1: org.lfedge.eve.config.BundleBlob
+	(*EdgeDevConfig)(nil),        // 2: org.lfedge.eve.config.EdgeDevConfig
+}
+var file_config_bundle_proto_depIdxs = []int32{
+	2, // 0: org.lfedge.eve.config.UpdateBundleManifest.config:type_name -> org.lfedge.eve.config.EdgeDevConfig
+	1, // 1: org.lfedge.eve.config.UpdateBundleManifest.blobs:type_name -> org.lfedge.eve.config.BundleBlob
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_config_bundle_proto_init() }
+func file_config_bundle_proto_init() {
+	if File_config_bundle_proto != nil {
+		return
+	}
+	file_config_devconfig_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_config_bundle_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateBundleManifest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_config_bundle_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BundleBlob); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_config_bundle_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_config_bundle_proto_goTypes,
+		DependencyIndexes: file_config_bundle_proto_depIdxs,
+		MessageInfos:      file_config_bundle_proto_msgTypes,
+	}.Build()
+	File_config_bundle_proto = out.File
+	file_config_bundle_proto_rawDesc = nil
+	file_config_bundle_proto_goTypes = nil
+	file_config_bundle_proto_depIdxs = nil
+}
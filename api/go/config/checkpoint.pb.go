@@ -0,0 +1,172 @@
+// Copyright(c) 2022 Zededa, Inc.
+// All rights reserved.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.26.0
+// 	protoc        v3.18.1
+// source: config/checkpoint.proto
+
+package config
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ConfigCheckpoint is a portable export of the config checkpoint of the
+// device. It is imported on another device, or on the same device after
+// a factory reset, and used to boot the app instances when the controller
+// is unreachable. The bundle is exported deterministically, hence the same
+// checkpoint always results in the same bundle.
+type ConfigCheckpoint struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Marshalled AuthContainer with a ConfigResponse as the payload, as
+	// received from the controller. It carries the signature of the
+	// controller signing certificate.
+	SignedConfig []byte `protobuf:"bytes,1,opt,name=signed_config,json=signedConfig,proto3" json:"signed_config,omitempty"`
+	// Marshalled ZControllerCert, as received from the controller. Used to
+	// verify signed_config against the root certificate of the device if
+	// the device does not have the controller signing certificate (yet).
+	ControllerCerts []byte `protobuf:"bytes,2,opt,name=controller_certs,json=controllerCerts,proto3" json:"controller_certs,omitempty"`
+}
+
+func (x *ConfigCheckpoint) Reset() {
+	*x = ConfigCheckpoint{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_config_checkpoint_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConfigCheckpoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigCheckpoint) ProtoMessage() {}
+
+func (x *ConfigCheckpoint) ProtoReflect() protoreflect.Message {
+	mi := &file_config_checkpoint_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigCheckpoint.ProtoReflect.Descriptor instead.
+func (*ConfigCheckpoint) Descriptor() ([]byte, []int) {
+	return file_config_checkpoint_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ConfigCheckpoint) GetSignedConfig() []byte {
+	if x != nil {
+		return x.SignedConfig
+	}
+	return nil
+}
+
+func (x *ConfigCheckpoint) GetControllerCerts() []byte {
+	if x != nil {
+		return x.ControllerCerts
+	}
+	return nil
+}
+
+var File_config_checkpoint_proto protoreflect.FileDescriptor
+
+var file_config_checkpoint_proto_rawDesc = []byte{
+	0x0a, 0x17, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f,
+	0x69, 0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x15, 0x6f, 0x72, 0x67, 0x2e, 0x6c,
+	0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x22, 0x62, 0x0a, 0x10, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70,
+	0x6f, 0x69, 0x6e, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x5f, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x73, 0x69, 0x67,
+	0x6e, 0x65, 0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x29, 0x0a, 0x10, 0x63, 0x6f, 0x6e,
+	0x74, 0x72, 0x6f, 0x6c, 0x6c, 0x65, 0x72, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x0f, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x6c, 0x65, 0x72, 0x43,
+	0x65, 0x72, 0x74, 0x73, 0x42, 0x3d, 0x0a, 0x15, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64,
+	0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5a, 0x24, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x66, 0x2d, 0x65, 0x64, 0x67,
+	0x65, 0x2f, 0x65, 0x76, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x6f, 0x2f, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_config_checkpoint_proto_rawDescOnce sync.Once
+	file_config_checkpoint_proto_rawDescData = file_config_checkpoint_proto_rawDesc
+)
+
+func file_config_checkpoint_proto_rawDescGZIP() []byte {
+	file_config_checkpoint_proto_rawDescOnce.Do(func() {
+		file_config_checkpoint_proto_rawDescData = protoimpl.X.CompressGZIP(file_config_checkpoint_proto_rawDescData)
+	})
+	return file_config_checkpoint_proto_rawDescData
+}
+
+var file_config_checkpoint_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_config_checkpoint_proto_goTypes = []interface{}{
+	(*ConfigCheckpoint)(nil), // 0: org.lfedge.eve.config.ConfigCheckpoint
+}
+var file_config_checkpoint_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_config_checkpoint_proto_init() }
+func file_config_checkpoint_proto_init() {
+	if File_config_checkpoint_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_config_checkpoint_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ConfigCheckpoint); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_config_checkpoint_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_config_checkpoint_proto_goTypes,
+		DependencyIndexes: file_config_checkpoint_proto_depIdxs,
+		MessageInfos:      file_config_checkpoint_proto_msgTypes,
+	}.Build()
+	File_config_checkpoint_proto = out.File
+	file_config_checkpoint_proto_rawDesc = nil
+	file_config_checkpoint_proto_goTypes = nil
+	file_config_checkpoint_proto_depIdxs = nil
+}
@@ -298,6 +298,112 @@ func (WiFiKeyScheme) EnumDescriptor() ([]byte, []int) {
 	return file_config_netcmn_proto_rawDescGZIP(), []int{4}
 }
 
+// Type of IP addressing requested for a cellular connection.
+type CellularIPType int32
+
+const (
+	CellularIPType_CELLULAR_IP_TYPE_UNSPECIFIED   CellularIPType = 0 // modem default
+	CellularIPType_CELLULAR_IP_TYPE_IPV4          CellularIPType = 1
+	CellularIPType_CELLULAR_IP_TYPE_IPV6          CellularIPType = 2
+	CellularIPType_CELLULAR_IP_TYPE_IPV4_AND_IPV6 CellularIPType = 3 // dual-stack
+)
+
+// Enum value maps for CellularIPType.
+var (
+	CellularIPType_name = map[int32]string{
+		0: "CELLULAR_IP_TYPE_UNSPECIFIED",
+		1: "CELLULAR_IP_TYPE_IPV4",
+		2: "CELLULAR_IP_TYPE_IPV6",
+		3: "CELLULAR_IP_TYPE_IPV4_AND_IPV6",
+	}
+	CellularIPType_value = map[string]int32{
+		"CELLULAR_IP_TYPE_UNSPECIFIED":   0,
+		"CELLULAR_IP_TYPE_IPV4":          1,
+		"CELLULAR_IP_TYPE_IPV6":          2,
+		"CELLULAR_IP_TYPE_IPV4_AND_IPV6": 3,
+	}
+)
+
+func (x CellularIPType) Enum() *CellularIPType {
+	p := new(CellularIPType)
+	*p = x
+	return p
+}
+
+func (x CellularIPType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CellularIPType) Descriptor() protoreflect.EnumDescriptor {
+	return file_config_netcmn_proto_enumTypes[5].Descriptor()
+}
+
+func (CellularIPType) Type() protoreflect.EnumType {
+	return &file_config_netcmn_proto_enumTypes[5]
+}
+
+func (x CellularIPType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CellularIPType.Descriptor instead.
+func (CellularIPType) EnumDescriptor() ([]byte, []int) {
+	return file_config_netcmn_proto_rawDescGZIP(), []int{5}
+}
+
+// Authentication protocol used to connect to a cellular APN.
+type CellularAuthProtocol int32
+
+const (
+	CellularAuthProtocol_CELLULAR_AUTH_PROTOCOL_NONE         CellularAuthProtocol = 0
+	CellularAuthProtocol_CELLULAR_AUTH_PROTOCOL_PAP          CellularAuthProtocol = 1
+	CellularAuthProtocol_CELLULAR_AUTH_PROTOCOL_CHAP         CellularAuthProtocol = 2
+	CellularAuthProtocol_CELLULAR_AUTH_PROTOCOL_PAP_AND_CHAP CellularAuthProtocol = 3
+)
+
+// Enum value maps for CellularAuthProtocol.
+var (
+	CellularAuthProtocol_name = map[int32]string{
+		0: "CELLULAR_AUTH_PROTOCOL_NONE",
+		1: "CELLULAR_AUTH_PROTOCOL_PAP",
+		2: "CELLULAR_AUTH_PROTOCOL_CHAP",
+		3: "CELLULAR_AUTH_PROTOCOL_PAP_AND_CHAP",
+	}
+	CellularAuthProtocol_value = map[string]int32{
+		"CELLULAR_AUTH_PROTOCOL_NONE":         0,
+		"CELLULAR_AUTH_PROTOCOL_PAP":          1,
+		"CELLULAR_AUTH_PROTOCOL_CHAP":         2,
+		"CELLULAR_AUTH_PROTOCOL_PAP_AND_CHAP": 3,
+	}
+)
+
+func (x CellularAuthProtocol) Enum() *CellularAuthProtocol {
+	p := new(CellularAuthProtocol)
+	*p = x
+	return p
+}
+
+func (x CellularAuthProtocol) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CellularAuthProtocol) Descriptor() protoreflect.EnumDescriptor {
+	return file_config_netcmn_proto_enumTypes[6].Descriptor()
+}
+
+func (CellularAuthProtocol) Type() protoreflect.EnumType {
+	return &file_config_netcmn_proto_enumTypes[6]
+}
+
+func (x CellularAuthProtocol) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CellularAuthProtocol.Descriptor instead.
+func (CellularAuthProtocol) EnumDescriptor() ([]byte, []int) {
+	return file_config_netcmn_proto_rawDescGZIP(), []int{6}
+}
+
 type IpRange struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -804,11 +910,30 @@ var file_config_netcmn_proto_rawDesc = []byte{
 	0x69, 0x4b, 0x65, 0x79, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x65, 0x12, 0x0e, 0x0a, 0x0a, 0x53, 0x63,
 	0x68, 0x65, 0x6d, 0x65, 0x4e, 0x4f, 0x4f, 0x50, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x57, 0x50,
 	0x41, 0x50, 0x53, 0x4b, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x57, 0x50, 0x41, 0x45, 0x41, 0x50,
-	0x10, 0x02, 0x42, 0x3d, 0x0a, 0x15, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65,
-	0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5a, 0x24, 0x67, 0x69, 0x74,
-	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x66, 0x2d, 0x65, 0x64, 0x67, 0x65, 0x2f,
-	0x65, 0x76, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x6f, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69,
-	0x67, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x10, 0x02, 0x2a, 0x8c, 0x01, 0x0a, 0x0e, 0x43, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x49,
+	0x50, 0x54, 0x79, 0x70, 0x65, 0x12, 0x20, 0x0a, 0x1c, 0x43, 0x45, 0x4c, 0x4c, 0x55, 0x4c, 0x41,
+	0x52, 0x5f, 0x49, 0x50, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43,
+	0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x19, 0x0a, 0x15, 0x43, 0x45, 0x4c, 0x4c, 0x55,
+	0x4c, 0x41, 0x52, 0x5f, 0x49, 0x50, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x49, 0x50, 0x56, 0x34,
+	0x10, 0x01, 0x12, 0x19, 0x0a, 0x15, 0x43, 0x45, 0x4c, 0x4c, 0x55, 0x4c, 0x41, 0x52, 0x5f, 0x49,
+	0x50, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x49, 0x50, 0x56, 0x36, 0x10, 0x02, 0x12, 0x22, 0x0a,
+	0x1e, 0x43, 0x45, 0x4c, 0x4c, 0x55, 0x4c, 0x41, 0x52, 0x5f, 0x49, 0x50, 0x5f, 0x54, 0x59, 0x50,
+	0x45, 0x5f, 0x49, 0x50, 0x56, 0x34, 0x5f, 0x41, 0x4e, 0x44, 0x5f, 0x49, 0x50, 0x56, 0x36, 0x10,
+	0x03, 0x2a, 0xa1, 0x01, 0x0a, 0x14, 0x43, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x41, 0x75,
+	0x74, 0x68, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x1f, 0x0a, 0x1b, 0x43, 0x45,
+	0x4c, 0x4c, 0x55, 0x4c, 0x41, 0x52, 0x5f, 0x41, 0x55, 0x54, 0x48, 0x5f, 0x50, 0x52, 0x4f, 0x54,
+	0x4f, 0x43, 0x4f, 0x4c, 0x5f, 0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x1e, 0x0a, 0x1a, 0x43,
+	0x45, 0x4c, 0x4c, 0x55, 0x4c, 0x41, 0x52, 0x5f, 0x41, 0x55, 0x54, 0x48, 0x5f, 0x50, 0x52, 0x4f,
+	0x54, 0x4f, 0x43, 0x4f, 0x4c, 0x5f, 0x50, 0x41, 0x50, 0x10, 0x01, 0x12, 0x1f, 0x0a, 0x1b, 0x43,
+	0x45, 0x4c, 0x4c, 0x55, 0x4c, 0x41, 0x52, 0x5f, 0x41, 0x55, 0x54, 0x48, 0x5f, 0x50, 0x52, 0x4f,
+	0x54, 0x4f, 0x43, 0x4f, 0x4c, 0x5f, 0x43, 0x48, 0x41, 0x50, 0x10, 0x02, 0x12, 0x27, 0x0a, 0x23,
+	0x43, 0x45, 0x4c, 0x4c, 0x55, 0x4c, 0x41, 0x52, 0x5f, 0x41, 0x55, 0x54, 0x48, 0x5f, 0x50, 0x52,
+	0x4f, 0x54, 0x4f, 0x43, 0x4f, 0x4c, 0x5f, 0x50, 0x41, 0x50, 0x5f, 0x41, 0x4e, 0x44, 0x5f, 0x43,
+	0x48, 0x41, 0x50, 0x10, 0x03, 0x42, 0x3d, 0x0a, 0x15, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65,
+	0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5a, 0x24,
+	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x66, 0x2d, 0x65, 0x64,
+	0x67, 0x65, 0x2f, 0x65, 0x76, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x6f, 0x2f, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -823,7 +948,7 @@ func file_config_netcmn_proto_rawDescGZIP() []byte {
 	return file_config_netcmn_proto_rawDescData
 }
 
-var file_config_netcmn_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_config_netcmn_proto_enumTypes = make([]protoimpl.EnumInfo, 7)
 var file_config_netcmn_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_config_netcmn_proto_goTypes = []interface{}{
 	(ProxyProto)(0),            // 0: org.lfedge.eve.config.proxyProto
@@ -831,18 +956,20 @@ var file_config_netcmn_proto_goTypes = []interface{}{
 	(NetworkType)(0),           // 2: org.lfedge.eve.config.NetworkType
 	(WirelessType)(0),          // 3: org.lfedge.eve.config.WirelessType
 	(WiFiKeyScheme)(0),         // 4: org.lfedge.eve.config.WiFiKeyScheme
-	(*IpRange)(nil),            // 5: org.lfedge.eve.config.ipRange
-	(*ProxyServer)(nil),        // 6: org.lfedge.eve.config.ProxyServer
-	(*ProxyConfig)(nil),        // 7: org.lfedge.eve.config.ProxyConfig
-	(*ZedServer)(nil),          // 8: org.lfedge.eve.config.ZedServer
-	(*ZnetStaticDNSEntry)(nil), // 9: org.lfedge.eve.config.ZnetStaticDNSEntry
-	(*Ipspec)(nil),             // 10: org.lfedge.eve.config.ipspec
+	(CellularIPType)(0),        // 5: org.lfedge.eve.config.CellularIPType
+	(CellularAuthProtocol)(0),  // 6: org.lfedge.eve.config.CellularAuthProtocol
+	(*IpRange)(nil),            // 7: org.lfedge.eve.config.ipRange
+	(*ProxyServer)(nil),        // 8: org.lfedge.eve.config.ProxyServer
+	(*ProxyConfig)(nil),        // 9: org.lfedge.eve.config.ProxyConfig
+	(*ZedServer)(nil),          // 10: org.lfedge.eve.config.ZedServer
+	(*ZnetStaticDNSEntry)(nil), // 11: org.lfedge.eve.config.ZnetStaticDNSEntry
+	(*Ipspec)(nil),             // 12: org.lfedge.eve.config.ipspec
 }
 var file_config_netcmn_proto_depIdxs = []int32{
 	0, // 0: org.lfedge.eve.config.ProxyServer.proto:type_name -> org.lfedge.eve.config.proxyProto
-	6, // 1: org.lfedge.eve.config.ProxyConfig.proxies:type_name -> org.lfedge.eve.config.ProxyServer
+	8, // 1: org.lfedge.eve.config.ProxyConfig.proxies:type_name -> org.lfedge.eve.config.ProxyServer
 	1, // 2: org.lfedge.eve.config.ipspec.dhcp:type_name -> org.lfedge.eve.config.DHCPType
-	5, // 3: org.lfedge.eve.config.ipspec.dhcpRange:type_name -> org.lfedge.eve.config.ipRange
+	7, // 3: org.lfedge.eve.config.ipspec.dhcpRange:type_name -> org.lfedge.eve.config.ipRange
 	4, // [4:4] is the sub-list for method output_type
 	4, // [4:4] is the sub-list for method input_type
 	4, // [4:4] is the sub-list for extension type_name
@@ -934,7 +1061,7 @@ func file_config_netcmn_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_config_netcmn_proto_rawDesc,
-			NumEnums:      5,
+			NumEnums:      7,
 			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   0,
@@ -23,6 +23,59 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Dot1xEAPMethod selects the EAP method used for 802.1X authentication.
+type Dot1XEAPMethod int32
+
+const (
+	// 802.1X authentication is disabled.
+	Dot1XEAPMethod_DOT1X_EAP_METHOD_UNSPECIFIED Dot1XEAPMethod = 0
+	// EAP-TLS: mutual authentication using certificates.
+	Dot1XEAPMethod_DOT1X_EAP_METHOD_TLS Dot1XEAPMethod = 1
+	// PEAP with MSCHAPv2 inner authentication.
+	Dot1XEAPMethod_DOT1X_EAP_METHOD_PEAP Dot1XEAPMethod = 2
+)
+
+// Enum value maps for Dot1XEAPMethod.
+var (
+	Dot1XEAPMethod_name = map[int32]string{
+		0: "DOT1X_EAP_METHOD_UNSPECIFIED",
+		1: "DOT1X_EAP_METHOD_TLS",
+		2: "DOT1X_EAP_METHOD_PEAP",
+	}
+	Dot1XEAPMethod_value = map[string]int32{
+		"DOT1X_EAP_METHOD_UNSPECIFIED": 0,
+		"DOT1X_EAP_METHOD_TLS":         1,
+		"DOT1X_EAP_METHOD_PEAP":        2,
+	}
+)
+
+func (x Dot1XEAPMethod) Enum() *Dot1XEAPMethod {
+	p := new(Dot1XEAPMethod)
+	*p = x
+	return p
+}
+
+func (x Dot1XEAPMethod) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Dot1XEAPMethod) Descriptor() protoreflect.EnumDescriptor {
+	return file_config_netconfig_proto_enumTypes[0].Descriptor()
+}
+
+func (Dot1XEAPMethod) Type() protoreflect.EnumType {
+	return &file_config_netconfig_proto_enumTypes[0]
+}
+
+func (x Dot1XEAPMethod) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Dot1XEAPMethod.Descriptor instead.
+func (Dot1XEAPMethod) EnumDescriptor() ([]byte, []int) {
+	return file_config_netconfig_proto_rawDescGZIP(), []int{0}
+}
+
 type NetworkConfig struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -37,6 +90,13 @@ type NetworkConfig struct {
 	EntProxy *ProxyConfig `protobuf:"bytes,8,opt,name=entProxy,proto3" json:"entProxy,omitempty"`
 	// wireless specification
 	Wireless *WirelessConfig `protobuf:"bytes,10,opt,name=wireless,proto3" json:"wireless,omitempty"`
+	// Static IP routes configured for every device port using this network.
+	StaticRoutes []*StaticIPRoute `protobuf:"bytes,11,rep,name=static_routes,json=staticRoutes,proto3" json:"static_routes,omitempty"`
+	// Policy-based routing rules configured for every device port using
+	// this network.
+	PolicyRules []*IPPolicyRule `protobuf:"bytes,12,rep,name=policy_rules,json=policyRules,proto3" json:"policy_rules,omitempty"`
+	// 802.1X authentication of wired device ports using this network.
+	Dot1X *Dot1XConfig `protobuf:"bytes,13,opt,name=dot1x,proto3" json:"dot1x,omitempty"`
 }
 
 func (x *NetworkConfig) Reset() {
@@ -113,6 +173,277 @@ func (x *NetworkConfig) GetWireless() *WirelessConfig {
 	return nil
 }
 
+func (x *NetworkConfig) GetStaticRoutes() []*StaticIPRoute {
+	if x != nil {
+		return x.StaticRoutes
+	}
+	return nil
+}
+
+func (x *NetworkConfig) GetPolicyRules() []*IPPolicyRule {
+	if x != nil {
+		return x.PolicyRules
+	}
+	return nil
+}
+
+func (x *NetworkConfig) GetDot1X() *Dot1XConfig {
+	if x != nil {
+		return x.Dot1X
+	}
+	return nil
+}
+
+// Dot1xConfig configures 802.1X (port-based network access control)
+// authentication of a wired port.
+type Dot1XConfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	EapMethod Dot1XEAPMethod `protobuf:"varint,1,opt,name=eap_method,json=eapMethod,proto3,enum=org.lfedge.eve.config.Dot1XEAPMethod" json:"eap_method,omitempty"`
+	// Identity presented to the authentication server.
+	Identity string `protobuf:"bytes,2,opt,name=identity,proto3" json:"identity,omitempty"`
+	// Identity sent in the unencrypted outer phase of PEAP.
+	// If empty, identity is used.
+	AnonymousIdentity string `protobuf:"bytes,3,opt,name=anonymous_identity,json=anonymousIdentity,proto3" json:"anonymous_identity,omitempty"`
+	// Password, client certificate with private key and CA certificate(s)
+	// are delivered encrypted in EncryptionBlock (fields dot1x*).
+	CipherData *CipherBlock `protobuf:"bytes,4,opt,name=cipher_data,json=cipherData,proto3" json:"cipher_data,omitempty"`
+	// PEAP without a CA certificate does not verify the authentication server
+	// and exposes the credentials to any server answering on the port.
+	// Such configuration is rejected unless explicitly allowed here.
+	AllowPeapWithoutCaCert bool `protobuf:"varint,5,opt,name=allow_peap_without_ca_cert,json=allowPeapWithoutCaCert,proto3" json:"allow_peap_without_ca_cert,omitempty"`
+}
+
+func (x *Dot1XConfig) Reset() {
+	*x = Dot1XConfig{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_config_netconfig_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Dot1XConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Dot1XConfig) ProtoMessage() {}
+
+func (x *Dot1XConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_config_netconfig_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Dot1XConfig.ProtoReflect.Descriptor instead.
+func (*Dot1XConfig) Descriptor() ([]byte, []int) {
+	return file_config_netconfig_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Dot1XConfig) GetEapMethod() Dot1XEAPMethod {
+	if x != nil {
+		return x.EapMethod
+	}
+	return Dot1XEAPMethod_DOT1X_EAP_METHOD_UNSPECIFIED
+}
+
+func (x *Dot1XConfig) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *Dot1XConfig) GetAnonymousIdentity() string {
+	if x != nil {
+		return x.AnonymousIdentity
+	}
+	return ""
+}
+
+func (x *Dot1XConfig) GetCipherData() *CipherBlock {
+	if x != nil {
+		return x.CipherData
+	}
+	return nil
+}
+
+func (x *Dot1XConfig) GetAllowPeapWithoutCaCert() bool {
+	if x != nil {
+		return x.AllowPeapWithoutCaCert
+	}
+	return false
+}
+
+// StaticIPRoute is a user-defined IP route of a device port.
+type StaticIPRoute struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Destination network in CIDR format, e.g. "10.50.0.0/16".
+	Destination string `protobuf:"bytes,1,opt,name=destination,proto3" json:"destination,omitempty"`
+	// Next hop address. If empty, the destination is reached directly
+	// via the port (on-link route).
+	Gateway string `protobuf:"bytes,2,opt,name=gateway,proto3" json:"gateway,omitempty"`
+	// Route metric, lower is preferred.
+	Metric uint32 `protobuf:"varint,3,opt,name=metric,proto3" json:"metric,omitempty"`
+	// Routing table to install the route into.
+	// Zero selects the main table, from where the route is also mirrored into
+	// the routing table of the port (used for traffic sourced from the port's
+	// addresses).
+	Table uint32 `protobuf:"varint,4,opt,name=table,proto3" json:"table,omitempty"`
+}
+
+func (x *StaticIPRoute) Reset() {
+	*x = StaticIPRoute{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_config_netconfig_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StaticIPRoute) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StaticIPRoute) ProtoMessage() {}
+
+func (x *StaticIPRoute) ProtoReflect() protoreflect.Message {
+	mi := &file_config_netconfig_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StaticIPRoute.ProtoReflect.Descriptor instead.
+func (*StaticIPRoute) Descriptor() ([]byte, []int) {
+	return file_config_netconfig_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *StaticIPRoute) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *StaticIPRoute) GetGateway() string {
+	if x != nil {
+		return x.Gateway
+	}
+	return ""
+}
+
+func (x *StaticIPRoute) GetMetric() uint32 {
+	if x != nil {
+		return x.Metric
+	}
+	return 0
+}
+
+func (x *StaticIPRoute) GetTable() uint32 {
+	if x != nil {
+		return x.Table
+	}
+	return 0
+}
+
+// IPPolicyRule selects traffic to route using the routing table of the port.
+// At least one of source and destination must be set.
+type IPPolicyRule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Source network in CIDR format. Empty matches any source.
+	Source string `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	// Destination network in CIDR format. Empty matches any destination.
+	Destination string `protobuf:"bytes,2,opt,name=destination,proto3" json:"destination,omitempty"`
+	// Priority of the rule, lower is evaluated first.
+	// Zero selects the default priority, which is applied after the rules
+	// for application traffic and before the source-based rules of device
+	// ports.
+	Priority uint32 `protobuf:"varint,3,opt,name=priority,proto3" json:"priority,omitempty"`
+	// Routing table to use for the matched traffic.
+	// Zero selects the routing table of the port.
+	Table uint32 `protobuf:"varint,4,opt,name=table,proto3" json:"table,omitempty"`
+}
+
+func (x *IPPolicyRule) Reset() {
+	*x = IPPolicyRule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_config_netconfig_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IPPolicyRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IPPolicyRule) ProtoMessage() {}
+
+func (x *IPPolicyRule) ProtoReflect() protoreflect.Message {
+	mi := &file_config_netconfig_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IPPolicyRule.ProtoReflect.Descriptor instead.
+func (*IPPolicyRule) Descriptor() ([]byte, []int) {
+	return file_config_netconfig_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *IPPolicyRule) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *IPPolicyRule) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *IPPolicyRule) GetPriority() uint32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *IPPolicyRule) GetTable() uint32 {
+	if x != nil {
+		return x.Table
+	}
+	return 0
+}
+
 type NetworkAdapter struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -142,7 +473,7 @@ type NetworkAdapter struct {
 func (x *NetworkAdapter) Reset() {
 	*x = NetworkAdapter{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_config_netconfig_proto_msgTypes[1]
+		mi := &file_config_netconfig_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -155,7 +486,7 @@ func (x *NetworkAdapter) String() string {
 func (*NetworkAdapter) ProtoMessage() {}
 
 func (x *NetworkAdapter) ProtoReflect() protoreflect.Message {
-	mi := &file_config_netconfig_proto_msgTypes[1]
+	mi := &file_config_netconfig_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -168,7 +499,7 @@ func (x *NetworkAdapter) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NetworkAdapter.ProtoReflect.Descriptor instead.
 func (*NetworkAdapter) Descriptor() ([]byte, []int) {
-	return file_config_netconfig_proto_rawDescGZIP(), []int{1}
+	return file_config_netconfig_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *NetworkAdapter) GetName() string {
@@ -253,15 +584,18 @@ type WirelessConfig struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Type        WirelessType      `protobuf:"varint,1,opt,name=type,proto3,enum=org.lfedge.eve.config.WirelessType" json:"type,omitempty"` // either LTE or Wifi
-	CellularCfg []*CellularConfig `protobuf:"bytes,5,rep,name=cellularCfg,proto3" json:"cellularCfg,omitempty"`                            // Cellular config
-	WifiCfg     []*WifiConfig     `protobuf:"bytes,10,rep,name=wifiCfg,proto3" json:"wifiCfg,omitempty"`                                   // Wifi, can be multiple APs on a single wlan, e.g. one for 2.5Ghz, other 5Ghz SSIDs
+	Type WirelessType `protobuf:"varint,1,opt,name=type,proto3,enum=org.lfedge.eve.config.WirelessType" json:"type,omitempty"` // either LTE or Wifi
+	// Cellular config. Multiple entries are APN profiles of the same modem,
+	// tried in the given order until connectivity is established.
+	// Probe and location tracking settings are taken from the first entry.
+	CellularCfg []*CellularConfig `protobuf:"bytes,5,rep,name=cellularCfg,proto3" json:"cellularCfg,omitempty"`
+	WifiCfg     []*WifiConfig     `protobuf:"bytes,10,rep,name=wifiCfg,proto3" json:"wifiCfg,omitempty"` // Wifi, can be multiple APs on a single wlan, e.g. one for 2.5Ghz, other 5Ghz SSIDs
 }
 
 func (x *WirelessConfig) Reset() {
 	*x = WirelessConfig{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_config_netconfig_proto_msgTypes[2]
+		mi := &file_config_netconfig_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -274,7 +608,7 @@ func (x *WirelessConfig) String() string {
 func (*WirelessConfig) ProtoMessage() {}
 
 func (x *WirelessConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_config_netconfig_proto_msgTypes[2]
+	mi := &file_config_netconfig_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -287,7 +621,7 @@ func (x *WirelessConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WirelessConfig.ProtoReflect.Descriptor instead.
 func (*WirelessConfig) Descriptor() ([]byte, []int) {
-	return file_config_netconfig_proto_rawDescGZIP(), []int{2}
+	return file_config_netconfig_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *WirelessConfig) GetType() WirelessType {
@@ -326,12 +660,24 @@ type CellularConfig struct {
 	// Enable this option to have location info periodically obtained from this
 	// modem and published to controller and to applications.
 	LocationTracking bool `protobuf:"varint,3,opt,name=location_tracking,json=locationTracking,proto3" json:"location_tracking,omitempty"`
+	// SIM slot to use with this APN profile, numbered from 1.
+	// Zero means the slot which is currently selected in the modem.
+	SimSlot uint32 `protobuf:"varint,4,opt,name=sim_slot,json=simSlot,proto3" json:"sim_slot,omitempty"`
+	// Type of IP addressing to request from the network.
+	IpType CellularIPType `protobuf:"varint,5,opt,name=ip_type,json=ipType,proto3,enum=org.lfedge.eve.config.CellularIPType" json:"ip_type,omitempty"`
+	// Authentication protocol required by the APN.
+	AuthProtocol CellularAuthProtocol `protobuf:"varint,6,opt,name=auth_protocol,json=authProtocol,proto3,enum=org.lfedge.eve.config.CellularAuthProtocol" json:"auth_protocol,omitempty"`
+	// Encrypted username and password for the APN authentication
+	// (see EncryptionBlock.cellularNetUsername and cellularNetPassword).
+	CipherData *CipherBlock `protobuf:"bytes,7,opt,name=cipher_data,json=cipherData,proto3" json:"cipher_data,omitempty"`
+	// If true, this APN profile is not used while the modem is roaming.
+	ForbidRoaming bool `protobuf:"varint,8,opt,name=forbid_roaming,json=forbidRoaming,proto3" json:"forbid_roaming,omitempty"`
 }
 
 func (x *CellularConfig) Reset() {
 	*x = CellularConfig{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_config_netconfig_proto_msgTypes[3]
+		mi := &file_config_netconfig_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -344,7 +690,7 @@ func (x *CellularConfig) String() string {
 func (*CellularConfig) ProtoMessage() {}
 
 func (x *CellularConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_config_netconfig_proto_msgTypes[3]
+	mi := &file_config_netconfig_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -357,7 +703,7 @@ func (x *CellularConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CellularConfig.ProtoReflect.Descriptor instead.
 func (*CellularConfig) Descriptor() ([]byte, []int) {
-	return file_config_netconfig_proto_rawDescGZIP(), []int{3}
+	return file_config_netconfig_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *CellularConfig) GetAPN() string {
@@ -381,6 +727,41 @@ func (x *CellularConfig) GetLocationTracking() bool {
 	return false
 }
 
+func (x *CellularConfig) GetSimSlot() uint32 {
+	if x != nil {
+		return x.SimSlot
+	}
+	return 0
+}
+
+func (x *CellularConfig) GetIpType() CellularIPType {
+	if x != nil {
+		return x.IpType
+	}
+	return CellularIPType_CELLULAR_IP_TYPE_UNSPECIFIED
+}
+
+func (x *CellularConfig) GetAuthProtocol() CellularAuthProtocol {
+	if x != nil {
+		return x.AuthProtocol
+	}
+	return CellularAuthProtocol_CELLULAR_AUTH_PROTOCOL_NONE
+}
+
+func (x *CellularConfig) GetCipherData() *CipherBlock {
+	if x != nil {
+		return x.CipherData
+	}
+	return nil
+}
+
+func (x *CellularConfig) GetForbidRoaming() bool {
+	if x != nil {
+		return x.ForbidRoaming
+	}
+	return false
+}
+
 // CellularConnectivityProbe is used to periodically check the connectivity status of a cellular network
 // by probing a remote endpoint.
 // Whenever the probe fails, the cellular connection is automatically restarted. If the probe keeps failing
@@ -401,7 +782,7 @@ type CellularConnectivityProbe struct {
 func (x *CellularConnectivityProbe) Reset() {
 	*x = CellularConnectivityProbe{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_config_netconfig_proto_msgTypes[4]
+		mi := &file_config_netconfig_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -414,7 +795,7 @@ func (x *CellularConnectivityProbe) String() string {
 func (*CellularConnectivityProbe) ProtoMessage() {}
 
 func (x *CellularConnectivityProbe) ProtoReflect() protoreflect.Message {
-	mi := &file_config_netconfig_proto_msgTypes[4]
+	mi := &file_config_netconfig_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -427,7 +808,7 @@ func (x *CellularConnectivityProbe) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CellularConnectivityProbe.ProtoReflect.Descriptor instead.
 func (*CellularConnectivityProbe) Descriptor() ([]byte, []int) {
-	return file_config_netconfig_proto_rawDescGZIP(), []int{4}
+	return file_config_netconfig_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *CellularConnectivityProbe) GetDisable() bool {
@@ -463,7 +844,7 @@ type WifiConfig struct {
 func (x *WifiConfig) Reset() {
 	*x = WifiConfig{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_config_netconfig_proto_msgTypes[5]
+		mi := &file_config_netconfig_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -476,7 +857,7 @@ func (x *WifiConfig) String() string {
 func (*WifiConfig) ProtoMessage() {}
 
 func (x *WifiConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_config_netconfig_proto_msgTypes[5]
+	mi := &file_config_netconfig_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -489,7 +870,7 @@ func (x *WifiConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WifiConfig.ProtoReflect.Descriptor instead.
 func (*WifiConfig) Descriptor() ([]byte, []int) {
-	return file_config_netconfig_proto_rawDescGZIP(), []int{5}
+	return file_config_netconfig_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *WifiConfig) GetWifiSSID() string {
@@ -553,7 +934,7 @@ type WifiConfigCryptoblock struct {
 func (x *WifiConfigCryptoblock) Reset() {
 	*x = WifiConfigCryptoblock{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_config_netconfig_proto_msgTypes[6]
+		mi := &file_config_netconfig_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -566,7 +947,7 @@ func (x *WifiConfigCryptoblock) String() string {
 func (*WifiConfigCryptoblock) ProtoMessage() {}
 
 func (x *WifiConfigCryptoblock) ProtoReflect() protoreflect.Message {
-	mi := &file_config_netconfig_proto_msgTypes[6]
+	mi := &file_config_netconfig_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -579,7 +960,7 @@ func (x *WifiConfigCryptoblock) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WifiConfigCryptoblock.ProtoReflect.Descriptor instead.
 func (*WifiConfigCryptoblock) Descriptor() ([]byte, []int) {
-	return file_config_netconfig_proto_rawDescGZIP(), []int{5, 0}
+	return file_config_netconfig_proto_rawDescGZIP(), []int{8, 0}
 }
 
 func (x *WifiConfigCryptoblock) GetIdentity() string {
@@ -606,7 +987,7 @@ var file_config_netconfig_proto_rawDesc = []byte{
 	0x6e, 0x66, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0f, 0x63, 0x6f, 0x6e, 0x66, 0x69,
 	0x67, 0x2f, 0x66, 0x77, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x13, 0x63, 0x6f, 0x6e, 0x66,
 	0x69, 0x67, 0x2f, 0x6e, 0x65, 0x74, 0x63, 0x6d, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
-	0xc6, 0x02, 0x0a, 0x0d, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x93, 0x04, 0x0a, 0x0d, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x43, 0x6f, 0x6e, 0x66, 0x69,
 	0x67, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69,
 	0x64, 0x12, 0x36, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32,
 	0x22, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65,
@@ -626,88 +1007,159 @@ var file_config_netconfig_proto_rawDesc = []byte{
 	0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66,
 	0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e,
 	0x57, 0x69, 0x72, 0x65, 0x6c, 0x65, 0x73, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x08,
-	0x77, 0x69, 0x72, 0x65, 0x6c, 0x65, 0x73, 0x73, 0x22, 0xec, 0x02, 0x0a, 0x0e, 0x4e, 0x65, 0x74,
-	0x77, 0x6f, 0x72, 0x6b, 0x41, 0x64, 0x61, 0x70, 0x74, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e,
-	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
-	0x1c, 0x0a, 0x09, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x64, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x09, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x64, 0x12, 0x12, 0x0a,
-	0x04, 0x61, 0x64, 0x64, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x61, 0x64, 0x64,
-	0x72, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x05, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a,
-	0x09, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x45, 0x69, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x09, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x45, 0x69, 0x64, 0x12, 0x24, 0x0a, 0x0d, 0x6c,
-	0x69, 0x73, 0x70, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x06, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x0d, 0x6c, 0x69, 0x73, 0x70, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
-	0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x65, 0x6d, 0x63, 0x65, 0x72, 0x74, 0x18, 0x07, 0x20, 0x01,
-	0x28, 0x0c, 0x52, 0x07, 0x70, 0x65, 0x6d, 0x63, 0x65, 0x72, 0x74, 0x12, 0x24, 0x0a, 0x0d, 0x70,
-	0x65, 0x6d, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x6b, 0x65, 0x79, 0x18, 0x08, 0x20, 0x01,
-	0x28, 0x0c, 0x52, 0x0d, 0x70, 0x65, 0x6d, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x6b, 0x65,
-	0x79, 0x12, 0x1e, 0x0a, 0x0a, 0x6d, 0x61, 0x63, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18,
-	0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6d, 0x61, 0x63, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73,
-	0x73, 0x12, 0x2e, 0x0a, 0x04, 0x61, 0x63, 0x6c, 0x73, 0x18, 0x28, 0x20, 0x03, 0x28, 0x0b, 0x32,
-	0x1a, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65,
-	0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x41, 0x43, 0x45, 0x52, 0x04, 0x61, 0x63, 0x6c,
-	0x73, 0x12, 0x24, 0x0a, 0x0e, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x76, 0x6c, 0x61, 0x6e,
-	0x5f, 0x69, 0x64, 0x18, 0x29, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x61, 0x63, 0x63, 0x65, 0x73,
-	0x73, 0x56, 0x6c, 0x61, 0x6e, 0x49, 0x64, 0x22, 0xcf, 0x01, 0x0a, 0x0e, 0x57, 0x69, 0x72, 0x65,
-	0x6c, 0x65, 0x73, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x37, 0x0a, 0x04, 0x74, 0x79,
-	0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x23, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c,
-	0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
-	0x2e, 0x57, 0x69, 0x72, 0x65, 0x6c, 0x65, 0x73, 0x73, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74,
-	0x79, 0x70, 0x65, 0x12, 0x47, 0x0a, 0x0b, 0x63, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x43,
-	0x66, 0x67, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c,
-	0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
-	0x2e, 0x43, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52,
-	0x0b, 0x63, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x43, 0x66, 0x67, 0x12, 0x3b, 0x0a, 0x07,
-	0x77, 0x69, 0x66, 0x69, 0x43, 0x66, 0x67, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e,
+	0x77, 0x69, 0x72, 0x65, 0x6c, 0x65, 0x73, 0x73, 0x12, 0x49, 0x0a, 0x0d, 0x73, 0x74, 0x61, 0x74,
+	0x69, 0x63, 0x5f, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x24, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65,
+	0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x69, 0x63, 0x49, 0x50,
+	0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x0c, 0x73, 0x74, 0x61, 0x74, 0x69, 0x63, 0x52, 0x6f, 0x75,
+	0x74, 0x65, 0x73, 0x12, 0x46, 0x0a, 0x0c, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x5f, 0x72, 0x75,
+	0x6c, 0x65, 0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x6f, 0x72, 0x67, 0x2e,
+	0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x2e, 0x49, 0x50, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x0b,
+	0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x12, 0x38, 0x0a, 0x05, 0x64,
+	0x6f, 0x74, 0x31, 0x78, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x6f, 0x72, 0x67,
+	0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2e, 0x44, 0x6f, 0x74, 0x31, 0x78, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x05,
+	0x64, 0x6f, 0x74, 0x31, 0x78, 0x22, 0x9f, 0x02, 0x0a, 0x0b, 0x44, 0x6f, 0x74, 0x31, 0x78, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x44, 0x0a, 0x0a, 0x65, 0x61, 0x70, 0x5f, 0x6d, 0x65, 0x74,
+	0x68, 0x6f, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x25, 0x2e, 0x6f, 0x72, 0x67, 0x2e,
+	0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x2e, 0x44, 0x6f, 0x74, 0x31, 0x78, 0x45, 0x41, 0x50, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64,
+	0x52, 0x09, 0x65, 0x61, 0x70, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x69,
+	0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69,
+	0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x2d, 0x0a, 0x12, 0x61, 0x6e, 0x6f, 0x6e, 0x79,
+	0x6d, 0x6f, 0x75, 0x73, 0x5f, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x11, 0x61, 0x6e, 0x6f, 0x6e, 0x79, 0x6d, 0x6f, 0x75, 0x73, 0x49, 0x64,
+	0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x43, 0x0a, 0x0b, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72,
+	0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x6f, 0x72,
+	0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x2e, 0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52,
+	0x0a, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x12, 0x3a, 0x0a, 0x1a, 0x61,
+	0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x70, 0x65, 0x61, 0x70, 0x5f, 0x77, 0x69, 0x74, 0x68, 0x6f, 0x75,
+	0x74, 0x5f, 0x63, 0x61, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x16, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x50, 0x65, 0x61, 0x70, 0x57, 0x69, 0x74, 0x68, 0x6f, 0x75,
+	0x74, 0x43, 0x61, 0x43, 0x65, 0x72, 0x74, 0x22, 0x79, 0x0a, 0x0d, 0x53, 0x74, 0x61, 0x74, 0x69,
+	0x63, 0x49, 0x50, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x74,
+	0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64,
+	0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x67, 0x61,
+	0x74, 0x65, 0x77, 0x61, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x67, 0x61, 0x74,
+	0x65, 0x77, 0x61, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x12, 0x14, 0x0a, 0x05,
+	0x74, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x74, 0x61, 0x62,
+	0x6c, 0x65, 0x22, 0x7a, 0x0a, 0x0c, 0x49, 0x50, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x75,
+	0x6c, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65,
+	0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08,
+	0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08,
+	0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x61, 0x62, 0x6c,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x22, 0xec,
+	0x02, 0x0a, 0x0e, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x41, 0x64, 0x61, 0x70, 0x74, 0x65,
+	0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b,
+	0x49, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72,
+	0x6b, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x64, 0x64, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x61, 0x64, 0x64, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x45, 0x69, 0x64,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x45, 0x69,
+	0x64, 0x12, 0x24, 0x0a, 0x0d, 0x6c, 0x69, 0x73, 0x70, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75,
+	0x72, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6c, 0x69, 0x73, 0x70, 0x73, 0x69,
+	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x65, 0x6d, 0x63, 0x65,
+	0x72, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x65, 0x6d, 0x63, 0x65, 0x72,
+	0x74, 0x12, 0x24, 0x0a, 0x0d, 0x70, 0x65, 0x6d, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x6b,
+	0x65, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0d, 0x70, 0x65, 0x6d, 0x70, 0x72, 0x69,
+	0x76, 0x61, 0x74, 0x65, 0x6b, 0x65, 0x79, 0x12, 0x1e, 0x0a, 0x0a, 0x6d, 0x61, 0x63, 0x41, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6d, 0x61, 0x63,
+	0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x2e, 0x0a, 0x04, 0x61, 0x63, 0x6c, 0x73, 0x18,
+	0x28, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64,
+	0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x41, 0x43,
+	0x45, 0x52, 0x04, 0x61, 0x63, 0x6c, 0x73, 0x12, 0x24, 0x0a, 0x0e, 0x61, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x5f, 0x76, 0x6c, 0x61, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x29, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x0c, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x56, 0x6c, 0x61, 0x6e, 0x49, 0x64, 0x22, 0xcf, 0x01,
+	0x0a, 0x0e, 0x57, 0x69, 0x72, 0x65, 0x6c, 0x65, 0x73, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x12, 0x37, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x23,
+	0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x57, 0x69, 0x72, 0x65, 0x6c, 0x65, 0x73, 0x73, 0x54,
+	0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x47, 0x0a, 0x0b, 0x63, 0x65, 0x6c,
+	0x6c, 0x75, 0x6c, 0x61, 0x72, 0x43, 0x66, 0x67, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x25,
+	0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x43, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x0b, 0x63, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x43,
+	0x66, 0x67, 0x12, 0x3b, 0x0a, 0x07, 0x77, 0x69, 0x66, 0x69, 0x43, 0x66, 0x67, 0x18, 0x0a, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65,
+	0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x57, 0x69, 0x66, 0x69,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x07, 0x77, 0x69, 0x66, 0x69, 0x43, 0x66, 0x67, 0x22,
+	0xb0, 0x03, 0x0a, 0x0e, 0x43, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x12, 0x10, 0x0a, 0x03, 0x41, 0x50, 0x4e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x41, 0x50, 0x4e, 0x12, 0x46, 0x0a, 0x05, 0x70, 0x72, 0x6f, 0x62, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x30, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65,
+	0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x43, 0x65, 0x6c, 0x6c,
+	0x75, 0x6c, 0x61, 0x72, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79,
+	0x50, 0x72, 0x6f, 0x62, 0x65, 0x52, 0x05, 0x70, 0x72, 0x6f, 0x62, 0x65, 0x12, 0x2b, 0x0a, 0x11,
+	0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x69, 0x6e,
+	0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x12, 0x19, 0x0a, 0x08, 0x73, 0x69, 0x6d,
+	0x5f, 0x73, 0x6c, 0x6f, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x73, 0x69, 0x6d,
+	0x53, 0x6c, 0x6f, 0x74, 0x12, 0x3e, 0x0a, 0x07, 0x69, 0x70, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x25, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64,
+	0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x43, 0x65,
+	0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x49, 0x50, 0x54, 0x79, 0x70, 0x65, 0x52, 0x06, 0x69, 0x70,
+	0x54, 0x79, 0x70, 0x65, 0x12, 0x50, 0x0a, 0x0d, 0x61, 0x75, 0x74, 0x68, 0x5f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2b, 0x2e, 0x6f, 0x72,
+	0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x2e, 0x43, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x41, 0x75, 0x74, 0x68,
+	0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x52, 0x0c, 0x61, 0x75, 0x74, 0x68, 0x50, 0x72,
+	0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x43, 0x0a, 0x0b, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72,
+	0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x6f, 0x72,
+	0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x2e, 0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52,
+	0x0a, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x12, 0x25, 0x0a, 0x0e, 0x66,
+	0x6f, 0x72, 0x62, 0x69, 0x64, 0x5f, 0x72, 0x6f, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0d, 0x66, 0x6f, 0x72, 0x62, 0x69, 0x64, 0x52, 0x6f, 0x61, 0x6d, 0x69,
+	0x6e, 0x67, 0x22, 0x5a, 0x0a, 0x19, 0x43, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x43, 0x6f,
+	0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x50, 0x72, 0x6f, 0x62, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x07, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x72, 0x6f,
+	0x62, 0x65, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0c, 0x70, 0x72, 0x6f, 0x62, 0x65, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x22, 0x92,
+	0x03, 0x0a, 0x0a, 0x57, 0x69, 0x66, 0x69, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x1a, 0x0a,
+	0x08, 0x77, 0x69, 0x66, 0x69, 0x53, 0x53, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x77, 0x69, 0x66, 0x69, 0x53, 0x53, 0x49, 0x44, 0x12, 0x42, 0x0a, 0x09, 0x6b, 0x65, 0x79,
+	0x53, 0x63, 0x68, 0x65, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x24, 0x2e, 0x6f,
+	0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x2e, 0x57, 0x69, 0x46, 0x69, 0x4b, 0x65, 0x79, 0x53, 0x63, 0x68, 0x65,
+	0x6d, 0x65, 0x52, 0x09, 0x6b, 0x65, 0x79, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x73,
+	0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x73,
+	0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x45, 0x0a, 0x06, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x18,
+	0x14, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64,
+	0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x57, 0x69,
+	0x66, 0x69, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x06, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x12, 0x1a, 0x0a, 0x08,
+	0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x19, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08,
+	0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x42, 0x0a, 0x0a, 0x63, 0x69, 0x70, 0x68,
+	0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x18, 0x1e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x6f,
+	0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x2e, 0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x52, 0x0a, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x1a, 0x45, 0x0a, 0x0b,
+	0x63, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x69,
+	0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69,
+	0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77,
+	0x6f, 0x72, 0x64, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77,
+	0x6f, 0x72, 0x64, 0x2a, 0x67, 0x0a, 0x0e, 0x44, 0x6f, 0x74, 0x31, 0x78, 0x45, 0x41, 0x50, 0x4d,
+	0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x20, 0x0a, 0x1c, 0x44, 0x4f, 0x54, 0x31, 0x58, 0x5f, 0x45,
+	0x41, 0x50, 0x5f, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43,
+	0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x18, 0x0a, 0x14, 0x44, 0x4f, 0x54, 0x31, 0x58,
+	0x5f, 0x45, 0x41, 0x50, 0x5f, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x54, 0x4c, 0x53, 0x10,
+	0x01, 0x12, 0x19, 0x0a, 0x15, 0x44, 0x4f, 0x54, 0x31, 0x58, 0x5f, 0x45, 0x41, 0x50, 0x5f, 0x4d,
+	0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x50, 0x45, 0x41, 0x50, 0x10, 0x02, 0x42, 0x3d, 0x0a, 0x15,
 	0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63,
-	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x57, 0x69, 0x66, 0x69, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
-	0x52, 0x07, 0x77, 0x69, 0x66, 0x69, 0x43, 0x66, 0x67, 0x22, 0x97, 0x01, 0x0a, 0x0e, 0x43, 0x65,
-	0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x10, 0x0a, 0x03,
-	0x41, 0x50, 0x4e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x41, 0x50, 0x4e, 0x12, 0x46,
-	0x0a, 0x05, 0x70, 0x72, 0x6f, 0x62, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x30, 0x2e,
-	0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63,
-	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x43, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x43, 0x6f,
-	0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x50, 0x72, 0x6f, 0x62, 0x65, 0x52,
-	0x05, 0x70, 0x72, 0x6f, 0x62, 0x65, 0x12, 0x2b, 0x0a, 0x11, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x5f, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x08, 0x52, 0x10, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x72, 0x61, 0x63, 0x6b,
-	0x69, 0x6e, 0x67, 0x22, 0x5a, 0x0a, 0x19, 0x43, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x43,
-	0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x50, 0x72, 0x6f, 0x62, 0x65,
-	0x12, 0x18, 0x0a, 0x07, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x08, 0x52, 0x07, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x72,
-	0x6f, 0x62, 0x65, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x0c, 0x70, 0x72, 0x6f, 0x62, 0x65, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x22,
-	0x92, 0x03, 0x0a, 0x0a, 0x57, 0x69, 0x66, 0x69, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x1a,
-	0x0a, 0x08, 0x77, 0x69, 0x66, 0x69, 0x53, 0x53, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x08, 0x77, 0x69, 0x66, 0x69, 0x53, 0x53, 0x49, 0x44, 0x12, 0x42, 0x0a, 0x09, 0x6b, 0x65,
-	0x79, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x24, 0x2e,
-	0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63,
-	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x57, 0x69, 0x46, 0x69, 0x4b, 0x65, 0x79, 0x53, 0x63, 0x68,
-	0x65, 0x6d, 0x65, 0x52, 0x09, 0x6b, 0x65, 0x79, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x65, 0x12, 0x1a,
-	0x0a, 0x08, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x08, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61,
-	0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61,
-	0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x45, 0x0a, 0x06, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f,
-	0x18, 0x14, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65,
-	0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x57,
-	0x69, 0x66, 0x69, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f,
-	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x06, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x12, 0x1a, 0x0a,
-	0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x19, 0x20, 0x01, 0x28, 0x05, 0x52,
-	0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x42, 0x0a, 0x0a, 0x63, 0x69, 0x70,
-	0x68, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x18, 0x1e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e,
-	0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63,
-	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x42, 0x6c, 0x6f, 0x63,
-	0x6b, 0x52, 0x0a, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x1a, 0x45, 0x0a,
-	0x0b, 0x63, 0x72, 0x79, 0x70, 0x74, 0x6f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x1a, 0x0a, 0x08,
-	0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
-	0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x73, 0x73,
-	0x77, 0x6f, 0x72, 0x64, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x73, 0x73,
-	0x77, 0x6f, 0x72, 0x64, 0x42, 0x3d, 0x0a, 0x15, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64,
-	0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5a, 0x24, 0x67,
-	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x66, 0x2d, 0x65, 0x64, 0x67,
-	0x65, 0x2f, 0x65, 0x76, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x6f, 0x2f, 0x63, 0x6f, 0x6e,
-	0x66, 0x69, 0x67, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5a, 0x24, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x6c, 0x66, 0x2d, 0x65, 0x64, 0x67, 0x65, 0x2f, 0x65, 0x76, 0x65, 0x2f, 0x61, 0x70,
+	0x69, 0x2f, 0x67, 0x6f, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
 }
 
 var (
@@ -722,43 +1174,58 @@ func file_config_netconfig_proto_rawDescGZIP() []byte {
 	return file_config_netconfig_proto_rawDescData
 }
 
-var file_config_netconfig_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_config_netconfig_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_config_netconfig_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_config_netconfig_proto_goTypes = []interface{}{
-	(*NetworkConfig)(nil),             // 0: org.lfedge.eve.config.NetworkConfig
-	(*NetworkAdapter)(nil),            // 1: org.lfedge.eve.config.NetworkAdapter
-	(*WirelessConfig)(nil),            // 2: org.lfedge.eve.config.WirelessConfig
-	(*CellularConfig)(nil),            // 3: org.lfedge.eve.config.CellularConfig
-	(*CellularConnectivityProbe)(nil), // 4: org.lfedge.eve.config.CellularConnectivityProbe
-	(*WifiConfig)(nil),                // 5: org.lfedge.eve.config.WifiConfig
-	(*WifiConfigCryptoblock)(nil),     // 6: org.lfedge.eve.config.WifiConfig.cryptoblock
-	(NetworkType)(0),                  // 7: org.lfedge.eve.config.NetworkType
-	(*Ipspec)(nil),                    // 8: org.lfedge.eve.config.ipspec
-	(*ZnetStaticDNSEntry)(nil),        // 9: org.lfedge.eve.config.ZnetStaticDNSEntry
-	(*ProxyConfig)(nil),               // 10: org.lfedge.eve.config.ProxyConfig
-	(*ACE)(nil),                       // 11: org.lfedge.eve.config.ACE
-	(WirelessType)(0),                 // 12: org.lfedge.eve.config.WirelessType
-	(WiFiKeyScheme)(0),                // 13: org.lfedge.eve.config.WiFiKeyScheme
-	(*CipherBlock)(nil),               // 14: org.lfedge.eve.config.CipherBlock
+	(Dot1XEAPMethod)(0),               // 0: org.lfedge.eve.config.Dot1xEAPMethod
+	(*NetworkConfig)(nil),             // 1: org.lfedge.eve.config.NetworkConfig
+	(*Dot1XConfig)(nil),               // 2: org.lfedge.eve.config.Dot1xConfig
+	(*StaticIPRoute)(nil),             // 3: org.lfedge.eve.config.StaticIPRoute
+	(*IPPolicyRule)(nil),              // 4: org.lfedge.eve.config.IPPolicyRule
+	(*NetworkAdapter)(nil),            // 5: org.lfedge.eve.config.NetworkAdapter
+	(*WirelessConfig)(nil),            // 6: org.lfedge.eve.config.WirelessConfig
+	(*CellularConfig)(nil),            // 7: org.lfedge.eve.config.CellularConfig
+	(*CellularConnectivityProbe)(nil), // 8: org.lfedge.eve.config.CellularConnectivityProbe
+	(*WifiConfig)(nil),                // 9: org.lfedge.eve.config.WifiConfig
+	(*WifiConfigCryptoblock)(nil),     // 10: org.lfedge.eve.config.WifiConfig.cryptoblock
+	(NetworkType)(0),                  // 11: org.lfedge.eve.config.NetworkType
+	(*Ipspec)(nil),                    // 12: org.lfedge.eve.config.ipspec
+	(*ZnetStaticDNSEntry)(nil),        // 13: org.lfedge.eve.config.ZnetStaticDNSEntry
+	(*ProxyConfig)(nil),               // 14: org.lfedge.eve.config.ProxyConfig
+	(*CipherBlock)(nil),               // 15: org.lfedge.eve.config.CipherBlock
+	(*ACE)(nil),                       // 16: org.lfedge.eve.config.ACE
+	(WirelessType)(0),                 // 17: org.lfedge.eve.config.WirelessType
+	(CellularIPType)(0),               // 18: org.lfedge.eve.config.CellularIPType
+	(CellularAuthProtocol)(0),         // 19: org.lfedge.eve.config.CellularAuthProtocol
+	(WiFiKeyScheme)(0),                // 20: org.lfedge.eve.config.WiFiKeyScheme
 }
 var file_config_netconfig_proto_depIdxs = []int32{
-	7,  // 0: org.lfedge.eve.config.NetworkConfig.type:type_name -> org.lfedge.eve.config.NetworkType
-	8,  // 1: org.lfedge.eve.config.NetworkConfig.ip:type_name -> org.lfedge.eve.config.ipspec
-	9,  // 2: org.lfedge.eve.config.NetworkConfig.dns:type_name -> org.lfedge.eve.config.ZnetStaticDNSEntry
-	10, // 3: org.lfedge.eve.config.NetworkConfig.entProxy:type_name -> org.lfedge.eve.config.ProxyConfig
-	2,  // 4: org.lfedge.eve.config.NetworkConfig.wireless:type_name -> org.lfedge.eve.config.WirelessConfig
-	11, // 5: org.lfedge.eve.config.NetworkAdapter.acls:type_name -> org.lfedge.eve.config.ACE
-	12, // 6: org.lfedge.eve.config.WirelessConfig.type:type_name -> org.lfedge.eve.config.WirelessType
-	3,  // 7: org.lfedge.eve.config.WirelessConfig.cellularCfg:type_name -> org.lfedge.eve.config.CellularConfig
-	5,  // 8: org.lfedge.eve.config.WirelessConfig.wifiCfg:type_name -> org.lfedge.eve.config.WifiConfig
-	4,  // 9: org.lfedge.eve.config.CellularConfig.probe:type_name -> org.lfedge.eve.config.CellularConnectivityProbe
-	13, // 10: org.lfedge.eve.config.WifiConfig.keyScheme:type_name -> org.lfedge.eve.config.WiFiKeyScheme
-	6,  // 11: org.lfedge.eve.config.WifiConfig.crypto:type_name -> org.lfedge.eve.config.WifiConfig.cryptoblock
-	14, // 12: org.lfedge.eve.config.WifiConfig.cipherData:type_name -> org.lfedge.eve.config.CipherBlock
-	13, // [13:13] is the sub-list for method output_type
-	13, // [13:13] is the sub-list for method input_type
-	13, // [13:13] is the sub-list for extension type_name
-	13, // [13:13] is the sub-list for extension extendee
-	0,  // [0:13] is the sub-list for field type_name
+	11, // 0: org.lfedge.eve.config.NetworkConfig.type:type_name -> org.lfedge.eve.config.NetworkType
+	12, // 1: org.lfedge.eve.config.NetworkConfig.ip:type_name -> org.lfedge.eve.config.ipspec
+	13, // 2: org.lfedge.eve.config.NetworkConfig.dns:type_name -> org.lfedge.eve.config.ZnetStaticDNSEntry
+	14, // 3: org.lfedge.eve.config.NetworkConfig.entProxy:type_name -> org.lfedge.eve.config.ProxyConfig
+	6,  // 4: org.lfedge.eve.config.NetworkConfig.wireless:type_name -> org.lfedge.eve.config.WirelessConfig
+	3,  // 5: org.lfedge.eve.config.NetworkConfig.static_routes:type_name -> org.lfedge.eve.config.StaticIPRoute
+	4,  // 6: org.lfedge.eve.config.NetworkConfig.policy_rules:type_name -> org.lfedge.eve.config.IPPolicyRule
+	2,  // 7: org.lfedge.eve.config.NetworkConfig.dot1x:type_name -> org.lfedge.eve.config.Dot1xConfig
+	0,  // 8: org.lfedge.eve.config.Dot1xConfig.eap_method:type_name -> org.lfedge.eve.config.Dot1xEAPMethod
+	15, // 9: org.lfedge.eve.config.Dot1xConfig.cipher_data:type_name -> org.lfedge.eve.config.CipherBlock
+	16, // 10: org.lfedge.eve.config.NetworkAdapter.acls:type_name -> org.lfedge.eve.config.ACE
+	17, // 11: org.lfedge.eve.config.WirelessConfig.type:type_name -> org.lfedge.eve.config.WirelessType
+	7,  // 12: org.lfedge.eve.config.WirelessConfig.cellularCfg:type_name -> org.lfedge.eve.config.CellularConfig
+	9,  // 13: org.lfedge.eve.config.WirelessConfig.wifiCfg:type_name -> org.lfedge.eve.config.WifiConfig
+	8,  // 14: org.lfedge.eve.config.CellularConfig.probe:type_name -> org.lfedge.eve.config.CellularConnectivityProbe
+	18, // 15: org.lfedge.eve.config.CellularConfig.ip_type:type_name -> org.lfedge.eve.config.CellularIPType
+	19, // 16: org.lfedge.eve.config.CellularConfig.auth_protocol:type_name -> org.lfedge.eve.config.CellularAuthProtocol
+	15, // 17: org.lfedge.eve.config.CellularConfig.cipher_data:type_name -> org.lfedge.eve.config.CipherBlock
+	20, // 18: org.lfedge.eve.config.WifiConfig.keyScheme:type_name -> org.lfedge.eve.config.WiFiKeyScheme
+	10, // 19: org.lfedge.eve.config.WifiConfig.crypto:type_name -> org.lfedge.eve.config.WifiConfig.cryptoblock
+	15, // 20: org.lfedge.eve.config.WifiConfig.cipherData:type_name -> org.lfedge.eve.config.CipherBlock
+	21, // [21:21] is the sub-list for method output_type
+	21, // [21:21] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
 }
 
 func init() { file_config_netconfig_proto_init() }
@@ -783,7 +1250,7 @@ func file_config_netconfig_proto_init() {
 			}
 		}
 		file_config_netconfig_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*NetworkAdapter); i {
+			switch v := v.(*Dot1XConfig); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -795,7 +1262,7 @@ func file_config_netconfig_proto_init() {
 			}
 		}
 		file_config_netconfig_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WirelessConfig); i {
+			switch v := v.(*StaticIPRoute); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -807,7 +1274,7 @@ func file_config_netconfig_proto_init() {
 			}
 		}
 		file_config_netconfig_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CellularConfig); i {
+			switch v := v.(*IPPolicyRule); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -819,7 +1286,7 @@ func file_config_netconfig_proto_init() {
 			}
 		}
 		file_config_netconfig_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CellularConnectivityProbe); i {
+			switch v := v.(*NetworkAdapter); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -831,7 +1298,7 @@ func file_config_netconfig_proto_init() {
 			}
 		}
 		file_config_netconfig_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WifiConfig); i {
+			switch v := v.(*WirelessConfig); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -843,6 +1310,42 @@ func file_config_netconfig_proto_init() {
 			}
 		}
 		file_config_netconfig_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CellularConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_config_netconfig_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CellularConnectivityProbe); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_config_netconfig_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WifiConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_config_netconfig_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*WifiConfigCryptoblock); i {
 			case 0:
 				return &v.state
@@ -860,13 +1363,14 @@ func file_config_netconfig_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_config_netconfig_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   7,
+			NumEnums:      1,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_config_netconfig_proto_goTypes,
 		DependencyIndexes: file_config_netconfig_proto_depIdxs,
+		EnumInfos:         file_config_netconfig_proto_enumTypes,
 		MessageInfos:      file_config_netconfig_proto_msgTypes,
 	}.Build()
 	File_config_netconfig_proto = out.File
@@ -38,6 +38,25 @@ const (
 	// A subsequent action to start the app will start it with a pristine runtime state.
 	// This command will purge ALL volumes used by the application.
 	AppCommand_COMMAND_PURGE AppCommand_Command = 2
+	// Application instance will be stopped and will remain stopped, even across
+	// device reboots and config changes made by the controller, until COMMAND_START
+	// is requested. Volumes and the mutated run time state are preserved.
+	AppCommand_COMMAND_STOP AppCommand_Command = 3
+	// Application instance previously stopped by COMMAND_STOP will be started again,
+	// unless it is deactivated by the controller or by the current local profile.
+	// The command has no effect for application instances not stopped locally.
+	AppCommand_COMMAND_START AppCommand_Command = 4
+	// Replace the environment variables set by the Local profile server for
+	// the application instance with env_variables, and restart the application
+	// instance to apply them. Variables set this way override those with the same
+	// name from the cloud-init user data. An empty env_variables removes all
+	// variables previously set by the Local profile server.
+	// Only applicable to container application instances, for other application
+	// instances the command is rejected and the error is reported in LocalAppInfo.
+	AppCommand_COMMAND_SET_ENV AppCommand_Command = 5
+	// EVE will submit the last max_console_log_lines lines of console output
+	// of the application instance in the request body of the api/v1/appconsolelog API.
+	AppCommand_COMMAND_CONSOLE_LOG AppCommand_Command = 6
 )
 
 // Enum value maps for AppCommand_Command.
@@ -46,11 +65,19 @@ var (
 		0: "COMMAND_UNSPECIFIED",
 		1: "COMMAND_RESTART",
 		2: "COMMAND_PURGE",
+		3: "COMMAND_STOP",
+		4: "COMMAND_START",
+		5: "COMMAND_SET_ENV",
+		6: "COMMAND_CONSOLE_LOG",
 	}
 	AppCommand_Command_value = map[string]int32{
 		"COMMAND_UNSPECIFIED": 0,
 		"COMMAND_RESTART":     1,
 		"COMMAND_PURGE":       2,
+		"COMMAND_STOP":        3,
+		"COMMAND_START":       4,
+		"COMMAND_SET_ENV":     5,
+		"COMMAND_CONSOLE_LOG": 6,
 	}
 )
 
@@ -81,6 +108,56 @@ func (AppCommand_Command) EnumDescriptor() ([]byte, []int) {
 	return file_profile_local_profile_proto_rawDescGZIP(), []int{7, 0}
 }
 
+type VolumeCommand_Command int32
+
+const (
+	VolumeCommand_COMMAND_UNSPECIFIED VolumeCommand_Command = 0
+	// Take a snapshot of the volume, replacing the previous snapshot taken
+	// on request of the Local profile server, if any. Snapshots of volumes
+	// used by running application instances are crash-consistent at best.
+	// Snapshots are not supported for container volumes.
+	VolumeCommand_COMMAND_SNAPSHOT VolumeCommand_Command = 1
+)
+
+// Enum value maps for VolumeCommand_Command.
+var (
+	VolumeCommand_Command_name = map[int32]string{
+		0: "COMMAND_UNSPECIFIED",
+		1: "COMMAND_SNAPSHOT",
+	}
+	VolumeCommand_Command_value = map[string]int32{
+		"COMMAND_UNSPECIFIED": 0,
+		"COMMAND_SNAPSHOT":    1,
+	}
+)
+
+func (x VolumeCommand_Command) Enum() *VolumeCommand_Command {
+	p := new(VolumeCommand_Command)
+	*p = x
+	return p
+}
+
+func (x VolumeCommand_Command) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (VolumeCommand_Command) Descriptor() protoreflect.EnumDescriptor {
+	return file_profile_local_profile_proto_enumTypes[1].Descriptor()
+}
+
+func (VolumeCommand_Command) Type() protoreflect.EnumType {
+	return &file_profile_local_profile_proto_enumTypes[1]
+}
+
+func (x VolumeCommand_Command) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use VolumeCommand_Command.Descriptor instead.
+func (VolumeCommand_Command) EnumDescriptor() ([]byte, []int) {
+	return file_profile_local_profile_proto_rawDescGZIP(), []int{12, 0}
+}
+
 type LocalDevCmd_Command int32
 
 const (
@@ -125,11 +202,11 @@ func (x LocalDevCmd_Command) String() string {
 }
 
 func (LocalDevCmd_Command) Descriptor() protoreflect.EnumDescriptor {
-	return file_profile_local_profile_proto_enumTypes[1].Descriptor()
+	return file_profile_local_profile_proto_enumTypes[2].Descriptor()
 }
 
 func (LocalDevCmd_Command) Type() protoreflect.EnumType {
-	return &file_profile_local_profile_proto_enumTypes[1]
+	return &file_profile_local_profile_proto_enumTypes[2]
 }
 
 func (x LocalDevCmd_Command) Number() protoreflect.EnumNumber {
@@ -138,7 +215,122 @@ func (x LocalDevCmd_Command) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use LocalDevCmd_Command.Descriptor instead.
 func (LocalDevCmd_Command) EnumDescriptor() ([]byte, []int) {
-	return file_profile_local_profile_proto_rawDescGZIP(), []int{9, 0}
+	return file_profile_local_profile_proto_rawDescGZIP(), []int{14, 0}
+}
+
+type LocalBundleStatus_State int32
+
+const (
+	LocalBundleStatus_STATE_UNSPECIFIED LocalBundleStatus_State = 0
+	// The images of the bundle are being copied from the local server
+	LocalBundleStatus_STATE_DOWNLOADING LocalBundleStatus_State = 1
+	// The bundle is verified and waits to be applied, which happens
+	// only while the controller is not reachable
+	LocalBundleStatus_STATE_STAGED LocalBundleStatus_State = 2
+	// The configuration of the bundle is applied; the state of the
+	// base OS and app instances is reported using api/v1/devinfo
+	// and api/v1/appinfo
+	LocalBundleStatus_STATE_APPLIED LocalBundleStatus_State = 3
+	// The bundle was rejected; see error
+	LocalBundleStatus_STATE_FAILED LocalBundleStatus_State = 4
+)
+
+// Enum value maps for LocalBundleStatus_State.
+var (
+	LocalBundleStatus_State_name = map[int32]string{
+		0: "STATE_UNSPECIFIED",
+		1: "STATE_DOWNLOADING",
+		2: "STATE_STAGED",
+		3: "STATE_APPLIED",
+		4: "STATE_FAILED",
+	}
+	LocalBundleStatus_State_value = map[string]int32{
+		"STATE_UNSPECIFIED": 0,
+		"STATE_DOWNLOADING": 1,
+		"STATE_STAGED":      2,
+		"STATE_APPLIED":     3,
+		"STATE_FAILED":      4,
+	}
+)
+
+func (x LocalBundleStatus_State) Enum() *LocalBundleStatus_State {
+	p := new(LocalBundleStatus_State)
+	*p = x
+	return p
+}
+
+func (x LocalBundleStatus_State) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LocalBundleStatus_State) Descriptor() protoreflect.EnumDescriptor {
+	return file_profile_local_profile_proto_enumTypes[3].Descriptor()
+}
+
+func (LocalBundleStatus_State) Type() protoreflect.EnumType {
+	return &file_profile_local_profile_proto_enumTypes[3]
+}
+
+func (x LocalBundleStatus_State) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LocalBundleStatus_State.Descriptor instead.
+func (LocalBundleStatus_State) EnumDescriptor() ([]byte, []int) {
+	return file_profile_local_profile_proto_rawDescGZIP(), []int{16, 0}
+}
+
+type LocalCheckpointCmd_Command int32
+
+const (
+	LocalCheckpointCmd_COMMAND_UNSPECIFIED LocalCheckpointCmd_Command = 0
+	// Decode and verify the checkpoint, reporting the result in
+	// 'inspection' of `LocalCheckpointInfo`.
+	LocalCheckpointCmd_COMMAND_INSPECT LocalCheckpointCmd_Command = 1
+	// Verify the checkpoint and make it the config checkpoint of the
+	// edge node.
+	LocalCheckpointCmd_COMMAND_IMPORT LocalCheckpointCmd_Command = 2
+)
+
+// Enum value maps for LocalCheckpointCmd_Command.
+var (
+	LocalCheckpointCmd_Command_name = map[int32]string{
+		0: "COMMAND_UNSPECIFIED",
+		1: "COMMAND_INSPECT",
+		2: "COMMAND_IMPORT",
+	}
+	LocalCheckpointCmd_Command_value = map[string]int32{
+		"COMMAND_UNSPECIFIED": 0,
+		"COMMAND_INSPECT":     1,
+		"COMMAND_IMPORT":      2,
+	}
+)
+
+func (x LocalCheckpointCmd_Command) Enum() *LocalCheckpointCmd_Command {
+	p := new(LocalCheckpointCmd_Command)
+	*p = x
+	return p
+}
+
+func (x LocalCheckpointCmd_Command) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LocalCheckpointCmd_Command) Descriptor() protoreflect.EnumDescriptor {
+	return file_profile_local_profile_proto_enumTypes[4].Descriptor()
+}
+
+func (LocalCheckpointCmd_Command) Type() protoreflect.EnumType {
+	return &file_profile_local_profile_proto_enumTypes[4]
+}
+
+func (x LocalCheckpointCmd_Command) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LocalCheckpointCmd_Command.Descriptor instead.
+func (LocalCheckpointCmd_Command) EnumDescriptor() ([]byte, []int) {
+	return file_profile_local_profile_proto_rawDescGZIP(), []int{18, 0}
 }
 
 // LocalProfile message is sent in response to a GET to
@@ -492,6 +684,9 @@ type LocalAppInfo struct {
 	// requested by the Local profile server, received by EVE and has completed
 	// its execution for this application instance.
 	LastCmdTimestamp uint64 `protobuf:"varint,6,opt,name=last_cmd_timestamp,json=lastCmdTimestamp,proto3" json:"last_cmd_timestamp,omitempty"`
+	// True if the application instance was stopped by COMMAND_STOP
+	// and not started again by COMMAND_START.
+	StoppedLocally bool `protobuf:"varint,7,opt,name=stopped_locally,json=stoppedLocally,proto3" json:"stopped_locally,omitempty"`
 }
 
 func (x *LocalAppInfo) Reset() {
@@ -568,6 +763,13 @@ func (x *LocalAppInfo) GetLastCmdTimestamp() uint64 {
 	return 0
 }
 
+func (x *LocalAppInfo) GetStoppedLocally() bool {
+	if x != nil {
+		return x.StoppedLocally
+	}
+	return false
+}
+
 // LocalAppCmdList message may be returned in the response from a POST request
 // sent to the api/v1/appinfo API.
 type LocalAppCmdList struct {
@@ -671,6 +873,11 @@ type AppCommand struct {
 	Timestamp uint64 `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
 	// Command to run.
 	Command AppCommand_Command `protobuf:"varint,4,opt,name=command,proto3,enum=org.lfedge.eve.profile.AppCommand_Command" json:"command,omitempty"`
+	// Environment variables for COMMAND_SET_ENV.
+	EnvVariables map[string]string `protobuf:"bytes,5,rep,name=env_variables,json=envVariables,proto3" json:"env_variables,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Maximum number of lines to submit for COMMAND_CONSOLE_LOG.
+	// Zero means the default of 100 lines. At most 1000 lines are submitted.
+	MaxConsoleLogLines uint32 `protobuf:"varint,6,opt,name=max_console_log_lines,json=maxConsoleLogLines,proto3" json:"max_console_log_lines,omitempty"`
 }
 
 func (x *AppCommand) Reset() {
@@ -733,26 +940,40 @@ func (x *AppCommand) GetCommand() AppCommand_Command {
 	return AppCommand_COMMAND_UNSPECIFIED
 }
 
-// LocalDevInfo contains information about an EdgeNode
-// sent to the api/v1/devinfo
-type LocalDevInfo struct {
+func (x *AppCommand) GetEnvVariables() map[string]string {
+	if x != nil {
+		return x.EnvVariables
+	}
+	return nil
+}
+
+func (x *AppCommand) GetMaxConsoleLogLines() uint32 {
+	if x != nil {
+		return x.MaxConsoleLogLines
+	}
+	return 0
+}
+
+// LocalAppConsoleLog message is sent in the POST request to the api/v1/appconsolelog API
+// in response to COMMAND_CONSOLE_LOG.
+type LocalAppConsoleLog struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	DeviceUuid             string                       `protobuf:"bytes,1,opt,name=device_uuid,json=deviceUuid,proto3" json:"device_uuid,omitempty"`
-	State                  info.ZDeviceState            `protobuf:"varint,2,opt,name=state,proto3,enum=org.lfedge.eve.info.ZDeviceState" json:"state,omitempty"`
-	MaintenanceModeReasons []info.MaintenanceModeReason `protobuf:"varint,3,rep,packed,name=maintenance_mode_reasons,json=maintenanceModeReasons,proto3,enum=org.lfedge.eve.info.MaintenanceModeReason" json:"maintenance_mode_reasons,omitempty"`
-	BootTime               *timestamppb.Timestamp       `protobuf:"bytes,4,opt,name=boot_time,json=bootTime,proto3" json:"boot_time,omitempty"`
-	LastBootReason         info.BootReason              `protobuf:"varint,5,opt,name=last_boot_reason,json=lastBootReason,proto3,enum=org.lfedge.eve.info.BootReason" json:"last_boot_reason,omitempty"`
-	// Value of the field `timestamp` from the last `DevCommand` that was
-	// requested by the Local profile server, received by EVE and has completed
-	// its execution for this edge node.
-	LastCmdTimestamp uint64 `protobuf:"varint,10,opt,name=last_cmd_timestamp,json=lastCmdTimestamp,proto3" json:"last_cmd_timestamp,omitempty"`
+	Id   string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Value of the field `timestamp` from the `AppCommand` which requested the console log.
+	CmdTimestamp uint64 `protobuf:"varint,3,opt,name=cmd_timestamp,json=cmdTimestamp,proto3" json:"cmd_timestamp,omitempty"`
+	// Lines of console output (including stdout and stderr of containers), oldest first.
+	// Only output kept on the device by the log service is available.
+	Lines []string `protobuf:"bytes,4,rep,name=lines,proto3" json:"lines,omitempty"`
+	// Set if the console output could not be read.
+	Error string `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
 }
 
-func (x *LocalDevInfo) Reset() {
-	*x = LocalDevInfo{}
+func (x *LocalAppConsoleLog) Reset() {
+	*x = LocalAppConsoleLog{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_profile_local_profile_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -760,13 +981,13 @@ func (x *LocalDevInfo) Reset() {
 	}
 }
 
-func (x *LocalDevInfo) String() string {
+func (x *LocalAppConsoleLog) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LocalDevInfo) ProtoMessage() {}
+func (*LocalAppConsoleLog) ProtoMessage() {}
 
-func (x *LocalDevInfo) ProtoReflect() protoreflect.Message {
+func (x *LocalAppConsoleLog) ProtoReflect() protoreflect.Message {
 	mi := &file_profile_local_profile_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -778,85 +999,136 @@ func (x *LocalDevInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LocalDevInfo.ProtoReflect.Descriptor instead.
-func (*LocalDevInfo) Descriptor() ([]byte, []int) {
+// Deprecated: Use LocalAppConsoleLog.ProtoReflect.Descriptor instead.
+func (*LocalAppConsoleLog) Descriptor() ([]byte, []int) {
 	return file_profile_local_profile_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *LocalDevInfo) GetDeviceUuid() string {
+func (x *LocalAppConsoleLog) GetId() string {
 	if x != nil {
-		return x.DeviceUuid
+		return x.Id
 	}
 	return ""
 }
 
-func (x *LocalDevInfo) GetState() info.ZDeviceState {
+func (x *LocalAppConsoleLog) GetName() string {
 	if x != nil {
-		return x.State
+		return x.Name
 	}
-	return info.ZDeviceState_ZDEVICE_STATE_UNSPECIFIED
+	return ""
 }
 
-func (x *LocalDevInfo) GetMaintenanceModeReasons() []info.MaintenanceModeReason {
+func (x *LocalAppConsoleLog) GetCmdTimestamp() uint64 {
 	if x != nil {
-		return x.MaintenanceModeReasons
+		return x.CmdTimestamp
 	}
-	return nil
+	return 0
 }
 
-func (x *LocalDevInfo) GetBootTime() *timestamppb.Timestamp {
+func (x *LocalAppConsoleLog) GetLines() []string {
 	if x != nil {
-		return x.BootTime
+		return x.Lines
 	}
 	return nil
 }
 
-func (x *LocalDevInfo) GetLastBootReason() info.BootReason {
+func (x *LocalAppConsoleLog) GetError() string {
 	if x != nil {
-		return x.LastBootReason
+		return x.Error
 	}
-	return info.BootReason_BOOT_REASON_UNSPECIFIED
+	return ""
 }
 
-func (x *LocalDevInfo) GetLastCmdTimestamp() uint64 {
+// LocalVolumeInfoList contains information about all volumes on EdgeNode
+// sent to the api/v1/volumeinfo
+type LocalVolumeInfoList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VolumesInfo []*LocalVolumeInfo `protobuf:"bytes,1,rep,name=volumes_info,json=volumesInfo,proto3" json:"volumes_info,omitempty"`
+}
+
+func (x *LocalVolumeInfoList) Reset() {
+	*x = LocalVolumeInfoList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_profile_local_profile_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LocalVolumeInfoList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocalVolumeInfoList) ProtoMessage() {}
+
+func (x *LocalVolumeInfoList) ProtoReflect() protoreflect.Message {
+	mi := &file_profile_local_profile_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocalVolumeInfoList.ProtoReflect.Descriptor instead.
+func (*LocalVolumeInfoList) Descriptor() ([]byte, []int) {
+	return file_profile_local_profile_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *LocalVolumeInfoList) GetVolumesInfo() []*LocalVolumeInfo {
 	if x != nil {
-		return x.LastCmdTimestamp
+		return x.VolumesInfo
 	}
-	return 0
+	return nil
 }
 
-// LocalDevCmd message may be returned in the response from a POST request
-// sent to the api/v1/devinfo API.
-type LocalDevCmd struct {
+// LocalVolumeInfo contains information about volume on EdgeNode
+type LocalVolumeInfo struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// Security token. EVE will verify that server_token matches the profile server
-	// token received from the controller.
-	ServerToken string `protobuf:"bytes,1,opt,name=server_token,json=serverToken,proto3" json:"server_token,omitempty"`
-	Timestamp   uint64 `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	// Command to run.
-	Command LocalDevCmd_Command `protobuf:"varint,3,opt,name=command,proto3,enum=org.lfedge.eve.profile.LocalDevCmd_Command" json:"command,omitempty"`
+	Id    string          `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string          `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Err   *info.ErrorInfo `protobuf:"bytes,3,opt,name=err,proto3" json:"err,omitempty"`
+	State info.ZSwState   `protobuf:"varint,4,opt,name=state,proto3,enum=org.lfedge.eve.info.ZSwState" json:"state,omitempty"`
+	// Maximum size of the volume.
+	MaxSizeBytes uint64 `protobuf:"varint,5,opt,name=max_size_bytes,json=maxSizeBytes,proto3" json:"max_size_bytes,omitempty"`
+	// Space used by the volume.
+	UsedBytes uint64 `protobuf:"varint,6,opt,name=used_bytes,json=usedBytes,proto3" json:"used_bytes,omitempty"`
+	// Value of the field `timestamp` from the last `VolumeCommand` that was
+	// requested by the Local profile server, received by EVE and has completed
+	// its execution for this volume.
+	LastCmdTimestamp uint64 `protobuf:"varint,7,opt,name=last_cmd_timestamp,json=lastCmdTimestamp,proto3" json:"last_cmd_timestamp,omitempty"`
+	// Time when the last snapshot requested by the Local profile server was taken.
+	LastSnapshotTime *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=last_snapshot_time,json=lastSnapshotTime,proto3" json:"last_snapshot_time,omitempty"`
+	// Set if the last snapshot requested by the Local profile server failed.
+	SnapshotError string `protobuf:"bytes,9,opt,name=snapshot_error,json=snapshotError,proto3" json:"snapshot_error,omitempty"`
 }
 
-func (x *LocalDevCmd) Reset() {
-	*x = LocalDevCmd{}
+func (x *LocalVolumeInfo) Reset() {
+	*x = LocalVolumeInfo{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_profile_local_profile_proto_msgTypes[9]
+		mi := &file_profile_local_profile_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *LocalDevCmd) String() string {
+func (x *LocalVolumeInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LocalDevCmd) ProtoMessage() {}
+func (*LocalVolumeInfo) ProtoMessage() {}
 
-func (x *LocalDevCmd) ProtoReflect() protoreflect.Message {
-	mi := &file_profile_local_profile_proto_msgTypes[9]
+func (x *LocalVolumeInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_profile_local_profile_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -867,67 +1139,722 @@ func (x *LocalDevCmd) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LocalDevCmd.ProtoReflect.Descriptor instead.
-func (*LocalDevCmd) Descriptor() ([]byte, []int) {
-	return file_profile_local_profile_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use LocalVolumeInfo.ProtoReflect.Descriptor instead.
+func (*LocalVolumeInfo) Descriptor() ([]byte, []int) {
+	return file_profile_local_profile_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *LocalDevCmd) GetServerToken() string {
+func (x *LocalVolumeInfo) GetId() string {
 	if x != nil {
-		return x.ServerToken
+		return x.Id
 	}
 	return ""
 }
 
-func (x *LocalDevCmd) GetTimestamp() uint64 {
+func (x *LocalVolumeInfo) GetName() string {
 	if x != nil {
-		return x.Timestamp
+		return x.Name
+	}
+	return ""
+}
+
+func (x *LocalVolumeInfo) GetErr() *info.ErrorInfo {
+	if x != nil {
+		return x.Err
+	}
+	return nil
+}
+
+func (x *LocalVolumeInfo) GetState() info.ZSwState {
+	if x != nil {
+		return x.State
+	}
+	return info.ZSwState_INVALID
+}
+
+func (x *LocalVolumeInfo) GetMaxSizeBytes() uint64 {
+	if x != nil {
+		return x.MaxSizeBytes
 	}
 	return 0
 }
 
-func (x *LocalDevCmd) GetCommand() LocalDevCmd_Command {
+func (x *LocalVolumeInfo) GetUsedBytes() uint64 {
 	if x != nil {
-		return x.Command
+		return x.UsedBytes
 	}
-	return LocalDevCmd_COMMAND_UNSPECIFIED
+	return 0
 }
 
-var File_profile_local_profile_proto protoreflect.FileDescriptor
+func (x *LocalVolumeInfo) GetLastCmdTimestamp() uint64 {
+	if x != nil {
+		return x.LastCmdTimestamp
+	}
+	return 0
+}
 
-var file_profile_local_profile_proto_rawDesc = []byte{
-	0x0a, 0x1b, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x2f, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x5f,
-	0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x16, 0x6f,
-	0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x70, 0x72,
-	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x1a, 0x0f, 0x69, 0x6e, 0x66, 0x6f, 0x2f, 0x69, 0x6e, 0x66, 0x6f,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x15, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2f,
-	0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67,
-	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74,
-	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x56,
-	0x0a, 0x0c, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x23,
-	0x0a, 0x0d, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x5f, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x50, 0x72, 0x6f, 0x66,
-	0x69, 0x6c, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x74, 0x6f,
-	0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x73, 0x65, 0x72, 0x76, 0x65,
-	0x72, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0xf9, 0x01, 0x0a, 0x0b, 0x52, 0x61, 0x64, 0x69, 0x6f,
-	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x61, 0x64, 0x69, 0x6f, 0x5f,
-	0x73, 0x69, 0x6c, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x72,
-	0x61, 0x64, 0x69, 0x6f, 0x53, 0x69, 0x6c, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x63,
-	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x4f,
-	0x0a, 0x0f, 0x63, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75,
-	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66,
-	0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65,
-	0x2e, 0x43, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
-	0x0e, 0x63, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
-	0x51, 0x0a, 0x10, 0x63, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x5f, 0x6d, 0x65, 0x74, 0x72,
-	0x69, 0x63, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x6f, 0x72, 0x67, 0x2e,
-	0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72, 0x69,
-	0x63, 0x73, 0x2e, 0x43, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x4d, 0x65, 0x74, 0x72, 0x69,
-	0x63, 0x52, 0x0f, 0x63, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x4d, 0x65, 0x74, 0x72, 0x69,
-	0x63, 0x73, 0x22, 0xc0, 0x02, 0x0a, 0x0e, 0x43, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x53,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x22, 0x0a, 0x0c, 0x6c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c,
-	0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6c, 0x6f, 0x67,
+func (x *LocalVolumeInfo) GetLastSnapshotTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSnapshotTime
+	}
+	return nil
+}
+
+func (x *LocalVolumeInfo) GetSnapshotError() string {
+	if x != nil {
+		return x.SnapshotError
+	}
+	return ""
+}
+
+// LocalVolumeCmdList message may be returned in the response from a POST request
+// sent to the api/v1/volumeinfo API.
+type LocalVolumeCmdList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Security token. EVE will verify that server_token matches the profile server
+	// token received from the controller.
+	ServerToken string `protobuf:"bytes,1,opt,name=server_token,json=serverToken,proto3" json:"server_token,omitempty"`
+	// A list of commands requested to be executed for volumes.
+	// The list should contain at most one entry for each volume, and the semantics
+	// of the 'timestamp' field is the same as for LocalAppCmdList: to check if
+	// the last requested command has completed, compare its timestamp with
+	// 'last_cmd_timestamp' from `LocalVolumeInfo` message, submitted by EVE in
+	// the request body of the api/v1/volumeinfo API.
+	VolumeCommands []*VolumeCommand `protobuf:"bytes,2,rep,name=volume_commands,json=volumeCommands,proto3" json:"volume_commands,omitempty"`
+}
+
+func (x *LocalVolumeCmdList) Reset() {
+	*x = LocalVolumeCmdList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_profile_local_profile_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LocalVolumeCmdList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocalVolumeCmdList) ProtoMessage() {}
+
+func (x *LocalVolumeCmdList) ProtoReflect() protoreflect.Message {
+	mi := &file_profile_local_profile_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocalVolumeCmdList.ProtoReflect.Descriptor instead.
+func (*LocalVolumeCmdList) Descriptor() ([]byte, []int) {
+	return file_profile_local_profile_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *LocalVolumeCmdList) GetServerToken() string {
+	if x != nil {
+		return x.ServerToken
+	}
+	return ""
+}
+
+func (x *LocalVolumeCmdList) GetVolumeCommands() []*VolumeCommand {
+	if x != nil {
+		return x.VolumeCommands
+	}
+	return nil
+}
+
+// VolumeCommand references a volume by UUID and/or displayname,
+// and describes a command to execute for this volume.
+type VolumeCommand struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Reference the volume by its ID (which is an instance of UUID).
+	// At least one of the id and displayname should be defined.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Reference the volume by the user-friendly displayname.
+	// At least one of the id and displayname should be defined.
+	Displayname string `protobuf:"bytes,2,opt,name=displayname,proto3" json:"displayname,omitempty"`
+	// Timestamp to record when the request to run the command was made.
+	// See AppCommand.timestamp for the requirements.
+	Timestamp uint64 `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Command to run.
+	Command VolumeCommand_Command `protobuf:"varint,4,opt,name=command,proto3,enum=org.lfedge.eve.profile.VolumeCommand_Command" json:"command,omitempty"`
+}
+
+func (x *VolumeCommand) Reset() {
+	*x = VolumeCommand{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_profile_local_profile_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VolumeCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VolumeCommand) ProtoMessage() {}
+
+func (x *VolumeCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_profile_local_profile_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VolumeCommand.ProtoReflect.Descriptor instead.
+func (*VolumeCommand) Descriptor() ([]byte, []int) {
+	return file_profile_local_profile_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *VolumeCommand) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *VolumeCommand) GetDisplayname() string {
+	if x != nil {
+		return x.Displayname
+	}
+	return ""
+}
+
+func (x *VolumeCommand) GetTimestamp() uint64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *VolumeCommand) GetCommand() VolumeCommand_Command {
+	if x != nil {
+		return x.Command
+	}
+	return VolumeCommand_COMMAND_UNSPECIFIED
+}
+
+// LocalDevInfo contains information about an EdgeNode
+// sent to the api/v1/devinfo
+type LocalDevInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceUuid             string                       `protobuf:"bytes,1,opt,name=device_uuid,json=deviceUuid,proto3" json:"device_uuid,omitempty"`
+	State                  info.ZDeviceState            `protobuf:"varint,2,opt,name=state,proto3,enum=org.lfedge.eve.info.ZDeviceState" json:"state,omitempty"`
+	MaintenanceModeReasons []info.MaintenanceModeReason `protobuf:"varint,3,rep,packed,name=maintenance_mode_reasons,json=maintenanceModeReasons,proto3,enum=org.lfedge.eve.info.MaintenanceModeReason" json:"maintenance_mode_reasons,omitempty"`
+	BootTime               *timestamppb.Timestamp       `protobuf:"bytes,4,opt,name=boot_time,json=bootTime,proto3" json:"boot_time,omitempty"`
+	LastBootReason         info.BootReason              `protobuf:"varint,5,opt,name=last_boot_reason,json=lastBootReason,proto3,enum=org.lfedge.eve.info.BootReason" json:"last_boot_reason,omitempty"`
+	// Value of the field `timestamp` from the last `DevCommand` that was
+	// requested by the Local profile server, received by EVE and has completed
+	// its execution for this edge node.
+	LastCmdTimestamp uint64 `protobuf:"varint,10,opt,name=last_cmd_timestamp,json=lastCmdTimestamp,proto3" json:"last_cmd_timestamp,omitempty"`
+}
+
+func (x *LocalDevInfo) Reset() {
+	*x = LocalDevInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_profile_local_profile_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LocalDevInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocalDevInfo) ProtoMessage() {}
+
+func (x *LocalDevInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_profile_local_profile_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocalDevInfo.ProtoReflect.Descriptor instead.
+func (*LocalDevInfo) Descriptor() ([]byte, []int) {
+	return file_profile_local_profile_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *LocalDevInfo) GetDeviceUuid() string {
+	if x != nil {
+		return x.DeviceUuid
+	}
+	return ""
+}
+
+func (x *LocalDevInfo) GetState() info.ZDeviceState {
+	if x != nil {
+		return x.State
+	}
+	return info.ZDeviceState_ZDEVICE_STATE_UNSPECIFIED
+}
+
+func (x *LocalDevInfo) GetMaintenanceModeReasons() []info.MaintenanceModeReason {
+	if x != nil {
+		return x.MaintenanceModeReasons
+	}
+	return nil
+}
+
+func (x *LocalDevInfo) GetBootTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.BootTime
+	}
+	return nil
+}
+
+func (x *LocalDevInfo) GetLastBootReason() info.BootReason {
+	if x != nil {
+		return x.LastBootReason
+	}
+	return info.BootReason_BOOT_REASON_UNSPECIFIED
+}
+
+func (x *LocalDevInfo) GetLastCmdTimestamp() uint64 {
+	if x != nil {
+		return x.LastCmdTimestamp
+	}
+	return 0
+}
+
+// LocalDevCmd message may be returned in the response from a POST request
+// sent to the api/v1/devinfo API.
+type LocalDevCmd struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Security token. EVE will verify that server_token matches the profile server
+	// token received from the controller.
+	ServerToken string `protobuf:"bytes,1,opt,name=server_token,json=serverToken,proto3" json:"server_token,omitempty"`
+	Timestamp   uint64 `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Command to run.
+	Command LocalDevCmd_Command `protobuf:"varint,3,opt,name=command,proto3,enum=org.lfedge.eve.profile.LocalDevCmd_Command" json:"command,omitempty"`
+}
+
+func (x *LocalDevCmd) Reset() {
+	*x = LocalDevCmd{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_profile_local_profile_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LocalDevCmd) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocalDevCmd) ProtoMessage() {}
+
+func (x *LocalDevCmd) ProtoReflect() protoreflect.Message {
+	mi := &file_profile_local_profile_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocalDevCmd.ProtoReflect.Descriptor instead.
+func (*LocalDevCmd) Descriptor() ([]byte, []int) {
+	return file_profile_local_profile_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *LocalDevCmd) GetServerToken() string {
+	if x != nil {
+		return x.ServerToken
+	}
+	return ""
+}
+
+func (x *LocalDevCmd) GetTimestamp() uint64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *LocalDevCmd) GetCommand() LocalDevCmd_Command {
+	if x != nil {
+		return x.Command
+	}
+	return LocalDevCmd_COMMAND_UNSPECIFIED
+}
+
+// LocalBundle message is sent in response to a GET to
+// the api/v1/bundle API
+type LocalBundle struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Security token. EVE will verify that server_token matches the profile server
+	// token received from the controller.
+	ServerToken string `protobuf:"bytes,1,opt,name=server_token,json=serverToken,proto3" json:"server_token,omitempty"`
+	// Marshalled AuthContainer with the UpdateBundleManifest
+	// (see config/bundle.proto) as the payload, signed by the controller.
+	Manifest []byte `protobuf:"bytes,2,opt,name=manifest,proto3" json:"manifest,omitempty"`
+}
+
+func (x *LocalBundle) Reset() {
+	*x = LocalBundle{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_profile_local_profile_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LocalBundle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocalBundle) ProtoMessage() {}
+
+func (x *LocalBundle) ProtoReflect() protoreflect.Message {
+	mi := &file_profile_local_profile_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocalBundle.ProtoReflect.Descriptor instead.
+func (*LocalBundle) Descriptor() ([]byte, []int) {
+	return file_profile_local_profile_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *LocalBundle) GetServerToken() string {
+	if x != nil {
+		return x.ServerToken
+	}
+	return ""
+}
+
+func (x *LocalBundle) GetManifest() []byte {
+	if x != nil {
+		return x.Manifest
+	}
+	return nil
+}
+
+// LocalBundleStatus contains the state of the update bundle
+// sent to the api/v1/bundleinfo
+type LocalBundleStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Sequence of the last bundle received by EVE
+	Sequence uint64                  `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	State    LocalBundleStatus_State `protobuf:"varint,2,opt,name=state,proto3,enum=org.lfedge.eve.profile.LocalBundleStatus_State" json:"state,omitempty"`
+	Error    string                  `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	// Sequence of the last applied bundle
+	AppliedSequence uint64 `protobuf:"varint,4,opt,name=applied_sequence,json=appliedSequence,proto3" json:"applied_sequence,omitempty"`
+}
+
+func (x *LocalBundleStatus) Reset() {
+	*x = LocalBundleStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_profile_local_profile_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LocalBundleStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocalBundleStatus) ProtoMessage() {}
+
+func (x *LocalBundleStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_profile_local_profile_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocalBundleStatus.ProtoReflect.Descriptor instead.
+func (*LocalBundleStatus) Descriptor() ([]byte, []int) {
+	return file_profile_local_profile_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *LocalBundleStatus) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *LocalBundleStatus) GetState() LocalBundleStatus_State {
+	if x != nil {
+		return x.State
+	}
+	return LocalBundleStatus_STATE_UNSPECIFIED
+}
+
+func (x *LocalBundleStatus) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *LocalBundleStatus) GetAppliedSequence() uint64 {
+	if x != nil {
+		return x.AppliedSequence
+	}
+	return 0
+}
+
+// LocalCheckpointInfo contains the config checkpoint of the EdgeNode
+// sent to the api/v1/checkpoint
+type LocalCheckpointInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Config checkpoint exported with its signature, a marshalled
+	// ConfigCheckpoint (see config/checkpoint.proto). Not set if the
+	// checkpoint cannot be exported, e.g. it is not signed; see export_error.
+	Checkpoint  []byte `protobuf:"bytes,1,opt,name=checkpoint,proto3" json:"checkpoint,omitempty"`
+	ExportError string `protobuf:"bytes,2,opt,name=export_error,json=exportError,proto3" json:"export_error,omitempty"`
+	// Value of the field `timestamp` from the last `LocalCheckpointCmd` that
+	// was requested by the Local profile server, received by EVE and has
+	// completed its execution.
+	LastCmdTimestamp uint64 `protobuf:"varint,3,opt,name=last_cmd_timestamp,json=lastCmdTimestamp,proto3" json:"last_cmd_timestamp,omitempty"`
+	// Result of the last COMMAND_INSPECT: the checkpoint in human-readable
+	// form, with the result of the signature verification.
+	Inspection string `protobuf:"bytes,4,opt,name=inspection,proto3" json:"inspection,omitempty"`
+	// Set if the last command failed.
+	CmdError string `protobuf:"bytes,5,opt,name=cmd_error,json=cmdError,proto3" json:"cmd_error,omitempty"`
+}
+
+func (x *LocalCheckpointInfo) Reset() {
+	*x = LocalCheckpointInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_profile_local_profile_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LocalCheckpointInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocalCheckpointInfo) ProtoMessage() {}
+
+func (x *LocalCheckpointInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_profile_local_profile_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocalCheckpointInfo.ProtoReflect.Descriptor instead.
+func (*LocalCheckpointInfo) Descriptor() ([]byte, []int) {
+	return file_profile_local_profile_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *LocalCheckpointInfo) GetCheckpoint() []byte {
+	if x != nil {
+		return x.Checkpoint
+	}
+	return nil
+}
+
+func (x *LocalCheckpointInfo) GetExportError() string {
+	if x != nil {
+		return x.ExportError
+	}
+	return ""
+}
+
+func (x *LocalCheckpointInfo) GetLastCmdTimestamp() uint64 {
+	if x != nil {
+		return x.LastCmdTimestamp
+	}
+	return 0
+}
+
+func (x *LocalCheckpointInfo) GetInspection() string {
+	if x != nil {
+		return x.Inspection
+	}
+	return ""
+}
+
+func (x *LocalCheckpointInfo) GetCmdError() string {
+	if x != nil {
+		return x.CmdError
+	}
+	return ""
+}
+
+// LocalCheckpointCmd message may be returned in the response from a POST request
+// sent to the api/v1/checkpoint API.
+type LocalCheckpointCmd struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Security token. EVE will verify that server_token matches the profile server
+	// token received from the controller.
+	ServerToken string `protobuf:"bytes,1,opt,name=server_token,json=serverToken,proto3" json:"server_token,omitempty"`
+	// Timestamp to record when the request to run the command was made.
+	// The semantics is the same as for LocalDevCmd: to check if the last
+	// requested command has completed, compare its timestamp with
+	// 'last_cmd_timestamp' from `LocalCheckpointInfo` message.
+	Timestamp uint64 `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Command to run.
+	Command LocalCheckpointCmd_Command `protobuf:"varint,3,opt,name=command,proto3,enum=org.lfedge.eve.profile.LocalCheckpointCmd_Command" json:"command,omitempty"`
+	// Exported config checkpoint to inspect or import, a marshalled
+	// ConfigCheckpoint (see config/checkpoint.proto).
+	Checkpoint []byte `protobuf:"bytes,4,opt,name=checkpoint,proto3" json:"checkpoint,omitempty"`
+}
+
+func (x *LocalCheckpointCmd) Reset() {
+	*x = LocalCheckpointCmd{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_profile_local_profile_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LocalCheckpointCmd) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocalCheckpointCmd) ProtoMessage() {}
+
+func (x *LocalCheckpointCmd) ProtoReflect() protoreflect.Message {
+	mi := &file_profile_local_profile_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocalCheckpointCmd.ProtoReflect.Descriptor instead.
+func (*LocalCheckpointCmd) Descriptor() ([]byte, []int) {
+	return file_profile_local_profile_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *LocalCheckpointCmd) GetServerToken() string {
+	if x != nil {
+		return x.ServerToken
+	}
+	return ""
+}
+
+func (x *LocalCheckpointCmd) GetTimestamp() uint64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *LocalCheckpointCmd) GetCommand() LocalCheckpointCmd_Command {
+	if x != nil {
+		return x.Command
+	}
+	return LocalCheckpointCmd_COMMAND_UNSPECIFIED
+}
+
+func (x *LocalCheckpointCmd) GetCheckpoint() []byte {
+	if x != nil {
+		return x.Checkpoint
+	}
+	return nil
+}
+
+var File_profile_local_profile_proto protoreflect.FileDescriptor
+
+var file_profile_local_profile_proto_rawDesc = []byte{
+	0x0a, 0x1b, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x2f, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x5f,
+	0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x16, 0x6f,
+	0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x1a, 0x0f, 0x69, 0x6e, 0x66, 0x6f, 0x2f, 0x69, 0x6e, 0x66, 0x6f,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x15, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2f,
+	0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x56,
+	0x0a, 0x0c, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x23,
+	0x0a, 0x0d, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x5f, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x50, 0x72, 0x6f, 0x66,
+	0x69, 0x6c, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x74, 0x6f,
+	0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0xf9, 0x01, 0x0a, 0x0b, 0x52, 0x61, 0x64, 0x69, 0x6f,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x61, 0x64, 0x69, 0x6f, 0x5f,
+	0x73, 0x69, 0x6c, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x72,
+	0x61, 0x64, 0x69, 0x6f, 0x53, 0x69, 0x6c, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x4f,
+	0x0a, 0x0f, 0x63, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66,
+	0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65,
+	0x2e, 0x43, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
+	0x0e, 0x63, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
+	0x51, 0x0a, 0x10, 0x63, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x5f, 0x6d, 0x65, 0x74, 0x72,
+	0x69, 0x63, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x6f, 0x72, 0x67, 0x2e,
+	0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72, 0x69,
+	0x63, 0x73, 0x2e, 0x43, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x4d, 0x65, 0x74, 0x72, 0x69,
+	0x63, 0x52, 0x0f, 0x63, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x4d, 0x65, 0x74, 0x72, 0x69,
+	0x63, 0x73, 0x22, 0xc0, 0x02, 0x0a, 0x0e, 0x43, 0x65, 0x6c, 0x6c, 0x75, 0x6c, 0x61, 0x72, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x22, 0x0a, 0x0c, 0x6c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c,
+	0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6c, 0x6f, 0x67,
 	0x69, 0x63, 0x61, 0x6c, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x12, 0x40, 0x0a, 0x06, 0x6d, 0x6f, 0x64,
 	0x75, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x6f, 0x72, 0x67, 0x2e,
 	0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x69, 0x6e, 0x66, 0x6f, 0x2e,
@@ -956,7 +1883,7 @@ var file_profile_local_profile_proto_rawDesc = []byte{
 	0x03, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65,
 	0x2e, 0x65, 0x76, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x2e, 0x4c, 0x6f, 0x63,
 	0x61, 0x6c, 0x41, 0x70, 0x70, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x08, 0x61, 0x70, 0x70, 0x73, 0x49,
-	0x6e, 0x66, 0x6f, 0x22, 0xe1, 0x01, 0x0a, 0x0c, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x41, 0x70, 0x70,
+	0x6e, 0x66, 0x6f, 0x22, 0x8a, 0x02, 0x0a, 0x0c, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x41, 0x70, 0x70,
 	0x49, 0x6e, 0x66, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
 	0x52, 0x02, 0x69, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18,
 	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x12,
@@ -970,74 +1897,209 @@ var file_profile_local_profile_proto_rawDesc = []byte{
 	0x74, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x2c, 0x0a, 0x12, 0x6c, 0x61, 0x73,
 	0x74, 0x5f, 0x63, 0x6d, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18,
 	0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x10, 0x6c, 0x61, 0x73, 0x74, 0x43, 0x6d, 0x64, 0x54, 0x69,
-	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x22, 0x7b, 0x0a, 0x0f, 0x4c, 0x6f, 0x63, 0x61, 0x6c,
-	0x41, 0x70, 0x70, 0x43, 0x6d, 0x64, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x65,
-	0x72, 0x76, 0x65, 0x72, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x0b, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x45, 0x0a,
-	0x0c, 0x61, 0x70, 0x70, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65,
-	0x2e, 0x65, 0x76, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x2e, 0x41, 0x70, 0x70,
-	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x0b, 0x61, 0x70, 0x70, 0x43, 0x6f, 0x6d, 0x6d,
-	0x61, 0x6e, 0x64, 0x73, 0x22, 0xee, 0x01, 0x0a, 0x0a, 0x41, 0x70, 0x70, 0x43, 0x6f, 0x6d, 0x6d,
-	0x61, 0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x02, 0x69, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x6e, 0x61,
-	0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61,
-	0x79, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
-	0x6d, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
-	0x61, 0x6d, 0x70, 0x12, 0x44, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x0e, 0x32, 0x2a, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67,
-	0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x2e, 0x41, 0x70,
-	0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x4a, 0x0a, 0x07, 0x43, 0x6f, 0x6d,
-	0x6d, 0x61, 0x6e, 0x64, 0x12, 0x17, 0x0a, 0x13, 0x43, 0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f,
-	0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x13, 0x0a,
-	0x0f, 0x43, 0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f, 0x52, 0x45, 0x53, 0x54, 0x41, 0x52, 0x54,
-	0x10, 0x01, 0x12, 0x11, 0x0a, 0x0d, 0x43, 0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f, 0x50, 0x55,
-	0x52, 0x47, 0x45, 0x10, 0x02, 0x22, 0x80, 0x03, 0x0a, 0x0c, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x44,
-	0x65, 0x76, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65,
-	0x5f, 0x75, 0x75, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x64, 0x65, 0x76,
-	0x69, 0x63, 0x65, 0x55, 0x75, 0x69, 0x64, 0x12, 0x37, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x21, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65,
-	0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x69, 0x6e, 0x66, 0x6f, 0x2e, 0x5a, 0x44, 0x65,
-	0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65,
-	0x12, 0x64, 0x0a, 0x18, 0x6d, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x5f,
-	0x6d, 0x6f, 0x64, 0x65, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03,
-	0x28, 0x0e, 0x32, 0x2a, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e,
-	0x65, 0x76, 0x65, 0x2e, 0x69, 0x6e, 0x66, 0x6f, 0x2e, 0x4d, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x6e,
-	0x61, 0x6e, 0x63, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x52, 0x16,
-	0x6d, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x52,
-	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x12, 0x37, 0x0a, 0x09, 0x62, 0x6f, 0x6f, 0x74, 0x5f, 0x74,
-	0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
-	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
-	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x62, 0x6f, 0x6f, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12,
-	0x49, 0x0a, 0x10, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x62, 0x6f, 0x6f, 0x74, 0x5f, 0x72, 0x65, 0x61,
-	0x73, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x6f, 0x72, 0x67, 0x2e,
-	0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x69, 0x6e, 0x66, 0x6f, 0x2e,
-	0x42, 0x6f, 0x6f, 0x74, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x52, 0x0e, 0x6c, 0x61, 0x73, 0x74,
-	0x42, 0x6f, 0x6f, 0x74, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x2c, 0x0a, 0x12, 0x6c, 0x61,
-	0x73, 0x74, 0x5f, 0x63, 0x6d, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
-	0x18, 0x0a, 0x20, 0x01, 0x28, 0x04, 0x52, 0x10, 0x6c, 0x61, 0x73, 0x74, 0x43, 0x6d, 0x64, 0x54,
-	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x22, 0xee, 0x01, 0x0a, 0x0b, 0x4c, 0x6f, 0x63,
-	0x61, 0x6c, 0x44, 0x65, 0x76, 0x43, 0x6d, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x65, 0x72, 0x76,
-	0x65, 0x72, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
-	0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x74,
-	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09,
-	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x45, 0x0a, 0x07, 0x63, 0x6f, 0x6d,
-	0x6d, 0x61, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2b, 0x2e, 0x6f, 0x72, 0x67,
-	0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x66,
-	0x69, 0x6c, 0x65, 0x2e, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x44, 0x65, 0x76, 0x43, 0x6d, 0x64, 0x2e,
-	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
-	0x22, 0x57, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x17, 0x0a, 0x13, 0x43,
-	0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49,
-	0x45, 0x44, 0x10, 0x00, 0x12, 0x14, 0x0a, 0x10, 0x43, 0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f,
-	0x53, 0x48, 0x55, 0x54, 0x44, 0x4f, 0x57, 0x4e, 0x10, 0x01, 0x12, 0x1d, 0x0a, 0x19, 0x43, 0x4f,
-	0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f, 0x53, 0x48, 0x55, 0x54, 0x44, 0x4f, 0x57, 0x4e, 0x5f, 0x50,
-	0x4f, 0x57, 0x45, 0x52, 0x4f, 0x46, 0x46, 0x10, 0x02, 0x42, 0x3f, 0x0a, 0x16, 0x6f, 0x72, 0x67,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x27, 0x0a, 0x0f, 0x73, 0x74, 0x6f, 0x70, 0x70,
+	0x65, 0x64, 0x5f, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x6c, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0e, 0x73, 0x74, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x6c, 0x79,
+	0x22, 0x7b, 0x0a, 0x0f, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x41, 0x70, 0x70, 0x43, 0x6d, 0x64, 0x4c,
+	0x69, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x74, 0x6f,
+	0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x45, 0x0a, 0x0c, 0x61, 0x70, 0x70, 0x5f, 0x63, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x6f,
+	0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x2e, 0x41, 0x70, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x52, 0x0b, 0x61, 0x70, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x22, 0x91, 0x04,
+	0x0a, 0x0a, 0x41, 0x70, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x20, 0x0a, 0x0b,
+	0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1c,
+	0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x44, 0x0a, 0x07,
+	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2a, 0x2e,
+	0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x2e, 0x41, 0x70, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x12, 0x59, 0x0a, 0x0d, 0x65, 0x6e, 0x76, 0x5f, 0x76, 0x61, 0x72, 0x69, 0x61, 0x62,
+	0x6c, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x34, 0x2e, 0x6f, 0x72, 0x67, 0x2e,
+	0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x66, 0x69,
+	0x6c, 0x65, 0x2e, 0x41, 0x70, 0x70, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x45, 0x6e,
+	0x76, 0x56, 0x61, 0x72, 0x69, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x0c, 0x65, 0x6e, 0x76, 0x56, 0x61, 0x72, 0x69, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x12, 0x31, 0x0a,
+	0x15, 0x6d, 0x61, 0x78, 0x5f, 0x63, 0x6f, 0x6e, 0x73, 0x6f, 0x6c, 0x65, 0x5f, 0x6c, 0x6f, 0x67,
+	0x5f, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x12, 0x6d, 0x61,
+	0x78, 0x43, 0x6f, 0x6e, 0x73, 0x6f, 0x6c, 0x65, 0x4c, 0x6f, 0x67, 0x4c, 0x69, 0x6e, 0x65, 0x73,
+	0x1a, 0x3f, 0x0a, 0x11, 0x45, 0x6e, 0x76, 0x56, 0x61, 0x72, 0x69, 0x61, 0x62, 0x6c, 0x65, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38,
+	0x01, 0x22, 0x9d, 0x01, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x17, 0x0a,
+	0x13, 0x43, 0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49,
+	0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x43, 0x4f, 0x4d, 0x4d, 0x41, 0x4e,
+	0x44, 0x5f, 0x52, 0x45, 0x53, 0x54, 0x41, 0x52, 0x54, 0x10, 0x01, 0x12, 0x11, 0x0a, 0x0d, 0x43,
+	0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f, 0x50, 0x55, 0x52, 0x47, 0x45, 0x10, 0x02, 0x12, 0x10,
+	0x0a, 0x0c, 0x43, 0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f, 0x53, 0x54, 0x4f, 0x50, 0x10, 0x03,
+	0x12, 0x11, 0x0a, 0x0d, 0x43, 0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f, 0x53, 0x54, 0x41, 0x52,
+	0x54, 0x10, 0x04, 0x12, 0x13, 0x0a, 0x0f, 0x43, 0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f, 0x53,
+	0x45, 0x54, 0x5f, 0x45, 0x4e, 0x56, 0x10, 0x05, 0x12, 0x17, 0x0a, 0x13, 0x43, 0x4f, 0x4d, 0x4d,
+	0x41, 0x4e, 0x44, 0x5f, 0x43, 0x4f, 0x4e, 0x53, 0x4f, 0x4c, 0x45, 0x5f, 0x4c, 0x4f, 0x47, 0x10,
+	0x06, 0x22, 0x89, 0x01, 0x0a, 0x12, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x41, 0x70, 0x70, 0x43, 0x6f,
+	0x6e, 0x73, 0x6f, 0x6c, 0x65, 0x4c, 0x6f, 0x67, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x23, 0x0a, 0x0d,
+	0x63, 0x6d, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0c, 0x63, 0x6d, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x05, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x61, 0x0a,
+	0x13, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x49, 0x6e, 0x66, 0x6f,
+	0x4c, 0x69, 0x73, 0x74, 0x12, 0x4a, 0x0a, 0x0c, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x73, 0x5f,
+	0x69, 0x6e, 0x66, 0x6f, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x6f, 0x72, 0x67,
 	0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x66,
-	0x69, 0x6c, 0x65, 0x5a, 0x25, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
-	0x6c, 0x66, 0x2d, 0x65, 0x64, 0x67, 0x65, 0x2f, 0x65, 0x76, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f,
-	0x67, 0x6f, 0x2f, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x33,
+	0x69, 0x6c, 0x65, 0x2e, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x49,
+	0x6e, 0x66, 0x6f, 0x52, 0x0b, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x73, 0x49, 0x6e, 0x66, 0x6f,
+	0x22, 0x80, 0x03, 0x0a, 0x0f, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65,
+	0x49, 0x6e, 0x66, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x30, 0x0a, 0x03, 0x65, 0x72, 0x72, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64,
+	0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x69, 0x6e, 0x66, 0x6f, 0x2e, 0x45, 0x72, 0x72, 0x6f,
+	0x72, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x03, 0x65, 0x72, 0x72, 0x12, 0x33, 0x0a, 0x05, 0x73, 0x74,
+	0x61, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1d, 0x2e, 0x6f, 0x72, 0x67, 0x2e,
+	0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x69, 0x6e, 0x66, 0x6f, 0x2e,
+	0x5a, 0x53, 0x77, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12,
+	0x24, 0x0a, 0x0e, 0x6d, 0x61, 0x78, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65,
+	0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x6d, 0x61, 0x78, 0x53, 0x69, 0x7a, 0x65,
+	0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x75, 0x73, 0x65, 0x64, 0x5f, 0x62, 0x79,
+	0x74, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x75, 0x73, 0x65, 0x64, 0x42,
+	0x79, 0x74, 0x65, 0x73, 0x12, 0x2c, 0x0a, 0x12, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x63, 0x6d, 0x64,
+	0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x10, 0x6c, 0x61, 0x73, 0x74, 0x43, 0x6d, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x12, 0x48, 0x0a, 0x12, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x73, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x10, 0x6c, 0x61, 0x73, 0x74,
+	0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x25, 0x0a, 0x0e,
+	0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x45, 0x72,
+	0x72, 0x6f, 0x72, 0x22, 0x87, 0x01, 0x0a, 0x12, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x56, 0x6f, 0x6c,
+	0x75, 0x6d, 0x65, 0x43, 0x6d, 0x64, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x4e, 0x0a,
+	0x0f, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65,
+	0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x2e,
+	0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x0e, 0x76,
+	0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x22, 0xe2, 0x01,
+	0x0a, 0x0d, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12,
+	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12,
+	0x20, 0x0a, 0x0b, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12,
+	0x47, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x2d, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x2e, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65,
+	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52,
+	0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x38, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x12, 0x17, 0x0a, 0x13, 0x43, 0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f, 0x55,
+	0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x14, 0x0a, 0x10,
+	0x43, 0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f, 0x53, 0x4e, 0x41, 0x50, 0x53, 0x48, 0x4f, 0x54,
+	0x10, 0x01, 0x22, 0x80, 0x03, 0x0a, 0x0c, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x44, 0x65, 0x76, 0x49,
+	0x6e, 0x66, 0x6f, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x75, 0x75,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65,
+	0x55, 0x75, 0x69, 0x64, 0x12, 0x37, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x21, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65,
+	0x2e, 0x65, 0x76, 0x65, 0x2e, 0x69, 0x6e, 0x66, 0x6f, 0x2e, 0x5a, 0x44, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x64, 0x0a,
+	0x18, 0x6d, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x6d, 0x6f, 0x64,
+	0x65, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0e, 0x32,
+	0x2a, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65,
+	0x2e, 0x69, 0x6e, 0x66, 0x6f, 0x2e, 0x4d, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x63,
+	0x65, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x52, 0x16, 0x6d, 0x61, 0x69,
+	0x6e, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x61, 0x73,
+	0x6f, 0x6e, 0x73, 0x12, 0x37, 0x0a, 0x09, 0x62, 0x6f, 0x6f, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x08, 0x62, 0x6f, 0x6f, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x49, 0x0a, 0x10,
+	0x6c, 0x61, 0x73, 0x74, 0x5f, 0x62, 0x6f, 0x6f, 0x74, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65,
+	0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x69, 0x6e, 0x66, 0x6f, 0x2e, 0x42, 0x6f, 0x6f,
+	0x74, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x52, 0x0e, 0x6c, 0x61, 0x73, 0x74, 0x42, 0x6f, 0x6f,
+	0x74, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x2c, 0x0a, 0x12, 0x6c, 0x61, 0x73, 0x74, 0x5f,
+	0x63, 0x6d, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x10, 0x6c, 0x61, 0x73, 0x74, 0x43, 0x6d, 0x64, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x22, 0xee, 0x01, 0x0a, 0x0b, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x44,
+	0x65, 0x76, 0x43, 0x6d, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x72, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x45, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2b, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66,
+	0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65,
+	0x2e, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x44, 0x65, 0x76, 0x43, 0x6d, 0x64, 0x2e, 0x43, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x57, 0x0a,
+	0x07, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x17, 0x0a, 0x13, 0x43, 0x4f, 0x4d, 0x4d,
+	0x41, 0x4e, 0x44, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10,
+	0x00, 0x12, 0x14, 0x0a, 0x10, 0x43, 0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f, 0x53, 0x48, 0x55,
+	0x54, 0x44, 0x4f, 0x57, 0x4e, 0x10, 0x01, 0x12, 0x1d, 0x0a, 0x19, 0x43, 0x4f, 0x4d, 0x4d, 0x41,
+	0x4e, 0x44, 0x5f, 0x53, 0x48, 0x55, 0x54, 0x44, 0x4f, 0x57, 0x4e, 0x5f, 0x50, 0x4f, 0x57, 0x45,
+	0x52, 0x4f, 0x46, 0x46, 0x10, 0x02, 0x22, 0x4c, 0x0a, 0x0b, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x42,
+	0x75, 0x6e, 0x64, 0x6c, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x72, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x61, 0x6e, 0x69,
+	0x66, 0x65, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x6d, 0x61, 0x6e, 0x69,
+	0x66, 0x65, 0x73, 0x74, 0x22, 0xa5, 0x02, 0x0a, 0x11, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x42, 0x75,
+	0x6e, 0x64, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65,
+	0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x73, 0x65,
+	0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x45, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2f, 0x2e, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64,
+	0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x2e, 0x4c,
+	0x6f, 0x63, 0x61, 0x6c, 0x42, 0x75, 0x6e, 0x64, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x14, 0x0a,
+	0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x12, 0x29, 0x0a, 0x10, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x64, 0x5f, 0x73,
+	0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0f, 0x61,
+	0x70, 0x70, 0x6c, 0x69, 0x65, 0x64, 0x53, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x22, 0x6c,
+	0x0a, 0x05, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x15, 0x0a, 0x11, 0x53, 0x54, 0x41, 0x54, 0x45,
+	0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x15,
+	0x0a, 0x11, 0x53, 0x54, 0x41, 0x54, 0x45, 0x5f, 0x44, 0x4f, 0x57, 0x4e, 0x4c, 0x4f, 0x41, 0x44,
+	0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c, 0x53, 0x54, 0x41, 0x54, 0x45, 0x5f, 0x53,
+	0x54, 0x41, 0x47, 0x45, 0x44, 0x10, 0x02, 0x12, 0x11, 0x0a, 0x0d, 0x53, 0x54, 0x41, 0x54, 0x45,
+	0x5f, 0x41, 0x50, 0x50, 0x4c, 0x49, 0x45, 0x44, 0x10, 0x03, 0x12, 0x10, 0x0a, 0x0c, 0x53, 0x54,
+	0x41, 0x54, 0x45, 0x5f, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x04, 0x22, 0xc3, 0x01, 0x0a,
+	0x13, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74,
+	0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69,
+	0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x70,
+	0x6f, 0x69, 0x6e, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x65, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x5f, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x65, 0x78, 0x70, 0x6f,
+	0x72, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x2c, 0x0a, 0x12, 0x6c, 0x61, 0x73, 0x74, 0x5f,
+	0x63, 0x6d, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x10, 0x6c, 0x61, 0x73, 0x74, 0x43, 0x6d, 0x64, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x1e, 0x0a, 0x0a, 0x69, 0x6e, 0x73, 0x70, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x69, 0x6e, 0x73, 0x70, 0x65,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x6d, 0x64, 0x5f, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6d, 0x64, 0x45, 0x72, 0x72,
+	0x6f, 0x72, 0x22, 0x90, 0x02, 0x0a, 0x12, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x43, 0x68, 0x65, 0x63,
+	0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x43, 0x6d, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x72, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1c, 0x0a, 0x09,
+	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x4c, 0x0a, 0x07, 0x63, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x32, 0x2e, 0x6f, 0x72,
+	0x67, 0x2e, 0x6c, 0x66, 0x65, 0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x66, 0x69, 0x6c, 0x65, 0x2e, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70,
+	0x6f, 0x69, 0x6e, 0x74, 0x43, 0x6d, 0x64, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52,
+	0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x68, 0x65, 0x63,
+	0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x63, 0x68,
+	0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x22, 0x4b, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x12, 0x17, 0x0a, 0x13, 0x43, 0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f, 0x55,
+	0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f,
+	0x43, 0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f, 0x49, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x54, 0x10,
+	0x01, 0x12, 0x12, 0x0a, 0x0e, 0x43, 0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f, 0x49, 0x4d, 0x50,
+	0x4f, 0x52, 0x54, 0x10, 0x02, 0x42, 0x3f, 0x0a, 0x16, 0x6f, 0x72, 0x67, 0x2e, 0x6c, 0x66, 0x65,
+	0x64, 0x67, 0x65, 0x2e, 0x65, 0x76, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x5a,
+	0x25, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x66, 0x2d, 0x65,
+	0x64, 0x67, 0x65, 0x2f, 0x65, 0x76, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x6f, 0x2f, 0x70,
+	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1052,53 +2114,75 @@ func file_profile_local_profile_proto_rawDescGZIP() []byte {
 	return file_profile_local_profile_proto_rawDescData
 }
 
-var file_profile_local_profile_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_profile_local_profile_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_profile_local_profile_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_profile_local_profile_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
 var file_profile_local_profile_proto_goTypes = []interface{}{
 	(AppCommand_Command)(0),          // 0: org.lfedge.eve.profile.AppCommand.Command
-	(LocalDevCmd_Command)(0),         // 1: org.lfedge.eve.profile.LocalDevCmd.Command
-	(*LocalProfile)(nil),             // 2: org.lfedge.eve.profile.LocalProfile
-	(*RadioStatus)(nil),              // 3: org.lfedge.eve.profile.RadioStatus
-	(*CellularStatus)(nil),           // 4: org.lfedge.eve.profile.CellularStatus
-	(*RadioConfig)(nil),              // 5: org.lfedge.eve.profile.RadioConfig
-	(*LocalAppInfoList)(nil),         // 6: org.lfedge.eve.profile.LocalAppInfoList
-	(*LocalAppInfo)(nil),             // 7: org.lfedge.eve.profile.LocalAppInfo
-	(*LocalAppCmdList)(nil),          // 8: org.lfedge.eve.profile.LocalAppCmdList
-	(*AppCommand)(nil),               // 9: org.lfedge.eve.profile.AppCommand
-	(*LocalDevInfo)(nil),             // 10: org.lfedge.eve.profile.LocalDevInfo
-	(*LocalDevCmd)(nil),              // 11: org.lfedge.eve.profile.LocalDevCmd
-	(*metrics.CellularMetric)(nil),   // 12: org.lfedge.eve.metrics.CellularMetric
-	(*info.ZCellularModuleInfo)(nil), // 13: org.lfedge.eve.info.ZCellularModuleInfo
-	(*info.ZSimcardInfo)(nil),        // 14: org.lfedge.eve.info.ZSimcardInfo
-	(*info.ZCellularProvider)(nil),   // 15: org.lfedge.eve.info.ZCellularProvider
-	(*info.ErrorInfo)(nil),           // 16: org.lfedge.eve.info.ErrorInfo
-	(info.ZSwState)(0),               // 17: org.lfedge.eve.info.ZSwState
-	(info.ZDeviceState)(0),           // 18: org.lfedge.eve.info.ZDeviceState
-	(info.MaintenanceModeReason)(0),  // 19: org.lfedge.eve.info.MaintenanceModeReason
-	(*timestamppb.Timestamp)(nil),    // 20: google.protobuf.Timestamp
-	(info.BootReason)(0),             // 21: org.lfedge.eve.info.BootReason
+	(VolumeCommand_Command)(0),       // 1: org.lfedge.eve.profile.VolumeCommand.Command
+	(LocalDevCmd_Command)(0),         // 2: org.lfedge.eve.profile.LocalDevCmd.Command
+	(LocalBundleStatus_State)(0),     // 3: org.lfedge.eve.profile.LocalBundleStatus.State
+	(LocalCheckpointCmd_Command)(0),  // 4: org.lfedge.eve.profile.LocalCheckpointCmd.Command
+	(*LocalProfile)(nil),             // 5: org.lfedge.eve.profile.LocalProfile
+	(*RadioStatus)(nil),              // 6: org.lfedge.eve.profile.RadioStatus
+	(*CellularStatus)(nil),           // 7: org.lfedge.eve.profile.CellularStatus
+	(*RadioConfig)(nil),              // 8: org.lfedge.eve.profile.RadioConfig
+	(*LocalAppInfoList)(nil),         // 9: org.lfedge.eve.profile.LocalAppInfoList
+	(*LocalAppInfo)(nil),             // 10: org.lfedge.eve.profile.LocalAppInfo
+	(*LocalAppCmdList)(nil),          // 11: org.lfedge.eve.profile.LocalAppCmdList
+	(*AppCommand)(nil),               // 12: org.lfedge.eve.profile.AppCommand
+	(*LocalAppConsoleLog)(nil),       // 13: org.lfedge.eve.profile.LocalAppConsoleLog
+	(*LocalVolumeInfoList)(nil),      // 14: org.lfedge.eve.profile.LocalVolumeInfoList
+	(*LocalVolumeInfo)(nil),          // 15: org.lfedge.eve.profile.LocalVolumeInfo
+	(*LocalVolumeCmdList)(nil),       // 16: org.lfedge.eve.profile.LocalVolumeCmdList
+	(*VolumeCommand)(nil),            // 17: org.lfedge.eve.profile.VolumeCommand
+	(*LocalDevInfo)(nil),             // 18: org.lfedge.eve.profile.LocalDevInfo
+	(*LocalDevCmd)(nil),              // 19: org.lfedge.eve.profile.LocalDevCmd
+	(*LocalBundle)(nil),              // 20: org.lfedge.eve.profile.LocalBundle
+	(*LocalBundleStatus)(nil),        // 21: org.lfedge.eve.profile.LocalBundleStatus
+	(*LocalCheckpointInfo)(nil),      // 22: org.lfedge.eve.profile.LocalCheckpointInfo
+	(*LocalCheckpointCmd)(nil),       // 23: org.lfedge.eve.profile.LocalCheckpointCmd
+	nil,                              // 24: org.lfedge.eve.profile.AppCommand.EnvVariablesEntry
+	(*metrics.CellularMetric)(nil),   // 25: org.lfedge.eve.metrics.CellularMetric
+	(*info.ZCellularModuleInfo)(nil), // 26: org.lfedge.eve.info.ZCellularModuleInfo
+	(*info.ZSimcardInfo)(nil),        // 27: org.lfedge.eve.info.ZSimcardInfo
+	(*info.ZCellularProvider)(nil),   // 28: org.lfedge.eve.info.ZCellularProvider
+	(*info.ErrorInfo)(nil),           // 29: org.lfedge.eve.info.ErrorInfo
+	(info.ZSwState)(0),               // 30: org.lfedge.eve.info.ZSwState
+	(*timestamppb.Timestamp)(nil),    // 31: google.protobuf.Timestamp
+	(info.ZDeviceState)(0),           // 32: org.lfedge.eve.info.ZDeviceState
+	(info.MaintenanceModeReason)(0),  // 33: org.lfedge.eve.info.MaintenanceModeReason
+	(info.BootReason)(0),             // 34: org.lfedge.eve.info.BootReason
 }
 var file_profile_local_profile_proto_depIdxs = []int32{
-	4,  // 0: org.lfedge.eve.profile.RadioStatus.cellular_status:type_name -> org.lfedge.eve.profile.CellularStatus
-	12, // 1: org.lfedge.eve.profile.RadioStatus.cellular_metrics:type_name -> org.lfedge.eve.metrics.CellularMetric
-	13, // 2: org.lfedge.eve.profile.CellularStatus.module:type_name -> org.lfedge.eve.info.ZCellularModuleInfo
-	14, // 3: org.lfedge.eve.profile.CellularStatus.sim_cards:type_name -> org.lfedge.eve.info.ZSimcardInfo
-	15, // 4: org.lfedge.eve.profile.CellularStatus.providers:type_name -> org.lfedge.eve.info.ZCellularProvider
-	7,  // 5: org.lfedge.eve.profile.LocalAppInfoList.apps_info:type_name -> org.lfedge.eve.profile.LocalAppInfo
-	16, // 6: org.lfedge.eve.profile.LocalAppInfo.err:type_name -> org.lfedge.eve.info.ErrorInfo
-	17, // 7: org.lfedge.eve.profile.LocalAppInfo.state:type_name -> org.lfedge.eve.info.ZSwState
-	9,  // 8: org.lfedge.eve.profile.LocalAppCmdList.app_commands:type_name -> org.lfedge.eve.profile.AppCommand
+	7,  // 0: org.lfedge.eve.profile.RadioStatus.cellular_status:type_name -> org.lfedge.eve.profile.CellularStatus
+	25, // 1: org.lfedge.eve.profile.RadioStatus.cellular_metrics:type_name -> org.lfedge.eve.metrics.CellularMetric
+	26, // 2: org.lfedge.eve.profile.CellularStatus.module:type_name -> org.lfedge.eve.info.ZCellularModuleInfo
+	27, // 3: org.lfedge.eve.profile.CellularStatus.sim_cards:type_name -> org.lfedge.eve.info.ZSimcardInfo
+	28, // 4: org.lfedge.eve.profile.CellularStatus.providers:type_name -> org.lfedge.eve.info.ZCellularProvider
+	10, // 5: org.lfedge.eve.profile.LocalAppInfoList.apps_info:type_name -> org.lfedge.eve.profile.LocalAppInfo
+	29, // 6: org.lfedge.eve.profile.LocalAppInfo.err:type_name -> org.lfedge.eve.info.ErrorInfo
+	30, // 7: org.lfedge.eve.profile.LocalAppInfo.state:type_name -> org.lfedge.eve.info.ZSwState
+	12, // 8: org.lfedge.eve.profile.LocalAppCmdList.app_commands:type_name -> org.lfedge.eve.profile.AppCommand
 	0,  // 9: org.lfedge.eve.profile.AppCommand.command:type_name -> org.lfedge.eve.profile.AppCommand.Command
-	18, // 10: org.lfedge.eve.profile.LocalDevInfo.state:type_name -> org.lfedge.eve.info.ZDeviceState
-	19, // 11: org.lfedge.eve.profile.LocalDevInfo.maintenance_mode_reasons:type_name -> org.lfedge.eve.info.MaintenanceModeReason
-	20, // 12: org.lfedge.eve.profile.LocalDevInfo.boot_time:type_name -> google.protobuf.Timestamp
-	21, // 13: org.lfedge.eve.profile.LocalDevInfo.last_boot_reason:type_name -> org.lfedge.eve.info.BootReason
-	1,  // 14: org.lfedge.eve.profile.LocalDevCmd.command:type_name -> org.lfedge.eve.profile.LocalDevCmd.Command
-	15, // [15:15] is the sub-list for method output_type
-	15, // [15:15] is the sub-list for method input_type
-	15, // [15:15] is the sub-list for extension type_name
-	15, // [15:15] is the sub-list for extension extendee
-	0,  // [0:15] is the sub-list for field type_name
+	24, // 10: org.lfedge.eve.profile.AppCommand.env_variables:type_name -> org.lfedge.eve.profile.AppCommand.EnvVariablesEntry
+	15, // 11: org.lfedge.eve.profile.LocalVolumeInfoList.volumes_info:type_name -> org.lfedge.eve.profile.LocalVolumeInfo
+	29, // 12: org.lfedge.eve.profile.LocalVolumeInfo.err:type_name -> org.lfedge.eve.info.ErrorInfo
+	30, // 13: org.lfedge.eve.profile.LocalVolumeInfo.state:type_name -> org.lfedge.eve.info.ZSwState
+	31, // 14: org.lfedge.eve.profile.LocalVolumeInfo.last_snapshot_time:type_name -> google.protobuf.Timestamp
+	17, // 15: org.lfedge.eve.profile.LocalVolumeCmdList.volume_commands:type_name -> org.lfedge.eve.profile.VolumeCommand
+	1,  // 16: org.lfedge.eve.profile.VolumeCommand.command:type_name -> org.lfedge.eve.profile.VolumeCommand.Command
+	32, // 17: org.lfedge.eve.profile.LocalDevInfo.state:type_name -> org.lfedge.eve.info.ZDeviceState
+	33, // 18: org.lfedge.eve.profile.LocalDevInfo.maintenance_mode_reasons:type_name -> org.lfedge.eve.info.MaintenanceModeReason
+	31, // 19: org.lfedge.eve.profile.LocalDevInfo.boot_time:type_name -> google.protobuf.Timestamp
+	34, // 20: org.lfedge.eve.profile.LocalDevInfo.last_boot_reason:type_name -> org.lfedge.eve.info.BootReason
+	2,  // 21: org.lfedge.eve.profile.LocalDevCmd.command:type_name -> org.lfedge.eve.profile.LocalDevCmd.Command
+	3,  // 22: org.lfedge.eve.profile.LocalBundleStatus.state:type_name -> org.lfedge.eve.profile.LocalBundleStatus.State
+	4,  // 23: org.lfedge.eve.profile.LocalCheckpointCmd.command:type_name -> org.lfedge.eve.profile.LocalCheckpointCmd.Command
+	24, // [24:24] is the sub-list for method output_type
+	24, // [24:24] is the sub-list for method input_type
+	24, // [24:24] is the sub-list for extension type_name
+	24, // [24:24] is the sub-list for extension extendee
+	0,  // [0:24] is the sub-list for field type_name
 }
 
 func init() { file_profile_local_profile_proto_init() }
@@ -1204,7 +2288,7 @@ func file_profile_local_profile_proto_init() {
 			}
 		}
 		file_profile_local_profile_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*LocalDevInfo); i {
+			switch v := v.(*LocalAppConsoleLog); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1216,6 +2300,66 @@ func file_profile_local_profile_proto_init() {
 			}
 		}
 		file_profile_local_profile_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LocalVolumeInfoList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_profile_local_profile_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LocalVolumeInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_profile_local_profile_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LocalVolumeCmdList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_profile_local_profile_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VolumeCommand); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_profile_local_profile_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LocalDevInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_profile_local_profile_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*LocalDevCmd); i {
 			case 0:
 				return &v.state
@@ -1227,14 +2371,62 @@ func file_profile_local_profile_proto_init() {
 				return nil
 			}
 		}
+		file_profile_local_profile_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LocalBundle); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_profile_local_profile_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LocalBundleStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_profile_local_profile_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LocalCheckpointInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_profile_local_profile_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LocalCheckpointCmd); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_profile_local_profile_proto_rawDesc,
-			NumEnums:      2,
-			NumMessages:   10,
+			NumEnums:      5,
+			NumMessages:   20,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
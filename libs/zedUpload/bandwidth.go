@@ -0,0 +1,186 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package zedUpload
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxBandwidthChunk limits the size of a single read from a limited
+// connection to keep the rate smooth
+const maxBandwidthChunk = 32 * 1024
+
+// BandwidthLimiter is a token bucket which limits the rate of the data
+// received over the connections it is applied to, e.g. all downloads using
+// one port. Readers with higher priority get the tokens first.
+type BandwidthLimiter struct {
+	sync.Mutex
+	rate    int64 // bytes per second, zero means unlimited
+	tokens  float64
+	last    time.Time
+	waiters map[int]int // number of waiting readers by priority
+}
+
+// NewBandwidthLimiter returns a limiter with rate in bytes per second,
+// zero means unlimited
+func NewBandwidthLimiter(rate int64) *BandwidthLimiter {
+	return &BandwidthLimiter{
+		rate:    rate,
+		last:    time.Now(),
+		waiters: make(map[int]int),
+	}
+}
+
+// SetRate changes the rate in bytes per second, zero means unlimited. It
+// applies to the connections already using the limiter.
+func (l *BandwidthLimiter) SetRate(rate int64) {
+	l.Lock()
+	defer l.Unlock()
+	l.refill()
+	l.rate = rate
+}
+
+// Rate returns the rate in bytes per second
+func (l *BandwidthLimiter) Rate() int64 {
+	l.Lock()
+	defer l.Unlock()
+	return l.rate
+}
+
+// refill adds the tokens accumulated since the last call, with the burst
+// limited to one second worth of data
+func (l *BandwidthLimiter) refill() {
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * float64(l.rate)
+	if l.tokens > float64(l.rate) {
+		l.tokens = float64(l.rate)
+	}
+	l.last = now
+}
+
+// higherWaiting returns true if a reader with higher priority waits
+func (l *BandwidthLimiter) higherWaiting(priority int) bool {
+	for p, count := range l.waiters {
+		if p > priority && count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// WaitN takes n tokens, waiting until they are available. The tokens may
+// go negative, the debt delays the next readers.
+func (l *BandwidthLimiter) WaitN(ctx context.Context, n int, priority int) error {
+	waiting := false
+	defer func() {
+		if waiting {
+			l.Lock()
+			l.waiters[priority]--
+			l.Unlock()
+		}
+	}()
+	for {
+		l.Lock()
+		if l.rate == 0 {
+			l.Unlock()
+			return nil
+		}
+		l.refill()
+		if l.tokens > 0 && !l.higherWaiting(priority) {
+			l.tokens -= float64(n)
+			l.Unlock()
+			return nil
+		}
+		if !waiting {
+			waiting = true
+			l.waiters[priority]++
+		}
+		delay := 10 * time.Millisecond
+		if l.tokens <= 0 {
+			delay += time.Duration(-l.tokens / float64(l.rate) * float64(time.Second))
+		}
+		l.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// limitedBody limits the rate of data read from the body of a response.
+// Waiting for tokens stops once the context of the request is cancelled or
+// the body is closed.
+type limitedBody struct {
+	io.ReadCloser
+	ctx      context.Context
+	cancel   context.CancelFunc
+	limiter  *BandwidthLimiter
+	priority int
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if len(p) > maxBandwidthChunk {
+		p = p[:maxBandwidthChunk]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		if waitErr := b.limiter.WaitN(b.ctx, n, b.priority); waitErr != nil && err == nil {
+			err = waitErr
+		}
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// limitedTransport applies the limiter to the bodies of the responses
+type limitedTransport struct {
+	http.RoundTripper
+	limiter  *BandwidthLimiter
+	priority int
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.RoundTripper.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		return resp, err
+	}
+	ctx, cancel := context.WithCancel(req.Context())
+	resp.Body = &limitedBody{
+		ReadCloser: resp.Body,
+		ctx:        ctx,
+		cancel:     cancel,
+		limiter:    t.limiter,
+		priority:   t.priority,
+	}
+	return resp, nil
+}
+
+// httpClientLimit applies the limiter to the responses received by the
+// client. The priority is the one of the request when the limit is applied,
+// i.e. of the download using the endpoint, and does not follow later
+// changes of the priority.
+func httpClientLimit(client *http.Client, limiter *BandwidthLimiter, priority int) *http.Client {
+	if client == nil || limiter == nil {
+		return client
+	}
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	client.Transport = &limitedTransport{
+		RoundTripper: transport,
+		limiter:      limiter,
+		priority:     priority,
+	}
+	return client
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package zedUpload
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBandwidthLimiterRate(t *testing.T) {
+	const rate = 200 * 1024
+	l := NewBandwidthLimiter(rate)
+	start := time.Now()
+	for i := 0; i < 30; i++ {
+		if err := l.WaitN(context.Background(), 10*1024, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	elapsed := time.Since(start)
+	if elapsed < time.Second || elapsed > 3*time.Second {
+		t.Errorf("300KB at 200KB/s took %v", elapsed)
+	}
+
+	l.SetRate(0)
+	start = time.Now()
+	if err := l.WaitN(context.Background(), 1024*1024, 0); err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(start) > 100*time.Millisecond {
+		t.Errorf("unlimited limiter waited %v", time.Since(start))
+	}
+}
+
+func TestBandwidthLimiterPriority(t *testing.T) {
+	l := NewBandwidthLimiter(50 * 1024)
+	// use up the tokens
+	if err := l.WaitN(context.Background(), 20*1024, 0); err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for _, priority := range []int{0, 1} {
+		wg.Add(1)
+		go func(priority int) {
+			defer wg.Done()
+			if err := l.WaitN(context.Background(), 20*1024, priority); err != nil {
+				t.Error(err)
+			}
+			mu.Lock()
+			order = append(order, priority)
+			mu.Unlock()
+		}(priority)
+		// make sure the low priority reader waits first
+		time.Sleep(50 * time.Millisecond)
+	}
+	wg.Wait()
+	if len(order) != 2 || order[0] != 1 {
+		t.Errorf("unexpected order %v", order)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := l.WaitN(ctx, 1, 0); err == nil {
+		t.Errorf("WaitN did not return the context error")
+	}
+}
+
+func TestHTTPClientLimit(t *testing.T) {
+	const size = 100 * 1024
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", size)))
+	}))
+	defer server.Close()
+
+	l := NewBandwidthLimiter(200 * 1024)
+	client := httpClientLimit(&http.Client{Transport: &http.Transport{}}, l, 0)
+	start := time.Now()
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || len(b) != size {
+		t.Fatalf("read %d bytes, error %v", len(b), err)
+	}
+	// the first second worth of tokens accumulates while idle
+	if time.Since(start) > 3*time.Second {
+		t.Errorf("100KB at 200KB/s took %v", time.Since(start))
+	}
+
+	// waiting for tokens stops once the request is cancelled
+	l.SetRate(1024)
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start = time.Now()
+	if _, err := ioutil.ReadAll(resp.Body); err == nil {
+		t.Errorf("read of cancelled request succeeded")
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Errorf("cancelled read took %v", time.Since(start))
+	}
+}
@@ -51,6 +51,7 @@ const (
 	SyncHttpTr        SyncTransportType = "http"
 	SyncSftpTr        SyncTransportType = "sftp"
 	SyncOCIRegistryTr SyncTransportType = "oci"
+	SyncPeerTr        SyncTransportType = "peer"
 )
 
 //
@@ -68,6 +69,7 @@ type DronaEndPoint interface {
 	WithSrcIPAndProxyAndHTTPSCerts(localAddr net.IP, proxy *url.URL, certs [][]byte) error
 	WithBindIntf(intf string) error
 	WithLogging(onoff bool) error
+	WithBandwidthLimit(limiter *BandwidthLimiter, priority int) error
 }
 
 // use the specific ip as source address for this connection
@@ -220,6 +222,10 @@ type AuthInput struct {
 
 	// optional, keytabs
 	Keys []string
+
+	// optional, TLS configuration with client certificate
+	// and server verification (used by peer transport)
+	TLSConfig *tls.Config
 }
 
 // NewSyncerDest:
@@ -276,6 +282,16 @@ func (ctx *DronaCtx) NewSyncerDest(tr SyncTransportType, UrlOrRegion, PathOrBkt
 		}
 		syncEp.failPostTime = time.Now()
 		return syncEp, nil
+	case SyncPeerTr:
+		syncEp := &PeerTransportMethod{transport: tr, purl: UrlOrRegion, path: PathOrBkt, ctx: ctx}
+		if syncEp.path == "" {
+			syncEp.path = PeerBlobsPath
+		}
+		if auth != nil {
+			syncEp.tlsConfig = auth.TLSConfig
+		}
+		syncEp.failPostTime = time.Now()
+		return syncEp, nil
 	default:
 	}
 
@@ -124,6 +124,13 @@ func (ep *AwsTransportMethod) WithLogging(onoff bool) error {
 	return nil
 }
 
+// WithBandwidthLimit limits the rate of downloads using the limiter, call
+// after the source IP is selected
+func (ep *AwsTransportMethod) WithBandwidthLimit(limiter *BandwidthLimiter, priority int) error {
+	ep.hClient = httpClientLimit(ep.hClient, limiter, priority)
+	return nil
+}
+
 // File upload to AWS S3 Datastore
 func (ep *AwsTransportMethod) processS3Upload(req *DronaRequest) (error, int) {
 	fInfo, err := os.Stat(req.objloc)
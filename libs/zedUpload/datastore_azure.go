@@ -114,6 +114,13 @@ func (ep *AzureTransportMethod) WithLogging(onoff bool) error {
 	return nil
 }
 
+// WithBandwidthLimit limits the rate of downloads using the limiter, call
+// after the source IP is selected
+func (ep *AzureTransportMethod) WithBandwidthLimit(limiter *BandwidthLimiter, priority int) error {
+	ep.hClient = httpClientLimit(ep.hClient, limiter, priority)
+	return nil
+}
+
 // File upload to Azure Blob Datastore
 func (ep *AzureTransportMethod) processAzureUpload(req *DronaRequest) (string, error) {
 	file := req.name
@@ -118,6 +118,13 @@ func (ep *GsTransportMethod) WithLogging(onoff bool) error {
 	return nil
 }
 
+// WithBandwidthLimit limits the rate of downloads using the limiter, call
+// after the source IP is selected
+func (ep *GsTransportMethod) WithBandwidthLimit(limiter *BandwidthLimiter, priority int) error {
+	ep.hClient = httpClientLimit(ep.hClient, limiter, priority)
+	return nil
+}
+
 // File upload to Google Storage Datastore
 func (ep *GsTransportMethod) processGSUpload(req *DronaRequest) (int, error) {
 	fInfo, err := os.Stat(req.objloc)
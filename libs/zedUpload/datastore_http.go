@@ -111,6 +111,13 @@ func (ep *HttpTransportMethod) WithLogging(onoff bool) error {
 	return nil
 }
 
+// WithBandwidthLimit limits the rate of downloads using the limiter, call
+// after the source IP is selected
+func (ep *HttpTransportMethod) WithBandwidthLimit(limiter *BandwidthLimiter, priority int) error {
+	ep.hClient = httpClientLimit(ep.hClient, limiter, priority)
+	return nil
+}
+
 // File upload to HTTP Datastore
 func (ep *HttpTransportMethod) processHttpUpload(req *DronaRequest) (error, int) {
 	postUrl := ep.hurl + "/" + ep.path
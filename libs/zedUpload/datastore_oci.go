@@ -130,6 +130,13 @@ func (ep *OCITransportMethod) WithLogging(onoff bool) error {
 	return nil
 }
 
+// WithBandwidthLimit limits the rate of downloads using the limiter, call
+// after the source IP is selected
+func (ep *OCITransportMethod) WithBandwidthLimit(limiter *BandwidthLimiter, priority int) error {
+	ep.hClient = httpClientLimit(ep.hClient, limiter, priority)
+	return nil
+}
+
 // processUpload artifact upload to OCI registry
 // not yet supported
 func (ep *OCITransportMethod) processUpload(req *DronaRequest) (int64, error) {
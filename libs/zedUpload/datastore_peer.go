@@ -0,0 +1,195 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package zedUpload
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	zedHttp "github.com/lf-edge/eve/libs/zedUpload/httputil"
+	"github.com/lf-edge/eve/libs/zedUpload/types"
+)
+
+// PeerBlobsPath is the path under which a peer serves blobs addressed
+// by their sha256 digest.
+const PeerBlobsPath = "blobs/sha256"
+
+// PeerTransportMethod transport method to download blobs from the cache
+// of another device (peer) on the same local network.
+// The connection is always HTTPS with mutual authentication,
+// i.e. the TLS configuration must carry the client certificate
+// and the verification of the peer certificate.
+type PeerTransportMethod struct {
+	transport SyncTransportType
+	// peer URL, e.g. https://192.168.1.10:8488
+	purl string
+	path string
+
+	tlsConfig *tls.Config
+
+	failPostTime time.Time
+	ctx          *DronaCtx
+	hClient      *http.Client
+}
+
+// Action perform an action using this method, one of
+// Download/GetObjectMetaData
+func (ep *PeerTransportMethod) Action(req *DronaRequest) error {
+	var err error
+	var size int64
+	var contentLength int64
+	var contentType string
+
+	switch req.operation {
+	case SyncOpDownload:
+		size, contentType, err = ep.processPeerDownload(req)
+		req.contentType = contentType
+	case SyncOpGetObjectMetaData:
+		contentLength, _, err = ep.processPeerObjectMetaData(req)
+		req.contentLength = contentLength
+	default:
+		err = fmt.Errorf("Unsupported peer operation")
+	}
+
+	req.asize = size
+	if err != nil {
+		req.status = fmt.Sprintf("%v", err)
+	}
+	return err
+}
+
+// Open unsupported
+func (ep *PeerTransportMethod) Open() error {
+	return nil
+}
+
+// Close unsupported
+func (ep *PeerTransportMethod) Close() error {
+	return nil
+}
+
+// WithSrcIPSelection use the specific ip as source address for this connection
+func (ep *PeerTransportMethod) WithSrcIPSelection(localAddr net.IP) error {
+	if ep.tlsConfig == nil {
+		return fmt.Errorf("missing TLS configuration for peer")
+	}
+	client := httpClientSrcIP(localAddr, nil)
+	client.Transport.(*http.Transport).TLSClientConfig = ep.tlsConfig.Clone()
+	ep.hClient = client
+	return nil
+}
+
+// WithSrcIPAndProxySelection unsupported, peers are on the local network
+func (ep *PeerTransportMethod) WithSrcIPAndProxySelection(localAddr net.IP,
+	proxy *url.URL) error {
+	return fmt.Errorf("not supported")
+}
+
+// WithSrcIPAndHTTPSCerts unsupported, peers are authenticated with device
+// certificates
+func (ep *PeerTransportMethod) WithSrcIPAndHTTPSCerts(localAddr net.IP, certs [][]byte) error {
+	return fmt.Errorf("not supported")
+}
+
+// WithSrcIPAndProxyAndHTTPSCerts unsupported
+func (ep *PeerTransportMethod) WithSrcIPAndProxyAndHTTPSCerts(localAddr net.IP, proxy *url.URL, certs [][]byte) error {
+	return fmt.Errorf("not supported")
+}
+
+// WithBindIntf bind to specific interface for this connection
+func (ep *PeerTransportMethod) WithBindIntf(intf string) error {
+	localAddr := getSrcIpFromInterface(intf)
+	if localAddr == nil {
+		return fmt.Errorf("failed to get the address for intf")
+	}
+	return ep.WithSrcIPSelection(localAddr)
+}
+
+// WithLogging enable logging, not yet supported
+func (ep *PeerTransportMethod) WithLogging(onoff bool) error {
+	return nil
+}
+
+// WithBandwidthLimit peers are on the local network, hence downloads from
+// them are not limited
+func (ep *PeerTransportMethod) WithBandwidthLimit(limiter *BandwidthLimiter, priority int) error {
+	return nil
+}
+
+func (ep *PeerTransportMethod) blobURL(req *DronaRequest) string {
+	return strings.TrimSuffix(ep.purl, "/") + "/" + ep.path + "/" + req.name
+}
+
+// processPeerDownload first checks that the peer has the blob
+// (and learns its media type), then downloads it.
+func (ep *PeerTransportMethod) processPeerDownload(req *DronaRequest) (int64, string, error) {
+	_, contentType, err := ep.processPeerObjectMetaData(req)
+	if err != nil {
+		return 0, "", err
+	}
+	prgChan := make(types.StatsNotifChan)
+	defer close(prgChan)
+	if req.ackback {
+		go statsUpdater(req, ep.ctx, prgChan)
+	}
+	stats, resp := zedHttp.ExecCmd(req.cancelContext, "get", ep.blobURL(req), "",
+		req.objloc, req.sizelimit, prgChan, ep.hClient)
+	return int64(resp.BodyLength), contentType, stats.Error
+}
+
+// processPeerObjectMetaData returns size and media type of the blob
+// as reported by the peer.
+func (ep *PeerTransportMethod) processPeerObjectMetaData(req *DronaRequest) (int64, string, error) {
+	if ep.hClient == nil {
+		return 0, "", fmt.Errorf("peer source IP not selected")
+	}
+	ctx := req.cancelContext
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	blobURL := ep.blobURL(req)
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodHead, blobURL, nil)
+	if err != nil {
+		return 0, "", fmt.Errorf("request failed for head %s: %v", blobURL, err)
+	}
+	resp, err := ep.hClient.Do(hreq)
+	if err != nil {
+		return 0, "", fmt.Errorf("head failed for %s: %v", blobURL, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, "", fmt.Errorf("bad response code for head %s: %d",
+			blobURL, resp.StatusCode)
+	}
+	return resp.ContentLength, resp.Header.Get("Content-Type"), nil
+}
+
+func (ep *PeerTransportMethod) getContext() *DronaCtx {
+	return ep.ctx
+}
+
+// NewRequest create a new DronaRequest with this PeerTransportMethod as the endpoint.
+// objname is the sha256 digest of the blob.
+func (ep *PeerTransportMethod) NewRequest(opType SyncOpType, objname, objloc string, sizelimit int64, ackback bool, reply chan *DronaRequest) *DronaRequest {
+	dR := &DronaRequest{}
+	dR.syncEp = ep
+	dR.operation = opType
+	dR.name = objname
+	dR.ackback = ackback
+
+	dR.localName = objname
+	dR.objloc = objloc
+
+	// limit for this download
+	dR.sizelimit = sizelimit
+	dR.result = reply
+
+	return dR
+}
@@ -0,0 +1,157 @@
+package zedUpload_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/eve/libs/zedUpload"
+)
+
+const peerMediaType = "application/vnd.oci.image.layer.v1.tar"
+
+func peerTestCert(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "EVE"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth,
+			x509.ExtKeyUsageServerAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template,
+		&key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func startPeer(t *testing.T, blob []byte) (*httptest.Server, string) {
+	hash := sha256.Sum256(blob)
+	digest := hex.EncodeToString(hash[:])
+	server := httptest.NewUnstartedServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/"+zedUpload.PeerBlobsPath+"/"+digest {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", peerMediaType)
+			w.Header().Set("Content-Length", strconv.Itoa(len(blob)))
+			if r.Method == http.MethodGet {
+				_, _ = w.Write(blob)
+			}
+		}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{peerTestCert(t)},
+		ClientAuth:   tls.RequireAnyClientCert,
+	}
+	server.StartTLS()
+	return server, digest
+}
+
+func operationPeer(t *testing.T, peerURL, digest, objloc string,
+	tlsConfig *tls.Config) (string, error) {
+	ctx, err := zedUpload.NewDronaCtx("zpeer", 0)
+	if ctx == nil {
+		t.Fatalf("context create fail %v", err)
+	}
+	auth := &zedUpload.AuthInput{AuthType: "peer", TLSConfig: tlsConfig}
+	dEndPoint, err := ctx.NewSyncerDest(zedUpload.SyncPeerTr, peerURL, "", auth)
+	if err != nil {
+		t.Fatalf("NewSyncerDest failed: %v", err)
+	}
+	if err = dEndPoint.WithSrcIPSelection(net.ParseIP("127.0.0.1")); err != nil {
+		return "", err
+	}
+	respChan := make(chan *zedUpload.DronaRequest)
+	req := dEndPoint.NewRequest(zedUpload.SyncOpDownload, digest, objloc, 0,
+		true, respChan)
+	req.Post()
+	for resp := range respChan {
+		if resp.IsDnUpdate() {
+			continue
+		}
+		if resp.IsError() {
+			return "", resp.GetDnStatus()
+		}
+		return resp.GetContentType(), nil
+	}
+	t.Fatalf("respChan closed")
+	return "", nil
+}
+
+func TestPeerDatastore(t *testing.T) {
+	blob := bytes.Repeat([]byte("eve-peer-blob"), 1000)
+	server, digest := startPeer(t, blob)
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "peer")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// TLS configuration is mandatory.
+	objloc := filepath.Join(dir, "blob")
+	if _, err = operationPeer(t, server.URL, digest, objloc, nil); err == nil {
+		t.Errorf("expected error without TLS configuration")
+	}
+
+	clientCert := peerTestCert(t)
+	tlsConfig := &tls.Config{
+		Certificates:       []tls.Certificate{clientCert},
+		InsecureSkipVerify: true,
+	}
+	contentType, err := operationPeer(t, server.URL, digest, objloc, tlsConfig)
+	if err != nil {
+		t.Fatalf("peer download failed: %v", err)
+	}
+	if contentType != peerMediaType {
+		t.Errorf("unexpected content type %q", contentType)
+	}
+	data, err := ioutil.ReadFile(objloc)
+	if err != nil {
+		t.Fatalf("failed to read downloaded blob: %v", err)
+	}
+	if !bytes.Equal(data, blob) {
+		t.Errorf("downloaded blob does not match")
+	}
+
+	// Peer does not have the blob.
+	missing := filepath.Join(dir, "missing")
+	_, err = operationPeer(t, server.URL, "0123456789abcdef", missing, tlsConfig)
+	if err == nil {
+		t.Errorf("expected error for missing blob")
+	}
+
+	// Peer without client certificate is rejected by the server.
+	tlsConfig = &tls.Config{InsecureSkipVerify: true}
+	_, err = operationPeer(t, server.URL, digest, objloc, tlsConfig)
+	if err == nil {
+		t.Errorf("expected error without client certificate")
+	}
+}
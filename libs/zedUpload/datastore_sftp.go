@@ -114,6 +114,11 @@ func (ep *SftpTransportMethod) WithLogging(onoff bool) error {
 	return nil
 }
 
+// WithBandwidthLimit not yet supported, sftp downloads are not limited
+func (ep *SftpTransportMethod) WithBandwidthLimit(limiter *BandwidthLimiter, priority int) error {
+	return nil
+}
+
 // File upload to SFTP Datastore
 func (ep *SftpTransportMethod) processSftpUpload(req *DronaRequest) (error, int) {
 	file := req.name
@@ -120,7 +120,8 @@ func PullBlob(registry, repo, hash, localFile, username, apiKey string, maxsize
 		// if we have a hash try to get the actual layer
 		d, ok := ref.(name.Digest)
 		if !ok {
-			return 0, "", fmt.Errorf("ref %s wasn't a tag or digest", image)
+			// a tag can only refer to a manifest
+			return 0, "", err
 		}
 		logrus.Infof("PullBlob: had hash, so pulling blob for %s", image)
 		layer, err := remote.Layer(d, opts...)
@@ -552,7 +552,7 @@ func printHelp(opt string) {
 			helpExample("flow/sport=53", "display all the ip flow matches source port of 53", true)
 			helpExample("flow/10.1.0.2", "display all the ip flow matches ip address of 10.1.0.2", false)
 		case "if":
-			helpOn("if[/intf-name]", "display interface related information briefly")
+			helpOn("if[/intf-name]", "display interface related information briefly, including LLDP/CDP neighbors")
 			helpExample("if/eth0", "display interface eth0 related information", true)
 		case "mdns":
 			helpOn("mdns[/intf-name][/service]", "display zeroconfig related information")
@@ -35,6 +35,30 @@ type appIPvnc struct {
 	vncEnable bool
 }
 
+// portNeighbors : subset of DeviceNetworkStatus with neighbors discovered
+// on device ports using LLDP and CDP.
+type portNeighbors struct {
+	Ports []struct {
+		IfName       string
+		Logicallabel string
+		Neighbors    []struct {
+			Protocol          uint8
+			ChassisID         string
+			PortID            string
+			PortDescription   string
+			SystemName        string
+			SystemDescription string
+			PortVLAN          uint16
+			VLANs             []struct {
+				ID   uint16
+				Name string
+			}
+			MgmtAddrs []net.IP
+			LastSeen  time.Time
+		}
+	}
+}
+
 type intfIP struct {
 	intfName    string
 	ipAddr      string
@@ -707,11 +731,54 @@ func showIntf(substring string, intfip []intfIP) {
 
 	getARP("")
 
+	showPortNeighbors(substring)
+
 	getPortCfg(substring, true)
 	printTitle(" proxy:", colorCYAN, false)
 	getProxy(true)
 }
 
+func showPortNeighbors(substring string) {
+	printTitle(" port neighbors (LLDP/CDP):", colorCYAN, false)
+	retbytes, err := ioutil.ReadFile("/run/nim/DeviceNetworkStatus/global.json")
+	if err != nil {
+		return
+	}
+	var status portNeighbors
+	if err = json.Unmarshal(retbytes, &status); err != nil {
+		return
+	}
+	protocols := map[uint8]string{1: "LLDP", 2: "CDP"}
+	for _, port := range status.Ports {
+		if substring != "" && !strings.Contains(port.IfName, substring) {
+			continue
+		}
+		if len(port.Neighbors) == 0 {
+			continue
+		}
+		fmt.Printf(" Intf Name: %s (%s)\n", port.IfName, port.Logicallabel)
+		for _, n := range port.Neighbors {
+			fmt.Printf("   %s: system %s, chassis %s, port %s",
+				protocols[n.Protocol], n.SystemName, n.ChassisID, n.PortID)
+			if n.PortDescription != "" {
+				fmt.Printf(" (%s)", n.PortDescription)
+			}
+			fmt.Printf("\n")
+			if n.PortVLAN != 0 {
+				fmt.Printf("     port VLAN: %d\n", n.PortVLAN)
+			}
+			for _, vlan := range n.VLANs {
+				fmt.Printf("     VLAN: %d %s\n", vlan.ID, vlan.Name)
+			}
+			if len(n.MgmtAddrs) > 0 {
+				fmt.Printf("     mgmt addresses: %v\n", n.MgmtAddrs)
+			}
+			fmt.Printf("     last seen: %v\n", n.LastSeen.Format(time.RFC3339))
+		}
+	}
+	fmt.Printf("\n")
+}
+
 func showAppDetail(substring string) {
 	if !rePattern.MatchString(substring) {
 		fmt.Printf("app substring invalid\n")
@@ -1064,7 +1131,7 @@ func runWireless() {
 	}
 
 	printTitle("\n wwan config", colorCYAN, false)
-	retbytes, err = ioutil.ReadFile("/run/wwan/config.json")
+	retbytes, err = ioutil.ReadFile("/run/nim/WwanConfig/global.json")
 	if err != nil {
 		return
 	}
@@ -1076,7 +1143,7 @@ func runWireless() {
 	fmt.Printf("%+v\n", wwancfg)
 
 	printTitle("\n wwan metrics", colorCYAN, false)
-	retbytes, err = ioutil.ReadFile("/run/wwan/metrics.json")
+	retbytes, err = ioutil.ReadFile("/run/wwan/WwanMetrics/global.json")
 	if err == nil {
 		prettyJSON, err := formatJSON(retbytes)
 		if err == nil {
@@ -1085,7 +1152,7 @@ func runWireless() {
 	}
 
 	printTitle("\n wwan status", colorCYAN, false)
-	retbytes, err = ioutil.ReadFile("/run/wwan/status.json")
+	retbytes, err = ioutil.ReadFile("/run/wwan/WwanStatus/global.json")
 	if err == nil {
 		prettyJSON, err := formatJSON(retbytes)
 		if err == nil {
@@ -0,0 +1,133 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package verifier
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/lf-edge/eve/api/go/attest"
+)
+
+const (
+	//evNoAction events are informational, they are not extended into PCRs
+	evNoAction = 0x3
+	//startupLocalitySignature marks EV_NO_ACTION event recording the locality
+	//TPM2_Startup was issued from, which is the initial value of PCR 0
+	startupLocalitySignature = "StartupLocality\x00"
+)
+
+//pcrKey identifies a PCR in a bank
+type pcrKey struct {
+	index    uint32
+	hashAlgo attest.TpmHashAlgo
+}
+
+//ReplayedPCR is a PCR value recomputed from the event log
+type ReplayedPCR struct {
+	Index    uint32
+	HashAlgo attest.TpmHashAlgo
+	Value    []byte
+}
+
+//ReplayEventLog recomputes PCR values by extending event digests from
+//the event log, in the order of events. Only PCRs referenced by the event
+//log are returned, sorted by hash algorithm and index.
+func ReplayEventLog(events []*attest.TpmEventLogEntry) ([]ReplayedPCR, error) {
+	var locality byte
+	pcrs := make(map[pcrKey][]byte)
+	for _, event := range events {
+		if event.GetEventType() == evNoAction {
+			data := event.GetEventDataBinary()
+			if bytes.HasPrefix(data, []byte(startupLocalitySignature)) &&
+				len(data) > len(startupLocalitySignature) {
+				locality = data[len(startupLocalitySignature)]
+			}
+			continue
+		}
+		digest := event.GetDigest()
+		algs, ok := hashAlgos[digest.GetHashAlgo()]
+		if !ok {
+			return nil, fmt.Errorf("%w: event %d: %v", ErrUnsupportedAlgo,
+				event.GetIndex(), digest.GetHashAlgo())
+		}
+		if len(digest.GetDigest()) != algs.hash.Size() {
+			return nil, fmt.Errorf("event %d: invalid digest size %d",
+				event.GetIndex(), len(digest.GetDigest()))
+		}
+		key := pcrKey{event.GetPcrIndex(), digest.GetHashAlgo()}
+		value, ok := pcrs[key]
+		if !ok {
+			value = make([]byte, algs.hash.Size())
+			if key.index == 0 {
+				value[len(value)-1] = locality
+			}
+		}
+		h := algs.hash.New()
+		h.Write(value)
+		h.Write(digest.GetDigest())
+		pcrs[key] = h.Sum(nil)
+	}
+
+	replayed := make([]ReplayedPCR, 0, len(pcrs))
+	for key, value := range pcrs {
+		replayed = append(replayed, ReplayedPCR{
+			Index:    key.index,
+			HashAlgo: key.hashAlgo,
+			Value:    value,
+		})
+	}
+	sort.Slice(replayed, func(i, j int) bool {
+		if replayed[i].HashAlgo != replayed[j].HashAlgo {
+			return replayed[i].HashAlgo < replayed[j].HashAlgo
+		}
+		return replayed[i].Index < replayed[j].Index
+	})
+	return replayed, nil
+}
+
+//CheckEventLog replays the event log, and compares the result with
+//PCR values in the PCR selection of the quote. PCRs not covered by
+//the event log are not checked, neither are PCRs which are not quoted.
+func CheckEventLog(events []*attest.TpmEventLogEntry,
+	pcrs []*attest.TpmPCRValue, sel tpm2.PCRSelection) ([]PCRMismatch, error) {
+	replayed, err := ReplayEventLog(events)
+	if err != nil {
+		return nil, err
+	}
+	pcrs = quotedPCRValues(pcrs, sel)
+	var mismatches []PCRMismatch
+	for _, r := range replayed {
+		pcr := findPCR(pcrs, r.Index, r.HashAlgo)
+		if pcr == nil {
+			//PCR was not quoted, e.g. SHA1 bank, so its value
+			//reported by the device can't be trusted
+			continue
+		}
+		if !bytes.Equal(pcr.GetValue(), r.Value) {
+			mismatches = append(mismatches, PCRMismatch{
+				Source:   SourceEventLog,
+				Index:    r.Index,
+				HashAlgo: r.HashAlgo,
+				Got:      pcr.GetValue(),
+				Want:     [][]byte{r.Value},
+				Events:   eventsForPCR(events, r.Index),
+			})
+		}
+	}
+	return mismatches, nil
+}
+
+//eventsForPCR returns events measured into PCR index
+func eventsForPCR(events []*attest.TpmEventLogEntry, index uint32) []*attest.TpmEventLogEntry {
+	var pcrEvents []*attest.TpmEventLogEntry
+	for _, event := range events {
+		if event.GetPcrIndex() == index && event.GetEventType() != evNoAction {
+			pcrEvents = append(pcrEvents, event)
+		}
+	}
+	return pcrEvents
+}
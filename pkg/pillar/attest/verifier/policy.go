@@ -0,0 +1,152 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package verifier
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/lf-edge/eve/api/go/attest"
+)
+
+//hashAlgoNames are the hash algorithm names used in policy files
+var hashAlgoNames = map[string]attest.TpmHashAlgo{
+	"sha1":   attest.TpmHashAlgo_TPM_HASH_ALGO_SHA1,
+	"sha256": attest.TpmHashAlgo_TPM_HASH_ALGO_SHA256,
+	"sha512": attest.TpmHashAlgo_TPM_HASH_ALGO_SHA512,
+}
+
+//PCRPolicy lists acceptable values of a single PCR
+type PCRPolicy struct {
+	Index uint32 `json:"index"`
+	//HashAlgo is one of sha1, sha256 or sha512
+	HashAlgo string `json:"hashAlgo"`
+	//Values are hex encoded acceptable PCR values, e.g.
+	//values for the current and for the fallback EVE image
+	Values []string `json:"values"`
+}
+
+//Policy holds reference PCR values, e.g.
+//	{
+//	  "pcrs": [
+//	    {"index": 0, "hashAlgo": "sha256", "values": ["3d45...", "a0c1..."]}
+//	  ]
+//	}
+type Policy struct {
+	PCRs []PCRPolicy `json:"pcrs"`
+}
+
+//LoadPolicy reads a policy from a JSON file
+func LoadPolicy(filename string) (*Policy, error) {
+	policyBytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	policy := &Policy{}
+	if err := json.Unmarshal(policyBytes, policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy %s: %v", filename, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %v", filename, err)
+	}
+	return policy, nil
+}
+
+//Validate checks that hash algorithms and values in the policy can be parsed
+func (p *Policy) Validate() error {
+	for _, pcrPolicy := range p.PCRs {
+		if _, _, err := pcrPolicy.parse(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p PCRPolicy) parse() (attest.TpmHashAlgo, [][]byte, error) {
+	hashAlgo, ok := hashAlgoNames[strings.ToLower(p.HashAlgo)]
+	if !ok {
+		return hashAlgo, nil, fmt.Errorf("PCR %d: %w: %s",
+			p.Index, ErrUnsupportedAlgo, p.HashAlgo)
+	}
+	if len(p.Values) == 0 {
+		return hashAlgo, nil, fmt.Errorf("PCR %d: no reference values", p.Index)
+	}
+	values := make([][]byte, 0, len(p.Values))
+	for _, v := range p.Values {
+		value, err := hex.DecodeString(v)
+		if err != nil {
+			return hashAlgo, nil, fmt.Errorf("PCR %d: invalid value %s: %v",
+				p.Index, v, err)
+		}
+		values = append(values, value)
+	}
+	return hashAlgo, values, nil
+}
+
+//Check compares PCR values in the PCR selection of the quote with reference
+//values from the policy. PCRs not mentioned in the policy are not checked,
+//PCRs mentioned in the policy but not reported are mismatches. A policy
+//mentioning a PCR which is not quoted fails the check, since a value not
+//covered by the quote signature can't be trusted.
+func (p *Policy) Check(pcrs []*attest.TpmPCRValue,
+	sel tpm2.PCRSelection) ([]PCRMismatch, error) {
+	pcrs = quotedPCRValues(pcrs, sel)
+	var mismatches []PCRMismatch
+	for _, pcrPolicy := range p.PCRs {
+		hashAlgo, values, err := pcrPolicy.parse()
+		if err != nil {
+			return nil, err
+		}
+		if !isQuoted(sel, pcrPolicy.Index, hashAlgo) {
+			return nil, fmt.Errorf("PCR %d (%s): %w", pcrPolicy.Index,
+				pcrPolicy.HashAlgo, ErrPCRNotQuoted)
+		}
+		var got []byte
+		if pcr := findPCR(pcrs, pcrPolicy.Index, hashAlgo); pcr != nil {
+			got = pcr.GetValue()
+		}
+		matched := false
+		for _, value := range values {
+			if got != nil && bytes.Equal(got, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			mismatches = append(mismatches, PCRMismatch{
+				Source:   SourcePolicy,
+				Index:    pcrPolicy.Index,
+				HashAlgo: hashAlgo,
+				Got:      got,
+				Want:     values,
+			})
+		}
+	}
+	return mismatches, nil
+}
+
+//PolicyFromPCRs creates a policy accepting exactly the given PCR values,
+//which is handy to record reference values from a known good device,
+//see QuotedPCRs
+func PolicyFromPCRs(pcrs []*attest.TpmPCRValue) *Policy {
+	policy := &Policy{}
+	for _, pcr := range pcrs {
+		for name, hashAlgo := range hashAlgoNames {
+			if hashAlgo != pcr.GetHashAlgo() {
+				continue
+			}
+			policy.PCRs = append(policy.PCRs, PCRPolicy{
+				Index:    pcr.GetIndex(),
+				HashAlgo: name,
+				Values:   []string{hex.EncodeToString(pcr.GetValue())},
+			})
+		}
+	}
+	return policy
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package verifier
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/lf-edge/eve/api/go/attest"
+)
+
+//Error values
+var (
+	ErrNoQuote         = errors.New("no quote to verify")
+	ErrNoQuoteCert     = errors.New("no quote certificate")
+	ErrNonceMismatch   = errors.New("quote nonce mismatch")
+	ErrSignature       = errors.New("quote signature verification failed")
+	ErrPCRDigest       = errors.New("PCR digest in the quote does not match PCR values")
+	ErrUnsupportedAlgo = errors.New("unsupported hash algorithm")
+	ErrPCRNotQuoted    = errors.New("PCR is not covered by the quote")
+)
+
+//hashAlgos maps hash algorithms of PCR values to TPM and Go hash algorithms
+var hashAlgos = map[attest.TpmHashAlgo]struct {
+	tpm  tpm2.Algorithm
+	hash crypto.Hash
+}{
+	attest.TpmHashAlgo_TPM_HASH_ALGO_SHA1:   {tpm2.AlgSHA1, crypto.SHA1},
+	attest.TpmHashAlgo_TPM_HASH_ALGO_SHA256: {tpm2.AlgSHA256, crypto.SHA256},
+	attest.TpmHashAlgo_TPM_HASH_ALGO_SHA512: {tpm2.AlgSHA512, crypto.SHA512},
+}
+
+//toHashAlgo converts TPM hash algorithm to the attest.TpmHashAlgo
+func toHashAlgo(alg tpm2.Algorithm) (attest.TpmHashAlgo, error) {
+	for hashAlgo, algs := range hashAlgos {
+		if algs.tpm == alg {
+			return hashAlgo, nil
+		}
+	}
+	return attest.TpmHashAlgo_TPM_HASH_ALGO_INVALID,
+		fmt.Errorf("%w: 0x%x", ErrUnsupportedAlgo, alg)
+}
+
+//findPCR returns the PCR value with the given index and hash algorithm
+func findPCR(pcrs []*attest.TpmPCRValue, index uint32,
+	hashAlgo attest.TpmHashAlgo) *attest.TpmPCRValue {
+	for _, pcr := range pcrs {
+		if pcr.GetIndex() == index && pcr.GetHashAlgo() == hashAlgo {
+			return pcr
+		}
+	}
+	return nil
+}
+
+//isQuoted checks if the PCR is in the PCR selection of the quote
+func isQuoted(sel tpm2.PCRSelection, index uint32, hashAlgo attest.TpmHashAlgo) bool {
+	algs, ok := hashAlgos[hashAlgo]
+	if !ok || algs.tpm != sel.Hash {
+		return false
+	}
+	for _, i := range sel.PCRs {
+		if uint32(i) == index {
+			return true
+		}
+	}
+	return false
+}
+
+//quotedPCRValues returns the PCR values in the PCR selection of the quote.
+//Only these are covered by the quote signature, any other PCR value sent
+//along with the quote can't be trusted.
+func quotedPCRValues(pcrs []*attest.TpmPCRValue,
+	sel tpm2.PCRSelection) []*attest.TpmPCRValue {
+	var quoted []*attest.TpmPCRValue
+	for _, pcr := range pcrs {
+		if isQuoted(sel, pcr.GetIndex(), pcr.GetHashAlgo()) {
+			quoted = append(quoted, pcr)
+		}
+	}
+	return quoted
+}
+
+//QuotedPCRs returns the PCR values of the quote in its PCR selection,
+//without verifying the quote, see VerifyQuote
+func QuotedPCRs(quote *attest.ZAttestQuote) ([]*attest.TpmPCRValue, error) {
+	attestData, err := tpm2.DecodeAttestationData(quote.GetAttestData())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode attestation data: %v", err)
+	}
+	if attestData.Type != tpm2.TagAttestQuote || attestData.AttestedQuoteInfo == nil {
+		return nil, fmt.Errorf("attestation data is not a quote: 0x%x", attestData.Type)
+	}
+	return quotedPCRValues(quote.GetPcrValues(),
+		attestData.AttestedQuoteInfo.PCRSelection), nil
+}
+
+//VerifyQuote checks the quote signature using the public key from
+//quoteCert, the nonce in the quote, and that the PCR values sent
+//along with the quote are the ones the TPM quoted.
+//EVE quote key is an ECDSA key using SHA256, see tpmmgr.
+func VerifyQuote(quote *attest.ZAttestQuote, quoteCert *x509.Certificate,
+	nonce []byte) (*tpm2.AttestationData, error) {
+	publicKey, ok := quoteCert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported quote key type %T", quoteCert.PublicKey)
+	}
+	digest := crypto.SHA256.New()
+	digest.Write(quote.GetAttestData())
+	if !ecdsa.VerifyASN1(publicKey, digest.Sum(nil), quote.GetSignature()) {
+		return nil, ErrSignature
+	}
+
+	attestData, err := tpm2.DecodeAttestationData(quote.GetAttestData())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode attestation data: %v", err)
+	}
+	if attestData.Type != tpm2.TagAttestQuote || attestData.AttestedQuoteInfo == nil {
+		return nil, fmt.Errorf("attestation data is not a quote: 0x%x", attestData.Type)
+	}
+	if len(nonce) != 0 && !bytes.Equal(attestData.ExtraData, nonce) {
+		return nil, ErrNonceMismatch
+	}
+
+	quoteInfo := attestData.AttestedQuoteInfo
+	hashAlgo, err := toHashAlgo(quoteInfo.PCRSelection.Hash)
+	if err != nil {
+		return nil, err
+	}
+	pcrDigest := crypto.SHA256.New()
+	for _, index := range quoteInfo.PCRSelection.PCRs {
+		pcr := findPCR(quote.GetPcrValues(), uint32(index), hashAlgo)
+		if pcr == nil {
+			return nil, fmt.Errorf("%w: PCR %d is quoted, but its value is missing",
+				ErrPCRDigest, index)
+		}
+		pcrDigest.Write(pcr.GetValue())
+	}
+	if !bytes.Equal(pcrDigest.Sum(nil), quoteInfo.PCRDigest) {
+		return nil, ErrPCRDigest
+	}
+	return attestData, nil
+}
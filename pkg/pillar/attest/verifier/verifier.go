@@ -0,0 +1,140 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package verifier implements an offline verifier for the attestation
+// quotes sent by EVE to the controller (see cmd/zedagent/attesttask.go).
+// It checks the quote signature against the quote certificate, replays
+// the TPM event log to recompute PCR values, and compares PCR values with
+// reference values from a policy file. It does not talk to a device or to
+// the controller, which makes it usable for testing attestation end-to-end
+// against a stand-in controller, and for debugging PCR mismatches.
+package verifier
+
+import (
+	"crypto/x509"
+	"fmt"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/lf-edge/eve/api/go/attest"
+)
+
+//Options controls what Verify checks
+type Options struct {
+	//QuoteCert is the certificate of the key that signed the quote
+	QuoteCert *x509.Certificate
+	//Roots, if set, is used to verify QuoteCert, usually
+	//it contains the device certificate
+	Roots *x509.CertPool
+	//Nonce is the nonce handed out by the controller for this
+	//attestation cycle. Empty Nonce skips the nonce check.
+	Nonce []byte
+	//Policy holds the reference PCR values, nil skips the check
+	Policy *Policy
+}
+
+//Source tells where a mismatching reference value came from
+type Source string
+
+//Sources of PCR mismatches
+const (
+	SourceEventLog Source = "eventlog"
+	SourcePolicy   Source = "policy"
+)
+
+//PCRMismatch describes a PCR whose value differs from
+//the value computed from the event log, or from the policy
+type PCRMismatch struct {
+	Source   Source
+	Index    uint32
+	HashAlgo attest.TpmHashAlgo
+	//Got is the PCR value reported by the device
+	Got []byte
+	//Want are the acceptable values
+	Want [][]byte
+	//Events are the event log entries measured into this PCR,
+	//to help finding out which component changed
+	Events []*attest.TpmEventLogEntry
+}
+
+//String returns a one line description of the mismatch
+func (m PCRMismatch) String() string {
+	return fmt.Sprintf("PCR %d (%s): %s mismatch, got %x, want %x",
+		m.Index, m.HashAlgo, m.Source, m.Got, m.Want)
+}
+
+//Result is the outcome of Verify
+type Result struct {
+	//Attest is the decoded TPMS_ATTEST structure of the quote
+	Attest *tpm2.AttestationData
+	//NonceChecked is set if the quote nonce was compared with Options.Nonce
+	NonceChecked bool
+	//EventLogReplayed is set if PCR values were compared with the event log
+	EventLogReplayed bool
+	//PolicyChecked is set if PCR values were compared with the policy
+	PolicyChecked bool
+	//Mismatches lists all PCRs which did not match
+	Mismatches []PCRMismatch
+}
+
+//OK returns true if no PCR mismatches were found
+func (r *Result) OK() bool {
+	return len(r.Mismatches) == 0
+}
+
+//Verify verifies the quote, and checks the PCR values it carries
+//against its event log and the policy. An error is returned if the quote
+//itself can't be trusted, PCR mismatches are reported in Result.
+func Verify(quote *attest.ZAttestQuote, opts Options) (*Result, error) {
+	if quote == nil {
+		return nil, ErrNoQuote
+	}
+	if opts.QuoteCert == nil {
+		return nil, ErrNoQuoteCert
+	}
+	if opts.Roots != nil {
+		if _, err := opts.QuoteCert.Verify(x509.VerifyOptions{
+			Roots:     opts.Roots,
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		}); err != nil {
+			return nil, fmt.Errorf("quote certificate verification failed: %v", err)
+		}
+	}
+	attestData, err := VerifyQuote(quote, opts.QuoteCert, opts.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{
+		Attest:       attestData,
+		NonceChecked: len(opts.Nonce) != 0,
+	}
+	//only PCR values covered by the quote signature are checked
+	sel := attestData.AttestedQuoteInfo.PCRSelection
+	if len(quote.EventLog) != 0 {
+		mismatches, err := CheckEventLog(quote.EventLog, quote.PcrValues, sel)
+		if err != nil {
+			return nil, err
+		}
+		result.EventLogReplayed = true
+		result.Mismatches = append(result.Mismatches, mismatches...)
+	}
+	if opts.Policy != nil {
+		mismatches, err := opts.Policy.Check(quote.PcrValues, sel)
+		if err != nil {
+			return nil, err
+		}
+		for i := range mismatches {
+			mismatches[i].Events = eventsForPCR(quote.EventLog, mismatches[i].Index)
+		}
+		result.PolicyChecked = true
+		result.Mismatches = append(result.Mismatches, mismatches...)
+	}
+	return result, nil
+}
+
+//VerifyAttestReq verifies the quote carried by a captured attestation request
+func VerifyAttestReq(req *attest.ZAttestReq, opts Options) (*Result, error) {
+	if req.GetReqType() != attest.ZAttestReqType_ATTEST_REQ_QUOTE {
+		return nil, fmt.Errorf("not a quote request: %v", req.GetReqType())
+	}
+	return Verify(req.GetQuote(), opts)
+}
@@ -0,0 +1,340 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package verifier
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/lf-edge/eve/api/go/attest"
+	"github.com/lf-edge/eve/pkg/pillar/evetpm/tpmsim"
+)
+
+var (
+	quoteKeyTemplate = tpm2.Public{
+		Type:    tpm2.AlgECC,
+		NameAlg: tpm2.AlgSHA256,
+		Attributes: tpm2.FlagFixedTPM | tpm2.FlagFixedParent |
+			tpm2.FlagSensitiveDataOrigin | tpm2.FlagUserWithAuth |
+			tpm2.FlagRestricted | tpm2.FlagSign | tpm2.FlagNoDA,
+		ECCParameters: &tpm2.ECCParams{
+			Sign: &tpm2.SigScheme{
+				Alg:  tpm2.AlgECDSA,
+				Hash: tpm2.AlgSHA256,
+			},
+			CurveID: tpm2.CurveNISTP256,
+		},
+	}
+	quotedPCRs = tpm2.PCRSelection{Hash: tpm2.AlgSHA256,
+		PCRs: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}}
+)
+
+//testDevice is a simulated device, with a quote key in the TPM,
+//and a quote certificate signed by the device certificate
+type testDevice struct {
+	sim        *tpmsim.Simulator
+	quoteKey   tpm2.Public
+	deviceCert *x509.Certificate
+	quoteCert  *x509.Certificate
+	events     []*attest.TpmEventLogEntry
+}
+
+func newTestDevice(t *testing.T) *testDevice {
+	t.Helper()
+	sim, err := tpmsim.New()
+	if err != nil {
+		t.Fatalf("Failed to create TPM simulator: %v", err)
+	}
+	rw, err := sim.Open()
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer rw.Close()
+	handle, pub, err := tpm2.CreatePrimary(rw, tpm2.HandleOwner,
+		tpm2.PCRSelection{}, "", "", quoteKeyTemplate)
+	if err != nil {
+		t.Fatalf("CreatePrimary failed: %v", err)
+	}
+	if err := tpm2.EvictControl(rw, "", tpm2.HandleOwner, handle, 0x81000004); err != nil {
+		t.Fatalf("EvictControl failed: %v", err)
+	}
+
+	deviceKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	deviceTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "device"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	deviceCertBytes, err := x509.CreateCertificate(rand.Reader, deviceTemplate,
+		deviceTemplate, &deviceKey.PublicKey, deviceKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate failed: %v", err)
+	}
+	deviceCert, _ := x509.ParseCertificate(deviceCertBytes)
+	quoteTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "Device Attestation certificate"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	quoteCertBytes, err := x509.CreateCertificate(rand.Reader, quoteTemplate,
+		deviceCert, pub, deviceKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate failed: %v", err)
+	}
+	quoteCert, _ := x509.ParseCertificate(quoteCertBytes)
+	return &testDevice{sim: sim, deviceCert: deviceCert, quoteCert: quoteCert}
+}
+
+//measure extends PCR, and records it in the event log
+func (d *testDevice) measure(t *testing.T, pcr int, data string) {
+	t.Helper()
+	if err := d.sim.ExtendPCR(pcr, []byte(data)); err != nil {
+		t.Fatalf("ExtendPCR failed: %v", err)
+	}
+	digest := sha256.Sum256([]byte(data))
+	d.events = append(d.events, &attest.TpmEventLogEntry{
+		Index:     uint32(len(d.events)),
+		PcrIndex:  uint32(pcr),
+		EventType: 0xd, //EV_IPL
+		Digest: &attest.TpmEventDigest{
+			HashAlgo: attest.TpmHashAlgo_TPM_HASH_ALGO_SHA256,
+			Digest:   digest[:],
+		},
+		EventDataBinary: []byte(data),
+		EventBinarySize: uint32(len(data)),
+	})
+}
+
+//quote builds ZAttestQuote the same way tpmmgr and zedagent do
+func (d *testDevice) quote(t *testing.T, nonce []byte) *attest.ZAttestQuote {
+	t.Helper()
+	rw, err := d.sim.Open()
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer rw.Close()
+	quote := &attest.ZAttestQuote{EventLog: d.events}
+	for i := 0; i < tpmsim.NumPCRs; i++ {
+		value, err := tpm2.ReadPCR(rw, i, tpm2.AlgSHA256)
+		if err != nil {
+			t.Fatalf("ReadPCR failed: %v", err)
+		}
+		quote.PcrValues = append(quote.PcrValues, &attest.TpmPCRValue{
+			Index:    uint32(i),
+			HashAlgo: attest.TpmHashAlgo_TPM_HASH_ALGO_SHA256,
+			Value:    value,
+		})
+	}
+	attestData, sig, err := tpm2.Quote(rw, 0x81000004, "", "", nonce,
+		quotedPCRs, tpm2.AlgNull)
+	if err != nil {
+		t.Fatalf("Quote failed: %v", err)
+	}
+	quote.AttestData = attestData
+	quote.Signature, err = asn1.Marshal(struct {
+		R, S *big.Int
+	}{sig.ECC.R, sig.ECC.S})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	return quote
+}
+
+func TestVerifyQuote(t *testing.T) {
+	device := newTestDevice(t)
+	device.measure(t, 0, "firmware")
+	device.measure(t, 4, "bootloader")
+	device.measure(t, 8, "kernel")
+	nonce := []byte("0123456789abcdef")
+	quote := device.quote(t, nonce)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(device.deviceCert)
+	pcrs, err := QuotedPCRs(quote)
+	if err != nil {
+		t.Fatalf("QuotedPCRs failed: %v", err)
+	}
+	if len(pcrs) != len(quotedPCRs.PCRs) {
+		t.Errorf("want %d quoted PCRs, but got %d", len(quotedPCRs.PCRs), len(pcrs))
+	}
+	result, err := Verify(quote, Options{
+		QuoteCert: device.quoteCert,
+		Roots:     roots,
+		Nonce:     nonce,
+		Policy:    PolicyFromPCRs(pcrs),
+	})
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !result.OK() || !result.NonceChecked || !result.EventLogReplayed || !result.PolicyChecked {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	//PCRs sent along with the quote, but not quoted, can't be checked
+	if _, err := Verify(quote, Options{QuoteCert: device.quoteCert,
+		Policy: PolicyFromPCRs(quote.PcrValues)}); !errors.Is(err, ErrPCRNotQuoted) {
+		t.Errorf("want %v, but got %v", ErrPCRNotQuoted, err)
+	}
+
+	if _, err := Verify(quote, Options{QuoteCert: device.quoteCert,
+		Nonce: []byte("another nonce")}); !errors.Is(err, ErrNonceMismatch) {
+		t.Errorf("want %v, but got %v", ErrNonceMismatch, err)
+	}
+
+	//PCR values must be the ones the TPM quoted
+	quote.PcrValues[8].Value = make([]byte, sha256.Size)
+	if _, err := Verify(quote, Options{QuoteCert: device.quoteCert}); !errors.Is(err, ErrPCRDigest) {
+		t.Errorf("want %v, but got %v", ErrPCRDigest, err)
+	}
+
+	//quote must be signed by the quote key of this device
+	other := newTestDevice(t)
+	quote = device.quote(t, nonce)
+	if _, err := Verify(quote, Options{QuoteCert: other.quoteCert}); !errors.Is(err, ErrSignature) {
+		t.Errorf("want %v, but got %v", ErrSignature, err)
+	}
+	if _, err := Verify(quote, Options{QuoteCert: device.quoteCert,
+		Roots: roots}); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+	otherRoots := x509.NewCertPool()
+	otherRoots.AddCert(other.deviceCert)
+	if _, err := Verify(quote, Options{QuoteCert: device.quoteCert,
+		Roots: otherRoots}); err == nil {
+		t.Errorf("Quote certificate verified with another device certificate")
+	}
+}
+
+func TestEventLogMismatch(t *testing.T) {
+	device := newTestDevice(t)
+	device.measure(t, 0, "firmware")
+	device.measure(t, 8, "kernel")
+	//measurement missing from the event log
+	if err := device.sim.ExtendPCR(8, []byte("unlogged")); err != nil {
+		t.Fatalf("ExtendPCR failed: %v", err)
+	}
+	quote := device.quote(t, nil)
+
+	result, err := Verify(quote, Options{QuoteCert: device.quoteCert})
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(result.Mismatches) != 1 {
+		t.Fatalf("want 1 mismatch, but got %v", result.Mismatches)
+	}
+	mismatch := result.Mismatches[0]
+	if mismatch.Source != SourceEventLog || mismatch.Index != 8 || len(mismatch.Events) != 1 {
+		t.Errorf("Unexpected mismatch %v", mismatch)
+	}
+
+	//value of a PCR which is not quoted is not compared with the event log
+	device.measure(t, 16, "not quoted")
+	quote = device.quote(t, nil)
+	quote.PcrValues[16].Value = make([]byte, sha256.Size)
+	result, err = Verify(quote, Options{QuoteCert: device.quoteCert})
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	for _, mismatch := range result.Mismatches {
+		if mismatch.Index == 16 {
+			t.Errorf("Unquoted PCR checked against the event log: %v", mismatch)
+		}
+	}
+}
+
+func TestReplayEventLogStartupLocality(t *testing.T) {
+	digest := sha256.Sum256([]byte("firmware"))
+	events := []*attest.TpmEventLogEntry{{
+		PcrIndex:        0,
+		EventType:       evNoAction,
+		EventDataBinary: append([]byte(startupLocalitySignature), 3),
+	}, {
+		PcrIndex: 0,
+		Digest: &attest.TpmEventDigest{
+			HashAlgo: attest.TpmHashAlgo_TPM_HASH_ALGO_SHA256,
+			Digest:   digest[:],
+		},
+	}}
+	replayed, err := ReplayEventLog(events)
+	if err != nil {
+		t.Fatalf("ReplayEventLog failed: %v", err)
+	}
+	initial := make([]byte, sha256.Size)
+	initial[sha256.Size-1] = 3
+	want := sha256.Sum256(append(initial, digest[:]...))
+	if len(replayed) != 1 || hex.EncodeToString(replayed[0].Value) != hex.EncodeToString(want[:]) {
+		t.Errorf("want PCR 0 %x, but got %v", want, replayed)
+	}
+}
+
+func TestPolicyCheck(t *testing.T) {
+	good := sha256.Sum256([]byte("good"))
+	fallback := sha256.Sum256([]byte("fallback"))
+	policy := &Policy{PCRs: []PCRPolicy{{
+		Index:    4,
+		HashAlgo: "sha256",
+		Values:   []string{hex.EncodeToString(good[:]), hex.EncodeToString(fallback[:])},
+	}, {
+		Index:    7,
+		HashAlgo: "sha256",
+		Values:   []string{hex.EncodeToString(good[:])},
+	}}}
+	if err := policy.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	pcrs := []*attest.TpmPCRValue{{
+		Index:    4,
+		HashAlgo: attest.TpmHashAlgo_TPM_HASH_ALGO_SHA256,
+		Value:    fallback[:],
+	}}
+	mismatches, err := policy.Check(pcrs, quotedPCRs)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	//PCR 7 is not reported
+	if len(mismatches) != 1 || mismatches[0].Index != 7 || mismatches[0].Got != nil {
+		t.Errorf("Unexpected mismatches %v", mismatches)
+	}
+
+	//PCR 4 is quoted from SHA256 bank only, SHA1 value is not trusted
+	pcrs[0].HashAlgo = attest.TpmHashAlgo_TPM_HASH_ALGO_SHA1
+	mismatches, err = policy.Check(pcrs, quotedPCRs)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(mismatches) != 2 {
+		t.Errorf("Unexpected mismatches %v", mismatches)
+	}
+
+	//policy referencing a PCR which is not quoted fails
+	policy.PCRs[1].Index = 16
+	if _, err := policy.Check(pcrs, quotedPCRs); !errors.Is(err, ErrPCRNotQuoted) {
+		t.Errorf("want %v, but got %v", ErrPCRNotQuoted, err)
+	}
+
+	policy.PCRs[0].HashAlgo = "md5"
+	if err := policy.Validate(); !errors.Is(err, ErrUnsupportedAlgo) {
+		t.Errorf("want %v, but got %v", ErrUnsupportedAlgo, err)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package bspatch applies patches in the format of bsdiff 4.x
+// (BSDIFF40 header followed by three bzip2 compressed blocks).
+// The old content is accessed through io.ReaderAt and the new content
+// is streamed to io.Writer, hence neither of them has to fit into memory.
+package bspatch
+
+import (
+	"bufio"
+	"compress/bzip2"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	magic      = "BSDIFF40"
+	headerSize = 32
+	bufSize    = 64 * 1024
+)
+
+// ErrCorruptPatch is returned when the patch does not follow the format
+// or does not fit the old content.
+var ErrCorruptPatch = errors.New("corrupt patch")
+
+// offtin decodes the sign-magnitude 64-bit integer used by bsdiff.
+func offtin(buf []byte) int64 {
+	y := int64(binary.LittleEndian.Uint64(buf) &^ (1 << 63))
+	if buf[7]&0x80 != 0 {
+		y = -y
+	}
+	return y
+}
+
+// Header : parsed header of the patch.
+type Header struct {
+	CtrlLen int64
+	DiffLen int64
+	NewSize int64
+}
+
+// ReadHeader reads and validates the header of the patch.
+func ReadHeader(patch io.ReaderAt, patchSize int64) (Header, error) {
+	var hdr Header
+	buf := make([]byte, headerSize)
+	if _, err := patch.ReadAt(buf, 0); err != nil {
+		return hdr, fmt.Errorf("failed to read header: %v", err)
+	}
+	if string(buf[:len(magic)]) != magic {
+		return hdr, fmt.Errorf("%w: bad magic", ErrCorruptPatch)
+	}
+	hdr.CtrlLen = offtin(buf[8:])
+	hdr.DiffLen = offtin(buf[16:])
+	hdr.NewSize = offtin(buf[24:])
+	if hdr.CtrlLen < 0 || hdr.DiffLen < 0 || hdr.NewSize < 0 ||
+		headerSize+hdr.CtrlLen+hdr.DiffLen > patchSize {
+		return hdr, fmt.Errorf("%w: bad header", ErrCorruptPatch)
+	}
+	return hdr, nil
+}
+
+// Apply reconstructs the new content from the old content of size oldSize
+// and the patch of size patchSize, and writes it to out.
+// Returns the number of bytes written.
+func Apply(old io.ReaderAt, oldSize int64, patch io.ReaderAt, patchSize int64,
+	out io.Writer) (int64, error) {
+
+	hdr, err := ReadHeader(patch, patchSize)
+	if err != nil {
+		return 0, err
+	}
+	diffOff := headerSize + hdr.CtrlLen
+	extraOff := diffOff + hdr.DiffLen
+	ctrlBlock := bzip2.NewReader(io.NewSectionReader(patch, headerSize, hdr.CtrlLen))
+	diffBlock := bzip2.NewReader(io.NewSectionReader(patch, diffOff, hdr.DiffLen))
+	extraBlock := bzip2.NewReader(io.NewSectionReader(patch, extraOff,
+		patchSize-extraOff))
+
+	w := bufio.NewWriterSize(out, bufSize)
+	buf := make([]byte, bufSize)
+	oldBuf := make([]byte, bufSize)
+	ctrlBuf := make([]byte, 24)
+	var oldPos, newPos int64
+	for newPos < hdr.NewSize {
+		if _, err := io.ReadFull(ctrlBlock, ctrlBuf); err != nil {
+			return newPos, fmt.Errorf("%w: failed to read control block: %v",
+				ErrCorruptPatch, err)
+		}
+		diffLen := offtin(ctrlBuf[0:])
+		extraLen := offtin(ctrlBuf[8:])
+		seek := offtin(ctrlBuf[16:])
+		if diffLen < 0 || extraLen < 0 ||
+			newPos+diffLen+extraLen > hdr.NewSize {
+			return newPos, fmt.Errorf("%w: bad control entry", ErrCorruptPatch)
+		}
+
+		// Add the diff block to the old content.
+		for diffLen > 0 {
+			n := int64(len(buf))
+			if n > diffLen {
+				n = diffLen
+			}
+			chunk := buf[:n]
+			if _, err := io.ReadFull(diffBlock, chunk); err != nil {
+				return newPos, fmt.Errorf("%w: failed to read diff block: %v",
+					ErrCorruptPatch, err)
+			}
+			if err := addOld(old, oldSize, oldPos, chunk, oldBuf[:n]); err != nil {
+				return newPos, err
+			}
+			if _, err := w.Write(chunk); err != nil {
+				return newPos, err
+			}
+			oldPos += n
+			newPos += n
+			diffLen -= n
+		}
+
+		// Copy the extra block.
+		if extraLen > 0 {
+			n, err := io.CopyN(w, extraBlock, extraLen)
+			newPos += n
+			if err != nil {
+				return newPos, fmt.Errorf("%w: failed to read extra block: %v",
+					ErrCorruptPatch, err)
+			}
+		}
+		oldPos += seek
+	}
+	if err := w.Flush(); err != nil {
+		return newPos, err
+	}
+	return newPos, nil
+}
+
+// addOld adds bytes of the old content at oldPos to chunk. Positions
+// outside of the old content are skipped, as done by bspatch.
+func addOld(old io.ReaderAt, oldSize, oldPos int64, chunk, oldBuf []byte) error {
+	start := oldPos
+	if start < 0 {
+		start = 0
+	}
+	end := oldPos + int64(len(chunk))
+	if end > oldSize {
+		end = oldSize
+	}
+	if start >= end {
+		return nil
+	}
+	data := oldBuf[:end-start]
+	if _, err := old.ReadAt(data, start); err != nil && err != io.EOF {
+		return fmt.Errorf("failed to read old content: %v", err)
+	}
+	offset := start - oldPos
+	for i, b := range data {
+		chunk[offset+int64(i)] += b
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package checkpoint exports the config checkpoint, which zedagent uses to
+// boot the app instances when the controller is unreachable, together with
+// its signature as a portable ConfigCheckpoint bundle, and imports such a
+// bundle on another device or after a factory reset once it is verified
+// against the controller signing certificate.
+package checkpoint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	zauth "github.com/lf-edge/eve/api/go/auth"
+	zconfig "github.com/lf-edge/eve/api/go/config"
+	fileutils "github.com/lf-edge/eve/pkg/pillar/utils/file"
+	"github.com/lf-edge/eve/pkg/pillar/zedcloud"
+	"google.golang.org/protobuf/encoding/protojson"
+	protov2 "google.golang.org/protobuf/proto"
+)
+
+const (
+	// ConfigFilename : the last ConfigResponse received from the controller
+	ConfigFilename = "lastconfig"
+	// SignedConfigFilename : the AuthContainer which carried ConfigFilename
+	SignedConfigFilename = "lastconfig.signed"
+	// ControllerCertsFilename : the ZControllerCert received from the controller
+	ControllerCertsFilename = "controllercerts"
+	// ImportedFilename : marks the checkpoint as imported; zedagent uses
+	// an imported checkpoint regardless of the boot reason and age
+	ImportedFilename = "imported"
+)
+
+// ErrNotSigned : the checkpoint has no signature, e.g. it was received
+// using the V1 API
+var ErrNotSigned = errors.New("config checkpoint is not signed")
+
+// SignedPayload returns the payload of the AuthContainer in signedConfig
+// without verifying the signature.
+func SignedPayload(signedConfig []byte) ([]byte, error) {
+	sm := &zauth.AuthContainer{}
+	if err := proto.Unmarshal(signedConfig, sm); err != nil {
+		return nil, err
+	}
+	return sm.GetProtectedPayload().GetPayload(), nil
+}
+
+// Export returns the bundle of the config checkpoint in dirName. The bundle
+// is marshalled deterministically, hence exporting the same checkpoint
+// always gives the same bundle.
+func Export(dirName string) ([]byte, error) {
+	config, err := ioutil.ReadFile(filepath.Join(dirName, ConfigFilename))
+	if err != nil {
+		return nil, err
+	}
+	signedConfig, err := ioutil.ReadFile(filepath.Join(dirName, SignedConfigFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotSigned
+		}
+		return nil, err
+	}
+	payload, err := SignedPayload(signedConfig)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", SignedConfigFilename, err)
+	}
+	if !bytes.Equal(payload, config) {
+		return nil, fmt.Errorf("%s does not match %s", SignedConfigFilename,
+			ConfigFilename)
+	}
+	cp := &zconfig.ConfigCheckpoint{SignedConfig: signedConfig}
+	certs, err := ioutil.ReadFile(filepath.Join(dirName, ControllerCertsFilename))
+	if err == nil {
+		cp.ControllerCerts = certs
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	return protov2.MarshalOptions{Deterministic: true}.Marshal(cp)
+}
+
+// Decode unmarshals the bundle and the config it carries without verifying
+// the signature.
+func Decode(b []byte) (*zconfig.ConfigCheckpoint, *zconfig.ConfigResponse, error) {
+	cp := &zconfig.ConfigCheckpoint{}
+	if err := proto.Unmarshal(b, cp); err != nil {
+		return nil, nil, err
+	}
+	if len(cp.SignedConfig) == 0 {
+		return nil, nil, ErrNotSigned
+	}
+	payload, err := SignedPayload(cp.SignedConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	configResponse := &zconfig.ConfigResponse{}
+	if err := proto.Unmarshal(payload, configResponse); err != nil {
+		return nil, nil, err
+	}
+	return cp, configResponse, nil
+}
+
+// Verify verifies the signature of the bundle against the controller signing
+// certificate signingCert (PEM). If signingCert is nil, the controller
+// certificates carried by the bundle are verified against the root
+// certificate of the device and the signing certificate among them is used.
+// Returns the certificate the bundle was verified against.
+func Verify(ctx *zedcloud.ZedCloudContext, cp *zconfig.ConfigCheckpoint,
+	signingCert []byte) ([]byte, error) {
+	if signingCert == nil {
+		if len(cp.ControllerCerts) == 0 {
+			return nil, errors.New("no controller signing certificate")
+		}
+		var err error
+		signingCert, err = zedcloud.VerifySigningCertChain(ctx, cp.ControllerCerts)
+		if err != nil {
+			return nil, fmt.Errorf("controller certificates: %v", err)
+		}
+	}
+	if _, err := zedcloud.VerifyAuthContainerWithCert(ctx, cp.SignedConfig,
+		signingCert); err != nil {
+		return nil, err
+	}
+	return signingCert, nil
+}
+
+// Import verifies the bundle (see Verify) and writes it as the config
+// checkpoint into dirName, marked as imported. Returns the certificate the
+// bundle was verified against.
+func Import(ctx *zedcloud.ZedCloudContext, dirName string, b []byte,
+	signingCert []byte) ([]byte, error) {
+	cp, _, err := Decode(b)
+	if err != nil {
+		return nil, err
+	}
+	signingCert, err = Verify(ctx, cp, signingCert)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := SignedPayload(cp.SignedConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dirName, 0700); err != nil {
+		return nil, err
+	}
+	if len(cp.ControllerCerts) != 0 {
+		if err := fileutils.WriteRename(filepath.Join(dirName, ControllerCertsFilename),
+			cp.ControllerCerts); err != nil {
+			return nil, err
+		}
+	}
+	if err := fileutils.WriteRename(filepath.Join(dirName, SignedConfigFilename),
+		cp.SignedConfig); err != nil {
+		return nil, err
+	}
+	if err := fileutils.WriteRename(filepath.Join(dirName, ConfigFilename),
+		payload); err != nil {
+		return nil, err
+	}
+	if err := fileutils.WriteRename(filepath.Join(dirName, ImportedFilename),
+		nil); err != nil {
+		return nil, err
+	}
+	return signingCert, nil
+}
+
+// Format returns the bundle in human-readable form, with the config decoded
+// as JSON. verifyErr is the result of Verify.
+func Format(cp *zconfig.ConfigCheckpoint, configResponse *zconfig.ConfigResponse,
+	verifyErr error) (string, error) {
+	var sb strings.Builder
+	config := configResponse.GetConfig()
+	fmt.Fprintf(&sb, "device: %s\n", config.GetId().GetUuid())
+	fmt.Fprintf(&sb, "config hash: %s\n", configResponse.GetConfigHash())
+	if verifyErr != nil {
+		fmt.Fprintf(&sb, "signature: NOT VERIFIED: %v\n", verifyErr)
+	} else {
+		fmt.Fprintf(&sb, "signature: verified\n")
+	}
+	fmt.Fprintf(&sb, "controller certificates: %t\n", len(cp.ControllerCerts) != 0)
+	b, err := protojson.MarshalOptions{Multiline: true}.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	sb.Write(b)
+	sb.WriteString("\n")
+	return sb.String(), nil
+}
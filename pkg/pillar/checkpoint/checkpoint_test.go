@@ -0,0 +1,172 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package checkpoint_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	zauth "github.com/lf-edge/eve/api/go/auth"
+	zconfig "github.com/lf-edge/eve/api/go/config"
+	zcommon "github.com/lf-edge/eve/api/go/evecommon"
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/lf-edge/eve/pkg/pillar/checkpoint"
+	"github.com/lf-edge/eve/pkg/pillar/zedcloud"
+	"github.com/sirupsen/logrus"
+)
+
+// newSigningCert returns a self-signed certificate (PEM) and its key
+func newSigningCert(t *testing.T) ([]byte, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "controller signing"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template,
+		&key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), key
+}
+
+// sign returns the AuthContainer with payload signed as by the controller
+func sign(t *testing.T, certPEM []byte, key *ecdsa.PrivateKey, payload []byte) []byte {
+	hash := sha256.Sum256(payload)
+	r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	certHash := sha256.Sum256(certPEM)
+	b, err := proto.Marshal(&zauth.AuthContainer{
+		ProtectedPayload: &zauth.AuthBody{Payload: payload},
+		Algo:             zcommon.HashAlgorithm_HASH_ALGORITHM_SHA256_32BYTES,
+		SenderCertHash:   certHash[:],
+		SignatureHash:    signature,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func writeCheckpoint(t *testing.T, dirName string, config, signedConfig []byte) {
+	if err := ioutil.WriteFile(filepath.Join(dirName, checkpoint.ConfigFilename),
+		config, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dirName, checkpoint.SignedConfigFilename),
+		signedConfig, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	log := base.NewSourceLogObject(logrus.StandardLogger(), "test", 1234)
+	ctx := zedcloud.NewContext(log, zedcloud.ContextOptions{})
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	srcDir := filepath.Join(dir, "src")
+	dstDir := filepath.Join(dir, "dst")
+	if err := os.Mkdir(srcDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM, key := newSigningCert(t)
+	config, err := proto.Marshal(&zconfig.ConfigResponse{
+		Config: &zconfig.EdgeDevConfig{
+			Id: &zconfig.UUIDandVersion{Uuid: "d1125b0f-633d-459c-99ea-2ef9b3b3f0f5"},
+		},
+		ConfigHash: "hash",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeCheckpoint(t, srcDir, config, sign(t, certPEM, key, config))
+
+	b, err := checkpoint.Export(srcDir)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	b2, err := checkpoint.Export(srcDir)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if !bytes.Equal(b, b2) {
+		t.Errorf("Export is not reproducible")
+	}
+
+	cp, configResponse, err := checkpoint.Decode(b)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if configResponse.GetConfigHash() != "hash" {
+		t.Errorf("unexpected config hash %s", configResponse.GetConfigHash())
+	}
+	if _, err := checkpoint.Format(cp, configResponse, nil); err != nil {
+		t.Errorf("Format failed: %v", err)
+	}
+
+	// signed by other certificate
+	otherCertPEM, _ := newSigningCert(t)
+	if _, err := checkpoint.Import(&ctx, dstDir, b, otherCertPEM); err == nil {
+		t.Errorf("Import succeeded with wrong certificate")
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, checkpoint.ConfigFilename)); err == nil {
+		t.Errorf("Import wrote checkpoint with wrong certificate")
+	}
+
+	if _, err := checkpoint.Import(&ctx, dstDir, b, certPEM); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	imported, err := ioutil.ReadFile(filepath.Join(dstDir, checkpoint.ConfigFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(imported, config) {
+		t.Errorf("imported config differs")
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, checkpoint.ImportedFilename)); err != nil {
+		t.Errorf("imported checkpoint is not marked: %v", err)
+	}
+	b3, err := checkpoint.Export(dstDir)
+	if err != nil {
+		t.Fatalf("Export of imported checkpoint failed: %v", err)
+	}
+	if !bytes.Equal(b, b3) {
+		t.Errorf("Export of imported checkpoint differs")
+	}
+
+	// tampered config
+	tampered := append([]byte{}, config...)
+	tampered[len(tampered)-1] ^= 0xff
+	writeCheckpoint(t, srcDir, tampered, sign(t, certPEM, key, config))
+	if _, err := checkpoint.Export(srcDir); err == nil {
+		t.Errorf("Export succeeded with signature of other config")
+	}
+}
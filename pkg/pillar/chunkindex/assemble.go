@@ -0,0 +1,165 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package chunkindex
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+)
+
+// location of a chunk in a local file
+type location struct {
+	path   string
+	offset int64
+	size   uint32
+}
+
+// LocalChunks : chunks of the index found in local files.
+type LocalChunks struct {
+	idx       *Index
+	wanted    map[string]bool
+	locations map[string]location
+}
+
+// NewLocalChunks returns empty set of local chunks for the index.
+func NewLocalChunks(idx *Index) *LocalChunks {
+	return &LocalChunks{
+		idx:       idx,
+		wanted:    idx.Wanted(),
+		locations: make(map[string]location),
+	}
+}
+
+// Complete returns true if all chunks of the index were found.
+func (l *LocalChunks) Complete() bool {
+	return len(l.locations) == len(l.wanted)
+}
+
+// Found returns the number of distinct chunks found.
+func (l *LocalChunks) Found() int {
+	return len(l.locations)
+}
+
+// AddFile chunks the file with the parameters of the index
+// and records chunks of the index found in it.
+func (l *LocalChunks) AddFile(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	chunker, err := NewChunker(bufio.NewReader(f), l.idx.Chunker)
+	if err != nil {
+		return err
+	}
+	var offset int64
+	for !l.Complete() {
+		data, err := chunker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", filename, err)
+		}
+		sum := sha256.Sum256(data)
+		sha := hex.EncodeToString(sum[:])
+		if _, found := l.locations[sha]; !found && l.wanted[sha] {
+			l.locations[sha] = location{path: filename, offset: offset,
+				size: uint32(len(data))}
+		}
+		offset += int64(len(data))
+	}
+	return nil
+}
+
+// read returns the chunk from the local file if it is still there.
+func (l *LocalChunks) read(chunk Chunk) []byte {
+	loc, found := l.locations[chunk.Sha256]
+	if !found || loc.size != chunk.Size {
+		return nil
+	}
+	f, err := os.Open(loc.path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	data := make([]byte, loc.size)
+	if _, err := f.ReadAt(data, loc.offset); err != nil {
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != chunk.Sha256 {
+		// File changed since it was chunked.
+		delete(l.locations, chunk.Sha256)
+		return nil
+	}
+	return data
+}
+
+// FetchFunc fetches the chunk from the datastore.
+type FetchFunc func(chunk Chunk) ([]byte, error)
+
+// Stats : statistics of assembling
+type Stats struct {
+	// Bytes reused from local files
+	Reused uint64
+	// Bytes fetched from the datastore
+	Fetched uint64
+}
+
+// Assemble writes the image described by the index to out, using
+// local chunks where possible and fetching the others. Every chunk and
+// the whole image are checked against sha256 from the index.
+// Progress (if not nil) is called with the number of bytes written
+// after every chunk.
+func Assemble(local *LocalChunks, fetch FetchFunc, out *os.File,
+	progress func(written uint64)) (Stats, error) {
+
+	var stats Stats
+	idx := local.idx
+	h := sha256.New()
+	var offset int64
+	for i, chunk := range idx.Chunks {
+		data := local.read(chunk)
+		if data != nil {
+			stats.Reused += uint64(chunk.Size)
+		} else {
+			var err error
+			data, err = fetch(chunk)
+			if err != nil {
+				return stats, fmt.Errorf("failed to fetch chunk %d (%s): %v",
+					i, chunk.Sha256, err)
+			}
+			sum := sha256.Sum256(data)
+			if uint32(len(data)) != chunk.Size ||
+				hex.EncodeToString(sum[:]) != chunk.Sha256 {
+				return stats, fmt.Errorf("chunk %d does not match sha256 %s",
+					i, chunk.Sha256)
+			}
+			stats.Fetched += uint64(chunk.Size)
+		}
+		if _, err := out.Write(data); err != nil {
+			return stats, err
+		}
+		h.Write(data)
+		// Chunk can repeat in the image; read it from the output next time.
+		if _, found := local.locations[chunk.Sha256]; !found {
+			local.locations[chunk.Sha256] = location{path: out.Name(),
+				offset: offset, size: chunk.Size}
+		}
+		offset += int64(chunk.Size)
+		if progress != nil {
+			progress(uint64(offset))
+		}
+	}
+	if sha := hex.EncodeToString(h.Sum(nil)); sha != idx.Sha256 {
+		return stats, fmt.Errorf("assembled image has sha256 %s, expected %s",
+			sha, idx.Sha256)
+	}
+	return stats, nil
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package chunkindex
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ChunkerParams : parameters of content-defined chunking.
+// The same parameters have to be used when creating the index and
+// when looking for chunks in local files.
+type ChunkerParams struct {
+	// Min : minimum size of a chunk (but the last one)
+	Min uint32 `json:"min"`
+	// Avg : expected average size of a chunk; power of 2
+	Avg uint32 `json:"avg"`
+	// Max : maximum size of a chunk
+	Max uint32 `json:"max"`
+}
+
+// DefaultChunkerParams : parameters suitable for VM images.
+var DefaultChunkerParams = ChunkerParams{
+	Min: 16 * 1024,
+	Avg: 64 * 1024,
+	Max: 256 * 1024,
+}
+
+// Validate checks that the parameters make sense.
+func (p ChunkerParams) Validate() error {
+	if p.Min == 0 || p.Min > p.Avg || p.Avg > p.Max {
+		return fmt.Errorf("invalid chunker parameters %d/%d/%d",
+			p.Min, p.Avg, p.Max)
+	}
+	if p.Avg&(p.Avg-1) != 0 {
+		return fmt.Errorf("average chunk size %d is not a power of 2", p.Avg)
+	}
+	if p.Max > maxChunkSize {
+		return fmt.Errorf("maximum chunk size %d is over %d", p.Max, maxChunkSize)
+	}
+	return nil
+}
+
+// Chunks are kept in memory.
+const maxChunkSize = 16 * 1024 * 1024
+
+// gear : table for the gear rolling hash. Generated by splitmix64
+// from a fixed seed, so that it does not have to be distributed
+// with the index.
+var gear = func() [256]uint64 {
+	var table [256]uint64
+	x := uint64(0x6576652d63686e6b) // "eve-chnk"
+	for i := range table {
+		x += 0x9e3779b97f4a7c15
+		z := x
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+	return table
+}()
+
+// cutPoint returns the size of the next chunk at the beginning of data.
+// Boundary is placed where the gear hash of the preceding 64 bytes
+// has the low bits (as many as given by Avg) zero.
+func cutPoint(data []byte, p ChunkerParams) int {
+	size := len(data)
+	if size <= int(p.Min) {
+		return size
+	}
+	if size > int(p.Max) {
+		size = int(p.Max)
+	}
+	mask := uint64(p.Avg - 1)
+	var hash uint64
+	for i := int(p.Min); i < size; i++ {
+		hash = (hash << 1) + gear[data[i]]
+		if hash&mask == 0 {
+			return i + 1
+		}
+	}
+	return size
+}
+
+// Chunker splits the content of a reader into content-defined chunks.
+type Chunker struct {
+	r      io.Reader
+	params ChunkerParams
+	buf    []byte
+	start  int
+	end    int
+	eof    bool
+}
+
+// NewChunker returns chunker reading from r.
+func NewChunker(r io.Reader, params ChunkerParams) (*Chunker, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return &Chunker{
+		r:      r,
+		params: params,
+		buf:    make([]byte, params.Max),
+	}, nil
+}
+
+// Next returns the next chunk or io.EOF at the end of the content.
+// The chunk is valid only until the next call.
+func (c *Chunker) Next() ([]byte, error) {
+	if c.end-c.start < int(c.params.Max) && !c.eof {
+		copy(c.buf, c.buf[c.start:c.end])
+		c.end -= c.start
+		c.start = 0
+		n, err := io.ReadFull(c.r, c.buf[c.end:])
+		c.end += n
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			c.eof = true
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	if c.start == c.end {
+		return nil, io.EOF
+	}
+	n := cutPoint(c.buf[c.start:c.end], c.params)
+	chunk := c.buf[c.start : c.start+n]
+	c.start += n
+	return chunk, nil
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package chunkindex implements deduplicated downloads of large images.
+// The image is split into content-defined chunks and described by an
+// index listing sha256 and size of every chunk. Chunks are stored in
+// the datastore next to the index, under <store>/<sha256[0:4]>/<sha256>.
+// The image is reassembled from chunks found in local files (e.g.
+// previous versions of the image) and only the missing chunks are fetched.
+package chunkindex
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+)
+
+const (
+	// IndexVersion : version of the index format
+	IndexVersion = 1
+	// DefaultStore : location of chunks relative to the index
+	DefaultStore = "chunks"
+)
+
+// Chunk : chunk of the image
+type Chunk struct {
+	Sha256 string `json:"sha256"`
+	Size   uint32 `json:"size"`
+}
+
+// Index : chunk index of the image; chunks are in the order
+// in which they appear in the image.
+type Index struct {
+	Version int           `json:"version"`
+	Size    uint64        `json:"size"`
+	Sha256  string        `json:"sha256"`
+	Chunker ChunkerParams `json:"chunker"`
+	// Store : location of chunks relative to the index,
+	// DefaultStore if empty
+	Store  string  `json:"store,omitempty"`
+	Chunks []Chunk `json:"chunks"`
+}
+
+func isSha256(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// ParseIndex reads and validates the index.
+func ParseIndex(r io.Reader) (*Index, error) {
+	var idx Index
+	if err := json.NewDecoder(r).Decode(&idx); err != nil {
+		return nil, fmt.Errorf("failed to decode chunk index: %v", err)
+	}
+	if idx.Version != IndexVersion {
+		return nil, fmt.Errorf("unsupported chunk index version %d", idx.Version)
+	}
+	if err := idx.Chunker.Validate(); err != nil {
+		return nil, err
+	}
+	if !isSha256(idx.Sha256) {
+		return nil, fmt.Errorf("invalid sha256 %q of the image", idx.Sha256)
+	}
+	var size uint64
+	for i, chunk := range idx.Chunks {
+		if !isSha256(chunk.Sha256) {
+			return nil, fmt.Errorf("invalid sha256 %q of chunk %d",
+				chunk.Sha256, i)
+		}
+		if chunk.Size == 0 || chunk.Size > idx.Chunker.Max {
+			return nil, fmt.Errorf("invalid size %d of chunk %d",
+				chunk.Size, i)
+		}
+		size += uint64(chunk.Size)
+	}
+	if size != idx.Size {
+		return nil, fmt.Errorf("chunks add up to %d bytes, expected %d",
+			size, idx.Size)
+	}
+	return &idx, nil
+}
+
+// CreateIndex chunks the content of r and returns its index.
+// The chunks are passed to store (if not nil); the slice is valid
+// only during the call.
+func CreateIndex(r io.Reader, params ChunkerParams,
+	store func(chunk Chunk, data []byte) error) (*Index, error) {
+
+	chunker, err := NewChunker(r, params)
+	if err != nil {
+		return nil, err
+	}
+	idx := &Index{
+		Version: IndexVersion,
+		Chunker: params,
+	}
+	h := sha256.New()
+	for {
+		data, err := chunker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		h.Write(data)
+		sum := sha256.Sum256(data)
+		chunk := Chunk{Sha256: hex.EncodeToString(sum[:]), Size: uint32(len(data))}
+		if store != nil {
+			if err := store(chunk, data); err != nil {
+				return nil, err
+			}
+		}
+		idx.Chunks = append(idx.Chunks, chunk)
+		idx.Size += uint64(len(data))
+	}
+	idx.Sha256 = hex.EncodeToString(h.Sum(nil))
+	return idx, nil
+}
+
+// ChunkName returns the name of the chunk relative to the index.
+func (idx *Index) ChunkName(sha string) string {
+	store := idx.Store
+	if store == "" {
+		store = DefaultStore
+	}
+	return path.Join(store, sha[:4], sha)
+}
+
+// Wanted returns set of sha256 of all chunks.
+func (idx *Index) Wanted() map[string]bool {
+	wanted := make(map[string]bool, len(idx.Chunks))
+	for _, chunk := range idx.Chunks {
+		wanted[chunk.Sha256] = true
+	}
+	return wanted
+}
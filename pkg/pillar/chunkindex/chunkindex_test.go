@@ -0,0 +1,191 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package chunkindex_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lf-edge/eve/pkg/pillar/chunkindex"
+)
+
+var testParams = chunkindex.ChunkerParams{Min: 1024, Avg: 4096, Max: 16384}
+
+func randomData(size int) []byte {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(1)).Read(data)
+	return data
+}
+
+// newVersion returns data with a few bytes inserted and modified.
+func newVersion(data []byte) []byte {
+	v2 := append([]byte{}, data[:len(data)/3]...)
+	v2 = append(v2, []byte("inserted by the new version")...)
+	v2 = append(v2, data[len(data)/3:]...)
+	v2[len(v2)*2/3] ^= 0xff
+	return v2
+}
+
+func createIndex(t *testing.T, data []byte) (*chunkindex.Index, map[string][]byte) {
+	store := make(map[string][]byte)
+	idx, err := chunkindex.CreateIndex(bytes.NewReader(data), testParams,
+		func(chunk chunkindex.Chunk, data []byte) error {
+			store[chunk.Sha256] = append([]byte{}, data...)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("CreateIndex failed: %v", err)
+	}
+	return idx, store
+}
+
+func TestChunker(t *testing.T) {
+	data := randomData(1024 * 1024)
+	idx, _ := createIndex(t, data)
+	if idx.Size != uint64(len(data)) {
+		t.Fatalf("index size %d, expected %d", idx.Size, len(data))
+	}
+	for i, chunk := range idx.Chunks[:len(idx.Chunks)-1] {
+		if chunk.Size < testParams.Min || chunk.Size > testParams.Max {
+			t.Errorf("chunk %d has size %d out of bounds", i, chunk.Size)
+		}
+	}
+	// Chunk boundaries are content-defined, hence most chunks survive
+	// changes in the middle of the content.
+	idx2, _ := createIndex(t, newVersion(data))
+	wanted := idx.Wanted()
+	shared := 0
+	for _, chunk := range idx2.Chunks {
+		if wanted[chunk.Sha256] {
+			shared++
+		}
+	}
+	if shared < len(idx2.Chunks)-4 {
+		t.Errorf("only %d out of %d chunks shared", shared, len(idx2.Chunks))
+	}
+}
+
+func TestParseIndex(t *testing.T) {
+	idx, _ := createIndex(t, randomData(64*1024))
+	encoded, err := json.Marshal(idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := chunkindex.ParseIndex(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatalf("ParseIndex failed: %v", err)
+	}
+	if parsed.Sha256 != idx.Sha256 || len(parsed.Chunks) != len(idx.Chunks) {
+		t.Errorf("parsed index does not match")
+	}
+	if name := parsed.ChunkName(idx.Chunks[0].Sha256); name !=
+		filepath.Join("chunks", idx.Chunks[0].Sha256[:4], idx.Chunks[0].Sha256) {
+		t.Errorf("unexpected chunk name %s", name)
+	}
+
+	idx.Size++
+	encoded, _ = json.Marshal(idx)
+	if _, err := chunkindex.ParseIndex(bytes.NewReader(encoded)); err == nil {
+		t.Errorf("expected error for wrong size")
+	}
+	idx.Size--
+	idx.Chunker.Avg = 5000
+	encoded, _ = json.Marshal(idx)
+	if _, err := chunkindex.ParseIndex(bytes.NewReader(encoded)); err == nil {
+		t.Errorf("expected error for invalid chunker parameters")
+	}
+}
+
+func TestAssemble(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chunkindex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	v1 := randomData(1024 * 1024)
+	oldFile := filepath.Join(dir, "v1")
+	if err := ioutil.WriteFile(oldFile, v1, 0644); err != nil {
+		t.Fatal(err)
+	}
+	v2 := newVersion(v1)
+	// Repeat part of the content to have a duplicate chunk.
+	v2 = append(v2, v2[:64*1024]...)
+	idx, store := createIndex(t, v2)
+
+	local := chunkindex.NewLocalChunks(idx)
+	if err := local.AddFile(oldFile); err != nil {
+		t.Fatalf("AddFile failed: %v", err)
+	}
+	if local.Found() == 0 || local.Complete() {
+		t.Fatalf("unexpected number of local chunks %d", local.Found())
+	}
+	fetched := make(map[string]int)
+	fetch := func(chunk chunkindex.Chunk) ([]byte, error) {
+		fetched[chunk.Sha256]++
+		return store[chunk.Sha256], nil
+	}
+	out, err := os.Create(filepath.Join(dir, "v2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	var written uint64
+	stats, err := chunkindex.Assemble(local, fetch, out,
+		func(w uint64) { written = w })
+	if err != nil {
+		t.Fatalf("Assemble failed: %v", err)
+	}
+	if written != uint64(len(v2)) || stats.Reused+stats.Fetched != uint64(len(v2)) {
+		t.Errorf("unexpected sizes: written %d, stats %+v", written, stats)
+	}
+	if stats.Fetched > stats.Reused/4 {
+		t.Errorf("fetched too much: %+v", stats)
+	}
+	for sha, count := range fetched {
+		if count > 1 {
+			t.Errorf("chunk %s fetched %d times", sha, count)
+		}
+	}
+	if _, err := out.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	assembled, err := ioutil.ReadAll(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(assembled, v2) {
+		t.Errorf("assembled image does not match")
+	}
+}
+
+func TestAssembleBadChunk(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chunkindex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	idx, store := createIndex(t, randomData(128*1024))
+	fetch := func(chunk chunkindex.Chunk) ([]byte, error) {
+		data := append([]byte{}, store[chunk.Sha256]...)
+		data[0] ^= 0xff
+		return data, nil
+	}
+	out, err := os.Create(filepath.Join(dir, "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	_, err = chunkindex.Assemble(chunkindex.NewLocalChunks(idx), fetch, out, nil)
+	if err == nil {
+		t.Errorf("expected error for corrupted chunk")
+	}
+}
@@ -25,6 +25,12 @@ func getEncryptionBlock(
 	decBlock.WifiUserName = zconfigDecBlockPtr.WifiUserName
 	decBlock.WifiPassword = zconfigDecBlockPtr.WifiPassword
 	decBlock.ProtectedUserData = zconfigDecBlockPtr.ProtectedUserData
+	decBlock.CellularNetUsername = zconfigDecBlockPtr.CellularNetUsername
+	decBlock.CellularNetPassword = zconfigDecBlockPtr.CellularNetPassword
+	decBlock.Dot1xPassword = zconfigDecBlockPtr.Dot1XPassword
+	decBlock.Dot1xClientKey = zconfigDecBlockPtr.Dot1XClientKey
+	decBlock.Dot1xClientCert = zconfigDecBlockPtr.Dot1XClientCert
+	decBlock.Dot1xCACert = zconfigDecBlockPtr.Dot1XCACert
 	return decBlock
 }
 
@@ -0,0 +1,194 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// attestverify verifies a captured attestation quote request offline,
+// using the verifier package. It is meant for debugging attestation
+// and PCR mismatches, e.g. against a local stand-in controller.
+
+package attestverify
+
+import (
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/lf-edge/eve/api/go/attest"
+	zauth "github.com/lf-edge/eve/api/go/auth"
+	"github.com/lf-edge/eve/pkg/pillar/attest/verifier"
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/lf-edge/eve/pkg/pillar/pubsub"
+	"github.com/sirupsen/logrus"
+)
+
+var logger *logrus.Logger
+var log *base.LogObject
+
+//readAttestReq reads a ZAttestReq protobuf message from file, optionally
+//wrapped into AuthContainer, as it is sent to the controller
+func readAttestReq(filename string, authContainer bool) (*attest.ZAttestReq, error) {
+	reqBytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if authContainer {
+		sm := &zauth.AuthContainer{}
+		if err := proto.Unmarshal(reqBytes, sm); err != nil {
+			return nil, fmt.Errorf("failed to parse AuthContainer: %v", err)
+		}
+		reqBytes = sm.GetProtectedPayload().GetPayload()
+	}
+	req := &attest.ZAttestReq{}
+	if err := proto.Unmarshal(reqBytes, req); err != nil {
+		return nil, fmt.Errorf("failed to parse ZAttestReq: %v", err)
+	}
+	return req, nil
+}
+
+//readCerts reads all PEM encoded certificates from file
+func readCerts(filename string) ([]*x509.Certificate, error) {
+	certBytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, certBytes = pem.Decode(certBytes)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate in %s: %v", filename, err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found in %s", filename)
+	}
+	return certs, nil
+}
+
+func printResult(result *verifier.Result, verbose bool) {
+	fmt.Printf("Quote signature: OK\n")
+	if result.NonceChecked {
+		fmt.Printf("Nonce: OK\n")
+	} else {
+		fmt.Printf("Nonce: not checked\n")
+	}
+	quoteInfo := result.Attest.AttestedQuoteInfo
+	fmt.Printf("Quoted PCRs: %v (0x%x bank)\n",
+		quoteInfo.PCRSelection.PCRs, quoteInfo.PCRSelection.Hash)
+	fmt.Printf("Event log replayed: %t\n", result.EventLogReplayed)
+	fmt.Printf("Policy checked: %t\n", result.PolicyChecked)
+	for _, mismatch := range result.Mismatches {
+		fmt.Println(mismatch)
+		if !verbose {
+			continue
+		}
+		for _, event := range mismatch.Events {
+			fmt.Printf("\tevent %d: type 0x%x, digest %x\n", event.GetIndex(),
+				event.GetEventType(), event.GetDigest().GetDigest())
+		}
+	}
+	if result.OK() {
+		fmt.Println("Attestation: OK")
+	} else {
+		fmt.Println("Attestation: FAILED")
+	}
+}
+
+// Run is the entry point for attestverify, from zedbox
+func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) int {
+	logger = loggerArg
+	log = logArg
+	reqPtr := flag.String("r", "", "Captured attestation quote request (ZAttestReq protobuf)")
+	authPtr := flag.Bool("a", false, "Request is wrapped into AuthContainer")
+	certPtr := flag.String("c", "", "Quote certificate (PEM)")
+	rootPtr := flag.String("ca", "", "Certificate(s) to verify the quote certificate with, e.g. device certificate (PEM)")
+	noncePtr := flag.String("n", "", "Expected nonce (hex)")
+	policyPtr := flag.String("p", "", "Reference PCR values policy (JSON)")
+	genPolicyPtr := flag.Bool("g", false, "Print a policy accepting PCR values from the request")
+	verbosePtr := flag.Bool("v", false, "Print event log entries of mismatching PCRs")
+	flag.Parse()
+
+	if *reqPtr == "" {
+		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
+		flag.PrintDefaults()
+		return 1
+	}
+	req, err := readAttestReq(*reqPtr, *authPtr)
+	if err != nil {
+		log.Error(err)
+		return 1
+	}
+	if *genPolicyPtr {
+		pcrs, err := verifier.QuotedPCRs(req.GetQuote())
+		if err != nil {
+			log.Error(err)
+			return 1
+		}
+		policyBytes, err := json.MarshalIndent(
+			verifier.PolicyFromPCRs(pcrs), "", "  ")
+		if err != nil {
+			log.Error(err)
+			return 1
+		}
+		fmt.Println(string(policyBytes))
+		return 0
+	}
+
+	if *certPtr == "" {
+		log.Error("Quote certificate is required")
+		return 1
+	}
+	certs, err := readCerts(*certPtr)
+	if err != nil {
+		log.Error(err)
+		return 1
+	}
+	opts := verifier.Options{QuoteCert: certs[0]}
+	if *rootPtr != "" {
+		roots, err := readCerts(*rootPtr)
+		if err != nil {
+			log.Error(err)
+			return 1
+		}
+		opts.Roots = x509.NewCertPool()
+		for _, root := range roots {
+			opts.Roots.AddCert(root)
+		}
+	}
+	if *noncePtr != "" {
+		if opts.Nonce, err = hex.DecodeString(*noncePtr); err != nil {
+			log.Errorf("Invalid nonce: %v", err)
+			return 1
+		}
+	}
+	if *policyPtr != "" {
+		if opts.Policy, err = verifier.LoadPolicy(*policyPtr); err != nil {
+			log.Error(err)
+			return 1
+		}
+	}
+
+	result, err := verifier.VerifyAttestReq(req, opts)
+	if err != nil {
+		fmt.Printf("Attestation: FAILED: %v\n", err)
+		return 1
+	}
+	printResult(result, *verbosePtr)
+	if !result.OK() {
+		return 1
+	}
+	return 0
+}
@@ -57,6 +57,7 @@ type baseOsMgrContext struct {
 	rebootReason         string    // From last reboot
 	rebootTime           time.Time // From last reboot
 	rebootImage          string    // Image from which the last reboot happened
+	failedHealthGate     string    // Health gate which caused the last reboot
 	currentUpdateRetry   uint32    // UpdateRetryCounter from last retry; it will be sent for info
 	configUpdateRetry    uint32    // UpdateRetryCounter from config; to avoid loop after reboot with failed testing
 
@@ -204,13 +205,7 @@ func handleBaseOsConfigCreate(ctxArg interface{}, key string, configArg interfac
 		BaseOsVersion:  config.BaseOsVersion,
 	}
 
-	status.ContentTreeStatusList = make([]types.ContentTreeStatus,
-		len(config.ContentTreeConfigList))
-
-	for i, ctc := range config.ContentTreeConfigList {
-		cts := &status.ContentTreeStatusList[i]
-		cts.UpdateFromContentTreeConfig(ctc)
-	}
+	initContentTreeStatusList(ctx, config, &status)
 	// Check image count
 	err := validateBaseOsConfig(ctx, config)
 	if err != nil {
@@ -519,6 +514,7 @@ func handleNodeAgentStatusImpl(ctxArg interface{}, key string,
 	ctx.rebootTime = status.RebootTime
 	ctx.rebootReason = status.RebootReason
 	ctx.rebootImage = status.RebootImage
+	ctx.failedHealthGate = status.FailedHealthGate
 	updateBaseOsStatusOnReboot(ctx)
 	log.Functionf("handleNodeAgentStatusImpl(%s) done", key)
 }
@@ -0,0 +1,128 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Installation from deltas: instead of the full image we download a patch
+// against the image in the current partition and reconstruct the image
+// from it. On any error we fall back to the full image.
+
+package baseosmgr
+
+import (
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	"github.com/lf-edge/eve/pkg/pillar/zboot"
+)
+
+// lookupDelta returns the delta of the config against baseVersion
+func lookupDelta(config types.BaseOsConfig, baseVersion string) *types.BaseOsDelta {
+	for i := range config.Deltas {
+		if config.Deltas[i].BaseVersion == baseVersion {
+			return &config.Deltas[i]
+		}
+	}
+	return nil
+}
+
+// selectDelta returns the delta which applies to the current partition
+func selectDelta(ctx *baseOsMgrContext, config types.BaseOsConfig) *types.BaseOsDelta {
+	if len(config.Deltas) == 0 {
+		return nil
+	}
+	curPartName := zboot.GetCurrentPartition()
+	partStatus := getZbootStatus(ctx, curPartName)
+	if partStatus == nil || partStatus.ShortVersion == "" {
+		return nil
+	}
+	delta := lookupDelta(config, partStatus.ShortVersion)
+	if delta == nil {
+		log.Functionf("selectDelta(%s): no delta from %s",
+			config.BaseOsVersion, partStatus.ShortVersion)
+		return nil
+	}
+	if !zboot.IsDeltaFormatSupported(delta.Format) {
+		log.Warnf("selectDelta(%s): unsupported format %s of delta from %s",
+			config.BaseOsVersion, delta.Format, delta.BaseVersion)
+		return nil
+	}
+	if delta.BaseSize == 0 || delta.BaseSha256 == "" || delta.TargetSha256 == "" {
+		log.Warnf("selectDelta(%s): incomplete delta from %s",
+			config.BaseOsVersion, delta.BaseVersion)
+		return nil
+	}
+	return delta
+}
+
+// statusDelta returns the delta used to install the status, if any
+func statusDelta(config types.BaseOsConfig, status *types.BaseOsStatus) *types.BaseOsDelta {
+	if status.DeltaBaseVersion == "" {
+		return nil
+	}
+	return lookupDelta(config, status.DeltaBaseVersion)
+}
+
+// contentTreeConfigList returns the content trees to download for the
+// status: the patch when installing from a delta, the full image otherwise.
+func contentTreeConfigList(config types.BaseOsConfig,
+	status *types.BaseOsStatus) []types.ContentTreeConfig {
+
+	if delta := statusDelta(config, status); delta != nil {
+		return []types.ContentTreeConfig{delta.Patch}
+	}
+	return config.ContentTreeConfigList
+}
+
+// initContentTreeStatusList selects the delta (unless installation
+// from a delta already failed) and fills in ContentTreeStatusList.
+func initContentTreeStatusList(ctx *baseOsMgrContext, config types.BaseOsConfig,
+	status *types.BaseOsStatus) {
+
+	if !status.DeltaFailed && status.DeltaBaseVersion == "" {
+		if delta := selectDelta(ctx, config); delta != nil {
+			log.Noticef("Installing %s from delta against %s",
+				config.BaseOsVersion, delta.BaseVersion)
+			status.DeltaBaseVersion = delta.BaseVersion
+		}
+	}
+	ctcList := contentTreeConfigList(config, status)
+	status.ContentTreeStatusList = make([]types.ContentTreeStatus, len(ctcList))
+	for i, ctc := range ctcList {
+		cts := &status.ContentTreeStatusList[i]
+		cts.UpdateFromContentTreeConfig(ctc)
+	}
+}
+
+// fallbackToFullImage drops the patch and requests the full image
+func fallbackToFullImage(ctx *baseOsMgrContext, config types.BaseOsConfig,
+	status *types.BaseOsStatus, reason string) {
+
+	log.Warnf("Installing %s from delta against %s failed, falling back to full image: %s",
+		config.BaseOsVersion, status.DeltaBaseVersion, reason)
+	for _, cts := range status.ContentTreeStatusList {
+		MaybeRemoveContentTreeConfig(ctx, cts.Key())
+	}
+	status.DeltaBaseVersion = ""
+	status.DeltaFailed = true
+	status.ClearError()
+	initContentTreeStatusList(ctx, config, status)
+	for i := range config.ContentTreeConfigList {
+		MaybeAddContentTreeConfig(ctx, &config.ContentTreeConfigList[i])
+	}
+}
+
+// baseOsKeyForContentTree returns the key of BaseOsStatus which
+// downloads the content tree. The full image has the same UUID as
+// the BaseOsConfig, but the patch of a delta has its own.
+func baseOsKeyForContentTree(ctx *baseOsMgrContext, contentID string) string {
+	items := ctx.pubBaseOsStatus.GetAll()
+	for _, st := range items {
+		status := st.(types.BaseOsStatus)
+		if status.DeltaBaseVersion == "" {
+			continue
+		}
+		for _, cts := range status.ContentTreeStatusList {
+			if cts.Key() == contentID {
+				return status.Key()
+			}
+		}
+	}
+	return contentID
+}
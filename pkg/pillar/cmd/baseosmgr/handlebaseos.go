@@ -85,6 +85,7 @@ func baseOsHandleStatusUpdateImageSha(ctx *baseOsMgrContext, imageSha string) {
 // and then call baseOsHandleStatusUpdate. Just a convenience function.
 func baseOsHandleStatusUpdateUUID(ctx *baseOsMgrContext, id string) {
 	log.Functionf("baseOsHandleStatusUpdateUUID for %s", id)
+	id = baseOsKeyForContentTree(ctx, id)
 	config := lookupBaseOsConfig(ctx, id)
 	if config == nil {
 		// assume that this ContentTreeStatus is not for baseOs
@@ -179,6 +180,11 @@ func doBaseOsStatusUpdate(ctx *baseOsMgrContext, uuidStr string,
 
 	changed := false
 
+	if status.DeltaBaseVersion != "" && statusDelta(config, status) == nil {
+		fallbackToFullImage(ctx, config, status, "delta removed from config")
+		changed = true
+	}
+
 	// check if the ContentSha256 and RelativeURL need to be updated
 	// we only update to latch it from empty, and if it matches exactly
 	cts := lookupContentTreeStatus(ctx, uuidStr)
@@ -354,7 +360,12 @@ func doBaseOsActivate(ctx *baseOsMgrContext, uuidStr string,
 
 	// install the image at proper partition; dd etc
 	changed, proceed, err = installDownloadedObjects(ctx, uuidStr, status.PartitionLabel,
-		&status.ContentTreeStatusList)
+		statusDelta(config, status), &status.ContentTreeStatusList)
+	if err != nil && status.DeltaBaseVersion != "" {
+		fallbackToFullImage(ctx, config, status, err.Error())
+		changed = true
+		return changed
+	}
 	if err != nil {
 		status.SetErrorNow(err.Error())
 		changed = true
@@ -398,7 +409,7 @@ func doBaseOsInstall(ctx *baseOsMgrContext, uuidStr string,
 	changed := false
 	proceed := false
 
-	for i, ctc := range config.ContentTreeConfigList {
+	for i, ctc := range contentTreeConfigList(config, status) {
 		cts := &status.ContentTreeStatusList[i]
 		// check that the contenttreeconfig and contenttreestatus have matching content ID
 		// and matching Relative URL. However, we tolerate the mismatched URL if it is because
@@ -537,11 +548,15 @@ func checkBaseOsVolumeStatus(ctx *baseOsMgrContext, baseOsUUID uuid.UUID,
 	uuidStr := baseOsUUID.String()
 	log.Functionf("checkBaseOsVolumeStatus(%s) for %s",
 		config.BaseOsVersion, uuidStr)
-	ret := checkContentTreeStatus(ctx, baseOsUUID, config.ContentTreeConfigList,
-		status.ContentTreeStatusList)
+	ret := checkContentTreeStatus(ctx, baseOsUUID,
+		contentTreeConfigList(config, status), status.ContentTreeStatusList)
 
 	status.State = ret.MinState
 
+	if ret.AllErrors != "" && status.DeltaBaseVersion != "" {
+		fallbackToFullImage(ctx, config, status, ret.AllErrors)
+		return true, false
+	}
 	if ret.AllErrors != "" {
 		status.SetError(ret.AllErrors, ret.ErrorTime)
 		log.Errorf("checkBaseOsVolumeStatus(%s) for %s, volumemgr error at %v: %v",
@@ -1034,6 +1049,7 @@ func handleOtherPartRebootReason(ctxPtr *baseOsMgrContext, status *types.BaseOsS
 			dateStr)
 		status.SetError(reason, ctxPtr.rebootTime)
 	}
+	status.FailedHealthGate = ctxPtr.failedHealthGate
 }
 
 func handleBaseOsCreate(ctxArg interface{}, key string,
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/lf-edge/eve/pkg/pillar/types"
 	"github.com/lf-edge/eve/pkg/pillar/worker"
 	"github.com/lf-edge/eve/pkg/pillar/zboot"
 )
@@ -20,14 +21,19 @@ type installWorkDescription struct {
 	contentID string
 	ref       string
 	target    string
+	// ref is the patch of the delta if set
+	delta *types.BaseOsDelta
 }
 
-// AddWorkInstall create a Work job to install the provided image to the target path
-func AddWorkInstall(ctx *baseOsMgrContext, key, ref, target string) {
+// AddWorkInstall create a Work job to install the provided image to the target path.
+// If delta is set, the image is reconstructed from the patch in ref.
+func AddWorkInstall(ctx *baseOsMgrContext, key, ref, target string,
+	delta *types.BaseOsDelta) {
 	d := installWorkDescription{
 		contentID: key,
 		ref:       ref,
 		target:    target,
+		delta:     delta,
 	}
 	// Don't fail on errors to make idempotent (Submit returns an error if
 	// the work was already submitted)
@@ -57,7 +63,12 @@ func installWorker(ctxPtr interface{}, w worker.Work) worker.WorkResult {
 	}
 
 	log.Functionf("installWorker to install %s to %s", d.ref, d.target)
-	err := zboot.WriteToPartition(log, d.ref, d.target)
+	var err error
+	if d.delta != nil {
+		err = zboot.WriteDeltaToPartition(log, d.ref, d.target, *d.delta)
+	} else {
+		err = zboot.WriteToPartition(log, d.ref, d.target)
+	}
 	log.Functionf("installWorker DONE install %s to %s: err %v",
 		d.ref, d.target, err)
 
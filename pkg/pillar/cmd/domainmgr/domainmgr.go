@@ -1803,6 +1803,16 @@ func configToStatus(ctx *domainContext, config types.DomainConfig,
 		}
 	}
 
+	// environment variables set via local profile server take precedence
+	if status.OCIConfigDir != "" && len(config.LocalEnvVariables) != 0 {
+		if status.EnvVariables == nil {
+			status.EnvVariables = make(map[string]string)
+		}
+		for k, v := range config.LocalEnvVariables {
+			status.EnvVariables[k] = v
+		}
+	}
+
 	if need9P {
 		status.DiskStatusList = append(status.DiskStatusList, types.DiskStatus{
 			FileLocation: "/mnt",
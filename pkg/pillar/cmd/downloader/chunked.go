@@ -0,0 +1,165 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Deduplicated download of images with chunk index: the image is assembled
+// from chunks found in local images and volumes, only missing chunks
+// are downloaded from the datastore.
+
+package downloader
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	v1types "github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/lf-edge/eve/pkg/pillar/chunkindex"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+const (
+	// Directory next to the target with the index and the chunk being downloaded
+	chunksDirSuffix = ".chunks"
+	// Chunk indexes are small, cap their size
+	maxChunkIndexSize = 64 * 1024 * 1024
+	// Local files smaller than that are not worth chunking
+	minChunkSourceSize = 1024 * 1024
+)
+
+// objectFetcher downloads the object from the datastore to locFilename.
+// Name is relative to the datastore as DownloaderConfig.Name.
+// Returns cancel bool and error.
+type objectFetcher func(name, locFilename string, maxSize uint64) (bool, error)
+
+// noProgress ignores progress of downloads of chunk index and chunks;
+// the progress is reported for the whole image instead.
+type noProgress struct{}
+
+// Progress always reports a change to not trigger detection of stalled downloads.
+func (noProgress) Progress(uint, int64, int64) bool {
+	return true
+}
+
+// chunkSources returns local files which may contain chunks of the image:
+// images (but not container layers) loaded into CAS and volumes.
+func chunkSources(ctx *downloaderContext, sha string) []string {
+	var candidates []string
+	for _, item := range ctx.subBlobStatus.GetAll() {
+		blob := item.(types.BlobStatus)
+		if blob.State != types.LOADED || blob.Sha256 == sha ||
+			blob.MediaType != string(v1types.OCILayer) {
+			continue
+		}
+		candidates = append(candidates, filepath.Join(types.ContainerdContentDir,
+			"blobs", "sha256", blob.Sha256))
+	}
+	for _, dir := range []string{types.VolumeEncryptedDirName, types.VolumeClearDirName} {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, fi := range files {
+			candidates = append(candidates, filepath.Join(dir, fi.Name()))
+		}
+	}
+	var sources []string
+	for _, candidate := range candidates {
+		info, err := os.Stat(candidate)
+		if err != nil || !info.Mode().IsRegular() || info.Size() < minChunkSourceSize {
+			continue
+		}
+		sources = append(sources, candidate)
+	}
+	return sources
+}
+
+// downloadChunked downloads the chunk index of the image and assembles
+// the image to locFilename. The image is checked against the sha256 from
+// the index, which has to match the expected sha256, if known.
+// Returns statistics, cancel bool and error.
+func downloadChunked(ctx *downloaderContext, config types.DownloaderConfig,
+	status *types.DownloaderStatus, locFilename string,
+	fetch objectFetcher) (chunkindex.Stats, bool, error) {
+
+	var stats chunkindex.Stats
+	workDir := locFilename + chunksDirSuffix
+	if err := os.MkdirAll(workDir, 0700); err != nil {
+		return stats, false, err
+	}
+	defer os.RemoveAll(workDir)
+
+	indexFile := filepath.Join(workDir, "index")
+	cancelled, err := fetch(config.ChunkIndexURL, indexFile, maxChunkIndexSize)
+	if err != nil {
+		return stats, cancelled, fmt.Errorf("failed to download chunk index %s: %v",
+			config.ChunkIndexURL, err)
+	}
+	f, err := os.Open(indexFile)
+	if err != nil {
+		return stats, false, err
+	}
+	idx, err := chunkindex.ParseIndex(f)
+	f.Close()
+	if err != nil {
+		return stats, false, err
+	}
+	if config.ImageSha256 != "" && idx.Sha256 != strings.ToLower(config.ImageSha256) {
+		return stats, false, fmt.Errorf("chunk index is for sha256 %s, expected %s",
+			idx.Sha256, config.ImageSha256)
+	}
+	if config.Size != 0 && idx.Size > config.Size {
+		return stats, false, fmt.Errorf("image size %d in chunk index exceeds %d",
+			idx.Size, config.Size)
+	}
+
+	local := chunkindex.NewLocalChunks(idx)
+	for _, source := range chunkSources(ctx, idx.Sha256) {
+		if local.Complete() {
+			break
+		}
+		if err := local.AddFile(source); err != nil {
+			log.Warnf("downloadChunked(%s): %v", config.Name, err)
+		}
+	}
+	log.Noticef("downloadChunked(%s): %d out of %d chunks found locally",
+		config.Name, local.Found(), len(idx.Wanted()))
+
+	out, err := os.Create(locFilename)
+	if err != nil {
+		return stats, false, err
+	}
+	defer out.Close()
+
+	chunkFile := filepath.Join(workDir, "chunk")
+	indexDir := path.Dir(config.ChunkIndexURL)
+	fetchChunk := func(chunk chunkindex.Chunk) ([]byte, error) {
+		defer os.Remove(chunkFile + progressFileSuffix)
+		defer os.Remove(chunkFile)
+		cancelled, err = fetch(path.Join(indexDir, idx.ChunkName(chunk.Sha256)),
+			chunkFile, uint64(chunk.Size))
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.ReadFile(chunkFile)
+	}
+	st := &PublishStatus{
+		ctx:    ctx,
+		status: status,
+	}
+	var lastProgress uint
+	progress := func(written uint64) {
+		p := uint(written * 100 / idx.Size)
+		if p != lastProgress {
+			lastProgress = p
+			st.Progress(p, int64(written), int64(idx.Size))
+		}
+	}
+	stats, err = chunkindex.Assemble(local, fetchChunk, out, progress)
+	if err != nil {
+		return stats, cancelled, err
+	}
+	return stats, false, nil
+}
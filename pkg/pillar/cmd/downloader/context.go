@@ -22,12 +22,19 @@ type downloaderContext struct {
 	pubCipherBlockStatus     pubsub.Publication
 	subDatastoreConfig       pubsub.Subscription
 	subNetworkInstanceStatus pubsub.Subscription
+	subBlobStatus            pubsub.Subscription
+	subPeerCacheConfig       pubsub.Subscription
 	deviceNetworkStatus      types.DeviceNetworkStatus
 	subGlobalConfig          pubsub.Subscription
 	zedcloudMetrics          *zedcloud.AgentMetrics
 	cipherMetrics            *cipher.AgentMetrics
 	GCInitialized            bool
 	downloadMaxPortCost      uint8
+	peerCacheEnabled         bool
+	peerCache                *peerCache
+	peerTrust                peerTrust
+	peerBrowse               peerBrowse
+	schedule                 downloadSchedule
 }
 
 func (ctx *downloaderContext) registerHandlers(ps *pubsub.PubSub) error {
@@ -134,6 +141,44 @@ func (ctx *downloaderContext) registerHandlers(ps *pubsub.PubSub) error {
 	ctx.subNetworkInstanceStatus = subNetworkInstanceStatus
 	subNetworkInstanceStatus.Activate()
 
+	// Subscribe to BlobStatus from volumemgr to learn which blobs
+	// are verified and loaded into CAS, and can be shared with peers.
+	subBlobStatus, err := ps.NewSubscription(pubsub.SubscriptionOptions{
+		AgentName:     "volumemgr",
+		MyAgentName:   agentName,
+		CreateHandler: handleBlobStatusCreate,
+		ModifyHandler: handleBlobStatusModify,
+		DeleteHandler: handleBlobStatusDelete,
+		WarningTime:   warningTime,
+		ErrorTime:     errorTime,
+		TopicImpl:     types.BlobStatus{},
+		Ctx:           ctx,
+	})
+	if err != nil {
+		return err
+	}
+	ctx.subBlobStatus = subBlobStatus
+	subBlobStatus.Activate()
+
+	// Subscribe to PeerCacheConfig from zedagent to learn the device
+	// certificates of the peers we may share blobs with.
+	subPeerCacheConfig, err := ps.NewSubscription(pubsub.SubscriptionOptions{
+		AgentName:     "zedagent",
+		MyAgentName:   agentName,
+		CreateHandler: handlePeerCacheConfigCreate,
+		ModifyHandler: handlePeerCacheConfigModify,
+		DeleteHandler: handlePeerCacheConfigDelete,
+		WarningTime:   warningTime,
+		ErrorTime:     errorTime,
+		TopicImpl:     types.PeerCacheConfig{},
+		Ctx:           ctx,
+	})
+	if err != nil {
+		return err
+	}
+	ctx.subPeerCacheConfig = subPeerCacheConfig
+	subPeerCacheConfig.Activate()
+
 	// Look for DatastoreConfig. We should process this
 	// before any download config. Without DataStore Config,
 	// Image Downloads will run into errors, which requires retries
@@ -56,10 +56,16 @@ func clearInProgressDownloadDirs(ctx *downloaderContext) {
 	}
 	// loop through files and check if they are in place
 	for _, fi := range files {
+		filePath := filepath.Join(dirName, fi.Name())
 		if fi.IsDir() {
+			// leftover of interrupted chunked download
+			if strings.HasSuffix(filePath, chunksDirSuffix) {
+				if err := os.RemoveAll(filePath); err != nil {
+					log.Error(err)
+				}
+			}
 			continue
 		}
-		filePath := filepath.Join(dirName, fi.Name())
 		// if progress file
 		if strings.HasSuffix(filePath, progressFileSuffix) {
 			//check if progress file points onto existing file
@@ -33,6 +33,9 @@ func handleDNSImpl(ctxArg interface{}, key string,
 		return
 	}
 	ctx.deviceNetworkStatus = status
+	if ctx.peerCacheEnabled {
+		updatePeerCache(ctx)
+	}
 	log.Functionf("handleDNSImpl done for %s", key)
 }
 
@@ -45,5 +48,8 @@ func handleDNSDelete(ctxArg interface{}, key string, statusArg interface{}) {
 		return
 	}
 	ctx.deviceNetworkStatus = types.DeviceNetworkStatus{}
+	if ctx.peerCacheEnabled {
+		updatePeerCache(ctx)
+	}
 	log.Functionf("handleDNSDelete done for %s", key)
 }
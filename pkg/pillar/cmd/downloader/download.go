@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/url"
 	"os"
 	"time"
 
@@ -59,9 +60,9 @@ func saveDownloadedParts(locFilename string, downloadedParts types.DownloadedPar
 // interfaces or IP addresses.
 func download(ctx *downloaderContext, trType zedUpload.SyncTransportType,
 	status Status, syncOp zedUpload.SyncOpType, downloadURL string,
-	auth *zedUpload.AuthInput, dpath, region string, maxsize uint64, ifname string,
-	ipSrc net.IP, filename, locFilename string, certs [][]byte,
-	receiveChan chan<- CancelChannel) (string, bool, error) {
+	auth *zedUpload.AuthInput, dpath, region string, maxsize uint64,
+	priority int32, ifname string, ipSrc net.IP, filename, locFilename string,
+	certs [][]byte, receiveChan chan<- CancelChannel) (string, bool, error) {
 
 	// create Endpoint
 	var dEndPoint zedUpload.DronaEndPoint
@@ -78,6 +79,8 @@ func download(ctx *downloaderContext, trType zedUpload.SyncTransportType,
 		dEndPoint, err = ctx.dCtx.NewSyncerDest(trType, downloadURL, filename, auth)
 	case zedUpload.SyncGSTr:
 		dEndPoint, err = ctx.dCtx.NewSyncerDest(trType, "", dpath, auth)
+	case zedUpload.SyncPeerTr:
+		dEndPoint, err = ctx.dCtx.NewSyncerDest(trType, downloadURL, dpath, auth)
 
 	default:
 		err = fmt.Errorf("unknown transfer type: %s", trType)
@@ -87,8 +90,12 @@ func download(ctx *downloaderContext, trType zedUpload.SyncTransportType,
 		return "", cancel, err
 	}
 	// check for proxies on the selected management port interface
-	proxyLookupURL := zedcloud.IntfLookupProxyCfg(log, &ctx.deviceNetworkStatus, ifname, downloadURL, trType)
-	proxyURL, err := zedcloud.LookupProxy(log, &ctx.deviceNetworkStatus, ifname, proxyLookupURL)
+	// (peers are on the local network and never reached through a proxy)
+	var proxyURL *url.URL
+	if trType != zedUpload.SyncPeerTr {
+		proxyLookupURL := zedcloud.IntfLookupProxyCfg(log, &ctx.deviceNetworkStatus, ifname, downloadURL, trType)
+		proxyURL, err = zedcloud.LookupProxy(log, &ctx.deviceNetworkStatus, ifname, proxyLookupURL)
+	}
 	if err == nil {
 		if proxyURL != nil {
 			log.Functionf("%s: Using proxy %s", trType, proxyURL.String())
@@ -114,6 +121,18 @@ func download(ctx *downloaderContext, trType zedUpload.SyncTransportType,
 		log.Errorf("Lookup Proxy failed: %s", err)
 		return "", cancel, err
 	}
+	// share the bandwidth of the port with the other downloads using it
+	// (peers are on the local network and not limited)
+	if trType != zedUpload.SyncPeerTr {
+		limiter := ctx.schedule.portLimiter(ifname)
+		if limiter != nil {
+			err = dEndPoint.WithBandwidthLimit(limiter, int(priority))
+			if err != nil {
+				log.Errorf("Set bandwidth limit failed: %s", err)
+				return "", cancel, err
+			}
+		}
+	}
 
 	var respChan = make(chan *zedUpload.DronaRequest)
 
@@ -224,7 +243,8 @@ func download(ctx *downloaderContext, trType zedUpload.SyncTransportType,
 func objectMetadata(ctx *downloaderContext, trType zedUpload.SyncTransportType,
 	syncOp zedUpload.SyncOpType, downloadURL string,
 	auth *zedUpload.AuthInput, dpath, region string, ifname string,
-	ipSrc net.IP, filename string, receiveChan chan<- CancelChannel) (string, bool, error) {
+	ipSrc net.IP, filename string, certs [][]byte,
+	receiveChan chan<- CancelChannel) (string, bool, error) {
 
 	// create Endpoint
 	var dEndPoint zedUpload.DronaEndPoint
@@ -247,9 +267,21 @@ func objectMetadata(ctx *downloaderContext, trType zedUpload.SyncTransportType,
 	proxyURL, err := zedcloud.LookupProxy(log, &ctx.deviceNetworkStatus, ifname, proxyLookupURL)
 	if err == nil && proxyURL != nil {
 		log.Functionf("%s: Using proxy %s", trType, proxyURL.String())
-		dEndPoint.WithSrcIPAndProxySelection(ipSrc, proxyURL)
+		if len(certs) > 0 {
+			err = dEndPoint.WithSrcIPAndProxyAndHTTPSCerts(ipSrc, proxyURL, certs)
+		} else {
+			err = dEndPoint.WithSrcIPAndProxySelection(ipSrc, proxyURL)
+		}
 	} else {
-		dEndPoint.WithSrcIPSelection(ipSrc)
+		if len(certs) > 0 {
+			err = dEndPoint.WithSrcIPAndHTTPSCerts(ipSrc, certs)
+		} else {
+			err = dEndPoint.WithSrcIPSelection(ipSrc)
+		}
+	}
+	if err != nil {
+		log.Errorf("Set source IP failed: %s", err)
+		return sha256, cancel, err
 	}
 
 	var respChan = make(chan *zedUpload.DronaRequest)
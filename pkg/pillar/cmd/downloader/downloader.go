@@ -157,6 +157,9 @@ func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) in
 	gcTimer := flextimer.NewRangeTicker(time.Duration(0.3*float64(gcInterval)),
 		gcInterval)
 
+	// check the download windows every minute
+	scheduleTimer := time.NewTicker(time.Minute)
+
 	for {
 		select {
 		case change := <-ctx.decryptCipherContext.SubControllerCert.MsgChan():
@@ -180,6 +183,12 @@ func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) in
 		case change := <-ctx.subNetworkInstanceStatus.MsgChan():
 			ctx.subNetworkInstanceStatus.ProcessChange(change)
 
+		case change := <-ctx.subBlobStatus.MsgChan():
+			ctx.subBlobStatus.ProcessChange(change)
+
+		case change := <-ctx.subPeerCacheConfig.MsgChan():
+			ctx.subPeerCacheConfig.ProcessChange(change)
+
 		case change := <-ctx.subDownloaderConfig.MsgChan():
 			ctx.subDownloaderConfig.ProcessChange(change)
 
@@ -209,6 +218,12 @@ func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) in
 			ps.CheckMaxTimeTopic(agentName, "gcTimer", start,
 				warningTime, errorTime)
 
+		case <-scheduleTimer.C:
+			start := time.Now()
+			checkDownloadSchedule(&ctx)
+			ps.CheckMaxTimeTopic(agentName, "scheduleTimer", start,
+				warningTime, errorTime)
+
 		case <-stillRunning.C:
 		}
 		ps.StillRunning(agentName, warningTime, errorTime)
@@ -459,6 +474,19 @@ func doDownload(ctx *downloaderContext, config types.DownloaderConfig, status *t
 		return
 	}
 
+	// Large downloads wait for the download window
+	if hold, next := ctx.schedule.holdDownload(config, time.Now()); hold {
+		log.Noticef("doDownload(%s): scheduled for the download window at %v",
+			config.Name, next)
+		status.Scheduled = true
+		status.ScheduledTime = next
+		publishDownloaderStatus(ctx, status)
+		return
+	}
+	status.Scheduled = false
+	status.ScheduledTime = time.Time{}
+	status.Paused = false
+
 	dst, err := utils.LookupDatastoreConfig(ctx.subDatastoreConfig, config.DatastoreID)
 	if dst == nil {
 		errStr := fmt.Sprintf("Will retry when datastore available: %s",
@@ -40,6 +40,14 @@ func handleGlobalConfigImpl(ctxArg interface{}, key string,
 			maxStalledTime = time.Duration(gcp.GlobalValueInt(types.DownloadStalledTime)) * time.Second
 		}
 		ctx.downloadMaxPortCost = uint8(gcp.GlobalValueInt(types.DownloadMaxPortCost))
+		peerCacheEnabled := gcp.GlobalValueBool(types.DownloadPeerCache)
+		if peerCacheEnabled != ctx.peerCacheEnabled {
+			log.Noticef("handleGlobalConfigImpl: peer cache enabled: %t",
+				peerCacheEnabled)
+			ctx.peerCacheEnabled = peerCacheEnabled
+			updatePeerCache(ctx)
+		}
+		updateDownloadSchedule(ctx, gcp)
 		ctx.GCInitialized = true
 	}
 	log.Functionf("handleGlobalConfigImpl done for %s", key)
@@ -77,6 +77,29 @@ func (d *downloadHandler) modify(ctxArg interface{},
 	}
 }
 
+// pause cancels the download in progress, if any, so it can be scheduled
+// again. Returns false if there is no download to cancel.
+func (d *downloadHandler) pause(key string) bool {
+
+	log.Functionf("downloadHandler.pause(%s)", key)
+	h, ok := d.handlers[key]
+	if !ok || h.currentCancelChan == nil {
+		return false
+	}
+	select {
+	case h.currentCancelChan <- Notify{}:
+		log.Noticef("downloadHandler.pause(%s) sent cancel to %v",
+			key, h.currentCancelChan)
+	default:
+		// handler is slow
+		log.Warnf("downloadHandler.pause(%s) NOT sent cancel", key)
+	}
+	// We only cancel one operation once
+	close(h.currentCancelChan)
+	h.currentCancelChan = nil
+	return true
+}
+
 func (d *downloadHandler) create(ctxArg interface{},
 	key string, configArg interface{}) {
 
@@ -0,0 +1,185 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Verification of cosign signatures of container images, see imagesig
+
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/lf-edge/eve/libs/zedUpload"
+	"github.com/lf-edge/eve/pkg/pillar/imagesig"
+)
+
+// maxSignatures limits the number of signatures of an image we try
+const maxSignatures = 16
+
+// maxSignatureManifestSize limits the size of signature manifests and of
+// referrers indexes
+const maxSignatureManifestSize = 256 * 1024
+
+// signatureError : the image is not signed by any of the keys, as opposed
+// to a failure to get the signatures from the registry
+type signatureError struct {
+	err error
+}
+
+func (e *signatureError) Error() string {
+	return fmt.Sprintf("image signature verification failed: %v", e.err)
+}
+
+// isNotFound returns true if the registry does not have the manifest
+func isNotFound(err error) bool {
+	errStr := err.Error()
+	return strings.Contains(errStr, "MANIFEST_UNKNOWN") ||
+		strings.Contains(errStr, "NAME_UNKNOWN") ||
+		strings.Contains(errStr, "status code 404")
+}
+
+// ociRepository returns the repository of remoteName without tag or digest
+func ociRepository(remoteName string) string {
+	if i := strings.Index(remoteName, "@"); i >= 0 {
+		remoteName = remoteName[:i]
+	}
+	if i := strings.LastIndex(remoteName, ":"); i > strings.LastIndex(remoteName, "/") {
+		return remoteName[:i]
+	}
+	return remoteName
+}
+
+// signatureFetcher fetches signature manifests and payloads from the
+// repository of the image using one interface
+type signatureFetcher struct {
+	ctx         *downloaderContext
+	trType      zedUpload.SyncTransportType
+	serverURL   string
+	auth        *zedUpload.AuthInput
+	dpath       string
+	region      string
+	ifname      string
+	ipSrc       net.IP
+	repo        string
+	certs       [][]byte
+	receiveChan chan<- CancelChannel
+}
+
+// fetch returns the manifest or blob ref (:tag or @digest) of the repository
+func (f signatureFetcher) fetch(ref string, maxsize uint64) ([]byte, bool, error) {
+	tmpFile, err := ioutil.TempFile(getPendingDir(), "signature")
+	if err != nil {
+		return nil, false, err
+	}
+	locFilename := tmpFile.Name()
+	tmpFile.Close()
+	defer os.Remove(locFilename)
+	defer os.Remove(locFilename + progressFileSuffix)
+
+	_, cancelled, err := download(f.ctx, f.trType, noProgress{},
+		zedUpload.SyncOpDownload, f.serverURL, f.auth, f.dpath, f.region,
+		maxsize, metadataPriority, f.ifname, f.ipSrc, f.repo+ref, locFilename, f.certs,
+		f.receiveChan)
+	if err != nil {
+		return nil, cancelled, err
+	}
+	b, err := ioutil.ReadFile(locFilename)
+	return b, false, err
+}
+
+// signatures returns the cosign signatures of the image with digest, either
+// from the signature tag or from the referrers index
+func (f signatureFetcher) signatures(digest string) ([]imagesig.Signature, bool, error) {
+	b, cancelled, err := f.fetch(":"+imagesig.SignatureTag(digest),
+		maxSignatureManifestSize)
+	if err == nil {
+		sigs, err := imagesig.ParseSignatureManifest(b)
+		if err != nil {
+			return nil, false, &signatureError{err: err}
+		}
+		return sigs, false, nil
+	}
+	if cancelled || !isNotFound(err) {
+		return nil, cancelled, err
+	}
+	log.Functionf("no signature tag for %s%s: %v, trying referrers",
+		f.repo, digest, err)
+	b, cancelled, err = f.fetch(":"+imagesig.ReferrersTag(digest),
+		maxSignatureManifestSize)
+	if err != nil {
+		if !cancelled && isNotFound(err) {
+			return nil, false, &signatureError{err: imagesig.ErrNoSignature}
+		}
+		return nil, cancelled, err
+	}
+	manifests, err := imagesig.ParseReferrers(b)
+	if err != nil {
+		return nil, false, &signatureError{err: err}
+	}
+	var sigs []imagesig.Signature
+	for _, manifestDigest := range manifests {
+		b, cancelled, err := f.fetch("@"+manifestDigest, maxSignatureManifestSize)
+		if err != nil {
+			return nil, cancelled, err
+		}
+		manifestSigs, err := imagesig.ParseSignatureManifest(b)
+		if err != nil {
+			log.Warnf("signature manifest %s: %v", manifestDigest, err)
+			continue
+		}
+		sigs = append(sigs, manifestSigs...)
+	}
+	if len(sigs) == 0 {
+		return nil, false, &signatureError{err: imagesig.ErrNoSignature}
+	}
+	return sigs, false, nil
+}
+
+// verifyImageSignature verifies that the image with sha256 in the
+// repository of remoteName is signed by one of keys.
+// Returns a cancel bool to tell the caller to not retry using other
+// interfaces, and a *signatureError if the image is not signed by any of
+// the keys.
+func verifyImageSignature(f signatureFetcher, remoteName string, sha256 string,
+	keys []string) (bool, error) {
+
+	pubKeys, err := imagesig.ParseKeys(keys)
+	if err != nil {
+		return false, &signatureError{err: fmt.Errorf("keys: %v", err)}
+	}
+	f.repo = ociRepository(remoteName)
+	digest := "sha256:" + strings.ToLower(sha256)
+	sigs, cancelled, err := f.signatures(digest)
+	if err != nil {
+		return cancelled, err
+	}
+	if len(sigs) > maxSignatures {
+		sigs = sigs[:maxSignatures]
+	}
+	for _, sig := range sigs {
+		if sig.PayloadSize > imagesig.MaxPayloadSize {
+			err = fmt.Errorf("payload %s too large", sig.PayloadDigest)
+			continue
+		}
+		payload, cancelled, fetchErr := f.fetch("@"+sig.PayloadDigest,
+			imagesig.MaxPayloadSize)
+		if fetchErr != nil {
+			return cancelled, fetchErr
+		}
+		err = imagesig.Verify(sig, payload, digest, pubKeys)
+		if err == nil {
+			log.Noticef("verified signature of %s@%s", f.repo, digest)
+			return false, nil
+		}
+		log.Warnf("signature %s of %s@%s: %v", sig.PayloadDigest, f.repo,
+			digest, err)
+	}
+	if err == nil {
+		err = errors.New("no signature verified")
+	}
+	return false, &signatureError{err: err}
+}
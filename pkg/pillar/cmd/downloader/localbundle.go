@@ -0,0 +1,43 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	fileutils "github.com/lf-edge/eve/pkg/pillar/utils/file"
+)
+
+// downloadFromLocalBundle moves the image with the given sha256 from the
+// update bundle (imported by zedagent from a USB stick or from the local
+// profile server) to locFilename, and returns its media type.
+// The image was checked against the bundle manifest, and is checked by
+// the verifier as any other download.
+func downloadFromLocalBundle(sha string, locFilename string) (string, error) {
+	sha = strings.ToLower(sha)
+	blobFile := filepath.Join(types.LocalBundleBlobsDir, sha)
+	if _, err := os.Stat(blobFile); err != nil {
+		return "", err
+	}
+	var mediaType string
+	mediaTypeFile := blobFile + types.LocalBundleMediaTypeSuffix
+	if b, err := ioutil.ReadFile(mediaTypeFile); err == nil {
+		mediaType = strings.TrimSpace(string(b))
+	}
+	if err := os.Rename(blobFile, locFilename); err != nil {
+		// the bundle and the download may be on different filesystems
+		if err := fileutils.CopyFile(blobFile, locFilename); err != nil {
+			os.Remove(locFilename)
+			return "", fmt.Errorf("failed to copy %s: %v", blobFile, err)
+		}
+		os.Remove(blobFile)
+	}
+	os.Remove(mediaTypeFile)
+	return mediaType, nil
+}
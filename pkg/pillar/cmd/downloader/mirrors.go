@@ -0,0 +1,255 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Downloads from mirrors of the registry of OCI datastores
+
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/lf-edge/eve/libs/zedUpload"
+	"github.com/lf-edge/eve/pkg/pillar/cipher"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	fileutils "github.com/lf-edge/eve/pkg/pillar/utils/file"
+)
+
+// getMirrorCredential returns the decrypted credentials of the mirror,
+// which has no cleartext fallback
+func getMirrorCredential(ctx *downloaderContext,
+	mirror types.RegistryMirror) (types.EncryptionBlock, error) {
+	if !mirror.CipherBlockStatus.IsCipher {
+		return types.EncryptionBlock{}, nil
+	}
+	status, decBlock, err := cipher.GetCipherCredentials(&ctx.decryptCipherContext,
+		mirror.CipherBlockStatus)
+	ctx.pubCipherBlockStatus.Publish(status.Key(), status)
+	if err != nil {
+		ctx.cipherMetrics.RecordFailure(log, types.MissingFallback)
+		return decBlock, fmt.Errorf("mirror %s credentials: %v", mirror.Fqdn, err)
+	}
+	return decBlock, nil
+}
+
+// mirrorHost returns host[:port] of the mirror, which may be configured
+// as URL
+func mirrorHost(mirror types.RegistryMirror) string {
+	fqdn := strings.TrimSpace(mirror.Fqdn)
+	if strings.Contains(fqdn, "://") {
+		if u, err := url.Parse(fqdn); err == nil {
+			return u.Host
+		}
+	}
+	return strings.TrimSuffix(fqdn, "/")
+}
+
+// mirrorName returns remoteName prefixed by the dpath of the mirror
+func mirrorName(mirror types.RegistryMirror, remoteName string) string {
+	dpath := strings.Trim(mirror.Dpath, "/")
+	if dpath == "" {
+		return remoteName
+	}
+	return path.Join(dpath, remoteName)
+}
+
+// mirrorRepositoryName returns the name of the blob in the mirror: the
+// repository prefixed by the dpath of the mirror and pinned to the sha256,
+// so the mirror cannot serve other content for a tag
+func mirrorRepositoryName(mirror types.RegistryMirror, remoteName, sha string) string {
+	return fmt.Sprintf("%s@sha256:%s", mirrorName(mirror, ociRepository(remoteName)),
+		strings.ToLower(sha))
+}
+
+// resolveFromMirrors resolves the tag of remoteName using the mirrors of
+// the OCI datastore in order, and verifies the signature of the image if
+// keys are given. If sha is set, remoteName is pinned to it and only the
+// signature is verified. The signature is checked against the keys
+// wherever it is fetched from, but without keys the device trusts the
+// mirror to resolve the tag as the registry does.
+// Returns the sha256, cancel bool and error.
+func resolveFromMirrors(ctx *downloaderContext, dst *types.DatastoreConfig,
+	remoteName, sha string, keys []string, downloadMaxPortCost uint8,
+	receiveChan chan<- CancelChannel) (string, bool, error) {
+
+	addrCount := types.CountLocalAddrNoLinkLocalWithCost(ctx.deviceNetworkStatus,
+		downloadMaxPortCost)
+	if addrCount == 0 {
+		return "", false, fmt.Errorf("No IP management port addresses with cost <= %d",
+			downloadMaxPortCost)
+	}
+	var errs []string
+	for _, mirror := range dst.Mirrors {
+		decBlock, err := getMirrorCredential(ctx, mirror)
+		if err != nil {
+			log.Error(err)
+			errs = append(errs, err.Error())
+			continue
+		}
+		auth := &zedUpload.AuthInput{
+			AuthType: "apikey",
+			Uname:    decBlock.DsAPIKey,
+			Password: decBlock.DsPassword,
+		}
+		host := mirrorHost(mirror)
+		name := mirrorName(mirror, remoteName)
+		for addrIndex := 0; addrIndex < addrCount; addrIndex++ {
+			var ipSrc net.IP
+			ipSrc, err = types.GetLocalAddrNoLinkLocalWithCost(ctx.deviceNetworkStatus,
+				addrIndex, "", downloadMaxPortCost)
+			if err != nil {
+				log.Errorf("GetLocalAddr failed: %s", err)
+				continue
+			}
+			ifname := types.GetMgmtPortFromAddr(ctx.deviceNetworkStatus, ipSrc)
+			sha256 := sha
+			var cancelled bool
+			if sha256 == "" {
+				sha256, cancelled, err = objectMetadata(ctx,
+					zedUpload.SyncOCIRegistryTr, zedUpload.SyncOpGetObjectMetaData,
+					host, auth, "", "", ifname, ipSrc, name, mirror.CertPEM,
+					receiveChan)
+			}
+			if err == nil && len(keys) != 0 {
+				cancelled, err = verifyImageSignature(signatureFetcher{
+					ctx:         ctx,
+					trType:      zedUpload.SyncOCIRegistryTr,
+					serverURL:   host,
+					auth:        auth,
+					ifname:      ifname,
+					ipSrc:       ipSrc,
+					certs:       mirror.CertPEM,
+					receiveChan: receiveChan,
+				}, name, sha256, keys)
+			}
+			if err != nil {
+				if cancelled {
+					return "", true, err
+				}
+				log.Warnf("Resolution of %s from mirror %s failed: %v",
+					name, host, err)
+				var sigErr *signatureError
+				if errors.As(err, &sigErr) {
+					// the same signatures on all interfaces
+					break
+				}
+				continue
+			}
+			log.Noticef("Resolved %s to %s using mirror %s", remoteName, sha256, host)
+			return sha256, false, nil
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("mirror %s: %v", host, err))
+		}
+	}
+	return "", false, errors.New(strings.Join(errs, "\n"))
+}
+
+// downloadFromMirrors tries to download the blob from the mirrors of the
+// OCI datastore in order before going to the registry. The download is
+// pinned to the expected sha256 and checked against it, so a mirror
+// serving other content is skipped.
+// Returns the content type, cancel bool and error.
+func downloadFromMirrors(ctx *downloaderContext, config types.DownloaderConfig,
+	status *types.DownloaderStatus, dst *types.DatastoreConfig, remoteName,
+	locFilename string, downloadMaxPortCost uint8,
+	receiveChan chan<- CancelChannel) (string, bool, error) {
+
+	sha := strings.ToLower(config.ImageSha256)
+	addrCount := types.CountLocalAddrNoLinkLocalWithCost(ctx.deviceNetworkStatus,
+		downloadMaxPortCost)
+	if addrCount == 0 {
+		return "", false, fmt.Errorf("No IP management port addresses with cost <= %d",
+			downloadMaxPortCost)
+	}
+	var errs []string
+	for _, mirror := range dst.Mirrors {
+		decBlock, err := getMirrorCredential(ctx, mirror)
+		if err != nil {
+			log.Error(err)
+			errs = append(errs, err.Error())
+			continue
+		}
+		auth := &zedUpload.AuthInput{
+			AuthType: "apikey",
+			Uname:    decBlock.DsAPIKey,
+			Password: decBlock.DsPassword,
+		}
+		host := mirrorHost(mirror)
+		name := mirrorRepositoryName(mirror, remoteName, sha)
+		metricsURL := fmt.Sprintf("mirror:%s/%s", host, name)
+		for addrIndex := 0; addrIndex < addrCount; addrIndex++ {
+			var ipSrc net.IP
+			ipSrc, err = types.GetLocalAddrNoLinkLocalWithCost(ctx.deviceNetworkStatus,
+				addrIndex, "", downloadMaxPortCost)
+			if err != nil {
+				log.Errorf("GetLocalAddr failed: %s", err)
+				continue
+			}
+			ifname := types.GetMgmtPortFromAddr(ctx.deviceNetworkStatus, ipSrc)
+			st := &PublishStatus{
+				ctx:    ctx,
+				status: status,
+			}
+			log.Noticef("Downloading %s from mirror %s", name, host)
+			downloadStartTime := time.Now()
+			var contentType string
+			var cancelled bool
+			contentType, cancelled, err = download(ctx, zedUpload.SyncOCIRegistryTr,
+				st, zedUpload.SyncOpDownload, host, auth, "", "",
+				config.Size, config.Priority, ifname, ipSrc, name, locFilename,
+				mirror.CertPEM, receiveChan)
+			if err == nil {
+				err = checkMirrorDownload(locFilename, sha)
+			}
+			if err != nil {
+				if cancelled {
+					return "", true, err
+				}
+				log.Warnf("Download of %s from mirror %s failed: %v",
+					name, host, err)
+				ctx.zedcloudMetrics.RecordFailure(log, ifname, metricsURL,
+					1024, 0, false)
+				// Start from scratch with the next interface, mirror or the registry.
+				if err := os.RemoveAll(locFilename); err != nil {
+					log.Error(err)
+				}
+				if err := os.RemoveAll(locFilename + progressFileSuffix); err != nil {
+					log.Error(err)
+				}
+				continue
+			}
+			size := int64(0)
+			if info, err := os.Stat(locFilename); err == nil {
+				size = info.Size()
+			}
+			downloadTime := int64(time.Since(downloadStartTime) / time.Millisecond)
+			ctx.zedcloudMetrics.RecordSuccess(log, ifname, metricsURL,
+				1024, size, downloadTime, false)
+			status.Mirror = host
+			return contentType, false, nil
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("mirror %s: %v", host, err))
+		}
+	}
+	return "", false, errors.New(strings.Join(errs, "\n"))
+}
+
+// checkMirrorDownload makes sure the mirror served the expected content
+func checkMirrorDownload(locFilename, sha string) error {
+	hash, err := fileutils.ComputeShaFile(locFilename)
+	if err != nil {
+		return err
+	}
+	if got := fmt.Sprintf("%x", hash); got != sha {
+		return fmt.Errorf("mirror served sha256 %s instead of %s", got, sha)
+	}
+	return nil
+}
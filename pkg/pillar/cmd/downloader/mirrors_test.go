@@ -0,0 +1,108 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"testing"
+
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+func TestMirrorHost(t *testing.T) {
+	testMatrix := map[string]struct {
+		fqdn     string
+		expected string
+	}{
+		"fqdn":           {fqdn: "mirror.example.com", expected: "mirror.example.com"},
+		"fqdn with port": {fqdn: "mirror.example.com:5000", expected: "mirror.example.com:5000"},
+		"trailing slash": {fqdn: " mirror.example.com/ ", expected: "mirror.example.com"},
+		"https URL":      {fqdn: "https://mirror.example.com:5000/", expected: "mirror.example.com:5000"},
+		"URL with path":  {fqdn: "http://mirror.example.com/v2", expected: "mirror.example.com"},
+	}
+	for name, test := range testMatrix {
+		t.Run(name, func(t *testing.T) {
+			got := mirrorHost(types.RegistryMirror{Fqdn: test.fqdn})
+			if got != test.expected {
+				t.Errorf("mirrorHost(%q) = %q, expected %q", test.fqdn, got, test.expected)
+			}
+		})
+	}
+}
+
+func TestMirrorRepositoryName(t *testing.T) {
+	const sha = "ABCDEF0123456789"
+	testMatrix := map[string]struct {
+		dpath      string
+		remoteName string
+		expected   string
+	}{
+		"no dpath": {
+			remoteName: "library/alpine",
+			expected:   "library/alpine@sha256:abcdef0123456789",
+		},
+		"dpath": {
+			dpath:      "cache",
+			remoteName: "library/alpine",
+			expected:   "cache/library/alpine@sha256:abcdef0123456789",
+		},
+		"dpath with slashes": {
+			dpath:      "/cache/docker.io/",
+			remoteName: "library/alpine",
+			expected:   "cache/docker.io/library/alpine@sha256:abcdef0123456789",
+		},
+		"tag": {
+			dpath:      "cache",
+			remoteName: "library/alpine:3.15",
+			expected:   "cache/library/alpine@sha256:abcdef0123456789",
+		},
+		"digest": {
+			remoteName: "library/alpine@sha256:0123",
+			expected:   "library/alpine@sha256:abcdef0123456789",
+		},
+		"tag and digest": {
+			remoteName: "library/alpine:3.15@sha256:0123",
+			expected:   "library/alpine@sha256:abcdef0123456789",
+		},
+	}
+	for name, test := range testMatrix {
+		t.Run(name, func(t *testing.T) {
+			mirror := types.RegistryMirror{Fqdn: "mirror.example.com", Dpath: test.dpath}
+			got := mirrorRepositoryName(mirror, test.remoteName, sha)
+			if got != test.expected {
+				t.Errorf("mirrorRepositoryName(%q, %q) = %q, expected %q",
+					test.dpath, test.remoteName, got, test.expected)
+			}
+		})
+	}
+}
+
+func TestMirrorName(t *testing.T) {
+	mirror := types.RegistryMirror{Fqdn: "mirror.example.com", Dpath: "/cache/"}
+	// the tag is kept to resolve it using the mirror
+	got := mirrorName(mirror, "library/alpine:3.15")
+	if expected := "cache/library/alpine:3.15"; got != expected {
+		t.Errorf("mirrorName = %q, expected %q", got, expected)
+	}
+	got = mirrorName(types.RegistryMirror{}, "library/alpine:3.15")
+	if expected := "library/alpine:3.15"; got != expected {
+		t.Errorf("mirrorName without dpath = %q, expected %q", got, expected)
+	}
+}
+
+func TestOciRepository(t *testing.T) {
+	testMatrix := map[string]string{
+		"library/alpine":                        "library/alpine",
+		"library/alpine:3.15":                   "library/alpine",
+		"library/alpine@sha256:0123":            "library/alpine",
+		"library/alpine:3.15@sha256:0123":       "library/alpine",
+		"registry:5000/library/alpine":          "registry:5000/library/alpine",
+		"registry:5000/library/alpine:3.15":     "registry:5000/library/alpine",
+		"registry:5000/library/alpine@sha256:0": "registry:5000/library/alpine",
+	}
+	for remoteName, expected := range testMatrix {
+		if got := ociRepository(remoteName); got != expected {
+			t.Errorf("ociRepository(%q) = %q, expected %q", remoteName, got, expected)
+		}
+	}
+}
@@ -0,0 +1,484 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+// github.com/grandcat/zeroconf: under MIT License
+
+// Peer cache: serve verified (loaded into CAS) blobs to other EVE devices
+// on the same LAN, whose device certificates are vouched for by the
+// controller, and advertise the service over mDNS.
+
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grandcat/zeroconf"
+	"github.com/lf-edge/eve/libs/zedUpload"
+	"github.com/lf-edge/eve/pkg/pillar/cas"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	"github.com/lf-edge/eve/pkg/pillar/zedcloud"
+)
+
+const (
+	casClientType = "containerd"
+	// TXT record keys.
+	peerTxtCert  = "cert="
+	peerTxtBlobs = "blobs="
+	// Size of the bloom filter of the served blobs in bytes, so that it
+	// fits into a single TXT string (255 bytes) when base64-encoded,
+	// regardless of the number of blobs.
+	peerBloomSize = 180
+	// Number of bits set for every blob.
+	peerBloomHashes = 7
+)
+
+// peerBloom : bloom filter of the sha256 digests of the served blobs.
+// A peer can have a blob only if the filter has it; false positives
+// only cost a failed request. The digests themselves are not advertised.
+type peerBloom []byte
+
+// peerBloomBits returns the bits of the filter for the sha256 digest.
+// The digest is uniformly distributed, so the bits are taken from it
+// directly instead of hashing it again.
+func peerBloomBits(sha string) []uint32 {
+	digest, err := hex.DecodeString(sha)
+	if err != nil || len(digest) != sha256.Size {
+		return nil
+	}
+	bits := make([]uint32, peerBloomHashes)
+	for i := range bits {
+		bits[i] = binary.BigEndian.Uint32(digest[4*i:]) % (peerBloomSize * 8)
+	}
+	return bits
+}
+
+func makePeerBloom(shas []string) peerBloom {
+	bloom := make(peerBloom, peerBloomSize)
+	for _, sha := range shas {
+		for _, bit := range peerBloomBits(sha) {
+			bloom[bit/8] |= 1 << (bit % 8)
+		}
+	}
+	return bloom
+}
+
+// has returns true if the blob may be in the filter.
+func (bloom peerBloom) has(sha string) bool {
+	bits := peerBloomBits(sha)
+	if len(bits) == 0 || len(bloom) != peerBloomSize {
+		return false
+	}
+	for _, bit := range bits {
+		if bloom[bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// peerBlob : blob served by the peer cache.
+type peerBlob struct {
+	mediaType string
+	size      uint64
+}
+
+// peerCache : HTTPS server and mDNS advertisement of the peer cache.
+type peerCache struct {
+	sync.Mutex
+	blobs      map[string]peerBlob // key: sha256
+	server     *http.Server
+	mdnsServer *zeroconf.Server
+	casClient  cas.CAS
+	// Interfaces on which the service is advertised.
+	ifNames []string
+	txt     []string
+	// SHA256 fingerprint of our device certificate.
+	certFingerprint string
+}
+
+// certFingerprint returns hex-encoded SHA256 of the DER-encoded certificate.
+func certFingerprint(der []byte) string {
+	hash := sha256.Sum256(der)
+	return hex.EncodeToString(hash[:])
+}
+
+// peerTrust : fingerprints of the device certificates of the peers
+// vouched for by the controller. Used during the TLS handshakes of the
+// server and the downloads, hence the lock.
+type peerTrust struct {
+	sync.Mutex
+	fingerprints map[string]bool
+}
+
+func (t *peerTrust) set(fingerprints map[string]bool) {
+	t.Lock()
+	t.fingerprints = fingerprints
+	t.Unlock()
+}
+
+func (t *peerTrust) trusted(fingerprint string) bool {
+	t.Lock()
+	defer t.Unlock()
+	return t.fingerprints[fingerprint]
+}
+
+// peerCertFingerprints returns the fingerprints of the PEM certificates.
+func peerCertFingerprints(certsPEM [][]byte) map[string]bool {
+	fingerprints := make(map[string]bool)
+	for _, certPEM := range certsPEM {
+		rest := certPEM
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+				log.Errorf("peerCertFingerprints: bad peer certificate: %v", err)
+				continue
+			}
+			fingerprints[certFingerprint(block.Bytes)] = true
+		}
+	}
+	return fingerprints
+}
+
+// verifyPeerDeviceCert checks the certificate presented by a peer.
+// Only the device certificates vouched for by the controller are accepted.
+// EVE device certificates are self-signed by the device key, which never
+// leaves the device (TPM), so we also check the self-signature, validity
+// and key usage. If expFingerprint is set (as learned from the mDNS
+// advertisement), the certificate must match it.
+// Integrity of the content is not based on this check; every blob
+// is re-verified by the verifier against the expected sha256.
+func verifyPeerDeviceCert(rawCerts [][]byte, expFingerprint string,
+	trust *peerTrust) error {
+	if len(rawCerts) == 0 {
+		return errors.New("peer did not present a certificate")
+	}
+	cert, err := x509.ParseCertificate(rawCerts[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse peer certificate: %v", err)
+	}
+	fingerprint := certFingerprint(rawCerts[0])
+	if expFingerprint != "" && fingerprint != expFingerprint {
+		return errors.New("peer certificate does not match the advertised one")
+	}
+	if !trust.trusted(fingerprint) {
+		return fmt.Errorf("peer certificate %s is not a known peer device certificate",
+			fingerprint)
+	}
+	if !bytes.Equal(cert.RawIssuer, cert.RawSubject) {
+		return errors.New("peer certificate is not a device certificate")
+	}
+	if err = cert.CheckSignatureFrom(cert); err != nil {
+		return fmt.Errorf("invalid peer certificate signature: %v", err)
+	}
+	now := time.Now()
+	if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return errors.New("peer certificate is expired or not yet valid")
+	}
+	var clientAuth, serverAuth bool
+	for _, usage := range cert.ExtKeyUsage {
+		switch usage {
+		case x509.ExtKeyUsageClientAuth:
+			clientAuth = true
+		case x509.ExtKeyUsageServerAuth:
+			serverAuth = true
+		}
+	}
+	if !clientAuth || !serverAuth {
+		return errors.New("peer certificate is missing client/server auth usage")
+	}
+	return nil
+}
+
+// makePeerTxt builds TXT record for the mDNS advertisement.
+func makePeerTxt(fingerprint string, shas []string) []string {
+	return []string{
+		peerTxtCert + fingerprint,
+		peerTxtBlobs + base64.RawStdEncoding.EncodeToString(makePeerBloom(shas)),
+	}
+}
+
+// updatePeerCache starts, stops or updates the peer cache based on
+// the global config, management ports and blobs loaded into CAS.
+func updatePeerCache(ctx *downloaderContext) {
+	if !ctx.peerCacheEnabled {
+		stopPeerCache(ctx)
+		return
+	}
+	pc := ctx.peerCache
+	if pc == nil {
+		var err error
+		if pc, err = startPeerCache(&ctx.peerTrust); err != nil {
+			log.Errorf("updatePeerCache: failed to start peer cache: %v", err)
+			return
+		}
+		ctx.peerCache = pc
+	}
+
+	// Collect blobs loaded into CAS, i.e. verified.
+	blobs := make(map[string]peerBlob)
+	var shas []string
+	for _, item := range ctx.subBlobStatus.GetAll() {
+		blob := item.(types.BlobStatus)
+		if blob.State != types.LOADED || blob.HasError() {
+			continue
+		}
+		sha := strings.ToLower(strings.TrimPrefix(blob.Sha256, "sha256:"))
+		blobs[sha] = peerBlob{mediaType: blob.MediaType, size: blob.Size}
+		shas = append(shas, sha)
+	}
+	sort.Strings(shas)
+	pc.Lock()
+	pc.blobs = blobs
+	pc.Unlock()
+
+	// Advertise on management ports with an IPv4 address.
+	var ifs []net.Interface
+	var ifNames []string
+	for _, port := range ctx.deviceNetworkStatus.Ports {
+		if !port.IsMgmt || port.IfName == "" {
+			continue
+		}
+		hasIPv4 := false
+		for _, ai := range port.AddrInfoList {
+			if ai.Addr.To4() != nil {
+				hasIPv4 = true
+			}
+		}
+		if !hasIPv4 {
+			continue
+		}
+		intf, err := net.InterfaceByName(port.IfName)
+		if err != nil {
+			log.Warnf("updatePeerCache: interface %s: %v", port.IfName, err)
+			continue
+		}
+		ifs = append(ifs, *intf)
+		ifNames = append(ifNames, port.IfName)
+	}
+	txt := makePeerTxt(pc.certFingerprint, shas)
+	if pc.mdnsServer != nil && !reflect.DeepEqual(pc.ifNames, ifNames) {
+		pc.mdnsServer.Shutdown()
+		pc.mdnsServer = nil
+	}
+	pc.ifNames = ifNames
+	if len(ifs) == 0 {
+		// zeroconf would otherwise advertise on all multicast interfaces.
+		return
+	}
+	if pc.mdnsServer == nil {
+		instance, err := os.Hostname()
+		if err != nil {
+			log.Errorf("updatePeerCache: failed to get hostname: %v", err)
+			return
+		}
+		pc.mdnsServer, err = zeroconf.Register(instance, types.PeerCacheService,
+			"local.", types.PeerCachePort, txt, ifs)
+		if err != nil {
+			log.Errorf("updatePeerCache: failed to register mDNS service: %v", err)
+			return
+		}
+		pc.txt = txt
+		log.Noticef("updatePeerCache: advertising %d blobs on %v",
+			len(shas), ifNames)
+	} else if !reflect.DeepEqual(pc.txt, txt) {
+		pc.mdnsServer.SetText(txt)
+		pc.txt = txt
+		log.Functionf("updatePeerCache: advertising %d blobs", len(shas))
+	}
+}
+
+func handlePeerCacheConfigCreate(ctxArg interface{}, key string,
+	configArg interface{}) {
+	handlePeerCacheConfigImpl(ctxArg, key, configArg)
+}
+
+func handlePeerCacheConfigModify(ctxArg interface{}, key string,
+	configArg interface{}, oldConfigArg interface{}) {
+	handlePeerCacheConfigImpl(ctxArg, key, configArg)
+}
+
+func handlePeerCacheConfigImpl(ctxArg interface{}, key string,
+	configArg interface{}) {
+	ctx := ctxArg.(*downloaderContext)
+	config := configArg.(types.PeerCacheConfig)
+	fingerprints := peerCertFingerprints(config.DeviceCertsPEM)
+	ctx.peerTrust.set(fingerprints)
+	log.Noticef("handlePeerCacheConfigImpl: %d peer device certificates",
+		len(fingerprints))
+}
+
+func handlePeerCacheConfigDelete(ctxArg interface{}, key string,
+	configArg interface{}) {
+	ctx := ctxArg.(*downloaderContext)
+	ctx.peerTrust.set(nil)
+	log.Noticef("handlePeerCacheConfigDelete: no peer device certificates")
+}
+
+func handleBlobStatusCreate(ctxArg interface{}, key string,
+	statusArg interface{}) {
+	handleBlobStatusImpl(ctxArg, key, statusArg)
+}
+
+func handleBlobStatusModify(ctxArg interface{}, key string,
+	statusArg interface{}, oldStatusArg interface{}) {
+	handleBlobStatusImpl(ctxArg, key, statusArg)
+}
+
+func handleBlobStatusImpl(ctxArg interface{}, key string,
+	statusArg interface{}) {
+	ctx := ctxArg.(*downloaderContext)
+	if ctx.peerCacheEnabled {
+		updatePeerCache(ctx)
+	}
+}
+
+func handleBlobStatusDelete(ctxArg interface{}, key string,
+	statusArg interface{}) {
+	ctx := ctxArg.(*downloaderContext)
+	if ctx.peerCacheEnabled {
+		updatePeerCache(ctx)
+	}
+}
+
+// startPeerCache starts HTTPS server authenticated with the device
+// certificate and requiring peers to present their device certificates,
+// which have to be vouched for by the controller.
+func startPeerCache(trust *peerTrust) (*peerCache, error) {
+	deviceCert, err := zedcloud.GetClientCert()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load device certificate: %v", err)
+	}
+	casClient, err := cas.NewCAS(casClientType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CAS client: %v", err)
+	}
+	pc := &peerCache{
+		blobs:           make(map[string]peerBlob),
+		casClient:       casClient,
+		certFingerprint: certFingerprint(deviceCert.Certificate[0]),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+zedUpload.PeerBlobsPath+"/", pc.serveBlob)
+	pc.server = &http.Server{
+		Addr:    fmt.Sprintf(":%d", types.PeerCachePort),
+		Handler: mux,
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{deviceCert},
+			ClientAuth:   tls.RequireAnyClientCert,
+			VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+				return verifyPeerDeviceCert(rawCerts, "", trust)
+			},
+			MinVersion: tls.VersionTLS12,
+		},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	listener, err := net.Listen("tcp", pc.server.Addr)
+	if err != nil {
+		casClient.CloseClient()
+		return nil, err
+	}
+	go func() {
+		err := pc.server.ServeTLS(listener, "", "")
+		if err != nil && err != http.ErrServerClosed {
+			log.Errorf("peer cache server failed: %v", err)
+		}
+	}()
+	log.Noticef("startPeerCache: serving blobs on port %d", types.PeerCachePort)
+	return pc, nil
+}
+
+func stopPeerCache(ctx *downloaderContext) {
+	pc := ctx.peerCache
+	if pc == nil {
+		return
+	}
+	if pc.mdnsServer != nil {
+		pc.mdnsServer.Shutdown()
+	}
+	if err := pc.server.Close(); err != nil {
+		log.Errorf("stopPeerCache: failed to close server: %v", err)
+	}
+	if err := pc.casClient.CloseClient(); err != nil {
+		log.Errorf("stopPeerCache: failed to close CAS client: %v", err)
+	}
+	ctx.peerCache = nil
+	log.Noticef("stopPeerCache: peer cache stopped")
+}
+
+// serveBlob handles GET and HEAD requests for /blobs/sha256/<sha>.
+func (pc *peerCache) serveBlob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	sha := strings.TrimPrefix(r.URL.Path, "/"+zedUpload.PeerBlobsPath+"/")
+	pc.Lock()
+	blob, found := pc.blobs[sha]
+	pc.Unlock()
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+	peer := ""
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		peer = certFingerprint(r.TLS.PeerCertificates[0].Raw)
+	}
+	if blob.mediaType != "" {
+		w.Header().Set("Content-Type", blob.mediaType)
+	}
+	if blob.size != 0 {
+		w.Header().Set("Content-Length", strconv.FormatUint(blob.size, 10))
+	}
+	if r.Method == http.MethodHead {
+		return
+	}
+	log.Noticef("serveBlob: sending %s to %s (device cert %s)",
+		sha, r.RemoteAddr, peer)
+	ctrdCtx, done := pc.casClient.CtrNewUserServicesCtx()
+	defer done()
+	ctrdCtx, cancel := context.WithCancel(ctrdCtx)
+	defer cancel()
+	go func() {
+		select {
+		case <-r.Context().Done():
+			cancel()
+		case <-ctrdCtx.Done():
+		}
+	}()
+	reader, err := pc.casClient.ReadBlob(ctrdCtx, "sha256:"+sha)
+	if err != nil {
+		log.Errorf("serveBlob: failed to read %s: %v", sha, err)
+		http.Error(w, "failed to read blob", http.StatusInternalServerError)
+		return
+	}
+	if _, err = io.Copy(w, reader); err != nil {
+		log.Warnf("serveBlob: failed to send %s to %s: %v",
+			sha, r.RemoteAddr, err)
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+// github.com/grandcat/zeroconf: under MIT License
+
+// Discovery of peers (other EVE devices on the same LAN) which advertise
+// a blob in their peer cache, and download from them.
+
+package downloader
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grandcat/zeroconf"
+	"github.com/lf-edge/eve/libs/zedUpload"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	"github.com/lf-edge/eve/pkg/pillar/zedcloud"
+)
+
+const (
+	// How long to wait for mDNS responses from peers.
+	peerQueryTimeout = 3 * time.Second
+	// How long the peers found by browsing are used before browsing again.
+	peerBrowseInterval = time.Minute
+)
+
+// peerEntry : peer cache service found by browsing.
+type peerEntry struct {
+	instance        string
+	addrs           []net.IP
+	port            int
+	certFingerprint string
+	blobs           peerBloom
+}
+
+// peerBrowse : peers found by the last browse, shared by the downloads.
+// The lock is held during browsing, so that concurrent downloads wait for
+// the result instead of browsing as well.
+type peerBrowse struct {
+	sync.Mutex
+	lastBrowse time.Time
+	entries    []peerEntry
+}
+
+// peerInfo : peer offering the requested blob.
+type peerInfo struct {
+	addr            net.IP
+	port            int
+	certFingerprint string
+	// Management port and source IP used to reach the peer.
+	ifName string
+	ipSrc  net.IP
+}
+
+func (p peerInfo) url() string {
+	return fmt.Sprintf("https://%s", net.JoinHostPort(p.addr.String(),
+		fmt.Sprint(p.port)))
+}
+
+// parsePeerTxt returns the fingerprint of the device certificate and
+// the filter of the blobs advertised by the peer.
+func parsePeerTxt(txt []string) (fingerprint string, blobs peerBloom) {
+	for _, record := range txt {
+		switch {
+		case strings.HasPrefix(record, peerTxtCert):
+			fingerprint = strings.TrimPrefix(record, peerTxtCert)
+		case strings.HasPrefix(record, peerTxtBlobs):
+			bloom, err := base64.RawStdEncoding.DecodeString(
+				strings.TrimPrefix(record, peerTxtBlobs))
+			if err == nil {
+				blobs = bloom
+			}
+		}
+	}
+	return fingerprint, blobs
+}
+
+// findPeerSrc returns management port and source IP address from the same
+// subnet as the peer. Peers are expected to be on the same LAN.
+func findPeerSrc(dns types.DeviceNetworkStatus,
+	peerIP net.IP) (ifName string, ipSrc net.IP) {
+	for _, port := range dns.Ports {
+		if !port.IsMgmt || port.Subnet.IP == nil || !port.Subnet.Contains(peerIP) {
+			continue
+		}
+		for _, ai := range port.AddrInfoList {
+			if ai.Addr.Equal(peerIP) {
+				// That is us.
+				return "", nil
+			}
+			if ai.Addr.To4() != nil && port.Subnet.Contains(ai.Addr) {
+				return port.IfName, ai.Addr
+			}
+		}
+	}
+	return "", nil
+}
+
+// findPeers returns the peers with device certificates vouched for by the
+// controller, which advertise the blob and are reachable from one of the
+// management ports.
+func findPeers(ctx *downloaderContext, sha string) ([]peerInfo, error) {
+	entries, err := browsePeers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return selectPeers(entries, sha, &ctx.peerTrust, ctx.deviceNetworkStatus), nil
+}
+
+func selectPeers(entries []peerEntry, sha string, trust *peerTrust,
+	dns types.DeviceNetworkStatus) []peerInfo {
+	var peers []peerInfo
+	for _, entry := range entries {
+		if !entry.blobs.has(sha) || !trust.trusted(entry.certFingerprint) {
+			continue
+		}
+		for _, addr := range entry.addrs {
+			ifName, ipSrc := findPeerSrc(dns, addr)
+			if ipSrc == nil {
+				continue
+			}
+			log.Functionf("findPeers: peer %s (%s) advertises %s",
+				entry.instance, addr, sha)
+			peers = append(peers, peerInfo{
+				addr:            addr,
+				port:            entry.port,
+				certFingerprint: entry.certFingerprint,
+				ifName:          ifName,
+				ipSrc:           ipSrc,
+			})
+			break
+		}
+	}
+	return peers
+}
+
+// browsePeers returns the peer cache services found on the management
+// ports. The result of browsing is reused for peerBrowseInterval.
+func browsePeers(ctx *downloaderContext) ([]peerEntry, error) {
+	ctx.peerBrowse.Lock()
+	defer ctx.peerBrowse.Unlock()
+	if !ctx.peerBrowse.lastBrowse.IsZero() &&
+		time.Since(ctx.peerBrowse.lastBrowse) < peerBrowseInterval {
+		return ctx.peerBrowse.entries, nil
+	}
+	var ifs []net.Interface
+	for _, port := range ctx.deviceNetworkStatus.Ports {
+		if !port.IsMgmt || port.IfName == "" {
+			continue
+		}
+		intf, err := net.InterfaceByName(port.IfName)
+		if err != nil {
+			continue
+		}
+		ifs = append(ifs, *intf)
+	}
+	if len(ifs) == 0 {
+		return nil, errors.New("no management interface to look for peers")
+	}
+	resolver, err := zeroconf.NewResolver(zeroconf.SelectIfaces(ifs),
+		zeroconf.SelectIPTraffic(zeroconf.IPv4))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize resolver: %v", err)
+	}
+	hostname, _ := os.Hostname()
+
+	mctx, cancel := context.WithTimeout(context.Background(), peerQueryTimeout)
+	defer cancel()
+	results := make(chan *zeroconf.ServiceEntry)
+	entriesCh := make(chan []peerEntry)
+	go func(results <-chan *zeroconf.ServiceEntry) {
+		var entries []peerEntry
+		seen := make(map[string]bool)
+		for result := range results {
+			if result.Instance == hostname || seen[result.Instance] ||
+				len(result.AddrIPv4) == 0 {
+				continue
+			}
+			fingerprint, blobs := parsePeerTxt(result.Text)
+			if fingerprint == "" {
+				continue
+			}
+			entries = append(entries, peerEntry{
+				instance:        result.Instance,
+				addrs:           result.AddrIPv4,
+				port:            result.Port,
+				certFingerprint: fingerprint,
+				blobs:           blobs,
+			})
+			seen[result.Instance] = true
+		}
+		entriesCh <- entries
+	}(results)
+
+	err = resolver.Browse(mctx, types.PeerCacheService, "local.", results)
+	if err != nil {
+		cancel()
+		<-entriesCh
+		return nil, fmt.Errorf("resolver error: %v", err)
+	}
+	<-mctx.Done()
+	// Browse closes results channel once the context is done.
+	entries := <-entriesCh
+	log.Functionf("browsePeers: found %d peers", len(entries))
+	ctx.peerBrowse.lastBrowse = time.Now()
+	ctx.peerBrowse.entries = entries
+	return entries, nil
+}
+
+// peerTLSConfig returns TLS configuration which authenticates us with
+// the device certificate and verifies the device certificate of the peer.
+func peerTLSConfig(ctx *downloaderContext, peer peerInfo) (*tls.Config, error) {
+	deviceCert, err := zedcloud.GetClientCert()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load device certificate: %v", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{deviceCert},
+		// Device certificates are self-signed, verification is done
+		// by verifyPeerDeviceCert instead.
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyPeerDeviceCert(rawCerts, peer.certFingerprint,
+				&ctx.peerTrust)
+		},
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
+
+// downloadFromPeers tries to download the blob from peers before going
+// to the datastore. The downloaded file is verified by the verifier
+// against the expected sha256 as any other download.
+// Returns the content type, cancel bool and error.
+func downloadFromPeers(ctx *downloaderContext, config types.DownloaderConfig,
+	status *types.DownloaderStatus, locFilename string,
+	receiveChan chan<- CancelChannel) (string, bool, error) {
+
+	sha := strings.ToLower(config.ImageSha256)
+	peers, err := findPeers(ctx, sha)
+	if err != nil {
+		return "", false, err
+	}
+	if len(peers) == 0 {
+		return "", false, fmt.Errorf("no known peer advertises %s", sha)
+	}
+	var errs []string
+	for _, peer := range peers {
+		tlsConfig, err := peerTLSConfig(ctx, peer)
+		if err != nil {
+			return "", false, err
+		}
+		auth := &zedUpload.AuthInput{
+			AuthType:  "peer",
+			TLSConfig: tlsConfig,
+		}
+		st := &PublishStatus{
+			ctx:    ctx,
+			status: status,
+		}
+		peerURL := peer.url()
+		metricsURL := "peer:" + peer.addr.String()
+		log.Noticef("Downloading %s from peer %s", sha, peerURL)
+		downloadStartTime := time.Now()
+		contentType, cancelled, err := download(ctx, zedUpload.SyncPeerTr, st,
+			zedUpload.SyncOpDownload, peerURL, auth, "", "", config.Size,
+			config.Priority, peer.ifName, peer.ipSrc, sha, locFilename, nil, receiveChan)
+		if err != nil {
+			if cancelled {
+				return "", true, err
+			}
+			log.Warnf("Download of %s from peer %s failed: %v",
+				sha, peerURL, err)
+			ctx.zedcloudMetrics.RecordFailure(log, peer.ifName, metricsURL,
+				1024, 0, false)
+			errs = append(errs, err.Error())
+			// Start from scratch with the next peer or the datastore.
+			if err := os.RemoveAll(locFilename); err != nil {
+				log.Error(err)
+			}
+			if err := os.RemoveAll(locFilename + progressFileSuffix); err != nil {
+				log.Error(err)
+			}
+			continue
+		}
+		size := int64(0)
+		if info, err := os.Stat(locFilename); err == nil {
+			size = info.Size()
+		}
+		downloadTime := int64(time.Since(downloadStartTime) / time.Millisecond)
+		ctx.zedcloudMetrics.RecordSuccess(log, peer.ifName, metricsURL,
+			1024, size, downloadTime, false)
+		return contentType, false, nil
+	}
+	return "", false, errors.New(strings.Join(errs, "\n"))
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	"github.com/sirupsen/logrus"
+)
+
+func testSha(i int) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("blob%d", i)))
+	return hex.EncodeToString(hash[:])
+}
+
+func TestPeerTxt(t *testing.T) {
+	var shas []string
+	for i := 0; i < 100; i++ {
+		shas = append(shas, testSha(i))
+	}
+	txt := makePeerTxt("fingerprint", shas)
+	for _, record := range txt {
+		if len(record) > 255 {
+			t.Errorf("TXT string %q is longer than 255 bytes", record)
+		}
+	}
+	fingerprint, blobs := parsePeerTxt(txt)
+	if fingerprint != "fingerprint" {
+		t.Errorf("parsePeerTxt fingerprint %q, expected %q", fingerprint, "fingerprint")
+	}
+	for _, sha := range shas {
+		if !blobs.has(sha) {
+			t.Errorf("advertised blob %s is not in the filter", sha)
+		}
+	}
+	falsePositives := 0
+	for i := 100; i < 10100; i++ {
+		if blobs.has(testSha(i)) {
+			falsePositives++
+		}
+	}
+	// about 0.1% expected with 100 blobs
+	if falsePositives > 100 {
+		t.Errorf("%d false positives out of 10000", falsePositives)
+	}
+	if blobs.has("not-a-sha") {
+		t.Errorf("invalid sha is in the filter")
+	}
+
+	_, blobs = parsePeerTxt(makePeerTxt("fingerprint", nil))
+	if blobs.has(shas[0]) {
+		t.Errorf("empty filter has blob %s", shas[0])
+	}
+	_, blobs = parsePeerTxt([]string{peerTxtCert + "fingerprint"})
+	if blobs.has(shas[0]) {
+		t.Errorf("peer without filter has blob %s", shas[0])
+	}
+}
+
+func TestSelectPeers(t *testing.T) {
+	log = base.NewSourceLogObject(logrus.StandardLogger(), "downloader", 0)
+	sha := testSha(1)
+	_, subnet, _ := net.ParseCIDR("192.168.1.0/24")
+	dns := types.DeviceNetworkStatus{
+		Ports: []types.NetworkPortStatus{
+			{
+				IfName: "eth0",
+				IsMgmt: true,
+				Subnet: *subnet,
+				AddrInfoList: []types.AddrInfo{
+					{Addr: net.ParseIP("192.168.1.10")},
+				},
+			},
+		},
+	}
+	trust := &peerTrust{}
+	trust.set(map[string]bool{"trusted": true})
+	entries := []peerEntry{
+		{
+			instance:        "has-blob",
+			addrs:           []net.IP{net.ParseIP("192.168.1.20")},
+			port:            types.PeerCachePort,
+			certFingerprint: "trusted",
+			blobs:           makePeerBloom([]string{sha}),
+		},
+		{
+			instance:        "no-blob",
+			addrs:           []net.IP{net.ParseIP("192.168.1.21")},
+			port:            types.PeerCachePort,
+			certFingerprint: "trusted",
+			blobs:           makePeerBloom([]string{testSha(2)}),
+		},
+		{
+			instance:        "untrusted",
+			addrs:           []net.IP{net.ParseIP("192.168.1.22")},
+			port:            types.PeerCachePort,
+			certFingerprint: "untrusted",
+			blobs:           makePeerBloom([]string{sha}),
+		},
+		{
+			instance:        "other-subnet",
+			addrs:           []net.IP{net.ParseIP("10.0.0.1")},
+			port:            types.PeerCachePort,
+			certFingerprint: "trusted",
+			blobs:           makePeerBloom([]string{sha}),
+		},
+	}
+	peers := selectPeers(entries, sha, trust, dns)
+	if len(peers) != 1 {
+		t.Fatalf("selectPeers returned %d peers, expected 1: %+v", len(peers), peers)
+	}
+	if !peers[0].addr.Equal(net.ParseIP("192.168.1.20")) ||
+		peers[0].ifName != "eth0" ||
+		!peers[0].ipSrc.Equal(net.ParseIP("192.168.1.10")) {
+		t.Errorf("selectPeers returned unexpected peer %+v", peers[0])
+	}
+}
@@ -4,6 +4,7 @@
 package downloader
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -162,8 +163,9 @@ func resolveTagsToHash(ctx *downloaderContext, rc types.ResolveConfig,
 			Counter:     rc.Counter,
 		}
 	}
+	rs.SignatureKeys = rc.SignatureKeys
 	sha := maybeNameHasSha(rc.Name)
-	if sha != "" {
+	if sha != "" && len(rc.SignatureKeys) == 0 {
 		rs.ImageSha256 = sha
 		publishResolveStatus(ctx, rs)
 		return
@@ -255,6 +257,29 @@ func resolveTagsToHash(ctx *downloaderContext, rc types.ResolveConfig,
 		return
 	}
 
+	// try the mirrors of the registry before the registry itself
+	if len(dst.Mirrors) != 0 {
+		sha256, cancelled, err = resolveFromMirrors(ctx, dst, remoteName, sha,
+			rc.SignatureKeys, downloadMaxPortCost, receiveChan)
+		if err == nil {
+			rs.ClearError()
+			rs.ImageSha256 = sha256
+			rs.SignatureVerified = len(rc.SignatureKeys) != 0
+			rs.SignatureError = ""
+			publishResolveStatus(ctx, rs)
+			return
+		}
+		if cancelled {
+			rs.SetErrorDescription(types.ErrorDescription{
+				Error: "tag resolution cancelled by user",
+			})
+			publishResolveStatus(ctx, rs)
+			return
+		}
+		log.Warnf("Resolving %s from mirrors failed, trying the registry: %v",
+			rc.Name, err)
+	}
+
 	// Loop through all interfaces until a success
 	for addrIndex := 0; addrIndex < addrCount; addrIndex++ {
 		ipSrc, err := types.GetLocalAddrNoLinkLocalWithCost(ctx.deviceNetworkStatus,
@@ -268,9 +293,34 @@ func resolveTagsToHash(ctx *downloaderContext, rc types.ResolveConfig,
 		log.Functionf("Using IP source %v if %s transport %v",
 			ipSrc, ifname, dsCtx.TransportMethod)
 
-		sha256, cancelled, err = objectMetadata(ctx, trType, syncOp, serverURL, auth,
-			dsCtx.Dpath, dsCtx.Region,
-			ifname, ipSrc, remoteName, receiveChan)
+		if sha != "" {
+			sha256 = sha
+		} else {
+			sha256, cancelled, err = objectMetadata(ctx, trType, syncOp, serverURL, auth,
+				dsCtx.Dpath, dsCtx.Region,
+				ifname, ipSrc, remoteName, nil, receiveChan)
+		}
+		if err == nil && len(rc.SignatureKeys) != 0 {
+			cancelled, err = verifyImageSignature(signatureFetcher{
+				ctx:         ctx,
+				trType:      trType,
+				serverURL:   serverURL,
+				auth:        auth,
+				dpath:       dsCtx.Dpath,
+				region:      dsCtx.Region,
+				ifname:      ifname,
+				ipSrc:       ipSrc,
+				receiveChan: receiveChan,
+			}, remoteName, sha256, rc.SignatureKeys)
+			var sigErr *signatureError
+			if errors.As(err, &sigErr) {
+				// the same signatures on all interfaces
+				rs.SignatureVerified = false
+				rs.SignatureError = sigErr.Error()
+				errStr = sigErr.Error()
+				break
+			}
+		}
 		if err != nil {
 			if cancelled {
 				errStr = "tag resolution cancelled by user"
@@ -284,6 +334,8 @@ func resolveTagsToHash(ctx *downloaderContext, rc types.ResolveConfig,
 		}
 		rs.ClearError()
 		rs.ImageSha256 = sha256
+		rs.SignatureVerified = len(rc.SignatureKeys) != 0
+		rs.SignatureError = ""
 		publishResolveStatus(ctx, rs)
 		return
 
@@ -0,0 +1,165 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Scheduling of large downloads into the download windows and limiting of
+// the bandwidth used by the downloads on each management port
+
+package downloader
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lf-edge/eve/libs/zedUpload"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+// metadataPriority lets the small fetches needed to start a download, e.g.
+// of signatures, go ahead of the downloads sharing the bandwidth
+const metadataPriority int32 = 1
+
+// downloadSchedule is used by the per-download goroutines, hence the lock
+type downloadSchedule struct {
+	sync.Mutex
+	windows []types.DownloadWindow
+	minSize uint64 // in bytes; smaller downloads ignore the windows
+	rate    int64  // in bytes per second on each port, zero means unlimited
+	// limiters by ifname
+	limiters map[string]*zedUpload.BandwidthLimiter
+	// paused has the keys of the downloads being paused
+	paused map[string]bool
+}
+
+// updateDownloadSchedule applies the global config
+func updateDownloadSchedule(ctx *downloaderContext, gcp *types.ConfigItemValueMap) {
+	s := &ctx.schedule
+	windows, err := types.ParseDownloadWindows(gcp.GlobalValueString(types.DownloadWindows))
+	if err != nil {
+		// rejected by the validator, so should not happen
+		log.Errorf("updateDownloadSchedule: %v", err)
+		windows = nil
+	}
+	minSize := uint64(gcp.GlobalValueInt(types.DownloadWindowMinSize)) * 1024 * 1024
+	// kbit/s to bytes/s
+	rate := int64(gcp.GlobalValueInt(types.DownloadMaxBandwidth)) * 1000 / 8
+
+	s.Lock()
+	defer s.Unlock()
+	if rate != s.rate {
+		log.Noticef("updateDownloadSchedule: bandwidth limit %d bytes/s per port",
+			rate)
+		for _, limiter := range s.limiters {
+			limiter.SetRate(rate)
+		}
+	}
+	s.windows = windows
+	s.minSize = minSize
+	s.rate = rate
+}
+
+// portLimiter returns the limiter shared by all downloads using the port.
+// The limiter exists even without a limit so a new limit applies to the
+// downloads in progress.
+func (s *downloadSchedule) portLimiter(ifname string) *zedUpload.BandwidthLimiter {
+	if ifname == "" {
+		return nil
+	}
+	s.Lock()
+	defer s.Unlock()
+	if s.limiters == nil {
+		s.limiters = make(map[string]*zedUpload.BandwidthLimiter)
+	}
+	limiter, ok := s.limiters[ifname]
+	if !ok {
+		limiter = zedUpload.NewBandwidthLimiter(s.rate)
+		s.limiters[ifname] = limiter
+	}
+	return limiter
+}
+
+// holdDownload returns true and the start of the next download window if
+// the download has to wait for it. Downloads with a priority above zero and
+// small downloads run at any time; a download of unknown size is large.
+func (s *downloadSchedule) holdDownload(config types.DownloaderConfig,
+	now time.Time) (bool, time.Time) {
+
+	if config.Priority > 0 {
+		return false, time.Time{}
+	}
+	s.Lock()
+	defer s.Unlock()
+	if config.Size != 0 && config.Size < s.minSize {
+		return false, time.Time{}
+	}
+	if types.InDownloadWindows(s.windows, now) {
+		return false, time.Time{}
+	}
+	return true, types.NextDownloadWindow(s.windows, now)
+}
+
+// nextWindow returns the time at or after now when large downloads may run
+func (s *downloadSchedule) nextWindow(now time.Time) time.Time {
+	s.Lock()
+	defer s.Unlock()
+	return types.NextDownloadWindow(s.windows, now)
+}
+
+// setPaused records that the download with key is cancelled to pause it
+func (s *downloadSchedule) setPaused(key string, paused bool) {
+	s.Lock()
+	defer s.Unlock()
+	if s.paused == nil {
+		s.paused = make(map[string]bool)
+	}
+	if paused {
+		s.paused[key] = true
+	} else {
+		delete(s.paused, key)
+	}
+}
+
+// takePaused returns and clears the paused mark of the download with key
+func (s *downloadSchedule) takePaused(key string) bool {
+	s.Lock()
+	defer s.Unlock()
+	paused := s.paused[key]
+	delete(s.paused, key)
+	return paused
+}
+
+// checkDownloadSchedule runs periodically; it starts the scheduled
+// downloads when their window opens and pauses the large downloads in
+// progress when the window closes
+func checkDownloadSchedule(ctx *downloaderContext) {
+	now := time.Now()
+	items := ctx.pubDownloaderStatus.GetAll()
+	for _, st := range items {
+		status := st.(types.DownloaderStatus)
+		if status.RefCount == 0 || status.State != types.DOWNLOADING ||
+			status.HasError() {
+			continue
+		}
+		config := lookupDownloaderConfig(ctx, status.Key())
+		if config == nil || config.RefCount == 0 {
+			continue
+		}
+		hold, next := ctx.schedule.holdDownload(*config, now)
+		if status.Scheduled {
+			if !hold {
+				log.Noticef("checkDownloadSchedule: starting scheduled download of %s",
+					status.Name)
+				dHandler.modify(ctx, status.Key(), *config)
+			}
+			continue
+		}
+		if !hold {
+			continue
+		}
+		log.Noticef("checkDownloadSchedule: pausing download of %s until %v",
+			status.Name, next)
+		ctx.schedule.setPaused(status.Key(), true)
+		if !dHandler.pause(status.Key()) {
+			ctx.schedule.setPaused(status.Key(), false)
+		}
+	}
+}
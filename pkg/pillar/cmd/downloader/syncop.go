@@ -14,6 +14,7 @@ import (
 
 	zconfig "github.com/lf-edge/eve/api/go/config"
 	"github.com/lf-edge/eve/libs/zedUpload"
+	"github.com/lf-edge/eve/pkg/pillar/chunkindex"
 	"github.com/lf-edge/eve/pkg/pillar/cipher"
 	"github.com/lf-edge/eve/pkg/pillar/types"
 	logutils "github.com/lf-edge/eve/pkg/pillar/utils/logging"
@@ -65,6 +66,7 @@ func handleSyncOp(ctx *downloaderContext, key string,
 	// derived, but it is good for the status to say where it *is*, as opposed to
 	// config, which says where it *should be*
 	status.Target = locFilename
+	status.Mirror = ""
 	publishDownloaderStatus(ctx, status)
 
 	// make sure the directory exists - just a safety check
@@ -75,6 +77,63 @@ func handleSyncOp(ctx *downloaderContext, key string,
 		}
 	}
 
+	// Take the image from the update bundle if it carries it
+	if config.ImageSha256 != "" {
+		contentType, err = downloadFromLocalBundle(config.ImageSha256,
+			locFilename)
+		if err == nil {
+			log.Noticef("Took %s from update bundle", config.Name)
+			size := int64(0)
+			if info, err := os.Stat(locFilename); err == nil {
+				size = info.Size()
+			}
+			status.Size = uint64(size)
+			status.ContentType = contentType
+			st := &PublishStatus{
+				ctx:    ctx,
+				status: status,
+			}
+			st.Progress(100, size, size)
+			handleSyncOpResponse(ctx, config, status,
+				locFilename, key, "", cancelled, cleanOnError)
+			return
+		}
+		if !os.IsNotExist(err) {
+			log.Errorf("Update bundle image %s: %v", config.Name, err)
+		}
+	}
+
+	// Then try peers on the local network (if enabled).
+	// Only blobs with known sha256 are shared between peers,
+	// the downloaded content is checked by the verifier as usual.
+	if ctx.peerCacheEnabled && config.ImageSha256 != "" {
+		contentType, cancelled, err = downloadFromPeers(ctx, config, status,
+			locFilename, receiveChan)
+		if err == nil {
+			size := int64(0)
+			if info, err := os.Stat(locFilename); err == nil {
+				size = info.Size()
+			}
+			status.Size = uint64(size)
+			status.ContentType = contentType
+			st := &PublishStatus{
+				ctx:    ctx,
+				status: status,
+			}
+			st.Progress(100, size, size)
+			handleSyncOpResponse(ctx, config, status,
+				locFilename, key, "", cancelled, cleanOnError)
+			return
+		}
+		if cancelled {
+			handleSyncOpResponse(ctx, config, status, locFilename,
+				key, "download cancelled by user", cancelled, cleanOnError)
+			return
+		}
+		log.Functionf("Peer download of %s failed, using datastore: %v",
+			config.Name, err)
+	}
+
 	downloadMaxPortCost := ctx.downloadMaxPortCost
 	log.Functionf("Downloading <%s> to <%s> using %d downloadMaxPortCost",
 		config.Name, locFilename, downloadMaxPortCost)
@@ -194,6 +253,39 @@ func handleSyncOp(ctx *downloaderContext, key string,
 		return
 	}
 
+	// Try the mirrors of the registry before the registry itself.
+	// Only blobs with known sha256 are pulled from mirrors.
+	if trType == zedUpload.SyncOCIRegistryTr && len(dst.Mirrors) != 0 &&
+		config.ImageSha256 != "" {
+		contentType, cancelled, err = downloadFromMirrors(ctx, config, status,
+			dst, remoteName, locFilename, downloadMaxPortCost, receiveChan)
+		if err == nil {
+			log.Noticef("Downloaded %s from mirror %s", config.Name,
+				status.Mirror)
+			size := int64(0)
+			if info, err := os.Stat(locFilename); err == nil {
+				size = info.Size()
+			}
+			status.Size = uint64(size)
+			status.ContentType = contentType
+			st := &PublishStatus{
+				ctx:    ctx,
+				status: status,
+			}
+			st.Progress(100, size, size)
+			handleSyncOpResponse(ctx, config, status,
+				locFilename, key, "", cancelled, cleanOnError)
+			return
+		}
+		if cancelled {
+			handleSyncOpResponse(ctx, config, status, locFilename,
+				key, "download cancelled by user", cancelled, cleanOnError)
+			return
+		}
+		log.Warnf("Download of %s from mirrors failed, using registry: %v",
+			config.Name, err)
+	}
+
 	// if the server URL ends with '.local', it is considered to be local data store
 	dsLocal := strings.HasSuffix(serverURL, ".local") || strings.HasSuffix(serverURL, ".local.")
 	if dsLocal {
@@ -235,6 +327,55 @@ func handleSyncOp(ctx *downloaderContext, key string,
 		log.Functionf("Using server URL %s IP source %v if %s transport %v",
 			serverURL, ipSrc, ifname, dsCtx.TransportMethod)
 
+		// Assemble the image from chunks if there is a chunk index;
+		// on failure download the whole image.
+		if config.ChunkIndexURL != "" && trType != zedUpload.SyncOCIRegistryTr {
+			// remoteName may have a prefix added to config.Name
+			remotePrefix := strings.TrimSuffix(remoteName, config.Name)
+			fetch := func(name, target string, maxSize uint64) (bool, error) {
+				_, cancelled, err := download(ctx, trType, noProgress{}, syncOp,
+					serverURL, auth, dsPath, dsCtx.Region, maxSize, config.Priority,
+					ifname, ipSrc, remotePrefix+name, target, dst.DsCertPEM,
+					receiveChan)
+				return cancelled, err
+			}
+			downloadStartTime := time.Now()
+			var stats chunkindex.Stats
+			stats, cancelled, err = downloadChunked(ctx, config, status,
+				locFilename, fetch)
+			if err == nil {
+				downloadTime := int64(time.Since(downloadStartTime) / time.Millisecond)
+				log.Noticef("Assembled %s from chunks: %d bytes reused, %d bytes downloaded",
+					config.Name, stats.Reused, stats.Fetched)
+				size := int64(stats.Reused + stats.Fetched)
+				status.Size = uint64(size)
+				ctx.zedcloudMetrics.RecordSuccess(log, ifname,
+					metricsURL, 1024, int64(stats.Fetched), downloadTime, false)
+				st := &PublishStatus{
+					ctx:    ctx,
+					status: status,
+				}
+				st.Progress(100, size, size)
+				handleSyncOpResponse(ctx, config, status,
+					locFilename, key, "", cancelled, cleanOnError)
+				return
+			}
+			if cancelled {
+				log.Errorf("chunked download %s cancelled", serverURL)
+				errStr = "download cancelled by user"
+				break
+			}
+			log.Warnf("Chunked download of %s failed, downloading whole image: %v",
+				config.Name, err)
+			// Start from scratch with the whole image.
+			if err := os.RemoveAll(locFilename); err != nil {
+				log.Error(err)
+			}
+			if err := os.RemoveAll(locFilename + progressFileSuffix); err != nil {
+				log.Error(err)
+			}
+		}
+
 		// do the download
 		st := &PublishStatus{
 			ctx:    ctx,
@@ -243,8 +384,8 @@ func handleSyncOp(ctx *downloaderContext, key string,
 		downloadStartTime := time.Now()
 		contentType, cancelled, err = download(ctx, trType, st, syncOp, serverURL, auth,
 			dsPath, dsCtx.Region,
-			config.Size, ifname, ipSrc, remoteName, locFilename, dst.DsCertPEM,
-			receiveChan)
+			config.Size, config.Priority, ifname, ipSrc, remoteName, locFilename,
+			dst.DsCertPEM, receiveChan)
 		if err != nil {
 			if cancelled {
 				log.Errorf("download %s cancelled", serverURL)
@@ -315,6 +456,18 @@ func handleSyncOpResponse(ctx *downloaderContext, config types.DownloaderConfig,
 	// based on the result, perform some storage
 	// management also
 
+	// Keep what we have of a download paused at the end of the download
+	// window, it is scheduled again
+	if ctx.schedule.takePaused(key) && cancelled {
+		status.Paused = true
+		status.Scheduled = true
+		status.ScheduledTime = ctx.schedule.nextWindow(time.Now())
+		publishDownloaderStatus(ctx, status)
+		log.Noticef("handleSyncOpResponse(%s): paused until %v",
+			status.Name, status.ScheduledTime)
+		return
+	}
+
 	if errStr != "" {
 		if cleanOnError {
 			// Delete file, and update the storage
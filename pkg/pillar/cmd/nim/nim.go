@@ -12,6 +12,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -87,6 +88,14 @@ type nim struct {
 	subDevicePortConfigS  pubsub.Subscription
 	subZedAgentStatus     pubsub.Subscription
 	subAssignableAdapters pubsub.Subscription
+	// To learn which wireless adapters operate as access points.
+	subNetworkInstanceConfig pubsub.Subscription
+	// Zedcloud metrics of other microservices used to estimate port quality.
+	subZedAgentMetrics    pubsub.Subscription
+	subClientMetrics      pubsub.Subscription
+	subDownloaderMetrics  pubsub.Subscription
+	subLoguploaderMetrics pubsub.Subscription
+	subZedrouterMetrics   pubsub.Subscription
 
 	// Publications
 	pubDummyDevicePortConfig pubsub.Publication // For logging
@@ -99,6 +108,9 @@ type nim struct {
 	pubWwanStatus            pubsub.Publication
 	pubWwanMetrics           pubsub.Publication
 	pubWwanLocationInfo      pubsub.Publication
+	pubWwanConfig            pubsub.Publication
+	pubPortQualityHistory    pubsub.Publication
+	pubNTPStatus             pubsub.Publication
 
 	// Metrics
 	zedcloudMetrics *zedcloud.AgentMetrics
@@ -170,6 +182,7 @@ func (n *nim) init() (err error) {
 		SubEdgeNodeCert:      n.subEdgeNodeCert,
 		PubCipherBlockStatus: n.pubCipherBlockStatus,
 		CipherMetrics:        n.cipherMetrics,
+		PubWwanConfig:        n.pubWwanConfig,
 	}
 	n.dpcManager = &dpcmanager.DpcManager{
 		Log:                      n.Log,
@@ -184,7 +197,10 @@ func (n *nim) init() (err error) {
 		PubWwanStatus:            n.pubWwanStatus,
 		PubWwanMetrics:           n.pubWwanMetrics,
 		PubWwanLocationInfo:      n.pubWwanLocationInfo,
+		PubPortQualityHistory:    n.pubPortQualityHistory,
+		PubNTPStatus:             n.pubNTPStatus,
 		ZedcloudMetrics:          n.zedcloudMetrics,
+		PubSub:                   n.PubSub,
 	}
 	return nil
 }
@@ -318,6 +334,24 @@ func (n *nim) run(ctx context.Context) (err error) {
 		case change := <-n.subZedAgentStatus.MsgChan():
 			n.subZedAgentStatus.ProcessChange(change)
 
+		case change := <-n.subZedAgentMetrics.MsgChan():
+			n.subZedAgentMetrics.ProcessChange(change)
+
+		case change := <-n.subClientMetrics.MsgChan():
+			n.subClientMetrics.ProcessChange(change)
+
+		case change := <-n.subDownloaderMetrics.MsgChan():
+			n.subDownloaderMetrics.ProcessChange(change)
+
+		case change := <-n.subLoguploaderMetrics.MsgChan():
+			n.subLoguploaderMetrics.ProcessChange(change)
+
+		case change := <-n.subZedrouterMetrics.MsgChan():
+			n.subZedrouterMetrics.ProcessChange(change)
+
+		case change := <-n.subNetworkInstanceConfig.MsgChan():
+			n.subNetworkInstanceConfig.ProcessChange(change)
+
 		case change := <-n.subAssignableAdapters.MsgChan():
 			n.subAssignableAdapters.ProcessChange(change)
 			if waitForAA && n.assignableAdapters.Initialized {
@@ -493,6 +527,34 @@ func (n *nim) initPublications() (err error) {
 	if err != nil {
 		return err
 	}
+
+	n.pubWwanConfig, err = n.PubSub.NewPublication(
+		pubsub.PublicationOptions{
+			AgentName: agentName,
+			TopicType: types.WwanConfig{},
+		})
+	if err != nil {
+		return err
+	}
+
+	n.pubPortQualityHistory, err = n.PubSub.NewPublication(
+		pubsub.PublicationOptions{
+			AgentName:  agentName,
+			Persistent: true,
+			TopicType:  types.PortQualityHistory{},
+		})
+	if err != nil {
+		return err
+	}
+
+	n.pubNTPStatus, err = n.PubSub.NewPublication(
+		pubsub.PublicationOptions{
+			AgentName: agentName,
+			TopicType: types.NTPStatus{},
+		})
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -637,6 +699,49 @@ func (n *nim) initSubscriptions() (err error) {
 		return err
 	}
 
+	// To enable radio transmission for wireless adapters operating
+	// as access points of local network instances.
+	n.subNetworkInstanceConfig, err = n.PubSub.NewSubscription(pubsub.SubscriptionOptions{
+		AgentName:     "zedagent",
+		MyAgentName:   agentName,
+		TopicImpl:     types.NetworkInstanceConfig{},
+		Activate:      true,
+		CreateHandler: n.handleNetworkInstanceCreate,
+		ModifyHandler: n.handleNetworkInstanceModify,
+		DeleteHandler: n.handleNetworkInstanceDelete,
+		WarningTime:   warningTime,
+		ErrorTime:     errorTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	// To estimate link quality of ports from controller requests,
+	// downloads and probes made by other microservices.
+	// Agent name is passed to handlers as context.
+	metricsSubs := map[string]*pubsub.Subscription{
+		"zedagent":    &n.subZedAgentMetrics,
+		"zedclient":   &n.subClientMetrics,
+		"downloader":  &n.subDownloaderMetrics,
+		"loguploader": &n.subLoguploaderMetrics,
+		"zedrouter":   &n.subZedrouterMetrics,
+	}
+	for agent, sub := range metricsSubs {
+		*sub, err = n.PubSub.NewSubscription(pubsub.SubscriptionOptions{
+			AgentName:     agent,
+			MyAgentName:   agentName,
+			TopicImpl:     types.MetricsMap{},
+			Activate:      true,
+			Ctx:           agent,
+			CreateHandler: n.handleZedcloudMetricsCreate,
+			ModifyHandler: n.handleZedcloudMetricsModify,
+			WarningTime:   warningTime,
+			ErrorTime:     errorTime,
+		})
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -795,6 +900,51 @@ func (n *nim) handleZedAgentStatusImpl(_ string, statusArg interface{}) {
 	n.dpcManager.UpdateRadioSilence(zedagentStatus.RadioSilence)
 }
 
+func (n *nim) handleNetworkInstanceCreate(_ interface{}, _ string, _ interface{}) {
+	n.updateWlanAccessPoints()
+}
+
+func (n *nim) handleNetworkInstanceModify(_ interface{}, _ string, _, _ interface{}) {
+	n.updateWlanAccessPoints()
+}
+
+func (n *nim) handleNetworkInstanceDelete(_ interface{}, _ string, _ interface{}) {
+	n.updateWlanAccessPoints()
+}
+
+// updateWlanAccessPoints collects logical labels of wireless adapters
+// configured to operate as access points of (activated) network instances.
+func (n *nim) updateWlanAccessPoints() {
+	var adapters []string
+	for _, item := range n.subNetworkInstanceConfig.GetAll() {
+		config := item.(types.NetworkInstanceConfig)
+		if !config.Activate || config.HasError() ||
+			!config.AccessPoint.IsEnabled() {
+			continue
+		}
+		adapters = append(adapters, config.AccessPoint.Adapter)
+	}
+	sort.Strings(adapters)
+	n.dpcManager.UpdateWlanAccessPoints(adapters)
+}
+
+func (n *nim) handleZedcloudMetricsCreate(ctxArg interface{}, key string, metricsArg interface{}) {
+	n.handleZedcloudMetricsImpl(ctxArg, key, metricsArg)
+}
+
+func (n *nim) handleZedcloudMetricsModify(ctxArg interface{}, key string, metricsArg, _ interface{}) {
+	n.handleZedcloudMetricsImpl(ctxArg, key, metricsArg)
+}
+
+func (n *nim) handleZedcloudMetricsImpl(ctxArg interface{}, key string, metricsArg interface{}) {
+	if key != "global" {
+		return
+	}
+	source := ctxArg.(string)
+	metrics := metricsArg.(types.MetricsMap)
+	n.dpcManager.UpdateZedcloudMetrics(source, metrics)
+}
+
 // ingestPortConfig reads all json files in configDevicePortConfigDir, ensures
 // they have a TimePriority, and adds a ShaFile and Shavalue to them and then writes to
 // runDevicePortConfigDir.
@@ -36,7 +36,7 @@ func updateTickerTime(ctxPtr *nodeagentContext) {
 	ctxPtr.timeTickCount += timeTickInterval
 
 	switch ctxPtr.configGetStatus {
-	case types.ConfigGetSuccess:
+	case types.ConfigGetSuccess, types.ConfigGetLocalBundle:
 		ctxPtr.lastControllerReachableTime = ctxPtr.timeTickCount
 
 	case types.ConfigGetTemporaryFail:
@@ -90,20 +90,34 @@ func handleResetOnCloudDisconnect(ctxPtr *nodeagentContext) {
 }
 
 // on upgrade validation testing time expiry,
+// evaluate the health gates if any, and
 // initiate the validation completion procedure
 func handleUpgradeTestValidation(ctxPtr *nodeagentContext) {
-	if !ctxPtr.testInprogress || ctxPtr.deviceReboot || ctxPtr.devicePoweroff {
+	if !ctxPtr.testInprogress || ctxPtr.deviceReboot || ctxPtr.devicePoweroff ||
+		ctxPtr.healthGatesInprogress {
 		return
 	}
 	if checkUpgradeValidationTestTimeExpiry(ctxPtr) {
-		log.Functionf("CurPart: %s, Upgrade Validation Test Complete",
-			ctxPtr.curPart)
-		resetTestStartTime(ctxPtr)
-		initiateBaseOsZedCloudTestComplete(ctxPtr)
-		publishNodeAgentStatus(ctxPtr)
+		gates := lookupHealthGates(ctxPtr)
+		if gates != nil && !gates.Empty() {
+			log.Functionf("CurPart: %s, evaluating health gates",
+				ctxPtr.curPart)
+			// completed from handleHealthGateResult
+			startHealthGates(ctxPtr, *gates)
+			return
+		}
+		completeUpgradeTestValidation(ctxPtr)
 	}
 }
 
+func completeUpgradeTestValidation(ctxPtr *nodeagentContext) {
+	log.Functionf("CurPart: %s, Upgrade Validation Test Complete",
+		ctxPtr.curPart)
+	resetTestStartTime(ctxPtr)
+	initiateBaseOsZedCloudTestComplete(ctxPtr)
+	publishNodeAgentStatus(ctxPtr)
+}
+
 // when baseos upgrade is inprogress,
 // check if vault is accessible, and if not reset the node
 func handleRebootOnVaultLocked(ctxPtr *nodeagentContext) {
@@ -226,6 +240,13 @@ func updateZedagentCloudConnectStatus(ctxPtr *nodeagentContext,
 	case types.ConfigGetReadSaved:
 		log.Functionf("Config is read from saved config")
 
+	case types.ConfigGetLocalBundle:
+		// Air-gapped device; the update bundle stands in for the
+		// controller hence validate the update the same way
+		log.Functionf("Config is from an update bundle")
+		ctxPtr.lastControllerReachableTime = ctxPtr.timeTickCount
+		setTestStartTime(ctxPtr)
+
 	case types.ConfigGetFail:
 		log.Functionf("Config get from controller has failed")
 	}
@@ -0,0 +1,278 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Operator defined health gates of the baseos upgrade validation.
+// The gates are evaluated once the upgrade validation test time expires,
+// before the partition is marked active. If any gate fails we fall back
+// to the other partition, and report the failed gate after the reboot.
+
+package nodeagent
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lf-edge/eve/pkg/pillar/containerd"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	fileutils "github.com/lf-edge/eve/pkg/pillar/utils/file"
+)
+
+const (
+	// Failed gate saved across the fallback reboot
+	healthGateFailureFile = types.PersistStatusDir + "/health-gate-failure"
+	httpProbeTimeout      = 15 * time.Second
+	defaultScriptTimeout  = 30 * time.Second
+)
+
+// healthGateResult : result of the health gates which run in a goroutine
+type healthGateResult struct {
+	testStartTime uint32 // Identifies the validation test
+	err           error
+}
+
+// httpProbe : probe with the address of the network instance to send it from
+type httpProbe struct {
+	types.BaseOsHTTPProbe
+	localIP string
+}
+
+// scriptCheck : script with the name of the domain to run it in
+type scriptCheck struct {
+	types.BaseOsScriptCheck
+	domainName string
+	timeout    time.Duration
+}
+
+// scriptTimeout returns the configured timeout of the script, or the default
+// one, bounded by the upgrade validation test time
+func scriptTimeout(configured, testTime time.Duration) time.Duration {
+	timeout := configured
+	if timeout == 0 {
+		timeout = defaultScriptTimeout
+	}
+	if testTime > 0 && timeout > testTime {
+		timeout = testTime
+	}
+	return timeout
+}
+
+// lookupHealthGates returns the gates of the BaseOs in the current partition
+func lookupHealthGates(ctxPtr *nodeagentContext) *types.BaseOsHealthGates {
+	zbootStatus := lookupZbootStatus(ctxPtr, ctxPtr.curPart)
+	if zbootStatus == nil {
+		return nil
+	}
+	for _, item := range ctxPtr.subBaseOsConfig.GetAll() {
+		config := item.(types.BaseOsConfig)
+		if config.Activate && config.BaseOsVersion == zbootStatus.ShortVersion {
+			return &config.HealthGates
+		}
+	}
+	return nil
+}
+
+// startHealthGates checks the state of app and network instances, and starts
+// the probes and scripts in a goroutine.
+func startHealthGates(ctxPtr *nodeagentContext, gates types.BaseOsHealthGates) {
+	var probes []httpProbe
+	var scripts []scriptCheck
+	testTime := time.Second * time.Duration(
+		ctxPtr.globalConfig.GlobalValueInt(types.MintimeUpdateSuccess))
+	for _, appUUID := range gates.AppInstances {
+		if _, err := lookupRunningDomain(ctxPtr, appUUID.String()); err != nil {
+			handleHealthGateFailure(ctxPtr, err)
+			return
+		}
+	}
+	for _, niUUID := range gates.NetworkInstances {
+		if _, err := lookupActiveNetworkInstance(ctxPtr, niUUID.String()); err != nil {
+			handleHealthGateFailure(ctxPtr, err)
+			return
+		}
+	}
+	for _, probe := range gates.HTTPProbes {
+		status, err := lookupActiveNetworkInstance(ctxPtr, probe.NetworkInstance.String())
+		if err != nil {
+			handleHealthGateFailure(ctxPtr,
+				fmt.Errorf("HTTP probe %s: %v", probe.URL, err))
+			return
+		}
+		probes = append(probes, httpProbe{BaseOsHTTPProbe: probe,
+			localIP: status.BridgeIPAddr})
+	}
+	for _, script := range gates.Scripts {
+		status, err := lookupRunningDomain(ctxPtr, script.AppInstance.String())
+		if err != nil {
+			handleHealthGateFailure(ctxPtr,
+				fmt.Errorf("script %s: %v", strings.Join(script.Command, " "), err))
+			return
+		}
+		scripts = append(scripts, scriptCheck{BaseOsScriptCheck: script,
+			domainName: status.DomainName,
+			timeout:    scriptTimeout(script.Timeout, testTime)})
+	}
+	ctxPtr.healthGatesInprogress = true
+	testStartTime := ctxPtr.upgradeTestStartTime
+	log.Noticef("Starting %d HTTP probes and %d scripts of health gates",
+		len(probes), len(scripts))
+	go func() {
+		ctxPtr.healthGateResult <- healthGateResult{
+			testStartTime: testStartTime,
+			err:           runHealthGateChecks(probes, scripts),
+		}
+	}()
+}
+
+func lookupRunningDomain(ctxPtr *nodeagentContext, key string) (*types.DomainStatus, error) {
+	item, _ := ctxPtr.subDomainStatus.Get(key)
+	if item == nil {
+		return nil, fmt.Errorf("app instance %s not found", key)
+	}
+	status := item.(types.DomainStatus)
+	if status.State != types.RUNNING {
+		return nil, fmt.Errorf("app instance %s (%s) is %s, not running",
+			key, status.DisplayName, status.State.String())
+	}
+	return &status, nil
+}
+
+func lookupActiveNetworkInstance(ctxPtr *nodeagentContext, key string) (*types.NetworkInstanceStatus, error) {
+	item, _ := ctxPtr.subNetworkInstanceStatus.Get(key)
+	if item == nil {
+		return nil, fmt.Errorf("network instance %s not found", key)
+	}
+	status := item.(types.NetworkInstanceStatus)
+	if status.HasError() {
+		return nil, fmt.Errorf("network instance %s (%s) has error: %s",
+			key, status.DisplayName, status.Error)
+	}
+	if !status.Activated {
+		return nil, fmt.Errorf("network instance %s (%s) is not activated",
+			key, status.DisplayName)
+	}
+	return &status, nil
+}
+
+// runHealthGateChecks runs the probes and scripts; returns the first failure
+func runHealthGateChecks(probes []httpProbe, scripts []scriptCheck) error {
+	for _, probe := range probes {
+		if err := runHTTPProbe(probe.BaseOsHTTPProbe, probe.localIP); err != nil {
+			return fmt.Errorf("HTTP probe %s from network instance %s: %v",
+				probe.URL, probe.NetworkInstance, err)
+		}
+	}
+	if len(scripts) == 0 {
+		return nil
+	}
+	ctrdClient, err := containerd.NewContainerdClient(true)
+	if err != nil {
+		return fmt.Errorf("script: %v", err)
+	}
+	defer ctrdClient.CloseClient()
+	for _, script := range scripts {
+		ctrdCtx, done := ctrdClient.CtrNewUserServicesCtx()
+		execCtx, cancel := context.WithTimeout(ctrdCtx, script.timeout)
+		stdOut, stdErr, err := ctrdClient.CtrExec(execCtx, script.domainName,
+			script.Command)
+		if execCtx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %v", script.timeout)
+		}
+		cancel()
+		done()
+		if err != nil {
+			return fmt.Errorf("script %s in app instance %s: %v: %s",
+				strings.Join(script.Command, " "), script.AppInstance,
+				err, strings.TrimSpace(stdErr))
+		}
+		log.Functionf("script %s in app instance %s passed: %s",
+			strings.Join(script.Command, " "), script.AppInstance, stdOut)
+	}
+	return nil
+}
+
+// runHTTPProbe sends GET request from localIP (if not empty)
+// and checks the status code of the response.
+func runHTTPProbe(probe types.BaseOsHTTPProbe, localIP string) error {
+	dialer := &net.Dialer{Timeout: httpProbeTimeout}
+	if localIP != "" {
+		ip := net.ParseIP(localIP)
+		if ip == nil {
+			return fmt.Errorf("bad local address %s", localIP)
+		}
+		dialer.LocalAddr = &net.TCPAddr{IP: ip}
+	}
+	client := &http.Client{
+		Timeout: httpProbeTimeout,
+		Transport: &http.Transport{
+			DialContext: dialer.DialContext,
+		},
+	}
+	resp, err := client.Get(probe.URL)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if probe.ExpectedStatus != 0 {
+		if resp.StatusCode != probe.ExpectedStatus {
+			return fmt.Errorf("status %d, expected %d", resp.StatusCode,
+				probe.ExpectedStatus)
+		}
+	} else if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// handleHealthGateResult completes the upgrade validation test if
+// the probes and scripts passed, or falls back
+func handleHealthGateResult(ctxPtr *nodeagentContext, result healthGateResult) {
+	ctxPtr.healthGatesInprogress = false
+	if !ctxPtr.testInprogress || ctxPtr.upgradeTestStartTime != result.testStartTime {
+		log.Noticef("Ignoring health gates result of restarted validation test: %v",
+			result.err)
+		return
+	}
+	if result.err != nil {
+		handleHealthGateFailure(ctxPtr, result.err)
+		return
+	}
+	log.Noticef("CurPart: %s, health gates passed", ctxPtr.curPart)
+	completeUpgradeTestValidation(ctxPtr)
+}
+
+// handleHealthGateFailure saves the failed gate and reboots to fall back
+func handleHealthGateFailure(ctxPtr *nodeagentContext, gateErr error) {
+	gate := gateErr.Error()
+	err := fileutils.WriteRename(healthGateFailureFile, []byte(gate))
+	if err != nil {
+		log.Errorf("handleHealthGateFailure write: %s", err)
+	}
+	errStr := fmt.Sprintf("Health gate failed: %s; rebooting to fall back", gate)
+	log.Errorf(errStr)
+	scheduleNodeOperation(ctxPtr, errStr, types.BootReasonFallback,
+		types.DeviceOperationReboot)
+}
+
+// handleLastHealthGateFailure picks up the gate which failed before the
+// last reboot, to be reported as part of baseos status
+func handleLastHealthGateFailure(ctxPtr *nodeagentContext) {
+	if !fileExists(healthGateFailureFile) {
+		return
+	}
+	b, err := fileutils.ReadWithMaxSize(log, healthGateFailureFile,
+		maxReadSize)
+	if err != nil {
+		log.Errorf("handleLastHealthGateFailure: %s", err)
+	} else {
+		log.Warnf("Found failed health gate: %s", string(b))
+		ctxPtr.lastLock.Lock()
+		ctxPtr.failedHealthGate = string(b)
+		ctxPtr.lastLock.Unlock()
+	}
+	os.Remove(healthGateFailureFile)
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package nodeagent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+func TestRunHTTPProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/missing" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+	defer server.Close()
+
+	testMatrix := map[string]struct {
+		probe   types.BaseOsHTTPProbe
+		localIP string
+		fail    bool
+	}{
+		"any 2xx": {
+			probe: types.BaseOsHTTPProbe{URL: server.URL + "/health"},
+		},
+		"from local address": {
+			probe:   types.BaseOsHTTPProbe{URL: server.URL + "/health"},
+			localIP: "127.0.0.1",
+		},
+		"expected status": {
+			probe: types.BaseOsHTTPProbe{URL: server.URL + "/missing",
+				ExpectedStatus: http.StatusNotFound},
+		},
+		"unexpected status": {
+			probe: types.BaseOsHTTPProbe{URL: server.URL + "/health",
+				ExpectedStatus: http.StatusOK},
+			fail: true,
+		},
+		"not 2xx": {
+			probe: types.BaseOsHTTPProbe{URL: server.URL + "/missing"},
+			fail:  true,
+		},
+		"bad local address": {
+			probe:   types.BaseOsHTTPProbe{URL: server.URL + "/health"},
+			localIP: "bad",
+			fail:    true,
+		},
+	}
+	for testname, test := range testMatrix {
+		t.Logf("Running test case %s", testname)
+		err := runHTTPProbe(test.probe, test.localIP)
+		if test.fail && err == nil {
+			t.Errorf("%s: expected failure", testname)
+		}
+		if !test.fail && err != nil {
+			t.Errorf("%s: unexpected failure: %v", testname, err)
+		}
+	}
+}
+
+func TestScriptTimeout(t *testing.T) {
+	testMatrix := map[string]struct {
+		configured time.Duration
+		testTime   time.Duration
+		want       time.Duration
+	}{
+		"default": {
+			testTime: 10 * time.Minute,
+			want:     defaultScriptTimeout,
+		},
+		"configured": {
+			configured: 2 * time.Minute,
+			testTime:   10 * time.Minute,
+			want:       2 * time.Minute,
+		},
+		"bounded by test time": {
+			configured: 20 * time.Minute,
+			testTime:   10 * time.Minute,
+			want:       10 * time.Minute,
+		},
+		"default bounded by test time": {
+			testTime: 10 * time.Second,
+			want:     10 * time.Second,
+		},
+	}
+	for testname, test := range testMatrix {
+		t.Logf("Running test case %s", testname)
+		if got := scriptTimeout(test.configured, test.testTime); got != test.want {
+			t.Errorf("%s: want %v, but got %v", testname, test.want, got)
+		}
+	}
+}
@@ -12,6 +12,9 @@
 //   * global config
 //   * zboot status                 <baseosmgr> / <zboot> / <status>
 //   * zedagent status              <zedagent>  / <status>
+//   * baseos config                <zedagent>  / <baseos> / <config>
+//   * domain status                <domainmgr> / <status>
+//   * network instance status      <zedrouter> / <network instance> / <status>
 
 package nodeagent
 
@@ -79,6 +82,8 @@ type nodeagentContext struct {
 	subZedAgentStatus           pubsub.Subscription
 	subDomainStatus             pubsub.Subscription
 	subVaultStatus              pubsub.Subscription
+	subBaseOsConfig             pubsub.Subscription
+	subNetworkInstanceStatus    pubsub.Subscription
 	pubZbootConfig              pubsub.Publication
 	pubNodeAgentStatus          pubsub.Publication
 	curPart                     string
@@ -116,6 +121,9 @@ type nodeagentContext struct {
 	maintModeReason             types.MaintenanceModeReason //reason for entering Maintenance mode
 	configGetSuccess            bool                        // got config from controller success
 	vaultmgrReported            bool                        // got reports from vaultmgr
+	healthGatesInprogress       bool                        // probes and scripts are running
+	healthGateResult            chan healthGateResult       // from probes and scripts
+	failedHealthGate            string                      // From last reboot
 
 	// Some contants.. Declared here as variables to enable unit tests
 	minRebootDelay          uint32
@@ -142,6 +150,7 @@ func newNodeagentContext(ps *pubsub.PubSub, logger *logrus.Logger, log *base.Log
 	duration = time.Duration(timeTickInterval) * time.Second
 	nodeagentCtx.tickerTimer = time.NewTicker(duration)
 	nodeagentCtx.configGetStatus = types.ConfigGetFail
+	nodeagentCtx.healthGateResult = make(chan healthGateResult, 1)
 
 	nodeagentCtx.agentBaseContext.PubSub = ps
 	nodeagentCtx.agentBaseContext.Logger = logger
@@ -220,6 +229,7 @@ func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) in
 	parseSMARTData()
 	// get the last reboot reason
 	handleLastRebootReason(ctxPtr)
+	handleLastHealthGateFailure(ctxPtr)
 	// send Installation log and remove first-boot from installation
 	handleInstallationLog(ctxPtr)
 
@@ -302,6 +312,34 @@ func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) in
 	ctxPtr.subDomainStatus = subDomainStatus
 	subDomainStatus.Activate()
 
+	// Get BaseOsConfig from zedagent for the health gates
+	subBaseOsConfig, err := ps.NewSubscription(pubsub.SubscriptionOptions{
+		AgentName:   "zedagent",
+		MyAgentName: agentName,
+		TopicImpl:   types.BaseOsConfig{},
+		Activate:    false,
+		Ctx:         ctxPtr,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctxPtr.subBaseOsConfig = subBaseOsConfig
+	subBaseOsConfig.Activate()
+
+	// Get NetworkInstanceStatus from zedrouter for the health gates
+	subNetworkInstanceStatus, err := ps.NewSubscription(pubsub.SubscriptionOptions{
+		AgentName:   "zedrouter",
+		MyAgentName: agentName,
+		TopicImpl:   types.NetworkInstanceStatus{},
+		Activate:    false,
+		Ctx:         ctxPtr,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctxPtr.subNetworkInstanceStatus = subNetworkInstanceStatus
+	subNetworkInstanceStatus.Activate()
+
 	// Wait until we have been onboarded aka know our own UUID however we do not use the UUID
 	if err := utils.WaitForOnboarded(ps, log, agentName, warningTime, errorTime); err != nil {
 		log.Fatal(err)
@@ -373,6 +411,15 @@ func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) in
 		case change := <-subDomainStatus.MsgChan():
 			subDomainStatus.ProcessChange(change)
 
+		case change := <-subBaseOsConfig.MsgChan():
+			subBaseOsConfig.ProcessChange(change)
+
+		case change := <-subNetworkInstanceStatus.MsgChan():
+			subNetworkInstanceStatus.ProcessChange(change)
+
+		case result := <-ctxPtr.healthGateResult:
+			handleHealthGateResult(ctxPtr, result)
+
 		case change := <-subZbootStatus.MsgChan():
 			subZbootStatus.ProcessChange(change)
 
@@ -708,6 +755,7 @@ func publishNodeAgentStatus(ctxPtr *nodeagentContext) {
 		RestartCounter:             ctxPtr.restartCounter,
 		LocalMaintenanceMode:       ctxPtr.maintMode,
 		LocalMaintenanceModeReason: ctxPtr.maintModeReason,
+		FailedHealthGate:           ctxPtr.failedHealthGate,
 	}
 	ctxPtr.lastLock.Unlock()
 	pub.Publish(agentName, status)
@@ -28,6 +28,7 @@ import (
 	"github.com/lf-edge/eve/pkg/pillar/agentlog"
 	"github.com/lf-edge/eve/pkg/pillar/base"
 	etpm "github.com/lf-edge/eve/pkg/pillar/evetpm"
+	"github.com/lf-edge/eve/pkg/pillar/evetpm/tpmsim"
 	"github.com/lf-edge/eve/pkg/pillar/pidfile"
 	"github.com/lf-edge/eve/pkg/pillar/pubsub"
 	"github.com/lf-edge/eve/pkg/pillar/types"
@@ -178,7 +179,7 @@ var toGoCurve = map[tpm2.EllipticCurve]elliptic.Curve{
 
 //Helps creating various keys, according to the supplied template, and hierarchy
 func createKey(keyHandle, ownerHandle tpmutil.Handle, template tpm2.Public, overwrite bool) error {
-	rw, err := tpm2.OpenTPM(etpm.TpmDevicePath)
+	rw, err := etpm.OpenTPM()
 	if err != nil {
 		log.Errorln(err)
 		return err
@@ -224,7 +225,7 @@ func createKey(keyHandle, ownerHandle tpmutil.Handle, template tpm2.Public, over
 }
 
 func createDeviceKey() (crypto.PublicKey, error) {
-	rw, err := tpm2.OpenTPM(etpm.TpmDevicePath)
+	rw, err := etpm.OpenTPM()
 	if err != nil {
 		log.Errorln(err)
 		return nil, err
@@ -265,7 +266,7 @@ func createDeviceKey() (crypto.PublicKey, error) {
 
 func writeDeviceCert() error {
 
-	rw, err := tpm2.OpenTPM(etpm.TpmDevicePath)
+	rw, err := etpm.OpenTPM()
 	if err != nil {
 		return err
 	}
@@ -309,7 +310,7 @@ func writeDeviceCert() error {
 
 func readDeviceCert() error {
 
-	rw, err := tpm2.OpenTPM(etpm.TpmDevicePath)
+	rw, err := etpm.OpenTPM()
 	if err != nil {
 		return err
 	}
@@ -359,7 +360,7 @@ func genCredentials() error {
 
 func writeCredentials() error {
 
-	rw, err := tpm2.OpenTPM(etpm.TpmDevicePath)
+	rw, err := etpm.OpenTPM()
 	if err != nil {
 		return err
 	}
@@ -402,7 +403,7 @@ func writeCredentials() error {
 
 func readCredentials() error {
 
-	rw, err := tpm2.OpenTPM(etpm.TpmDevicePath)
+	rw, err := etpm.OpenTPM()
 	if err != nil {
 		return err
 	}
@@ -439,8 +440,12 @@ func getQuote(nonce []byte) ([]byte, []byte, []types.PCRValue, error) {
 		//No TPM, not an error, return empty values
 		return nil, nil, nil, nil
 	}
+	return quotePCRs(nonce)
+}
 
-	rw, err := tpm2.OpenTPM(etpm.TpmDevicePath)
+//quotePCRs reads PCRs, and gets them quoted by the TPM with the given nonce
+func quotePCRs(nonce []byte) ([]byte, []byte, []types.PCRValue, error) {
+	rw, err := etpm.OpenTPM()
 	if err != nil {
 		log.Errorf("Unable to open TPM device handle (%v), returning empty quote/PCRs", err)
 		return nil, nil, nil, nil
@@ -505,7 +510,7 @@ func printPCRs() {
 }
 
 func testTpmEcdhSupport() error {
-	rw, err := tpm2.OpenTPM(etpm.TpmDevicePath)
+	rw, err := etpm.OpenTPM()
 	if err != nil {
 		log.Errorln(err)
 		return err
@@ -650,7 +655,7 @@ func createEkCertOnTpm() error {
 	//Check if we already have the certificate
 	if !etpm.FileExists(EkCertFile) {
 		//Cert is not present, generate new one
-		rw, err := tpm2.OpenTPM(etpm.TpmDevicePath)
+		rw, err := etpm.OpenTPM()
 		if err != nil {
 			return err
 		}
@@ -748,7 +753,7 @@ func createDeviceCertOnTpm(pubkey crypto.PublicKey) error {
 	}
 
 	//Cert is not present, generate new one
-	rw, err := tpm2.OpenTPM(etpm.TpmDevicePath)
+	rw, err := etpm.OpenTPM()
 	if err != nil {
 		return err
 	}
@@ -904,7 +909,7 @@ func createQuoteCertOnTpm() error {
 	//Check if we already have the certificate
 	if !etpm.FileExists(quoteCertFile) {
 		//Cert is not present, generate new one
-		rw, err := tpm2.OpenTPM(etpm.TpmDevicePath)
+		rw, err := etpm.OpenTPM()
 		if err != nil {
 			return err
 		}
@@ -1098,7 +1103,7 @@ func createEcdhCertOnTpm() error {
 	//Check if we already have the certificate
 	if !etpm.FileExists(ecdhCertFile) {
 		//Cert is not present, generate new one
-		rw, err := tpm2.OpenTPM(etpm.TpmDevicePath)
+		rw, err := etpm.OpenTPM()
 		if err != nil {
 			return err
 		}
@@ -1322,7 +1327,7 @@ func publishEdgeNodeCertToController(ctx *tpmMgrContext, certFile string, certTy
 }
 
 func getEkCertMetaData() ([]types.CertMetaData, error) {
-	rw, err := tpm2.OpenTPM(etpm.TpmDevicePath)
+	rw, err := etpm.OpenTPM()
 	if err != nil {
 		return nil, fmt.Errorf("Unable to open TPM device: %v", err)
 	}
@@ -1376,6 +1381,7 @@ func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) in
 	log = logArg
 	var err error
 	debugPtr := flag.Bool("d", false, "Debug flag")
+	simStatePtr := flag.String("s", "", "Use TPM simulator, with state kept in the given file")
 	flag.Parse()
 	debug = *debugPtr
 	debugOverride = debug
@@ -1388,6 +1394,14 @@ func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) in
 		log.Error("Insufficient arguments")
 		return 1
 	}
+	if *simStatePtr != "" {
+		sim, err := tpmsim.NewWithStateFile(*simStatePtr)
+		if err != nil {
+			log.Errorf("Failed to start TPM simulator: %v", err)
+			return 1
+		}
+		etpm.SetTpmTransport(sim.Open)
+	}
 
 	switch flag.Args()[0] {
 	case "createDeviceCert":
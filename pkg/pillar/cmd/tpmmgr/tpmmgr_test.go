@@ -6,15 +6,26 @@
 package tpmmgr
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/asn1"
 	"encoding/pem"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+	"github.com/lf-edge/eve/pkg/pillar/base"
 	etpm "github.com/lf-edge/eve/pkg/pillar/evetpm"
+	"github.com/lf-edge/eve/pkg/pillar/evetpm/tpmsim"
+	"github.com/sirupsen/logrus"
 )
 
 const ecdhCertPem = `
@@ -201,3 +212,180 @@ func TestVerifyEdgeNodeCerts(t *testing.T) {
 		return
 	}
 }
+
+//setupSimulator plugs a fresh TPM simulator in as the TPM transport,
+//and points TPM credentials to a temporary file
+func setupSimulator(t *testing.T) *tpmsim.Simulator {
+	t.Helper()
+	logger = logrus.StandardLogger()
+	log = base.NewSourceLogObject(logger, agentName, 0)
+
+	sim, err := tpmsim.New()
+	if err != nil {
+		t.Fatalf("Failed to create TPM simulator: %v", err)
+	}
+	etpm.SetTpmTransport(sim.Open)
+	t.Cleanup(func() { etpm.SetTpmTransport(nil) })
+
+	dir, err := ioutil.TempDir("", "tpmmgr_test")
+	if err != nil {
+		t.Fatalf("TempDir failed: %v", err)
+	}
+	oldCredentialsFile := etpm.TpmCredentialsFileName
+	etpm.TpmCredentialsFileName = filepath.Join(dir, "tpm_credential")
+	t.Cleanup(func() {
+		etpm.TpmCredentialsFileName = oldCredentialsFile
+		os.RemoveAll(dir)
+	})
+	if err := genCredentials(); err != nil {
+		t.Fatalf("genCredentials failed: %v", err)
+	}
+	return sim
+}
+
+func readPublicKey(t *testing.T, handle tpmutil.Handle) tpm2.Public {
+	t.Helper()
+	rw, err := etpm.OpenTPM()
+	if err != nil {
+		t.Fatalf("OpenTPM failed: %v", err)
+	}
+	defer rw.Close()
+	pub, _, _, err := tpm2.ReadPublic(rw, handle)
+	if err != nil {
+		t.Fatalf("ReadPublic 0x%X failed: %v", handle, err)
+	}
+	return pub
+}
+
+func TestCreateKeysWithSimulator(t *testing.T) {
+	setupSimulator(t)
+
+	err := createOtherKeys(true)
+	if err != nil {
+		t.Fatalf("createOtherKeys failed: %v", err)
+	}
+	handles := []tpmutil.Handle{etpm.TpmEKHdl, etpm.TpmSRKHdl,
+		etpm.TpmAKHdl, etpm.TpmQuoteKeyHdl, etpm.TpmEcdhKeyHdl}
+	templates := []tpm2.Public{defaultEkTemplate, defaultSrkTemplate,
+		defaultAkTemplate, defaultQuoteKeyTemplate, defaultEcdhKeyTemplate}
+	keys := make([][]byte, len(handles))
+	for i, handle := range handles {
+		pub := readPublicKey(t, handle)
+		if keys[i], err = pub.Encode(); err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+		if pub.Attributes != templates[i].Attributes {
+			t.Errorf("Key 0x%X: want attributes %v, but got %v",
+				handle, templates[i].Attributes, pub.Attributes)
+		}
+	}
+
+	//existing keys are kept, unless asked to override them
+	if err := createOtherKeys(false); err != nil {
+		t.Fatalf("createOtherKeys failed: %v", err)
+	}
+	for i, handle := range handles {
+		pub, err := readPublicKey(t, handle).Encode()
+		if err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+		if !bytes.Equal(pub, keys[i]) {
+			t.Errorf("Key 0x%X was re-created", handle)
+		}
+	}
+}
+
+func TestDeviceKeyWithSimulator(t *testing.T) {
+	setupSimulator(t)
+
+	pubKey, err := createDeviceKey()
+	if err != nil {
+		t.Fatalf("createDeviceKey failed: %v", err)
+	}
+	ecdsaPub, ok := pubKey.(*ecdsa.PublicKey)
+	if !ok {
+		t.Fatalf("want ECDSA device key, but got %T", pubKey)
+	}
+	digest := sha256.Sum256([]byte("data to sign"))
+	r, s, err := etpm.TpmSign(digest[:])
+	if err != nil {
+		t.Fatalf("TpmSign failed: %v", err)
+	}
+	if !ecdsa.Verify(ecdsaPub, digest[:], r, s) {
+		t.Errorf("Device key signature verification failed")
+	}
+	if err := testTpmEcdhSupport(); err != nil {
+		t.Errorf("testTpmEcdhSupport failed: %v", err)
+	}
+}
+
+func TestQuoteWithSimulator(t *testing.T) {
+	sim := setupSimulator(t)
+	if err := createOtherKeys(true); err != nil {
+		t.Fatalf("createOtherKeys failed: %v", err)
+	}
+	for i, event := range []string{"firmware", "bootloader", "kernel"} {
+		if err := sim.ExtendPCR(i, []byte(event)); err != nil {
+			t.Fatalf("ExtendPCR failed: %v", err)
+		}
+	}
+
+	nonce := []byte("ThisIsRandomNonce")
+	attestData, signature, pcrs, err := quotePCRs(nonce)
+	if err != nil || attestData == nil {
+		t.Fatalf("quotePCRs failed: %v", err)
+	}
+
+	//verify the quote the way the controller does
+	attest, err := tpm2.DecodeAttestationData(attestData)
+	if err != nil {
+		t.Fatalf("DecodeAttestationData failed: %v", err)
+	}
+	if attest.Type != tpm2.TagAttestQuote || attest.AttestedQuoteInfo == nil {
+		t.Fatalf("Not a quote: %v", attest.Type)
+	}
+	if !bytes.Equal(attest.ExtraData, nonce) {
+		t.Errorf("want nonce %x, but got %x", nonce, attest.ExtraData)
+	}
+	pcrDigest := sha256.New()
+	for _, pcr := range pcrs {
+		want, err := sim.PCRValue(tpm2.AlgSHA256, int(pcr.Index))
+		if err != nil {
+			t.Fatalf("PCRValue failed: %v", err)
+		}
+		if !bytes.Equal(pcr.Digest, want) {
+			t.Errorf("PCR %d: want %x, but got %x", pcr.Index, want, pcr.Digest)
+		}
+		if int(pcr.Index) < len(pcrListForQuote.PCRs) {
+			pcrDigest.Write(pcr.Digest)
+		}
+	}
+	if !bytes.Equal(attest.AttestedQuoteInfo.PCRDigest, pcrDigest.Sum(nil)) {
+		t.Errorf("PCR digest in the quote does not match PCR values")
+	}
+
+	var sig struct {
+		R, S *big.Int
+	}
+	if _, err := asn1.Unmarshal(signature, &sig); err != nil {
+		t.Fatalf("Failed to parse quote signature: %v", err)
+	}
+	quoteKey, err := readPublicKey(t, etpm.TpmQuoteKeyHdl).Key()
+	if err != nil {
+		t.Fatalf("Failed to get quote key: %v", err)
+	}
+	digest := sha256.Sum256(attestData)
+	if !ecdsa.Verify(quoteKey.(*ecdsa.PublicKey), digest[:], sig.R, sig.S) {
+		t.Errorf("Quote signature verification failed")
+	}
+
+	//a quote with another nonce must not verify with this one
+	attestData2, _, _, err := quotePCRs([]byte("AnotherNonce"))
+	if err != nil {
+		t.Fatalf("quotePCRs failed: %v", err)
+	}
+	digest = sha256.Sum256(attestData2)
+	if ecdsa.Verify(quoteKey.(*ecdsa.PublicKey), digest[:], sig.R, sig.S) {
+		t.Errorf("Quote signature verified for a different nonce")
+	}
+}
@@ -56,8 +56,12 @@ type vaultMgrContext struct {
 	pubVaultConfig            pubsub.Publication
 	subGlobalConfig           pubsub.Subscription
 	subVaultKeyFromController pubsub.Subscription
+	subVaultKeyEscrowStatus   pubsub.Subscription
 	GCInitialized             bool // GlobalConfig initialized
 	defaultVaultUnlocked      bool
+	pcrEventDiffs             []types.PCREventDiff
+	keyRotationInterval       time.Duration
+	escrowedKeyDigest         []byte // of the key Controller acknowledged
 	vaultUCDone               bool
 	ps                        *pubsub.PubSub
 	ucChan                    chan struct{}
@@ -101,9 +105,13 @@ func getEncryptParams(vaultPath string) []string {
 	return args
 }
 
-func getUnlockParams(vaultPath string) []string {
+//protectorID selects the protector to unlock with, if set
+func getUnlockParams(vaultPath string, protectorID string) []string {
 	args := []string{"unlock", vaultPath, "--key=" + keyFile,
 		"--user=root"}
+	if protectorID != "" {
+		args = append(args, "--unlock-with="+vault.MountPoint+":"+protectorID)
+	}
 	return args
 }
 
@@ -119,6 +127,30 @@ func getChangeProtectorParams(protectorID string) []string {
 	return args
 }
 
+//the protector is created with the key in oldKeyFile, see addFscryptVaultKey
+func getCreateProtectorParams(vaultPath string) []string {
+	args := []string{"metadata", "create", "protector", vault.MountPoint,
+		"--source=raw_key", "--name=" + protectorPrefix + filepath.Base(vaultPath),
+		"--key=" + oldKeyFile}
+	return args
+}
+
+func getAddProtectorParams(protectorID, policyID, unlockWithID string) []string {
+	args := []string{"metadata", "add-protector-to-policy",
+		"--protector=" + vault.MountPoint + ":" + protectorID,
+		"--policy=" + vault.MountPoint + ":" + policyID,
+		"--unlock-with=" + vault.MountPoint + ":" + unlockWithID,
+		"--key=" + oldKeyFile, "--quiet"}
+	return args
+}
+
+func getRemoveProtectorFromPolicyParams(protectorID, policyID string) []string {
+	args := []string{"metadata", "remove-protector-from-policy",
+		"--protector=" + vault.MountPoint + ":" + protectorID,
+		"--policy=" + vault.MountPoint + ":" + policyID, "--quiet"}
+	return args
+}
+
 func getRemoveProtectorParams(protectorID string) []string {
 	args := []string{"metadata", "destroy", "--protector=" + vault.MountPoint + ":" + protectorID, "--quiet", "--force"}
 	return args
@@ -186,6 +218,33 @@ func getProtectorID(vaultPath string) ([][]string, error) {
 	return protector.FindAllStringSubmatch(stdOut, -1), nil
 }
 
+var createdProtectorRegexp = regexp.MustCompile(`Protector ([[:xdigit:]]+) created`)
+
+func getPolicyID(vaultPath string) (string, error) {
+	args := getStatusParams(vaultPath)
+	stdOut, _, err := execCmd(vault.FscryptPath, args...)
+	if err != nil {
+		return "", err
+	}
+	policy := regexp.MustCompile(`Policy:\s+([[:xdigit:]]+)`)
+	match := policy.FindStringSubmatch(stdOut)
+	if match == nil {
+		return "", fmt.Errorf("no policy found for %s", vaultPath)
+	}
+	return match[1], nil
+}
+
+//fscryptProtectorExists checks if the protector is present on the mountpoint
+//e.g. after removing it from the policy of the vault
+func fscryptProtectorExists(protectorID string) bool {
+	stdOut, _, err := execCmd(vault.FscryptPath, vault.StatusParams...)
+	if err != nil {
+		return false
+	}
+	protector := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(protectorID) + `\s`)
+	return protector.MatchString(stdOut)
+}
+
 //changeProtector is used on deprecated vaults. It is used for migrating them
 //to TPM based keys, from cloudOnlyKey (which was a bug introduced in the late
 //2019). We still need to keep cloudKeyOnly for some more time, till we migrate
@@ -311,7 +370,12 @@ func deriveVaultKey(cloudKeyOnlyMode, useSealedKey bool) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	return vaultKeyFromTpmKey(tpmKey, cloudKey)
+}
 
+//vaultKeyFromTpmKey combines TPM key with cloud key, unless TPM key only
+//mode is in use
+func vaultKeyFromTpmKey(tpmKey, cloudKey []byte) ([]byte, error) {
 	tpmKeyOnlyMode := vaultConfig.TpmKeyOnly
 	if tpmKeyOnlyMode == false {
 		log.Notice("Calling mergeKeys")
@@ -324,6 +388,16 @@ func deriveVaultKey(cloudKeyOnlyMode, useSealedKey bool) ([]byte, error) {
 //stageKey is responsible for talking to TPM and Controller
 //and preparing the key for accessing the vault
 func stageKey(cloudKeyOnlyMode, useSealedKey bool, keyDirName string, keyFileName string) error {
+	vaultKey, err := deriveVaultKey(cloudKeyOnlyMode, useSealedKey)
+	if err != nil {
+		log.Errorf("Error deriving key for accessing the vault: %v", err)
+		return err
+	}
+	return stageVaultKey(vaultKey, keyDirName, keyFileName)
+}
+
+//stageVaultKey writes vaultKey into a tmpfs file, to pass it to fscrypt or zfs
+func stageVaultKey(vaultKey []byte, keyDirName string, keyFileName string) error {
 	//Create a tmpfs file to pass the secret to fscrypt
 	if _, _, err := execCmd("mkdir", keyDirName); err != nil {
 		return fmt.Errorf("Error creating keyDir %s %v", keyDirName, err)
@@ -333,12 +407,6 @@ func stageKey(cloudKeyOnlyMode, useSealedKey bool, keyDirName string, keyFileNam
 		return fmt.Errorf("Error mounting tmpfs on keyDir %s: %v", keyDirName, err)
 	}
 
-	vaultKey, err := deriveVaultKey(cloudKeyOnlyMode, useSealedKey)
-	if err != nil {
-		log.Errorf("Error deriving key for accessing the vault: %v", err)
-		unstageKey(keyDirName, keyFileName)
-		return err
-	}
 	if err := ioutil.WriteFile(keyFileName, vaultKey, 0700); err != nil {
 		return fmt.Errorf("Error creating keyFile: %v", err)
 	}
@@ -400,13 +468,29 @@ func unlockVault(vaultPath string, cloudKeyOnlyMode, useSealedKey bool) error {
 	defer unstageKey(keyDir, keyFile)
 
 	//Unlock vault for access
-	if _, _, err := execCmd(vault.FscryptPath, getUnlockParams(vaultPath)...); err != nil {
+	if _, _, err := execCmd(vault.FscryptPath, getUnlockParams(vaultPath, "")...); err != nil {
 		log.Errorf("Error unlocking vault: %v", err)
 		return err
 	}
 	return linkKeyrings()
 }
 
+//unlockVaultWithKey unlocks the vault with vaultKey, using the protector
+//if set, e.g. during key rotation when the vault has two protectors
+func unlockVaultWithKey(vaultPath string, vaultKey []byte, protectorID string) error {
+	if err := stageVaultKey(vaultKey, keyDir, keyFile); err != nil {
+		return err
+	}
+	defer unstageKey(keyDir, keyFile)
+
+	if _, _, err := execCmd(vault.FscryptPath,
+		getUnlockParams(vaultPath, protectorID)...); err != nil {
+		log.Errorf("Error unlocking vault with protector %s: %v", protectorID, err)
+		return err
+	}
+	return linkKeyrings()
+}
+
 //createVault expects an empty, existing dir at vaultPath
 func createVault(vaultPath string) error {
 	if !etpm.IsTpmEnabled() || !etpm.PCRBankSHA256Enabled() {
@@ -520,6 +604,7 @@ func publishFscryptVaultStatus(ctx *vaultMgrContext,
 	status := types.VaultStatus{}
 	status.Name = vaultName
 	status.ConversionComplete = ctx.vaultUCDone
+	status.PCREventDiffs = ctx.pcrEventDiffs
 
 	if etpm.PCRBankSHA256Enabled() {
 		status.PCRStatus = info.PCRStatus_PCR_ENABLED
@@ -583,8 +668,7 @@ func fetchFscryptStatus() (info.DataSecAtRestStatus, string) {
 			return info.DataSecAtRestStatus_DATASEC_AT_REST_ENABLED, ""
 		}
 	} else {
-		_, err := os.Stat(etpm.TpmDevicePath)
-		if err != nil {
+		if !etpm.IsTpmPresent() {
 			//This is due to lack of TPM
 			log.Trace("Setting status to disabled, TPM is not in use")
 			return info.DataSecAtRestStatus_DATASEC_AT_REST_DISABLED,
@@ -737,16 +821,18 @@ func setupDefaultVault(ctx *vaultMgrContext) error {
 
 	switch persistFsType {
 	case types.PersistExt4:
-		if err := setupDefaultVaultOnExt4(); err != nil {
+		if err := recoverKeyRotation(setupDefaultVaultOnExt4); err != nil {
 			return err
 		}
+		saveMeasurementLog(ctx)
 		//Log the type of key used for unlocking default vault
 		log.Noticef("%s unlocked using key type %s", defaultVault,
 			etpm.CompareLegacyandSealedKey().String())
 	case types.PersistZFS:
-		if err := setupDefaultVaultOnZfs(); err != nil {
+		if err := recoverKeyRotation(setupDefaultVaultOnZfs); err != nil {
 			return err
 		}
+		saveMeasurementLog(ctx)
 		//Log the type of key used for unlocking default vault
 		log.Noticef("%s unlocked using key type %s", defaultVault,
 			etpm.CompareLegacyandSealedKey().String())
@@ -787,6 +873,7 @@ func publishZfsVaultStatus(ctx *vaultMgrContext, vaultName, vaultPath string) {
 	status := types.VaultStatus{}
 	status.Name = vaultName
 	status.ConversionComplete = ctx.vaultUCDone
+	status.PCREventDiffs = ctx.pcrEventDiffs
 	zfsEncryptStatus, zfsEncryptError := vault.GetOperInfo(log)
 	if zfsEncryptStatus != info.DataSecAtRestStatus_DATASEC_AT_REST_ENABLED {
 		status.Status = zfsEncryptStatus
@@ -811,6 +898,38 @@ func publishZfsVaultStatus(ctx *vaultMgrContext, vaultName, vaultPath string) {
 	pub.Publish(key, status)
 }
 
+//saveMeasurementLog keeps the measurement log of this boot as the
+//last known good one, now that the vault key is unsealed
+func saveMeasurementLog(ctx *vaultMgrContext) {
+	ctx.pcrEventDiffs = nil
+	if err := etpm.SaveMeasurementLog(); err != nil {
+		log.Warnf("Failed to save measurement log: %v", err)
+	}
+}
+
+//diffMeasurementLogs finds measurements which changed since the vault key
+//was unsealed last time, to report why unsealing fails now
+func diffMeasurementLogs(ctx *vaultMgrContext) {
+	if !etpm.IsTpmEnabled() {
+		return
+	}
+	diffs, err := etpm.DiffMeasurementLogs(etpm.DiskKeySealingPCRs.PCRs)
+	if err != nil {
+		log.Warnf("Failed to compare measurement logs: %v", err)
+		return
+	}
+	for _, diff := range diffs {
+		log.Noticef("PCR %d changed since the last unseal", diff.Index)
+		for _, event := range diff.Removed {
+			log.Noticef("PCR %d: -%d %s", diff.Index, event.Sequence, event.Description)
+		}
+		for _, event := range diff.Added {
+			log.Noticef("PCR %d: +%d %s", diff.Index, event.Sequence, event.Description)
+		}
+	}
+	ctx.pcrEventDiffs = diffs
+}
+
 func publishVaultStatus(ctx *vaultMgrContext) {
 	persistFsType := vault.ReadPersistType()
 	switch persistFsType {
@@ -911,6 +1030,25 @@ func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) in
 		ctx.subVaultKeyFromController = subVaultKeyFromController
 		subVaultKeyFromController.Activate()
 
+		// Look for acknowledgement of vault key escrow from Controller
+		subVaultKeyEscrowStatus, err := ps.NewSubscription(pubsub.SubscriptionOptions{
+			AgentName:     "zedagent",
+			MyAgentName:   agentName,
+			TopicImpl:     types.VaultKeyEscrowStatus{},
+			Activate:      false,
+			Ctx:           &ctx,
+			CreateHandler: handleVaultKeyEscrowStatusCreate,
+			ModifyHandler: handleVaultKeyEscrowStatusModify,
+			WarningTime:   warningTime,
+			ErrorTime:     errorTime,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		ctx.subVaultKeyEscrowStatus = subVaultKeyEscrowStatus
+		subVaultKeyEscrowStatus.Activate()
+
 		// Pick up debug aka log level before we start real work
 		for !ctx.GCInitialized {
 			log.Functionf("waiting for GCInitialized")
@@ -927,6 +1065,7 @@ func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) in
 		initializeSelfPublishHandles(ps, &ctx)
 		if err := setupDefaultVault(&ctx); err != nil {
 			log.Errorf("setupDefaultVault failed, err: %v", err)
+			diffMeasurementLogs(&ctx)
 			publishVaultStatus(&ctx)
 		}
 		if ctx.defaultVaultUnlocked || !etpm.IsTpmEnabled() {
@@ -943,10 +1082,15 @@ func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) in
 			log.Errorf("Failed to publish Vault Key, %v", err)
 		}
 
+		keyRotationTicker := time.NewTicker(keyRotationCheckInterval)
 		for {
 			select {
 			case change := <-subVaultKeyFromController.MsgChan():
 				subVaultKeyFromController.ProcessChange(change)
+			case change := <-subVaultKeyEscrowStatus.MsgChan():
+				subVaultKeyEscrowStatus.ProcessChange(change)
+			case <-keyRotationTicker.C:
+				maybeRotateVaultKey(&ctx)
 			case <-stillRunning.C:
 				ps.StillRunning(agentName, warningTime, errorTime)
 			case <-ctx.ucChan:
@@ -987,6 +1131,8 @@ func handleGlobalConfigImpl(ctxArg interface{}, key string,
 		debugOverride, logger)
 	if gcp != nil {
 		ctx.GCInitialized = true
+		ctx.keyRotationInterval = time.Duration(
+			gcp.GlobalValueInt(types.VaultKeyRotationInterval)) * time.Second
 	}
 	log.Functionf("handleGlobalConfigImpl done for %s\n", key)
 }
@@ -1064,7 +1210,9 @@ func handleVaultKeyFromControllerImpl(ctxArg interface{}, key string,
 		log.Noticef("Sealed key in TPM, unlocking %s", types.DefaultVaultName)
 
 		if vault.ReadPersistType() == types.PersistZFS {
-			err = unlockZfsVault(defaultSecretDataset)
+			err = recoverKeyRotation(func() error {
+				return unlockZfsVault(defaultSecretDataset)
+			})
 			if err != nil {
 				log.Errorf("Failed to unlock zfs vault after receiving Controller key, %v",
 					err)
@@ -1072,7 +1220,9 @@ func handleVaultKeyFromControllerImpl(ctxArg interface{}, key string,
 			}
 		} else {
 			//cloudKeyOnlyMode=false, useSealedKey=true
-			err = unlockVault(defaultVault, false, true)
+			err = recoverKeyRotation(func() error {
+				return unlockVault(defaultVault, false, true)
+			})
 			if err != nil {
 				log.Errorf("Failed to unlock vault after receiving Controller key, %v",
 					err)
@@ -1086,6 +1236,7 @@ func handleVaultKeyFromControllerImpl(ctxArg interface{}, key string,
 
 		//Mark the default vault as unlocked
 		ctx.defaultVaultUnlocked = true
+		saveMeasurementLog(ctx)
 
 		//publish vault key to Controller
 		if err := publishVaultKey(ctx, types.DefaultVaultName); err != nil {
@@ -1101,6 +1252,31 @@ func handleVaultKeyFromControllerImpl(ctxArg interface{}, key string,
 	}
 }
 
+func handleVaultKeyEscrowStatusCreate(ctxArg interface{}, key string,
+	statusArg interface{}) {
+	handleVaultKeyEscrowStatusImpl(ctxArg, key, statusArg)
+}
+
+func handleVaultKeyEscrowStatusModify(ctxArg interface{}, key string,
+	statusArg interface{}, oldStatusArg interface{}) {
+	handleVaultKeyEscrowStatusImpl(ctxArg, key, statusArg)
+}
+
+func handleVaultKeyEscrowStatusImpl(ctxArg interface{}, key string,
+	statusArg interface{}) {
+
+	ctx := ctxArg.(*vaultMgrContext)
+	status := statusArg.(types.VaultKeyEscrowStatus)
+	if status.Name != types.DefaultVaultName {
+		log.Warnf("Ignoring unknown vault %s", status.Name)
+		return
+	}
+	log.Functionf("Controller acknowledged vault key %x", status.DigestSha256)
+	ctx.escrowedKeyDigest = status.DigestSha256
+	//a key rotation may be waiting for this
+	maybeRotateVaultKey(ctx)
+}
+
 func publishVaultKey(ctx *vaultMgrContext, vaultName string) error {
 	var encryptedVaultKey []byte
 	//we try to fill EncryptedVaultKey only in case of tpm enabled
@@ -0,0 +1,380 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package vaultmgr
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	etpm "github.com/lf-edge/eve/pkg/pillar/evetpm"
+	"github.com/lf-edge/eve/pkg/pillar/evetpm/tpmsim"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	"github.com/sirupsen/logrus"
+)
+
+func TestMergeKeys(t *testing.T) {
+	logger = logrus.StandardLogger()
+	log = base.NewSourceLogObject(logger, agentName, 0)
+
+	key1 := bytes.Repeat([]byte{1}, vaultKeyLen)
+	key2 := bytes.Repeat([]byte{2}, vaultKeyLen)
+	merged, err := mergeKeys(key1, key2)
+	if err != nil {
+		t.Fatalf("mergeKeys failed: %v", err)
+	}
+	want := append(key1[:vaultHalfKeyLen:vaultHalfKeyLen], key2[vaultHalfKeyLen:]...)
+	if !bytes.Equal(merged, want) {
+		t.Errorf("want %x, but got %x", want, merged)
+	}
+	if _, err := mergeKeys(key1[1:], key2); err != ErrInvalKeyLen {
+		t.Errorf("want %v, but got %v", ErrInvalKeyLen, err)
+	}
+}
+
+func TestDeriveVaultKeyWithSimulator(t *testing.T) {
+	logger = logrus.StandardLogger()
+	log = base.NewSourceLogObject(logger, agentName, 0)
+	sim, err := tpmsim.New()
+	if err != nil {
+		t.Fatalf("Failed to create TPM simulator: %v", err)
+	}
+	etpm.SetTpmTransport(sim.Open)
+	defer etpm.SetTpmTransport(nil)
+	defer func() { vaultConfig = types.VaultConfig{} }()
+
+	cloudKey, err := retrieveCloudKey()
+	if err != nil {
+		t.Fatalf("retrieveCloudKey failed: %v", err)
+	}
+	key, err := deriveVaultKey(true, false)
+	if err != nil {
+		t.Fatalf("deriveVaultKey failed: %v", err)
+	}
+	if !bytes.Equal(key, cloudKey) {
+		t.Errorf("want cloud key %x, but got %x", cloudKey, key)
+	}
+
+	//the TPM part of the key is generated once, and kept in TPM NV
+	vaultConfig = types.VaultConfig{TpmKeyOnly: true}
+	tpmKey, err := deriveVaultKey(false, false)
+	if err != nil {
+		t.Fatalf("deriveVaultKey failed: %v", err)
+	}
+	if len(tpmKey) != vaultKeyLen {
+		t.Fatalf("want %d bytes long key, but got %d", vaultKeyLen, len(tpmKey))
+	}
+	tpmKey2, err := deriveVaultKey(false, false)
+	if err != nil {
+		t.Fatalf("deriveVaultKey failed: %v", err)
+	}
+	if !bytes.Equal(tpmKey, tpmKey2) {
+		t.Errorf("TPM key changed from %x to %x", tpmKey, tpmKey2)
+	}
+
+	vaultConfig = types.VaultConfig{TpmKeyOnly: false}
+	mergedKey, err := deriveVaultKey(false, false)
+	if err != nil {
+		t.Fatalf("deriveVaultKey failed: %v", err)
+	}
+	want, err := mergeKeys(tpmKey, cloudKey)
+	if err != nil {
+		t.Fatalf("mergeKeys failed: %v", err)
+	}
+	if !bytes.Equal(mergedKey, want) {
+		t.Errorf("want merged key %x, but got %x", want, mergedKey)
+	}
+
+	//without SHA256 PCR bank in use, sealed key falls back to the same key
+	sealedKey, err := deriveVaultKey(false, true)
+	if err != nil {
+		t.Fatalf("deriveVaultKey failed: %v", err)
+	}
+	if !bytes.Equal(sealedKey, mergedKey) {
+		t.Errorf("want %x, but got %x", mergedKey, sealedKey)
+	}
+}
+
+func TestKeyRotationState(t *testing.T) {
+	defer func(stateFile string) { vaultKeyRotationFile = stateFile }(vaultKeyRotationFile)
+	vaultKeyRotationFile = filepath.Join(t.TempDir(), "vault-key-rotation.json")
+
+	//no rotation happened yet
+	state, err := loadKeyRotationState()
+	if err != nil {
+		t.Fatalf("loadKeyRotationState failed: %v", err)
+	}
+	if state.Phase != keyRotationIdle || !state.LastRotation.IsZero() {
+		t.Errorf("Unexpected initial state %+v", state)
+	}
+
+	saved := keyRotationState{
+		Phase:        keyRotationKeyAdded,
+		OldKey:       bytes.Repeat([]byte{1}, vaultKeyLen),
+		NewKey:       bytes.Repeat([]byte{2}, vaultKeyLen),
+		OldProtector: "1d3f2a1b1a2b3c4d",
+		NewProtector: "5e6f7a8b9c0d1e2f",
+		LastRotation: time.Now().Round(0),
+	}
+	if err := saveKeyRotationState(saved); err != nil {
+		t.Fatalf("saveKeyRotationState failed: %v", err)
+	}
+	state, err = loadKeyRotationState()
+	if err != nil {
+		t.Fatalf("loadKeyRotationState failed: %v", err)
+	}
+	if state.Phase != saved.Phase || !bytes.Equal(state.OldKey, saved.OldKey) ||
+		!bytes.Equal(state.NewKey, saved.NewKey) || !state.LastRotation.Equal(saved.LastRotation) ||
+		state.OldProtector != saved.OldProtector || state.NewProtector != saved.NewProtector {
+		t.Errorf("want %+v, but got %+v", saved, state)
+	}
+}
+
+func TestKeyRotationDue(t *testing.T) {
+	now := time.Now()
+	lastRotation := now.Add(-2 * time.Hour)
+	testMatrix := map[string]struct {
+		state    keyRotationState
+		interval time.Duration
+		due      bool
+	}{
+		"disabled": {
+			state:    keyRotationState{LastRotation: lastRotation},
+			interval: 0,
+			due:      false,
+		},
+		"never rotated": {
+			state:    keyRotationState{},
+			interval: time.Hour,
+			due:      false,
+		},
+		"interval passed": {
+			state:    keyRotationState{LastRotation: lastRotation},
+			interval: time.Hour,
+			due:      true,
+		},
+		"interval not passed": {
+			state:    keyRotationState{LastRotation: lastRotation},
+			interval: 3 * time.Hour,
+			due:      false,
+		},
+		"interrupted rotation": {
+			state:    keyRotationState{Phase: keyRotationResealed, LastRotation: lastRotation},
+			interval: 0,
+			due:      true,
+		},
+	}
+	for testname, test := range testMatrix {
+		t.Logf("Running test case %s", testname)
+		if due := keyRotationDue(test.state, test.interval, now); due != test.due {
+			t.Errorf("%s: want %t, but got %t", testname, test.due, due)
+		}
+	}
+}
+
+//fakeKeyRotation models the vault, TPM and Controller during key rotation
+type fakeKeyRotation struct {
+	t         *testing.T
+	vaultKeys map[string]bool //keys the vault accepts
+	tpmKey    []byte
+	//key sent to Controller, and the one it acknowledged storing
+	sentKey, escrowedKey []byte
+	acknowledge          bool
+	failStep             string
+	steps                []string
+}
+
+//step records the step together with the phase saved before it,
+//and checks that the vault accepts the keys held by TPM and Controller
+func (f *fakeKeyRotation) step(name string) error {
+	state, err := loadKeyRotationState()
+	if err != nil {
+		f.t.Fatalf("loadKeyRotationState failed: %v", err)
+	}
+	if !f.vaultKeys[string(f.tpmKey)] || !f.vaultKeys[string(f.escrowedKey)] {
+		f.t.Errorf("%s: vault does not accept the key in TPM or with Controller", name)
+	}
+	if name == f.failStep {
+		return errors.New("injected failure")
+	}
+	f.steps = append(f.steps, name+"@"+state.Phase.String())
+	return nil
+}
+
+func (f *fakeKeyRotation) addVaultKey(state *keyRotationState, oldKey, newKey []byte) error {
+	if err := f.step("add"); err != nil {
+		return err
+	}
+	f.vaultKeys[string(newKey)] = true
+	return nil
+}
+
+func (f *fakeKeyRotation) sealKey(key []byte) error {
+	if err := f.step("seal"); err != nil {
+		return err
+	}
+	f.tpmKey = key
+	return nil
+}
+
+func (f *fakeKeyRotation) escrowKey() error {
+	if err := f.step("escrow"); err != nil {
+		return err
+	}
+	f.sentKey = f.tpmKey
+	return nil
+}
+
+func (f *fakeKeyRotation) keyEscrowed(key []byte) bool {
+	if f.acknowledge && f.sentKey != nil {
+		f.escrowedKey = f.sentKey
+	}
+	return bytes.Equal(f.escrowedKey, key)
+}
+
+func (f *fakeKeyRotation) retireVaultKey(state *keyRotationState, oldKey, newKey []byte) error {
+	if err := f.step("retire"); err != nil {
+		return err
+	}
+	delete(f.vaultKeys, string(oldKey))
+	return nil
+}
+
+func setupKeyRotationTest(t *testing.T) (*fakeKeyRotation, []byte, []byte) {
+	logger = logrus.StandardLogger()
+	log = base.NewSourceLogObject(logger, agentName, 0)
+	stateFile := vaultKeyRotationFile
+	t.Cleanup(func() { vaultKeyRotationFile = stateFile })
+	vaultKeyRotationFile = filepath.Join(t.TempDir(), "vault-key-rotation.json")
+
+	oldKey := bytes.Repeat([]byte{1}, vaultKeyLen)
+	newKey := bytes.Repeat([]byte{2}, vaultKeyLen)
+	fake := &fakeKeyRotation{
+		t:           t,
+		vaultKeys:   map[string]bool{string(oldKey): true},
+		tpmKey:      oldKey,
+		escrowedKey: oldKey,
+	}
+	return fake, oldKey, newKey
+}
+
+func TestRunKeyRotation(t *testing.T) {
+	fake, oldKey, newKey := setupKeyRotationTest(t)
+	state := keyRotationState{Phase: keyRotationStarted}
+	if err := saveKeyRotationState(state); err != nil {
+		t.Fatalf("saveKeyRotationState failed: %v", err)
+	}
+
+	//the old key is not retired until Controller acknowledges the new one
+	if err := runKeyRotation(fake, &state, oldKey, newKey); err != nil {
+		t.Fatalf("runKeyRotation failed: %v", err)
+	}
+	if state.Phase != keyRotationEscrowing {
+		t.Errorf("want phase %s, but got %s", keyRotationEscrowing, state.Phase)
+	}
+	if !fake.vaultKeys[string(oldKey)] || !fake.vaultKeys[string(newKey)] {
+		t.Errorf("vault does not accept both keys while waiting for Controller")
+	}
+	if err := runKeyRotation(fake, &state, oldKey, newKey); err != nil {
+		t.Fatalf("runKeyRotation failed: %v", err)
+	}
+	if state.Phase != keyRotationEscrowing {
+		t.Errorf("want phase %s, but got %s", keyRotationEscrowing, state.Phase)
+	}
+
+	fake.acknowledge = true
+	if err := runKeyRotation(fake, &state, oldKey, newKey); err != nil {
+		t.Fatalf("runKeyRotation failed: %v", err)
+	}
+	want := []string{"add@started", "seal@key added", "escrow@resealed", "retire@escrowed"}
+	if !reflect.DeepEqual(fake.steps, want) {
+		t.Errorf("want steps %v, but got %v", want, fake.steps)
+	}
+	saved, err := loadKeyRotationState()
+	if err != nil {
+		t.Fatalf("loadKeyRotationState failed: %v", err)
+	}
+	if saved.Phase != keyRotationIdle || saved.OldKey != nil || saved.NewKey != nil ||
+		saved.LastRotation.IsZero() {
+		t.Errorf("Unexpected state after rotation %+v", saved)
+	}
+	if fake.vaultKeys[string(oldKey)] || !fake.vaultKeys[string(newKey)] {
+		t.Errorf("vault accepts %d keys after rotation, want the new key only",
+			len(fake.vaultKeys))
+	}
+}
+
+func TestRunKeyRotationInterrupted(t *testing.T) {
+	for _, failStep := range []string{"add", "seal", "escrow", "retire"} {
+		fake, oldKey, newKey := setupKeyRotationTest(t)
+		fake.acknowledge = true
+		fake.failStep = failStep
+		state := keyRotationState{Phase: keyRotationStarted}
+		if err := saveKeyRotationState(state); err != nil {
+			t.Fatalf("saveKeyRotationState failed: %v", err)
+		}
+		if err := runKeyRotation(fake, &state, oldKey, newKey); err == nil {
+			t.Errorf("%s: runKeyRotation succeeded with injected failure", failStep)
+		}
+		//resume from the saved phase, as after a reboot
+		fake.failStep = ""
+		saved, err := loadKeyRotationState()
+		if err != nil {
+			t.Fatalf("loadKeyRotationState failed: %v", err)
+		}
+		if saved.Phase != state.Phase {
+			t.Errorf("%s: saved phase %s, but rotation is in phase %s",
+				failStep, saved.Phase, state.Phase)
+		}
+		if err := runKeyRotation(fake, &saved, oldKey, newKey); err != nil {
+			t.Fatalf("%s: runKeyRotation failed: %v", failStep, err)
+		}
+		if saved.Phase != keyRotationIdle {
+			t.Errorf("%s: want phase %s, but got %s", failStep, keyRotationIdle, saved.Phase)
+		}
+		if !bytes.Equal(fake.tpmKey, newKey) || !bytes.Equal(fake.escrowedKey, newKey) {
+			t.Errorf("%s: TPM or Controller does not hold the new key", failStep)
+		}
+	}
+}
+
+func TestRotationUnlockKeys(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, vaultKeyLen)
+	newKey := bytes.Repeat([]byte{2}, vaultKeyLen)
+	state := keyRotationState{Phase: keyRotationStarted, OldProtector: "1111"}
+
+	//new protector not created yet
+	keys, err := rotationUnlockKeys(state, oldKey, oldKey, newKey)
+	if err != nil {
+		t.Fatalf("rotationUnlockKeys failed: %v", err)
+	}
+	want := []rotationUnlockKey{{tpmKey: oldKey, protector: "1111"}}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("want %v, but got %v", want, keys)
+	}
+
+	//key in TPM first, then the other one, e.g. for ZFS
+	//still using the old key after resealing
+	state.Phase = keyRotationEscrowing
+	state.NewProtector = "2222"
+	keys, err = rotationUnlockKeys(state, newKey, oldKey, newKey)
+	if err != nil {
+		t.Fatalf("rotationUnlockKeys failed: %v", err)
+	}
+	want = []rotationUnlockKey{{tpmKey: newKey, protector: "2222"},
+		{tpmKey: oldKey, protector: "1111"}}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("want %v, but got %v", want, keys)
+	}
+
+	//keys of the rotation are not used with any other key in TPM
+	otherKey := bytes.Repeat([]byte{3}, vaultKeyLen)
+	if _, err := rotationUnlockKeys(state, otherKey, oldKey, newKey); err == nil {
+		t.Errorf("rotationUnlockKeys succeeded with unknown key in TPM")
+	}
+}
@@ -0,0 +1,547 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package vaultmgr
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	etpm "github.com/lf-edge/eve/pkg/pillar/evetpm"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	fileutils "github.com/lf-edge/eve/pkg/pillar/utils/file"
+	"github.com/lf-edge/eve/pkg/pillar/vault"
+)
+
+// keyRotationCheckInterval is how often we check if the vault key is due for rotation
+const keyRotationCheckInterval = time.Minute
+
+// vaultKeyRotationFile keeps keyRotationState across reboots.
+// It is not a constant due to test usage
+var vaultKeyRotationFile = types.PersistStatusDir + "/vault-key-rotation.json"
+
+// keyRotationPhase tracks progress of the vault key rotation, so that
+// a rotation interrupted by a reboot can be resumed
+type keyRotationPhase uint8
+
+// Vault key rotation goes through these phases, in this order. The vault
+// keeps accepting the old key until Controller acknowledged storing the
+// new one, so the vault can always be unlocked with the key in TPM, or
+// with the key escrowed with Controller.
+const (
+	keyRotationIdle      keyRotationPhase = iota //No rotation in progress
+	keyRotationStarted                           //New key generated, vault accepts the old key only
+	keyRotationKeyAdded                          //Vault accepts both keys, TPM holds the old one
+	keyRotationResealed                          //TPM holds the new key, not yet sent to Controller
+	keyRotationEscrowing                         //New key sent to Controller, waiting for acknowledgement
+	keyRotationEscrowed                          //Controller holds the new key, old key to be retired
+)
+
+func (phase keyRotationPhase) String() string {
+	switch phase {
+	case keyRotationIdle:
+		return "idle"
+	case keyRotationStarted:
+		return "started"
+	case keyRotationKeyAdded:
+		return "key added"
+	case keyRotationResealed:
+		return "resealed"
+	case keyRotationEscrowing:
+		return "escrowing"
+	case keyRotationEscrowed:
+		return "escrowed"
+	default:
+		return fmt.Sprintf("unknown(%d)", phase)
+	}
+}
+
+// keyRotationState is kept in vaultKeyRotationFile. While the rotation is
+// in progress, the old and the new TPM keys are kept there encrypted using
+// TPM (see etpm.EncryptDecryptUsingTpm), to recover from an interruption.
+// On ext4 the fscrypt protectors of the old and the new key are kept too.
+type keyRotationState struct {
+	Phase        keyRotationPhase
+	OldKey       []byte
+	NewKey       []byte
+	OldProtector string
+	NewProtector string
+	LastRotation time.Time
+}
+
+func loadKeyRotationState() (keyRotationState, error) {
+	var state keyRotationState
+	stateBytes, err := ioutil.ReadFile(vaultKeyRotationFile)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(stateBytes, &state); err != nil {
+		return state, fmt.Errorf("failed to parse %s: %v", vaultKeyRotationFile, err)
+	}
+	return state, nil
+}
+
+func saveKeyRotationState(state keyRotationState) error {
+	stateBytes, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return fileutils.WriteRename(vaultKeyRotationFile, stateBytes)
+}
+
+// keyRotationDue returns true if an interrupted rotation needs to be resumed,
+// or if interval passed since the last rotation
+func keyRotationDue(state keyRotationState, interval time.Duration, now time.Time) bool {
+	if state.Phase != keyRotationIdle {
+		return true
+	}
+	if interval == 0 || state.LastRotation.IsZero() {
+		return false
+	}
+	return now.Sub(state.LastRotation) >= interval
+}
+
+func decryptRotationKeys(state keyRotationState) ([]byte, []byte, error) {
+	oldKey, err := etpm.EncryptDecryptUsingTpm(state.OldKey, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt old key: %v", err)
+	}
+	newKey, err := etpm.EncryptDecryptUsingTpm(state.NewKey, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt new key: %v", err)
+	}
+	return oldKey, newKey, nil
+}
+
+// rotationVaultKeys derives the vault keys from the TPM keys of the rotation
+func rotationVaultKeys(oldTpmKey, newTpmKey []byte) ([]byte, []byte, error) {
+	cloudKey, err := retrieveCloudKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	oldKey, err := vaultKeyFromTpmKey(oldTpmKey, cloudKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	newKey, err := vaultKeyFromTpmKey(newTpmKey, cloudKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return oldKey, newKey, nil
+}
+
+// keyRotationOps are the steps of the vault key rotation, taking TPM keys.
+// Implemented by vaultKeyRotationOps, and replaced in tests.
+type keyRotationOps interface {
+	//addVaultKey makes the vault accept newKey, in addition to oldKey
+	addVaultKey(state *keyRotationState, oldKey, newKey []byte) error
+	//sealKey seals key into TPM
+	sealKey(key []byte) error
+	//escrowKey sends the key sealed into TPM to Controller
+	escrowKey() error
+	//keyEscrowed returns true once Controller acknowledged storing key
+	keyEscrowed(key []byte) bool
+	//retireVaultKey makes the vault accept newKey only
+	retireVaultKey(state *keyRotationState, oldKey, newKey []byte) error
+}
+
+// vaultKeyRotationOps rotates the key of the default vault
+type vaultKeyRotationOps struct {
+	ctx *vaultMgrContext
+}
+
+func (ops vaultKeyRotationOps) addVaultKey(state *keyRotationState, oldKey, newKey []byte) error {
+	oldVaultKey, newVaultKey, err := rotationVaultKeys(oldKey, newKey)
+	if err != nil {
+		return err
+	}
+	persistFsType := vault.ReadPersistType()
+	switch persistFsType {
+	case types.PersistExt4:
+		return addFscryptVaultKey(defaultVault, state, oldVaultKey, newVaultKey)
+	case types.PersistZFS:
+		//ZFS wraps the dataset master key with a single key, it is changed
+		//once Controller holds the new key. Until then the old key is taken
+		//from the rotation state to unlock the vault, see recoverKeyRotation.
+		log.Noticef("Keeping the old key of %s until the new key is escrowed",
+			defaultSecretDataset)
+		return nil
+	default:
+		return fmt.Errorf("key rotation is not supported on %s filesystem", persistFsType)
+	}
+}
+
+func (ops vaultKeyRotationOps) sealKey(key []byte) error {
+	if err := etpm.SealDiskKey(key, etpm.DiskKeySealingPCRs); err != nil {
+		return fmt.Errorf("failed to seal new key: %v", err)
+	}
+	saveMeasurementLog(ops.ctx)
+	return nil
+}
+
+func (ops vaultKeyRotationOps) escrowKey() error {
+	//publishVaultKey reads the key from TPM
+	return publishVaultKey(ops.ctx, types.DefaultVaultName)
+}
+
+func (ops vaultKeyRotationOps) keyEscrowed(key []byte) bool {
+	digest := sha256.Sum256(key)
+	return bytes.Equal(ops.ctx.escrowedKeyDigest, digest[:])
+}
+
+func (ops vaultKeyRotationOps) retireVaultKey(state *keyRotationState, oldKey, newKey []byte) error {
+	_, newVaultKey, err := rotationVaultKeys(oldKey, newKey)
+	if err != nil {
+		return err
+	}
+	persistFsType := vault.ReadPersistType()
+	switch persistFsType {
+	case types.PersistExt4:
+		return retireFscryptVaultKey(defaultVault, state)
+	case types.PersistZFS:
+		return changeZfsVaultKey(defaultSecretDataset, newVaultKey)
+	default:
+		return fmt.Errorf("key rotation is not supported on %s filesystem", persistFsType)
+	}
+}
+
+// hasProtector checks if protectorID is among protectors, as returned
+// by getProtectorID
+func hasProtector(protectors [][]string, protectorID string) bool {
+	for _, protector := range protectors {
+		if protector[1] == protectorID {
+			return true
+		}
+	}
+	return false
+}
+
+// addFscryptVaultKey adds a protector with newKey to the policy of the vault,
+// next to the protector with oldKey. fscrypt reads the keys of both protectors
+// from the same key file when adding a protector to a policy, so the new
+// protector is created with oldKey, added, and then changed to newKey.
+// Every step is skipped if already done before an interruption.
+func addFscryptVaultKey(vaultPath string, state *keyRotationState, oldKey, newKey []byte) error {
+	protectors, err := getProtectorID(vaultPath)
+	if err != nil {
+		return err
+	}
+	if state.OldProtector == "" {
+		if len(protectors) != 1 {
+			return fmt.Errorf("expected a single protector of %s, found %d",
+				vaultPath, len(protectors))
+		}
+		state.OldProtector = protectors[0][1]
+		if err := saveKeyRotationState(*state); err != nil {
+			return err
+		}
+	}
+	policyID, err := getPolicyID(vaultPath)
+	if err != nil {
+		return err
+	}
+	if err := stageVaultKey(oldKey, oldKeyDir, oldKeyFile); err != nil {
+		return err
+	}
+	defer unstageKey(oldKeyDir, oldKeyFile)
+	if state.NewProtector == "" {
+		stdOut, stdErr, err := execCmd(vault.FscryptPath,
+			getCreateProtectorParams(vaultPath)...)
+		if err != nil {
+			log.Errorf("Error creating protector: %v, %s, %s", err, stdOut, stdErr)
+			return err
+		}
+		match := createdProtectorRegexp.FindStringSubmatch(stdOut)
+		if match == nil {
+			return fmt.Errorf("no protector ID in fscrypt output: %s", stdOut)
+		}
+		state.NewProtector = match[1]
+		if err := saveKeyRotationState(*state); err != nil {
+			return err
+		}
+		log.Functionf("Created protector %s", state.NewProtector)
+	}
+	if !hasProtector(protectors, state.NewProtector) {
+		if stdOut, stdErr, err := execCmd(vault.FscryptPath,
+			getAddProtectorParams(state.NewProtector, policyID, state.OldProtector)...); err != nil {
+			log.Errorf("Error adding protector: %v, %s, %s", err, stdOut, stdErr)
+			return err
+		}
+		log.Functionf("Added protector %s to policy %s", state.NewProtector, policyID)
+	}
+	if err := stageVaultKey(newKey, keyDir, keyFile); err != nil {
+		return err
+	}
+	defer unstageKey(keyDir, keyFile)
+	stdOut, stdErr, err := execCmd(vault.FscryptPath,
+		getChangeProtectorParams(state.NewProtector)...)
+	if err == nil {
+		log.Functionf("Changed key for protector %s", state.NewProtector)
+		return nil
+	}
+	//the key may have been changed before an interruption,
+	//check by changing it from newKey to newKey
+	unstageKey(oldKeyDir, oldKeyFile)
+	if err := stageVaultKey(newKey, oldKeyDir, oldKeyFile); err != nil {
+		return err
+	}
+	if _, _, err := execCmd(vault.FscryptPath,
+		getChangeProtectorParams(state.NewProtector)...); err == nil {
+		log.Functionf("Key for protector %s already changed", state.NewProtector)
+		return nil
+	}
+	log.Errorf("Error changing protector key: %v, %s, %s", err, stdOut, stdErr)
+	return err
+}
+
+// retireFscryptVaultKey removes the protector with the old key
+// from the policy of the vault, and destroys it
+func retireFscryptVaultKey(vaultPath string, state *keyRotationState) error {
+	protectors, err := getProtectorID(vaultPath)
+	if err != nil {
+		return err
+	}
+	if hasProtector(protectors, state.OldProtector) {
+		if !hasProtector(protectors, state.NewProtector) {
+			return fmt.Errorf("protector %s of the new key does not protect %s",
+				state.NewProtector, vaultPath)
+		}
+		policyID, err := getPolicyID(vaultPath)
+		if err != nil {
+			return err
+		}
+		if stdOut, stdErr, err := execCmd(vault.FscryptPath,
+			getRemoveProtectorFromPolicyParams(state.OldProtector, policyID)...); err != nil {
+			log.Errorf("Error removing protector from policy: %v, %s, %s",
+				err, stdOut, stdErr)
+			return err
+		}
+		log.Functionf("Removed protector %s from policy %s", state.OldProtector, policyID)
+	}
+	if fscryptProtectorExists(state.OldProtector) {
+		if stdOut, stdErr, err := execCmd(vault.FscryptPath,
+			getRemoveProtectorParams(state.OldProtector)...); err != nil {
+			log.Errorf("Error destroying protector: %v, %s, %s", err, stdOut, stdErr)
+			return err
+		}
+		log.Functionf("Destroyed protector %s", state.OldProtector)
+	}
+	return nil
+}
+
+// startKeyRotation generates the new key, and saves it together
+// with the current key in the rotation state
+func startKeyRotation(state *keyRotationState) error {
+	log.Notice("Starting vault key rotation")
+	oldKey, err := retrieveTpmKey(true)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve key from TPM: %v", err)
+	}
+	newKey, err := etpm.GetRandom(vaultKeyLen)
+	if err != nil {
+		return fmt.Errorf("failed to generate new key: %v", err)
+	}
+	if state.OldKey, err = etpm.EncryptDecryptUsingTpm(oldKey, true); err != nil {
+		return fmt.Errorf("failed to encrypt old key: %v", err)
+	}
+	if state.NewKey, err = etpm.EncryptDecryptUsingTpm(newKey, true); err != nil {
+		return fmt.Errorf("failed to encrypt new key: %v", err)
+	}
+	state.OldProtector = ""
+	state.NewProtector = ""
+	state.Phase = keyRotationStarted
+	return saveKeyRotationState(*state)
+}
+
+// runKeyRotation runs the key rotation from its current phase: add the new
+// key to the vault, reseal it into TPM, send it to Controller, and once
+// Controller acknowledged it, retire the old key. The state is saved after
+// each phase. Returns without error while waiting for Controller.
+func runKeyRotation(ops keyRotationOps, state *keyRotationState, oldKey, newKey []byte) error {
+	for state.Phase != keyRotationIdle {
+		switch state.Phase {
+		case keyRotationStarted:
+			if err := ops.addVaultKey(state, oldKey, newKey); err != nil {
+				return err
+			}
+			state.Phase = keyRotationKeyAdded
+		case keyRotationKeyAdded:
+			if err := ops.sealKey(newKey); err != nil {
+				return err
+			}
+			state.Phase = keyRotationResealed
+		case keyRotationResealed:
+			if err := ops.escrowKey(); err != nil {
+				return err
+			}
+			state.Phase = keyRotationEscrowing
+		case keyRotationEscrowing:
+			if !ops.keyEscrowed(newKey) {
+				log.Functionf("Waiting for Controller to acknowledge the new vault key")
+				return nil
+			}
+			state.Phase = keyRotationEscrowed
+		case keyRotationEscrowed:
+			if err := ops.retireVaultKey(state, oldKey, newKey); err != nil {
+				return err
+			}
+			state.Phase = keyRotationIdle
+			state.OldKey = nil
+			state.NewKey = nil
+			state.OldProtector = ""
+			state.NewProtector = ""
+			state.LastRotation = time.Now()
+		default:
+			return fmt.Errorf("unexpected key rotation phase %s", state.Phase)
+		}
+		if err := saveKeyRotationState(*state); err != nil {
+			return err
+		}
+		log.Noticef("Vault key rotation: %s", state.Phase)
+	}
+	return nil
+}
+
+// rotateVaultKey starts a new key rotation, or continues the one in progress
+func rotateVaultKey(ctx *vaultMgrContext, state *keyRotationState) error {
+	if state.Phase == keyRotationIdle {
+		if err := startKeyRotation(state); err != nil {
+			return err
+		}
+	}
+	oldKey, newKey, err := decryptRotationKeys(*state)
+	if err != nil {
+		return err
+	}
+	return runKeyRotation(vaultKeyRotationOps{ctx: ctx}, state, oldKey, newKey)
+}
+
+// maybeRotateVaultKey rotates the key of the default vault once
+// the rotation interval passed, or continues the rotation in progress
+func maybeRotateVaultKey(ctx *vaultMgrContext) {
+	if !ctx.defaultVaultUnlocked || !etpm.IsTpmEnabled() || !etpm.PCRBankSHA256Enabled() {
+		return
+	}
+	persistFsType := vault.ReadPersistType()
+	if persistFsType != types.PersistExt4 && persistFsType != types.PersistZFS {
+		return
+	}
+	state, err := loadKeyRotationState()
+	if err != nil {
+		log.Errorf("Failed to load key rotation state: %v", err)
+		return
+	}
+	if state.Phase == keyRotationIdle && ctx.keyRotationInterval != 0 &&
+		state.LastRotation.IsZero() {
+		//count the interval from now on
+		state.LastRotation = time.Now()
+		if err := saveKeyRotationState(state); err != nil {
+			log.Errorf("Failed to save key rotation state: %v", err)
+		}
+		return
+	}
+	if !keyRotationDue(state, ctx.keyRotationInterval, time.Now()) {
+		return
+	}
+	if err := rotateVaultKey(ctx, &state); err != nil {
+		log.Errorf("Vault key rotation failed in phase %s: %v", state.Phase, err)
+	}
+}
+
+// rotationUnlockKey is a key to try for unlocking the vault during
+// a key rotation, with the fscrypt protector it is expected to unlock
+type rotationUnlockKey struct {
+	tpmKey    []byte
+	protector string
+}
+
+// rotationUnlockKeys returns the keys to try for unlocking the vault during
+// a key rotation: the key sealed into TPM, then the other key of the
+// rotation. The vault may not accept the key in TPM yet, e.g. on ZFS until
+// the new key is escrowed. The keys of the rotation are used only if the
+// key sealed into TPM is one of them, i.e. the PCRs are unchanged or the
+// key came from Controller.
+func rotationUnlockKeys(state keyRotationState, sealedKey, oldKey, newKey []byte) ([]rotationUnlockKey, error) {
+	oldUnlockKey := rotationUnlockKey{tpmKey: oldKey, protector: state.OldProtector}
+	newUnlockKey := rotationUnlockKey{tpmKey: newKey, protector: state.NewProtector}
+	switch {
+	case bytes.Equal(sealedKey, newKey):
+		return []rotationUnlockKey{newUnlockKey, oldUnlockKey}, nil
+	case bytes.Equal(sealedKey, oldKey):
+		if state.NewProtector == "" {
+			return []rotationUnlockKey{oldUnlockKey}, nil
+		}
+		return []rotationUnlockKey{oldUnlockKey, newUnlockKey}, nil
+	default:
+		return nil, errors.New("TPM holds neither old nor new key of the key rotation")
+	}
+}
+
+// unlockDuringKeyRotation unlocks the default vault with tpmKey, using the
+// fscrypt protector if set, since the vault may have two protectors
+func unlockDuringKeyRotation(tpmKey []byte, protector string) error {
+	cloudKey, err := retrieveCloudKey()
+	if err != nil {
+		return err
+	}
+	vaultKey, err := vaultKeyFromTpmKey(tpmKey, cloudKey)
+	if err != nil {
+		return err
+	}
+	persistFsType := vault.ReadPersistType()
+	switch persistFsType {
+	case types.PersistExt4:
+		return unlockVaultWithKey(defaultVault, vaultKey, protector)
+	case types.PersistZFS:
+		return unlockZfsVaultWithKey(defaultSecretDataset, vaultKey)
+	default:
+		return fmt.Errorf("key rotation is not supported on %s filesystem", persistFsType)
+	}
+}
+
+// recoverKeyRotation unlocks the default vault with unlock, unless a key
+// rotation is in progress, when the vault is unlocked with one of the keys
+// of the rotation instead. The rotation itself continues from the saved
+// phase in maybeRotateVaultKey.
+func recoverKeyRotation(unlock func() error) error {
+	state, err := loadKeyRotationState()
+	if err != nil {
+		log.Errorf("Failed to load key rotation state: %v", err)
+		return unlock()
+	}
+	if state.Phase == keyRotationIdle {
+		return unlock()
+	}
+	oldKey, newKey, err := decryptRotationKeys(state)
+	if err != nil {
+		log.Errorf("Cannot recover vault key rotation: %v", err)
+		return unlock()
+	}
+	sealedKey, err := etpm.UnsealDiskKey(etpm.DiskKeySealingPCRs)
+	if err != nil {
+		//PCRs changed, wait for the key from Controller
+		log.Noticef("Cannot unlock vault during key rotation now: %v", err)
+		return unlock()
+	}
+	keys, err := rotationUnlockKeys(state, sealedKey, oldKey, newKey)
+	if err != nil {
+		log.Error(err)
+		return unlock()
+	}
+	for _, key := range keys {
+		if err = unlockDuringKeyRotation(key.tpmKey, key.protector); err == nil {
+			log.Noticef("Unlocked vault during key rotation in phase %s", state.Phase)
+			return nil
+		}
+	}
+	return err
+}
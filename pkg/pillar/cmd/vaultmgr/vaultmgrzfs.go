@@ -67,6 +67,28 @@ func unlockZfsVault(vaultPath string) error {
 	return nil
 }
 
+//unlockZfsVaultWithKey loads vaultKey for the dataset and mounts it,
+//e.g. during key rotation when TPM may not hold the key of the dataset
+func unlockZfsVaultWithKey(vaultPath string, vaultKey []byte) error {
+	if err := stageVaultKey(vaultKey, zfsKeyDir, zfsKeyFile); err != nil {
+		return err
+	}
+	defer unstageKey(zfsKeyDir, zfsKeyFile)
+
+	args := getLoadKeyParams(vaultPath)
+	if stdOut, stdErr, err := execCmd(types.ZFSBinary, args...); err != nil {
+		log.Errorf("Error loading key for vault: %v, %s, %s",
+			err, stdOut, stdErr)
+		return err
+	}
+	args = getMountParams(vaultPath)
+	if stdOut, stdErr, err := execCmd(types.ZFSBinary, args...); err != nil {
+		log.Errorf("Error unlocking vault: %v, %s, %s", err, stdOut, stdErr)
+		return err
+	}
+	return nil
+}
+
 //e.g. zfs create -o encryption=aes-256-gcm -o keylocation=file://tmp/raw.key -o keyformat=raw persist/vault
 func createZfsVault(vaultPath string) error {
 	//prepare key in the staging file
@@ -86,6 +108,29 @@ func createZfsVault(vaultPath string) error {
 	return nil
 }
 
+func getChangeKeyParams(vaultPath string) []string {
+	args := []string{"change-key", "-o", "keyformat=raw",
+		"-o", "keylocation=file://" + zfsKeyFile, vaultPath}
+	return args
+}
+
+//e.g. zfs change-key -o keyformat=raw -o keylocation=file:///run/TmpVaultDir2/protector.key persist/vault
+//zfs re-wraps the dataset master key with the new key, the data is not re-encrypted
+func changeZfsVaultKey(vaultPath string, newKey []byte) error {
+	if err := stageVaultKey(newKey, zfsKeyDir, zfsKeyFile); err != nil {
+		return err
+	}
+	defer unstageKey(zfsKeyDir, zfsKeyFile)
+	args := getChangeKeyParams(vaultPath)
+	if stdOut, stdErr, err := execCmd(types.ZFSBinary, args...); err != nil {
+		log.Errorf("Error changing key of vault %s, error=%v, %s, %s",
+			vaultPath, err, stdOut, stdErr)
+		return err
+	}
+	log.Functionf("Changed key of vault %s", vaultPath)
+	return nil
+}
+
 //e.g. zfs get keystatus persist/vault
 func checkKeyStatus(vaultPath string) error {
 	args := getKeyStatusParams(vaultPath)
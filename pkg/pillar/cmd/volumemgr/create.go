@@ -128,7 +128,14 @@ func createVdiskVolume(ctx *volumemgrContext, status types.VolumeStatus,
 		}
 		filelocation = zVolDevice
 	default:
-		if ref != "" {
+		if status.IsolatedKey {
+			device, err := createLuksVolume(createContext, ctx, status, ref)
+			if err != nil {
+				log.Error(err)
+				return created, device, err
+			}
+			filelocation = device
+		} else if ref != "" {
 			// use the edge-containers library to extract the data we need
 			puller := registry.Puller{
 				Image: ref,
@@ -284,19 +291,35 @@ func destroyVdiskVolume(ctx *volumemgrContext, status types.VolumeStatus) (bool,
 				log.Error(errStr)
 			}
 		}
-		//Assume this is zfs device
-		zVolName := status.ZVolName()
-		if stdoutStderr, err := zfs.DestroyDataset(log, zVolName); err != nil {
-			errStr := fmt.Sprintf("Error destroying zfs zvol at %s, error=%v, output=%s",
-				zVolName, err, stdoutStderr)
-			log.Error(errStr)
-			return created, "", errors.New(errStr)
+		if ctx.persistType != types.PersistZFS && status.IsolatedKey {
+			if err := destroyLuksVolume(status); err != nil {
+				errStr := fmt.Sprintf("Error destroying LUKS container for %s, error=%v",
+					status.Key(), err)
+				log.Error(errStr)
+				return created, "", errors.New(errStr)
+			}
+		} else {
+			//Assume this is zfs device
+			zVolName := status.ZVolName()
+			if stdoutStderr, err := zfs.DestroyDataset(log, zVolName); err != nil {
+				errStr := fmt.Sprintf("Error destroying zfs zvol at %s, error=%v, output=%s",
+					zVolName, err, stdoutStderr)
+				log.Error(errStr)
+				return created, "", errors.New(errStr)
+			}
 		}
 	} else {
 		if err := os.RemoveAll(filelocation); err != nil {
 			log.Error(err)
 			return created, "", err
 		}
+		removeLocalSnapshotFiles(localSnapshotDirName(status.Encrypted),
+			status.Key(), "")
+	}
+	if status.IsolatedKey {
+		if err := destroyVolumeKey(status.Key()); err != nil {
+			return created, "", err
+		}
 	}
 	filelocation = ""
 	created = false
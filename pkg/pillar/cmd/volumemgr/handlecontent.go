@@ -35,6 +35,11 @@ func handleContentTreeModify(ctxArg interface{}, key string,
 	if status == nil {
 		log.Fatalf("Missing ContentTreeStatus for %s", config.Key())
 	}
+	status.DownloadPriority = config.DownloadPriority
+	if !signatureKeysEqual(status.SignatureKeys, config.SignatureKeys) {
+		updateContentTreeSignatureKeys(ctx, status, config.SignatureKeys)
+		publishContentTreeStatus(ctx, status)
+	}
 	updateContentTree(ctx, status)
 	log.Functionf("handleContentTree(%s) Done", key)
 }
@@ -159,6 +164,8 @@ func createContentTreeStatus(ctx *volumemgrContext, config types.ContentTreeConf
 			MaxDownloadSize:   config.MaxDownloadSize,
 			GenerationCounter: config.GenerationCounter,
 			DisplayName:       config.DisplayName,
+			SignatureKeys:     config.SignatureKeys,
+			DownloadPriority:  config.DownloadPriority,
 			State:             types.INITIAL,
 			Blobs:             []string{},
 			// LastRefCountChangeTime: time.Now(),
@@ -173,19 +180,24 @@ func createContentTreeStatus(ctx *volumemgrContext, config types.ContentTreeConf
 				// i.e. an OCI registry may have other formats, no matter what the
 				// image format is. This will do for now, though.
 				mediaType := string(v1types.OCILayer)
+				chunkIndexURL := config.ChunkIndexURL
 				if config.Format == zconfig.Format_CONTAINER {
 					// when first creating the root, the type is unknown,
 					// but will be updated from the mediatype passed by the
 					// Content-Type http header
 					mediaType = ""
+					// registries do their own deduplication of layers
+					chunkIndexURL = ""
 				}
 				rootBlob := &types.BlobStatus{
 					DatastoreID:            config.DatastoreID,
 					RelativeURL:            config.RelativeURL,
+					ChunkIndexURL:          chunkIndexURL,
 					Sha256:                 strings.ToLower(config.ContentSha256),
 					Size:                   config.MaxDownloadSize,
 					State:                  types.INITIAL,
 					MediaType:              mediaType,
+					DownloadPriority:       config.DownloadPriority,
 					CreateTime:             time.Now(),
 					LastRefCountChangeTime: time.Now(),
 				}
@@ -267,6 +279,9 @@ func deleteContentTree(ctx *volumemgrContext, status *types.ContentTreeStatus) {
 
 	// Clean up in case it was never resolved
 	deleteResolveConfig(ctx, status.ResolveKey())
+	if len(status.SignatureKeys) != 0 {
+		deleteResolveConfig(ctx, signatureResolveConfig(*status).Key())
+	}
 
 	// If the content tree did not complete, or knob is at default of
 	// no defer, then delete. Otherwise honor defer time to to avoid
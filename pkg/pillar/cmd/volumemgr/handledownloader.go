@@ -56,18 +56,33 @@ func AddOrRefcountDownloaderConfig(ctx *volumemgrContext, blob types.BlobStatus)
 	// try to reserve storage, must be released on error
 	size := blob.Size
 	n := types.DownloaderConfig{
-		DatastoreID: blob.DatastoreID,
-		Name:        blob.RelativeURL,
-		ImageSha256: blob.Sha256,
-		Size:        size,
-		Target:      locFilename,
-		RefCount:    refCount,
+		DatastoreID:   blob.DatastoreID,
+		Name:          blob.RelativeURL,
+		ImageSha256:   blob.Sha256,
+		Size:          size,
+		Target:        locFilename,
+		RefCount:      refCount,
+		ChunkIndexURL: blob.ChunkIndexURL,
+		Priority:      blob.DownloadPriority,
 	}
 	log.Functionf("AddOrRefcountDownloaderConfig: DownloaderConfig: %+v", n)
 	publishDownloaderConfig(ctx, &n)
 	log.Functionf("AddOrRefcountDownloaderConfig done for %s", blob.Sha256)
 }
 
+// updateDownloaderConfigPriority updates the priority of the existing
+// DownloaderConfig of the blob
+func updateDownloaderConfigPriority(ctx *volumemgrContext, blob types.BlobStatus) {
+	m := lookupDownloaderConfig(ctx, blob.Sha256)
+	if m == nil || m.Priority == blob.DownloadPriority {
+		return
+	}
+	log.Functionf("updateDownloaderConfigPriority for %s from %d to %d",
+		blob.Sha256, m.Priority, blob.DownloadPriority)
+	m.Priority = blob.DownloadPriority
+	publishDownloaderConfig(ctx, m)
+}
+
 // MaybeRemoveDownloaderConfig decrements Refcount of the given DownloaderConfig.
 // If the Refcount of a DownloaderConfig reaches zero, the following sequence of handshake is performed
 // before deleting DownloaderConfig:
@@ -16,6 +16,8 @@ func MaybeAddResolveConfig(ctx *volumemgrContext, cs types.ContentTreeStatus) {
 		DatastoreID: cs.DatastoreID,
 		Name:        cs.RelativeURL,
 		Counter:     uint32(cs.GenerationCounter),
+		// verify the signature together with resolving the tag
+		SignatureKeys: cs.SignatureKeys,
 	}
 	publishResolveConfig(ctx, &resolveConfig)
 	log.Functionf("MaybeAddResolveConfig for %s Done", cs.ContentID)
@@ -102,7 +104,8 @@ func handleResolveStatusImpl(ctxArg interface{}, key string,
 	for _, cs := range items {
 		status := cs.(types.ContentTreeStatus)
 		if !status.HasResolverRef ||
-			status.RelativeURL != rs.Name ||
+			(status.RelativeURL != rs.Name &&
+				signatureResolveConfig(status).Name != rs.Name) ||
 			status.DatastoreID != rs.DatastoreID {
 			continue
 		}
@@ -25,6 +25,25 @@ func handleVolumeCreate(ctxArg interface{}, key string,
 	log.Functionf("handleVolumeCreate(%s) Done", key)
 }
 
+//maybeLoadVolumeKey loads the key of the existing volume with IsolatedKey
+//and sets FileLocation and KeyState. It publishes the error and returns
+//false if the key cannot be loaded.
+func maybeLoadVolumeKey(ctx *volumemgrContext, status *types.VolumeStatus) bool {
+	device, err := loadVolumeKey(ctx, *status)
+	if err != nil {
+		log.Errorf("maybeLoadVolumeKey(%s): %v", status.Key(), err)
+		status.KeyState = types.VolumeKeyStateError
+		status.SetError(fmt.Sprintf("cannot load key of volume %s: %v",
+			status.DisplayName, err), time.Now())
+		publishVolumeStatus(ctx, status)
+		updateVolumeRefStatus(ctx, status)
+		return false
+	}
+	status.KeyState = types.VolumeKeyStateLoaded
+	status.FileLocation = device
+	return true
+}
+
 func handleVolumeModify(ctxArg interface{}, key string,
 	configArg interface{}, oldConfigArg interface{}) {
 
@@ -69,6 +88,7 @@ func handleVolumeModify(ctxArg interface{}, key string,
 		if err := createOrUpdateAppDiskMetrics(ctx, status); err != nil {
 			log.Errorf("handleVolumeModify(%s): exception while publishing diskmetric. %s", key, err.Error())
 		}
+		maybeSnapshotVolume(ctx, status, config.LocalSnapshotCounter)
 	}
 	log.Functionf("handleVolumeModify(%s) Done", key)
 }
@@ -111,8 +131,11 @@ func handleDeferredVolumeCreate(ctx *volumemgrContext, key string, config *types
 		GenerationCounter:       config.GenerationCounter,
 		LocalGenerationCounter:  config.LocalGenerationCounter,
 		Encrypted:               config.Encrypted,
+		IsolatedKey:             config.IsolatedKey,
+		AppInstanceID:           config.AppInstanceID,
 		DisplayName:             config.DisplayName,
 		RefCount:                config.RefCount,
+		LocalSnapshotCounter:    config.LocalSnapshotCounter,
 		LastRefCountChangeTime:  time.Now(),
 		LastUse:                 time.Now(),
 		State:                   types.INITIAL,
@@ -127,6 +150,9 @@ func handleDeferredVolumeCreate(ctx *volumemgrContext, key string, config *types
 	if persistFsType == types.PersistZFS && !status.IsContainer() {
 		zvolName := status.ZVolName()
 		if _, err := zfs.GetDatasetOptions(log, zvolName); err == nil {
+			if status.IsolatedKey && !maybeLoadVolumeKey(ctx, status) {
+				return
+			}
 			zVolDevice := zfs.GetZVolDeviceByDataset(zvolName)
 			if zVolDevice == "" {
 				errStr := fmt.Sprintf("cannot find device for zvol %s of %s", zvolName, status.Key())
@@ -160,8 +186,14 @@ func handleDeferredVolumeCreate(ctx *volumemgrContext, key string, config *types
 		}
 	} else {
 		if _, err := os.Stat(status.PathName()); err == nil {
+			if status.IsolatedKey && !status.IsContainer() {
+				if !maybeLoadVolumeKey(ctx, status) {
+					return
+				}
+			} else {
+				status.FileLocation = status.PathName()
+			}
 			created = true
-			status.FileLocation = status.PathName()
 		}
 	}
 
@@ -183,11 +215,25 @@ func handleDeferredVolumeCreate(ctx *volumemgrContext, key string, config *types
 			status.CurrentSize = int64(actualSize)
 		}
 		updateStatusByPersistType(status, persistFsType)
+		if status.LocalSnapshotCounter != 0 {
+			// take the requested snapshot again if it was not taken
+			// before reboot
+			snapshotTime, err := lookupLocalSnapshotTime(ctx, *status,
+				status.LocalSnapshotCounter)
+			if err != nil {
+				log.Warnf("handleDeferredVolumeCreate(%s): no snapshot %d: %v",
+					key, status.LocalSnapshotCounter, err)
+				status.LocalSnapshotCounter = 0
+			} else {
+				status.LocalSnapshotTime = snapshotTime
+			}
+		}
 		publishVolumeStatus(ctx, status)
 		updateVolumeRefStatus(ctx, status)
 		if err := createOrUpdateAppDiskMetrics(ctx, status); err != nil {
 			log.Errorf("handleDeferredVolumeCreate(%s): exception while publishing diskmetric. %s", key, err.Error())
 		}
+		maybeSnapshotVolume(ctx, status, config.LocalSnapshotCounter)
 		return
 	}
 	publishVolumeStatus(ctx, status)
@@ -303,6 +349,9 @@ func maybeDeleteVolume(ctx *volumemgrContext, status *types.VolumeStatus) {
 			log.Functionf("VolumeWorkResult(%s) location %s, created %t",
 				status.Key(), vr.FileLocation, vr.VolumeCreated)
 			if !vr.VolumeCreated {
+				if status.IsolatedKey && !status.IsContainer() {
+					status.KeyState = types.VolumeKeyStateDestroyed
+				}
 				readyToUnPublish = true
 			} else {
 				var err string
@@ -418,6 +467,13 @@ func quantifyChanges(config types.VolumeConfig,
 		needRegeneration = true
 		regenerationReason += str + "\n"
 	}
+	if config.IsolatedKey != status.IsolatedKey {
+		str := fmt.Sprintf("IsolatedKey changed from %v to %v for %s",
+			status.IsolatedKey, config.IsolatedKey, config.DisplayName)
+		log.Functionf(str)
+		needRegeneration = true
+		regenerationReason += str + "\n"
+	}
 
 	log.Functionf("quantifyChanges for %s %s returns %v, %s",
 		config.Key(), config.DisplayName, needRegeneration, regenerationReason)
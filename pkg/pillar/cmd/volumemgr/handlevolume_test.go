@@ -8,9 +8,11 @@ import (
 	"testing"
 	"time"
 
+	zconfig "github.com/lf-edge/eve/api/go/config"
 	"github.com/lf-edge/eve/pkg/pillar/base"
 	"github.com/lf-edge/eve/pkg/pillar/pubsub"
 	"github.com/lf-edge/eve/pkg/pillar/types"
+	uuid "github.com/satori/go.uuid"
 	"github.com/sirupsen/logrus"
 )
 
@@ -51,3 +53,27 @@ func initStatusCtx(t *testing.T) volumemgrContext {
 	ctx.pubVolumeStatus = pubVolumeStatus
 	return ctx
 }
+
+func TestIsolatedKeyVolumeNames(t *testing.T) {
+	status := types.VolumeStatus{
+		VolumeID:          uuid.FromStringOrNil("e1b8b0a1-6a3a-4c2a-9d0c-1b3f5e0c9a11"),
+		GenerationCounter: 2,
+		Encrypted:         true,
+		IsolatedKey:       true,
+		ContentFormat:     zconfig.Format_QCOW2,
+	}
+	assert.Equal(t, types.VolumeEncryptedDirName+"/e1b8b0a1-6a3a-4c2a-9d0c-1b3f5e0c9a11#2.raw",
+		luksContainerPath(status))
+	assert.Equal(t, "/dev/mapper/eve-volume-e1b8b0a1-6a3a-4c2a-9d0c-1b3f5e0c9a11.2",
+		luksDevice(status.Key()))
+
+	//volume is raw inside of LUKS container, so the file name stays the same
+	updateStatusByPersistType(&status, types.PersistExt4)
+	assert.Equal(t, zconfig.Format_RAW, status.ContentFormat)
+	assert.Equal(t, luksContainerPath(status), status.PathName())
+
+	status.IsolatedKey = false
+	status.ContentFormat = zconfig.Format_QCOW2
+	updateStatusByPersistType(&status, types.PersistExt4)
+	assert.Equal(t, zconfig.Format_QCOW2, status.ContentFormat)
+}
@@ -13,9 +13,10 @@ import (
 )
 
 const (
-	workCreate  = "create"
-	workIngest  = "ingest"
-	workPrepare = "prepare"
+	workCreate   = "create"
+	workIngest   = "ingest"
+	workPrepare  = "prepare"
+	workSnapshot = "snapshot"
 )
 
 // volumeWorkDescription volume creation/deletion work we feed into the worker go routine.
@@ -38,6 +39,14 @@ type casIngestWorkDescription struct {
 	loaded []string
 }
 
+// volumeSnapshotWorkDescription local snapshot work we feed into the worker go routine
+type volumeSnapshotWorkDescription struct {
+	status  types.VolumeStatus
+	counter uint32
+	// Used for results
+	snapshotTime time.Time
+}
+
 // What we track for the result
 type volumeWorkResult struct {
 	worker.WorkResult // Error etc
@@ -111,6 +120,30 @@ func AddWorkPrepare(ctx *volumemgrContext, status *types.VolumeStatus) {
 	}
 }
 
+// AddWorkSnapshot adds a Work job to take a local snapshot of a volume
+func AddWorkSnapshot(ctx *volumemgrContext, status *types.VolumeStatus, counter uint32) {
+	d := volumeSnapshotWorkDescription{
+		status:  *status,
+		counter: counter,
+	}
+	w := worker.Work{Kind: workSnapshot, Key: snapshotWorkKey(status.Key()), Description: d}
+	// Don't fail on errors to make idempotent (Submit returns an error if
+	// the work was already submitted)
+	done, err := ctx.worker.TrySubmit(w)
+	if err != nil {
+		log.Errorf("TrySubmit %s failed: %s", status.Key(), err)
+	} else if !done {
+		log.Fatalf("Failed to submit work due to queue length for %s",
+			status.Key())
+	}
+}
+
+// snapshotWorkKey returns the key of snapshot work, which must differ
+// from the key of create and destroy work of the volume
+func snapshotWorkKey(volumeKey string) string {
+	return workSnapshot + "-" + volumeKey
+}
+
 // DeleteWorkCreate is called by user when work is done
 func DeleteWorkCreate(ctx *volumemgrContext, status *types.VolumeStatus) {
 	ctx.worker.Cancel(status.Key())
@@ -258,6 +291,23 @@ func volumePrepareWorker(ctxPtr interface{}, w worker.Work) worker.WorkResult {
 	return result
 }
 
+// volumeSnapshotWorker implementation of work.WorkFunction that takes a local snapshot of a volume
+func volumeSnapshotWorker(ctxPtr interface{}, w worker.Work) worker.WorkResult {
+	ctx := ctxPtr.(*volumemgrContext)
+	d := w.Description.(volumeSnapshotWorkDescription)
+	snapshotTime, err := snapshotVolume(ctx, d.status, d.counter)
+	d.snapshotTime = snapshotTime
+	result := worker.WorkResult{
+		Key:         w.Key,
+		Description: d,
+	}
+	if err != nil {
+		result.Error = err
+		result.ErrorTime = time.Now()
+	}
+	return result
+}
+
 // processVolumeWorkResult handle the work result that was a volume action
 func processVolumeWorkResult(ctxPtr interface{}, res worker.WorkResult) error {
 	ctx := ctxPtr.(*volumemgrContext)
@@ -293,6 +343,37 @@ func processVolumePrepareResult(ctxPtr interface{}, res worker.WorkResult) error
 	return nil
 }
 
+// processVolumeSnapshotResult handle the work result that was a volume snapshot action
+func processVolumeSnapshotResult(ctxPtr interface{}, res worker.WorkResult) error {
+	ctx := ctxPtr.(*volumemgrContext)
+	d := res.Description.(volumeSnapshotWorkDescription)
+	// nobody looks the result up later
+	ctx.worker.Pop(res.Key)
+	status := lookupVolumeStatus(ctx, d.status.Key())
+	if status == nil {
+		log.Functionf("processVolumeSnapshotResult for %v, VolumeStatus not found", d.status.Key())
+		return nil
+	}
+	status.LocalSnapshotInprogress = false
+	status.LocalSnapshotCounter = d.counter
+	if res.Error != nil {
+		log.Errorf("processVolumeSnapshotResult(%s): snapshot %d failed: %v",
+			status.Key(), d.counter, res.Error)
+		status.LocalSnapshotError = res.Error.Error()
+	} else {
+		log.Noticef("processVolumeSnapshotResult(%s): snapshot %d taken",
+			status.Key(), d.counter)
+		status.LocalSnapshotError = ""
+		status.LocalSnapshotTime = d.snapshotTime
+	}
+	publishVolumeStatus(ctx, status)
+	// another snapshot may have been requested meanwhile
+	if config := lookupVolumeConfig(ctx, status.Key()); config != nil {
+		maybeSnapshotVolume(ctx, status, config.LocalSnapshotCounter)
+	}
+	return nil
+}
+
 // processCasIngestWorkResult handle the work result that was a cas ingestion
 func processCasIngestWorkResult(ctxPtr interface{}, res worker.WorkResult) error {
 	ctx := ctxPtr.(*volumemgrContext)
@@ -69,7 +69,13 @@ func gcObjects(ctx *volumemgrContext, dirName string) {
 			if format == "CONTAINER" {
 				_ = ctx.casClient.RemoveContainerRootDir(filelocation)
 			} else {
+				if err := closeLuksContainer(key); err != nil {
+					log.Errorf("gcObjects: %v", err)
+				}
 				deleteFile(filelocation)
+				_ = destroyVolumeKey(key)
+				removeLocalSnapshotFiles(
+					localSnapshotDirName(dirName == volumeEncryptedDirName), key, "")
 			}
 		}
 	}
@@ -112,7 +118,9 @@ func gcDatasets(ctx *volumemgrContext, dataset string) {
 			if err != nil {
 				log.Errorf("gcDatasets: DestroyDataset '%s' failed: %v output:%s",
 					location, err, output)
+				continue
 			}
+			_ = destroyVolumeKey(key)
 		}
 	}
 	log.Tracef("gcDatasets(%s) Done", dataset)
@@ -170,6 +178,13 @@ func gcPendingCreateVolume(ctx *volumemgrContext) {
 					}
 				}
 			default:
+				if vcp.IsolatedKey {
+					vcp.ContentFormat = zconfig.Format_RAW
+					if err := closeLuksContainer(vcp.Key()); err != nil {
+						log.Errorf("gcPendingCreateVolume: %v", err)
+						continue
+					}
+				}
 				// check if file accessible
 				// assume that we should remove it as not created completely
 				if _, err := os.Stat(vcp.PathName()); err == nil {
@@ -180,6 +195,11 @@ func gcPendingCreateVolume(ctx *volumemgrContext) {
 				}
 			}
 		}
+		if vcp.IsolatedKey && !vcp.IsContainer() {
+			if err := destroyVolumeKey(vcp.Key()); err != nil {
+				continue
+			}
+		}
 		if err := ctx.pubVolumeCreatePending.Unpublish(vcp.Key()); err != nil {
 			log.Errorf("gcPendingCreateVolume: cannot unpublish %s: %s", vcp.Key(), err)
 		}
@@ -0,0 +1,223 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Volumes with IsolatedKey are encrypted with their own key instead of
+// the key of the vault: zvols are zfs encryption roots, and file-backed
+// volumes are LUKS containers. The key is shared by volumes of the same
+// app instance, and sealed under the vault PCR policy (see vault.CreateVolumeKey).
+// The key is removed along with the last volume of the app instance.
+
+package volumemgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	zconfig "github.com/lf-edge/eve/api/go/config"
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/lf-edge/eve/pkg/pillar/diskmetrics"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	"github.com/lf-edge/eve/pkg/pillar/vault"
+	"github.com/lf-edge/eve/pkg/pillar/zfs"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	cryptsetupBinary = "/sbin/cryptsetup"
+	//luksHeaderSize is the default size of LUKS2 header, which is
+	//added to the size of the volume
+	luksHeaderSize = 16 << 20
+	//luksMapperPrefix is prepended to the names of device mapper devices
+	luksMapperPrefix = "eve-volume-"
+	luksMapperDir    = "/dev/mapper"
+)
+
+//luksContainerPath returns the path of the LUKS container of the volume.
+//The volume is always raw inside of the container.
+func luksContainerPath(status types.VolumeStatus) string {
+	status.ContentFormat = zconfig.Format_RAW
+	return status.PathName()
+}
+
+//luksMapperName returns the name of device mapper device for the volume
+//with volumeKey, which is VolumeStatus.Key()
+func luksMapperName(volumeKey string) string {
+	return luksMapperPrefix + strings.ReplaceAll(volumeKey, "#", ".")
+}
+
+//luksDevice returns the path of device mapper device for the volume
+func luksDevice(volumeKey string) string {
+	return filepath.Join(luksMapperDir, luksMapperName(volumeKey))
+}
+
+//withVolumeKey stages the key of the volume, creating it if create is set,
+//and calls f with the name of the staged key file
+func withVolumeKey(status types.VolumeStatus, create bool, f func(keyFile string) error) error {
+	volumeKey := status.Key()
+	var key []byte
+	var err error
+	if create {
+		appInstance := ""
+		if status.AppInstanceID != uuid.Nil {
+			appInstance = status.AppInstanceID.String()
+		}
+		key, err = vault.CreateVolumeKey(volumeKey, appInstance)
+	} else {
+		key, err = vault.GetVolumeKey(volumeKey)
+	}
+	if err != nil {
+		return err
+	}
+	keyFile, err := vault.StageVolumeKey(volumeKey, key)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := vault.UnstageVolumeKey(keyFile); err != nil {
+			log.Errorf("withVolumeKey(%s): failed to unstage key: %v", volumeKey, err)
+		}
+	}()
+	return f(keyFile)
+}
+
+//createEncryptedZVol creates zvol for the volume with a new key
+func createEncryptedZVol(status types.VolumeStatus, size uint64) error {
+	zVolName := status.ZVolName()
+	return withVolumeKey(status, true, func(keyFile string) error {
+		if stdoutStderr, err := zfs.CreateEncryptedVolumeDataset(log, zVolName, size,
+			"zstd", keyFile); err != nil {
+			return fmt.Errorf("error creating encrypted zfs zvol at %s, error=%v, output=%s",
+				zVolName, err, stdoutStderr)
+		}
+		return nil
+	})
+}
+
+//createLuksVolume creates LUKS container for the volume with a new key,
+//opens it and rolls out the image from ref, if any. It returns the path
+//of the opened device.
+func createLuksVolume(createContext context.Context, ctx *volumemgrContext,
+	status types.VolumeStatus, ref string) (string, error) {
+
+	containerPath := luksContainerPath(status)
+	if _, err := os.Stat(containerPath); err == nil {
+		return "", fmt.Errorf("can not create %s for %s: exists",
+			containerPath, status.Key())
+	}
+	size := status.MaxVolSize
+	pathToFile := ""
+	if ref != "" {
+		var err error
+		pathToFile, err = getVolumeFilePath(ctx, status)
+		if err != nil {
+			return "", fmt.Errorf("error obtaining file for volume %s, error=%v",
+				status.Key(), err)
+		}
+		size, _, err = checkResizeDisk(pathToFile, status.MaxVolSize)
+		if err != nil {
+			return "", fmt.Errorf("error obtaining size of %s, error=%v",
+				pathToFile, err)
+		}
+	}
+	f, err := os.Create(containerPath)
+	if err != nil {
+		return "", err
+	}
+	err = f.Truncate(int64(size) + luksHeaderSize)
+	f.Close()
+	if err != nil {
+		return "", err
+	}
+	device := luksDevice(status.Key())
+	err = withVolumeKey(status, true, func(keyFile string) error {
+		if output, err := base.Exec(log, cryptsetupBinary, "luksFormat", "--batch-mode",
+			"--type", "luks2", "--key-file", keyFile, containerPath).CombinedOutput(); err != nil {
+			return fmt.Errorf("luksFormat %s failed: %v, output=%s", containerPath, err, output)
+		}
+		return openLuksContainer(containerPath, status.Key(), keyFile)
+	})
+	if err != nil {
+		return "", err
+	}
+	if pathToFile != "" {
+		if err := diskmetrics.RolloutImgToNonZeroBlock(createContext, log, pathToFile,
+			device, "raw"); err != nil {
+			return device, fmt.Errorf("error converting %s to %s: %v",
+				pathToFile, device, err)
+		}
+	}
+	return device, nil
+}
+
+func openLuksContainer(containerPath, volumeKey, keyFile string) error {
+	output, err := base.Exec(log, cryptsetupBinary, "open", "--type", "luks2",
+		"--key-file", keyFile, containerPath, luksMapperName(volumeKey)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("open %s failed: %v, output=%s", containerPath, err, output)
+	}
+	return nil
+}
+
+func closeLuksContainer(volumeKey string) error {
+	if _, err := os.Stat(luksDevice(volumeKey)); os.IsNotExist(err) {
+		return nil
+	}
+	output, err := base.Exec(log, cryptsetupBinary, "close",
+		luksMapperName(volumeKey)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("close %s failed: %v, output=%s",
+			luksMapperName(volumeKey), err, output)
+	}
+	return nil
+}
+
+//loadVolumeKey makes the existing volume with IsolatedKey accessible after
+//reboot, and returns its device
+func loadVolumeKey(ctx *volumemgrContext, status types.VolumeStatus) (string, error) {
+	if ctx.persistType == types.PersistZFS {
+		zVolName := status.ZVolName()
+		err := withVolumeKey(status, false, func(keyFile string) error {
+			if stdoutStderr, err := zfs.LoadDatasetKey(log, zVolName, keyFile); err != nil {
+				return fmt.Errorf("error loading key for zfs zvol %s, error=%v, output=%s",
+					zVolName, err, stdoutStderr)
+			}
+			return nil
+		})
+		return zfs.GetZVolDeviceByDataset(zVolName), err
+	}
+	device := luksDevice(status.Key())
+	if _, err := os.Stat(device); err == nil {
+		return device, nil
+	}
+	err := withVolumeKey(status, false, func(keyFile string) error {
+		return openLuksContainer(luksContainerPath(status), status.Key(), keyFile)
+	})
+	return device, err
+}
+
+//destroyLuksVolume closes and removes LUKS container of the volume
+func destroyLuksVolume(status types.VolumeStatus) error {
+	if err := closeLuksContainer(status.Key()); err != nil {
+		return err
+	}
+	if err := os.Remove(luksContainerPath(status)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+//destroyVolumeKey releases the key of the volume, the key of the app
+//instance is removed once no volume uses it
+func destroyVolumeKey(volumeKey string) error {
+	if err := vault.DestroyVolumeKey(volumeKey); err != nil {
+		errStr := fmt.Sprintf("Error destroying key of %s: %v", volumeKey, err)
+		log.Error(errStr)
+		return errors.New(errStr)
+	}
+	log.Functionf("Destroyed key of volume %s", volumeKey)
+	return nil
+}
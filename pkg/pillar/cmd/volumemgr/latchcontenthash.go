@@ -7,6 +7,8 @@
 package volumemgr
 
 import (
+	"strings"
+
 	"github.com/lf-edge/eve/pkg/pillar/types"
 	"github.com/lf-edge/eve/pkg/pillar/utils"
 	uuid "github.com/satori/go.uuid"
@@ -42,6 +44,69 @@ func latchContentTreeHash(ctx *volumemgrContext, contentID uuid.UUID,
 	log.Functionf("latchContentTreeHash(%s, %s, %d) done", contentID, hash, generationCounter)
 }
 
+// latchContentTreeSignature records that the signature of the image with
+// hash is verified, creating the mapping if needed
+func latchContentTreeSignature(ctx *volumemgrContext, contentID uuid.UUID,
+	hash string, generationCounter uint32) {
+
+	log.Functionf("latchContentTreeSignature(%s, %s, %d)", contentID, hash, generationCounter)
+	aih := types.AppAndImageToHash{
+		ImageID:      contentID,
+		Hash:         hash,
+		PurgeCounter: generationCounter,
+	}
+	item, _ := ctx.pubContentTreeToHash.Get(aih.Key())
+	if item != nil {
+		old := item.(types.AppAndImageToHash)
+		if old.Hash == aih.Hash && old.SignatureVerified {
+			return
+		}
+	}
+	aih.SignatureVerified = true
+	ctx.pubContentTreeToHash.Publish(aih.Key(), aih)
+	log.Functionf("latchContentTreeSignature(%s, %s, %d) done", contentID, hash, generationCounter)
+}
+
+// unlatchContentTreeSignature forgets that the signature was verified, but
+// keeps the latched hash
+func unlatchContentTreeSignature(ctx *volumemgrContext, contentID uuid.UUID,
+	generationCounter uint32) {
+
+	aih := types.AppAndImageToHash{
+		ImageID:      contentID,
+		PurgeCounter: generationCounter,
+	}
+	item, _ := ctx.pubContentTreeToHash.Get(aih.Key())
+	if item == nil {
+		return
+	}
+	aih = item.(types.AppAndImageToHash)
+	if !aih.SignatureVerified {
+		return
+	}
+	log.Functionf("unlatchContentTreeSignature(%s, %d)", contentID, generationCounter)
+	aih.SignatureVerified = false
+	ctx.pubContentTreeToHash.Publish(aih.Key(), aih)
+}
+
+// Returns true if the signature of the image with status.ContentSha256
+// was verified
+func lookupLatchContentTreeSignature(ctx *volumemgrContext,
+	status *types.ContentTreeStatus) bool {
+
+	temp := types.AppAndImageToHash{
+		ImageID:      status.ContentID,
+		PurgeCounter: uint32(status.GenerationCounter),
+	}
+	item, _ := ctx.pubContentTreeToHash.Get(temp.Key())
+	if item == nil {
+		return false
+	}
+	aih := item.(types.AppAndImageToHash)
+	return aih.SignatureVerified &&
+		strings.EqualFold(aih.Hash, status.ContentSha256)
+}
+
 // Delete for a specific content tree
 func deleteLatchContentTreeHash(ctx *volumemgrContext,
 	contentID uuid.UUID, generationCounter uint32) {
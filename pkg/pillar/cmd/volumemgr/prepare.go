@@ -74,6 +74,13 @@ func prepareZVol(ctx *volumemgrContext, status types.VolumeStatus) error {
 			return errors.New(errStr)
 		}
 	}
+	if status.IsolatedKey {
+		if err := createEncryptedZVol(status, size); err != nil {
+			log.Error(err)
+			return err
+		}
+		return nil
+	}
 	zVolName := status.ZVolName()
 	if stdoutStderr, err := zfs.CreateVolumeDataset(log, zVolName, size, "zstd"); err != nil {
 		errStr := fmt.Sprintf("Error creating zfs zvol at %s, error=%v, output=%s",
@@ -0,0 +1,122 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Verification of the signature of container images. The resolver in
+// downloader verifies the signature together with resolving the tag; if the
+// sha is already known we ask the resolver to only verify the signature.
+
+package volumemgr
+
+import (
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	"github.com/lf-edge/eve/pkg/pillar/utils"
+)
+
+// signatureResolveConfig returns the ResolveConfig to verify the signature
+// of the image with the known sha
+func signatureResolveConfig(status types.ContentTreeStatus) types.ResolveConfig {
+	return types.ResolveConfig{
+		DatastoreID:   status.DatastoreID,
+		Name:          utils.MaybeInsertSha(status.RelativeURL, status.ContentSha256),
+		Counter:       uint32(status.GenerationCounter),
+		SignatureKeys: status.SignatureKeys,
+	}
+}
+
+// checkContentTreeSignature makes sure that the signature of the image is
+// verified before we download it.
+// Returns changed and whether the signature is verified.
+func checkContentTreeSignature(ctx *volumemgrContext, status *types.ContentTreeStatus) (bool, bool) {
+
+	if lookupLatchContentTreeSignature(ctx, status) {
+		log.Functionf("ContentTree(%s) %s signature verified before",
+			status.ContentID, status.DisplayName)
+		status.SignatureVerified = true
+		return true, true
+	}
+	rc := signatureResolveConfig(*status)
+	rs := lookupResolveStatus(ctx, rc.Key())
+	if rs != nil && !signatureKeysEqual(rs.SignatureKeys, status.SignatureKeys) {
+		// the status is for the keys used before they changed
+		rs = nil
+	}
+	if rs == nil {
+		if status.HasResolverRef {
+			return false, false
+		}
+		log.Functionf("Verifying signature of content tree %s", status.ContentID)
+		status.HasResolverRef = true
+		publishResolveConfig(ctx, &rc)
+		return true, false
+	}
+	if rs.HasError() {
+		log.Errorf("Received error from resolver verifying signature of %s: %s",
+			rc.Key(), rs.Error)
+		status.SignatureError = rs.SignatureError
+		status.SetErrorWithSourceAndDescription(rs.ErrorDescription, types.ResolveStatus{})
+		return true, false
+	}
+	if !rs.SignatureVerified {
+		// not yet processed by the resolver
+		return false, false
+	}
+	if status.IsErrorSource(types.ResolveStatus{}) {
+		log.Functionf("Clearing resolver error %s", status.Error)
+		status.ClearErrorWithSource()
+	}
+	setContentTreeSignatureVerified(ctx, status)
+	deleteResolveConfig(ctx, rc.Key())
+	return true, true
+}
+
+// setContentTreeSignatureVerified records that the signature of the image
+// with status.ContentSha256 is verified
+func setContentTreeSignatureVerified(ctx *volumemgrContext, status *types.ContentTreeStatus) {
+	log.Noticef("ContentTree(%s) %s signature of %s verified",
+		status.ContentID, status.DisplayName, status.ContentSha256)
+	status.SignatureVerified = true
+	status.SignatureError = ""
+	status.HasResolverRef = false
+	latchContentTreeSignature(ctx, status.ContentID, status.ContentSha256,
+		uint32(status.GenerationCounter))
+}
+
+// updateContentTreeSignatureKeys sets new signature keys on the content
+// tree. The result of a previous verification does not apply to them,
+// hence it is dropped and the signature is verified again.
+func updateContentTreeSignatureKeys(ctx *volumemgrContext,
+	status *types.ContentTreeStatus, keys []string) {
+
+	log.Noticef("ContentTree(%s) %s signature keys changed",
+		status.ContentID, status.DisplayName)
+	if status.HasResolverRef {
+		if status.ContentSha256 == "" {
+			// resolving the tag, resolve it again with the new keys
+			deleteResolveConfig(ctx, status.ResolveKey())
+		} else {
+			deleteResolveConfig(ctx, signatureResolveConfig(*status).Key())
+		}
+		status.HasResolverRef = false
+	}
+	status.SignatureKeys = keys
+	status.SignatureVerified = false
+	status.SignatureError = ""
+	if status.IsErrorSource(types.ResolveStatus{}) {
+		status.ClearErrorWithSource()
+	}
+	unlatchContentTreeSignature(ctx, status.ContentID,
+		uint32(status.GenerationCounter))
+}
+
+// signatureKeysEqual returns true if both lists hold the same keys
+func signatureKeysEqual(keys1, keys2 []string) bool {
+	if len(keys1) != len(keys2) {
+		return false
+	}
+	for i := range keys1 {
+		if keys1[i] != keys2[i] {
+			return false
+		}
+	}
+	return true
+}
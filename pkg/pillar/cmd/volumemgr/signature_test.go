@@ -0,0 +1,129 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package volumemgr
+
+import (
+	"testing"
+
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/lf-edge/eve/pkg/pillar/pubsub"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	uuid "github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// resolveStatusSub stands in for the ResolveStatus subscription from
+// downloader
+type resolveStatusSub struct {
+	pubsub.Subscription
+	items map[string]types.ResolveStatus
+}
+
+func (sub *resolveStatusSub) Get(key string) (interface{}, error) {
+	rs, ok := sub.items[key]
+	if !ok {
+		return nil, nil
+	}
+	return rs, nil
+}
+
+func initSignatureCtx(t *testing.T) (*volumemgrContext, *resolveStatusSub) {
+	logger := logrus.StandardLogger()
+	log = base.NewSourceLogObject(logger, "test", 1234)
+	ps := pubsub.New(&pubsub.EmptyDriver{}, logger, log)
+
+	pubResolveConfig, err := ps.NewPublication(pubsub.PublicationOptions{
+		AgentName: agentName,
+		TopicType: types.ResolveConfig{},
+	})
+	assert.Nil(t, err)
+	pubContentTreeToHash, err := ps.NewPublication(pubsub.PublicationOptions{
+		AgentName: agentName,
+		TopicType: types.AppAndImageToHash{},
+	})
+	assert.Nil(t, err)
+	sub := &resolveStatusSub{items: make(map[string]types.ResolveStatus)}
+	ctx := &volumemgrContext{
+		pubResolveConfig:     pubResolveConfig,
+		pubContentTreeToHash: pubContentTreeToHash,
+		subResolveStatus:     sub,
+	}
+	return ctx, sub
+}
+
+// TestSignatureKeysModify checks that the signature is verified again
+// when the signature keys of the content tree change
+func TestSignatureKeysModify(t *testing.T) {
+	ctx, sub := initSignatureCtx(t)
+	status := &types.ContentTreeStatus{
+		ContentID:     uuid.FromStringOrNil("9b2a3a2e-3c1f-4b4e-8f0a-2d3c4b5a6f70"),
+		DatastoreID:   uuid.FromStringOrNil("0c5d6e7f-8091-4a2b-9c3d-4e5f60718293"),
+		RelativeURL:   "lfedge/eve-app:1.0",
+		ContentSha256: "4f7a5e8ba6b6d1d4c0b8e6f3a2c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3",
+		SignatureKeys: []string{"key1"},
+	}
+
+	// ask the resolver to verify with key1
+	changed, verified := checkContentTreeSignature(ctx, status)
+	assert.True(t, changed)
+	assert.False(t, verified)
+	assert.True(t, status.HasResolverRef)
+	rcKey := signatureResolveConfig(*status).Key()
+	rc := lookupResolveConfig(ctx, rcKey)
+	assert.NotNil(t, rc)
+	assert.Equal(t, []string{"key1"}, rc.SignatureKeys)
+
+	// key1 is replaced while the verification is pending
+	updateContentTreeSignatureKeys(ctx, status, []string{"key2"})
+	assert.Nil(t, lookupResolveConfig(ctx, rcKey))
+	assert.False(t, status.HasResolverRef)
+	assert.Equal(t, []string{"key2"}, status.SignatureKeys)
+
+	// the resolver reports the result for key1 which does not apply
+	sub.items[rcKey] = types.ResolveStatus{
+		DatastoreID:       status.DatastoreID,
+		Name:              rc.Name,
+		SignatureVerified: true,
+		SignatureKeys:     []string{"key1"},
+	}
+	_, verified = checkContentTreeSignature(ctx, status)
+	assert.False(t, verified)
+	assert.False(t, status.SignatureVerified)
+	rc = lookupResolveConfig(ctx, rcKey)
+	assert.NotNil(t, rc)
+	assert.Equal(t, []string{"key2"}, rc.SignatureKeys)
+
+	// verified with key2
+	sub.items[rcKey] = types.ResolveStatus{
+		DatastoreID:       status.DatastoreID,
+		Name:              rc.Name,
+		SignatureVerified: true,
+		SignatureKeys:     []string{"key2"},
+	}
+	_, verified = checkContentTreeSignature(ctx, status)
+	assert.True(t, verified)
+	assert.True(t, status.SignatureVerified)
+	assert.True(t, lookupLatchContentTreeSignature(ctx, status))
+	assert.Nil(t, lookupResolveConfig(ctx, rcKey))
+
+	// key2 is rotated to key3 after the verification, the latched result
+	// is dropped and the signature is verified again
+	updateContentTreeSignatureKeys(ctx, status, []string{"key3"})
+	assert.False(t, status.SignatureVerified)
+	assert.False(t, lookupLatchContentTreeSignature(ctx, status))
+	sub.items[rcKey] = types.ResolveStatus{
+		DatastoreID:    status.DatastoreID,
+		Name:           rc.Name,
+		SignatureError: "not signed by key3",
+		SignatureKeys:  []string{"key3"},
+		ErrorAndTime: types.ErrorAndTime{
+			ErrorDescription: types.ErrorDescription{Error: "not signed by key3"},
+		},
+	}
+	_, verified = checkContentTreeSignature(ctx, status)
+	assert.False(t, verified)
+	assert.Equal(t, "not signed by key3", status.SignatureError)
+	assert.True(t, status.HasError())
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Snapshots of volumes requested via local profile server. Only the last
+// snapshot of a volume is kept: zvols get a zfs snapshot, file-backed
+// volumes are copied into a qcow2 file in the snapshot directory.
+
+package volumemgr
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lf-edge/eve/pkg/pillar/diskmetrics"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	"github.com/lf-edge/eve/pkg/pillar/zfs"
+)
+
+// localSnapshotPrefix is the prefix of names of zfs snapshots
+const localSnapshotPrefix = "local-"
+
+// localSnapshotDirName returns the directory for snapshots of file-backed volumes
+func localSnapshotDirName(encrypted bool) string {
+	if encrypted {
+		return types.VolumeEncryptedSnapshotDirName
+	}
+	return types.VolumeClearSnapshotDirName
+}
+
+// localSnapshotFile returns the path of the snapshot of file-backed volume
+func localSnapshotFile(status types.VolumeStatus, counter uint32) string {
+	return filepath.Join(localSnapshotDirName(status.Encrypted),
+		fmt.Sprintf("%s.%d.qcow2", status.Key(), counter))
+}
+
+// localSnapshotName returns the name of zfs snapshot of zvol
+func localSnapshotName(zVolName string, counter uint32) string {
+	return zVolName + "@" + localSnapshotPrefix + strconv.FormatUint(uint64(counter), 10)
+}
+
+// isZVol returns true if the volume is a zvol, false if it is file-backed
+func isZVol(ctx *volumemgrContext, status types.VolumeStatus) (bool, error) {
+	if status.FileLocation == "" {
+		return false, fmt.Errorf("no FileLocation for %s", status.Key())
+	}
+	info, err := os.Stat(status.FileLocation)
+	if err != nil {
+		return false, err
+	}
+	if info.Mode()&os.ModeDevice == 0 {
+		return false, nil
+	}
+	if ctx.persistType != types.PersistZFS {
+		// e.g. opened LUKS container of a volume with isolated key
+		return false, fmt.Errorf("snapshots of volumes backed by block device %s are not supported on %s",
+			status.FileLocation, ctx.persistType)
+	}
+	return true, nil
+}
+
+// maybeSnapshotVolume submits a work to take a snapshot of the volume
+// if one was requested via local profile server and is not taken yet.
+func maybeSnapshotVolume(ctx *volumemgrContext, status *types.VolumeStatus, counter uint32) {
+	if counter == status.LocalSnapshotCounter || status.LocalSnapshotInprogress ||
+		status.State != types.CREATED_VOLUME {
+		return
+	}
+	log.Noticef("maybeSnapshotVolume(%s): snapshot %d requested for %s",
+		status.Key(), counter, status.DisplayName)
+	status.LocalSnapshotInprogress = true
+	publishVolumeStatus(ctx, status)
+	AddWorkSnapshot(ctx, status, counter)
+}
+
+// lookupLocalSnapshotTime returns the time when the snapshot with counter
+// was taken, or an error if the volume has no such snapshot
+func lookupLocalSnapshotTime(ctx *volumemgrContext, status types.VolumeStatus, counter uint32) (time.Time, error) {
+	zvol, err := isZVol(ctx, status)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if zvol {
+		snapshot := localSnapshotName(status.ZVolName(), counter)
+		creation, err := zfs.GetDatasetOption(log, snapshot, "creation")
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%s: %v", snapshot, err)
+		}
+		sec, err := strconv.ParseInt(creation, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(sec, 0), nil
+	}
+	info, err := os.Stat(localSnapshotFile(status, counter))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// snapshotVolume takes the snapshot with counter and removes the previous
+// snapshots of the volume. Returns the time of the snapshot.
+func snapshotVolume(ctx *volumemgrContext, status types.VolumeStatus, counter uint32) (time.Time, error) {
+	if status.IsContainer() {
+		return time.Time{}, fmt.Errorf("snapshots of container volumes are not supported")
+	}
+	zvol, err := isZVol(ctx, status)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if zvol {
+		return snapshotZVol(status, counter)
+	}
+	return snapshotVdiskFile(status, counter)
+}
+
+func snapshotZVol(status types.VolumeStatus, counter uint32) (time.Time, error) {
+	zVolName := status.ZVolName()
+	snapshot := localSnapshotName(zVolName, counter)
+	if stdoutStderr, err := zfs.CreateSnapshot(log, snapshot); err != nil {
+		return time.Time{}, fmt.Errorf("cannot create snapshot %s: %v, output=%s",
+			snapshot, err, stdoutStderr)
+	}
+	snapshotTime := time.Now()
+	snapshots, err := zfs.GetSnapshots(log, zVolName)
+	if err != nil {
+		log.Errorf("snapshotZVol(%s): %v", status.Key(), err)
+		return snapshotTime, nil
+	}
+	for _, s := range snapshots {
+		if s == snapshot || !strings.HasPrefix(s, zVolName+"@"+localSnapshotPrefix) {
+			continue
+		}
+		if stdoutStderr, err := zfs.DestroyDataset(log, s); err != nil {
+			log.Errorf("snapshotZVol(%s): cannot destroy snapshot %s: %v, output=%s",
+				status.Key(), s, err, stdoutStderr)
+		}
+	}
+	return snapshotTime, nil
+}
+
+func snapshotVdiskFile(status types.VolumeStatus, counter uint32) (time.Time, error) {
+	dirName := localSnapshotDirName(status.Encrypted)
+	if err := os.MkdirAll(dirName, 0700); err != nil {
+		return time.Time{}, err
+	}
+	filename := localSnapshotFile(status, counter)
+	tmpFilename := filename + ".tmp"
+	os.Remove(tmpFilename)
+	if err := diskmetrics.CopyImg(context.Background(), log, status.FileLocation,
+		tmpFilename); err != nil {
+		os.Remove(tmpFilename)
+		return time.Time{}, err
+	}
+	if err := os.Rename(tmpFilename, filename); err != nil {
+		os.Remove(tmpFilename)
+		return time.Time{}, err
+	}
+	removeLocalSnapshotFiles(dirName, status.Key(), filename)
+	return time.Now(), nil
+}
+
+// removeLocalSnapshotFiles removes snapshots of the file-backed volume
+// with volumeKey from dirName, except for keep
+func removeLocalSnapshotFiles(dirName, volumeKey, keep string) {
+	files, err := filepath.Glob(filepath.Join(dirName, volumeKey+".*"))
+	if err != nil {
+		log.Errorf("removeLocalSnapshotFiles(%s): %v", volumeKey, err)
+		return
+	}
+	for _, f := range files {
+		if f != keep {
+			deleteFile(f)
+		}
+	}
+}
@@ -32,10 +32,26 @@ func doUpdateContentTree(ctx *volumemgrContext, status *types.ContentTreeStatus)
 			return false, false
 		}
 
+		if len(status.SignatureKeys) != 0 && !status.IsOCIRegistry() {
+			errStr := fmt.Sprintf("Image signature verification is only supported for OCI registries, not %s",
+				status.DatastoreType)
+			if status.Error != errStr {
+				log.Error(errStr)
+				status.SetErrorWithSource(errStr, types.ContentTreeStatus{}, time.Now())
+				return true, false
+			}
+			return false, false
+		}
+
 		if status.IsOCIRegistry() {
 			maybeLatchContentTreeHash(ctx, status)
 			if status.ContentSha256 == "" {
 				rs := lookupResolveStatus(ctx, status.ResolveKey())
+				if rs != nil && !signatureKeysEqual(rs.SignatureKeys, status.SignatureKeys) {
+					log.Functionf("Ignoring ResolveStatus for %s with previous signature keys",
+						status.ContentID)
+					rs = nil
+				}
 				if rs == nil {
 					log.Functionf("Resolve status not found for %s",
 						status.ContentID)
@@ -51,6 +67,7 @@ func doUpdateContentTree(ctx *volumemgrContext, status *types.ContentTreeStatus)
 					errStr := fmt.Sprintf("Received error from resolver for %s, SHA (%s): %s",
 						status.ResolveKey(), rs.ImageSha256, rs.Error)
 					log.Error(errStr)
+					status.SignatureError = rs.SignatureError
 					status.SetErrorWithSourceAndDescription(rs.ErrorDescription, types.ResolveStatus{})
 					changed = true
 					return changed, false
@@ -77,6 +94,9 @@ func doUpdateContentTree(ctx *volumemgrContext, status *types.ContentTreeStatus)
 				latchContentTreeHash(ctx, status.ContentID,
 					status.ContentSha256, uint32(status.GenerationCounter))
 				maybeLatchContentTreeHash(ctx, status)
+				if len(status.SignatureKeys) != 0 && rs.SignatureVerified {
+					setContentTreeSignatureVerified(ctx, status)
+				}
 				deleteResolveConfig(ctx, rs.Key())
 				changed = true
 
@@ -88,6 +108,15 @@ func doUpdateContentTree(ctx *volumemgrContext, status *types.ContentTreeStatus)
 				changed = true
 			}
 
+			// do not download an image which is not signed as required
+			if len(status.SignatureKeys) != 0 && !status.SignatureVerified {
+				sigChanged, verified := checkContentTreeSignature(ctx, status)
+				if !verified {
+					return changed || sigChanged, false
+				}
+				changed = true
+			}
+
 			// at this point, we will have the hash of the root blob as status.ContentSha256,
 			// so we need to create the BlobStatus, if it does not exist already
 			rootBlob := lookupOrCreateBlobStatus(ctx, status.ContentSha256)
@@ -151,6 +180,16 @@ func doUpdateContentTree(ctx *volumemgrContext, status *types.ContentTreeStatus)
 			// these calls might update Blob.State hence we check
 			// sequentially
 			if blob.State <= types.DOWNLOADING {
+				// the blob is downloaded with the highest priority of the
+				// content trees using it
+				if blob.DownloadPriority < status.DownloadPriority {
+					blob.DownloadPriority = status.DownloadPriority
+					if blob.HasDownloaderRef {
+						updateDownloaderConfigPriority(ctx, *blob)
+					}
+					publishBlobStatus(ctx, blob)
+					changed = true
+				}
 				// any state less than downloaded, we ask for download, so that we have the refcount;
 				// downloadBlob() is smart enough to look for existing references
 				log.Tracef("doUpdateContentTree: blob sha %s download state %v less than DOWNLOADED", blob.Sha256, blob.State)
@@ -326,6 +365,17 @@ func doUpdateContentTree(ctx *volumemgrContext, status *types.ContentTreeStatus)
 		publishContentTreeStatus(ctx, status)
 	}
 
+	// the signature keys changed after the image was verified; check the
+	// signature again using the new keys
+	if status.IsOCIRegistry() && len(status.SignatureKeys) != 0 &&
+		!status.SignatureVerified {
+		sigChanged, verified := checkContentTreeSignature(ctx, status)
+		if !verified {
+			return changed || sigChanged, false
+		}
+		changed = true
+	}
+
 	// at this point, the image is VERIFIED or higher
 	if status.State == types.VERIFIED {
 		// we need to check root blob state to wait for another loading process if exists
@@ -538,6 +588,10 @@ func doUpdateVol(ctx *volumemgrContext, status *types.VolumeStatus) (bool, bool)
 			status.CurrentSize = ctStatus.CurrentSize
 			changed = true
 		}
+		if status.SignatureError != ctStatus.SignatureError {
+			status.SignatureError = ctStatus.SignatureError
+			changed = true
+		}
 		if status.State != ctStatus.State && status.State < types.CREATING_VOLUME {
 			status.State = ctStatus.State
 			changed = true
@@ -696,6 +750,9 @@ func doUpdateVol(ctx *volumemgrContext, status *types.VolumeStatus) (bool, bool)
 		if !status.HasError() {
 			status.State = types.CREATED_VOLUME
 			status.CreateTime = time.Now()
+			if status.IsolatedKey && !status.IsContainer() {
+				status.KeyState = types.VolumeKeyStateLoaded
+			}
 		}
 		changed = true
 		// Work is done
@@ -886,5 +943,10 @@ func updateStatusByPersistType(status *types.VolumeStatus, fsType types.PersistT
 	switch fsType {
 	case types.PersistZFS:
 		status.ContentFormat = zconfig.Format_RAW
+	default:
+		if status.IsolatedKey {
+			//raw inside of LUKS container
+			status.ContentFormat = zconfig.Format_RAW
+		}
 	}
 }
@@ -205,9 +205,10 @@ func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) in
 
 	// Create the background worker
 	ctx.worker = worker.NewPool(log, &ctx, 20, map[string]worker.Handler{
-		workCreate:  {Request: volumeWorker, Response: processVolumeWorkResult},
-		workIngest:  {Request: casIngestWorker, Response: processCasIngestWorkResult},
-		workPrepare: {Request: volumePrepareWorker, Response: processVolumePrepareResult},
+		workCreate:   {Request: volumeWorker, Response: processVolumeWorkResult},
+		workIngest:   {Request: casIngestWorker, Response: processCasIngestWorkResult},
+		workPrepare:  {Request: volumePrepareWorker, Response: processVolumePrepareResult},
+		workSnapshot: {Request: volumeSnapshotWorker, Response: processVolumeSnapshotResult},
 	})
 
 	// Set up our publications before the subscriptions so ctx is set
@@ -706,7 +707,7 @@ func handleZedAgentStatusImpl(ctxArg interface{}, key string,
 		return
 	}
 	switch status.ConfigGetStatus {
-	case types.ConfigGetSuccess, types.ConfigGetReadSaved:
+	case types.ConfigGetSuccess, types.ConfigGetReadSaved, types.ConfigGetLocalBundle:
 		ctx.usingConfig = true
 		duration := time.Duration(ctx.vdiskGCTime / 10)
 		ctx.gc = time.NewTicker(duration * time.Second)
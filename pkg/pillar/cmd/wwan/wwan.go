@@ -0,0 +1,219 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package wwan implements the wwan microservice, which manages cellular
+// modems according to WwanConfig published by nim and publishes WwanStatus,
+// WwanMetrics and WwanLocationInfo back to nim.
+package wwan
+
+import (
+	"flag"
+	"time"
+
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	generic "github.com/lf-edge/eve/pkg/pillar/dpcreconciler/genericitems"
+	"github.com/lf-edge/eve/pkg/pillar/pidfile"
+	"github.com/lf-edge/eve/pkg/pillar/pubsub"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	wwanmgr "github.com/lf-edge/eve/pkg/pillar/wwan"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	agentName = "wwan"
+	// Time limits for event loop handlers
+	errorTime            = 3 * time.Minute
+	warningTime          = 40 * time.Second
+	stillRunningInterval = 25 * time.Second
+
+	// How often to verify connectivity of cellular networks.
+	probeInterval = 5 * time.Minute
+	// How often to collect metrics from modems.
+	metricsInterval = time.Minute
+	// How often to check for new location info.
+	locationInterval = 5 * time.Second
+)
+
+var (
+	logger *logrus.Logger
+	log    *base.LogObject
+)
+
+type wwanContext struct {
+	subWwanConfig        pubsub.Subscription
+	pubWwanStatus        pubsub.Publication
+	pubWwanMetrics       pubsub.Publication
+	pubWwanLocationInfo  pubsub.Publication
+	manager              *wwanmgr.Manager
+	configCh             chan types.WwanConfig
+	lastPublishedLocInfo types.WwanLocationInfo
+	lastPublishedLocSet  bool
+}
+
+// Run - wwan microservice
+func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) int {
+	logger = loggerArg
+	log = logArg
+	debugPtr := flag.Bool("d", false, "Debug flag")
+	flag.Parse()
+	if *debugPtr {
+		logger.SetLevel(logrus.DebugLevel)
+	} else {
+		logger.SetLevel(logrus.InfoLevel)
+	}
+
+	if err := pidfile.CheckAndCreatePidfile(log, agentName); err != nil {
+		log.Fatal(err)
+	}
+
+	// Run a periodic timer so we always update StillRunning
+	stillRunning := time.NewTicker(stillRunningInterval)
+	ps.StillRunning(agentName, warningTime, errorTime)
+
+	ctx := &wwanContext{
+		manager: &wwanmgr.Manager{
+			Log:     log,
+			Backend: wwanmgr.LinuxModemBackend{},
+			Network: &wwanmgr.LinuxNetworkConfigurator{Log: log},
+		},
+		// Only the latest config is of interest.
+		configCh: make(chan types.WwanConfig, 1),
+	}
+
+	var err error
+	ctx.pubWwanStatus, err = ps.NewPublication(
+		pubsub.PublicationOptions{
+			AgentName: agentName,
+			TopicType: types.WwanStatus{},
+		})
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx.pubWwanMetrics, err = ps.NewPublication(
+		pubsub.PublicationOptions{
+			AgentName: agentName,
+			TopicType: types.WwanMetrics{},
+		})
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx.pubWwanLocationInfo, err = ps.NewPublication(
+		pubsub.PublicationOptions{
+			AgentName: agentName,
+			TopicType: types.WwanLocationInfo{},
+		})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Look for cellular config published by nim.
+	ctx.subWwanConfig, err = ps.NewSubscription(pubsub.SubscriptionOptions{
+		AgentName:     "nim",
+		MyAgentName:   agentName,
+		TopicImpl:     types.WwanConfig{},
+		Activate:      false,
+		Ctx:           ctx,
+		CreateHandler: handleWwanConfigCreate,
+		ModifyHandler: handleWwanConfigModify,
+		WarningTime:   warningTime,
+		ErrorTime:     errorTime,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = ctx.subWwanConfig.Activate(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Modems are managed by a separate goroutine because some operations
+	// (e.g. connection establishment) may take minutes.
+	go ctx.runManager()
+
+	for {
+		select {
+		case change := <-ctx.subWwanConfig.MsgChan():
+			ctx.subWwanConfig.ProcessChange(change)
+		case <-stillRunning.C:
+		}
+		ps.StillRunning(agentName, warningTime, errorTime)
+	}
+}
+
+func handleWwanConfigCreate(ctxArg interface{}, key string, configArg interface{}) {
+	handleWwanConfigImpl(ctxArg, key, configArg)
+}
+
+func handleWwanConfigModify(ctxArg interface{}, key string, configArg, oldConfigArg interface{}) {
+	handleWwanConfigImpl(ctxArg, key, configArg)
+}
+
+func handleWwanConfigImpl(ctxArg interface{}, key string, configArg interface{}) {
+	ctx := ctxArg.(*wwanContext)
+	config := configArg.(types.WwanConfig)
+	log.Functionf("handleWwanConfigImpl: %+v", config)
+	// Replace config which was not yet applied.
+	select {
+	case <-ctx.configCh:
+	default:
+	}
+	ctx.configCh <- config
+}
+
+func (ctx *wwanContext) runManager() {
+	probeTicker := time.NewTicker(probeInterval)
+	metricsTicker := time.NewTicker(metricsInterval)
+	locationTicker := time.NewTicker(locationInterval)
+	var configured bool
+	for {
+		select {
+		case config := <-ctx.configCh:
+			_, checksum, err := generic.MarshalWwanConfig(config)
+			if err != nil {
+				log.Error(err)
+				continue
+			}
+			log.Noticef("Applying wwan config: %+v", config)
+			status := ctx.manager.ApplyConfig(config, checksum)
+			ctx.publishStatus(status)
+			configured = true
+		case <-probeTicker.C:
+			if !configured {
+				continue
+			}
+			ctx.publishStatus(ctx.manager.Probe())
+		case <-metricsTicker.C:
+			if !configured {
+				continue
+			}
+			ctx.publishMetrics(ctx.manager.CollectMetrics())
+		case <-locationTicker.C:
+			if locInfo, ok := ctx.manager.GetLocation(); ok {
+				ctx.publishLocationInfo(locInfo)
+			}
+		}
+	}
+}
+
+func (ctx *wwanContext) publishStatus(status types.WwanStatus) {
+	if err := ctx.pubWwanStatus.Publish(status.Key(), status); err != nil {
+		log.Errorf("Failed to publish wwan status: %v", err)
+	}
+}
+
+func (ctx *wwanContext) publishMetrics(metrics types.WwanMetrics) {
+	if err := ctx.pubWwanMetrics.Publish(metrics.Key(), metrics); err != nil {
+		log.Errorf("Failed to publish wwan metrics: %v", err)
+	}
+}
+
+func (ctx *wwanContext) publishLocationInfo(locInfo types.WwanLocationInfo) {
+	if ctx.lastPublishedLocSet && ctx.lastPublishedLocInfo == locInfo {
+		return
+	}
+	if err := ctx.pubWwanLocationInfo.Publish(locInfo.Key(), locInfo); err != nil {
+		log.Errorf("Failed to publish wwan location info: %v", err)
+		return
+	}
+	ctx.lastPublishedLocInfo = locInfo
+	ctx.lastPublishedLocSet = true
+}
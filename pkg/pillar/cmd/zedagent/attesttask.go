@@ -45,6 +45,7 @@ type attestContext struct {
 	attestFsmCtx                  *zattest.Context
 	pubAttestNonce                pubsub.Publication
 	pubEncryptedKeyFromController pubsub.Publication
+	pubVaultKeyEscrowStatus       pubsub.Publication
 	//Nonce for the current attestation cycle
 	Nonce []byte
 	//Quote for the current attestation cycle
@@ -504,6 +505,7 @@ func (server *VerifierImpl) SendAttestEscrow(ctx *zattest.Context) error {
 	case attest.AttestStorageKeysResponseCode_ATTEST_STORAGE_KEYS_RESPONSE_CODE_SUCCESS:
 		log.Notice("[ATTEST] Escrow successful")
 		ctx.ClearError()
+		publishVaultKeyEscrowStatus(attestCtx)
 		triggerPublishDevInfo(attestCtx.zedagentCtx)
 		return nil
 	default:
@@ -614,6 +616,15 @@ func attestModuleInitialize(ctx *zedagentContext, ps *pubsub.PubSub) error {
 		log.Fatal(err)
 	}
 	ctx.attestCtx.pubEncryptedKeyFromController = pubEncryptedKeyFromController
+	pubVaultKeyEscrowStatus, err := ps.NewPublication(
+		pubsub.PublicationOptions{
+			AgentName: agentName,
+			TopicType: types.VaultKeyEscrowStatus{},
+		})
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx.attestCtx.pubVaultKeyEscrowStatus = pubVaultKeyEscrowStatus
 	parseTpmEventLog(ctx.attestCtx)
 	return nil
 }
@@ -793,6 +804,25 @@ func publishEncryptedKeyFromController(ctx *attestContext, encryptedVaultKey []b
 	log.Tracef("[ATTEST] publishEncryptedKeyFromController done for %s", key)
 }
 
+//publishVaultKeyEscrowStatus lets vaultmgr know that Controller stored
+//the vault key, e.g. to complete a vault key rotation
+func publishVaultKeyEscrowStatus(ctx *attestContext) {
+	keyData := &attest.AttestVolumeKeyData{}
+	if err := proto.Unmarshal(ctx.EscrowData, keyData); err != nil {
+		log.Errorf("[ATTEST] Failed to unmarshal escrowed key data: %v", err)
+		return
+	}
+	status := types.VaultKeyEscrowStatus{
+		Name:         types.DefaultVaultName,
+		DigestSha256: keyData.DigestSha256,
+	}
+	key := status.Key()
+	log.Tracef("[ATTEST] publishVaultKeyEscrowStatus %s", key)
+	pub := ctx.pubVaultKeyEscrowStatus
+	pub.Publish(key, status)
+	log.Tracef("[ATTEST] publishVaultKeyEscrowStatus done for %s", key)
+}
+
 func unpublishAttestNonce(ctx *attestContext) {
 	nonce := types.AttestNonce{
 		Nonce:     ctx.Nonce,
@@ -0,0 +1,117 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Export, inspection and import of the config checkpoint, from the command
+// line and from the local profile server (see localcheckpoint.go)
+
+package zedagent
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/lf-edge/eve/pkg/pillar/checkpoint"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	fileutils "github.com/lf-edge/eve/pkg/pillar/utils/file"
+	"github.com/lf-edge/eve/pkg/pillar/zedcloud"
+)
+
+// readSigningCert returns the controller signing certificate of the device,
+// or nil if the device does not have it
+func readSigningCert() ([]byte, error) {
+	b, err := ioutil.ReadFile(types.ServerSigningCertFileName)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return b, err
+}
+
+// exportCheckpoint writes the config checkpoint with its signature to filename
+func exportCheckpoint(filename string) int {
+	b, err := checkpoint.Export(checkpointDirname)
+	if err != nil {
+		fmt.Printf("Failed to export checkpoint: %v\n", err)
+		return 1
+	}
+	if err := fileutils.WriteRename(filename, b); err != nil {
+		fmt.Printf("Failed to write %s: %v\n", filename, err)
+		return 1
+	}
+	fmt.Printf("Exported checkpoint to %s\n", filename)
+	return 0
+}
+
+// inspectCheckpointBundle returns the exported checkpoint in human-readable
+// form, with the result of the signature verification
+func inspectCheckpointBundle(b []byte) (string, error) {
+	cp, configResponse, err := checkpoint.Decode(b)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode: %v", err)
+	}
+	signingCert, verifyErr := readSigningCert()
+	if verifyErr == nil {
+		ctx := zedcloud.NewContext(log, zedcloud.ContextOptions{AgentName: agentName})
+		_, verifyErr = checkpoint.Verify(&ctx, cp, signingCert)
+	}
+	out, err := checkpoint.Format(cp, configResponse, verifyErr)
+	if err != nil {
+		return "", fmt.Errorf("failed to format: %v", err)
+	}
+	return out, nil
+}
+
+// importCheckpointBundle verifies the exported checkpoint and makes it the
+// config checkpoint of the device. The controller signing certificate
+// carried by the checkpoint is installed if the device does not have one.
+func importCheckpointBundle(b []byte) error {
+	signingCert, err := readSigningCert()
+	if err != nil {
+		return fmt.Errorf("failed to read controller signing certificate: %v", err)
+	}
+	ctx := zedcloud.NewContext(log, zedcloud.ContextOptions{AgentName: agentName})
+	cert, err := checkpoint.Import(&ctx, checkpointDirname, b, signingCert)
+	if err != nil {
+		return err
+	}
+	if signingCert == nil {
+		if err := os.MkdirAll(types.CertificateDirname, 0700); err != nil {
+			return fmt.Errorf("failed to create %s: %v", types.CertificateDirname, err)
+		}
+		if err := zedcloud.UpdateServerCert(&ctx, cert); err != nil {
+			return fmt.Errorf("failed to install controller signing certificate: %v", err)
+		}
+	}
+	return nil
+}
+
+// inspectCheckpoint prints the exported checkpoint in human-readable form
+func inspectCheckpoint(filename string) int {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		fmt.Printf("Failed to read %s: %v\n", filename, err)
+		return 1
+	}
+	out, err := inspectCheckpointBundle(b)
+	if err != nil {
+		fmt.Printf("Failed to inspect %s: %v\n", filename, err)
+		return 1
+	}
+	fmt.Print(out)
+	return 0
+}
+
+// importCheckpoint imports the exported checkpoint from filename
+func importCheckpoint(filename string) int {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		fmt.Printf("Failed to read %s: %v\n", filename, err)
+		return 1
+	}
+	if err := importCheckpointBundle(b); err != nil {
+		fmt.Printf("Failed to import %s: %v\n", filename, err)
+		return 1
+	}
+	fmt.Printf("Imported checkpoint from %s\n", filename)
+	return 0
+}
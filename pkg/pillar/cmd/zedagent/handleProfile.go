@@ -197,6 +197,10 @@ func parseProfile(ctx *getconfigContext, config *zconfig.EdgeDevConfig) {
 		triggerLocalAppInfoPOST(ctx)
 		updateLocalDevInfoTicker(ctx, false)
 		triggerLocalDevInfoPOST(ctx)
+		updateLocalVolumeInfoTicker(ctx, false)
+		triggerLocalVolumeInfoPOST(ctx)
+		updateLocalCheckpointTicker(ctx, false)
+		triggerLocalCheckpointPOST(ctx)
 		ctx.lpsThrottledLocation = false
 	}
 	profileStateMachine(ctx, true)
@@ -19,6 +19,7 @@ import (
 	zconfig "github.com/lf-edge/eve/api/go/config"
 	"github.com/lf-edge/eve/api/go/evecommon"
 	"github.com/lf-edge/eve/pkg/pillar/agentlog"
+	"github.com/lf-edge/eve/pkg/pillar/checkpoint"
 	"github.com/lf-edge/eve/pkg/pillar/pubsub"
 	"github.com/lf-edge/eve/pkg/pillar/types"
 	"github.com/lf-edge/eve/pkg/pillar/zedcloud"
@@ -253,6 +254,9 @@ func getCertsFromController(ctx *zedagentContext) bool {
 		return false
 	}
 
+	// keep the certificates along with the config checkpoint
+	saveConfig(checkpoint.ControllerCertsFilename, contents)
+
 	// manage the certificates through pubsub
 	parseControllerCerts(ctx, contents)
 
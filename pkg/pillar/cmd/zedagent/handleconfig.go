@@ -19,6 +19,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	zconfig "github.com/lf-edge/eve/api/go/config"
 	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/lf-edge/eve/pkg/pillar/checkpoint"
 	"github.com/lf-edge/eve/pkg/pillar/flextimer"
 	"github.com/lf-edge/eve/pkg/pillar/hardware"
 	"github.com/lf-edge/eve/pkg/pillar/pidfile"
@@ -34,6 +35,12 @@ import (
 var serverName string
 var serverNameAndPort string
 
+// Envelope of the last config received from the controller
+var lastConfigAuthContainer struct {
+	sync.Mutex
+	contents []byte
+}
+
 // Notify simple struct to pass notification messages
 type Notify struct{}
 
@@ -86,6 +93,7 @@ type getconfigContext struct {
 	pubBaseOsConfig           pubsub.Publication
 	pubBaseOs                 pubsub.Publication
 	pubDatastoreConfig        pubsub.Publication
+	pubPeerCacheConfig        pubsub.Publication
 	pubNetworkInstanceConfig  pubsub.Publication
 	pubControllerCert         pubsub.Publication
 	pubCipherContext          pubsub.Publication
@@ -115,8 +123,13 @@ type getconfigContext struct {
 	radioSilence     types.RadioSilence // the intended state of radio devices
 	triggerRadioPOST chan Notify
 
-	localAppInfoPOSTTicker flextimer.FlexTickerHandle
-	localDevInfoPOSTTicker flextimer.FlexTickerHandle
+	localAppInfoPOSTTicker    flextimer.FlexTickerHandle
+	localDevInfoPOSTTicker    flextimer.FlexTickerHandle
+	localVolumeInfoPOSTTicker flextimer.FlexTickerHandle
+	localCheckpointPOSTTicker flextimer.FlexTickerHandle
+
+	// result of the last checkpoint command from the Local profile server
+	localCheckpoint localCheckpointState
 
 	// When enabled, device location reports are being published to the Local profile server
 	// at a significantly decreased rate.
@@ -138,6 +151,10 @@ type getconfigContext struct {
 	currentMetricInterval uint32
 
 	configEdgeview *types.EdgeviewConfig // edge-view config save
+
+	// update bundles applied without the controller
+	localBundle      *localBundleState
+	localBundleInUse bool // config from an update bundle is in use
 }
 
 // current devUUID from OnboardingStatus
@@ -178,6 +195,7 @@ func handleConfigInit(networkSendTimeout uint32, agentMetrics *zedcloud.AgentMet
 	}
 
 	zedcloudCtx.DevUUID = devUUID
+	zedcloudCtx.AuthContainerFunc = saveConfigAuthContainer
 	return &zedcloudCtx
 }
 
@@ -290,7 +308,9 @@ func getLatestConfig(url string, iteration int,
 	// our config.
 	if !getconfigCtx.zedagentCtx.publishedEdgeNodeCerts {
 		log.Noticef("Defer fetching config until our EdgeNodeCerts have been published")
-		return false
+		// Without the controller we can still apply an update bundle
+		_, skipFlag := applyLocalBundleConfig(getconfigCtx)
+		return skipFlag
 	}
 	ctx := getconfigCtx.zedagentCtx
 	const bailOnHTTPErr = false // For 4xx and 5xx HTTP errors we try other interfaces
@@ -354,19 +374,33 @@ func getLatestConfig(url string, iteration int,
 			}
 		}
 
-		if !getconfigCtx.readSavedConfig && !getconfigCtx.configReceived {
+		// Without the controller apply an update bundle, if any
+		if applied, skipFlag := applyLocalBundleConfig(getconfigCtx); applied {
+			return skipFlag
+		}
+		if !getconfigCtx.readSavedConfig && !getconfigCtx.configReceived &&
+			!getconfigCtx.localBundleInUse {
 			// If we didn't yet get a config, then look for a file
 			// XXX should we try a few times?
 			// If we crashed we wait until we connect to zedcloud so that
 			// keyboard can be enabled and things can be debugged and not
 			// have e.g., an OOM reboot loop
-			if !ctx.bootReason.StartWithSavedConfig() {
+			// An imported checkpoint is used regardless of the boot
+			// reason and its age, once its signature is verified
+			imported := existsSavedConfig(checkpoint.ImportedFilename)
+			if !ctx.bootReason.StartWithSavedConfig() && !imported {
 				log.Warnf("Ignore any saved config due to boot reason %s",
 					ctx.bootReason)
 			} else {
+				if imported {
+					if err := verifyImportedCheckpoint(); err != nil {
+						log.Errorf("getconfig: imported checkpoint: %v", err)
+						return false
+					}
+				}
 				config, ts, err := readSavedProtoMessageConfig(
 					ctx.globalConfig.GlobalValueInt(types.StaleConfigTime),
-					checkpointDirname+"/lastconfig", false)
+					checkpointDirname+"/lastconfig", imported)
 				if err != nil {
 					log.Errorf("getconfig: %v", err)
 					return false
@@ -394,6 +428,7 @@ func getLatestConfig(url string, iteration int,
 		if !getconfigCtx.configReceived {
 			getconfigCtx.configReceived = true
 		}
+		getconfigCtx.localBundleInUse = false
 		getconfigCtx.configGetStatus = types.ConfigGetSuccess
 		publishZedAgentStatus(getconfigCtx)
 
@@ -429,6 +464,7 @@ func getLatestConfig(url string, iteration int,
 	if !getconfigCtx.configReceived {
 		getconfigCtx.configReceived = true
 	}
+	getconfigCtx.localBundleInUse = false
 	getconfigCtx.configGetStatus = types.ConfigGetSuccess
 	publishZedAgentStatus(getconfigCtx)
 
@@ -445,6 +481,55 @@ func getLatestConfig(url string, iteration int,
 
 func saveReceivedProtoMessage(contents []byte) {
 	saveConfig("lastconfig", contents)
+	cleanSavedConfig(checkpoint.ImportedFilename)
+	// Keep the signature of the config, which allows to export the checkpoint
+	lastConfigAuthContainer.Lock()
+	signedConfig := lastConfigAuthContainer.contents
+	lastConfigAuthContainer.contents = nil
+	lastConfigAuthContainer.Unlock()
+	if signedConfig != nil {
+		payload, err := checkpoint.SignedPayload(signedConfig)
+		if err == nil && bytes.Equal(payload, contents) {
+			saveConfig(checkpoint.SignedConfigFilename, signedConfig)
+			return
+		}
+	}
+	cleanSavedConfig(checkpoint.SignedConfigFilename)
+}
+
+// verifyImportedCheckpoint verifies the signature of the imported config
+// checkpoint against the controller signing certificate
+func verifyImportedCheckpoint() error {
+	signedConfig, err := ioutil.ReadFile(filepath.Join(checkpointDirname,
+		checkpoint.SignedConfigFilename))
+	if err != nil {
+		return err
+	}
+	payload, err := zedcloud.VerifyAuthContainer(zedcloudCtx, signedConfig)
+	if err != nil {
+		return err
+	}
+	config, err := ioutil.ReadFile(filepath.Join(checkpointDirname,
+		checkpoint.ConfigFilename))
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(payload, config) {
+		return fmt.Errorf("%s does not match %s", checkpoint.SignedConfigFilename,
+			checkpoint.ConfigFilename)
+	}
+	return nil
+}
+
+// saveConfigAuthContainer records the envelope of the config received from
+// the controller, which carries the signature
+func saveConfigAuthContainer(url string, authContainer []byte) {
+	if !strings.HasSuffix(url, "/config") {
+		return
+	}
+	lastConfigAuthContainer.Lock()
+	lastConfigAuthContainer.contents = authContainer
+	lastConfigAuthContainer.Unlock()
 }
 
 // Update timestamp - no content changes
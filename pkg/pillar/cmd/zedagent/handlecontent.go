@@ -65,6 +65,9 @@ func parseContentInfoConfig(ctx *getconfigContext,
 		contentConfig.ContentSha256 = strings.ToLower(cfgContentTree.GetSha256())
 		contentConfig.MaxDownloadSize = cfgContentTree.GetMaxSizeBytes()
 		contentConfig.DisplayName = cfgContentTree.GetDisplayName()
+		contentConfig.ChunkIndexURL = cfgContentTree.GetChunkIndexUrl()
+		contentConfig.SignatureKeys = cfgContentTree.GetSignatureVerificationKeys()
+		contentConfig.DownloadPriority = cfgContentTree.GetDownloadPriority()
 		publishContentTreeConfig(ctx, *contentConfig)
 	}
 	ctx.pubContentTreeConfig.SignalRestarted()
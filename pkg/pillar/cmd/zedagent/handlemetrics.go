@@ -1298,6 +1298,7 @@ func PublishVolumeToZedCloud(ctx *zedagentContext, uuid string,
 
 		ReportVolumeInfo.ProgressPercentage = uint32(volStatus.Progress)
 		ReportVolumeInfo.GenerationCount = volStatus.GenerationCounter
+		ReportVolumeInfo.KeyState = info.VolumeKeyState(volStatus.KeyState)
 	}
 
 	ReportInfo.InfoContent = new(info.ZInfoMsg_Vinfo)
@@ -89,6 +89,8 @@ func parseVolumeConfig(ctx *getconfigContext,
 		} else {
 			volumeConfig.Encrypted = true
 		}
+		volumeConfig.IsolatedKey = cfgVolume.GetIsolatedKey()
+		volumeConfig.AppInstanceID = volumeAppInstance(cfgVolume, config)
 		volumeConfig.DisplayName = cfgVolume.GetDisplayName()
 		volumeConfig.ReadOnly = cfgVolume.GetReadonly()
 		volumeConfig.RefCount = 1
@@ -112,6 +114,22 @@ func signalVolumeConfigRestarted(ctx *getconfigContext) {
 	log.Trace("signalVolumeConfigRestarted done")
 }
 
+// volumeAppInstance returns the first app instance using this volume in new config
+func volumeAppInstance(cfgVolume *zconfig.Volume,
+	devConfig *zconfig.EdgeDevConfig) uuid.UUID {
+
+	for _, el := range devConfig.GetApps() {
+		for _, vr := range el.VolumeRefList {
+			if vr.Uuid == cfgVolume.GetUuid() &&
+				vr.GenerationCount == cfgVolume.GenerationCount {
+				id, _ := uuid.FromString(el.GetUuidandversion().GetUuid())
+				return id
+			}
+		}
+	}
+	return uuid.UUID{}
+}
+
 // checkVolumeHasNoAppReferences returns true if there are no apps using this image in new config
 func checkVolumeHasNoAppReferences(ctx *getconfigContext, cfgVolume *zconfig.Volume,
 	devConfig *zconfig.EdgeDevConfig) bool {
@@ -171,6 +189,8 @@ func handleVolumeStatusImpl(ctxArg interface{}, key string,
 	ctx := ctxArg.(*zedagentContext)
 	uuidStr := status.VolumeID.String()
 	PublishVolumeToZedCloud(ctx, uuidStr, &status, ctx.iteration)
+	processVolumeCommandStatus(ctx.getconfigCtx, status)
+	triggerLocalVolumeInfoPOST(ctx.getconfigCtx)
 	ctx.iteration++
 }
 
@@ -181,5 +201,6 @@ func handleVolumeStatusDelete(ctxArg interface{},
 	ctx := ctxArg.(*zedagentContext)
 	uuidStr := status.VolumeID.String()
 	PublishVolumeToZedCloud(ctx, uuidStr, nil, ctx.iteration)
+	triggerLocalVolumeInfoPOST(ctx.getconfigCtx)
 	ctx.iteration++
 }
@@ -0,0 +1,541 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Update bundles for air-gapped devices. A bundle consists of a manifest,
+// signed by the controller, which carries the device config, and of the
+// images the config refers to. The bundle is taken from a USB stick (copied
+// to LocalBundleIncomingDir at boot by device-steps.sh) or from the local
+// profile server. The images are checked against the manifest and staged in
+// LocalBundleBlobsDir, where the downloader looks for them. The config is
+// applied only while the controller is not reachable, through the same
+// pipelines as the config from the controller.
+
+package zedagent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	zauth "github.com/lf-edge/eve/api/go/auth"
+	zconfig "github.com/lf-edge/eve/api/go/config"
+	"github.com/lf-edge/eve/api/go/profile"
+	"github.com/lf-edge/eve/pkg/pillar/flextimer"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	fileutils "github.com/lf-edge/eve/pkg/pillar/utils/file"
+	"github.com/lf-edge/eve/pkg/pillar/zedcloud"
+	uuid "github.com/satori/go.uuid"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	localBundleURLPath      = "/api/v1/bundle"
+	localBundleBlobsURLPath = "/api/v1/bundle/blobs/"
+	localBundleInfoURLPath  = "/api/v1/bundleinfo"
+	localBundleInterval     = 5 * time.Minute
+	localBundleDialTimeout  = 30 * time.Second
+	// Manifest of the bundle whose images are staged
+	stagedBundleFile = types.LocalBundleDir + "/staged"
+	// Manifest of the last applied bundle
+	appliedBundleFile = types.LocalBundleDir + "/applied"
+	// Last status, copied to the USB stick at boot by device-steps.sh
+	localBundleStatusFile = types.LocalBundleDir + "/status.json"
+)
+
+// localBundleState : state of the update bundles, shared by localBundleTask
+// which stages the bundles, and configTimerTask which applies them.
+type localBundleState struct {
+	sync.Mutex
+	sequence        uint64 // last received bundle
+	state           profile.LocalBundleStatus_State
+	err             string
+	appliedSequence uint64
+	stagedSequence  uint64
+}
+
+// fetchBundleBlobFunc copies an image of the bundle to the dst file
+type fetchBundleBlobFunc func(blob *zconfig.BundleBlob, dst string) error
+
+func initializeLocalBundle(ctx *getconfigContext) {
+	ctx.localBundle = &localBundleState{}
+	if err := os.MkdirAll(types.LocalBundleDir, 0700); err != nil {
+		log.Errorf("initializeLocalBundle: %v", err)
+	}
+	if manifest, err := readLocalBundleManifest(appliedBundleFile); err == nil {
+		ctx.localBundle.appliedSequence = manifest.Sequence
+		ctx.localBundle.sequence = manifest.Sequence
+		ctx.localBundle.state = profile.LocalBundleStatus_STATE_APPLIED
+	}
+	if manifest, err := readLocalBundleManifest(stagedBundleFile); err == nil {
+		ctx.localBundle.stagedSequence = manifest.Sequence
+		ctx.localBundle.sequence = manifest.Sequence
+		ctx.localBundle.state = profile.LocalBundleStatus_STATE_STAGED
+	}
+}
+
+// Run a periodic check for a new update bundle from a USB stick or from
+// the local profile server
+func localBundleTask(ctx *getconfigContext) {
+	max := float64(localBundleInterval)
+	min := max * 0.3
+	ticker := flextimer.NewRangeTicker(time.Duration(min), time.Duration(max))
+
+	wdName := agentName + "-localbundle"
+
+	// Run a periodic timer so we always update StillRunning
+	stillRunning := time.NewTicker(25 * time.Second)
+	ctx.zedagentCtx.ps.StillRunning(wdName, warningTime, errorTime)
+	ctx.zedagentCtx.ps.RegisterFileWatchdog(wdName)
+
+	// Look for a bundle from USB stick right away
+	ticker.TickNow()
+	for {
+		select {
+		case <-ticker.C:
+			start := time.Now()
+			checkLocalBundle(ctx, wdName)
+			ctx.zedagentCtx.ps.CheckMaxTimeTopic(wdName, "checkLocalBundle", start,
+				warningTime, errorTime)
+		case <-stillRunning.C:
+		}
+		ctx.zedagentCtx.ps.StillRunning(wdName, warningTime, errorTime)
+	}
+}
+
+// checkLocalBundle stages a new bundle from the USB stick or from the local
+// profile server, and reports the state of the bundles to the local server
+func checkLocalBundle(ctx *getconfigContext, wdName string) {
+	incomingManifest := filepath.Join(types.LocalBundleIncomingDir,
+		types.LocalBundleManifestFile)
+	if b, err := ioutil.ReadFile(incomingManifest); err == nil {
+		log.Noticef("Found update bundle from USB stick")
+		importLocalBundle(ctx, b, func(blob *zconfig.BundleBlob, dst string) error {
+			src := filepath.Join(types.LocalBundleIncomingDir,
+				types.LocalBundleBlobsSubdir, blob.Sha256)
+			if err := os.Rename(src, dst); err == nil {
+				return nil
+			}
+			return fileutils.CopyFile(src, dst)
+		})
+		if err := os.RemoveAll(types.LocalBundleIncomingDir); err != nil {
+			log.Errorf("checkLocalBundle: %v", err)
+		}
+	} else if !os.IsNotExist(err) {
+		log.Errorf("checkLocalBundle: %v", err)
+	} else if ctx.localProfileServer != "" {
+		bundle, srv, err := getLocalBundle(ctx)
+		if err != nil {
+			log.Errorf("checkLocalBundle: %v", err)
+		} else if bundle != nil {
+			importLocalBundle(ctx, bundle.GetManifest(),
+				func(blob *zconfig.BundleBlob, dst string) error {
+					return fetchLocalBundleBlob(ctx, srv, blob, dst, wdName)
+				})
+		}
+	}
+	saveLocalBundleStatus(ctx)
+	postLocalBundleStatus(ctx)
+}
+
+// importLocalBundle verifies the bundle and stages it, unless we already
+// have it
+func importLocalBundle(ctx *getconfigContext, b []byte, fetch fetchBundleBlobFunc) {
+	for _, filename := range []string{stagedBundleFile, appliedBundleFile} {
+		if known, err := ioutil.ReadFile(filename); err == nil && string(known) == string(b) {
+			log.Functionf("importLocalBundle: already have the bundle")
+			return
+		}
+	}
+	err := stageLocalBundle(ctx, b, fetch)
+	if err != nil {
+		log.Errorf("importLocalBundle: %v", err)
+		ctx.localBundle.Lock()
+		ctx.localBundle.state = profile.LocalBundleStatus_STATE_FAILED
+		ctx.localBundle.err = err.Error()
+		ctx.localBundle.Unlock()
+	}
+}
+
+// stageLocalBundle verifies the signature of the manifest, copies and
+// checks the images, and saves the manifest to be applied.
+func stageLocalBundle(ctx *getconfigContext, b []byte, fetch fetchBundleBlobFunc) error {
+	payload, err := zedcloud.VerifyAuthContainer(zedcloudCtx, b)
+	if err != nil {
+		return fmt.Errorf("manifest signature verification failed: %v", err)
+	}
+	manifest := &zconfig.UpdateBundleManifest{}
+	if err := proto.Unmarshal(payload, manifest); err != nil {
+		return fmt.Errorf("manifest unmarshalling failed: %v", err)
+	}
+	ctx.localBundle.Lock()
+	lastSequence := ctx.localBundle.appliedSequence
+	if ctx.localBundle.stagedSequence > lastSequence {
+		lastSequence = ctx.localBundle.stagedSequence
+	}
+	ctx.localBundle.sequence = manifest.Sequence
+	ctx.localBundle.err = ""
+	ctx.localBundle.Unlock()
+	if err := checkLocalBundleManifest(manifest, devUUID, lastSequence); err != nil {
+		return err
+	}
+
+	log.Noticef("Staging update bundle %d with %d images",
+		manifest.Sequence, len(manifest.Blobs))
+	ctx.localBundle.Lock()
+	ctx.localBundle.state = profile.LocalBundleStatus_STATE_DOWNLOADING
+	ctx.localBundle.Unlock()
+	postLocalBundleStatus(ctx)
+
+	// The new bundle replaces the staged one
+	os.Remove(stagedBundleFile)
+	if err := os.RemoveAll(types.LocalBundleBlobsDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(types.LocalBundleBlobsDir, 0700); err != nil {
+		return err
+	}
+	for _, blob := range manifest.Blobs {
+		if err := stageLocalBundleBlob(blob, fetch); err != nil {
+			os.RemoveAll(types.LocalBundleBlobsDir)
+			return fmt.Errorf("image %s: %v", blob.Sha256, err)
+		}
+	}
+	if err := fileutils.WriteRename(stagedBundleFile, b); err != nil {
+		return err
+	}
+	log.Noticef("Staged update bundle %d", manifest.Sequence)
+	ctx.localBundle.Lock()
+	ctx.localBundle.stagedSequence = manifest.Sequence
+	ctx.localBundle.state = profile.LocalBundleStatus_STATE_STAGED
+	ctx.localBundle.Unlock()
+	return nil
+}
+
+func stageLocalBundleBlob(blob *zconfig.BundleBlob, fetch fetchBundleBlobFunc) error {
+	blobFile := filepath.Join(types.LocalBundleBlobsDir, blob.Sha256)
+	tmpFile := blobFile + ".tmp"
+	if err := fetch(blob, tmpFile); err != nil {
+		os.Remove(tmpFile)
+		return err
+	}
+	if err := checkLocalBundleBlob(tmpFile, blob); err != nil {
+		os.Remove(tmpFile)
+		return err
+	}
+	if blob.MediaType != "" {
+		err := fileutils.WriteRename(blobFile+types.LocalBundleMediaTypeSuffix,
+			[]byte(blob.MediaType))
+		if err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmpFile, blobFile)
+}
+
+// checkLocalBundleManifest checks that the bundle is built for this device,
+// and is newer than the last one.
+func checkLocalBundleManifest(manifest *zconfig.UpdateBundleManifest,
+	deviceUUID uuid.UUID, lastSequence uint64) error {
+	if manifest.DeviceUuid != deviceUUID.String() {
+		return fmt.Errorf("bundle is built for device %s", manifest.DeviceUuid)
+	}
+	if manifest.Sequence <= lastSequence {
+		return fmt.Errorf("bundle sequence %d is not higher than %d",
+			manifest.Sequence, lastSequence)
+	}
+	if manifest.Config == nil {
+		return fmt.Errorf("bundle has no config")
+	}
+	for _, blob := range manifest.Blobs {
+		sha := blob.Sha256
+		if _, err := hex.DecodeString(sha); err != nil || len(sha) != 2*sha256.Size ||
+			strings.ToLower(sha) != sha {
+			return fmt.Errorf("bad image sha256 %s", sha)
+		}
+	}
+	return nil
+}
+
+// checkLocalBundleBlob checks the size and the sha256 of the image
+func checkLocalBundleBlob(filename string, blob *zconfig.BundleBlob) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return err
+	}
+	if uint64(size) != blob.SizeBytes {
+		return fmt.Errorf("size %d, expected %d", size, blob.SizeBytes)
+	}
+	sha := hex.EncodeToString(h.Sum(nil))
+	if sha != blob.Sha256 {
+		return fmt.Errorf("sha256 mismatch: %s", sha)
+	}
+	return nil
+}
+
+// readLocalBundleManifest reads the manifest of a bundle which was
+// verified before it was staged.
+func readLocalBundleManifest(filename string) (*zconfig.UpdateBundleManifest, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	sm := &zauth.AuthContainer{}
+	if err := proto.Unmarshal(b, sm); err != nil {
+		return nil, err
+	}
+	manifest := &zconfig.UpdateBundleManifest{}
+	if err := proto.Unmarshal(sm.GetProtectedPayload().GetPayload(), manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// applyLocalBundleConfig is called when the controller is not reachable.
+// It applies the config of the staged bundle, or after a reboot the config of
+// the last applied one. Returns if a config was applied, and the
+// configProcessingSkipFlag.
+func applyLocalBundleConfig(getconfigCtx *getconfigContext) (bool, bool) {
+	if getconfigCtx.localBundleInUse {
+		getconfigCtx.configGetStatus = types.ConfigGetLocalBundle
+	}
+	usingSaved := false
+	manifest, err := readLocalBundleManifest(stagedBundleFile)
+	if err == nil {
+		if err := os.Rename(stagedBundleFile, appliedBundleFile); err != nil {
+			log.Errorf("applyLocalBundleConfig: %v", err)
+			return false, false
+		}
+		log.Noticef("Applying update bundle %d", manifest.Sequence)
+		getconfigCtx.localBundle.Lock()
+		getconfigCtx.localBundle.appliedSequence = manifest.Sequence
+		getconfigCtx.localBundle.stagedSequence = 0
+		if getconfigCtx.localBundle.sequence == manifest.Sequence {
+			getconfigCtx.localBundle.state = profile.LocalBundleStatus_STATE_APPLIED
+		}
+		getconfigCtx.localBundle.Unlock()
+	} else if getconfigCtx.configReceived || getconfigCtx.readSavedConfig ||
+		getconfigCtx.localBundleInUse {
+		return false, false
+	} else {
+		// There is no controller to wait for, hence we apply the last
+		// bundle irrespective of the boot reason, unless the controller
+		// gave us a config after it.
+		applied, err := os.Stat(appliedBundleFile)
+		if err != nil {
+			return false, false
+		}
+		saved, err := os.Stat(filepath.Join(checkpointDirname, "lastconfig"))
+		if err == nil && saved.ModTime().After(applied.ModTime()) {
+			return false, false
+		}
+		manifest, err = readLocalBundleManifest(appliedBundleFile)
+		if err != nil {
+			log.Errorf("applyLocalBundleConfig: %v", err)
+			return false, false
+		}
+		log.Noticef("Using config of update bundle %d", manifest.Sequence)
+		usingSaved = true
+	}
+	getconfigCtx.localBundleInUse = true
+	// Get the complete config once the controller is reachable
+	prevConfigHash = ""
+	getconfigCtx.configGetStatus = types.ConfigGetLocalBundle
+	publishZedAgentStatus(getconfigCtx)
+	return true, inhaleDeviceConfig(manifest.Config, getconfigCtx, usingSaved)
+}
+
+// getLocalBundle gets the bundle from the local profile server.
+// Returns nil if the server does not have any.
+func getLocalBundle(ctx *getconfigContext) (*profile.LocalBundle, *localBundleServer, error) {
+	servers, err := getLocalBundleServers(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var errList []string
+	for _, srv := range servers {
+		bundle := &profile.LocalBundle{}
+		resp, err := zedcloud.SendLocalProto(zedcloudCtx,
+			srv.url+localBundleURLPath, srv.bridgeName,
+			srv.bridgeIP, nil, bundle)
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				// Not implemented
+				return nil, nil, nil
+			}
+			errList = append(errList, fmt.Sprintf("SendLocalProto: %v", err))
+			continue
+		}
+		switch resp.StatusCode {
+		case http.StatusNoContent:
+			return nil, nil, nil
+		case http.StatusOK:
+			if bundle.GetServerToken() != ctx.profileServerToken {
+				errList = append(errList,
+					fmt.Sprintf("invalid token submitted by local server (%s)", bundle.GetServerToken()))
+				continue
+			}
+			if len(bundle.GetManifest()) == 0 {
+				return nil, nil, nil
+			}
+			return bundle, &srv, nil
+		default:
+			errList = append(errList, fmt.Sprintf("SendLocalProto: wrong response status code: %d",
+				resp.StatusCode))
+		}
+	}
+	return nil, nil, fmt.Errorf("getLocalBundle: all attempts failed: %s", strings.Join(errList, ";"))
+}
+
+// fetchLocalBundleBlob downloads the image from the local profile server
+func fetchLocalBundleBlob(ctx *getconfigContext, srv *localBundleServer,
+	blob *zconfig.BundleBlob, dst string, wdName string) error {
+	dialer := &net.Dialer{
+		Timeout:   localBundleDialTimeout,
+		LocalAddr: &net.TCPAddr{IP: srv.bridgeIP},
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: dialer.DialContext,
+		},
+	}
+	resp, err := client.Get(srv.url + localBundleBlobsURLPath + blob.Sha256)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("wrong response status code: %d", resp.StatusCode)
+	}
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	// The images can be large; keep the watchdog happy while we copy
+	w := &watchdogWriter{w: f, ctx: ctx.zedagentCtx, wdName: wdName}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// watchdogWriter updates StillRunning of the task while writing
+type watchdogWriter struct {
+	w           io.Writer
+	ctx         *zedagentContext
+	wdName      string
+	lastTouched time.Time
+}
+
+func (ww *watchdogWriter) Write(p []byte) (int, error) {
+	if time.Since(ww.lastTouched) > 25*time.Second {
+		ww.ctx.ps.StillRunning(ww.wdName, warningTime, errorTime)
+		ww.lastTouched = time.Now()
+	}
+	return ww.w.Write(p)
+}
+
+// localBundleServer : local profile server on a bridge
+type localBundleServer struct {
+	url        string
+	bridgeName string
+	bridgeIP   net.IP
+}
+
+func getLocalBundleServers(ctx *getconfigContext) ([]localBundleServer, error) {
+	localServerURL, err := makeLocalServerBaseURL(ctx.localProfileServer)
+	if err != nil {
+		return nil, fmt.Errorf("makeLocalServerBaseURL: %v", err)
+	}
+	if !ctx.localServerMap.upToDate {
+		err := updateLocalServerMap(ctx, localServerURL)
+		if err != nil {
+			return nil, fmt.Errorf("updateLocalServerMap: %v", err)
+		}
+		// Make sure HasLocalServer is set correctly for the AppInstanceConfig
+		updateHasLocalServer(ctx)
+	}
+	var servers []localBundleServer
+	for bridgeName, srvs := range ctx.localServerMap.servers {
+		for _, srv := range srvs {
+			servers = append(servers, localBundleServer{
+				url:        srv.localServerAddr,
+				bridgeName: bridgeName,
+				bridgeIP:   srv.bridgeIP,
+			})
+		}
+	}
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("cannot find any configured apps for localServerURL: %s",
+			localServerURL)
+	}
+	return servers, nil
+}
+
+func prepareLocalBundleStatus(ctx *getconfigContext) *profile.LocalBundleStatus {
+	ctx.localBundle.Lock()
+	defer ctx.localBundle.Unlock()
+	return &profile.LocalBundleStatus{
+		Sequence:        ctx.localBundle.sequence,
+		State:           ctx.localBundle.state,
+		Error:           ctx.localBundle.err,
+		AppliedSequence: ctx.localBundle.appliedSequence,
+	}
+}
+
+// saveLocalBundleStatus saves the status to be copied to the USB stick
+func saveLocalBundleStatus(ctx *getconfigContext) {
+	b, err := protojson.Marshal(prepareLocalBundleStatus(ctx))
+	if err != nil {
+		log.Errorf("saveLocalBundleStatus: %v", err)
+		return
+	}
+	if err := fileutils.WriteRename(localBundleStatusFile, b); err != nil {
+		log.Errorf("saveLocalBundleStatus: %v", err)
+	}
+}
+
+// postLocalBundleStatus reports the state of the bundles to the local server
+func postLocalBundleStatus(ctx *getconfigContext) {
+	if ctx.localProfileServer == "" {
+		return
+	}
+	servers, err := getLocalBundleServers(ctx)
+	if err != nil {
+		log.Functionf("postLocalBundleStatus: %v", err)
+		return
+	}
+	status := prepareLocalBundleStatus(ctx)
+	var errList []string
+	for _, srv := range servers {
+		resp, err := zedcloud.SendLocalProto(zedcloudCtx,
+			srv.url+localBundleInfoURLPath, srv.bridgeName,
+			srv.bridgeIP, status, nil)
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				// Not implemented
+				return
+			}
+			errList = append(errList, fmt.Sprintf("SendLocalProto: %v", err))
+			continue
+		}
+		return
+	}
+	log.Errorf("postLocalBundleStatus: all attempts failed: %s", strings.Join(errList, ";"))
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package zedagent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	zconfig "github.com/lf-edge/eve/api/go/config"
+	uuid "github.com/satori/go.uuid"
+)
+
+func TestCheckLocalBundleManifest(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deviceUUID, err := uuid.NewV4()
+	g.Expect(err).To(BeNil())
+	otherUUID, err := uuid.NewV4()
+	g.Expect(err).To(BeNil())
+	sha := strings.Repeat("ab", sha256.Size)
+
+	testMatrix := map[string]struct {
+		manifest *zconfig.UpdateBundleManifest
+		fail     bool
+	}{
+		"valid": {
+			manifest: &zconfig.UpdateBundleManifest{
+				DeviceUuid: deviceUUID.String(),
+				Sequence:   2,
+				Config:     &zconfig.EdgeDevConfig{},
+				Blobs:      []*zconfig.BundleBlob{{Sha256: sha, SizeBytes: 1}},
+			},
+		},
+		"other device": {
+			manifest: &zconfig.UpdateBundleManifest{
+				DeviceUuid: otherUUID.String(),
+				Sequence:   2,
+				Config:     &zconfig.EdgeDevConfig{},
+			},
+			fail: true,
+		},
+		"old sequence": {
+			manifest: &zconfig.UpdateBundleManifest{
+				DeviceUuid: deviceUUID.String(),
+				Sequence:   1,
+				Config:     &zconfig.EdgeDevConfig{},
+			},
+			fail: true,
+		},
+		"no config": {
+			manifest: &zconfig.UpdateBundleManifest{
+				DeviceUuid: deviceUUID.String(),
+				Sequence:   2,
+			},
+			fail: true,
+		},
+		"uppercase sha": {
+			manifest: &zconfig.UpdateBundleManifest{
+				DeviceUuid: deviceUUID.String(),
+				Sequence:   2,
+				Config:     &zconfig.EdgeDevConfig{},
+				Blobs: []*zconfig.BundleBlob{
+					{Sha256: strings.ToUpper(sha), SizeBytes: 1}},
+			},
+			fail: true,
+		},
+		"bad sha": {
+			manifest: &zconfig.UpdateBundleManifest{
+				DeviceUuid: deviceUUID.String(),
+				Sequence:   2,
+				Config:     &zconfig.EdgeDevConfig{},
+				Blobs:      []*zconfig.BundleBlob{{Sha256: "../lastconfig"}},
+			},
+			fail: true,
+		},
+	}
+	for testname, test := range testMatrix {
+		t.Logf("Running test case %s", testname)
+		err := checkLocalBundleManifest(test.manifest, deviceUUID, 1)
+		if test.fail {
+			g.Expect(err).ToNot(BeNil(), testname)
+		} else {
+			g.Expect(err).To(BeNil(), testname)
+		}
+	}
+}
+
+func TestCheckLocalBundleBlob(t *testing.T) {
+	g := NewGomegaWithT(t)
+	dir, err := ioutil.TempDir("", "localbundle")
+	g.Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+	content := []byte("image content")
+	filename := filepath.Join(dir, "blob")
+	g.Expect(ioutil.WriteFile(filename, content, 0644)).To(Succeed())
+	sum := sha256.Sum256(content)
+	sha := hex.EncodeToString(sum[:])
+
+	g.Expect(checkLocalBundleBlob(filename, &zconfig.BundleBlob{
+		Sha256: sha, SizeBytes: uint64(len(content))})).To(Succeed())
+	g.Expect(checkLocalBundleBlob(filename, &zconfig.BundleBlob{
+		Sha256: sha, SizeBytes: uint64(len(content)) + 1})).ToNot(Succeed())
+	g.Expect(checkLocalBundleBlob(filename, &zconfig.BundleBlob{
+		Sha256:    strings.Repeat("00", sha256.Size),
+		SizeBytes: uint64(len(content))})).ToNot(Succeed())
+}
@@ -0,0 +1,262 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Export, inspection and import of the config checkpoint through the local
+// profile server. The exported checkpoint is posted to the local server,
+// which can ask in the response to inspect or import another checkpoint.
+
+package zedagent
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lf-edge/eve/api/go/profile"
+	"github.com/lf-edge/eve/pkg/pillar/checkpoint"
+	"github.com/lf-edge/eve/pkg/pillar/flextimer"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	fileutils "github.com/lf-edge/eve/pkg/pillar/utils/file"
+	"github.com/lf-edge/eve/pkg/pillar/zedcloud"
+)
+
+const (
+	localCheckpointURLPath               = "/api/v1/checkpoint"
+	localCheckpointPOSTInterval          = time.Minute
+	localCheckpointPOSTThrottledInterval = time.Hour
+)
+
+// lastCheckpointCmdTimestampFile keeps the timestamp of the last processed
+// LocalCheckpointCmd, so that an import is not repeated after reboot
+var lastCheckpointCmdTimestampFile = types.PersistStatusDir + "/lastcheckpointcmdtimestamp"
+
+var throttledLocalCheckpoint bool
+
+// localCheckpointState : result of the last command requested by the local
+// server, reported back in LocalCheckpointInfo
+type localCheckpointState struct {
+	lastCmdTimestamp uint64 // From lastCheckpointCmdTimestampFile
+	inspection       string
+	cmdError         string
+}
+
+// updateLocalCheckpointTicker sets ticker options to the initial value
+// if throttle set, will use localCheckpointPOSTThrottledInterval as interval
+func updateLocalCheckpointTicker(ctx *getconfigContext, throttle bool) {
+	interval := float64(localCheckpointPOSTInterval)
+	if throttle {
+		interval = float64(localCheckpointPOSTThrottledInterval)
+	}
+	max := 1.1 * interval
+	min := 0.8 * max
+	throttledLocalCheckpoint = throttle
+	ctx.localCheckpointPOSTTicker.UpdateRangeTicker(time.Duration(min), time.Duration(max))
+}
+
+func initializeLocalCheckpoint(ctx *getconfigContext) {
+	max := 1.1 * float64(localCheckpointPOSTInterval)
+	min := 0.8 * max
+	ctx.localCheckpointPOSTTicker = flextimer.NewRangeTicker(time.Duration(min), time.Duration(max))
+	ctx.localCheckpoint.lastCmdTimestamp = readCheckpointCmdTimestamp()
+}
+
+func readCheckpointCmdTimestamp() uint64 {
+	if _, err := os.Stat(lastCheckpointCmdTimestampFile); os.IsNotExist(err) {
+		return 0
+	}
+	b, err := fileutils.ReadWithMaxSize(log, lastCheckpointCmdTimestampFile,
+		maxReadSize)
+	if err != nil {
+		log.Errorf("readCheckpointCmdTimestamp read: %s", err)
+		return 0
+	}
+	u, err := strconv.ParseUint(string(b), 10, 64)
+	if err != nil {
+		log.Errorf("readCheckpointCmdTimestamp: %s", err)
+		return 0
+	}
+	log.Noticef("readCheckpointCmdTimestamp: read %d", u)
+	return u
+}
+
+func saveCheckpointCmdTimestamp(ctx *getconfigContext) {
+	b := []byte(fmt.Sprintf("%v", ctx.localCheckpoint.lastCmdTimestamp))
+	err := fileutils.WriteRename(lastCheckpointCmdTimestampFile, b)
+	if err != nil {
+		log.Errorf("saveCheckpointCmdTimestamp write: %s", err)
+	}
+}
+
+func triggerLocalCheckpointPOST(ctx *getconfigContext) {
+	log.Functionf("Triggering POST for %s to local server", localCheckpointURLPath)
+	if throttledLocalCheckpoint {
+		log.Functionln("throttledLocalCheckpoint flag set")
+		return
+	}
+	ctx.localCheckpointPOSTTicker.TickNow()
+}
+
+// Run a periodic POST request to send the exported config checkpoint to
+// local server and optionally receive a checkpoint command in the response.
+func localCheckpointPOSTTask(ctx *getconfigContext) {
+
+	log.Functionf("localCheckpointPOSTTask: waiting for localCheckpointPOSTTicker")
+	// wait for the first trigger
+	<-ctx.localCheckpointPOSTTicker.C
+	log.Functionln("localCheckpointPOSTTask: waiting for localCheckpointPOSTTicker done")
+	// trigger again to pass into the loop
+	triggerLocalCheckpointPOST(ctx)
+
+	wdName := agentName + "-localcheckpoint"
+
+	// Run a periodic timer so we always update StillRunning
+	stillRunning := time.NewTicker(25 * time.Second)
+	ctx.zedagentCtx.ps.StillRunning(wdName, warningTime, errorTime)
+	ctx.zedagentCtx.ps.RegisterFileWatchdog(wdName)
+
+	for {
+		select {
+		case <-ctx.localCheckpointPOSTTicker.C:
+			start := time.Now()
+			cmd := postLocalCheckpoint(ctx)
+			if processReceivedCheckpointCmd(ctx, cmd) {
+				// report the result
+				triggerLocalCheckpointPOST(ctx)
+			}
+			ctx.zedagentCtx.ps.CheckMaxTimeTopic(wdName, "localCheckpointPOSTTask", start,
+				warningTime, errorTime)
+		case <-stillRunning.C:
+		}
+		ctx.zedagentCtx.ps.StillRunning(wdName, warningTime, errorTime)
+	}
+}
+
+// Post the exported config checkpoint to the local server
+// and optionally receive a checkpoint command to run in the response.
+func postLocalCheckpoint(ctx *getconfigContext) *profile.LocalCheckpointCmd {
+	localProfileServer := ctx.localProfileServer
+	if localProfileServer == "" {
+		return nil
+	}
+	localServerURL, err := makeLocalServerBaseURL(localProfileServer)
+	if err != nil {
+		log.Errorf("postLocalCheckpoint: makeLocalServerBaseURL: %v", err)
+		return nil
+	}
+	if !ctx.localServerMap.upToDate {
+		err := updateLocalServerMap(ctx, localServerURL)
+		if err != nil {
+			log.Errorf("postLocalCheckpoint: updateLocalServerMap: %v", err)
+			return nil
+		}
+		// Make sure HasLocalServer is set correctly for the AppInstanceConfig
+		updateHasLocalServer(ctx)
+	}
+	srvMap := ctx.localServerMap.servers
+	if len(srvMap) == 0 {
+		log.Functionf("postLocalCheckpoint: cannot find any configured apps for localServerURL: %s",
+			localServerURL)
+		return nil
+	}
+
+	localInfo := prepareLocalCheckpointInfo(ctx)
+	var errList []string
+	for bridgeName, servers := range srvMap {
+		for _, srv := range servers {
+			fullURL := srv.localServerAddr + localCheckpointURLPath
+			cmd := &profile.LocalCheckpointCmd{}
+			resp, err := zedcloud.SendLocalProto(
+				zedcloudCtx, fullURL, bridgeName, srv.bridgeIP,
+				localInfo, cmd)
+			if err != nil {
+				errList = append(errList, fmt.Sprintf("SendLocalProto: %v", err))
+				continue
+			}
+			switch resp.StatusCode {
+			case http.StatusNotFound:
+				// Throttle sending to be about once per hour.
+				updateLocalCheckpointTicker(ctx, true)
+				return nil
+			case http.StatusOK:
+				if cmd.GetServerToken() != ctx.profileServerToken {
+					errList = append(errList,
+						fmt.Sprintf("invalid token submitted by local server (%s)",
+							cmd.GetServerToken()))
+					continue
+				}
+				updateLocalCheckpointTicker(ctx, false)
+				return cmd
+			case http.StatusNoContent:
+				log.Functionf("Local server %s does not require a checkpoint command to execute",
+					localServerURL)
+				updateLocalCheckpointTicker(ctx, false)
+				return nil
+			default:
+				errList = append(errList, fmt.Sprintf("postLocalCheckpoint: wrong response status code: %d",
+					resp.StatusCode))
+				continue
+			}
+		}
+	}
+	log.Errorf("postLocalCheckpoint: all attempts failed: %s", strings.Join(errList, ";"))
+	return nil
+}
+
+func prepareLocalCheckpointInfo(ctx *getconfigContext) *profile.LocalCheckpointInfo {
+	msg := profile.LocalCheckpointInfo{}
+	b, err := checkpoint.Export(checkpointDirname)
+	if err != nil {
+		msg.ExportError = err.Error()
+	} else {
+		msg.Checkpoint = b
+	}
+	msg.LastCmdTimestamp = ctx.localCheckpoint.lastCmdTimestamp
+	msg.Inspection = ctx.localCheckpoint.inspection
+	msg.CmdError = ctx.localCheckpoint.cmdError
+	return &msg
+}
+
+// processReceivedCheckpointCmd runs the command requested by the local
+// server, unless it was already processed.
+// Returns true if the command was run.
+func processReceivedCheckpointCmd(ctx *getconfigContext,
+	cmd *profile.LocalCheckpointCmd) bool {
+	if cmd == nil {
+		return false
+	}
+	state := &ctx.localCheckpoint
+	if cmd.Timestamp == state.lastCmdTimestamp {
+		log.Functionf("unchanged timestamp %v", state.lastCmdTimestamp)
+		return false
+	}
+	state.inspection = ""
+	state.cmdError = ""
+	switch cmd.Command {
+	case profile.LocalCheckpointCmd_COMMAND_UNSPECIFIED:
+		// Do nothing
+	case profile.LocalCheckpointCmd_COMMAND_INSPECT:
+		out, err := inspectCheckpointBundle(cmd.Checkpoint)
+		if err != nil {
+			log.Errorf("Failed to inspect checkpoint from local profile server: %v", err)
+			state.cmdError = err.Error()
+		}
+		state.inspection = out
+	case profile.LocalCheckpointCmd_COMMAND_IMPORT:
+		log.Noticef("Importing checkpoint from local profile server")
+		if err := importCheckpointBundle(cmd.Checkpoint); err != nil {
+			log.Errorf("Failed to import checkpoint from local profile server: %v", err)
+			state.cmdError = err.Error()
+		} else {
+			log.Noticef("Imported checkpoint from local profile server")
+		}
+	default:
+		state.cmdError = fmt.Sprintf("unsupported command %v", cmd.Command)
+		log.Warnf("processReceivedCheckpointCmd: %s", state.cmdError)
+	}
+	state.lastCmdTimestamp = cmd.Timestamp
+	saveCheckpointCmdTimestamp(ctx)
+	return true
+}
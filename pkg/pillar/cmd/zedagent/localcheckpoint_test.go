@@ -0,0 +1,106 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package zedagent
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/golang/protobuf/proto"
+	zauth "github.com/lf-edge/eve/api/go/auth"
+	zconfig "github.com/lf-edge/eve/api/go/config"
+	"github.com/lf-edge/eve/api/go/profile"
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	uuid "github.com/satori/go.uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// makeCheckpointBundle returns an exported checkpoint with the config of
+// deviceUUID, which does not carry any signature
+func makeCheckpointBundle(t *testing.T, deviceUUID uuid.UUID) []byte {
+	g := NewGomegaWithT(t)
+	configResponse := &zconfig.ConfigResponse{
+		Config: &zconfig.EdgeDevConfig{
+			Id: &zconfig.UUIDandVersion{Uuid: deviceUUID.String()},
+		},
+		ConfigHash: "hash",
+	}
+	payload, err := proto.Marshal(configResponse)
+	g.Expect(err).To(BeNil())
+	signedConfig, err := proto.Marshal(&zauth.AuthContainer{
+		ProtectedPayload: &zauth.AuthBody{Payload: payload},
+	})
+	g.Expect(err).To(BeNil())
+	b, err := proto.Marshal(&zconfig.ConfigCheckpoint{SignedConfig: signedConfig})
+	g.Expect(err).To(BeNil())
+	return b
+}
+
+func TestProcessReceivedCheckpointCmd(t *testing.T) {
+	g := NewGomegaWithT(t)
+	log = base.NewSourceLogObject(logrus.StandardLogger(), agentName, 0)
+	lastCheckpointCmdTimestampFile = filepath.Join(t.TempDir(), "lastcheckpointcmdtimestamp")
+	deviceUUID, err := uuid.NewV4()
+	g.Expect(err).To(BeNil())
+	bundle := makeCheckpointBundle(t, deviceUUID)
+	ctx := &getconfigContext{}
+
+	// no command
+	g.Expect(processReceivedCheckpointCmd(ctx, nil)).To(BeFalse())
+
+	// inspect
+	ran := processReceivedCheckpointCmd(ctx, &profile.LocalCheckpointCmd{
+		Timestamp:  1,
+		Command:    profile.LocalCheckpointCmd_COMMAND_INSPECT,
+		Checkpoint: bundle,
+	})
+	g.Expect(ran).To(BeTrue())
+	g.Expect(ctx.localCheckpoint.cmdError).To(BeEmpty())
+	g.Expect(ctx.localCheckpoint.inspection).To(ContainSubstring("device: " + deviceUUID.String()))
+	g.Expect(ctx.localCheckpoint.inspection).To(ContainSubstring("config hash: hash"))
+	g.Expect(ctx.localCheckpoint.inspection).To(ContainSubstring("signature: NOT VERIFIED"))
+	g.Expect(ctx.localCheckpoint.lastCmdTimestamp).To(BeEquivalentTo(1))
+	b, err := ioutil.ReadFile(lastCheckpointCmdTimestampFile)
+	g.Expect(err).To(BeNil())
+	g.Expect(string(b)).To(Equal("1"))
+	g.Expect(readCheckpointCmdTimestamp()).To(BeEquivalentTo(1))
+
+	// the same command is not run again
+	ran = processReceivedCheckpointCmd(ctx, &profile.LocalCheckpointCmd{
+		Timestamp:  1,
+		Command:    profile.LocalCheckpointCmd_COMMAND_IMPORT,
+		Checkpoint: bundle,
+	})
+	g.Expect(ran).To(BeFalse())
+	g.Expect(ctx.localCheckpoint.cmdError).To(BeEmpty())
+
+	// inspect garbage
+	ran = processReceivedCheckpointCmd(ctx, &profile.LocalCheckpointCmd{
+		Timestamp:  2,
+		Command:    profile.LocalCheckpointCmd_COMMAND_INSPECT,
+		Checkpoint: []byte("garbage"),
+	})
+	g.Expect(ran).To(BeTrue())
+	g.Expect(ctx.localCheckpoint.cmdError).To(ContainSubstring("failed to decode"))
+	g.Expect(ctx.localCheckpoint.inspection).To(BeEmpty())
+
+	// import of a checkpoint which cannot be verified is rejected
+	ran = processReceivedCheckpointCmd(ctx, &profile.LocalCheckpointCmd{
+		Timestamp:  3,
+		Command:    profile.LocalCheckpointCmd_COMMAND_IMPORT,
+		Checkpoint: bundle,
+	})
+	g.Expect(ran).To(BeTrue())
+	g.Expect(ctx.localCheckpoint.cmdError).NotTo(BeEmpty())
+	g.Expect(ctx.localCheckpoint.lastCmdTimestamp).To(BeEquivalentTo(3))
+
+	// the result is reported to the local server
+	info := prepareLocalCheckpointInfo(ctx)
+	g.Expect(info.LastCmdTimestamp).To(BeEquivalentTo(3))
+	g.Expect(info.CmdError).To(Equal(ctx.localCheckpoint.cmdError))
+	g.Expect(info.Checkpoint == nil).To(Equal(info.ExportError != ""))
+}
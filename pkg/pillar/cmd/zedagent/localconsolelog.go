@@ -0,0 +1,207 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package zedagent
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lf-edge/eve/api/go/logs"
+	"github.com/lf-edge/eve/api/go/profile"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	"github.com/lf-edge/eve/pkg/pillar/zedcloud"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	localAppConsoleLogURLPath = "/api/v1/appconsolelog"
+	defaultConsoleLogLines    = 100
+	maxConsoleLogLines        = 1000
+	maxConsoleLogLineSize     = 64 * 1024
+)
+
+// localConsoleLogRequest : console output requested by COMMAND_CONSOLE_LOG
+type localConsoleLogRequest struct {
+	appUUID     uuid.UUID
+	displayName string
+	timestamp   uint64
+	maxLines    uint32
+}
+
+// postLocalAppConsoleLogs submits the requested console output of apps
+// to the local server. Failures are only logged, the local server can
+// request the console output again.
+func postLocalAppConsoleLogs(ctx *getconfigContext, reqs []localConsoleLogRequest) {
+	for _, req := range reqs {
+		msg := &profile.LocalAppConsoleLog{
+			Id:           req.appUUID.String(),
+			Name:         req.displayName,
+			CmdTimestamp: req.timestamp,
+		}
+		maxLines := int(req.maxLines)
+		if maxLines == 0 {
+			maxLines = defaultConsoleLogLines
+		} else if maxLines > maxConsoleLogLines {
+			maxLines = maxConsoleLogLines
+		}
+		lines, err := readAppConsoleLog(req.appUUID.String(), maxLines)
+		if err != nil {
+			log.Errorf("postLocalAppConsoleLogs(%s): %v", req.appUUID, err)
+			msg.Error = err.Error()
+		}
+		msg.Lines = lines
+		if err := postLocalAppConsoleLog(ctx, msg); err != nil {
+			log.Errorf("postLocalAppConsoleLogs(%s): %v", req.appUUID, err)
+		}
+	}
+}
+
+func postLocalAppConsoleLog(ctx *getconfigContext, msg *profile.LocalAppConsoleLog) error {
+	localProfileServer := ctx.localProfileServer
+	if localProfileServer == "" {
+		return fmt.Errorf("no local profile server")
+	}
+	localServerURL, err := makeLocalServerBaseURL(localProfileServer)
+	if err != nil {
+		return fmt.Errorf("makeLocalServerBaseURL: %v", err)
+	}
+	srvMap := ctx.localServerMap.servers
+	var errList []string
+	for bridgeName, servers := range srvMap {
+		for _, srv := range servers {
+			fullURL := srv.localServerAddr + localAppConsoleLogURLPath
+			resp, err := zedcloud.SendLocalProto(
+				zedcloudCtx, fullURL, bridgeName, srv.bridgeIP, msg, nil)
+			if err != nil {
+				errList = append(errList, fmt.Sprintf("SendLocalProto: %v", err))
+				continue
+			}
+			switch resp.StatusCode {
+			case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+				return nil
+			default:
+				errList = append(errList, fmt.Sprintf("SendLocal: wrong response status code: %d",
+					resp.StatusCode))
+			}
+		}
+	}
+	return fmt.Errorf("all attempts to submit to %s failed: %s",
+		localServerURL, strings.Join(errList, ";"))
+}
+
+// readAppConsoleLog returns the last maxLines lines of console output of
+// the app, as collected by newlogd. The file being collected is read, and
+// if it has fewer lines, the last file compressed for upload as well.
+func readAppConsoleLog(appUUID string, maxLines int) ([]string, error) {
+	collected, err := newestFile(filepath.Join(types.NewlogCollectDir,
+		types.AppPrefix+appUUID+".log*"))
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	if collected != "" {
+		lines, err = readLogLines(collected, false, maxLines)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(lines) < maxLines {
+		var gzipped string
+		for _, dir := range []string{types.NewlogUploadAppDir, types.NewlogKeepSentQueueDir} {
+			f, err := newestFile(filepath.Join(dir,
+				types.AppPrefix+"*"+appUUID+types.AppSuffix+"*.gz"))
+			if err != nil {
+				return nil, err
+			}
+			if f != "" && (gzipped == "" || newerFile(f, gzipped)) {
+				gzipped = f
+			}
+		}
+		if gzipped != "" {
+			older, err := readLogLines(gzipped, true, maxLines-len(lines))
+			if err != nil {
+				return nil, err
+			}
+			lines = append(older, lines...)
+		}
+	}
+	return lines, nil
+}
+
+// newestFile returns the most recently modified file matching pattern,
+// or an empty string if there is none.
+func newestFile(pattern string) (string, error) {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return "", err
+	}
+	var newest string
+	for _, f := range files {
+		if newest == "" || newerFile(f, newest) {
+			newest = f
+		}
+	}
+	return newest, nil
+}
+
+func newerFile(f1, f2 string) bool {
+	info1, err := os.Stat(f1)
+	if err != nil {
+		return false
+	}
+	info2, err := os.Stat(f2)
+	if err != nil {
+		return true
+	}
+	return info1.ModTime().After(info2.ModTime())
+}
+
+// readLogLines returns the content of the last maxLines log entries
+// in the file written by newlogd.
+func readLogLines(filename string, gzipped bool, maxLines int) ([]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if gzipped {
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close()
+		r = gr
+	}
+	// ring buffer with the last maxLines lines
+	lines := make([]string, maxLines)
+	var count int
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4096), maxConsoleLogLineSize)
+	for scanner.Scan() {
+		var entry logs.LogEntry
+		// skip metadata and lines which are not log entries
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil ||
+			entry.Content == "" {
+			continue
+		}
+		lines[count%maxLines] = entry.Content
+		count++
+	}
+	if err := scanner.Err(); err != nil && err != bufio.ErrTooLong {
+		return nil, err
+	}
+	if count <= maxLines {
+		return lines[:count], nil
+	}
+	start := count % maxLines
+	return append(lines[start:], lines[:start]...), nil
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package zedagent
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/lf-edge/eve/api/go/logs"
+)
+
+func writeTestLogFile(g *GomegaWithT, filename string, gzipped bool, lines int) {
+	f, err := os.Create(filename)
+	g.Expect(err).To(BeNil())
+	defer f.Close()
+	var w io.Writer = f
+	var gw *gzip.Writer
+	if gzipped {
+		gw = gzip.NewWriter(f)
+		w = gw
+	}
+	// metadata line, as written by newlogd
+	_, err = w.Write([]byte("{\"appName\":\"test\"}\n"))
+	g.Expect(err).To(BeNil())
+	for i := 0; i < lines; i++ {
+		b, err := json.Marshal(&logs.LogEntry{
+			Source:  "guest_vm-test",
+			Content: fmt.Sprintf("line %d", i),
+		})
+		g.Expect(err).To(BeNil())
+		_, err = w.Write(append(b, '\n'))
+		g.Expect(err).To(BeNil())
+	}
+	if gw != nil {
+		g.Expect(gw.Close()).To(Succeed())
+	}
+}
+
+func TestReadLogLines(t *testing.T) {
+	g := NewGomegaWithT(t)
+	dir, err := ioutil.TempDir("", "consolelog")
+	g.Expect(err).To(BeNil())
+	defer os.RemoveAll(dir)
+
+	for _, gzipped := range []bool{false, true} {
+		filename := filepath.Join(dir, fmt.Sprintf("log-%t", gzipped))
+		writeTestLogFile(g, filename, gzipped, 5)
+
+		lines, err := readLogLines(filename, gzipped, 3)
+		g.Expect(err).To(BeNil())
+		g.Expect(lines).To(Equal([]string{"line 2", "line 3", "line 4"}))
+
+		lines, err = readLogLines(filename, gzipped, 10)
+		g.Expect(err).To(BeNil())
+		g.Expect(lines).To(Equal([]string{
+			"line 0", "line 1", "line 2", "line 3", "line 4"}))
+	}
+}
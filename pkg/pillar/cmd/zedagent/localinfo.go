@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/golang/protobuf/ptypes"
+	zconfig "github.com/lf-edge/eve/api/go/config"
 	"github.com/lf-edge/eve/api/go/info"
 	"github.com/lf-edge/eve/api/go/profile"
 	"github.com/lf-edge/eve/pkg/pillar/flextimer"
@@ -98,7 +99,8 @@ func localAppInfoPOSTTask(ctx *getconfigContext) {
 		case <-ctx.localAppInfoPOSTTicker.C:
 			start := time.Now()
 			appCmds := postLocalAppInfo(ctx)
-			processReceivedAppCommands(ctx, appCmds)
+			consoleLogReqs := processReceivedAppCommands(ctx, appCmds)
+			postLocalAppConsoleLogs(ctx, consoleLogReqs)
 			ctx.zedagentCtx.ps.CheckMaxTimeTopic(wdName, "localAppInfoPOSTTask", start,
 				warningTime, errorTime)
 		case <-stillRunning.C:
@@ -180,7 +182,11 @@ func postLocalAppInfo(ctx *getconfigContext) *profile.LocalAppCmdList {
 	return nil
 }
 
-func processReceivedAppCommands(ctx *getconfigContext, cmdList *profile.LocalAppCmdList) {
+// processReceivedAppCommands triggers the received app commands and returns
+// the requests to submit console output, which should be done without
+// ctx.localCommands locked.
+func processReceivedAppCommands(ctx *getconfigContext,
+	cmdList *profile.LocalAppCmdList) (consoleLogReqs []localConsoleLogRequest) {
 	ctx.localCommands.Lock()
 	defer ctx.localCommands.Unlock()
 	if cmdList == nil {
@@ -188,7 +194,7 @@ func processReceivedAppCommands(ctx *getconfigContext, cmdList *profile.LocalApp
 		if !ctx.localCommands.Empty() {
 			touchLocalCommands()
 		}
-		return
+		return nil
 	}
 
 	var cmdChanges, volChanges bool
@@ -243,14 +249,49 @@ func processReceivedAppCommands(ctx *getconfigContext, cmdList *profile.LocalApp
 		appCmd.LocalServerTimestamp = appCmdReq.Timestamp
 		appCmd.DeviceTimestamp = time.Now()
 		appCmd.Completed = false
+		appCmd.Error = ""
 		cmdChanges = true
 
 		// Update and re-publish configuration to trigger the operation.
 		timestamp := appCmd.DeviceTimestamp.String()
-		changedVolumes := triggerLocalCommand(ctx, command, appInst, timestamp)
+		changedVolumes, err := triggerLocalCommand(ctx, command, appInst,
+			appCmdReq.EnvVariables, timestamp)
 		if changedVolumes {
 			volChanges = true
 		}
+		if err != nil {
+			// Rejected command is complete, the error is reported
+			// in LocalAppInfo.
+			log.Errorf("Local app command %v for %s rejected: %v",
+				command, appUUID, err)
+			appCmd.Error = err.Error()
+			completeAppCommand(appCmd)
+			continue
+		}
+
+		switch command {
+		case types.AppCommandStart, types.AppCommandConsoleLog:
+			// Start only lifts the local stop, the application state
+			// is reported in LocalAppInfo.
+			// Console output is submitted right after processing
+			// the received commands.
+			completeAppCommand(appCmd)
+		default:
+			// The command may be complete already, e.g. stop of
+			// an application which is not running.
+			appStatus := lookupAppInstanceStatus(ctx.zedagentCtx, appUUID.String())
+			if appStatus != nil {
+				checkAppCommandCompletion(appCmd, *appStatus)
+			}
+		}
+		if command == types.AppCommandConsoleLog {
+			consoleLogReqs = append(consoleLogReqs, localConsoleLogRequest{
+				appUUID:     appUUID,
+				displayName: appInst.DisplayName,
+				timestamp:   appCmdReq.Timestamp,
+				maxLines:    appCmdReq.MaxConsoleLogLines,
+			})
+		}
 	}
 
 	// Persist accepted application commands and counters.
@@ -265,12 +306,14 @@ func processReceivedAppCommands(ctx *getconfigContext, cmdList *profile.LocalApp
 	if volChanges {
 		signalVolumeConfigRestarted(ctx)
 	}
+	return consoleLogReqs
 }
 
 // Trigger application command (restart, purge, ...) requested via Local profile server.
 // TODO: move this logic to zedmanager
 func triggerLocalCommand(ctx *getconfigContext, cmd types.AppCommand,
-	app *types.AppInstanceConfig, timestamp string) (changedVolumes bool) {
+	app *types.AppInstanceConfig, envVariables map[string]string,
+	timestamp string) (changedVolumes bool, err error) {
 	// Get current local counters of the application.
 	appUUID := app.UUIDandVersion.UUID
 	appCounters, hasCounters := ctx.localCommands.AppCounters[appUUID.String()]
@@ -329,12 +372,81 @@ func triggerLocalCommand(ctx *getconfigContext, cmd types.AppCommand,
 			ctx.localCommands.VolumeGenCounters[uuid] = localGenCounter
 			vr.LocalGenerationCounter = localGenCounter
 			volume.LocalGenerationCounter = localGenCounter
+			// Snapshots of the purged volume are gone with it.
+			delete(ctx.localCommands.VolumeSnapshotCounters, uuid)
+			volume.LocalSnapshotCounter = 0
+			if volCmd, hasCmd := ctx.localCommands.VolumeCommands[uuid]; hasCmd {
+				completeVolumeCommand(volCmd)
+			}
 			publishVolumeConfig(ctx, volume)
 			changedVolumes = true
 		}
 		checkAndPublishAppInstanceConfig(ctx, *app)
+
+	case types.AppCommandStop, types.AppCommandStart:
+		// To stop (start) the application we take the previously published
+		// app instance config, mark it as (not) stopped locally and re-publish
+		// the updated configuration. The mark is persisted, so that the application
+		// remains stopped after reboot and config changes made by the controller.
+		appConfig := getLocalAppConfig(ctx, appUUID.String())
+		appConfig.Stopped = cmd == types.AppCommandStop
+		app.LocalStopped = appConfig.Stopped
+		checkAndPublishAppInstanceConfig(ctx, *app)
+
+	case types.AppCommandSetEnv:
+		// Environment variables can be passed only to the container
+		// of the application.
+		if !isContainerApp(ctx, app) {
+			return false, fmt.Errorf("environment variables can be set " +
+				"only for container applications")
+		}
+		// Environment variables are applied when the application starts,
+		// so we restart it the same way as with AppCommandRestart.
+		if len(envVariables) == 0 {
+			envVariables = nil
+		}
+		appConfig := getLocalAppConfig(ctx, appUUID.String())
+		appConfig.EnvVariables = envVariables
+		app.LocalEnvVariables = envVariables
+		appCounters.RestartCmd.Counter++
+		appCounters.RestartCmd.ApplyTime = timestamp
+		app.LocalRestartCmd = appCounters.RestartCmd
+		checkAndPublishAppInstanceConfig(ctx, *app)
 	}
-	return changedVolumes
+	return changedVolumes, nil
+}
+
+// isContainerApp returns true if the application runs from a container,
+// i.e. its first volume is created from a container image.
+func isContainerApp(ctx *getconfigContext, app *types.AppInstanceConfig) bool {
+	if len(app.VolumeRefConfigList) == 0 {
+		return false
+	}
+	volObj, _ := ctx.pubVolumeConfig.Get(app.VolumeRefConfigList[0].VolumeKey())
+	if volObj == nil {
+		return false
+	}
+	volume := volObj.(types.VolumeConfig)
+	ctObj, _ := ctx.pubContentTreeConfig.Get(volume.ContentID.String())
+	if ctObj == nil {
+		return false
+	}
+	return ctObj.(types.ContentTreeConfig).Format == zconfig.Format_CONTAINER
+}
+
+// getLocalAppConfig returns the local config of the application, creating it
+// if needed.
+// ctx.localCommands should be locked!
+func getLocalAppConfig(ctx *getconfigContext, appUUID string) *types.LocalAppConfig {
+	appConfig, hasConfig := ctx.localCommands.AppConfigs[appUUID]
+	if !hasConfig {
+		appConfig = &types.LocalAppConfig{}
+		if ctx.localCommands.AppConfigs == nil {
+			ctx.localCommands.AppConfigs = make(map[string]*types.LocalAppConfig)
+		}
+		ctx.localCommands.AppConfigs[appUUID] = appConfig
+	}
+	return appConfig
 }
 
 func processAppCommandStatus(
@@ -347,35 +459,48 @@ func processAppCommandStatus(
 		// This app received no local command requests.
 		return
 	}
+	if checkAppCommandCompletion(appCmd, appStatus) {
+		persistLocalCommands(ctx.localCommands)
+	}
+}
+
+// checkAppCommandCompletion marks the command as completed if appStatus
+// shows it is. Returns true if it was marked.
+func checkAppCommandCompletion(
+	appCmd *types.LocalAppCommand, appStatus types.AppInstanceStatus) bool {
 	if appCmd.Completed {
 		// Nothing to update.
-		return
+		return false
 	}
 	if appStatus.PurgeInprogress != types.NotInprogress ||
 		appStatus.RestartInprogress != types.NotInprogress {
 		// A command is still ongoing.
-		return
+		return false
 	}
-	var updated bool
+	halted := !appStatus.Activated && !appStatus.ActivateInprogress
+	var completed bool
 	switch appCmd.Command {
 	case types.AppCommandRestart:
-		if appStatus.RestartStartedAt.After(appCmd.DeviceTimestamp) {
-			appCmd.Completed = true
-			appCmd.LastCompletedTimestamp = appCmd.LocalServerTimestamp
-			updated = true
-			log.Noticef("Local restart completed: %+v", appCmd)
-		}
+		completed = appStatus.RestartStartedAt.After(appCmd.DeviceTimestamp)
 	case types.AppCommandPurge:
-		if appStatus.PurgeStartedAt.After(appCmd.DeviceTimestamp) {
-			appCmd.Completed = true
-			appCmd.LastCompletedTimestamp = appCmd.LocalServerTimestamp
-			updated = true
-			log.Noticef("Local purge completed: %+v", appCmd)
-		}
-	}
-	if updated {
-		persistLocalCommands(ctx.localCommands)
-	}
+		completed = appStatus.PurgeStartedAt.After(appCmd.DeviceTimestamp)
+	case types.AppCommandStop:
+		completed = halted
+	case types.AppCommandSetEnv:
+		// A halted application gets the variables when it is started.
+		completed = halted ||
+			appStatus.RestartStartedAt.After(appCmd.DeviceTimestamp)
+	}
+	if completed {
+		completeAppCommand(appCmd)
+	}
+	return completed
+}
+
+func completeAppCommand(appCmd *types.LocalAppCommand) {
+	appCmd.Completed = true
+	appCmd.LastCompletedTimestamp = appCmd.LocalServerTimestamp
+	log.Noticef("Local app command completed: %+v", appCmd)
 }
 
 // Add config submitted for the application via local profile server.
@@ -387,6 +512,11 @@ func addLocalAppConfig(ctx *getconfigContext, appInstance *types.AppInstanceConf
 		appInstance.LocalRestartCmd = appCounters.RestartCmd
 		appInstance.LocalPurgeCmd = appCounters.PurgeCmd
 	}
+	appConfig, hasConfig := ctx.localCommands.AppConfigs[uuid]
+	if hasConfig {
+		appInstance.LocalStopped = appConfig.Stopped
+		appInstance.LocalEnvVariables = appConfig.EnvVariables
+	}
 	for i := range appInstance.VolumeRefConfigList {
 		vr := &appInstance.VolumeRefConfigList[i]
 		uuid = vr.VolumeID.String()
@@ -399,6 +529,7 @@ func addLocalAppConfig(ctx *getconfigContext, appInstance *types.AppInstanceConf
 func delLocalAppConfig(ctx *getconfigContext, appUUID string) {
 	delete(ctx.localCommands.AppCommands, appUUID)
 	delete(ctx.localCommands.AppCounters, appUUID)
+	delete(ctx.localCommands.AppConfigs, appUUID)
 	persistLocalCommands(ctx.localCommands)
 }
 
@@ -407,12 +538,15 @@ func delLocalAppConfig(ctx *getconfigContext, appUUID string) {
 func addLocalVolumeConfig(ctx *getconfigContext, volumeConfig *types.VolumeConfig) {
 	uuid := volumeConfig.VolumeID.String()
 	volumeConfig.LocalGenerationCounter = ctx.localCommands.VolumeGenCounters[uuid]
+	volumeConfig.LocalSnapshotCounter = ctx.localCommands.VolumeSnapshotCounters[uuid]
 }
 
 // Delete all local config for this volume.
 // ctx.localCommands should be locked!
 func delLocalVolumeConfig(ctx *getconfigContext, volumeUUID string) {
 	delete(ctx.localCommands.VolumeGenCounters, volumeUUID)
+	delete(ctx.localCommands.VolumeCommands, volumeUUID)
+	delete(ctx.localCommands.VolumeSnapshotCounters, volumeUUID)
 	persistLocalCommands(ctx.localCommands)
 }
 
@@ -430,6 +564,15 @@ func prepareLocalAppInfo(ctx *getconfigContext) *profile.LocalAppInfoList {
 		zinfoAppInst.State = ais.State.ZSwState()
 		if appCmd, hasEntry := ctx.localCommands.AppCommands[zinfoAppInst.Id]; hasEntry {
 			zinfoAppInst.LastCmdTimestamp = appCmd.LastCompletedTimestamp
+			if appCmd.Error != "" && ais.Error == "" {
+				zinfoAppInst.Err = encodeErrorInfo(types.ErrorDescription{
+					Error:     appCmd.Error,
+					ErrorTime: appCmd.DeviceTimestamp,
+				})
+			}
+		}
+		if appConfig, hasEntry := ctx.localCommands.AppConfigs[zinfoAppInst.Id]; hasEntry {
+			zinfoAppInst.StoppedLocally = appConfig.Stopped
 		}
 		msg.AppsInfo = append(msg.AppsInfo, zinfoAppInst)
 		return true
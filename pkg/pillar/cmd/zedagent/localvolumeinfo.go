@@ -0,0 +1,331 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package zedagent
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/lf-edge/eve/api/go/profile"
+	"github.com/lf-edge/eve/pkg/pillar/flextimer"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	"github.com/lf-edge/eve/pkg/pillar/zedcloud"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	localVolumeInfoURLPath               = "/api/v1/volumeinfo"
+	localVolumeInfoPOSTInterval          = time.Minute
+	localVolumeInfoPOSTThrottledInterval = time.Hour
+)
+
+var throttledLocalVolumeInfo bool
+
+//updateLocalVolumeInfoTicker sets ticker options to the initial value
+//if throttle set, will use localVolumeInfoPOSTThrottledInterval as interval
+func updateLocalVolumeInfoTicker(ctx *getconfigContext, throttle bool) {
+	interval := float64(localVolumeInfoPOSTInterval)
+	if throttle {
+		interval = float64(localVolumeInfoPOSTThrottledInterval)
+	}
+	max := 1.1 * interval
+	min := 0.8 * max
+	throttledLocalVolumeInfo = throttle
+	ctx.localVolumeInfoPOSTTicker.UpdateRangeTicker(time.Duration(min), time.Duration(max))
+}
+
+func initializeLocalVolumeInfo(ctx *getconfigContext) {
+	max := 1.1 * float64(localVolumeInfoPOSTInterval)
+	min := 0.8 * max
+	ctx.localVolumeInfoPOSTTicker = flextimer.NewRangeTicker(time.Duration(min), time.Duration(max))
+}
+
+func triggerLocalVolumeInfoPOST(ctx *getconfigContext) {
+	log.Functionf("Triggering POST for %s to local server", localVolumeInfoURLPath)
+	if throttledLocalVolumeInfo {
+		log.Functionln("throttledLocalVolumeInfo flag set")
+		return
+	}
+	ctx.localVolumeInfoPOSTTicker.TickNow()
+}
+
+// Run a periodic POST request to send information message about volumes to local server
+// and optionally receive volume commands to run in the response.
+func localVolumeInfoPOSTTask(ctx *getconfigContext) {
+
+	log.Functionf("localVolumeInfoPOSTTask: waiting for localVolumeInfoPOSTTicker")
+	// wait for the first trigger
+	<-ctx.localVolumeInfoPOSTTicker.C
+	log.Functionln("localVolumeInfoPOSTTask: waiting for localVolumeInfoPOSTTicker done")
+	// trigger again to pass into the loop
+	triggerLocalVolumeInfoPOST(ctx)
+
+	wdName := agentName + "-localvolumeinfo"
+
+	// Run a periodic timer so we always update StillRunning
+	stillRunning := time.NewTicker(25 * time.Second)
+	ctx.zedagentCtx.ps.StillRunning(wdName, warningTime, errorTime)
+	ctx.zedagentCtx.ps.RegisterFileWatchdog(wdName)
+
+	for {
+		select {
+		case <-ctx.localVolumeInfoPOSTTicker.C:
+			start := time.Now()
+			volumeCmds := postLocalVolumeInfo(ctx)
+			processReceivedVolumeCommands(ctx, volumeCmds)
+			ctx.zedagentCtx.ps.CheckMaxTimeTopic(wdName, "localVolumeInfoPOSTTask", start,
+				warningTime, errorTime)
+		case <-stillRunning.C:
+		}
+		ctx.zedagentCtx.ps.StillRunning(wdName, warningTime, errorTime)
+	}
+}
+
+// Post the current state of volumes to the local server
+// and optionally receive a set of volume commands to run in the response.
+func postLocalVolumeInfo(ctx *getconfigContext) *profile.LocalVolumeCmdList {
+	localProfileServer := ctx.localProfileServer
+	if localProfileServer == "" {
+		return nil
+	}
+	localServerURL, err := makeLocalServerBaseURL(localProfileServer)
+	if err != nil {
+		log.Errorf("sendLocalVolumeInfo: makeLocalServerBaseURL: %v", err)
+		return nil
+	}
+	if !ctx.localServerMap.upToDate {
+		err := updateLocalServerMap(ctx, localServerURL)
+		if err != nil {
+			log.Errorf("sendLocalVolumeInfo: updateLocalServerMap: %v", err)
+			return nil
+		}
+		// Make sure HasLocalServer is set correctly for the AppInstanceConfig
+		updateHasLocalServer(ctx)
+	}
+	srvMap := ctx.localServerMap.servers
+	if len(srvMap) == 0 {
+		log.Functionf("sendLocalVolumeInfo: cannot find any configured apps for localServerURL: %s",
+			localServerURL)
+		return nil
+	}
+
+	localInfo := prepareLocalVolumeInfo(ctx)
+	var errList []string
+	for bridgeName, servers := range srvMap {
+		for _, srv := range servers {
+			fullURL := srv.localServerAddr + localVolumeInfoURLPath
+			volumeCmds := &profile.LocalVolumeCmdList{}
+			resp, err := zedcloud.SendLocalProto(
+				zedcloudCtx, fullURL, bridgeName, srv.bridgeIP, localInfo, volumeCmds)
+			if err != nil {
+				errList = append(errList, fmt.Sprintf("SendLocalProto: %v", err))
+				continue
+			}
+			switch resp.StatusCode {
+			case http.StatusNotFound:
+				// Throttle sending to be about once per hour.
+				updateLocalVolumeInfoTicker(ctx, true)
+				return nil
+			case http.StatusOK:
+				if len(volumeCmds.VolumeCommands) != 0 {
+					if volumeCmds.GetServerToken() != ctx.profileServerToken {
+						errList = append(errList,
+							fmt.Sprintf("invalid token submitted by local server (%s)", volumeCmds.GetServerToken()))
+						continue
+					}
+					updateLocalVolumeInfoTicker(ctx, false)
+					return volumeCmds
+				}
+				// No content in the response.
+				fallthrough
+			case http.StatusNoContent:
+				log.Functionf("Local server %s does not require additional volume commands to execute",
+					localServerURL)
+				updateLocalVolumeInfoTicker(ctx, false)
+				return nil
+			default:
+				errList = append(errList, fmt.Sprintf("SendLocal: wrong response status code: %d",
+					resp.StatusCode))
+				continue
+			}
+		}
+	}
+	log.Errorf("sendLocalVolumeInfo: all attempts failed: %s", strings.Join(errList, ";"))
+	return nil
+}
+
+func processReceivedVolumeCommands(ctx *getconfigContext, cmdList *profile.LocalVolumeCmdList) {
+	ctx.localCommands.Lock()
+	defer ctx.localCommands.Unlock()
+	if cmdList == nil {
+		// Nothing requested by local server, just refresh the persisted config.
+		if !ctx.localCommands.Empty() {
+			touchLocalCommands()
+		}
+		return
+	}
+
+	var cmdChanges bool
+	processedVolumes := make(map[string]struct{})
+	for _, volumeCmdReq := range cmdList.VolumeCommands {
+		var err error
+		volumeUUID := nilUUID
+		if volumeCmdReq.Id != "" {
+			volumeUUID, err = uuid.FromString(volumeCmdReq.Id)
+			if err != nil {
+				log.Warnf("Failed to parse UUID from volume command request: %v", err)
+				continue
+			}
+		}
+		displayName := volumeCmdReq.Displayname
+		if volumeUUID == nilUUID && displayName == "" {
+			log.Warnf("Volume command request is missing both UUID and display name: %+v",
+				volumeCmdReq)
+			continue
+		}
+		// Try to find the volume.
+		volume := findVolume(ctx, volumeUUID, displayName)
+		if volume == nil {
+			log.Warnf("Failed to find volume with UUID=%s, displayName=%s",
+				volumeUUID, displayName)
+			continue
+		}
+		volumeUUID = volume.VolumeID
+		if _, duplicate := processedVolumes[volumeUUID.String()]; duplicate {
+			log.Warnf("Multiple commands requested for volume with UUID=%s",
+				volumeUUID)
+			continue
+		}
+		processedVolumes[volumeUUID.String()] = struct{}{}
+
+		// Accept (or skip already accepted) volume command.
+		command := types.VolumeCommand(volumeCmdReq.Command)
+		volumeCmd, hasLocalCmd := ctx.localCommands.VolumeCommands[volumeUUID.String()]
+		if !hasLocalCmd {
+			volumeCmd = &types.LocalVolumeCommand{}
+			if ctx.localCommands.VolumeCommands == nil {
+				ctx.localCommands.VolumeCommands = make(map[string]*types.LocalVolumeCommand)
+			}
+			ctx.localCommands.VolumeCommands[volumeUUID.String()] = volumeCmd
+		}
+		if volumeCmd.Command == command &&
+			volumeCmd.LocalServerTimestamp == volumeCmdReq.Timestamp {
+			// already accepted
+			continue
+		}
+		volumeCmd.Command = command
+		volumeCmd.LocalServerTimestamp = volumeCmdReq.Timestamp
+		volumeCmd.Completed = false
+		cmdChanges = true
+
+		// Update and re-publish configuration to trigger the operation.
+		switch command {
+		case types.VolumeCommandSnapshot:
+			// To trigger volume snapshot we take the previously published
+			// volume config, increase the local-snapshot counter by 1
+			// and re-publish the updated configuration.
+			if ctx.localCommands.VolumeSnapshotCounters == nil {
+				ctx.localCommands.VolumeSnapshotCounters = make(map[string]uint32)
+			}
+			counter := ctx.localCommands.VolumeSnapshotCounters[volumeUUID.String()] + 1
+			ctx.localCommands.VolumeSnapshotCounters[volumeUUID.String()] = counter
+			volumeCmd.SnapshotCounter = counter
+			volume.LocalSnapshotCounter = counter
+			publishVolumeConfig(ctx, *volume)
+		default:
+			completeVolumeCommand(volumeCmd)
+		}
+	}
+
+	// Persist accepted volume commands and counters.
+	if cmdChanges {
+		persistLocalCommands(ctx.localCommands)
+	} else {
+		// No actual configuration change to apply, just refresh the persisted config.
+		touchLocalCommands()
+	}
+}
+
+func processVolumeCommandStatus(
+	ctx *getconfigContext, volumeStatus types.VolumeStatus) {
+	ctx.localCommands.Lock()
+	defer ctx.localCommands.Unlock()
+	uuid := volumeStatus.VolumeID.String()
+	volumeCmd, hasLocalCmd := ctx.localCommands.VolumeCommands[uuid]
+	if !hasLocalCmd {
+		// This volume received no local command requests.
+		return
+	}
+	if volumeCmd.Completed {
+		// Nothing to update.
+		return
+	}
+	switch volumeCmd.Command {
+	case types.VolumeCommandSnapshot:
+		if volumeStatus.LocalSnapshotInprogress ||
+			volumeStatus.LocalSnapshotCounter != volumeCmd.SnapshotCounter {
+			return
+		}
+	}
+	completeVolumeCommand(volumeCmd)
+	persistLocalCommands(ctx.localCommands)
+}
+
+func completeVolumeCommand(volumeCmd *types.LocalVolumeCommand) {
+	volumeCmd.Completed = true
+	volumeCmd.LastCompletedTimestamp = volumeCmd.LocalServerTimestamp
+	log.Noticef("Local volume command completed: %+v", volumeCmd)
+}
+
+func prepareLocalVolumeInfo(ctx *getconfigContext) *profile.LocalVolumeInfoList {
+	msg := profile.LocalVolumeInfoList{}
+	ctx.localCommands.Lock()
+	defer ctx.localCommands.Unlock()
+	addVolumeFunc := func(key string, value interface{}) bool {
+		vs := value.(types.VolumeStatus)
+		zinfoVolume := new(profile.LocalVolumeInfo)
+		zinfoVolume.Id = vs.VolumeID.String()
+		zinfoVolume.Name = vs.DisplayName
+		zinfoVolume.Err = encodeErrorInfo(vs.ErrorAndTimeWithSource.ErrorDescription)
+		zinfoVolume.State = vs.State.ZSwState()
+		zinfoVolume.MaxSizeBytes = vs.MaxVolSize
+		if vs.FileLocation != "" {
+			appDiskMetric := lookupAppDiskMetric(ctx.zedagentCtx, vs.FileLocation)
+			if appDiskMetric != nil {
+				zinfoVolume.UsedBytes = appDiskMetric.UsedBytes
+			}
+		}
+		if volumeCmd, hasEntry := ctx.localCommands.VolumeCommands[zinfoVolume.Id]; hasEntry {
+			zinfoVolume.LastCmdTimestamp = volumeCmd.LastCompletedTimestamp
+		}
+		if !vs.LocalSnapshotTime.IsZero() {
+			snapshotTime, _ := ptypes.TimestampProto(vs.LocalSnapshotTime)
+			zinfoVolume.LastSnapshotTime = snapshotTime
+		}
+		zinfoVolume.SnapshotError = vs.LocalSnapshotError
+		msg.VolumesInfo = append(msg.VolumesInfo, zinfoVolume)
+		return true
+	}
+	ctx.subVolumeStatus.Iterate(addVolumeFunc)
+	return &msg
+}
+
+func findVolume(
+	ctx *getconfigContext, volumeUUID uuid.UUID, displayName string) (volume *types.VolumeConfig) {
+	matchVolume := func(_ string, value interface{}) bool {
+		vc := value.(types.VolumeConfig)
+		if (volumeUUID == nilUUID || volumeUUID == vc.VolumeID) &&
+			(displayName == "" || displayName == vc.DisplayName) {
+			volume = &vc
+			// stop iteration
+			return false
+		}
+		return true
+	}
+	ctx.pubVolumeConfig.Iterate(matchVolume)
+	return volume
+}
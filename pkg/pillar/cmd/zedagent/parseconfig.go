@@ -13,6 +13,7 @@ import (
 	"io/ioutil"
 	"net"
 	"os"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -99,6 +100,7 @@ func parseConfig(config *zconfig.EdgeDevConfig, getconfigCtx *getconfigContext,
 		handleControllerCertsSha(ctx, config)
 		parseCipherContext(getconfigCtx, config)
 		parseDatastoreConfig(config, getconfigCtx)
+		parsePeerCacheConfig(config, getconfigCtx)
 		// DeviceIoList has some defaults for Usage and UsagePolicy
 		// used by systemAdapters
 		physioChanged := parseDeviceIoListConfig(config, getconfigCtx)
@@ -296,6 +298,25 @@ func parseBaseOsConfig(getconfigCtx *getconfigContext,
 		baseOs.ContentTreeConfigList = make([]types.ContentTreeConfig,
 			len(cfgOs.Drives))
 		parseContentTreeConfigList(baseOs.ContentTreeConfigList, cfgOs.Drives)
+		for _, cfgDelta := range cfgOs.GetDeltas() {
+			if cfgDelta.GetPatch() == nil {
+				log.Errorf("parseBaseOsConfig: no patch in delta from %s",
+					cfgDelta.GetBaseVersion())
+				continue
+			}
+			delta := types.BaseOsDelta{
+				Format:       cfgDelta.GetFormat(),
+				BaseVersion:  cfgDelta.GetBaseVersion(),
+				BaseSize:     cfgDelta.GetBaseSizeBytes(),
+				BaseSha256:   strings.ToLower(cfgDelta.GetBaseSha256()),
+				TargetSha256: strings.ToLower(cfgDelta.GetTargetSha256()),
+			}
+			patch := make([]types.ContentTreeConfig, 1)
+			parseContentTreeConfigList(patch, []*zconfig.Drive{cfgDelta.GetPatch()})
+			delta.Patch = patch[0]
+			baseOs.Deltas = append(baseOs.Deltas, delta)
+		}
+		baseOs.HealthGates = parseBaseOsHealthGates(cfgOs.GetHealthGates())
 
 		log.Tracef("parseBaseOsConfig publishing %v",
 			baseOs)
@@ -303,6 +324,64 @@ func parseBaseOsConfig(getconfigCtx *getconfigContext,
 	}
 }
 
+// parseBaseOsHealthGates skips gates with invalid UUIDs
+func parseBaseOsHealthGates(cfgGates *zconfig.BaseOSHealthGates) types.BaseOsHealthGates {
+	var gates types.BaseOsHealthGates
+	if cfgGates == nil {
+		return gates
+	}
+	for _, uuidStr := range cfgGates.GetAppInstanceUuids() {
+		id, err := uuid.FromString(uuidStr)
+		if err != nil {
+			log.Errorf("parseBaseOsHealthGates: bad app instance UUID %s: %v",
+				uuidStr, err)
+			continue
+		}
+		gates.AppInstances = append(gates.AppInstances, id)
+	}
+	for _, uuidStr := range cfgGates.GetNetworkInstanceUuids() {
+		id, err := uuid.FromString(uuidStr)
+		if err != nil {
+			log.Errorf("parseBaseOsHealthGates: bad network instance UUID %s: %v",
+				uuidStr, err)
+			continue
+		}
+		gates.NetworkInstances = append(gates.NetworkInstances, id)
+	}
+	for _, cfgProbe := range cfgGates.GetHttpProbes() {
+		id, err := uuid.FromString(cfgProbe.GetNetworkInstanceUuid())
+		if err != nil {
+			log.Errorf("parseBaseOsHealthGates: bad network instance UUID %s for probe %s: %v",
+				cfgProbe.GetNetworkInstanceUuid(), cfgProbe.GetUrl(), err)
+			continue
+		}
+		gates.HTTPProbes = append(gates.HTTPProbes, types.BaseOsHTTPProbe{
+			NetworkInstance: id,
+			URL:             cfgProbe.GetUrl(),
+			ExpectedStatus:  int(cfgProbe.GetExpectedStatus()),
+		})
+	}
+	for _, cfgScript := range cfgGates.GetScripts() {
+		id, err := uuid.FromString(cfgScript.GetAppInstanceUuid())
+		if err != nil {
+			log.Errorf("parseBaseOsHealthGates: bad app instance UUID %s for script: %v",
+				cfgScript.GetAppInstanceUuid(), err)
+			continue
+		}
+		if len(cfgScript.GetCommand()) == 0 {
+			log.Errorf("parseBaseOsHealthGates: no command for script in %s",
+				cfgScript.GetAppInstanceUuid())
+			continue
+		}
+		gates.Scripts = append(gates.Scripts, types.BaseOsScriptCheck{
+			AppInstance: id,
+			Command:     cfgScript.GetCommand(),
+			Timeout:     time.Duration(cfgScript.GetTimeout()) * time.Second,
+		})
+	}
+	return gates
+}
+
 var networkConfigPrevConfigHash []byte
 
 func parseNetworkXObjectConfig(config *zconfig.EdgeDevConfig,
@@ -501,13 +580,84 @@ func publishNetworkInstanceConfig(ctx *getconfigContext,
 
 			parseDnsNameToIpList(apiConfigEntry,
 				&networkInstanceConfig)
+			parseUplinkLoadBalancing(apiConfigEntry,
+				&networkInstanceConfig)
 		}
+		parseAccessPoint(ctx, apiConfigEntry, &networkInstanceConfig)
 
 		ctx.pubNetworkInstanceConfig.Publish(networkInstanceConfig.UUID.String(),
 			networkInstanceConfig)
 	}
 }
 
+func parseUplinkLoadBalancing(apiConfigEntry *zconfig.NetworkInstanceConfig,
+	config *types.NetworkInstanceConfig) {
+
+	lb := apiConfigEntry.GetUplinkLoadBalancing()
+	if lb == nil {
+		return
+	}
+	switch lb.GetMode() {
+	case zconfig.UplinkLoadBalancingMode_UPLINK_LOAD_BALANCING_MODE_NONE:
+		return
+	case zconfig.UplinkLoadBalancingMode_UPLINK_LOAD_BALANCING_MODE_ECMP:
+		config.UplinkLoadBalancing.Mode = types.UplinkLBModeECMP
+	case zconfig.UplinkLoadBalancingMode_UPLINK_LOAD_BALANCING_MODE_WEIGHTED:
+		config.UplinkLoadBalancing.Mode = types.UplinkLBModeWeighted
+		config.UplinkLoadBalancing.Weights = make(map[string]uint32)
+		for port, weight := range lb.GetWeights() {
+			config.UplinkLoadBalancing.Weights[port] = weight
+		}
+	default:
+		log.Errorf("Network instance %s: unknown uplink load-balancing mode %v",
+			config.Key(), lb.GetMode())
+		return
+	}
+	if config.Type != types.NetworkInstanceTypeLocal ||
+		config.IpType != types.AddressTypeIPV4 {
+		errStr := fmt.Sprintf("Network instance %s: uplink load-balancing "+
+			"is supported only for IPv4 local network instances", config.Key())
+		log.Error(errStr)
+		config.SetErrorNow(errStr)
+	}
+}
+
+func parseAccessPoint(ctx *getconfigContext,
+	apiConfigEntry *zconfig.NetworkInstanceConfig,
+	config *types.NetworkInstanceConfig) {
+
+	apCfg := apiConfigEntry.GetAccessPoint()
+	if apCfg == nil || apCfg.GetAdapter() == "" {
+		return
+	}
+	ap := types.WirelessAccessPoint{
+		Adapter:     apCfg.GetAdapter(),
+		SSID:        apCfg.GetSsid(),
+		Channel:     apCfg.GetChannel(),
+		CountryCode: strings.ToUpper(apCfg.GetCountryCode()),
+		HiddenSSID:  apCfg.GetHiddenSsid(),
+	}
+	ap.CipherBlockStatus = parseCipherBlock(ctx, config.Key()+"-ap",
+		apCfg.GetCipherData())
+	config.AccessPoint = ap
+	var err error
+	if config.Type != types.NetworkInstanceTypeLocal ||
+		config.IpType != types.AddressTypeIPV4 {
+		err = errors.New("wireless access point is supported only " +
+			"for IPv4 local network instances")
+	} else if !ap.CipherBlockStatus.IsCipher {
+		err = errors.New("missing encrypted WPA passphrase")
+	} else {
+		err = ap.Validate()
+	}
+	if err != nil {
+		errStr := fmt.Sprintf("Network instance %s: invalid access point "+
+			"config: %v", config.Key(), err)
+		log.Error(errStr)
+		config.SetErrorNow(errStr)
+	}
+}
+
 var networkInstancePrevConfigHash []byte
 
 func parseNetworkInstanceConfig(config *zconfig.EdgeDevConfig,
@@ -658,6 +808,10 @@ func parseSystemAdapterConfig(config *zconfig.EdgeDevConfig,
 	for _, a := range sysAdapters {
 		computeConfigElementSha(h, a)
 	}
+	ntpServers := config.GetNtpServers()
+	for _, ntpServer := range ntpServers {
+		h.Write([]byte(ntpServer))
+	}
 	configHash := h.Sum(nil)
 	same := bytes.Equal(configHash, systemAdaptersPrevConfigHash)
 	if same && !forceParse {
@@ -696,6 +850,7 @@ func parseSystemAdapterConfig(config *zconfig.EdgeDevConfig,
 		newPorts = append(newPorts, ports...)
 	}
 	validateAndAssignNetPorts(portConfig, newPorts)
+	portConfig.NTPServers = parseNTPServers(ntpServers)
 
 	// Check if all management ports have errors
 	// Propagate any parse errors for all ports to the DPC
@@ -732,6 +887,7 @@ func parseSystemAdapterConfig(config *zconfig.EdgeDevConfig,
 	// Even if only ErrorAndTime changed we publish so
 	// the change can be sent back to the controller using ctx.devicePortConfigList
 	if cmp.Equal(getconfigCtx.devicePortConfig.Ports, portConfig.Ports) &&
+		cmp.Equal(getconfigCtx.devicePortConfig.NTPServers, portConfig.NTPServers) &&
 		cmp.Equal(getconfigCtx.devicePortConfig.TestResults, portConfig.TestResults) &&
 		getconfigCtx.devicePortConfig.Version == portConfig.Version {
 		log.Functionf("parseSystemAdapterConfig: DevicePortConfig - " +
@@ -751,6 +907,35 @@ func parseSystemAdapterConfig(config *zconfig.EdgeDevConfig,
 	log.Functionf("parseSystemAdapterConfig: Done")
 }
 
+// RFC 1123 hostname (optionally fully-qualified with the trailing dot).
+var hostnameRegexp = regexp.MustCompile(
+	`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)*` +
+		`[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.?$`)
+
+// parseNTPServers returns valid and unique NTP servers from the list.
+// Every NTP server should be either an IP address or a hostname.
+func parseNTPServers(ntpServers []string) (validated []string) {
+	for _, ntpServer := range ntpServers {
+		ntpServer = strings.TrimSpace(ntpServer)
+		if net.ParseIP(ntpServer) == nil &&
+			(len(ntpServer) > 253 || !hostnameRegexp.MatchString(ntpServer)) {
+			log.Errorf("parseNTPServers: invalid NTP server %q, skipping", ntpServer)
+			continue
+		}
+		var duplicate bool
+		for _, server := range validated {
+			if server == ntpServer {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			validated = append(validated, ntpServer)
+		}
+	}
+	return validated
+}
+
 // Validate parsed network ports and assign non-duplicates to DevicePortConfig.
 func validateAndAssignNetPorts(dpc *types.DevicePortConfig, newPorts []*types.NetworkPortConfig) {
 	var validatedPorts []*types.NetworkPortConfig
@@ -1118,6 +1303,8 @@ func parseOneSystemAdapterConfig(getconfigCtx *getconfigContext,
 				port.AddrSubnet = addrSubnet.String()
 			}
 			port.WirelessCfg = network.WirelessCfg
+			port.IPRoutingConfig = network.IPRouting
+			port.Dot1x = network.Dot1x
 			port.Gateway = network.Gateway
 			port.DomainName = network.DomainName
 			port.NtpServer = network.NtpServer
@@ -1503,6 +1690,32 @@ func lookupDatastore(datastores []*zconfig.DatastoreConfig,
 	return nil
 }
 
+var peerCacheConfigPrevConfigHash []byte
+
+// parsePeerCacheConfig publishes the device certificates of the peers
+// the downloader may share blobs with
+func parsePeerCacheConfig(config *zconfig.EdgeDevConfig,
+	getconfigCtx *getconfigContext) {
+
+	certs := config.GetPeerCacheDeviceCerts()
+	h := sha256.New()
+	for _, cert := range certs {
+		h.Write(cert)
+	}
+	configHash := h.Sum(nil)
+	same := bytes.Equal(configHash, peerCacheConfigPrevConfigHash)
+	if same {
+		return
+	}
+	log.Functionf("parsePeerCacheConfig: Applying updated peer cache config "+
+		"prevSha: % x, NewSha : % x, Num Certs: %d",
+		peerCacheConfigPrevConfigHash, configHash, len(certs))
+	peerCacheConfigPrevConfigHash = configHash
+	peerCacheConfig := types.PeerCacheConfig{DeviceCertsPEM: certs}
+	getconfigCtx.pubPeerCacheConfig.Publish(peerCacheConfig.Key(),
+		peerCacheConfig)
+}
+
 var datastoreConfigPrevConfigHash []byte
 
 func parseDatastoreConfig(config *zconfig.EdgeDevConfig,
@@ -1564,6 +1777,16 @@ func publishDatastoreConfig(ctx *getconfigContext,
 
 		datastore.CipherBlockStatus = parseCipherBlock(ctx, datastore.Key(),
 			ds.GetCipherData())
+		for i, m := range ds.GetMirrors() {
+			mirror := types.RegistryMirror{
+				Fqdn:    m.GetFqdn(),
+				Dpath:   m.GetDpath(),
+				CertPEM: m.GetCertPEM(),
+			}
+			mirror.CipherBlockStatus = parseCipherBlock(ctx,
+				fmt.Sprintf("%s.mirror%d", datastore.Key(), i), m.GetCipherData())
+			datastore.Mirrors = append(datastore.Mirrors, mirror)
+		}
 		ctx.pubDatastoreConfig.Publish(datastore.Key(), *datastore)
 	}
 }
@@ -1726,6 +1949,23 @@ func parseOneNetworkXObjectConfig(ctx *getconfigContext, netEnt *zconfig.Network
 
 	// wireless property configuration
 	config.WirelessCfg = parseNetworkWirelessConfig(ctx, config.Key(), netEnt)
+	ipRouting, err := parseNetworkIPRouting(netEnt)
+	if err != nil {
+		errStr := fmt.Sprintf("parseOneNetworkXObjectConfig: IP routing parse for %s failed: %s",
+			config.Key(), err)
+		log.Error(errStr)
+		config.SetErrorNow(errStr)
+		return config
+	}
+	config.IPRouting = ipRouting
+	config.Dot1x = parseNetworkDot1xConfig(ctx, config.Key(), netEnt)
+	if config.Dot1x.IsEnabled() && config.WirelessCfg.WType != types.WirelessTypeNone {
+		errStr := fmt.Sprintf("parseOneNetworkXObjectConfig: 802.1X authentication "+
+			"of %s is supported only for wired ports", config.Key())
+		log.Error(errStr)
+		config.SetErrorNow(errStr)
+		return config
+	}
 
 	ipspec := netEnt.GetIp()
 	switch config.Type {
@@ -1801,6 +2041,121 @@ func parseOneNetworkXObjectConfig(ctx *getconfigContext, netEnt *zconfig.Network
 	return config
 }
 
+// parseNetworkIPRouting parses user-defined static routes and policy rules.
+func parseNetworkIPRouting(netEnt *zconfig.NetworkConfig) (types.IPRoutingConfig, error) {
+	var routing types.IPRoutingConfig
+	for _, route := range netEnt.GetStaticRoutes() {
+		_, dst, err := net.ParseCIDR(route.GetDestination())
+		if err != nil {
+			return routing, fmt.Errorf("invalid static route destination %q: %v",
+				route.GetDestination(), err)
+		}
+		staticRoute := types.StaticIPRoute{
+			Dst:    *dst,
+			Metric: int(route.GetMetric()),
+			Table:  int(route.GetTable()),
+		}
+		if route.GetGateway() != "" {
+			staticRoute.Gateway = net.ParseIP(route.GetGateway())
+			if staticRoute.Gateway == nil {
+				return routing, fmt.Errorf("invalid static route gateway %q",
+					route.GetGateway())
+			}
+			if (staticRoute.Gateway.To4() == nil) != (dst.IP.To4() == nil) {
+				return routing, fmt.Errorf("static route %s: gateway %s is from "+
+					"a different IP address family", dst, staticRoute.Gateway)
+			}
+		}
+		routing.StaticRoutes = append(routing.StaticRoutes, staticRoute)
+	}
+	for _, rule := range netEnt.GetPolicyRules() {
+		policyRule := types.IPPolicyRule{
+			Priority: int(rule.GetPriority()),
+			Table:    int(rule.GetTable()),
+		}
+		if rule.GetSource() != "" {
+			_, src, err := net.ParseCIDR(rule.GetSource())
+			if err != nil {
+				return routing, fmt.Errorf("invalid policy rule source %q: %v",
+					rule.GetSource(), err)
+			}
+			policyRule.Src = src
+		}
+		if rule.GetDestination() != "" {
+			_, dst, err := net.ParseCIDR(rule.GetDestination())
+			if err != nil {
+				return routing, fmt.Errorf("invalid policy rule destination %q: %v",
+					rule.GetDestination(), err)
+			}
+			policyRule.Dst = dst
+		}
+		if policyRule.Src == nil && policyRule.Dst == nil {
+			return routing, fmt.Errorf("policy rule without source and destination")
+		}
+		if policyRule.Src != nil && policyRule.Dst != nil &&
+			(policyRule.Src.IP.To4() == nil) != (policyRule.Dst.IP.To4() == nil) {
+			return routing, fmt.Errorf("policy rule %s: source and destination "+
+				"are from different IP address families", policyRule)
+		}
+		routing.PolicyRules = append(routing.PolicyRules, policyRule)
+	}
+	return routing, nil
+}
+
+func parseNetworkDot1xConfig(ctx *getconfigContext, key string,
+	netEnt *zconfig.NetworkConfig) types.Dot1xConfig {
+	var dot1x types.Dot1xConfig
+	dot1xCfg := netEnt.GetDot1X()
+	if dot1xCfg == nil {
+		return dot1x
+	}
+	switch dot1xCfg.GetEapMethod() {
+	case zconfig.Dot1XEAPMethod_DOT1X_EAP_METHOD_TLS:
+		dot1x.EAPMethod = types.Dot1xEAPMethodTLS
+	case zconfig.Dot1XEAPMethod_DOT1X_EAP_METHOD_PEAP:
+		dot1x.EAPMethod = types.Dot1xEAPMethodPEAP
+	default:
+		return dot1x
+	}
+	dot1x.Identity = dot1xCfg.GetIdentity()
+	dot1x.AnonymousIdentity = dot1xCfg.GetAnonymousIdentity()
+	dot1x.AllowPEAPWithoutCACert = dot1xCfg.GetAllowPeapWithoutCaCert()
+	if dot1x.EAPMethod == types.Dot1xEAPMethodPEAP && dot1x.AllowPEAPWithoutCACert {
+		log.Warnf("parseNetworkDot1xConfig: PEAP for %s is allowed to run "+
+			"without CA certificate, the authentication server may not be verified",
+			key)
+	}
+	dot1x.CipherBlockStatus = parseCipherBlock(ctx, key+"-dot1x",
+		dot1xCfg.GetCipherData())
+	log.Functionf("parseNetworkDot1xConfig: 802.1X with EAP-%s for %s",
+		dot1x.EAPMethod, key)
+	return dot1x
+}
+
+func parseCellularIPType(ipType zconfig.CellularIPType) types.WwanIPType {
+	switch ipType {
+	case zconfig.CellularIPType_CELLULAR_IP_TYPE_IPV4:
+		return types.WwanIPTypeIPv4
+	case zconfig.CellularIPType_CELLULAR_IP_TYPE_IPV6:
+		return types.WwanIPTypeIPv6
+	case zconfig.CellularIPType_CELLULAR_IP_TYPE_IPV4_AND_IPV6:
+		return types.WwanIPTypeIPv4AndIPv6
+	}
+	return types.WwanIPTypeUnspecified
+}
+
+func parseCellularAuthProtocol(proto zconfig.CellularAuthProtocol) types.WwanAuthProtocol {
+	switch proto {
+	case zconfig.CellularAuthProtocol_CELLULAR_AUTH_PROTOCOL_PAP:
+		return types.WwanAuthProtocolPAP
+	case zconfig.CellularAuthProtocol_CELLULAR_AUTH_PROTOCOL_CHAP:
+		return types.WwanAuthProtocolCHAP
+	case zconfig.CellularAuthProtocol_CELLULAR_AUTH_PROTOCOL_PAP_AND_CHAP:
+		return types.WwanAuthProtocolPAPAndCHAP
+	}
+	return types.WwanAuthProtocolNone
+}
+
 func parseNetworkWirelessConfig(ctx *getconfigContext, key string, netEnt *zconfig.NetworkConfig) types.WirelessConfig {
 	var wconfig types.WirelessConfig
 
@@ -1816,12 +2171,19 @@ func parseNetworkWirelessConfig(ctx *getconfigContext, key string, netEnt *zconf
 		//
 		wconfig.WType = types.WirelessTypeCellular
 		cellulars := netWireless.GetCellularCfg()
-		for _, cellular := range cellulars {
+		for i, cellular := range cellulars {
 			var wcell types.CellConfig
 			wcell.APN = cellular.GetAPN()
 			wcell.ProbeAddr = cellular.GetProbe().GetProbeAddress()
 			wcell.DisableProbe = cellular.GetProbe().GetDisable()
 			wcell.LocationTracking = cellular.GetLocationTracking()
+			wcell.SimSlot = uint8(cellular.GetSimSlot())
+			wcell.IPType = parseCellularIPType(cellular.GetIpType())
+			wcell.AuthProtocol = parseCellularAuthProtocol(cellular.GetAuthProtocol())
+			wcell.ForbidRoaming = cellular.GetForbidRoaming()
+			cipherKey := fmt.Sprintf("%s-cellular-%d", key, i)
+			wcell.CipherBlockStatus = parseCipherBlock(ctx, cipherKey,
+				cellular.GetCipherData())
 			wconfig.Cellular = append(wconfig.Cellular, wcell)
 		}
 		log.Functionf("parseNetworkWirelessConfig: Wireless of network Cellular, %v", wconfig.Cellular)
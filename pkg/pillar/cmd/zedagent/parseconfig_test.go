@@ -140,6 +140,65 @@ func TestParsePhysicalNetworkAdapters(t *testing.T) {
 	g.Expect(port.WirelessCfg.WType).To(Equal(types.WirelessTypeNone))
 }
 
+func TestParseNTPServers(t *testing.T) {
+	g := NewGomegaWithT(t)
+	getconfigCtx := initGetConfigCtx(g)
+
+	const networkUUID = "572cd3bc-ade6-42ad-97a0-22cd24fed1a0"
+	config := &zconfig.EdgeDevConfig{
+		Networks: []*zconfig.NetworkConfig{
+			{
+				Id:   networkUUID,
+				Type: zconfig.NetworkType_V4,
+				Ip: &zconfig.Ipspec{
+					Dhcp: zconfig.DHCPType_Client,
+				},
+			},
+		},
+		DeviceIoList: []*zconfig.PhysicalIO{
+			{
+				Ptype:        zcommon.PhyIoType_PhyIoNetEth,
+				Phylabel:     "ethernet0",
+				Logicallabel: "shopfloor",
+				Phyaddrs: map[string]string{
+					"ifname": "eth0",
+				},
+				Usage: zcommon.PhyIoMemberUsage_PhyIoUsageMgmtAndApps,
+			},
+		},
+		SystemAdapterList: []*zconfig.SystemAdapter{
+			{
+				Name:           "adapter-shopfloor",
+				Uplink:         true,
+				NetworkUUID:    networkUUID,
+				LowerLayerName: "shopfloor",
+			},
+		},
+		NtpServers: []string{"ntp.plant.example.com", "10.10.0.1",
+			"bad_hostname!", "10.10.0.1", "2001:db8::123"},
+	}
+
+	parseDeviceIoListConfig(config, getconfigCtx)
+	parseNetworkXObjectConfig(config, getconfigCtx)
+	parseSystemAdapterConfig(config, getconfigCtx, true)
+
+	portConfig, err := getconfigCtx.pubDevicePortConfig.Get("zedagent")
+	g.Expect(err).To(BeNil())
+	dpc := portConfig.(types.DevicePortConfig)
+	g.Expect(dpc.HasError()).To(BeFalse())
+	g.Expect(dpc.Ports).To(HaveLen(1))
+	g.Expect(dpc.NTPServers).To(Equal(
+		[]string{"ntp.plant.example.com", "10.10.0.1", "2001:db8::123"}))
+
+	// Change of NTP servers alone is detected.
+	config.NtpServers = []string{"10.10.0.2"}
+	parseSystemAdapterConfig(config, getconfigCtx, false)
+	portConfig, err = getconfigCtx.pubDevicePortConfig.Get("zedagent")
+	g.Expect(err).To(BeNil())
+	dpc = portConfig.(types.DevicePortConfig)
+	g.Expect(dpc.NTPServers).To(Equal([]string{"10.10.0.2"}))
+}
+
 // Test DPC with recorded parsing error (missing network configuration)
 func TestDPCWithError(t *testing.T) {
 	g := NewGomegaWithT(t)
@@ -599,6 +599,12 @@ func PublishDeviceInfoToZedCloud(ctx *zedagentContext) {
 
 	ReportDeviceInfo.ApiCapability = info.APICapability_API_CAPABILITY_START_DELAY_IN_SECONDS
 
+	if ctx.subNTPStatus != nil {
+		if item, err := ctx.subNTPStatus.Get("global"); err == nil {
+			ReportDeviceInfo.NtpStatus = encodeNTPStatus(item.(types.NTPStatus))
+		}
+	}
+
 	// Report if there is a local override of profile
 	if ctx.getconfigCtx.currentProfile != ctx.getconfigCtx.globalProfile {
 		ReportDeviceInfo.LocalProfile = ctx.getconfigCtx.currentProfile
@@ -837,9 +843,76 @@ func encodeNetInfo(port types.NetworkPortStatus) *info.ZInfoNetwork {
 	for _, server := range port.NtpServers {
 		networkInfo.NtpServers = append(networkInfo.NtpServers, server.String())
 	}
+	if port.IsMgmt && port.Quality.IsKnown() {
+		lastUpdate, _ := ptypes.TimestampProto(port.Quality.LastUpdate)
+		networkInfo.Quality = &info.ZInfoPortQuality{
+			Score:          uint32(port.Quality.Score),
+			LatencyMs:      port.Quality.LatencyMs,
+			LossPercent:    uint32(port.Quality.LossPercent),
+			ThroughputKbps: port.Quality.ThroughputKbps,
+			Samples:        port.Quality.Samples,
+			LastUpdate:     lastUpdate,
+		}
+	}
+	for _, neighbor := range port.Neighbors {
+		networkInfo.Neighbors = append(networkInfo.Neighbors,
+			encodePortNeighbor(neighbor))
+	}
 	return networkInfo
 }
 
+func encodeNTPStatus(status types.NTPStatus) *info.ZInfoNTPStatus {
+	ntpStatus := &info.ZInfoNTPStatus{
+		Servers:      status.Servers,
+		Stratum:      uint32(status.Stratum),
+		OffsetUs:     status.Offset.Microseconds(),
+		PollInterval: uint32(status.PollInterval.Seconds()),
+	}
+	switch status.State {
+	case types.NTPSyncStateSynchronized:
+		ntpStatus.State = info.ZNTPSyncState_Z_NTP_SYNC_STATE_SYNCHRONIZED
+	case types.NTPSyncStateUnsynchronized:
+		ntpStatus.State = info.ZNTPSyncState_Z_NTP_SYNC_STATE_UNSYNCHRONIZED
+	}
+	if !status.LastUpdate.IsZero() {
+		ntpStatus.LastUpdate, _ = ptypes.TimestampProto(status.LastUpdate)
+	}
+	return ntpStatus
+}
+
+func encodePortNeighbor(neighbor types.PortNeighbor) *info.ZInfoPortNeighbor {
+	var protocol info.ZNeighborProtocol
+	switch neighbor.Protocol {
+	case types.NeighborProtocolLLDP:
+		protocol = info.ZNeighborProtocol_Z_NEIGHBOR_PROTOCOL_LLDP
+	case types.NeighborProtocolCDP:
+		protocol = info.ZNeighborProtocol_Z_NEIGHBOR_PROTOCOL_CDP
+	default:
+		protocol = info.ZNeighborProtocol_Z_NEIGHBOR_PROTOCOL_UNSPECIFIED
+	}
+	lastSeen, _ := ptypes.TimestampProto(neighbor.LastSeen)
+	neighborInfo := &info.ZInfoPortNeighbor{
+		Protocol:          protocol,
+		ChassisId:         neighbor.ChassisID,
+		PortId:            neighbor.PortID,
+		PortDescription:   neighbor.PortDescription,
+		SystemName:        neighbor.SystemName,
+		SystemDescription: neighbor.SystemDescription,
+		PortVlan:          uint32(neighbor.PortVLAN),
+		LastSeen:          lastSeen,
+	}
+	for _, vlan := range neighbor.VLANs {
+		neighborInfo.Vlans = append(neighborInfo.Vlans, &info.ZInfoNeighborVLAN{
+			Id:   uint32(vlan.ID),
+			Name: vlan.Name,
+		})
+	}
+	for _, addr := range neighbor.MgmtAddrs {
+		neighborInfo.MgmtAddrs = append(neighborInfo.MgmtAddrs, addr.String())
+	}
+	return neighborInfo
+}
+
 func encodeCellModuleInfo(wwanModule types.WwanCellModule) *info.ZCellularModuleInfo {
 	var opState info.ZCellularOperatingState
 	switch wwanModule.OpMode {
@@ -973,6 +1046,10 @@ func getDataSecAtRestInfo(ctx *zedagentContext) *info.DataSecAtRest {
 		vaultInfo.Name = v.Name
 		vaultInfo.Status = v.Status
 		vaultInfo.PcrStatus = v.PCRStatus
+		for _, diff := range v.PCREventDiffs {
+			vaultInfo.PcrEventDiffs = append(vaultInfo.PcrEventDiffs,
+				encodePCREventDiff(diff))
+		}
 		if !v.ErrorTime.IsZero() {
 			vaultInfo.VaultErr = encodeErrorInfo(v.ErrorAndTime.ErrorDescription)
 		}
@@ -981,6 +1058,27 @@ func getDataSecAtRestInfo(ctx *zedagentContext) *info.DataSecAtRest {
 	return ReportDataSecAtRestInfo
 }
 
+func encodeMeasuredBootEvents(events []types.MeasuredBootEvent) []*info.MeasuredBootEvent {
+	var encoded []*info.MeasuredBootEvent
+	for _, event := range events {
+		encoded = append(encoded, &info.MeasuredBootEvent{
+			Index:       event.Sequence,
+			EventType:   event.EventType,
+			Digest:      event.Digest,
+			Description: event.Description,
+		})
+	}
+	return encoded
+}
+
+func encodePCREventDiff(diff types.PCREventDiff) *info.PCREventDiff {
+	return &info.PCREventDiff{
+		Index:   uint32(diff.Index),
+		Added:   encodeMeasuredBootEvents(diff.Added),
+		Removed: encodeMeasuredBootEvents(diff.Removed),
+	}
+}
+
 func createConfigItemStatus(
 	status types.GlobalStatus) *info.ZInfoConfigItemStatus {
 
@@ -53,7 +53,7 @@ const (
 	restartCounterFile      = types.PersistStatusDir + "/restartcounter"
 	lastDevCmdTimestampFile = types.PersistStatusDir + "/lastdevcmdtimestamp"
 	// checkpointDirname - location of config checkpoint
-	checkpointDirname = types.PersistDir + "/checkpoint"
+	checkpointDirname = types.CheckpointDirname
 	// Time limits for event loop handlers
 	errorTime   = 3 * time.Minute
 	warningTime = 40 * time.Second
@@ -135,6 +135,7 @@ type zedagentContext struct {
 	subDeviceNetworkStatus    pubsub.Subscription
 	subZFSPoolStatus          pubsub.Subscription
 	subEdgeviewStatus         pubsub.Subscription
+	subNTPStatus              pubsub.Subscription
 	zedcloudMetrics           *zedcloud.AgentMetrics
 	rebootCmd                 bool
 	rebootCmdDeferred         bool
@@ -207,6 +208,9 @@ func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) in
 	debugPtr := flag.Bool("d", false, "Debug flag")
 	parsePtr := flag.String("p", "", "parse checkpoint file")
 	validatePtr := flag.Bool("V", false, "validate UTF-8 in checkpoint")
+	exportPtr := flag.String("export", "", "export checkpoint with signature to file")
+	inspectPtr := flag.String("inspect", "", "inspect exported checkpoint file")
+	importPtr := flag.String("import", "", "verify and import exported checkpoint file")
 	fatalPtr := flag.Bool("F", false, "Cause log.Fatal fault injection")
 	hangPtr := flag.Bool("H", false, "Cause watchdog .touch fault injection")
 	flag.Parse()
@@ -246,6 +250,15 @@ func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) in
 		}
 		return 0
 	}
+	if *exportPtr != "" {
+		return exportCheckpoint(*exportPtr)
+	}
+	if *inspectPtr != "" {
+		return inspectCheckpoint(*inspectPtr)
+	}
+	if *importPtr != "" {
+		return importCheckpoint(*importPtr)
+	}
 	if err := pidfile.CheckAndCreatePidfile(log, agentName); err != nil {
 		log.Fatal(err)
 	}
@@ -506,6 +519,16 @@ func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) in
 	getconfigCtx.pubDatastoreConfig = pubDatastoreConfig
 	pubDatastoreConfig.ClearRestarted()
 
+	pubPeerCacheConfig, err := ps.NewPublication(pubsub.PublicationOptions{
+		AgentName: agentName,
+		TopicType: types.PeerCacheConfig{},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	getconfigCtx.pubPeerCacheConfig = pubPeerCacheConfig
+	pubPeerCacheConfig.ClearRestarted()
+
 	pubControllerCert, err := ps.NewPublication(
 		pubsub.PublicationOptions{
 			AgentName:  agentName,
@@ -1350,6 +1373,23 @@ func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) in
 	zedagentCtx.subZFSPoolStatus = subZFSPoolStatus
 	subZFSPoolStatus.Activate()
 
+	subNTPStatus, err := ps.NewSubscription(pubsub.SubscriptionOptions{
+		AgentName:     "nim",
+		MyAgentName:   agentName,
+		TopicImpl:     types.NTPStatus{},
+		Activate:      false,
+		Ctx:           &zedagentCtx,
+		CreateHandler: handleNTPStatusCreate,
+		ModifyHandler: handleNTPStatusModify,
+		WarningTime:   warningTime,
+		ErrorTime:     errorTime,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	zedagentCtx.subNTPStatus = subNTPStatus
+	subNTPStatus.Activate()
+
 	//Parse SMART data
 	go parseSMARTData()
 
@@ -1398,6 +1438,12 @@ func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) in
 	initializeLocalDevInfo(&getconfigCtx)
 	go localDevInfoPOSTTask(&getconfigCtx)
 
+	initializeLocalVolumeInfo(&getconfigCtx)
+	go localVolumeInfoPOSTTask(&getconfigCtx)
+
+	initializeLocalCheckpoint(&getconfigCtx)
+	go localCheckpointPOSTTask(&getconfigCtx)
+
 	// start the config fetch tasks, when zboot status is ready
 	log.Functionf("Creating %s at %s", "configTimerTask", agentlog.GetMyStack())
 	go configTimerTask(handleChannel, &getconfigCtx)
@@ -1414,6 +1460,10 @@ func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) in
 	// start task fetching radio config from local server
 	go radioPOSTTask(&getconfigCtx)
 
+	// start task importing update bundles from USB stick or local server
+	initializeLocalBundle(&getconfigCtx)
+	go localBundleTask(&getconfigCtx)
+
 	// start cipher module tasks
 	cipherModuleStart(&zedagentCtx)
 
@@ -1673,6 +1723,9 @@ func Run(ps *pubsub.PubSub, loggerArg *logrus.Logger, logArg *base.LogObject) in
 		case change := <-subEdgeviewStatus.MsgChan():
 			subEdgeviewStatus.ProcessChange(change)
 
+		case change := <-subNTPStatus.MsgChan():
+			subNTPStatus.ProcessChange(change)
+
 		case <-stillRunning.C:
 			// Fault injection
 			if fatalFlag {
@@ -1937,6 +1990,15 @@ func trimmedDNS(dns types.DeviceNetworkStatus) types.DeviceNetworkStatus {
 		npsCopy := nps
 		npsCopy.LastFailed = time.Time{}
 		npsCopy.LastSucceeded = time.Time{}
+		// Only significant changes of the link quality are worth reporting.
+		npsCopy.Quality = types.PortQuality{Score: nps.Quality.Score}
+		// Neighbor advertisements are received periodically.
+		npsCopy.Neighbors = make([]types.PortNeighbor, len(nps.Neighbors))
+		for j, neighbor := range nps.Neighbors {
+			neighbor.LastSeen = time.Time{}
+			neighbor.ExpiresAt = time.Time{}
+			npsCopy.Neighbors[j] = neighbor
+		}
 		npsCopy.AddrInfoList = make([]types.AddrInfo, len(nps.AddrInfoList))
 		for j, naddr := range nps.AddrInfoList {
 			naddr.Geo = ipinfo.IPInfo{}
@@ -2418,3 +2480,20 @@ func handleEdgeviewStatusImpl(ctxArg interface{}, key string, statusArg interfac
 	PublishEdgeviewToZedCloud(ctx, &status, ctx.iteration)
 	ctx.iteration++
 }
+
+func handleNTPStatusCreate(ctxArg interface{}, key string, statusArg interface{}) {
+	ctx := ctxArg.(*zedagentContext)
+	triggerPublishDevInfo(ctx)
+}
+
+// Device info is re-published only if the synchronization state, stratum
+// or the set of servers changes. Clock offset alone is not worth an update.
+func handleNTPStatusModify(ctxArg interface{}, key string,
+	statusArg interface{}, oldStatusArg interface{}) {
+	ctx := ctxArg.(*zedagentContext)
+	status := statusArg.(types.NTPStatus)
+	oldStatus := oldStatusArg.(types.NTPStatus)
+	if !status.MostlyEqual(oldStatus) {
+		triggerPublishDevInfo(ctx)
+	}
+}
@@ -929,9 +929,11 @@ func handleZedAgentStatusImpl(ctxArg interface{}, key string,
 	statusArg interface{}) {
 	ctxPtr := ctxArg.(*zedmanagerContext)
 	status := statusArg.(types.ZedAgentStatus)
-	// When getting the config successfully for the first time (get from the controller or read from the file), consider
-	// the device as ready to start apps. Hence, count the app delay timeout from now.
-	if status.ConfigGetStatus == types.ConfigGetSuccess || status.ConfigGetStatus == types.ConfigGetReadSaved {
+	// When getting the config successfully for the first time (get from the controller, read from the file
+	// or from an update bundle), consider the device as ready to start apps. Hence, count the app delay
+	// timeout from now.
+	if status.ConfigGetStatus == types.ConfigGetSuccess || status.ConfigGetStatus == types.ConfigGetReadSaved ||
+		status.ConfigGetStatus == types.ConfigGetLocalBundle {
 		if ctxPtr.delayBaseTime.IsZero() {
 			ctxPtr.delayBaseTime = time.Now()
 		}
@@ -999,6 +1001,11 @@ func updateBasedOnProfile(ctx *zedmanagerContext, oldProfile string) {
 
 // returns effective Activate status based on Activate from app instance config and current profile
 func effectiveActivateCurrentProfile(config types.AppInstanceConfig, currentProfile string) bool {
+	if config.LocalStopped {
+		log.Functionf("effectiveActivateCurrentProfile(%s): stopped locally", config.Key())
+		// app was stopped via local profile server
+		return false
+	}
 	if currentProfile == "" {
 		log.Functionf("effectiveActivateCurrentProfile(%s): empty current", config.Key())
 		// if currentProfile is empty set activate state from controller
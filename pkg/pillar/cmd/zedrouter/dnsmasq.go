@@ -299,6 +299,11 @@ func createDnsmasqConfiglet(
 	} else {
 		file.WriteString(fmt.Sprintf("dhcp-range=%s,static,%s,60m\n",
 			dhcpRange, ipv4Netmask))
+		// Wireless clients are allocated IP addresses dynamically.
+		if apPool, ok := accessPointDhcpPool(netstatus); ok {
+			file.WriteString(fmt.Sprintf("dhcp-range=%s,%s,%s,60m\n",
+				apPool.Start.String(), apPool.End.String(), ipv4Netmask))
+		}
 	}
 }
 
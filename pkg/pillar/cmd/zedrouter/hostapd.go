@@ -0,0 +1,236 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// hostapd configlets for wireless access points bridged into local
+// network instances
+
+package zedrouter
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/lf-edge/eve/pkg/pillar/cipher"
+	"github.com/lf-edge/eve/pkg/pillar/iptables"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	"github.com/lf-edge/eve/pkg/pillar/utils"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	// Upper bound for the number of IP addresses from the DHCP range
+	// reserved for wireless clients.
+	maxAccessPointDhcpPool = 64
+	// Minimal number of IP addresses reserved for wireless clients.
+	minAccessPointDhcpPool = 4
+)
+
+func hostapdConfigFile(bridgeName string) string {
+	return "hostapd." + bridgeName + ".conf"
+}
+
+func hostapdConfigPath(bridgeName string) string {
+	return runDirname + "/" + hostapdConfigFile(bridgeName)
+}
+
+func hostapdPidPath(bridgeName string) string {
+	return "/run/hostapd." + bridgeName + ".pid"
+}
+
+// makeHostapdConfig generates hostapd configuration for an access point
+// with WPA2-PSK, bridging wireless clients into the network instance bridge.
+func makeHostapdConfig(ap types.WirelessAccessPoint, ifName, bridgeName,
+	passphrase string) string {
+	var sb strings.Builder
+	sb.WriteString("# Automatically generated by zedrouter\n")
+	sb.WriteString(fmt.Sprintf("interface=%s\n", ifName))
+	sb.WriteString(fmt.Sprintf("bridge=%s\n", bridgeName))
+	sb.WriteString("driver=nl80211\n")
+	// hex-encoded, hostapd does not unescape quoted strings and the SSID
+	// may contain any bytes
+	sb.WriteString(fmt.Sprintf("ssid2=%x\n", ap.SSID))
+	sb.WriteString("utf8_ssid=1\n")
+	if ap.CountryCode != "" {
+		sb.WriteString(fmt.Sprintf("country_code=%s\n", ap.CountryCode))
+		sb.WriteString("ieee80211d=1\n")
+	}
+	if ap.Is5GHz() {
+		sb.WriteString("hw_mode=a\n")
+	} else {
+		sb.WriteString("hw_mode=g\n")
+	}
+	sb.WriteString(fmt.Sprintf("channel=%d\n", ap.GetChannel()))
+	sb.WriteString("ieee80211n=1\n")
+	sb.WriteString("wmm_enabled=1\n")
+	if ap.HiddenSSID {
+		sb.WriteString("ignore_broadcast_ssid=1\n")
+	} else {
+		sb.WriteString("ignore_broadcast_ssid=0\n")
+	}
+	sb.WriteString("auth_algs=1\n")
+	sb.WriteString("wpa=2\n")
+	sb.WriteString("wpa_key_mgmt=WPA-PSK\n")
+	sb.WriteString("rsn_pairwise=CCMP\n")
+	sb.WriteString(fmt.Sprintf("wpa_passphrase=%s\n", passphrase))
+	return sb.String()
+}
+
+// accessPointDhcpPool returns range of IP addresses from the end of the DHCP
+// range, which is reserved for (dynamically allocated to) wireless clients.
+// Applications are allocated IP addresses from the beginning of the DHCP range.
+func accessPointDhcpPool(status *types.NetworkInstanceStatus) (types.IpRange, bool) {
+	if !status.AccessPoint.IsEnabled() || status.IsIPv6() ||
+		status.DhcpRange.Start == nil || status.DhcpRange.End == nil {
+		return types.IpRange{}, false
+	}
+	poolSize := int(status.DhcpRange.Size()+1) / 4
+	if poolSize > maxAccessPointDhcpPool {
+		poolSize = maxAccessPointDhcpPool
+	}
+	if poolSize < minAccessPointDhcpPool {
+		return types.IpRange{}, false
+	}
+	return types.IpRange{
+		Start: types.AddToIP(status.DhcpRange.End, -(poolSize - 1)),
+		End:   status.DhcpRange.End,
+	}, true
+}
+
+// getAccessPointIfName returns interface name of the wireless adapter
+// configured for the access point, after checking that the adapter
+// is available for this purpose.
+func getAccessPointIfName(ctx *zedrouterContext,
+	status *types.NetworkInstanceStatus) (string, error) {
+	label := status.AccessPoint.Adapter
+	ib := ctx.assignableAdapters.LookupIoBundleLogicallabel(label)
+	if ib == nil {
+		return "", fmt.Errorf("wireless adapter %s does not exist", label)
+	}
+	if ib.Type != types.IoNetWLAN {
+		return "", fmt.Errorf("adapter %s is not a wireless adapter", label)
+	}
+	if ib.IsPCIBack || ib.UsedByUUID != uuid.Nil {
+		return "", fmt.Errorf("wireless adapter %s is assigned to an application",
+			label)
+	}
+	if ib.Ifname == "" {
+		return "", fmt.Errorf("wireless adapter %s has no interface name", label)
+	}
+	if ctx.deviceNetworkStatus.GetPortByIfName(ib.Ifname) != nil {
+		return "", fmt.Errorf("wireless adapter %s is used as a device port",
+			label)
+	}
+	for _, st := range ctx.pubNetworkInstanceStatus.GetAll() {
+		status2 := st.(types.NetworkInstanceStatus)
+		if status2.UUID != status.UUID &&
+			status2.AccessPointIfName == ib.Ifname {
+			return "", fmt.Errorf("wireless adapter %s is already used "+
+				"by network instance %s", label, status2.DisplayName)
+		}
+	}
+	return ib.Ifname, nil
+}
+
+// getAccessPointPassphrase returns decrypted WPA passphrase.
+// There is no cleartext fallback.
+func getAccessPointPassphrase(ctx *zedrouterContext,
+	status *types.NetworkInstanceStatus) (string, error) {
+	cipherBlock := status.AccessPoint.CipherBlockStatus
+	if !cipherBlock.IsCipher {
+		ctx.cipherMetrics.RecordFailure(log, types.NoData)
+		return "", errors.New("missing encrypted WPA passphrase")
+	}
+	cbStatus, decBlock, err := cipher.GetCipherCredentials(
+		&ctx.decryptCipherContext, cipherBlock)
+	ctx.pubCipherBlockStatus.Publish(cbStatus.Key(), cbStatus)
+	if err != nil {
+		ctx.cipherMetrics.RecordFailure(log, types.MissingFallback)
+		return "", fmt.Errorf("failed to decrypt WPA passphrase: %v", err)
+	}
+	if err = types.ValidateWifiPassphrase(decBlock.WifiPassword); err != nil {
+		return "", err
+	}
+	return decBlock.WifiPassword, nil
+}
+
+// startAccessPoint starts hostapd for the wireless adapter configured
+// as the network instance access point.
+func startAccessPoint(ctx *zedrouterContext,
+	status *types.NetworkInstanceStatus) error {
+	if !status.AccessPoint.IsEnabled() || status.AccessPointIfName != "" {
+		return nil
+	}
+	ifName, err := getAccessPointIfName(ctx, status)
+	if err != nil {
+		return err
+	}
+	passphrase, err := getAccessPointPassphrase(ctx, status)
+	if err != nil {
+		return err
+	}
+	bridgeName := status.BridgeName
+	cfgPathname := hostapdConfigPath(bridgeName)
+	config := makeHostapdConfig(status.AccessPoint, ifName, bridgeName, passphrase)
+	// Configuration contains the passphrase.
+	if err = ioutil.WriteFile(cfgPathname, []byte(config), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %v", cfgPathname, err)
+	}
+	// Wireless clients are only allowed to access the network instance,
+	// not to be routed towards uplinks.
+	err = iptables.IptableCmd(log, accessPointFwdRule("-I", bridgeName, ifName)...)
+	if err != nil {
+		deleteHostapdConfiglet(bridgeName)
+		return fmt.Errorf("failed to isolate wireless clients: %v", err)
+	}
+	args := []string{"-B", "-P", hostapdPidPath(bridgeName), cfgPathname}
+	log.Noticef("Starting hostapd %v", args)
+	out, err := base.Exec(log, "hostapd", args...).CombinedOutput()
+	if err != nil {
+		_ = iptables.IptableCmd(log, accessPointFwdRule("-D", bridgeName, ifName)...)
+		deleteHostapdConfiglet(bridgeName)
+		return fmt.Errorf("failed to start hostapd for %s: %v, output: %s",
+			ifName, err, out)
+	}
+	status.AccessPointIfName = ifName
+	return nil
+}
+
+// stopAccessPoint stops hostapd running for the network instance (if any).
+func stopAccessPoint(status *types.NetworkInstanceStatus) {
+	if status.AccessPointIfName == "" {
+		return
+	}
+	bridgeName := status.BridgeName
+	log.Noticef("Stopping hostapd for %s", status.AccessPointIfName)
+	utils.PkillArgs(log, hostapdConfigFile(bridgeName), true, false)
+	err := iptables.IptableCmd(log,
+		accessPointFwdRule("-D", bridgeName, status.AccessPointIfName)...)
+	if err != nil {
+		log.Errorf("stopAccessPoint: failed to remove iptables rule: %v", err)
+	}
+	deleteHostapdConfiglet(bridgeName)
+	if err = os.Remove(hostapdPidPath(bridgeName)); err != nil && !os.IsNotExist(err) {
+		log.Errorf("stopAccessPoint: failed to remove pidfile: %v", err)
+	}
+	status.AccessPointIfName = ""
+}
+
+func deleteHostapdConfiglet(bridgeName string) {
+	cfgPathname := hostapdConfigPath(bridgeName)
+	if err := os.Remove(cfgPathname); err != nil && !os.IsNotExist(err) {
+		log.Errorln(err)
+	}
+}
+
+// accessPointFwdRule returns arguments for the iptables command (adding
+// or deleting a rule) which prevents wireless clients from being routed
+// outside the network instance bridge.
+func accessPointFwdRule(action, bridgeName, ifName string) []string {
+	return []string{"-t", "filter", action, appChain("FORWARD"),
+		"-i", bridgeName, "-m", "physdev", "--physdev-in", ifName,
+		"!", "-o", bridgeName, "-j", "DROP"}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package zedrouter
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+// Test makeHostapdConfig function.
+func TestMakeHostapdConfig(t *testing.T) {
+	ap := types.WirelessAccessPoint{
+		Adapter:     "wlan0",
+		SSID:        "eve-local",
+		CountryCode: "DE",
+		HiddenSSID:  true,
+	}
+	config := makeHostapdConfig(ap, "wlan0", "bn1", "my-passphrase")
+	for _, line := range []string{
+		"interface=wlan0",
+		"bridge=bn1",
+		"driver=nl80211",
+		"ssid2=6576652d6c6f63616c",
+		"country_code=DE",
+		"hw_mode=g",
+		"channel=6",
+		"ignore_broadcast_ssid=1",
+		"wpa=2",
+		"wpa_key_mgmt=WPA-PSK",
+		"rsn_pairwise=CCMP",
+		"wpa_passphrase=my-passphrase",
+	} {
+		if !strings.Contains(config, line+"\n") {
+			t.Errorf("hostapd config is missing line %q:\n%s", line, config)
+		}
+	}
+
+	ap.Channel = 36
+	ap.CountryCode = ""
+	ap.HiddenSSID = false
+	config = makeHostapdConfig(ap, "wlan1", "bn2", "my-passphrase")
+	for _, line := range []string{
+		"interface=wlan1",
+		"bridge=bn2",
+		"hw_mode=a",
+		"channel=36",
+		"ignore_broadcast_ssid=0",
+	} {
+		if !strings.Contains(config, line+"\n") {
+			t.Errorf("hostapd config is missing line %q:\n%s", line, config)
+		}
+	}
+	if strings.Contains(config, "country_code") {
+		t.Errorf("hostapd config should not contain country code:\n%s", config)
+	}
+
+	// SSID cannot break out of its line
+	ap.SSID = "eve\"\nwpa=0"
+	config = makeHostapdConfig(ap, "wlan1", "bn2", "my-passphrase")
+	if !strings.Contains(config, "ssid2=657665220a7770613d30\n") {
+		t.Errorf("hostapd config has unexpected SSID:\n%s", config)
+	}
+	if strings.Contains(config, "wpa=0") {
+		t.Errorf("hostapd config contains line from SSID:\n%s", config)
+	}
+}
+
+// Test accessPointDhcpPool function.
+func TestAccessPointDhcpPool(t *testing.T) {
+	status := &types.NetworkInstanceStatus{
+		NetworkInstanceConfig: types.NetworkInstanceConfig{
+			Type:   types.NetworkInstanceTypeLocal,
+			IpType: types.AddressTypeIPV4,
+			DhcpRange: types.IpRange{
+				Start: net.ParseIP("10.1.0.2"),
+				End:   net.ParseIP("10.1.0.254"),
+			},
+		},
+	}
+	if _, ok := accessPointDhcpPool(status); ok {
+		t.Errorf("expected no DHCP pool without access point")
+	}
+
+	status.AccessPoint = types.WirelessAccessPoint{
+		Adapter: "wlan0",
+		SSID:    "eve-local",
+	}
+	pool, ok := accessPointDhcpPool(status)
+	if !ok {
+		t.Fatalf("expected DHCP pool for access point")
+	}
+	if !pool.Start.Equal(net.ParseIP("10.1.0.192")) ||
+		!pool.End.Equal(net.ParseIP("10.1.0.254")) {
+		t.Errorf("unexpected DHCP pool %v-%v", pool.Start, pool.End)
+	}
+
+	// Small DHCP range.
+	status.DhcpRange = types.IpRange{
+		Start: net.ParseIP("10.1.0.2"),
+		End:   net.ParseIP("10.1.0.17"),
+	}
+	pool, ok = accessPointDhcpPool(status)
+	if !ok {
+		t.Fatalf("expected DHCP pool for access point")
+	}
+	if !pool.Start.Equal(net.ParseIP("10.1.0.14")) ||
+		!pool.End.Equal(net.ParseIP("10.1.0.17")) {
+		t.Errorf("unexpected DHCP pool %v-%v", pool.Start, pool.End)
+	}
+
+	// DHCP range is too small to reserve addresses for wireless clients.
+	status.DhcpRange.End = net.ParseIP("10.1.0.6")
+	if _, ok = accessPointDhcpPool(status); ok {
+		t.Errorf("expected no DHCP pool for too small DHCP range")
+	}
+}
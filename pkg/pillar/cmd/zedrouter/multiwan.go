@@ -0,0 +1,311 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Load-balancing of application traffic across multiple uplinks
+// of a local network instance (multi-WAN).
+// Instead of copying routes of a single uplink into the NI routing table,
+// the table is filled with (non-default) routes of all usable uplinks
+// and with a multipath default route, where the nexthop weights are given
+// by the NI configuration. Traffic is NATed on every uplink.
+
+package zedrouter
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"syscall"
+
+	"github.com/lf-edge/eve/pkg/pillar/iptables"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	"github.com/vishvananda/netlink"
+)
+
+// lbUplink : uplink used by a load-balanced network instance.
+type lbUplink struct {
+	ifName       string
+	logicalLabel string
+	ifIndex      int
+	gateway      net.IP
+	weight       int
+}
+
+// isUplinkLoadBalanced returns true if the traffic of the network instance
+// should be load-balanced across all uplinks matching the NI port label.
+func isUplinkLoadBalanced(status *types.NetworkInstanceStatus) bool {
+	return status.Type == types.NetworkInstanceTypeLocal &&
+		status.IpType == types.AddressTypeIPV4 &&
+		status.UplinkLoadBalancing.IsEnabled()
+}
+
+// getLoadBalancedUplinks returns uplinks usable for the load-balanced
+// network instance, i.e. ports matching the NI port label with an IPv4
+// address and an IPv4 default router.
+func getLoadBalancedUplinks(ctx *zedrouterContext,
+	status *types.NetworkInstanceStatus) []lbUplink {
+
+	var uplinks []lbUplink
+	for _, ifName := range getIfNameListForLLOrIfname(ctx, status.Logicallabel) {
+		port := ctx.deviceNetworkStatus.GetPortByIfName(ifName)
+		if port == nil || !port.IsL3Port {
+			continue
+		}
+		var hasIPv4 bool
+		for _, addrInfo := range port.AddrInfoList {
+			if addrInfo.Addr.To4() != nil && !addrInfo.Addr.IsLinkLocalUnicast() {
+				hasIPv4 = true
+				break
+			}
+		}
+		var gateway net.IP
+		for _, router := range port.DefaultRouters {
+			if router.To4() != nil {
+				gateway = router
+				break
+			}
+		}
+		if !hasIPv4 || gateway == nil {
+			log.Functionf("getLoadBalancedUplinks(%s): skipping %s without "+
+				"IPv4 address or default router", status.DisplayName, ifName)
+			continue
+		}
+		ifIndex, err := IfnameToIndex(log, ifName)
+		if err != nil {
+			log.Errorf("getLoadBalancedUplinks(%s): IfnameToIndex(%s) failed: %v",
+				status.DisplayName, ifName, err)
+			continue
+		}
+		uplinks = append(uplinks, lbUplink{
+			ifName:       ifName,
+			logicalLabel: port.Logicallabel,
+			ifIndex:      ifIndex,
+			gateway:      gateway,
+			weight:       status.UplinkLoadBalancing.PortWeight(port.Logicallabel),
+		})
+	}
+	sort.Slice(uplinks, func(i, j int) bool {
+		return uplinks[i].ifName < uplinks[j].ifName
+	})
+	return uplinks
+}
+
+// makeMultipathDefaultRoute returns default route for the given table
+// splitting traffic across all uplinks according to their weights.
+func makeMultipathDefaultRoute(table int, uplinks []lbUplink) *netlink.Route {
+	if len(uplinks) == 0 {
+		return nil
+	}
+	_, dst, _ := net.ParseCIDR("0.0.0.0/0")
+	route := &netlink.Route{
+		Dst:    dst,
+		Table:  table,
+		Family: syscall.AF_INET,
+	}
+	for _, uplink := range uplinks {
+		route.MultiPath = append(route.MultiPath, &netlink.NexthopInfo{
+			LinkIndex: uplink.ifIndex,
+			Gw:        uplink.gateway,
+			// Weight of the nexthop is Hops+1.
+			Hops: uplink.weight - 1,
+		})
+	}
+	return route
+}
+
+// isDefaultRoute returns true for IPv4 default route.
+func isDefaultRoute(route netlink.Route) bool {
+	if route.Dst == nil {
+		return true
+	}
+	ones, _ := route.Dst.Mask.Size()
+	return ones == 0
+}
+
+// routeKey identifies route in the NI table for the purpose
+// of synchronization.
+func routeKey(route netlink.Route) string {
+	dst := "default"
+	if !isDefaultRoute(route) {
+		dst = route.Dst.String()
+	}
+	if len(route.MultiPath) > 0 {
+		return fmt.Sprintf("%s/multipath", dst)
+	}
+	return fmt.Sprintf("%s/%d/%d", dst, route.LinkIndex, route.Priority)
+}
+
+// getIntendedLoadBalancedRoutes returns routes which should be installed
+// into the routing table of the load-balanced network instance.
+func getIntendedLoadBalancedRoutes(table int, uplinks []lbUplink) []netlink.Route {
+	var routes []netlink.Route
+	for _, uplink := range uplinks {
+		for _, rt := range getAllIPv4Routes(uplink.ifIndex) {
+			if isDefaultRoute(rt) {
+				// Replaced with the multipath route.
+				continue
+			}
+			rt.Table = table
+			// Clear any RTNH_F_LINKDOWN etc flags since add doesn't like them
+			rt.Flags = 0
+			routes = append(routes, rt)
+		}
+	}
+	if defRoute := makeMultipathDefaultRoute(table, uplinks); defRoute != nil {
+		routes = append(routes, *defRoute)
+	}
+	return routes
+}
+
+// syncLoadBalancedUplinks (re)programs NAT and routes of the load-balanced
+// network instance to match the current set of usable uplinks.
+// Returns true if the set of uplinks has changed.
+func syncLoadBalancedUplinks(ctx *zedrouterContext,
+	status *types.NetworkInstanceStatus) bool {
+
+	uplinks := getLoadBalancedUplinks(ctx, status)
+	var uplinkNames []string
+	for _, uplink := range uplinks {
+		uplinkNames = append(uplinkNames, uplink.ifName)
+	}
+	subnetStr := status.Subnet.String()
+	// Update NAT rules.
+	for _, prevUplink := range status.LoadBalancedUplinks {
+		if !strInSlice(uplinkNames, prevUplink) {
+			err := iptables.IptableCmd(log, "-t", "nat", "-D", appChain("POSTROUTING"),
+				"-o", prevUplink, "-s", subnetStr, "-j", "MASQUERADE")
+			if err != nil {
+				log.Errorf("syncLoadBalancedUplinks: IptableCmd failed: %s", err)
+			}
+		}
+	}
+	for _, uplink := range uplinkNames {
+		if !strInSlice(status.LoadBalancedUplinks, uplink) {
+			err := iptables.IptableCmd(log, "-t", "nat", "-A", appChain("POSTROUTING"),
+				"-o", uplink, "-s", subnetStr, "-j", "MASQUERADE")
+			if err != nil {
+				log.Errorf("syncLoadBalancedUplinks: IptableCmd failed: %s", err)
+			}
+		}
+	}
+	// Update routes.
+	table := baseTableIndex + status.BridgeIfindex
+	intended := make(map[string]netlink.Route)
+	for _, rt := range getIntendedLoadBalancedRoutes(table, uplinks) {
+		intended[routeKey(rt)] = rt
+	}
+	filter := netlink.Route{Table: table}
+	current, err := netlink.RouteListFiltered(syscall.AF_INET, &filter,
+		netlink.RT_FILTER_TABLE)
+	if err != nil {
+		log.Errorf("syncLoadBalancedUplinks: RouteListFiltered failed: %v", err)
+	}
+	for _, rt := range current {
+		if rt.Type == syscall.RTN_UNREACHABLE {
+			// Default-drop route.
+			continue
+		}
+		if _, keep := intended[routeKey(rt)]; keep {
+			continue
+		}
+		rt := rt
+		log.Functionf("syncLoadBalancedUplinks(%s): deleting %v",
+			status.DisplayName, rt)
+		if err := netlink.RouteDel(&rt); err != nil {
+			log.Errorf("syncLoadBalancedUplinks: failed to delete %v from %d: %v",
+				rt, table, err)
+		}
+	}
+	for _, rt := range intended {
+		rt := rt
+		if err := netlink.RouteReplace(&rt); err != nil {
+			log.Errorf("syncLoadBalancedUplinks: failed to add %v to %d: %v",
+				rt, table, err)
+		}
+	}
+	changed := !reflect.DeepEqual(status.LoadBalancedUplinks, uplinkNames)
+	status.LoadBalancedUplinks = uplinkNames
+	return changed
+}
+
+// loadBalancingActivate sets up NAT and routing for the load-balanced
+// network instance.
+func loadBalancingActivate(ctx *zedrouterContext,
+	status *types.NetworkInstanceStatus) error {
+
+	log.Functionf("loadBalancingActivate(%s)", status.DisplayName)
+	err := AddDefaultDropRoute(status.BridgeIfindex, true)
+	if err != nil {
+		log.Errorf("loadBalancingActivate: failed to add default-drop route: %s", err)
+	}
+	syncLoadBalancedUplinks(ctx, status)
+	log.Noticef("loadBalancingActivate(%s): using uplinks %v",
+		status.DisplayName, status.LoadBalancedUplinks)
+	return nil
+}
+
+// loadBalancingInactivate removes NAT and routing configured for
+// the load-balanced network instance.
+func loadBalancingInactivate(status *types.NetworkInstanceStatus) {
+
+	log.Functionf("loadBalancingInactivate(%s)", status.DisplayName)
+	subnetStr := status.Subnet.String()
+	for _, uplink := range status.LoadBalancedUplinks {
+		err := iptables.IptableCmd(log, "-t", "nat", "-D", appChain("POSTROUTING"),
+			"-o", uplink, "-s", subnetStr, "-j", "MASQUERADE")
+		if err != nil {
+			log.Errorf("loadBalancingInactivate: IptableCmd failed: %s", err)
+		}
+	}
+	status.LoadBalancedUplinks = nil
+	table := baseTableIndex + status.BridgeIfindex
+	filter := netlink.Route{Table: table}
+	routes, err := netlink.RouteListFiltered(syscall.AF_INET, &filter,
+		netlink.RT_FILTER_TABLE)
+	if err != nil {
+		log.Errorf("loadBalancingInactivate: RouteListFiltered failed: %v", err)
+		return
+	}
+	for _, rt := range routes {
+		rt := rt
+		if err := netlink.RouteDel(&rt); err != nil {
+			log.Errorf("loadBalancingInactivate: failed to delete %v from %d: %v",
+				rt, table, err)
+		}
+	}
+}
+
+// updateLoadBalancedNetworkInstances re-synchronizes all activated
+// load-balanced network instances with the current state of uplinks.
+// If ifName is not empty, only network instances which use or may use
+// the given port are updated.
+func updateLoadBalancedNetworkInstances(ctx *zedrouterContext, ifName string) {
+	pub := ctx.pubNetworkInstanceStatus
+	if pub == nil {
+		return
+	}
+	for _, st := range pub.GetAll() {
+		status := st.(types.NetworkInstanceStatus)
+		if !status.Activated || !isUplinkLoadBalanced(&status) {
+			continue
+		}
+		if ifName != "" &&
+			!strInSlice(getIfNameListForLLOrIfname(ctx, status.Logicallabel), ifName) {
+			continue
+		}
+		if syncLoadBalancedUplinks(ctx, &status) {
+			log.Noticef("updateLoadBalancedNetworkInstances(%s): using uplinks %v",
+				status.DisplayName, status.LoadBalancedUplinks)
+			publishNetworkInstanceStatus(ctx, &status)
+		}
+	}
+}
+
+func strInSlice(list []string, str string) bool {
+	for _, item := range list {
+		if item == str {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package zedrouter
+
+import (
+	"net"
+	"testing"
+
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+// Test makeMultipathDefaultRoute function.
+func TestMakeMultipathDefaultRoute(t *testing.T) {
+	lb := types.UplinkLoadBalancing{
+		Mode: types.UplinkLBModeWeighted,
+		Weights: map[string]uint32{
+			"eth0": 3,
+			"wwan": 1000,
+		},
+	}
+	uplinks := []lbUplink{
+		{
+			ifName:  "eth0",
+			ifIndex: 2,
+			gateway: net.ParseIP("192.168.1.1"),
+			weight:  lb.PortWeight("eth0"),
+		},
+		{
+			ifName:  "eth1",
+			ifIndex: 3,
+			gateway: net.ParseIP("10.10.1.1"),
+			weight:  lb.PortWeight("eth1"),
+		},
+		{
+			ifName:  "wwan0",
+			ifIndex: 4,
+			gateway: net.ParseIP("172.16.5.1"),
+			weight:  lb.PortWeight("wwan"),
+		},
+	}
+	if route := makeMultipathDefaultRoute(600, nil); route != nil {
+		t.Errorf("expected no route without uplinks, got %v", route)
+	}
+	route := makeMultipathDefaultRoute(600, uplinks)
+	if route.Table != 600 {
+		t.Errorf("unexpected table %d", route.Table)
+	}
+	if !isDefaultRoute(*route) {
+		t.Errorf("expected default route, got dst %v", route.Dst)
+	}
+	if key := routeKey(*route); key != "default/multipath" {
+		t.Errorf("unexpected route key %s", key)
+	}
+	expHops := []int{2, 0, types.MaxUplinkWeight - 1}
+	if len(route.MultiPath) != len(expHops) {
+		t.Fatalf("expected %d nexthops, got %d", len(expHops), len(route.MultiPath))
+	}
+	for i, nh := range route.MultiPath {
+		if nh.LinkIndex != uplinks[i].ifIndex || !nh.Gw.Equal(uplinks[i].gateway) {
+			t.Errorf("unexpected nexthop %d: %v", i, nh)
+		}
+		if nh.Hops != expHops[i] {
+			t.Errorf("nexthop %d: expected hops %d, got %d", i, expHops[i], nh.Hops)
+		}
+	}
+
+	// With ECMP all nexthops have the same weight.
+	lb.Mode = types.UplinkLBModeECMP
+	for i := range uplinks {
+		uplinks[i].weight = lb.PortWeight(uplinks[i].ifName)
+	}
+	route = makeMultipathDefaultRoute(600, uplinks)
+	for i, nh := range route.MultiPath {
+		if nh.Hops != 0 {
+			t.Errorf("nexthop %d: expected hops 0, got %d", i, nh.Hops)
+		}
+	}
+}
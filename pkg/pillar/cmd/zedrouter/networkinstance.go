@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"reflect"
 	"strings"
 
 	"github.com/lf-edge/eve/pkg/pillar/base"
@@ -693,6 +694,43 @@ func doNetworkInstanceModify(ctx *zedrouterContext,
 		return err
 	}
 
+	if !reflect.DeepEqual(config.UplinkLoadBalancing, status.UplinkLoadBalancing) {
+		log.Functionf("doNetworkInstanceModify: uplink load-balancing changed "+
+			"from %+v to %+v", status.UplinkLoadBalancing, config.UplinkLoadBalancing)
+		if status.Activated && status.Type == types.NetworkInstanceTypeLocal {
+			natInactivate(ctx, status, false)
+			status.UplinkLoadBalancing = config.UplinkLoadBalancing
+			if err := natActivate(ctx, status); err != nil {
+				log.Error(err)
+				status.SetErrorNow(err.Error())
+				return err
+			}
+		} else {
+			status.UplinkLoadBalancing = config.UplinkLoadBalancing
+		}
+	}
+
+	if !reflect.DeepEqual(config.AccessPoint, status.AccessPoint) {
+		log.Functionf("doNetworkInstanceModify: access point changed "+
+			"from %s/%s to %s/%s", status.AccessPoint.Adapter,
+			status.AccessPoint.SSID, config.AccessPoint.Adapter,
+			config.AccessPoint.SSID)
+		stopAccessPoint(status)
+		status.AccessPoint = config.AccessPoint
+		if status.BridgeIPAddr != "" {
+			// Update DHCP pool for wireless clients.
+			restartDnsmasq(ctx, status)
+		}
+	}
+	if status.Activated && status.AccessPoint.IsEnabled() &&
+		status.AccessPointIfName == "" {
+		if err := startAccessPoint(ctx, status); err != nil {
+			log.Error(err)
+			status.SetErrorNow(err.Error())
+			return err
+		}
+	}
+
 	if config.Activate && !status.Activated {
 		err := doNetworkInstanceActivate(ctx, status)
 		if err != nil {
@@ -846,7 +884,9 @@ func lookupOrAllocateIPv4(status *types.NetworkInstanceStatus,
 
 	networkID := status.UUID
 	// the address does not fall in the Dhcp Range
-	if !status.DhcpRange.Contains(a) {
+	// (or falls into the pool reserved for wireless clients)
+	apPool, hasAPPool := accessPointDhcpPool(status)
+	if !status.DhcpRange.Contains(a) || (hasAPPool && apPool.Contains(a)) {
 		errStr := fmt.Sprintf("no free IP addresses in DHCP range(%s, %s)",
 			status.DhcpRange.Start.String(),
 			status.DhcpRange.End.String())
@@ -1254,6 +1294,15 @@ func doNetworkInstanceActivate(ctx *zedrouterContext,
 		}
 	case types.NetworkInstanceTypeLocal:
 		err = natActivate(ctx, status)
+		if err == nil {
+			// Failure to start access point does not prevent the network
+			// instance from being used by applications. Start of the access
+			// point is retried by retryNetworkInstance.
+			if apErr := startAccessPoint(ctx, status); apErr != nil {
+				log.Errorf("doNetworkInstanceActivate: %v", apErr)
+				status.SetErrorNow(apErr.Error())
+			}
+		}
 
 	case types.NetworkInstanceTypeCloud:
 		err = vpnActivate(ctx, status)
@@ -1367,6 +1416,7 @@ func doNetworkInstanceInactivate(
 	bridgeInactivateforNetworkInstance(ctx, status)
 	switch status.Type {
 	case types.NetworkInstanceTypeLocal:
+		stopAccessPoint(status)
 		natInactivate(ctx, status, false)
 	case types.NetworkInstanceTypeCloud:
 		vpnInactivate(ctx, status)
@@ -1399,6 +1449,7 @@ func doNetworkInstanceDelete(
 	case types.NetworkInstanceTypeSwitch:
 		// Nothing to do.
 	case types.NetworkInstanceTypeLocal:
+		stopAccessPoint(status)
 		natDelete(status)
 	case types.NetworkInstanceTypeCloud:
 		vpnDelete(ctx, status)
@@ -1650,6 +1701,17 @@ func natActivate(ctx *zedrouterContext,
 		err := errors.New(errStr)
 		return err
 	}
+	if isUplinkLoadBalanced(status) {
+		err := loadBalancingActivate(ctx, status)
+		if err != nil {
+			return err
+		}
+		devicenetwork.AddGatewaySourceRule(log, status.Subnet,
+			net.ParseIP(status.BridgeIPAddr), devicenetwork.PbrNatOutGatewayPrio)
+		devicenetwork.AddSourceRule(log, status.BridgeIfindex, status.Subnet, true, devicenetwork.PbrNatOutPrio)
+		devicenetwork.AddInwardSourceRule(log, status.BridgeIfindex, status.Subnet, true, devicenetwork.PbrNatInPrio)
+		return nil
+	}
 	for _, a := range status.IfNameList {
 		log.Functionf("Adding iptables rules for %s \n", a)
 		err := iptables.IptableCmd(log, "-t", "nat", "-A", appChain("POSTROUTING"),
@@ -1676,6 +1738,14 @@ func natInactivate(ctx *zedrouterContext,
 	status *types.NetworkInstanceStatus, inActivateOld bool) {
 
 	log.Functionf("natInactivate(%s)\n", status.DisplayName)
+	if isUplinkLoadBalanced(status) {
+		devicenetwork.DelGatewaySourceRule(log, status.Subnet,
+			net.ParseIP(status.BridgeIPAddr), devicenetwork.PbrNatOutGatewayPrio)
+		devicenetwork.DelSourceRule(log, status.BridgeIfindex, status.Subnet, true, devicenetwork.PbrNatOutPrio)
+		devicenetwork.DelInwardSourceRule(log, status.BridgeIfindex, status.Subnet, true, devicenetwork.PbrNatInPrio)
+		loadBalancingInactivate(status)
+		return
+	}
 	subnetStr := status.Subnet.String()
 	var oldUplinkIntf string
 	if inActivateOld {
@@ -1904,6 +1974,10 @@ func getAllNIindices(ctx *zedrouterContext, ifname string) []int {
 		if !status.IsUsingIfName(ifname) {
 			continue
 		}
+		if isUplinkLoadBalanced(&status) {
+			// Routes are synchronized by updateLoadBalancedNetworkInstances.
+			continue
+		}
 		if status.BridgeName == "" {
 			continue
 		}
@@ -190,6 +190,9 @@ func PbrRouteChange(ctx *zedrouterContext,
 		return
 	}
 	log.Tracef("RouteChange(%d/%s) %s %+v", rt.LinkIndex, ifname, op, rt)
+	if linkType != "bridge" {
+		updateLoadBalancedNetworkInstances(ctx, ifname)
+	}
 
 	// Add to ifindex specific table and to any bridges used by network instances
 	myrt := rt
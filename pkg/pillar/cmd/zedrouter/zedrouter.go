@@ -2153,6 +2153,7 @@ func handleDNSImpl(ctxArg interface{}, key string,
 
 	// Look for ports which disappeared
 	maybeRetryNetworkInstances(ctx)
+	updateLoadBalancedNetworkInstances(ctx, "")
 	propagateNetworkInstToAppNetwork(ctx)
 	handleMetaDataServerChange(ctx, &status)
 	log.Functionf("handleDNSImpl done for %s\n", key)
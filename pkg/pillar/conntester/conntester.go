@@ -22,6 +22,11 @@ type ConnectivityTester interface {
 type RemoteTemporaryFailure struct {
 	Endpoint   string
 	WrappedErr error
+	// CertTimeInvalid is set if the controller certificate was rejected because
+	// the device time is outside of the certificate validity period.
+	// Unless the certificate is really expired, this is caused by a skewed
+	// device clock.
+	CertTimeInvalid *types.CertTimeInvalid
 }
 
 // Error message.
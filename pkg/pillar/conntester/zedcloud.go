@@ -109,8 +109,9 @@ func (t *ZedcloudConnectivityTester) TestConnectivity(
 	if err != nil {
 		if rtf {
 			err = &RemoteTemporaryFailure{
-				Endpoint:   serverNameAndPort,
-				WrappedErr: err,
+				Endpoint:        serverNameAndPort,
+				WrappedErr:      err,
+				CertTimeInvalid: t.getCertTimeInvalid(err),
 			}
 		} else if portsNotReady := t.getPortsNotReady(err, dns); len(portsNotReady) > 0 {
 			// At least one of the uplink ports is not ready in terms of L3 connectivity.
@@ -158,3 +159,19 @@ func (t *ZedcloudConnectivityTester) getPortsNotReady(
 	}
 	return ports
 }
+
+// getCertTimeInvalid returns the first send attempt error (if any) reporting
+// the controller certificate as rejected due to the current device time being
+// outside of its validity period.
+func (t *ZedcloudConnectivityTester) getCertTimeInvalid(
+	verifyErr error) *types.CertTimeInvalid {
+	if sendErr, isSendErr := verifyErr.(*zedcloud.SendError); isSendErr {
+		for _, attempt := range sendErr.Attempts {
+			var certErr *types.CertTimeInvalid
+			if errors.As(attempt.Err, &certErr) {
+				return certErr
+			}
+		}
+	}
+	return nil
+}
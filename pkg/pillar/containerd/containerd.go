@@ -678,7 +678,12 @@ func (client *Client) ctrExec(ctx context.Context, domainName string, args []str
 	if err != nil {
 		return "", "", err
 	}
-	defer process.Delete(ctx)
+	// the process is killed and deleted even if ctx is done
+	cleanupCtx := context.Background()
+	if namespace, ok := namespaces.Namespace(ctx); ok {
+		cleanupCtx = namespaces.WithNamespace(cleanupCtx, namespace)
+	}
+	defer process.Delete(cleanupCtx, containerd.WithProcessKill)
 
 	// prepare an exit code channel
 	statusC, err := process.Wait(ctx)
@@ -691,8 +696,14 @@ func (client *Client) ctrExec(ctx context.Context, domainName string, args []str
 		return "", "", err
 	}
 
-	// block until the process exits or the timer fires
-	timer := time.NewTimer(30 * time.Second)
+	// block until the process exits, ctx is done, or the timer fires
+	// if ctx has no deadline
+	var timeout <-chan time.Time
+	if _, ok := ctx.Deadline(); !ok {
+		timer := time.NewTimer(30 * time.Second)
+		defer timer.Stop()
+		timeout = timer.C
+	}
 	select {
 	case status := <-statusC:
 		if code, _, e := status.Result(); e == nil && code != 0 {
@@ -700,11 +711,13 @@ func (client *Client) ctrExec(ctx context.Context, domainName string, args []str
 		} else {
 			err = e
 		}
-	case <-timer.C:
+	case <-timeout:
 		err = fmt.Errorf("execution timed out")
+	case <-ctx.Done():
+		err = fmt.Errorf("execution timed out: %v", ctx.Err())
 	}
 
-	st, ee := process.Status(ctx)
+	st, ee := process.Status(cleanupCtx)
 	logrus.Debugf("ctrExec process exited with: %v %v %d %d %d %d stdout: %s stderr: %s, err: %v", st, ee, stdOut.Cap(), stdOut.Len(), stdErr.Cap(), stdErr.Len(), stdOut.String(), stdErr.String(), err)
 	return stdOut.String(), stdErr.String(), err
 }
@@ -10,10 +10,14 @@ import (
 	"strings"
 )
 
+// DhcpResolvConfDir : directory where the DHCP client stores DNS configuration
+// received for an interface.
+const DhcpResolvConfDir = "/run/dhcpclient/resolv.conf"
+
 // ResolveConfDirs : directories where resolv.conf for an interface could be found.
-var ResolveConfDirs = []string{"/run/dhcpcd/resolv.conf", "/run/wwan/resolv.conf"}
+var ResolveConfDirs = []string{DhcpResolvConfDir, WwanResolvConfDir}
 
-// IfnameToResolvConf : Look for a file created by dhcpcd
+// IfnameToResolvConf : Look for a file created by the DHCP client or wwan microservice
 func IfnameToResolvConf(ifname string) string {
 	for _, d := range ResolveConfDirs {
 		filename := fmt.Sprintf("%s/%s.dhcp", d, ifname)
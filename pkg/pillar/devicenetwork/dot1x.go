@@ -0,0 +1,129 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package devicenetwork
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+const (
+	// RunDot1xDir : directory with configuration and credentials
+	// of wpa_supplicant instances used for wired 802.1X authentication.
+	// Every port has its own sub-directory named after the interface.
+	RunDot1xDir = "/run/dot1x"
+	// Dot1xCtrlDir : directory with control sockets of wired wpa_supplicant
+	// instances.
+	Dot1xCtrlDir = "/run/dot1x/ctrl"
+	// Dot1xConfFilename : name of the wpa_supplicant config file.
+	Dot1xConfFilename = "wpa_supplicant.conf"
+	// Dot1xPidFilename : name of the wpa_supplicant pid file.
+	Dot1xPidFilename = "wpa_supplicant.pid"
+
+	dot1xCtrlTimeout = 2 * time.Second
+)
+
+// Dot1xPortDir returns directory with the configuration of the wired
+// wpa_supplicant for the given interface.
+func Dot1xPortDir(ifName string) string {
+	return filepath.Join(RunDot1xDir, ifName)
+}
+
+// GetDot1xStatus queries wpa_supplicant running for the interface
+// over its control socket and returns the state of 802.1X authentication.
+func GetDot1xStatus(ifName string) types.Dot1xStatus {
+	reply, err := dot1xCtrlRequest(ifName, "STATUS")
+	if err != nil {
+		return types.Dot1xStatus{
+			State: types.Dot1xAuthStateUnknown,
+			Error: err.Error(),
+		}
+	}
+	return ParseDot1xStatus(reply)
+}
+
+// ParseDot1xStatus parses the reply of wpa_supplicant to the STATUS command.
+func ParseDot1xStatus(reply string) types.Dot1xStatus {
+	var paeState, portStatus, eapState string
+	scanner := bufio.NewScanner(strings.NewReader(reply))
+	for scanner.Scan() {
+		kv := strings.SplitN(scanner.Text(), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "Supplicant PAE state":
+			paeState = kv[1]
+		case "suppPortStatus":
+			portStatus = kv[1]
+		case "EAP state":
+			eapState = kv[1]
+		}
+	}
+	switch {
+	case portStatus == "Authorized":
+		return types.Dot1xStatus{State: types.Dot1xAuthStateAuthenticated}
+	case paeState == "HELD" || eapState == "FAILURE":
+		return types.Dot1xStatus{
+			State: types.Dot1xAuthStateFailed,
+			Error: fmt.Sprintf("EAP authentication failed "+
+				"(PAE state: %s, EAP state: %s)", paeState, eapState),
+		}
+	case paeState == "":
+		return types.Dot1xStatus{
+			State: types.Dot1xAuthStateUnknown,
+			Error: "missing supplicant PAE state",
+		}
+	}
+	return types.Dot1xStatus{State: types.Dot1xAuthStateAuthenticating}
+}
+
+// dot1xCtrlRequest sends request to wpa_supplicant over the control socket
+// and returns the reply.
+func dot1xCtrlRequest(ifName, request string) (string, error) {
+	remote := &net.UnixAddr{
+		Name: filepath.Join(Dot1xCtrlDir, ifName),
+		Net:  "unixgram",
+	}
+	// wpa_supplicant replies to the address of the client socket.
+	localFile, err := ioutil.TempFile(Dot1xCtrlDir, "client-"+ifName+"-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create client socket for %s: %v",
+			ifName, err)
+	}
+	localPath := localFile.Name()
+	localFile.Close()
+	os.Remove(localPath)
+	local := &net.UnixAddr{Name: localPath, Net: "unixgram"}
+	conn, err := net.DialUnix("unixgram", local, remote)
+	if err != nil {
+		os.Remove(localPath)
+		return "", fmt.Errorf("failed to connect to wpa_supplicant of %s: %v",
+			ifName, err)
+	}
+	defer os.Remove(localPath)
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(dot1xCtrlTimeout)); err != nil {
+		return "", err
+	}
+	if _, err = conn.Write([]byte(request)); err != nil {
+		return "", fmt.Errorf("failed to send %s to wpa_supplicant of %s: %v",
+			request, ifName, err)
+	}
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return "", fmt.Errorf("failed to read reply from wpa_supplicant of %s: %v",
+			ifName, err)
+	}
+	return string(buf[:n]), nil
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package devicenetwork
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// RunNTPDir : directory with the NTP client status.
+	RunNTPDir = "/run/ntpd"
+	// NTPStatusFilename : name of the file where the ntpd hook script
+	// (executed by ntpd after every clock step, stratum change and periodically
+	// every 11 minutes) records the synchronization status.
+	NTPStatusFilename = "status"
+	// NTPServersFilename : name of the file with the list of NTP servers
+	// (one per line) that ntpd was started with.
+	NTPServersFilename = "servers"
+
+	chronycPath    = "/usr/bin/chronyc"
+	chronycTimeout = 5 // seconds
+	// The ntpd hook is executed at least every 11 minutes. If the status
+	// is older than this, ntpd is likely not running.
+	ntpdStatusMaxAge = 30 * time.Minute
+	// Stratum 16 means unsynchronized in NTP.
+	ntpMaxStratum = 16
+)
+
+// GetNTPStatus returns the status of the device clock synchronization.
+// The status is obtained from chrony if installed, otherwise from the status
+// recorded by the ntpd hook script. If neither is available, only the kernel
+// clock state is reported.
+func GetNTPStatus(log *base.LogObject) (types.NTPStatus, error) {
+	servers := readNTPServers()
+	if _, err := os.Stat(chronycPath); err == nil {
+		out, err := base.Exec(log, chronycPath, "-c", "tracking").
+			OutputWithCustomTimeout(chronycTimeout)
+		if err != nil {
+			return types.NTPStatus{}, fmt.Errorf("chronyc tracking failed: %v", err)
+		}
+		status, err := ParseChronyTracking(string(out))
+		status.Servers = servers
+		return status, err
+	}
+	statusPath := filepath.Join(RunNTPDir, NTPStatusFilename)
+	if content, err := ioutil.ReadFile(statusPath); err == nil {
+		status, err := ParseNTPDHookStatus(string(content))
+		if err != nil {
+			return types.NTPStatus{}, err
+		}
+		if time.Since(status.LastUpdate) < ntpdStatusMaxAge {
+			status.Servers = servers
+			return status, nil
+		}
+	}
+	status, err := getKernelClockStatus()
+	status.Servers = servers
+	return status, err
+}
+
+// ParseNTPDHookStatus parses the status recorded by the ntpd hook script.
+// The hook records environment variables set by (busybox) ntpd
+// as key=value pairs, one per line.
+func ParseNTPDHookStatus(content string) (status types.NTPStatus, err error) {
+	status.Source = "ntpd"
+	var event string
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		kv := strings.SplitN(strings.TrimSpace(scanner.Text()), "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			continue
+		}
+		switch kv[0] {
+		case "event":
+			event = kv[1]
+		case "stratum":
+			stratum, err := strconv.ParseUint(kv[1], 10, 8)
+			if err != nil {
+				return status, fmt.Errorf("invalid stratum %q: %v", kv[1], err)
+			}
+			status.Stratum = uint8(stratum)
+		case "offset":
+			// Offset in seconds.
+			offset, err := strconv.ParseFloat(kv[1], 64)
+			if err != nil {
+				return status, fmt.Errorf("invalid offset %q: %v", kv[1], err)
+			}
+			status.Offset = time.Duration(offset * float64(time.Second))
+		case "poll_interval":
+			// Poll interval in seconds.
+			interval, err := strconv.ParseUint(kv[1], 10, 32)
+			if err != nil {
+				return status, fmt.Errorf("invalid poll interval %q: %v", kv[1], err)
+			}
+			status.PollInterval = time.Duration(interval) * time.Second
+		case "timestamp":
+			timestamp, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil {
+				return status, fmt.Errorf("invalid timestamp %q: %v", kv[1], err)
+			}
+			status.LastUpdate = time.Unix(timestamp, 0)
+		}
+	}
+	if event == "" {
+		return status, fmt.Errorf("missing ntpd event")
+	}
+	if event == "unsync" || status.Stratum == 0 || status.Stratum >= ntpMaxStratum {
+		status.State = types.NTPSyncStateUnsynchronized
+	} else {
+		status.State = types.NTPSyncStateSynchronized
+	}
+	return status, nil
+}
+
+// ParseChronyTracking parses the output of "chronyc -c tracking".
+func ParseChronyTracking(output string) (status types.NTPStatus, err error) {
+	status.Source = "chrony"
+	records, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+	if err != nil {
+		return status, fmt.Errorf("failed to parse chronyc output: %v", err)
+	}
+	// Reference ID, reference name, stratum, reference time, system time offset,
+	// last offset, RMS offset, frequency, residual frequency, skew, root delay,
+	// root dispersion, update interval, leap status.
+	const fieldCount = 14
+	if len(records) != 1 || len(records[0]) < fieldCount {
+		return status, fmt.Errorf("unexpected chronyc output: %q", output)
+	}
+	fields := records[0]
+	stratum, err := strconv.ParseUint(fields[2], 10, 8)
+	if err != nil {
+		return status, fmt.Errorf("invalid stratum %q: %v", fields[2], err)
+	}
+	status.Stratum = uint8(stratum)
+	refTime, err := strconv.ParseFloat(fields[3], 64)
+	if err != nil {
+		return status, fmt.Errorf("invalid reference time %q: %v", fields[3], err)
+	}
+	if refTime > 0 {
+		status.LastUpdate = time.Unix(0, int64(refTime*float64(time.Second)))
+	}
+	// System time offset is positive if the system clock is fast.
+	offset, err := strconv.ParseFloat(fields[4], 64)
+	if err != nil {
+		return status, fmt.Errorf("invalid offset %q: %v", fields[4], err)
+	}
+	status.Offset = time.Duration(offset * float64(time.Second))
+	interval, err := strconv.ParseFloat(fields[12], 64)
+	if err != nil {
+		return status, fmt.Errorf("invalid update interval %q: %v", fields[12], err)
+	}
+	status.PollInterval = time.Duration(interval * float64(time.Second))
+	if fields[13] == "Not synchronised" || status.Stratum == 0 ||
+		status.Stratum >= ntpMaxStratum {
+		status.State = types.NTPSyncStateUnsynchronized
+	} else {
+		status.State = types.NTPSyncStateSynchronized
+	}
+	return status, nil
+}
+
+// getKernelClockStatus returns synchronization state as known to the kernel.
+func getKernelClockStatus() (status types.NTPStatus, err error) {
+	status.Source = "kernel"
+	var timex unix.Timex
+	state, err := unix.Adjtimex(&timex)
+	if err != nil {
+		return status, fmt.Errorf("adjtimex failed: %v", err)
+	}
+	// Kernel reports TIME_ERROR while the clock is not synchronized (STA_UNSYNC).
+	if state == unix.TIME_ERROR {
+		status.State = types.NTPSyncStateUnsynchronized
+	} else {
+		status.State = types.NTPSyncStateSynchronized
+	}
+	return status, nil
+}
+
+// readNTPServers returns NTP servers that ntpd was started with.
+func readNTPServers() (servers []string) {
+	content, err := ioutil.ReadFile(filepath.Join(RunNTPDir, NTPServersFilename))
+	if err != nil {
+		return nil
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if server := strings.TrimSpace(line); server != "" {
+			servers = append(servers, server)
+		}
+	}
+	return servers
+}
@@ -48,9 +48,21 @@ func CheckAndGetNetworkProxy(log *base.LogObject, portStatus *types.NetworkPortS
 		proxyConfig.Pacfile = pac
 		return nil
 	}
+	// Try the URL received from DHCP (option 252) before searching DNS
+	if proxyConfig.DhcpWpadURL != "" {
+		url := proxyConfig.DhcpWpadURL
+		pac, err := getPacFile(log, url, ifname, metrics)
+		if err == nil {
+			proxyConfig.Pacfile = pac
+			proxyConfig.WpadURL = url
+			return nil
+		}
+		log.Warnf("Failed to fetch %s received from DHCP for %s: %s",
+			url, ifname, err)
+	}
 	dn := portStatus.DomainName
 	if dn == "" {
-		errStr := fmt.Sprintf("NetworkProxyEnable for %s but neither a NetworkProxyURL nor a DomainName",
+		errStr := fmt.Sprintf("NetworkProxyEnable for %s but neither a NetworkProxyURL, a WPAD URL from DHCP nor a DomainName",
 			ifname)
 		log.Errorln(errStr)
 		return errors.New(errStr)
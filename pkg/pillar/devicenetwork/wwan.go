@@ -4,15 +4,9 @@
 package devicenetwork
 
 const (
-	// RunWwanDir : directory where config/status/metrics are exchanged between
-	// nim and wwan microservice.
+	// RunWwanDir : directory with runtime files of the wwan microservice.
 	RunWwanDir = "/run/wwan/"
-	// WwanConfigPath : LTE configuration submitted by NIM to wwan microservice.
-	WwanConfigPath = RunWwanDir + "config.json"
-	// WwanStatusPath : LTE status data published by wwan microservice.
-	WwanStatusPath = RunWwanDir + "status.json"
-	// WwanMetricsPath : LTE metrics published by wwan microservice.
-	WwanMetricsPath = RunWwanDir + "metrics.json"
-	// WwanLocationPath : Location info obtained from GNSS receiver by wwan microservice.
-	WwanLocationPath = RunWwanDir + "location.json"
+	// WwanResolvConfDir : directory where the wwan microservice stores DNS
+	// servers obtained from cellular networks (as <interface>.dhcp).
+	WwanResolvConfDir = RunWwanDir + "resolv.conf"
 )
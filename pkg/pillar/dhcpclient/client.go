@@ -0,0 +1,510 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package dhcpclient implements DHCPv4 (RFC 2131) and DHCPv6 (RFC 8415) client
+// used by NIM to obtain IP configuration for device ports.
+// Client applies the obtained addresses, routes and DNS configuration directly
+// to the Linux network stack, persists leases to survive reboots and publishes
+// Info about the applied configuration into a file watched by NetworkMonitor.
+// DHCPv6 is used only to obtain non-temporary addresses (IA_NA) and DNS/NTP
+// configuration, default IPv6 route is learned by the kernel from Router
+// Advertisements.
+package dhcpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	// DefaultLeaseDir : directory where leases are persisted by default.
+	DefaultLeaseDir = "/persist/status/nim/dhcp-leases"
+	// DefaultInfoDir : directory where Info is published by default.
+	DefaultInfoDir = "/run/dhcpclient"
+	// Routes installed by the client are marked with this protocol number.
+	rtProtoDHCP = 16
+	// How long to wait before retrying to open socket.
+	openRetryInterval = 10 * time.Second
+	// How long to wait for goroutines to exit in Stop.
+	stopTimeout = 10 * time.Second
+)
+
+// Config : configuration for DHCP client.
+type Config struct {
+	Log    *base.LogObject
+	IfName string
+	// DHCP is run for each enabled IP version.
+	IPv4, IPv6 bool
+	// Hostname sent to DHCPv4 server (option 12).
+	Hostname string
+	// NoGateway : do not install default route received from DHCPv4 server.
+	NoGateway bool
+	// StaticIPv4 : if defined, this configuration is applied instead of running
+	// DHCPv4 (IPv4 is ignored).
+	StaticIPv4 *Lease4
+	// LeaseDir : directory where obtained leases are persisted.
+	LeaseDir string
+	// InfoDir : directory where Info is published (see InfoFile).
+	InfoDir string
+	// ResolvConf : path to the resolv.conf file generated with the DNS
+	// configuration received from the server(s).
+	ResolvConf string
+}
+
+// Client : DHCP client running for one interface.
+type Client struct {
+	config Config
+	log    *base.LogObject
+	hwAddr net.HardwareAddr
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// Currently applied configuration.
+	sync.Mutex
+	lease4  *Lease4
+	lease6  *Lease6
+	routes4 []netlink.Route
+}
+
+// NewClient creates a new DHCP client. Use Start to run it.
+func NewClient(config Config) *Client {
+	return &Client{config: config, log: config.Log}
+}
+
+// Start applies static configuration and starts DHCP exchanges in the background.
+func (c *Client) Start() error {
+	intf, err := net.InterfaceByName(c.config.IfName)
+	if err != nil {
+		return fmt.Errorf("failed to get interface %s: %w", c.config.IfName, err)
+	}
+	c.hwAddr = intf.HardwareAddr
+	if c.config.StaticIPv4 != nil {
+		lease := *c.config.StaticIPv4
+		lease.fillTimers()
+		lease.AcquiredAt = time.Now()
+		if err = c.bind4(&lease); err != nil {
+			return err
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	if c.config.IPv4 && c.config.StaticIPv4 == nil {
+		c.wg.Add(1)
+		go c.run4(ctx)
+	}
+	if c.config.IPv6 {
+		// Forwarding is enabled in EVE, RA must be explicitly accepted.
+		c.setAcceptRA()
+		c.wg.Add(1)
+		go c.run6(ctx)
+	}
+	return nil
+}
+
+// Stop releases leases and removes the applied configuration.
+func (c *Client) Stop() error {
+	if c.cancel != nil {
+		c.cancel()
+		done := make(chan struct{})
+		go func() {
+			c.wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(stopTimeout):
+			return fmt.Errorf("DHCP client for %s failed to stop in time", c.config.IfName)
+		}
+	}
+	c.Lock()
+	lease4 := c.lease4
+	c.Unlock()
+	if lease4 != nil && lease4.IsStatic() {
+		c.unbind4()
+	}
+	return nil
+}
+
+// GetInfo returns the currently applied IP configuration.
+func (c *Client) GetInfo() Info {
+	c.Lock()
+	defer c.Unlock()
+	return Info{IfName: c.config.IfName, IPv4: c.lease4, IPv6: c.lease6}
+}
+
+func (c *Client) setAcceptRA() {
+	sysctl := fmt.Sprintf("/proc/sys/net/ipv6/conf/%s/accept_ra", c.config.IfName)
+	if err := ioutil.WriteFile(sysctl, []byte("2"), 0644); err != nil {
+		c.log.Warnf("DHCP client: failed to enable RA for %s: %v", c.config.IfName, err)
+	}
+}
+
+// sleep waits for the given duration or until the context is canceled.
+// Returns false if the context was canceled.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// withJitter randomizes the timeout by up to +-1s (RFC 2131, section 4.1)
+// or by up to +-10% for timeouts shorter than 10 seconds.
+func withJitter(timeout time.Duration) time.Duration {
+	jitter := time.Second
+	if timeout < 10*time.Second {
+		jitter = timeout / 10
+	}
+	if jitter <= 0 {
+		return timeout
+	}
+	return timeout - jitter + time.Duration(rand.Int63n(int64(2*jitter)))
+}
+
+// remaining returns lifetime in seconds as expected by netlink (0 = forever).
+func remaining(expiry time.Time, lifetime time.Duration) int {
+	if lifetime >= InfinityLifetime {
+		return 0
+	}
+	secs := int(time.Until(expiry) / time.Second)
+	if secs < 1 {
+		secs = 1
+	}
+	return secs
+}
+
+// Application of IPv4 configuration.
+
+func (c *Client) bind4(lease *Lease4) error {
+	link, err := netlink.LinkByName(c.config.IfName)
+	if err != nil {
+		return err
+	}
+	c.Lock()
+	defer c.Unlock()
+	prev := c.lease4
+	ipNet := &net.IPNet{IP: lease.Address, Mask: lease.Mask}
+	lft := remaining(lease.Expiry(), lease.LeaseTime)
+	addr := &netlink.Addr{
+		IPNet:       ipNet,
+		Broadcast:   broadcastAddr(ipNet),
+		ValidLft:    lft,
+		PreferedLft: lft,
+	}
+	if err = netlink.AddrReplace(link, addr); err != nil {
+		return fmt.Errorf("failed to assign address %s to %s: %w",
+			ipNet, c.config.IfName, err)
+	}
+	if prev != nil && !prev.Address.Equal(lease.Address) {
+		c.delAddr4(link, prev)
+	}
+	c.lease4 = lease
+	c.updateRoutes4(link)
+	c.publish()
+	return nil
+}
+
+func (c *Client) unbind4() {
+	c.Lock()
+	defer c.Unlock()
+	if c.lease4 == nil {
+		return
+	}
+	prev := c.lease4
+	c.lease4 = nil
+	if link, err := netlink.LinkByName(c.config.IfName); err == nil {
+		// Routes are removed together with the address by the kernel,
+		// but not those with on-link flag.
+		c.updateRoutes4(link)
+		c.delAddr4(link, prev)
+	}
+	c.routes4 = nil
+	c.publish()
+}
+
+func (c *Client) delAddr4(link netlink.Link, lease *Lease4) {
+	if lease == nil {
+		return
+	}
+	addr := &netlink.Addr{IPNet: &net.IPNet{IP: lease.Address, Mask: lease.Mask}}
+	if err := netlink.AddrDel(link, addr); err != nil && !errors.Is(err, syscall.EADDRNOTAVAIL) {
+		c.log.Warnf("DHCP client: failed to remove address %s from %s: %v",
+			addr.IPNet, c.config.IfName, err)
+	}
+}
+
+func broadcastAddr(ipNet *net.IPNet) net.IP {
+	ip := ipNet.IP.To4()
+	mask := net.IP(ipNet.Mask).To4()
+	if ip == nil || mask == nil {
+		return nil
+	}
+	bcast := make(net.IP, net.IPv4len)
+	for i := range ip {
+		bcast[i] = ip[i] | ^mask[i]
+	}
+	return bcast
+}
+
+// routeMetric : metric of routes installed for the interface.
+// Same as used by dhcpcd for wired interfaces.
+func routeMetric(link netlink.Link) int {
+	return 200 + link.Attrs().Index
+}
+
+// intendedRoutes4 returns routes that should be installed for the lease.
+func (c *Client) intendedRoutes4(link netlink.Link, lease *Lease4) (routes []netlink.Route) {
+	if lease == nil {
+		return nil
+	}
+	staticRoutes := lease.StaticRoutes
+	if len(staticRoutes) == 0 && len(lease.Routers) > 0 {
+		// Router option is ignored if classless static routes are provided
+		// (RFC 3442).
+		staticRoutes = []Route{{
+			Dst:     &net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)},
+			Gateway: lease.Routers[0],
+		}}
+	}
+	subnet := lease.Subnet()
+	for _, route := range staticRoutes {
+		ones, _ := route.Dst.Mask.Size()
+		isDefault := ones == 0
+		if isDefault && c.config.NoGateway {
+			continue
+		}
+		nlRoute := netlink.Route{
+			LinkIndex: link.Attrs().Index,
+			Src:       lease.Address,
+			Priority:  routeMetric(link),
+			Protocol:  rtProtoDHCP,
+			Table:     syscall.RT_TABLE_MAIN,
+		}
+		if !isDefault {
+			nlRoute.Dst = route.Dst
+		}
+		if route.Gateway == nil || route.Gateway.IsUnspecified() {
+			nlRoute.Scope = netlink.SCOPE_LINK
+		} else {
+			nlRoute.Gw = route.Gateway
+			if !subnet.Contains(route.Gateway) {
+				nlRoute.Flags = int(netlink.FLAG_ONLINK)
+			}
+		}
+		routes = append(routes, nlRoute)
+	}
+	return routes
+}
+
+// updateRoutes4 installs routes for the current lease and removes obsolete routes.
+func (c *Client) updateRoutes4(link netlink.Link) {
+	intended := c.intendedRoutes4(link, c.lease4)
+	for _, prevRoute := range c.routes4 {
+		var keep bool
+		for _, route := range intended {
+			if routesEqual(route, prevRoute) {
+				keep = true
+				break
+			}
+		}
+		if !keep {
+			prevRoute := prevRoute
+			if err := netlink.RouteDel(&prevRoute); err != nil &&
+				!errors.Is(err, syscall.ESRCH) {
+				c.log.Warnf("DHCP client: failed to remove route %+v: %v", prevRoute, err)
+			}
+		}
+	}
+	c.routes4 = nil
+	for _, route := range intended {
+		route := route
+		if err := netlink.RouteReplace(&route); err != nil {
+			c.log.Errorf("DHCP client: failed to add route %+v: %v", route, err)
+			continue
+		}
+		c.routes4 = append(c.routes4, route)
+	}
+}
+
+func routesEqual(r1, r2 netlink.Route) bool {
+	dstEqual := (r1.Dst == nil && r2.Dst == nil) ||
+		(r1.Dst != nil && r2.Dst != nil && r1.Dst.String() == r2.Dst.String())
+	return dstEqual && r1.Gw.Equal(r2.Gw) && r1.Src.Equal(r2.Src) &&
+		r1.Priority == r2.Priority && r1.LinkIndex == r2.LinkIndex
+}
+
+// Application of IPv6 configuration.
+
+func (c *Client) bind6(lease *Lease6) error {
+	link, err := netlink.LinkByName(c.config.IfName)
+	if err != nil {
+		return err
+	}
+	c.Lock()
+	defer c.Unlock()
+	prev := c.lease6
+	ipNet := &net.IPNet{IP: lease.Address, Mask: net.CIDRMask(128, 128)}
+	addr := &netlink.Addr{
+		IPNet:       ipNet,
+		ValidLft:    remaining(lease.Expiry(), lease.ValidLifetime),
+		PreferedLft: remaining(lease.AcquiredAt.Add(lease.PreferredLifetime), lease.PreferredLifetime),
+	}
+	if err = netlink.AddrReplace(link, addr); err != nil {
+		return fmt.Errorf("failed to assign address %s to %s: %w",
+			ipNet, c.config.IfName, err)
+	}
+	if prev != nil && !prev.Address.Equal(lease.Address) {
+		c.delAddr6(link, prev)
+	}
+	c.lease6 = lease
+	c.publish()
+	return nil
+}
+
+func (c *Client) unbind6() {
+	c.Lock()
+	defer c.Unlock()
+	if c.lease6 == nil {
+		return
+	}
+	if link, err := netlink.LinkByName(c.config.IfName); err == nil {
+		c.delAddr6(link, c.lease6)
+	}
+	c.lease6 = nil
+	c.publish()
+}
+
+func (c *Client) delAddr6(link netlink.Link, lease *Lease6) {
+	addr := &netlink.Addr{IPNet: &net.IPNet{IP: lease.Address, Mask: net.CIDRMask(128, 128)}}
+	if err := netlink.AddrDel(link, addr); err != nil && !errors.Is(err, syscall.EADDRNOTAVAIL) {
+		c.log.Warnf("DHCP client: failed to remove address %s from %s: %v",
+			addr.IPNet, c.config.IfName, err)
+	}
+}
+
+// Publishing of the applied configuration.
+
+// publish writes resolv.conf and Info for NetworkMonitor.
+// This method is run with the client in the locked state.
+func (c *Client) publish() {
+	c.writeResolvConf()
+	if c.config.InfoDir == "" {
+		return
+	}
+	infoFile := InfoFile(c.config.InfoDir, c.config.IfName)
+	if c.lease4 == nil && c.lease6 == nil {
+		if err := os.Remove(infoFile); err != nil && !os.IsNotExist(err) {
+			c.log.Errorf("DHCP client: failed to remove %s: %v", infoFile, err)
+		}
+		return
+	}
+	info := Info{IfName: c.config.IfName, IPv4: c.lease4, IPv6: c.lease6}
+	if err := writeJSONFile(infoFile, info); err != nil {
+		c.log.Errorf("DHCP client: failed to publish info: %v", err)
+	}
+}
+
+func (c *Client) writeResolvConf() {
+	if c.config.ResolvConf == "" {
+		return
+	}
+	var servers []net.IP
+	var domains []string
+	if c.lease4 != nil {
+		servers = append(servers, c.lease4.DNSServers...)
+		if c.lease4.DomainName != "" {
+			domains = append(domains, c.lease4.DomainName)
+		}
+	}
+	if c.lease6 != nil {
+		servers = append(servers, c.lease6.DNSServers...)
+		domains = append(domains, c.lease6.DomainSearch...)
+	}
+	if len(servers) == 0 && len(domains) == 0 {
+		err := os.Remove(c.config.ResolvConf)
+		if err != nil && !os.IsNotExist(err) {
+			c.log.Errorf("DHCP client: failed to remove %s: %v", c.config.ResolvConf, err)
+		}
+		return
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Generated by DHCP client for %s\n", c.config.IfName)
+	if len(domains) > 0 {
+		fmt.Fprintf(&sb, "search %s\n", strings.Join(domains, " "))
+	}
+	for _, server := range servers {
+		fmt.Fprintf(&sb, "nameserver %s\n", server)
+	}
+	if err := writeFile(c.config.ResolvConf, []byte(sb.String())); err != nil {
+		c.log.Errorf("DHCP client: failed to write %s: %v", c.config.ResolvConf, err)
+	}
+}
+
+// Lease persistence.
+
+func (c *Client) loadLease4() *Lease4 {
+	var lease Lease4
+	if !c.loadLease(false, &lease) || !time.Now().Before(lease.Expiry()) {
+		return nil
+	}
+	return &lease
+}
+
+func (c *Client) loadLease6() *Lease6 {
+	var lease Lease6
+	if !c.loadLease(true, &lease) || !time.Now().Before(lease.Expiry()) {
+		return nil
+	}
+	return &lease
+}
+
+func (c *Client) loadLease(ipv6 bool, lease interface{}) bool {
+	if c.config.LeaseDir == "" {
+		return false
+	}
+	path := leaseFile(c.config.LeaseDir, c.config.IfName, ipv6)
+	if err := readJSONFile(path, lease); err != nil {
+		if !os.IsNotExist(err) {
+			c.log.Warnf("DHCP client: failed to load lease from %s: %v", path, err)
+		}
+		return false
+	}
+	return true
+}
+
+func (c *Client) persistLease(ipv6 bool, lease interface{}) {
+	if c.config.LeaseDir == "" {
+		return
+	}
+	path := leaseFile(c.config.LeaseDir, c.config.IfName, ipv6)
+	if err := writeJSONFile(path, lease); err != nil {
+		c.log.Errorf("DHCP client: failed to persist lease into %s: %v", path, err)
+	}
+}
+
+func (c *Client) removeLease(ipv6 bool) {
+	if c.config.LeaseDir == "" {
+		return
+	}
+	path := leaseFile(c.config.LeaseDir, c.config.IfName, ipv6)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		c.log.Errorf("DHCP client: failed to remove lease %s: %v", path, err)
+	}
+}
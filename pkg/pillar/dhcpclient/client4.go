@@ -0,0 +1,346 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package dhcpclient
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"time"
+)
+
+// Retransmission parameters for DHCPv4 (RFC 2131, section 4.1).
+// Variables instead of constants to allow tests to shorten the timeouts.
+var (
+	retransmitInit4 = 4 * time.Second
+	retransmitMax4  = 64 * time.Second
+	// Number of REQUEST (re)transmissions before going back to DISCOVER.
+	requestAttempts4 = 4
+	// Minimal retransmission timeout in RENEWING and REBINDING states.
+	minRenewRetry = 60 * time.Second
+)
+
+// run4 runs the DHCPv4 client state machine until the context is canceled.
+func (c *Client) run4(ctx context.Context) {
+	defer c.wg.Done()
+	var conn *conn4
+	for {
+		var err error
+		conn, err = openConn4(c.config.IfName)
+		if err == nil {
+			break
+		}
+		c.log.Errorf("DHCP client: %s: %v", c.config.IfName, err)
+		if !sleep(ctx, openRetryInterval) {
+			return
+		}
+	}
+	defer conn.close()
+	lease := c.loadLease4()
+	if lease != nil {
+		lease = c.reboot4(ctx, conn, lease)
+	}
+	for ctx.Err() == nil {
+		if lease == nil {
+			if lease = c.discover4(ctx, conn); lease == nil {
+				break
+			}
+		}
+		if err := c.bind4(lease); err != nil {
+			c.log.Errorf("DHCP client: failed to apply lease for %s: %v",
+				c.config.IfName, err)
+			if !sleep(ctx, openRetryInterval) {
+				break
+			}
+			continue
+		}
+		c.persistLease(false, lease)
+		c.log.Noticef("DHCP client: %s bound to %s (lease time %v)",
+			c.config.IfName, lease.Subnet(), lease.LeaseTime)
+		lease = c.maintain4(ctx, conn, lease)
+		if lease == nil {
+			c.log.Warnf("DHCP client: lost IPv4 lease for %s", c.config.IfName)
+			c.unbind4()
+			c.removeLease(false)
+		}
+	}
+	if lease != nil {
+		c.release4(conn, lease)
+	}
+	c.unbind4()
+}
+
+// newMessage4 prepares a client message with options common to all message types.
+func (c *Client) newMessage4(msgType MessageType4, xid uint32, start time.Time) *message4 {
+	msg := &message4{
+		op:      bootRequest,
+		xid:     xid,
+		chaddr:  c.hwAddr,
+		options: make(options4),
+	}
+	if !start.IsZero() {
+		secs := time.Since(start) / time.Second
+		if secs > 0xffff {
+			secs = 0xffff
+		}
+		msg.secs = uint16(secs)
+	}
+	msg.options[opt4MessageType] = []byte{uint8(msgType)}
+	msg.options[opt4ClientID] = append([]byte{dhcp4HwTypeEth}, c.hwAddr...)
+	if msgType == MsgRelease {
+		return msg
+	}
+	msg.options[opt4MaxMessageSize] = []byte{1500 >> 8, 1500 & 0xff}
+	msg.options[opt4ParamRequestList] = requestedOptions4
+	if c.config.Hostname != "" {
+		msg.options[opt4Hostname] = []byte(c.config.Hostname)
+	}
+	return msg
+}
+
+// exchange4 sends the message and waits for a reply with one of the expected
+// message types until the timeout.
+// Returns nil reply (and nil error) on timeout.
+func (c *Client) exchange4(ctx context.Context, conn *conn4, msg *message4,
+	dst net.IP, timeout time.Duration, expected ...MessageType4) (*message4, error) {
+	c.log.Functionf("DHCP client: sending %s (xid %#x) to %s via %s",
+		msg.msgType(), msg.xid, dst, c.config.IfName)
+	if err := conn.send(msg.encode(), msg.ciaddr, dst); err != nil {
+		// Interface may be down or without address (when renewing).
+		// Handle as if the message was lost.
+		c.log.Functionf("DHCP client: failed to send %s via %s: %v",
+			msg.msgType(), c.config.IfName, err)
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		// Wake up at least every second to check if the client was stopped.
+		recvDeadline := time.Now().Add(time.Second)
+		if recvDeadline.After(deadline) {
+			recvDeadline = deadline
+		}
+		data, err := conn.recv(recvDeadline)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			if errors.Is(err, errRecvTimeout) {
+				if time.Now().Before(deadline) {
+					continue
+				}
+				return nil, nil
+			}
+			return nil, err
+		}
+		reply, err := decodeMessage4(data)
+		if err != nil {
+			c.log.Functionf("DHCP client: ignoring invalid message: %v", err)
+			continue
+		}
+		if reply.op != bootReply || reply.xid != msg.xid ||
+			reply.chaddr.String() != c.hwAddr.String() {
+			continue
+		}
+		for _, msgType := range expected {
+			if reply.msgType() == msgType {
+				c.log.Functionf("DHCP client: received %s (xid %#x) via %s",
+					msgType, reply.xid, c.config.IfName)
+				return reply, nil
+			}
+		}
+	}
+}
+
+// discover4 obtains a new lease (INIT and SELECTING states).
+// Returns nil if the context was canceled.
+func (c *Client) discover4(ctx context.Context, conn *conn4) *Lease4 {
+	timeout := retransmitInit4
+	start := time.Now()
+	for {
+		xid := rand.Uint32()
+		discover := c.newMessage4(MsgDiscover, xid, start)
+		discover.flags = dhcp4BroadcastBit
+		offer, err := c.exchange4(ctx, conn, discover, net.IPv4bcast,
+			withJitter(timeout), MsgOffer)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			c.log.Errorf("DHCP client: %s: %v", c.config.IfName, err)
+		}
+		if offer == nil {
+			if timeout *= 2; timeout > retransmitMax4 {
+				timeout = retransmitMax4
+			}
+			continue
+		}
+		serverID := offer.options[opt4ServerID]
+		if optIP4(serverID) == nil || offer.yiaddr.IsUnspecified() {
+			continue
+		}
+		request := c.newMessage4(MsgRequest, xid, start)
+		request.flags = dhcp4BroadcastBit
+		request.options[opt4RequestedIP] = offer.yiaddr.To4()
+		request.options[opt4ServerID] = serverID
+		lease, nak := c.request4(ctx, conn, request)
+		if lease != nil {
+			return lease
+		}
+		if nak {
+			c.log.Warnf("DHCP client: offer of %s was declined by the server",
+				offer.yiaddr)
+		}
+		timeout = retransmitInit4
+	}
+}
+
+// request4 (re)transmits REQUEST until ACK or NAK is received.
+// Returns nil lease if no ACK was received, nak is true if NAK was received.
+func (c *Client) request4(ctx context.Context, conn *conn4,
+	request *message4) (lease *Lease4, nak bool) {
+	timeout := retransmitInit4
+	for i := 0; i < requestAttempts4; i++ {
+		sentAt := time.Now()
+		reply, err := c.exchange4(ctx, conn, request, net.IPv4bcast,
+			withJitter(timeout), MsgAck, MsgNak)
+		if ctx.Err() != nil {
+			return nil, false
+		}
+		if err != nil {
+			c.log.Errorf("DHCP client: %s: %v", c.config.IfName, err)
+		}
+		if reply != nil {
+			return c.handleReply4(reply, sentAt)
+		}
+		if timeout *= 2; timeout > retransmitMax4 {
+			timeout = retransmitMax4
+		}
+	}
+	return nil, false
+}
+
+func (c *Client) handleReply4(reply *message4, sentAt time.Time) (lease *Lease4, nak bool) {
+	if reply.msgType() == MsgNak {
+		return nil, true
+	}
+	lease, err := leaseFromAck(reply, sentAt)
+	if err != nil {
+		c.log.Errorf("DHCP client: invalid DHCPACK received via %s: %v",
+			c.config.IfName, err)
+		return nil, false
+	}
+	return lease, false
+}
+
+// reboot4 tries to confirm the persisted lease (INIT-REBOOT state).
+// If the server does not respond, the persisted lease is used until it expires.
+func (c *Client) reboot4(ctx context.Context, conn *conn4, lease *Lease4) *Lease4 {
+	request := c.newMessage4(MsgRequest, rand.Uint32(), time.Now())
+	request.flags = dhcp4BroadcastBit
+	request.options[opt4RequestedIP] = lease.Address.To4()
+	newLease, nak := c.request4(ctx, conn, request)
+	if newLease != nil {
+		return newLease
+	}
+	if nak {
+		c.log.Noticef("DHCP client: persisted lease for %s (%s) was rejected",
+			c.config.IfName, lease.Address)
+		c.removeLease(false)
+		return nil
+	}
+	if ctx.Err() == nil && time.Now().Before(lease.Expiry()) {
+		c.log.Noticef("DHCP client: no response to INIT-REBOOT, using persisted "+
+			"lease for %s (%s)", c.config.IfName, lease.Address)
+		return lease
+	}
+	return nil
+}
+
+// maintain4 renews (RENEWING state) and rebinds (REBINDING state) the lease.
+// Returns nil when the lease expires or is rejected by the server.
+// Returns the current lease when the context is canceled.
+func (c *Client) maintain4(ctx context.Context, conn *conn4, lease *Lease4) *Lease4 {
+	for {
+		if !sleep(ctx, time.Until(lease.AcquiredAt.Add(lease.T1))) {
+			return lease
+		}
+		newLease, lost := c.extend4(ctx, conn, lease)
+		if ctx.Err() != nil {
+			return lease
+		}
+		if lost {
+			return nil
+		}
+		if err := c.bind4(newLease); err != nil {
+			c.log.Errorf("DHCP client: failed to apply renewed lease for %s: %v",
+				c.config.IfName, err)
+		}
+		c.persistLease(false, newLease)
+		c.log.Functionf("DHCP client: lease for %s (%s) extended by %v",
+			c.config.IfName, newLease.Address, newLease.LeaseTime)
+		lease = newLease
+	}
+}
+
+// extend4 tries to extend the lease first with the original server (unicast),
+// then with any server (broadcast) after T2.
+func (c *Client) extend4(ctx context.Context, conn *conn4,
+	lease *Lease4) (newLease *Lease4, lost bool) {
+	t2 := lease.AcquiredAt.Add(lease.T2)
+	expiry := lease.Expiry()
+	for ctx.Err() == nil {
+		now := time.Now()
+		var limit time.Time
+		var dst net.IP
+		switch {
+		case now.Before(t2):
+			limit, dst = t2, lease.ServerID
+		case now.Before(expiry):
+			limit, dst = expiry, net.IPv4bcast
+		default:
+			return nil, true
+		}
+		// Wait one-half of the remaining time, down to minRenewRetry
+		// (RFC 2131, section 4.4.5).
+		timeout := limit.Sub(now) / 2
+		if timeout < minRenewRetry {
+			timeout = minRenewRetry
+		}
+		if now.Add(timeout).After(limit) {
+			timeout = limit.Sub(now)
+		}
+		request := c.newMessage4(MsgRequest, rand.Uint32(), time.Time{})
+		request.ciaddr = lease.Address
+		sentAt := time.Now()
+		reply, err := c.exchange4(ctx, conn, request, dst, timeout, MsgAck, MsgNak)
+		if err != nil && ctx.Err() == nil {
+			c.log.Errorf("DHCP client: %s: %v", c.config.IfName, err)
+			sleep(ctx, timeout)
+		}
+		if reply == nil {
+			continue
+		}
+		newLease, nak := c.handleReply4(reply, sentAt)
+		if nak {
+			return nil, true
+		}
+		if newLease != nil {
+			return newLease, false
+		}
+	}
+	return nil, false
+}
+
+// release4 gives up the lease (the message is not acknowledged by the server).
+func (c *Client) release4(conn *conn4, lease *Lease4) {
+	release := c.newMessage4(MsgRelease, rand.Uint32(), time.Time{})
+	release.ciaddr = lease.Address
+	release.options[opt4ServerID] = lease.ServerID.To4()
+	c.log.Noticef("DHCP client: releasing %s from %s", lease.Address, c.config.IfName)
+	if err := conn.send(release.encode(), lease.Address, lease.ServerID); err != nil {
+		c.log.Warnf("DHCP client: failed to send RELEASE via %s: %v",
+			c.config.IfName, err)
+	}
+	c.removeLease(false)
+}
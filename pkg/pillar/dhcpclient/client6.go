@@ -0,0 +1,353 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package dhcpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// Retransmission parameters for DHCPv6 (RFC 8415, section 7.6).
+// SOL_MAX_RT is lowered from 3600s to obtain address sooner when DHCPv6 server
+// appears on the link.
+var (
+	solTimeout6 = time.Second
+	solMaxRT6   = 120 * time.Second
+	reqTimeout6 = time.Second
+	reqMaxRT6   = 30 * time.Second
+	reqMaxRC6   = 10
+	renTimeout6 = 10 * time.Second
+	renMaxRT6   = 600 * time.Second
+)
+
+// transaction6 : parameters of a DHCPv6 message exchange.
+type transaction6 struct {
+	msg       *message6
+	expected  MessageType6
+	initRT    time.Duration
+	maxRT     time.Duration
+	maxRC     int       // 0 = unlimited
+	deadline  time.Time // zero = no deadline
+	startedAt time.Time
+}
+
+// run6 runs the DHCPv6 client state machine until the context is canceled.
+func (c *Client) run6(ctx context.Context) {
+	defer c.wg.Done()
+	var conn *conn6
+	for {
+		var err error
+		conn, err = openConn6(c.config.IfName)
+		if err == nil {
+			break
+		}
+		c.log.Errorf("DHCP client: %s: %v", c.config.IfName, err)
+		if !sleep(ctx, openRetryInterval) {
+			return
+		}
+	}
+	defer conn.close()
+	iaid := iaidFromHwAddr(c.hwAddr)
+	lease := c.loadLease6()
+	if lease != nil && lease.IAID == iaid {
+		// Confirm the lease with any server (RFC 8415, section 18.2.5).
+		lease = c.confirm6(ctx, conn, lease)
+	} else {
+		lease = nil
+	}
+	for ctx.Err() == nil {
+		if lease == nil {
+			if lease = c.solicit6(ctx, conn, iaid); lease == nil {
+				break
+			}
+		}
+		if err := c.bind6(lease); err != nil {
+			c.log.Errorf("DHCP client: failed to apply IPv6 lease for %s: %v",
+				c.config.IfName, err)
+			if !sleep(ctx, openRetryInterval) {
+				break
+			}
+			continue
+		}
+		c.persistLease(true, lease)
+		c.log.Noticef("DHCP client: %s bound to %s (valid lifetime %v)",
+			c.config.IfName, lease.Address, lease.ValidLifetime)
+		lease = c.maintain6(ctx, conn, lease)
+		if lease == nil {
+			c.log.Warnf("DHCP client: lost IPv6 lease for %s", c.config.IfName)
+			c.unbind6()
+			c.removeLease(true)
+		}
+	}
+	if lease != nil {
+		c.release6(conn, lease)
+	}
+	c.unbind6()
+}
+
+// newMessage6 prepares a client message with options common to all message types.
+func (c *Client) newMessage6(msgType MessageType6) *message6 {
+	msg := &message6{msgType: msgType, xid: rand.Uint32() & 0xffffff}
+	msg.options = append(msg.options, option6{code: opt6ClientID, value: duidLL(c.hwAddr)})
+	if msgType != MsgRelease6 {
+		msg.options = append(msg.options, oroOpt())
+	}
+	return msg
+}
+
+func iaForLease(iaid uint32, lease *Lease6) iaNA {
+	ia := iaNA{iaid: iaid}
+	if lease != nil {
+		ia.addrs = []iaAddr{{addr: lease.Address}}
+	}
+	return ia
+}
+
+// exchange6 (re)transmits the message until a reply of the expected type is
+// received, or the maximum retransmission count or deadline is reached
+// (RFC 8415, section 15). Returns nil reply if no valid reply was received.
+func (c *Client) exchange6(ctx context.Context, conn *conn6, tr transaction6) *message6 {
+	tr.startedAt = time.Now()
+	rt := withJitter(tr.initRT)
+	clientID, _ := tr.msg.option(opt6ClientID)
+	for attempt := 1; ctx.Err() == nil; attempt++ {
+		msg := *tr.msg
+		msg.options = append(append([]option6{}, tr.msg.options...),
+			elapsedTimeOpt(tr.startedAt))
+		c.log.Functionf("DHCP client: sending %s (xid %#x) via %s",
+			msg.msgType, msg.xid, c.config.IfName)
+		if err := conn.send(msg.encode()); err != nil {
+			// Link-local address may not be ready yet.
+			c.log.Functionf("DHCP client: failed to send %s via %s: %v",
+				msg.msgType, c.config.IfName, err)
+		}
+		waitUntil := time.Now().Add(rt)
+		if !tr.deadline.IsZero() && waitUntil.After(tr.deadline) {
+			waitUntil = tr.deadline
+		}
+		for ctx.Err() == nil {
+			recvDeadline := time.Now().Add(time.Second)
+			if recvDeadline.After(waitUntil) {
+				recvDeadline = waitUntil
+			}
+			data, err := conn.recv(recvDeadline)
+			if err != nil {
+				if !errors.Is(err, errRecvTimeout) {
+					c.log.Errorf("DHCP client: %s: %v", c.config.IfName, err)
+					sleep(ctx, time.Until(recvDeadline))
+				}
+				if !time.Now().Before(waitUntil) {
+					break
+				}
+				continue
+			}
+			reply, err := decodeMessage6(data)
+			if err != nil {
+				c.log.Functionf("DHCP client: ignoring invalid message: %v", err)
+				continue
+			}
+			replyClientID, _ := reply.option(opt6ClientID)
+			if reply.xid != msg.xid || reply.msgType != tr.expected ||
+				!bytes.Equal(replyClientID, clientID) {
+				continue
+			}
+			c.log.Functionf("DHCP client: received %s (xid %#x) via %s",
+				reply.msgType, reply.xid, c.config.IfName)
+			return reply
+		}
+		if tr.maxRC > 0 && attempt >= tr.maxRC {
+			return nil
+		}
+		if !tr.deadline.IsZero() && !time.Now().Before(tr.deadline) {
+			return nil
+		}
+		if rt = 2 * rt; rt > tr.maxRT {
+			rt = withJitter(tr.maxRT)
+		}
+	}
+	return nil
+}
+
+// solicit6 obtains a new lease from a DHCPv6 server.
+// Returns nil if the context was canceled.
+func (c *Client) solicit6(ctx context.Context, conn *conn6, iaid uint32) *Lease6 {
+	for ctx.Err() == nil {
+		solicit := c.newMessage6(MsgSolicit)
+		solicit.options = append(solicit.options, iaForLease(iaid, nil).option())
+		advertise := c.exchange6(ctx, conn, transaction6{
+			msg:      solicit,
+			expected: MsgAdvertise,
+			initRT:   solTimeout6,
+			maxRT:    solMaxRT6,
+		})
+		if advertise == nil {
+			continue
+		}
+		serverID, ok := advertise.option(opt6ServerID)
+		if !ok {
+			continue
+		}
+		// Use ADVERTISE parser to validate that an address is offered.
+		offer, err := leaseFromReply(advertise, iaid, time.Now())
+		if err != nil {
+			c.log.Functionf("DHCP client: ignoring ADVERTISE: %v", err)
+			sleep(ctx, solTimeout6)
+			continue
+		}
+		request := c.newMessage6(MsgRequest6)
+		request.options = append(request.options,
+			option6{code: opt6ServerID, value: serverID},
+			iaForLease(iaid, offer).option())
+		sentAt := time.Now()
+		reply := c.exchange6(ctx, conn, transaction6{
+			msg:      request,
+			expected: MsgReply,
+			initRT:   reqTimeout6,
+			maxRT:    reqMaxRT6,
+			maxRC:    reqMaxRC6,
+		})
+		if reply == nil {
+			continue
+		}
+		lease, err := leaseFromReply(reply, iaid, sentAt)
+		if err != nil {
+			c.log.Warnf("DHCP client: REQUEST via %s failed: %v", c.config.IfName, err)
+			continue
+		}
+		return lease
+	}
+	return nil
+}
+
+// confirm6 tries to extend the persisted lease using REBIND.
+// If no server responds, the persisted lease is used until it expires.
+func (c *Client) confirm6(ctx context.Context, conn *conn6, lease *Lease6) *Lease6 {
+	rebind := c.newMessage6(MsgRebind)
+	rebind.options = append(rebind.options, iaForLease(lease.IAID, lease).option())
+	sentAt := time.Now()
+	reply := c.exchange6(ctx, conn, transaction6{
+		msg:      rebind,
+		expected: MsgReply,
+		initRT:   reqTimeout6,
+		maxRT:    reqMaxRT6,
+		maxRC:    4,
+		deadline: lease.Expiry(),
+	})
+	if ctx.Err() != nil {
+		return nil
+	}
+	if reply == nil {
+		if time.Now().Before(lease.Expiry()) {
+			c.log.Noticef("DHCP client: no response to REBIND, using persisted "+
+				"lease for %s (%s)", c.config.IfName, lease.Address)
+			return lease
+		}
+		return nil
+	}
+	newLease, err := leaseFromReply(reply, lease.IAID, sentAt)
+	if err != nil {
+		c.log.Noticef("DHCP client: persisted lease for %s (%s) was rejected: %v",
+			c.config.IfName, lease.Address, err)
+		c.removeLease(true)
+		return nil
+	}
+	return newLease
+}
+
+// maintain6 renews and rebinds the lease.
+// Returns nil when the lease expires or is rejected by the server.
+// Returns the current lease when the context is canceled.
+func (c *Client) maintain6(ctx context.Context, conn *conn6, lease *Lease6) *Lease6 {
+	for {
+		if lease.T1 >= InfinityLifetime {
+			<-ctx.Done()
+			return lease
+		}
+		if !sleep(ctx, time.Until(lease.AcquiredAt.Add(lease.T1))) {
+			return lease
+		}
+		newLease := c.extend6(ctx, conn, lease)
+		if ctx.Err() != nil {
+			return lease
+		}
+		if newLease == nil {
+			return nil
+		}
+		if err := c.bind6(newLease); err != nil {
+			c.log.Errorf("DHCP client: failed to apply renewed IPv6 lease for %s: %v",
+				c.config.IfName, err)
+		}
+		c.persistLease(true, newLease)
+		c.log.Functionf("DHCP client: IPv6 lease for %s (%s) extended by %v",
+			c.config.IfName, newLease.Address, newLease.ValidLifetime)
+		lease = newLease
+	}
+}
+
+// extend6 sends RENEW to the original server until T2, then REBIND to any
+// server until the lease expires.
+func (c *Client) extend6(ctx context.Context, conn *conn6, lease *Lease6) *Lease6 {
+	t2 := lease.AcquiredAt.Add(lease.T2)
+	for _, msgType := range []MessageType6{MsgRenew, MsgRebind} {
+		deadline := t2
+		if msgType == MsgRebind {
+			deadline = lease.Expiry()
+		}
+		if !time.Now().Before(deadline) {
+			continue
+		}
+		msg := c.newMessage6(msgType)
+		if msgType == MsgRenew {
+			msg.options = append(msg.options,
+				option6{code: opt6ServerID, value: lease.ServerID})
+		}
+		msg.options = append(msg.options, iaForLease(lease.IAID, lease).option())
+		sentAt := time.Now()
+		reply := c.exchange6(ctx, conn, transaction6{
+			msg:      msg,
+			expected: MsgReply,
+			initRT:   renTimeout6,
+			maxRT:    renMaxRT6,
+			deadline: deadline,
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		if reply == nil {
+			continue
+		}
+		newLease, err := leaseFromReply(reply, lease.IAID, sentAt)
+		if err != nil {
+			var statusErr *statusError6
+			if errors.As(err, &statusErr) && statusErr.code == status6NoBinding {
+				c.log.Warnf("DHCP client: server has no binding for %s (%s)",
+					c.config.IfName, lease.Address)
+			} else {
+				c.log.Warnf("DHCP client: %s via %s failed: %v",
+					msgType, c.config.IfName, err)
+			}
+			return nil
+		}
+		return newLease
+	}
+	return nil
+}
+
+// release6 gives up the lease (reply from the server is not awaited).
+func (c *Client) release6(conn *conn6, lease *Lease6) {
+	release := c.newMessage6(MsgRelease6)
+	release.options = append(release.options,
+		option6{code: opt6ServerID, value: lease.ServerID},
+		iaForLease(lease.IAID, lease).option(),
+		elapsedTimeOpt(time.Now()))
+	c.log.Noticef("DHCP client: releasing %s from %s", lease.Address, c.config.IfName)
+	if err := conn.send(release.encode()); err != nil {
+		c.log.Warnf("DHCP client: failed to send RELEASE via %s: %v",
+			c.config.IfName, err)
+	}
+	c.removeLease(true)
+}
@@ -0,0 +1,454 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package dhcpclient
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+
+	"github.com/lf-edge/eve/pkg/pillar/base"
+)
+
+const (
+	clientIfName = "dhcpc-test"
+	serverIfName = "dhcps-test"
+)
+
+var (
+	serverIPv4   = net.ParseIP("192.168.77.1")
+	leaseIPv4    = &net.IPNet{IP: net.ParseIP("192.168.77.10"), Mask: net.CIDRMask(24, 32)}
+	serverIPv6   = net.ParseIP("fd77::1")
+	leaseIPv6    = net.ParseIP("fd77::10")
+	staticRoutes = []Route{
+		{
+			Dst:     &net.IPNet{IP: net.ParseIP("10.77.0.0"), Mask: net.CIDRMask(16, 32)},
+			Gateway: serverIPv4,
+		},
+		{
+			Dst:     &net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)},
+			Gateway: serverIPv4,
+		},
+	}
+)
+
+type testEnv struct {
+	t       *GomegaWithT
+	log     *base.LogObject
+	tmpDir  string
+	server  *FakeServer
+	origNs  int
+	testNs  int
+	cleanup []func()
+}
+
+func writeSysctl(path, value string) error {
+	return ioutil.WriteFile(path, []byte(value), 0644)
+}
+
+// runInNs executes the function with the current thread switched into
+// the given network namespace.
+func (env *testEnv) runInNs(ns int, fn func() error) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	if err := unix.Setns(ns, unix.CLONE_NEWNET); err != nil {
+		return err
+	}
+	defer unix.Setns(env.origNs, unix.CLONE_NEWNET)
+	return fn()
+}
+
+// setupEnv creates veth pair with the server side moved into a separate
+// network namespace, where the fake server is started.
+func setupEnv(test *testing.T, serverConfig FakeServerConfig) *testEnv {
+	if os.Geteuid() != 0 {
+		test.Skip("test requires root privileges")
+	}
+	// Shorten retransmission timeouts.
+	retransmitInit4 = 500 * time.Millisecond
+	retransmitMax4 = 2 * time.Second
+	minRenewRetry = 500 * time.Millisecond
+	solTimeout6 = 200 * time.Millisecond
+	solMaxRT6 = 2 * time.Second
+	reqTimeout6 = 200 * time.Millisecond
+	reqMaxRT6 = 2 * time.Second
+	renTimeout6 = 500 * time.Millisecond
+	renMaxRT6 = 2 * time.Second
+
+	env := &testEnv{t: NewGomegaWithT(test)}
+	env.t.SetDefaultEventuallyTimeout(15 * time.Second)
+	env.log = base.NewSourceLogObject(logrus.StandardLogger(), "dhcpclient-test", 1234)
+	tmpDir, err := ioutil.TempDir("", "dhcpclient-test")
+	env.t.Expect(err).ToNot(HaveOccurred())
+	env.tmpDir = tmpDir
+	env.cleanup = append(env.cleanup, func() { os.RemoveAll(tmpDir) })
+
+	veth := &netlink.Veth{
+		LinkAttrs: netlink.LinkAttrs{Name: clientIfName},
+		PeerName:  serverIfName,
+	}
+	_ = netlink.LinkDel(veth)
+	if err = netlink.LinkAdd(veth); err != nil {
+		env.teardown()
+		test.Skipf("failed to create veth pair: %v", err)
+	}
+	env.cleanup = append(env.cleanup, func() { _ = netlink.LinkDel(veth) })
+
+	// Create a new network namespace for the server.
+	runtime.LockOSThread()
+	env.origNs, err = unix.Open("/proc/thread-self/ns/net", unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err == nil {
+		if err = unix.Unshare(unix.CLONE_NEWNET); err == nil {
+			env.testNs, err = unix.Open("/proc/thread-self/ns/net",
+				unix.O_RDONLY|unix.O_CLOEXEC, 0)
+			_ = unix.Setns(env.origNs, unix.CLONE_NEWNET)
+		}
+	}
+	runtime.UnlockOSThread()
+	if err != nil {
+		env.teardown()
+		test.Skipf("failed to create network namespace: %v", err)
+	}
+	env.cleanup = append(env.cleanup, func() {
+		unix.Close(env.testNs)
+		unix.Close(env.origNs)
+	})
+	peer, err := netlink.LinkByName(serverIfName)
+	env.t.Expect(err).ToNot(HaveOccurred())
+	env.t.Expect(netlink.LinkSetNsFd(peer, env.testNs)).To(Succeed())
+
+	// Setup the client side.
+	sysctlPrefix := "/proc/sys/net/ipv6/conf/" + clientIfName
+	_ = writeSysctl(sysctlPrefix+"/accept_dad", "0")
+	link, err := netlink.LinkByName(clientIfName)
+	env.t.Expect(err).ToNot(HaveOccurred())
+	env.t.Expect(netlink.LinkSetUp(link)).To(Succeed())
+
+	// Setup the server side and start the server.
+	serverConfig.IfName = serverIfName
+	err = env.runInNs(env.testNs, func() error {
+		sysctlPrefix := "/proc/sys/net/ipv6/conf/" + serverIfName
+		_ = writeSysctl(sysctlPrefix+"/accept_dad", "0")
+		link, err := netlink.LinkByName(serverIfName)
+		if err != nil {
+			return err
+		}
+		if err = netlink.LinkSetUp(link); err != nil {
+			return err
+		}
+		addr4 := &netlink.Addr{IPNet: &net.IPNet{IP: serverIPv4, Mask: leaseIPv4.Mask}}
+		if err = netlink.AddrAdd(link, addr4); err != nil {
+			return err
+		}
+		addr6 := &netlink.Addr{IPNet: &net.IPNet{IP: serverIPv6, Mask: net.CIDRMask(64, 128)},
+			Flags: unix.IFA_F_NODAD}
+		if err = netlink.AddrAdd(link, addr6); err != nil {
+			return err
+		}
+		// Wait for the link-local address.
+		for i := 0; i < 50; i++ {
+			addrs, _ := netlink.AddrList(link, netlink.FAMILY_V6)
+			for _, addr := range addrs {
+				if addr.IP.IsLinkLocalUnicast() && addr.Flags&unix.IFA_F_TENTATIVE == 0 {
+					env.server, err = NewFakeServer(serverConfig)
+					return err
+				}
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		return fmt.Errorf("link-local address is not ready")
+	})
+	env.t.Expect(err).ToNot(HaveOccurred())
+	env.cleanup = append(env.cleanup, env.server.Stop)
+	return env
+}
+
+func (env *testEnv) teardown() {
+	for i := len(env.cleanup) - 1; i >= 0; i-- {
+		env.cleanup[i]()
+	}
+}
+
+func (env *testEnv) newClient(ipv4, ipv6 bool) *Client {
+	return NewClient(Config{
+		Log:        env.log,
+		IfName:     clientIfName,
+		IPv4:       ipv4,
+		IPv6:       ipv6,
+		Hostname:   "test-device",
+		LeaseDir:   filepath.Join(env.tmpDir, "leases"),
+		InfoDir:    filepath.Join(env.tmpDir, "info"),
+		ResolvConf: filepath.Join(env.tmpDir, "resolv.conf", clientIfName+".dhcp"),
+	})
+}
+
+func (env *testEnv) hasAddress(ip net.IP) func() bool {
+	return func() bool {
+		link, err := netlink.LinkByName(clientIfName)
+		if err != nil {
+			return false
+		}
+		addrs, err := netlink.AddrList(link, netlink.FAMILY_ALL)
+		if err != nil {
+			return false
+		}
+		for _, addr := range addrs {
+			if addr.IP.Equal(ip) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func (env *testEnv) routes() []netlink.Route {
+	link, err := netlink.LinkByName(clientIfName)
+	env.t.Expect(err).ToNot(HaveOccurred())
+	routes, err := netlink.RouteList(link, netlink.FAMILY_V4)
+	env.t.Expect(err).ToNot(HaveOccurred())
+	return routes
+}
+
+func (env *testEnv) hasRoute(dst string, gw net.IP) bool {
+	for _, route := range env.routes() {
+		routeDst := "default"
+		if route.Dst != nil {
+			routeDst = route.Dst.String()
+		}
+		if routeDst == dst && route.Gw.Equal(gw) && route.Protocol == rtProtoDHCP {
+			return true
+		}
+	}
+	return false
+}
+
+func countMsgs(msgs []string, msgType string) (count int) {
+	for _, msg := range msgs {
+		if msg == msgType {
+			count++
+		}
+	}
+	return count
+}
+
+func TestDHCPv4(test *testing.T) {
+	env := setupEnv(test, FakeServerConfig{
+		ServerIPv4:   serverIPv4,
+		LeaseIPv4:    leaseIPv4,
+		Routers:      []net.IP{serverIPv4},
+		DNSServers:   []net.IP{net.ParseIP("192.168.77.2"), net.ParseIP("192.168.77.3")},
+		NTPServers:   []net.IP{net.ParseIP("192.168.77.4")},
+		DomainName:   "test.local",
+		StaticRoutes: staticRoutes,
+		VendorInfo:   []byte{1, 2, 3},
+		WPADURL:      "http://wpad.test.local/wpad.dat",
+		LeaseTime:    6 * time.Second,
+	})
+	defer env.teardown()
+	t := env.t
+
+	client := env.newClient(true, false)
+	t.Expect(client.Start()).To(Succeed())
+	defer client.Stop()
+	t.Eventually(env.hasAddress(leaseIPv4.IP)).Should(BeTrue())
+	msgs := env.server.Received()
+	t.Expect(msgs[0]).To(Equal("DISCOVER"))
+	t.Expect(msgs).To(ContainElement("REQUEST"))
+
+	// Both classless static routes should be installed, router option ignored.
+	t.Expect(env.hasRoute("10.77.0.0/16", serverIPv4)).To(BeTrue())
+	t.Expect(env.hasRoute("default", serverIPv4)).To(BeTrue())
+
+	// Check the published info.
+	var info Info
+	t.Eventually(func() (err error) {
+		info, err = ReadInfo(InfoFile(filepath.Join(env.tmpDir, "info"), clientIfName))
+		return err
+	}).Should(Succeed())
+	t.Expect(info.IfName).To(Equal(clientIfName))
+	t.Expect(info.IPv4).ToNot(BeNil())
+	t.Expect(info.IPv4.Subnet().String()).To(Equal("192.168.77.0/24"))
+	t.Expect(info.IPv4.ServerID.Equal(serverIPv4)).To(BeTrue())
+	t.Expect(info.IPv4.NTPServers).To(HaveLen(1))
+	t.Expect(info.IPv4.NTPServers[0].String()).To(Equal("192.168.77.4"))
+	t.Expect(info.IPv4.DomainName).To(Equal("test.local"))
+	t.Expect(info.IPv4.StaticRoutes).To(HaveLen(2))
+	t.Expect(info.IPv4.VendorInfo).To(Equal([]byte{1, 2, 3}))
+	t.Expect(info.IPv4.WPADURL).To(Equal("http://wpad.test.local/wpad.dat"))
+	t.Expect(info.IPv4.LeaseTime).To(Equal(6 * time.Second))
+	t.Expect(info.IPv6).To(BeNil())
+
+	// Check the generated resolv.conf.
+	resolvConf, err := ioutil.ReadFile(client.config.ResolvConf)
+	t.Expect(err).ToNot(HaveOccurred())
+	t.Expect(string(resolvConf)).To(ContainSubstring("search test.local\n"))
+	t.Expect(string(resolvConf)).To(ContainSubstring("nameserver 192.168.77.2\n"))
+	t.Expect(string(resolvConf)).To(ContainSubstring("nameserver 192.168.77.3\n"))
+
+	// Lease is renewed at T1 (3 seconds).
+	env.server.ClearReceived()
+	t.Eventually(env.server.Received).Should(ContainElement("REQUEST"))
+	t.Expect(env.server.Received()).ToNot(ContainElement("DISCOVER"))
+	t.Consistently(env.hasAddress(leaseIPv4.IP), 2*time.Second).Should(BeTrue())
+
+	// Lease is persisted.
+	leaseFile := leaseFile(client.config.LeaseDir, clientIfName, false)
+	_, err = os.Stat(leaseFile)
+	t.Expect(err).ToNot(HaveOccurred())
+
+	// Server rejects renewal - client should remove the address and start
+	// from DISCOVER again.
+	env.server.ClearReceived()
+	env.server.SetNak(true)
+	t.Eventually(env.hasAddress(leaseIPv4.IP)).Should(BeFalse())
+	env.server.SetNak(false)
+	t.Eventually(env.hasAddress(leaseIPv4.IP)).Should(BeTrue())
+	t.Expect(env.server.Received()).To(ContainElement("DISCOVER"))
+
+	// Stop releases the lease and removes the config.
+	env.server.ClearReceived()
+	t.Expect(client.Stop()).To(Succeed())
+	t.Eventually(env.server.Received).Should(ContainElement("RELEASE"))
+	t.Expect(env.hasAddress(leaseIPv4.IP)()).To(BeFalse())
+	t.Expect(env.hasRoute("10.77.0.0/16", serverIPv4)).To(BeFalse())
+	t.Expect(env.hasRoute("default", serverIPv4)).To(BeFalse())
+	_, err = os.Stat(leaseFile)
+	t.Expect(os.IsNotExist(err)).To(BeTrue())
+	_, err = os.Stat(client.config.ResolvConf)
+	t.Expect(os.IsNotExist(err)).To(BeTrue())
+	_, err = os.Stat(InfoFile(filepath.Join(env.tmpDir, "info"), clientIfName))
+	t.Expect(os.IsNotExist(err)).To(BeTrue())
+}
+
+func TestDHCPv4InitReboot(test *testing.T) {
+	env := setupEnv(test, FakeServerConfig{
+		ServerIPv4: serverIPv4,
+		LeaseIPv4:  leaseIPv4,
+		Routers:    []net.IP{serverIPv4},
+		LeaseTime:  time.Hour,
+	})
+	defer env.teardown()
+	t := env.t
+
+	// Persist lease as if it was obtained before reboot.
+	client := env.newClient(true, false)
+	lease := &Lease4{
+		Address:    leaseIPv4.IP,
+		Mask:       leaseIPv4.Mask,
+		ServerID:   serverIPv4,
+		LeaseTime:  time.Hour,
+		AcquiredAt: time.Now().Add(-time.Minute),
+	}
+	lease.fillTimers()
+	client.persistLease(false, lease)
+
+	t.Expect(client.Start()).To(Succeed())
+	defer client.Stop()
+	t.Eventually(env.hasAddress(leaseIPv4.IP)).Should(BeTrue())
+	msgs := env.server.Received()
+	t.Expect(msgs).To(ContainElement("REQUEST"))
+	t.Expect(msgs).ToNot(ContainElement("DISCOVER"))
+	// Router option is used when classless routes are not provided.
+	t.Expect(env.hasRoute("default", serverIPv4)).To(BeTrue())
+	t.Expect(client.Stop()).To(Succeed())
+	t.Expect(env.hasAddress(leaseIPv4.IP)()).To(BeFalse())
+
+	// Persisted lease with an address not known to the server is rejected.
+	env.server.ClearReceived()
+	lease.Address = net.ParseIP("192.168.77.20")
+	client = env.newClient(true, false)
+	client.persistLease(false, lease)
+	t.Expect(client.Start()).To(Succeed())
+	defer client.Stop()
+	t.Eventually(env.hasAddress(leaseIPv4.IP)).Should(BeTrue())
+	msgs = env.server.Received()
+	t.Expect(countMsgs(msgs, "DISCOVER")).To(BeNumerically(">=", 1))
+	t.Expect(env.hasAddress(lease.Address)()).To(BeFalse())
+	t.Expect(client.Stop()).To(Succeed())
+}
+
+func TestStaticIPv4(test *testing.T) {
+	env := setupEnv(test, FakeServerConfig{})
+	defer env.teardown()
+	t := env.t
+
+	client := NewClient(Config{
+		Log:    env.log,
+		IfName: clientIfName,
+		IPv4:   true,
+		StaticIPv4: &Lease4{
+			Address:    net.ParseIP("192.168.77.30"),
+			Mask:       leaseIPv4.Mask,
+			Routers:    []net.IP{serverIPv4},
+			DNSServers: []net.IP{serverIPv4},
+		},
+		InfoDir:    filepath.Join(env.tmpDir, "info"),
+		ResolvConf: filepath.Join(env.tmpDir, "resolv.conf", clientIfName+".dhcp"),
+	})
+	t.Expect(client.Start()).To(Succeed())
+	defer client.Stop()
+	t.Expect(env.hasAddress(net.ParseIP("192.168.77.30"))()).To(BeTrue())
+	t.Expect(env.hasRoute("default", serverIPv4)).To(BeTrue())
+	info := client.GetInfo()
+	t.Expect(info.IPv4).ToNot(BeNil())
+	t.Expect(info.IPv4.IsStatic()).To(BeTrue())
+	resolvConf, err := ioutil.ReadFile(client.config.ResolvConf)
+	t.Expect(err).ToNot(HaveOccurred())
+	t.Expect(string(resolvConf)).To(ContainSubstring("nameserver 192.168.77.1\n"))
+	t.Expect(client.Stop()).To(Succeed())
+	t.Expect(env.hasAddress(net.ParseIP("192.168.77.30"))()).To(BeFalse())
+	t.Expect(env.hasRoute("default", serverIPv4)).To(BeFalse())
+}
+
+func TestDHCPv6(test *testing.T) {
+	env := setupEnv(test, FakeServerConfig{
+		LeaseIPv6:         leaseIPv6,
+		DNSServersV6:      []net.IP{net.ParseIP("fd77::2")},
+		NTPServersV6:      []net.IP{net.ParseIP("fd77::3")},
+		DomainSearch:      []string{"test6.local"},
+		PreferredLifetime: 4 * time.Second,
+		ValidLifetime:     time.Minute,
+	})
+	defer env.teardown()
+	t := env.t
+
+	client := env.newClient(false, true)
+	t.Expect(client.Start()).To(Succeed())
+	defer client.Stop()
+	t.Eventually(env.hasAddress(leaseIPv6)).Should(BeTrue())
+	msgs := env.server.Received()
+	t.Expect(msgs).To(ContainElement("SOLICIT"))
+	t.Expect(msgs).To(ContainElement("REQUEST"))
+
+	info := client.GetInfo()
+	t.Expect(info.IPv6).ToNot(BeNil())
+	t.Expect(info.IPv6.NTPServers).To(HaveLen(1))
+	t.Expect(info.IPv6.NTPServers[0].String()).To(Equal("fd77::3"))
+	t.Expect(info.IPv6.DomainSearch).To(Equal([]string{"test6.local"}))
+	resolvConf, err := ioutil.ReadFile(client.config.ResolvConf)
+	t.Expect(err).ToNot(HaveOccurred())
+	t.Expect(strings.Split(string(resolvConf), "\n")).To(ContainElements(
+		"search test6.local", "nameserver fd77::2"))
+
+	// Lease is renewed at T1 (2 seconds).
+	env.server.ClearReceived()
+	t.Eventually(env.server.Received).Should(ContainElement("RENEW"))
+	t.Expect(env.server.Received()).ToNot(ContainElement("SOLICIT"))
+
+	env.server.ClearReceived()
+	t.Expect(client.Stop()).To(Succeed())
+	t.Eventually(env.server.Received).Should(ContainElement("RELEASE"))
+	t.Expect(env.hasAddress(leaseIPv6)()).To(BeFalse())
+}
@@ -0,0 +1,338 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package dhcpclient
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// UDP ports used by DHCPv4.
+const (
+	ServerPortV4 = 67
+	ClientPortV4 = 68
+)
+
+// BOOTP operation codes.
+const (
+	bootRequest = 1
+	bootReply   = 2
+)
+
+// Fixed part of the DHCPv4 message (RFC 2131, section 2), without options.
+const (
+	dhcp4FixedLen     = 236
+	dhcp4BroadcastBit = 0x8000
+	dhcp4HwTypeEth    = 1
+)
+
+var dhcp4MagicCookie = []byte{99, 130, 83, 99}
+
+// MessageType4 : type of DHCPv4 message (option 53).
+type MessageType4 uint8
+
+// DHCPv4 message types (RFC 2132, section 9.6).
+const (
+	MsgDiscover MessageType4 = 1
+	MsgOffer    MessageType4 = 2
+	MsgRequest  MessageType4 = 3
+	MsgDecline  MessageType4 = 4
+	MsgAck      MessageType4 = 5
+	MsgNak      MessageType4 = 6
+	MsgRelease  MessageType4 = 7
+	MsgInform   MessageType4 = 8
+)
+
+// String returns the name of the message type.
+func (t MessageType4) String() string {
+	switch t {
+	case MsgDiscover:
+		return "DISCOVER"
+	case MsgOffer:
+		return "OFFER"
+	case MsgRequest:
+		return "REQUEST"
+	case MsgDecline:
+		return "DECLINE"
+	case MsgAck:
+		return "ACK"
+	case MsgNak:
+		return "NAK"
+	case MsgRelease:
+		return "RELEASE"
+	case MsgInform:
+		return "INFORM"
+	default:
+		return fmt.Sprintf("Unknown message type %d", t)
+	}
+}
+
+// DHCPv4 options used by the client (RFC 2132, RFC 3442).
+const (
+	opt4Pad              = 0
+	opt4SubnetMask       = 1
+	opt4Router           = 3
+	opt4DNSServers       = 6
+	opt4Hostname         = 12
+	opt4DomainName       = 15
+	opt4InterfaceMTU     = 26
+	opt4NTPServers       = 42
+	opt4VendorSpecific   = 43
+	opt4RequestedIP      = 50
+	opt4LeaseTime        = 51
+	opt4MessageType      = 53
+	opt4ServerID         = 54
+	opt4ParamRequestList = 55
+	opt4Message          = 56
+	opt4MaxMessageSize   = 57
+	opt4RenewalTime      = 58
+	opt4RebindingTime    = 59
+	opt4ClientID         = 61
+	opt4ClasslessRoutes  = 121
+	opt4WPAD             = 252
+	opt4End              = 255
+)
+
+// Options requested from DHCPv4 servers.
+var requestedOptions4 = []byte{
+	opt4SubnetMask, opt4Router, opt4DNSServers, opt4Hostname, opt4DomainName,
+	opt4InterfaceMTU, opt4NTPServers, opt4VendorSpecific, opt4ClasslessRoutes,
+	opt4WPAD,
+}
+
+// message4 : DHCPv4 message.
+// Only the fields of the fixed BOOTP header used by DHCP are represented.
+type message4 struct {
+	op      uint8
+	xid     uint32
+	secs    uint16
+	flags   uint16
+	ciaddr  net.IP
+	yiaddr  net.IP
+	siaddr  net.IP
+	giaddr  net.IP
+	chaddr  net.HardwareAddr
+	options options4
+}
+
+// options4 : DHCPv4 options (key = option code).
+type options4 map[uint8][]byte
+
+func (m *message4) msgType() MessageType4 {
+	if value := m.options[opt4MessageType]; len(value) == 1 {
+		return MessageType4(value[0])
+	}
+	return 0
+}
+
+func (m *message4) encode() []byte {
+	buf := make([]byte, dhcp4FixedLen, 300)
+	buf[0] = m.op
+	buf[1] = dhcp4HwTypeEth
+	buf[2] = uint8(len(m.chaddr))
+	binary.BigEndian.PutUint32(buf[4:], m.xid)
+	binary.BigEndian.PutUint16(buf[8:], m.secs)
+	binary.BigEndian.PutUint16(buf[10:], m.flags)
+	copy(buf[12:16], m.ciaddr.To4())
+	copy(buf[16:20], m.yiaddr.To4())
+	copy(buf[20:24], m.siaddr.To4())
+	copy(buf[24:28], m.giaddr.To4())
+	copy(buf[28:44], m.chaddr)
+	buf = append(buf, dhcp4MagicCookie...)
+	// Message type goes first (not required, but expected by some servers).
+	if value, ok := m.options[opt4MessageType]; ok {
+		buf = append(buf, opt4MessageType, uint8(len(value)))
+		buf = append(buf, value...)
+	}
+	for code := 1; code < opt4End; code++ {
+		value, ok := m.options[uint8(code)]
+		if !ok || code == opt4MessageType {
+			continue
+		}
+		// Long options are split into multiple instances (RFC 3396).
+		for {
+			chunk := value
+			if len(chunk) > 255 {
+				chunk = chunk[:255]
+			}
+			buf = append(buf, uint8(code), uint8(len(chunk)))
+			buf = append(buf, chunk...)
+			value = value[len(chunk):]
+			if len(value) == 0 {
+				break
+			}
+		}
+	}
+	buf = append(buf, opt4End)
+	// Pad to the minimal BOOTP message size.
+	for len(buf) < 300 {
+		buf = append(buf, opt4Pad)
+	}
+	return buf
+}
+
+func decodeMessage4(data []byte) (*message4, error) {
+	if len(data) < dhcp4FixedLen+len(dhcp4MagicCookie) {
+		return nil, fmt.Errorf("DHCPv4 message too short (%d bytes)", len(data))
+	}
+	if !bytes.Equal(data[dhcp4FixedLen:dhcp4FixedLen+4], dhcp4MagicCookie) {
+		return nil, errors.New("DHCPv4 message without magic cookie")
+	}
+	hwLen := int(data[2])
+	if hwLen > 16 {
+		return nil, fmt.Errorf("invalid hardware address length %d", hwLen)
+	}
+	m := &message4{
+		op:      data[0],
+		xid:     binary.BigEndian.Uint32(data[4:]),
+		secs:    binary.BigEndian.Uint16(data[8:]),
+		flags:   binary.BigEndian.Uint16(data[10:]),
+		ciaddr:  net.IP(append([]byte{}, data[12:16]...)),
+		yiaddr:  net.IP(append([]byte{}, data[16:20]...)),
+		siaddr:  net.IP(append([]byte{}, data[20:24]...)),
+		giaddr:  net.IP(append([]byte{}, data[24:28]...)),
+		chaddr:  net.HardwareAddr(append([]byte{}, data[28:28+hwLen]...)),
+		options: make(options4),
+	}
+	opts := data[dhcp4FixedLen+4:]
+	for len(opts) > 0 {
+		code := opts[0]
+		if code == opt4End {
+			break
+		}
+		if code == opt4Pad {
+			opts = opts[1:]
+			continue
+		}
+		if len(opts) < 2 || len(opts) < 2+int(opts[1]) {
+			return nil, fmt.Errorf("truncated DHCPv4 option %d", code)
+		}
+		length := int(opts[1])
+		// Multiple instances of the same option are concatenated (RFC 3396).
+		m.options[code] = append(m.options[code], opts[2:2+length]...)
+		opts = opts[2+length:]
+	}
+	return m, nil
+}
+
+// Parsing of option values.
+
+func optIP4(value []byte) net.IP {
+	if len(value) != net.IPv4len {
+		return nil
+	}
+	return net.IPv4(value[0], value[1], value[2], value[3])
+}
+
+func optIP4List(value []byte) (ips []net.IP) {
+	for len(value) >= net.IPv4len {
+		ips = append(ips, optIP4(value[:net.IPv4len]))
+		value = value[net.IPv4len:]
+	}
+	return ips
+}
+
+func optDuration(value []byte) time.Duration {
+	if len(value) != 4 {
+		return 0
+	}
+	return time.Duration(binary.BigEndian.Uint32(value)) * time.Second
+}
+
+func optString(value []byte) string {
+	// Some servers include the terminating NUL character.
+	return strings.TrimRight(string(value), "\x00")
+}
+
+// parseClasslessRoutes parses option 121 (RFC 3442).
+func parseClasslessRoutes(value []byte) ([]Route, error) {
+	var routes []Route
+	for len(value) > 0 {
+		prefixLen := int(value[0])
+		if prefixLen > 32 {
+			return nil, fmt.Errorf("invalid prefix length %d", prefixLen)
+		}
+		dstLen := (prefixLen + 7) / 8
+		if len(value) < 1+dstLen+net.IPv4len {
+			return nil, errors.New("truncated classless static route")
+		}
+		dst := make([]byte, net.IPv4len)
+		copy(dst, value[1:1+dstLen])
+		gw := optIP4(value[1+dstLen : 1+dstLen+net.IPv4len])
+		routes = append(routes, Route{
+			Dst: &net.IPNet{
+				IP:   net.IP(dst).To16(),
+				Mask: net.CIDRMask(prefixLen, 32),
+			},
+			Gateway: gw,
+		})
+		value = value[1+dstLen+net.IPv4len:]
+	}
+	return routes, nil
+}
+
+// encodeClasslessRoutes encodes routes for option 121 (RFC 3442).
+func encodeClasslessRoutes(routes []Route) (value []byte) {
+	for _, route := range routes {
+		prefixLen, _ := route.Dst.Mask.Size()
+		dstLen := (prefixLen + 7) / 8
+		value = append(value, uint8(prefixLen))
+		value = append(value, route.Dst.IP.To4()[:dstLen]...)
+		value = append(value, route.Gateway.To4()...)
+	}
+	return value
+}
+
+// leaseFromAck builds Lease4 from DHCPACK.
+func leaseFromAck(ack *message4, acquiredAt time.Time) (*Lease4, error) {
+	addr := ack.yiaddr.To4()
+	if addr == nil || addr.IsUnspecified() {
+		return nil, errors.New("DHCPACK without IP address")
+	}
+	opts := ack.options
+	mask := net.IPMask(opts[opt4SubnetMask])
+	if len(mask) != net.IPv4len {
+		// Guess the mask from the address class.
+		mask = addr.DefaultMask()
+	}
+	lease := &Lease4{
+		Address:    net.IPv4(addr[0], addr[1], addr[2], addr[3]),
+		Mask:       mask,
+		ServerID:   optIP4(opts[opt4ServerID]),
+		Routers:    optIP4List(opts[opt4Router]),
+		DNSServers: optIP4List(opts[opt4DNSServers]),
+		NTPServers: optIP4List(opts[opt4NTPServers]),
+		DomainName: optString(opts[opt4DomainName]),
+		Hostname:   optString(opts[opt4Hostname]),
+		WPADURL:    optString(opts[opt4WPAD]),
+		LeaseTime:  optDuration(opts[opt4LeaseTime]),
+		T1:         optDuration(opts[opt4RenewalTime]),
+		T2:         optDuration(opts[opt4RebindingTime]),
+		AcquiredAt: acquiredAt,
+	}
+	if value := opts[opt4VendorSpecific]; len(value) > 0 {
+		lease.VendorInfo = append([]byte{}, value...)
+	}
+	if value := opts[opt4InterfaceMTU]; len(value) == 2 {
+		lease.MTU = binary.BigEndian.Uint16(value)
+	}
+	if value := opts[opt4ClasslessRoutes]; len(value) > 0 {
+		routes, err := parseClasslessRoutes(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse classless static routes: %w", err)
+		}
+		lease.StaticRoutes = routes
+	}
+	if lease.ServerID == nil {
+		// ServerID is required by RFC 2131.
+		return nil, errors.New("DHCPACK without server identifier")
+	}
+	lease.fillTimers()
+	return lease, nil
+}
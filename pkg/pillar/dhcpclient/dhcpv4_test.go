@@ -0,0 +1,130 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package dhcpclient
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMessage4EncodeDecode(test *testing.T) {
+	t := NewGomegaWithT(test)
+	hwAddr, _ := net.ParseMAC("02:00:00:00:00:01")
+	msg := &message4{
+		op:      bootRequest,
+		xid:     0x12345678,
+		secs:    3,
+		flags:   dhcp4BroadcastBit,
+		ciaddr:  net.IPv4zero,
+		yiaddr:  net.IPv4zero,
+		siaddr:  net.IPv4zero,
+		giaddr:  net.IPv4zero,
+		chaddr:  hwAddr,
+		options: make(options4),
+	}
+	msg.options[opt4MessageType] = []byte{uint8(MsgDiscover)}
+	msg.options[opt4ParamRequestList] = requestedOptions4
+	// Option longer than 255 bytes must be split.
+	msg.options[opt4VendorSpecific] = bytes.Repeat([]byte{0xab}, 300)
+	data := msg.encode()
+	// Message type is expected to be the first option.
+	t.Expect(data[dhcp4FixedLen+4 : dhcp4FixedLen+7]).To(Equal(
+		[]byte{opt4MessageType, 1, uint8(MsgDiscover)}))
+
+	decoded, err := decodeMessage4(data)
+	t.Expect(err).ToNot(HaveOccurred())
+	t.Expect(decoded.op).To(BeEquivalentTo(bootRequest))
+	t.Expect(decoded.xid).To(BeEquivalentTo(0x12345678))
+	t.Expect(decoded.secs).To(BeEquivalentTo(3))
+	t.Expect(decoded.flags).To(BeEquivalentTo(dhcp4BroadcastBit))
+	t.Expect(decoded.chaddr).To(Equal(hwAddr))
+	t.Expect(decoded.msgType()).To(Equal(MsgDiscover))
+	t.Expect(decoded.options[opt4ParamRequestList]).To(Equal(requestedOptions4))
+	t.Expect(decoded.options[opt4VendorSpecific]).To(HaveLen(300))
+
+	_, err = decodeMessage4(data[:100])
+	t.Expect(err).To(HaveOccurred())
+	data[dhcp4FixedLen] = 0
+	_, err = decodeMessage4(data)
+	t.Expect(err).To(HaveOccurred())
+}
+
+func TestClasslessRoutes(test *testing.T) {
+	t := NewGomegaWithT(test)
+	routes := []Route{
+		{
+			Dst:     &net.IPNet{IP: net.ParseIP("10.0.0.0"), Mask: net.CIDRMask(8, 32)},
+			Gateway: net.ParseIP("192.168.1.1"),
+		},
+		{
+			Dst:     &net.IPNet{IP: net.ParseIP("172.16.12.0"), Mask: net.CIDRMask(22, 32)},
+			Gateway: net.IPv4zero,
+		},
+		{
+			Dst:     &net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)},
+			Gateway: net.ParseIP("192.168.1.254"),
+		},
+	}
+	value := encodeClasslessRoutes(routes)
+	// Example from RFC 3442: 10.0.0.0/8 encoded as 8.10 followed by gateway.
+	t.Expect(value[:6]).To(Equal([]byte{8, 10, 192, 168, 1, 1}))
+	parsed, err := parseClasslessRoutes(value)
+	t.Expect(err).ToNot(HaveOccurred())
+	t.Expect(parsed).To(HaveLen(3))
+	for i := range routes {
+		t.Expect(parsed[i].String()).To(Equal(routes[i].String()))
+	}
+
+	_, err = parseClasslessRoutes([]byte{33, 10, 0, 0, 0, 0})
+	t.Expect(err).To(HaveOccurred())
+	_, err = parseClasslessRoutes([]byte{24, 10, 0})
+	t.Expect(err).To(HaveOccurred())
+}
+
+func TestLeaseFromAck(test *testing.T) {
+	t := NewGomegaWithT(test)
+	ack := &message4{
+		op:      bootReply,
+		yiaddr:  net.ParseIP("192.168.1.10"),
+		options: make(options4),
+	}
+	ack.options[opt4MessageType] = []byte{uint8(MsgAck)}
+	now := time.Now()
+	_, err := leaseFromAck(ack, now)
+	t.Expect(err).To(HaveOccurred()) // missing server ID
+
+	ack.options[opt4ServerID] = []byte{192, 168, 1, 1}
+	ack.options[opt4SubnetMask] = []byte{255, 255, 255, 0}
+	ack.options[opt4Router] = []byte{192, 168, 1, 1}
+	ack.options[opt4DNSServers] = []byte{8, 8, 8, 8, 1, 1, 1, 1}
+	ack.options[opt4NTPServers] = []byte{192, 168, 1, 2}
+	ack.options[opt4DomainName] = []byte("example.com\x00")
+	ack.options[opt4LeaseTime] = []byte{0, 0, 0x0e, 0x10}
+	ack.options[opt4VendorSpecific] = []byte{1, 4, 't', 'e', 's', 't'}
+	ack.options[opt4WPAD] = []byte("http://wpad.example.com/wpad.dat\x00")
+	lease, err := leaseFromAck(ack, now)
+	t.Expect(err).ToNot(HaveOccurred())
+	t.Expect(lease.Subnet().String()).To(Equal("192.168.1.0/24"))
+	t.Expect(lease.IsStatic()).To(BeFalse())
+	t.Expect(lease.Routers).To(HaveLen(1))
+	t.Expect(lease.DNSServers).To(HaveLen(2))
+	t.Expect(lease.NTPServers[0].String()).To(Equal("192.168.1.2"))
+	t.Expect(lease.DomainName).To(Equal("example.com"))
+	t.Expect(lease.VendorInfo).To(Equal([]byte{1, 4, 't', 'e', 's', 't'}))
+	t.Expect(lease.WPADURL).To(Equal("http://wpad.example.com/wpad.dat"))
+	t.Expect(lease.LeaseTime).To(Equal(time.Hour))
+	t.Expect(lease.T1).To(Equal(30 * time.Minute))
+	t.Expect(lease.T2).To(Equal(52*time.Minute + 30*time.Second))
+	t.Expect(lease.Expiry()).To(Equal(now.Add(time.Hour)))
+
+	// Lease without lease time never expires.
+	delete(ack.options, opt4LeaseTime)
+	lease, err = leaseFromAck(ack, now)
+	t.Expect(err).ToNot(HaveOccurred())
+	t.Expect(lease.LeaseTime).To(Equal(InfinityLifetime))
+}
@@ -0,0 +1,426 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package dhcpclient
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// UDP ports used by DHCPv6.
+const (
+	ServerPortV6 = 547
+	ClientPortV6 = 546
+)
+
+// AllDHCPServersV6 : All_DHCP_Relay_Agents_and_Servers multicast address.
+var AllDHCPServersV6 = net.ParseIP("ff02::1:2")
+
+// MessageType6 : type of DHCPv6 message.
+type MessageType6 uint8
+
+// DHCPv6 message types (RFC 8415, section 7.3).
+const (
+	MsgSolicit   MessageType6 = 1
+	MsgAdvertise MessageType6 = 2
+	MsgRequest6  MessageType6 = 3
+	MsgConfirm   MessageType6 = 4
+	MsgRenew     MessageType6 = 5
+	MsgRebind    MessageType6 = 6
+	MsgReply     MessageType6 = 7
+	MsgRelease6  MessageType6 = 8
+	MsgDecline6  MessageType6 = 9
+)
+
+// String returns the name of the message type.
+func (t MessageType6) String() string {
+	switch t {
+	case MsgSolicit:
+		return "SOLICIT"
+	case MsgAdvertise:
+		return "ADVERTISE"
+	case MsgRequest6:
+		return "REQUEST"
+	case MsgConfirm:
+		return "CONFIRM"
+	case MsgRenew:
+		return "RENEW"
+	case MsgRebind:
+		return "REBIND"
+	case MsgReply:
+		return "REPLY"
+	case MsgRelease6:
+		return "RELEASE"
+	case MsgDecline6:
+		return "DECLINE"
+	default:
+		return fmt.Sprintf("Unknown message type %d", t)
+	}
+}
+
+// DHCPv6 options used by the client (RFC 8415, RFC 3646, RFC 5908).
+const (
+	opt6ClientID     = 1
+	opt6ServerID     = 2
+	opt6IANA         = 3
+	opt6IAAddr       = 5
+	opt6ORO          = 6
+	opt6ElapsedTime  = 8
+	opt6StatusCode   = 13
+	opt6DNSServers   = 23
+	opt6DomainList   = 24
+	opt6SNTPServers  = 31
+	opt6NTPServer    = 56
+	ntpSubOptSrvAddr = 1
+	ntpSubOptMcAddr  = 2
+)
+
+// DHCPv6 status codes (RFC 8415, section 21.13).
+const (
+	status6Success   = 0
+	status6NoAddrs   = 2
+	status6NoBinding = 3
+)
+
+// Options requested from DHCPv6 servers.
+var requestedOptions6 = []uint16{
+	opt6DNSServers, opt6DomainList, opt6NTPServer, opt6SNTPServers,
+}
+
+// option6 : DHCPv6 option.
+type option6 struct {
+	code  uint16
+	value []byte
+}
+
+// message6 : DHCPv6 client/server message.
+type message6 struct {
+	msgType MessageType6
+	xid     uint32 // only 24 bits are used
+	options []option6
+}
+
+func (m *message6) option(code uint16) ([]byte, bool) {
+	for _, opt := range m.options {
+		if opt.code == code {
+			return opt.value, true
+		}
+	}
+	return nil, false
+}
+
+func (m *message6) encode() []byte {
+	buf := []byte{uint8(m.msgType), uint8(m.xid >> 16), uint8(m.xid >> 8), uint8(m.xid)}
+	return appendOptions6(buf, m.options)
+}
+
+func appendOptions6(buf []byte, options []option6) []byte {
+	for _, opt := range options {
+		buf = appendU16(buf, opt.code)
+		buf = appendU16(buf, uint16(len(opt.value)))
+		buf = append(buf, opt.value...)
+	}
+	return buf
+}
+
+func decodeMessage6(data []byte) (*message6, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("DHCPv6 message too short (%d bytes)", len(data))
+	}
+	options, err := decodeOptions6(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	return &message6{
+		msgType: MessageType6(data[0]),
+		xid:     uint32(data[1])<<16 | uint32(data[2])<<8 | uint32(data[3]),
+		options: options,
+	}, nil
+}
+
+func decodeOptions6(data []byte) (options []option6, err error) {
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, errors.New("truncated DHCPv6 option header")
+		}
+		code := binary.BigEndian.Uint16(data)
+		length := int(binary.BigEndian.Uint16(data[2:]))
+		if len(data) < 4+length {
+			return nil, fmt.Errorf("truncated DHCPv6 option %d", code)
+		}
+		options = append(options, option6{
+			code:  code,
+			value: append([]byte{}, data[4:4+length]...),
+		})
+		data = data[4+length:]
+	}
+	return options, nil
+}
+
+func appendU16(buf []byte, value uint16) []byte {
+	return append(buf, uint8(value>>8), uint8(value))
+}
+
+func appendU32(buf []byte, value uint32) []byte {
+	return append(buf, uint8(value>>24), uint8(value>>16), uint8(value>>8), uint8(value))
+}
+
+// duidLL returns DUID based on the link-layer address (RFC 8415, section 11.4).
+func duidLL(hwAddr net.HardwareAddr) []byte {
+	duid := []byte{0, 3, 0, dhcp4HwTypeEth}
+	return append(duid, hwAddr...)
+}
+
+// iaid derived from the link-layer address (stable across reboots).
+func iaidFromHwAddr(hwAddr net.HardwareAddr) uint32 {
+	var iaid uint32
+	for _, b := range hwAddr {
+		iaid = iaid<<8 | uint32(b)
+	}
+	return iaid
+}
+
+func elapsedTimeOpt(since time.Time) option6 {
+	// In hundredths of a second, 0xffff represents any larger value.
+	elapsed := time.Since(since) / (10 * time.Millisecond)
+	if elapsed > 0xffff {
+		elapsed = 0xffff
+	}
+	return option6{code: opt6ElapsedTime, value: appendU16(nil, uint16(elapsed))}
+}
+
+func oroOpt() option6 {
+	var value []byte
+	for _, code := range requestedOptions6 {
+		value = appendU16(value, code)
+	}
+	return option6{code: opt6ORO, value: value}
+}
+
+// iaNA : Identity Association for Non-temporary Addresses.
+type iaNA struct {
+	iaid  uint32
+	t1    time.Duration
+	t2    time.Duration
+	addrs []iaAddr
+	// Status code of the IA_NA (not of addresses).
+	status uint16
+	// Status message from the server.
+	statusMsg string
+}
+
+type iaAddr struct {
+	addr              net.IP
+	preferredLifetime time.Duration
+	validLifetime     time.Duration
+	status            uint16
+}
+
+func (ia iaNA) option() option6 {
+	value := appendU32(nil, ia.iaid)
+	value = appendU32(value, uint32(ia.t1/time.Second))
+	value = appendU32(value, uint32(ia.t2/time.Second))
+	var addrOpts []option6
+	for _, addr := range ia.addrs {
+		addrValue := append([]byte{}, addr.addr.To16()...)
+		addrValue = appendU32(addrValue, uint32(addr.preferredLifetime/time.Second))
+		addrValue = appendU32(addrValue, uint32(addr.validLifetime/time.Second))
+		addrOpts = append(addrOpts, option6{code: opt6IAAddr, value: addrValue})
+	}
+	value = appendOptions6(value, addrOpts)
+	return option6{code: opt6IANA, value: value}
+}
+
+func lifetime(seconds uint32) time.Duration {
+	return time.Duration(seconds) * time.Second
+}
+
+func parseIANA(value []byte) (ia iaNA, err error) {
+	if len(value) < 12 {
+		return ia, errors.New("truncated IA_NA option")
+	}
+	ia.iaid = binary.BigEndian.Uint32(value)
+	ia.t1 = lifetime(binary.BigEndian.Uint32(value[4:]))
+	ia.t2 = lifetime(binary.BigEndian.Uint32(value[8:]))
+	options, err := decodeOptions6(value[12:])
+	if err != nil {
+		return ia, err
+	}
+	for _, opt := range options {
+		switch opt.code {
+		case opt6IAAddr:
+			if len(opt.value) < 24 {
+				return ia, errors.New("truncated IAADDR option")
+			}
+			addr := iaAddr{
+				addr:              net.IP(append([]byte{}, opt.value[:16]...)),
+				preferredLifetime: lifetime(binary.BigEndian.Uint32(opt.value[16:])),
+				validLifetime:     lifetime(binary.BigEndian.Uint32(opt.value[20:])),
+			}
+			subOpts, err := decodeOptions6(opt.value[24:])
+			if err != nil {
+				return ia, err
+			}
+			for _, subOpt := range subOpts {
+				if subOpt.code == opt6StatusCode {
+					addr.status, _ = parseStatusCode(subOpt.value)
+				}
+			}
+			ia.addrs = append(ia.addrs, addr)
+		case opt6StatusCode:
+			ia.status, ia.statusMsg = parseStatusCode(opt.value)
+		}
+	}
+	return ia, nil
+}
+
+func parseStatusCode(value []byte) (code uint16, msg string) {
+	if len(value) < 2 {
+		return status6Success, ""
+	}
+	return binary.BigEndian.Uint16(value), string(value[2:])
+}
+
+func statusCodeOpt(code uint16, msg string) option6 {
+	return option6{code: opt6StatusCode, value: append(appendU16(nil, code), msg...)}
+}
+
+func parseIP6List(value []byte) (ips []net.IP) {
+	for len(value) >= net.IPv6len {
+		ips = append(ips, net.IP(append([]byte{}, value[:net.IPv6len]...)))
+		value = value[net.IPv6len:]
+	}
+	return ips
+}
+
+// parseDomainList parses list of domain names encoded as described
+// in RFC 1035, section 3.1 (without compression).
+func parseDomainList(value []byte) (domains []string, err error) {
+	var labels []string
+	for len(value) > 0 {
+		length := int(value[0])
+		if length == 0 {
+			if len(labels) > 0 {
+				domains = append(domains, strings.Join(labels, "."))
+			}
+			labels = nil
+			value = value[1:]
+			continue
+		}
+		if len(value) < 1+length {
+			return nil, errors.New("truncated domain name")
+		}
+		labels = append(labels, string(value[1:1+length]))
+		value = value[1+length:]
+	}
+	if len(labels) > 0 {
+		// Partial domain name (without the terminating zero-length label).
+		domains = append(domains, strings.Join(labels, "."))
+	}
+	return domains, nil
+}
+
+func encodeDomainList(domains []string) (value []byte) {
+	for _, domain := range domains {
+		for _, label := range strings.Split(strings.TrimSuffix(domain, "."), ".") {
+			value = append(value, uint8(len(label)))
+			value = append(value, label...)
+		}
+		value = append(value, 0)
+	}
+	return value
+}
+
+// parseNTPServers parses NTP server option (RFC 5908).
+// Only server and multicast addresses are supported, FQDNs are skipped.
+func parseNTPServers(value []byte) (servers []net.IP, err error) {
+	subOpts, err := decodeOptions6(value)
+	if err != nil {
+		return nil, err
+	}
+	for _, subOpt := range subOpts {
+		switch subOpt.code {
+		case ntpSubOptSrvAddr, ntpSubOptMcAddr:
+			servers = append(servers, parseIP6List(subOpt.value)...)
+		}
+	}
+	return servers, nil
+}
+
+// leaseFromReply builds Lease6 from REPLY to REQUEST, RENEW or REBIND.
+func leaseFromReply(reply *message6, iaid uint32, acquiredAt time.Time) (*Lease6, error) {
+	if value, ok := reply.option(opt6StatusCode); ok {
+		if code, msg := parseStatusCode(value); code != status6Success {
+			return nil, &statusError6{code: code, msg: msg}
+		}
+	}
+	serverID, ok := reply.option(opt6ServerID)
+	if !ok {
+		return nil, errors.New("REPLY without server identifier")
+	}
+	value, ok := reply.option(opt6IANA)
+	if !ok {
+		return nil, errors.New("REPLY without IA_NA")
+	}
+	ia, err := parseIANA(value)
+	if err != nil {
+		return nil, err
+	}
+	if ia.iaid != iaid {
+		return nil, fmt.Errorf("REPLY with unexpected IAID %d", ia.iaid)
+	}
+	if ia.status != status6Success {
+		return nil, &statusError6{code: ia.status, msg: ia.statusMsg}
+	}
+	lease := &Lease6{
+		ServerID:   serverID,
+		IAID:       iaid,
+		T1:         ia.t1,
+		T2:         ia.t2,
+		AcquiredAt: acquiredAt,
+	}
+	for _, addr := range ia.addrs {
+		if addr.status != status6Success || addr.validLifetime == 0 {
+			continue
+		}
+		lease.Address = addr.addr
+		lease.PreferredLifetime = addr.preferredLifetime
+		lease.ValidLifetime = addr.validLifetime
+		break
+	}
+	if lease.Address == nil {
+		return nil, &statusError6{code: status6NoAddrs, msg: "no valid address in IA_NA"}
+	}
+	if value, ok = reply.option(opt6DNSServers); ok {
+		lease.DNSServers = parseIP6List(value)
+	}
+	if value, ok = reply.option(opt6DomainList); ok {
+		if lease.DomainSearch, err = parseDomainList(value); err != nil {
+			return nil, fmt.Errorf("failed to parse domain list: %w", err)
+		}
+	}
+	if value, ok = reply.option(opt6NTPServer); ok {
+		if lease.NTPServers, err = parseNTPServers(value); err != nil {
+			return nil, fmt.Errorf("failed to parse NTP servers: %w", err)
+		}
+	} else if value, ok = reply.option(opt6SNTPServers); ok {
+		lease.NTPServers = parseIP6List(value)
+	}
+	lease.fillTimers()
+	return lease, nil
+}
+
+// statusError6 : non-success status code returned by DHCPv6 server.
+type statusError6 struct {
+	code uint16
+	msg  string
+}
+
+func (e *statusError6) Error() string {
+	return fmt.Sprintf("DHCPv6 status code %d: %s", e.code, e.msg)
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package dhcpclient
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMessage6EncodeDecode(test *testing.T) {
+	t := NewGomegaWithT(test)
+	hwAddr, _ := net.ParseMAC("02:00:00:00:00:01")
+	ia := iaNA{
+		iaid: iaidFromHwAddr(hwAddr),
+		t1:   time.Minute,
+		t2:   2 * time.Minute,
+		addrs: []iaAddr{{
+			addr:              net.ParseIP("fd00::10"),
+			preferredLifetime: 3 * time.Minute,
+			validLifetime:     4 * time.Minute,
+		}},
+	}
+	msg := &message6{
+		msgType: MsgSolicit,
+		xid:     0xabcdef,
+		options: []option6{
+			{code: opt6ClientID, value: duidLL(hwAddr)},
+			oroOpt(),
+			ia.option(),
+		},
+	}
+	decoded, err := decodeMessage6(msg.encode())
+	t.Expect(err).ToNot(HaveOccurred())
+	t.Expect(decoded.msgType).To(Equal(MsgSolicit))
+	t.Expect(decoded.xid).To(BeEquivalentTo(0xabcdef))
+	clientID, ok := decoded.option(opt6ClientID)
+	t.Expect(ok).To(BeTrue())
+	t.Expect(clientID).To(Equal([]byte{0, 3, 0, 1, 2, 0, 0, 0, 0, 1}))
+	value, ok := decoded.option(opt6IANA)
+	t.Expect(ok).To(BeTrue())
+	parsedIA, err := parseIANA(value)
+	t.Expect(err).ToNot(HaveOccurred())
+	t.Expect(parsedIA.iaid).To(Equal(ia.iaid))
+	t.Expect(parsedIA.t2).To(Equal(2 * time.Minute))
+	t.Expect(parsedIA.addrs).To(HaveLen(1))
+	t.Expect(parsedIA.addrs[0].addr.String()).To(Equal("fd00::10"))
+	t.Expect(parsedIA.addrs[0].validLifetime).To(Equal(4 * time.Minute))
+
+	_, err = decodeMessage6([]byte{1, 0})
+	t.Expect(err).To(HaveOccurred())
+	_, err = decodeMessage6([]byte{1, 0, 0, 1, 0, 1, 0, 10, 1})
+	t.Expect(err).To(HaveOccurred())
+}
+
+func TestDomainList(test *testing.T) {
+	t := NewGomegaWithT(test)
+	domains := []string{"example.com", "lab.example.org."}
+	value := encodeDomainList(domains)
+	t.Expect(value[:13]).To(Equal([]byte{
+		7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0}))
+	parsed, err := parseDomainList(value)
+	t.Expect(err).ToNot(HaveOccurred())
+	t.Expect(parsed).To(Equal([]string{"example.com", "lab.example.org"}))
+	_, err = parseDomainList([]byte{7, 'e', 'x'})
+	t.Expect(err).To(HaveOccurred())
+}
+
+func TestLeaseFromReply(test *testing.T) {
+	t := NewGomegaWithT(test)
+	iaid := uint32(1)
+	ntpServers := appendOptions6(nil, []option6{
+		{code: ntpSubOptSrvAddr, value: net.ParseIP("fd00::3").To16()},
+	})
+	reply := &message6{
+		msgType: MsgReply,
+		options: []option6{
+			{code: opt6ServerID, value: []byte{0, 3, 0, 1, 2, 0, 0, 0, 0, 2}},
+			iaNA{iaid: iaid, addrs: []iaAddr{{
+				addr:              net.ParseIP("fd00::10"),
+				preferredLifetime: 100 * time.Second,
+				validLifetime:     200 * time.Second,
+			}}}.option(),
+			{code: opt6DNSServers, value: net.ParseIP("fd00::2").To16()},
+			{code: opt6DomainList, value: encodeDomainList([]string{"example.com"})},
+			{code: opt6NTPServer, value: ntpServers},
+		},
+	}
+	now := time.Now()
+	lease, err := leaseFromReply(reply, iaid, now)
+	t.Expect(err).ToNot(HaveOccurred())
+	t.Expect(lease.Address.String()).To(Equal("fd00::10"))
+	t.Expect(lease.DNSServers[0].String()).To(Equal("fd00::2"))
+	t.Expect(lease.DomainSearch).To(Equal([]string{"example.com"}))
+	t.Expect(lease.NTPServers[0].String()).To(Equal("fd00::3"))
+	// T1 and T2 left for the client to choose.
+	t.Expect(lease.T1).To(Equal(50 * time.Second))
+	t.Expect(lease.T2).To(Equal(80 * time.Second))
+	t.Expect(lease.Expiry()).To(Equal(now.Add(200 * time.Second)))
+
+	_, err = leaseFromReply(reply, 2, now)
+	t.Expect(err).To(HaveOccurred())
+
+	// Server without available addresses.
+	reply.options[1] = iaNA{iaid: iaid, status: status6NoAddrs}.option()
+	reply.options[1].value = appendOptions6(reply.options[1].value,
+		[]option6{statusCodeOpt(status6NoAddrs, "no addresses")})
+	_, err = leaseFromReply(reply, iaid, now)
+	var statusErr *statusError6
+	t.Expect(errors.As(err, &statusErr)).To(BeTrue())
+	t.Expect(statusErr.code).To(BeEquivalentTo(status6NoAddrs))
+}
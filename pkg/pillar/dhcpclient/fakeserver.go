@@ -0,0 +1,315 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package dhcpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/net/ipv6"
+)
+
+// FakeServerConfig : configuration for FakeServer.
+// The server leases at most one IPv4 and one IPv6 address.
+type FakeServerConfig struct {
+	IfName string
+	// IPv4 is served if LeaseIPv4 is defined.
+	ServerIPv4   net.IP
+	LeaseIPv4    *net.IPNet
+	Routers      []net.IP
+	DNSServers   []net.IP
+	NTPServers   []net.IP
+	DomainName   string
+	StaticRoutes []Route
+	VendorInfo   []byte
+	WPADURL      string
+	LeaseTime    time.Duration
+	// IPv6 is served if LeaseIPv6 is defined.
+	LeaseIPv6         net.IP
+	DNSServersV6      []net.IP
+	NTPServersV6      []net.IP
+	DomainSearch      []string
+	PreferredLifetime time.Duration
+	ValidLifetime     time.Duration
+}
+
+// FakeServer : minimal DHCPv4 and DHCPv6 server for testing of the client.
+// The server should run on the other side of a veth pair (in a different
+// network namespace) from the interface with the client.
+type FakeServer struct {
+	config FakeServerConfig
+	conn4  net.PacketConn
+	conn6  *ipv6.PacketConn
+	duid   []byte
+	wg     sync.WaitGroup
+
+	sync.Mutex
+	received []string
+	nak      bool
+}
+
+// NewFakeServer opens server sockets (in the current network namespace)
+// and starts serving requests in the background.
+func NewFakeServer(config FakeServerConfig) (*FakeServer, error) {
+	s := &FakeServer{config: config}
+	if config.LeaseIPv4 != nil {
+		lc := net.ListenConfig{Control: udpControl(config.IfName, true)}
+		conn, err := lc.ListenPacket(context.Background(), "udp4",
+			fmt.Sprintf("0.0.0.0:%d", ServerPortV4))
+		if err != nil {
+			return nil, err
+		}
+		s.conn4 = conn
+	}
+	if config.LeaseIPv6 != nil {
+		intf, err := net.InterfaceByName(config.IfName)
+		if err == nil {
+			s.duid = duidLL(intf.HardwareAddr)
+			lc := net.ListenConfig{Control: udpControl(config.IfName, false)}
+			var conn net.PacketConn
+			conn, err = lc.ListenPacket(context.Background(), "udp6",
+				fmt.Sprintf("[::]:%d", ServerPortV6))
+			if err == nil {
+				s.conn6 = ipv6.NewPacketConn(conn)
+				err = s.conn6.JoinGroup(intf, &net.UDPAddr{IP: AllDHCPServersV6})
+			}
+		}
+		if err != nil {
+			s.Stop()
+			return nil, err
+		}
+	}
+	if s.conn4 != nil {
+		s.wg.Add(1)
+		go s.serve4()
+	}
+	if s.conn6 != nil {
+		s.wg.Add(1)
+		go s.serve6()
+	}
+	return s, nil
+}
+
+// Stop the server.
+func (s *FakeServer) Stop() {
+	if s.conn4 != nil {
+		s.conn4.Close()
+	}
+	if s.conn6 != nil {
+		s.conn6.Close()
+	}
+	s.wg.Wait()
+}
+
+// Received returns names of the message types received so far.
+func (s *FakeServer) Received() []string {
+	s.Lock()
+	defer s.Unlock()
+	return append([]string{}, s.received...)
+}
+
+// ClearReceived clears the list of received messages.
+func (s *FakeServer) ClearReceived() {
+	s.Lock()
+	defer s.Unlock()
+	s.received = nil
+}
+
+// SetNak : when enabled, all DHCPv4 requests are rejected with NAK.
+func (s *FakeServer) SetNak(nak bool) {
+	s.Lock()
+	defer s.Unlock()
+	s.nak = nak
+}
+
+func (s *FakeServer) record(msgType string) bool {
+	s.Lock()
+	defer s.Unlock()
+	s.received = append(s.received, msgType)
+	return s.nak
+}
+
+func (s *FakeServer) serve4() {
+	defer s.wg.Done()
+	buf := make([]byte, 1500)
+	for {
+		n, _, err := s.conn4.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		msg, err := decodeMessage4(buf[:n])
+		if err != nil || msg.op != bootRequest {
+			continue
+		}
+		nak := s.record(msg.msgType().String())
+		reply := s.reply4(msg, nak)
+		if reply == nil {
+			continue
+		}
+		dst := &net.UDPAddr{IP: net.IPv4bcast, Port: ClientPortV4}
+		if !msg.ciaddr.IsUnspecified() {
+			dst.IP = msg.ciaddr
+		}
+		_, _ = s.conn4.WriteTo(reply.encode(), dst)
+	}
+}
+
+func (s *FakeServer) reply4(msg *message4, nak bool) *message4 {
+	var replyType MessageType4
+	switch msg.msgType() {
+	case MsgDiscover:
+		replyType = MsgOffer
+	case MsgRequest:
+		if serverID := optIP4(msg.options[opt4ServerID]); serverID != nil &&
+			!serverID.Equal(s.config.ServerIPv4) {
+			// Client selected another server.
+			return nil
+		}
+		requested := optIP4(msg.options[opt4RequestedIP])
+		if requested == nil {
+			requested = msg.ciaddr
+		}
+		replyType = MsgAck
+		if nak || !requested.Equal(s.config.LeaseIPv4.IP) {
+			replyType = MsgNak
+		}
+	default:
+		return nil
+	}
+	reply := &message4{
+		op:      bootReply,
+		xid:     msg.xid,
+		flags:   msg.flags,
+		ciaddr:  msg.ciaddr,
+		yiaddr:  net.IPv4zero,
+		siaddr:  net.IPv4zero,
+		giaddr:  net.IPv4zero,
+		chaddr:  msg.chaddr,
+		options: make(options4),
+	}
+	reply.options[opt4MessageType] = []byte{uint8(replyType)}
+	reply.options[opt4ServerID] = s.config.ServerIPv4.To4()
+	if replyType == MsgNak {
+		return reply
+	}
+	reply.yiaddr = s.config.LeaseIPv4.IP
+	reply.options[opt4SubnetMask] = s.config.LeaseIPv4.Mask
+	leaseTime := uint32(s.config.LeaseTime / time.Second)
+	reply.options[opt4LeaseTime] = appendU32(nil, leaseTime)
+	putIPs := func(code uint8, ips []net.IP) {
+		var value []byte
+		for _, ip := range ips {
+			value = append(value, ip.To4()...)
+		}
+		if len(value) > 0 {
+			reply.options[code] = value
+		}
+	}
+	putIPs(opt4Router, s.config.Routers)
+	putIPs(opt4DNSServers, s.config.DNSServers)
+	putIPs(opt4NTPServers, s.config.NTPServers)
+	if s.config.DomainName != "" {
+		reply.options[opt4DomainName] = []byte(s.config.DomainName)
+	}
+	if len(s.config.StaticRoutes) > 0 {
+		reply.options[opt4ClasslessRoutes] = encodeClasslessRoutes(s.config.StaticRoutes)
+	}
+	if len(s.config.VendorInfo) > 0 {
+		reply.options[opt4VendorSpecific] = s.config.VendorInfo
+	}
+	if s.config.WPADURL != "" {
+		reply.options[opt4WPAD] = []byte(s.config.WPADURL)
+	}
+	return reply
+}
+
+func (s *FakeServer) serve6() {
+	defer s.wg.Done()
+	buf := make([]byte, 1500)
+	for {
+		n, _, src, err := s.conn6.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		msg, err := decodeMessage6(buf[:n])
+		if err != nil {
+			continue
+		}
+		s.record(msg.msgType.String())
+		reply := s.reply6(msg)
+		if reply == nil {
+			continue
+		}
+		_, _ = s.conn6.WriteTo(reply.encode(), nil, src)
+	}
+}
+
+func (s *FakeServer) reply6(msg *message6) *message6 {
+	replyType := MsgReply
+	switch msg.msgType {
+	case MsgSolicit:
+		replyType = MsgAdvertise
+	case MsgRequest6, MsgRenew, MsgRebind, MsgRelease6:
+	default:
+		return nil
+	}
+	clientID, ok := msg.option(opt6ClientID)
+	if !ok {
+		return nil
+	}
+	reply := &message6{msgType: replyType, xid: msg.xid}
+	reply.options = append(reply.options,
+		option6{code: opt6ClientID, value: clientID},
+		option6{code: opt6ServerID, value: s.duid})
+	if msg.msgType == MsgRelease6 {
+		reply.options = append(reply.options, statusCodeOpt(status6Success, ""))
+		return reply
+	}
+	value, ok := msg.option(opt6IANA)
+	if !ok {
+		return nil
+	}
+	ia, err := parseIANA(value)
+	if err != nil {
+		return nil
+	}
+	ia.t1 = s.config.PreferredLifetime / 2
+	ia.t2 = s.config.PreferredLifetime * 4 / 5
+	ia.addrs = []iaAddr{{
+		addr:              s.config.LeaseIPv6,
+		preferredLifetime: s.config.PreferredLifetime,
+		validLifetime:     s.config.ValidLifetime,
+	}}
+	reply.options = append(reply.options, ia.option())
+	if len(s.config.DNSServersV6) > 0 {
+		var value []byte
+		for _, ip := range s.config.DNSServersV6 {
+			value = append(value, ip.To16()...)
+		}
+		reply.options = append(reply.options, option6{code: opt6DNSServers, value: value})
+	}
+	if len(s.config.DomainSearch) > 0 {
+		reply.options = append(reply.options, option6{
+			code: opt6DomainList, value: encodeDomainList(s.config.DomainSearch)})
+	}
+	if len(s.config.NTPServersV6) > 0 {
+		var subOpts []option6
+		for _, ip := range s.config.NTPServersV6 {
+			subOpts = append(subOpts, option6{code: ntpSubOptSrvAddr, value: ip.To16()})
+		}
+		reply.options = append(reply.options, option6{
+			code: opt6NTPServer, value: appendOptions6(nil, subOpts)})
+	}
+	return reply
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package dhcpclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// InfinityLifetime is used for leases which never expire.
+const InfinityLifetime = time.Duration(0xffffffff) * time.Second
+
+// Route : static route received from DHCPv4 server (option 121).
+type Route struct {
+	Dst *net.IPNet
+	// Gateway is unspecified (0.0.0.0) for routes to on-link destinations.
+	Gateway net.IP
+}
+
+// String returns human-readable description of the route.
+func (r Route) String() string {
+	return fmt.Sprintf("%s via %s", r.Dst, r.Gateway)
+}
+
+// Lease4 : IPv4 configuration obtained from a DHCPv4 server or configured
+// statically.
+type Lease4 struct {
+	Address net.IP
+	Mask    net.IPMask
+	// ServerID is nil for static configuration.
+	ServerID     net.IP
+	Routers      []net.IP
+	DNSServers   []net.IP
+	NTPServers   []net.IP
+	DomainName   string
+	Hostname     string
+	StaticRoutes []Route
+	// VendorInfo : vendor-specific information (option 43).
+	VendorInfo []byte
+	// WPADURL : URL of the proxy auto-configuration file (option 252).
+	WPADURL    string
+	MTU        uint16
+	LeaseTime  time.Duration
+	T1         time.Duration // renewal time
+	T2         time.Duration // rebinding time
+	AcquiredAt time.Time
+}
+
+// Subnet returns the subnet of the leased address.
+func (l *Lease4) Subnet() *net.IPNet {
+	return &net.IPNet{IP: l.Address.Mask(l.Mask), Mask: l.Mask}
+}
+
+// IsStatic returns true for statically configured IPv4 address.
+func (l *Lease4) IsStatic() bool {
+	return l.ServerID == nil
+}
+
+// fillTimers sets T1 and T2 to default values if not provided by the server
+// (RFC 2131, section 4.4.5).
+func (l *Lease4) fillTimers() {
+	if l.LeaseTime == 0 {
+		l.LeaseTime = InfinityLifetime
+	}
+	if l.T1 == 0 || l.T1 > l.LeaseTime {
+		l.T1 = l.LeaseTime / 2
+	}
+	if l.T2 == 0 || l.T2 > l.LeaseTime || l.T2 < l.T1 {
+		l.T2 = l.LeaseTime * 7 / 8
+	}
+}
+
+// Expiry returns the time when the lease expires.
+func (l *Lease4) Expiry() time.Time {
+	return l.AcquiredAt.Add(l.LeaseTime)
+}
+
+// Lease6 : IPv6 address obtained from a DHCPv6 server (IA_NA).
+type Lease6 struct {
+	Address net.IP
+	// ServerID : DUID of the server.
+	ServerID          []byte
+	IAID              uint32
+	DNSServers        []net.IP
+	NTPServers        []net.IP
+	DomainSearch      []string
+	PreferredLifetime time.Duration
+	ValidLifetime     time.Duration
+	T1                time.Duration // renewal time
+	T2                time.Duration // rebinding time
+	AcquiredAt        time.Time
+}
+
+// fillTimers sets T1 and T2 to recommended values if left for the client
+// to choose (RFC 8415, section 21.4).
+func (l *Lease6) fillTimers() {
+	if l.T1 == 0 || l.T1 > l.ValidLifetime {
+		l.T1 = l.PreferredLifetime / 2
+	}
+	if l.T2 == 0 || l.T2 > l.ValidLifetime || l.T2 < l.T1 {
+		l.T2 = l.PreferredLifetime * 4 / 5
+	}
+}
+
+// Expiry returns the time when the leased address becomes invalid.
+func (l *Lease6) Expiry() time.Time {
+	return l.AcquiredAt.Add(l.ValidLifetime)
+}
+
+// Info : IP configuration applied by the DHCP client to an interface.
+// The client publishes Info into a file (see InfoFile), which is watched
+// by NetworkMonitor.
+type Info struct {
+	IfName string
+	IPv4   *Lease4
+	IPv6   *Lease6
+}
+
+// InfoFile returns path to the file with Info published for the interface.
+func InfoFile(infoDir, ifName string) string {
+	return filepath.Join(infoDir, ifName+infoFileExt)
+}
+
+// InfoFileToIfName returns the name of the interface for which the given
+// info file was published (empty string if this is not an info file).
+func InfoFileToIfName(infoFile string) string {
+	if filepath.Ext(infoFile) != infoFileExt {
+		return ""
+	}
+	return filepath.Base(infoFile[:len(infoFile)-len(infoFileExt)])
+}
+
+// ReadInfo reads Info published into the given file.
+func ReadInfo(infoFile string) (info Info, err error) {
+	data, err := ioutil.ReadFile(infoFile)
+	if err != nil {
+		return info, err
+	}
+	err = json.Unmarshal(data, &info)
+	return info, err
+}
+
+const (
+	infoFileExt   = ".json"
+	lease4FileExt = ".lease"
+	lease6FileExt = ".lease6"
+)
+
+func leaseFile(leaseDir, ifName string, ipv6 bool) string {
+	if ipv6 {
+		return filepath.Join(leaseDir, ifName+lease6FileExt)
+	}
+	return filepath.Join(leaseDir, ifName+lease4FileExt)
+}
+
+// writeJSONFile atomically replaces the file with JSON-encoded data.
+func writeJSONFile(path string, data interface{}) error {
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(path, content)
+}
+
+// writeFile atomically replaces content of the file.
+func writeFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmpFile, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err = tmpFile.Write(content); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err = tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err = tmpFile.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmpFile.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), path)
+}
+
+func readJSONFile(path string, data interface{}) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, data)
+}
@@ -0,0 +1,286 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package dhcpclient
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+// errRecvTimeout is returned by recv when no message arrives before the deadline.
+var errRecvTimeout = errors.New("receive timeout")
+
+// udpControl returns Control function for net.ListenConfig, which binds
+// the socket to the interface.
+func udpControl(ifName string, broadcast bool) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		var sockErr error
+		err := c.Control(func(fd uintptr) {
+			if sockErr = unix.BindToDevice(int(fd), ifName); sockErr != nil {
+				return
+			}
+			if sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET,
+				unix.SO_REUSEADDR, 1); sockErr != nil {
+				return
+			}
+			if broadcast {
+				sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET,
+					unix.SO_BROADCAST, 1)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		return sockErr
+	}
+}
+
+// conn4 : sockets used to exchange DHCPv4 messages over an interface.
+// Broadcast messages are sent and all messages are received using packet socket,
+// which allows to communicate before the interface has an IP address
+// (and regardless of the reverse-path filtering). UDP socket is used to send
+// unicast messages.
+type conn4 struct {
+	ifIndex    int
+	udpConn    net.PacketConn
+	packetConn *os.File
+}
+
+func htons(v uint16) uint16 {
+	return v<<8 | v>>8
+}
+
+// dhcp4Filter accepts only non-fragmented UDP packets destined to the DHCPv4
+// client port.
+func dhcp4Filter() ([]unix.SockFilter, error) {
+	instructions, err := bpf.Assemble([]bpf.Instruction{
+		// IP protocol must be UDP.
+		bpf.LoadAbsolute{Off: 9, Size: 1},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: unix.IPPROTO_UDP, SkipFalse: 6},
+		// Skip fragments.
+		bpf.LoadAbsolute{Off: 6, Size: 2},
+		bpf.JumpIf{Cond: bpf.JumpBitsSet, Val: 0x1fff, SkipTrue: 4},
+		// Check destination UDP port.
+		bpf.LoadMemShift{Off: 0},
+		bpf.LoadIndirect{Off: 2, Size: 2},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: ClientPortV4, SkipFalse: 1},
+		bpf.RetConstant{Val: 0xffff},
+		bpf.RetConstant{Val: 0},
+	})
+	if err != nil {
+		return nil, err
+	}
+	filter := make([]unix.SockFilter, 0, len(instructions))
+	for _, ins := range instructions {
+		filter = append(filter, unix.SockFilter{
+			Code: ins.Op,
+			Jt:   ins.Jt,
+			Jf:   ins.Jf,
+			K:    ins.K,
+		})
+	}
+	return filter, nil
+}
+
+func openConn4(ifName string) (*conn4, error) {
+	link, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return nil, err
+	}
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_DGRAM|unix.SOCK_NONBLOCK|unix.SOCK_CLOEXEC,
+		int(htons(unix.ETH_P_IP)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open packet socket: %w", err)
+	}
+	filter, err := dhcp4Filter()
+	if err == nil {
+		err = unix.SetsockoptSockFprog(fd, unix.SOL_SOCKET, unix.SO_ATTACH_FILTER,
+			&unix.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]})
+	}
+	if err == nil {
+		err = unix.Bind(fd, &unix.SockaddrLinklayer{
+			Protocol: htons(unix.ETH_P_IP),
+			Ifindex:  link.Index,
+		})
+	}
+	if err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("failed to setup packet socket: %w", err)
+	}
+	packetConn := os.NewFile(uintptr(fd), "dhcp4-"+ifName)
+	lc := net.ListenConfig{Control: udpControl(ifName, true)}
+	udpConn, err := lc.ListenPacket(context.Background(), "udp4",
+		fmt.Sprintf("0.0.0.0:%d", ClientPortV4))
+	if err != nil {
+		packetConn.Close()
+		return nil, fmt.Errorf("failed to open UDP socket: %w", err)
+	}
+	// Packets received by the UDP socket are never read, keep the buffer small.
+	_ = udpConn.(*net.UDPConn).SetReadBuffer(1)
+	return &conn4{ifIndex: link.Index, udpConn: udpConn, packetConn: packetConn}, nil
+}
+
+// send message to the server. Source address is used only for broadcasts
+// (for unicast it is selected by the kernel).
+func (c *conn4) send(msg []byte, src, dst net.IP) error {
+	if !dst.Equal(net.IPv4bcast) {
+		_, err := c.udpConn.WriteTo(msg, &net.UDPAddr{IP: dst, Port: ServerPortV4})
+		return err
+	}
+	packet := udp4Packet(msg, src, dst)
+	rawConn, err := c.packetConn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var sendErr error
+	err = rawConn.Write(func(fd uintptr) bool {
+		sendErr = unix.Sendto(int(fd), packet, 0, &unix.SockaddrLinklayer{
+			Protocol: htons(unix.ETH_P_IP),
+			Ifindex:  c.ifIndex,
+			Halen:    6,
+			Addr:     [8]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		})
+		return sendErr != unix.EAGAIN
+	})
+	if err != nil {
+		return err
+	}
+	return sendErr
+}
+
+// udp4Packet encapsulates DHCPv4 message into UDP and IPv4 headers.
+// UDP checksum is optional for IPv4 and left as zero.
+func udp4Packet(payload []byte, src, dst net.IP) []byte {
+	const ipHdrLen, udpHdrLen = 20, 8
+	packet := make([]byte, ipHdrLen+udpHdrLen+len(payload))
+	ipHdr := packet[:ipHdrLen]
+	ipHdr[0] = 0x45 // version 4, header length 5 words
+	ipHdr[1] = 0x10 // low delay
+	binary.BigEndian.PutUint16(ipHdr[2:], uint16(len(packet)))
+	ipHdr[8] = 64 // TTL
+	ipHdr[9] = unix.IPPROTO_UDP
+	if src4 := src.To4(); src4 != nil {
+		copy(ipHdr[12:16], src4)
+	}
+	copy(ipHdr[16:20], dst.To4())
+	binary.BigEndian.PutUint16(ipHdr[10:], ipChecksum(ipHdr))
+	udpHdr := packet[ipHdrLen : ipHdrLen+udpHdrLen]
+	binary.BigEndian.PutUint16(udpHdr[0:], ClientPortV4)
+	binary.BigEndian.PutUint16(udpHdr[2:], ServerPortV4)
+	binary.BigEndian.PutUint16(udpHdr[4:], uint16(udpHdrLen+len(payload)))
+	copy(packet[ipHdrLen+udpHdrLen:], payload)
+	return packet
+}
+
+func ipChecksum(hdr []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(hdr); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(hdr[i:]))
+	}
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	return ^uint16(sum)
+}
+
+// recv returns UDP payload of the next received DHCPv4 packet.
+func (c *conn4) recv(deadline time.Time) ([]byte, error) {
+	if err := c.packetConn.SetReadDeadline(deadline); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 1500)
+	for {
+		n, err := c.packetConn.Read(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return nil, errRecvTimeout
+			}
+			return nil, err
+		}
+		packet := buf[:n]
+		if n < 20 || packet[0]>>4 != 4 {
+			continue
+		}
+		ihl := int(packet[0]&0x0f) * 4
+		if n < ihl+8 {
+			continue
+		}
+		udp := packet[ihl:]
+		udpLen := int(binary.BigEndian.Uint16(udp[4:]))
+		if udpLen < 8 || udpLen > len(udp) {
+			continue
+		}
+		return append([]byte{}, udp[8:udpLen]...), nil
+	}
+}
+
+func (c *conn4) close() error {
+	err := c.packetConn.Close()
+	if err2 := c.udpConn.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
+
+// conn6 : UDP socket used to exchange DHCPv6 messages over an interface.
+type conn6 struct {
+	// Interface index is used as the zone of the destination address.
+	// Interface name could be resolved to a stale index by the zone cache
+	// of the net package if the interface was recreated.
+	zone string
+	conn net.PacketConn
+}
+
+func openConn6(ifName string) (*conn6, error) {
+	link, err := net.InterfaceByName(ifName)
+	if err != nil {
+		return nil, err
+	}
+	lc := net.ListenConfig{Control: udpControl(ifName, false)}
+	conn, err := lc.ListenPacket(context.Background(), "udp6",
+		fmt.Sprintf("[::]:%d", ClientPortV6))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open UDP socket: %w", err)
+	}
+	return &conn6{zone: strconv.Itoa(link.Index), conn: conn}, nil
+}
+
+// send message to all DHCPv6 servers on the link.
+func (c *conn6) send(msg []byte) error {
+	_, err := c.conn.WriteTo(msg, &net.UDPAddr{
+		IP:   AllDHCPServersV6,
+		Port: ServerPortV6,
+		Zone: c.zone,
+	})
+	return err
+}
+
+func (c *conn6) recv(deadline time.Time) ([]byte, error) {
+	if err := c.conn.SetReadDeadline(deadline); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 1500)
+	n, _, err := c.conn.ReadFrom(buf)
+	if err != nil {
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return nil, errRecvTimeout
+		}
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+func (c *conn6) close() error {
+	return c.conn.Close()
+}
@@ -70,14 +70,40 @@ func CreateImg(ctx context.Context, log *base.LogObject, diskfile string, format
 	return nil
 }
 
+//CopyImg copies diskfile, which may be in use, to outputFile in qcow2 format
+func CopyImg(ctx context.Context, log *base.LogObject, diskfile, outputFile string) error {
+	output, err := base.Exec(log, "/usr/bin/qemu-img", "convert", "-U", "-O", "qcow2",
+		diskfile, outputFile).WithContext(ctx).CombinedOutputWithCustomTimeout(432000)
+	if err != nil {
+		errStr := fmt.Sprintf("qemu-img failed: %s, %s\n",
+			err, output)
+		return errors.New(errStr)
+	}
+	return nil
+}
+
 //RolloutImgToBlock do conversion of diskfile to outputFile with defined format
 func RolloutImgToBlock(ctx context.Context, log *base.LogObject, diskfile, outputFile, outputFormat string) error {
+	return rolloutImg(ctx, log, diskfile, outputFile, outputFormat, true)
+}
+
+//RolloutImgToNonZeroBlock is RolloutImgToBlock for outputFile which is not
+//zeroed, e.g. dm-crypt device, so zeroes from diskfile are written too
+func RolloutImgToNonZeroBlock(ctx context.Context, log *base.LogObject, diskfile, outputFile, outputFormat string) error {
+	return rolloutImg(ctx, log, diskfile, outputFile, outputFormat, false)
+}
+
+func rolloutImg(ctx context.Context, log *base.LogObject, diskfile, outputFile, outputFormat string, targetIsZero bool) error {
 	if _, err := os.Stat(diskfile); err != nil {
 		return err
 	}
 	// writeback cache instead of default unsafe, out of order enabled, skip file creation
 	// Timeout 2 hours
-	args := []string{"convert", "--target-is-zero", "-t", "writeback", "-W", "-n", "-O", outputFormat, diskfile, outputFile}
+	args := []string{"convert"}
+	if targetIsZero {
+		args = append(args, "--target-is-zero")
+	}
+	args = append(args, "-t", "writeback", "-W", "-n", "-O", outputFormat, diskfile, outputFile)
 	output, err := base.Exec(log, "/usr/bin/qemu-img", args...).WithContext(ctx).CombinedOutputWithCustomTimeout(432000)
 	if err != nil {
 		errStr := fmt.Sprintf("qemu-img failed: %s, %s\n",
@@ -33,6 +33,7 @@ func (g *geoService) GetGeolocationInfo(ipAddr net.IP) (*ipinfo.IPInfo, error) {
 func (m *DpcManager) updateDNS() {
 	defer m.publishDNS()
 	dpc := m.currentDPC()
+	m.updateNeighborDiscovery(dpc)
 	if dpc == nil {
 		m.deviceNetStatus = types.DeviceNetworkStatus{}
 		return
@@ -43,6 +44,7 @@ func (m *DpcManager) updateDNS() {
 	m.deviceNetStatus.Testing = m.dpcVerify.inProgress
 	m.deviceNetStatus.CurrentIndex = m.dpcList.CurrentIndex
 	m.deviceNetStatus.RadioSilence = m.radioSilence
+	m.deviceNetStatus.NTPServers = dpc.NTPServers
 	oldPorts := m.deviceNetStatus.Ports
 	m.deviceNetStatus.Ports = make([]types.NetworkPortStatus, len(dpc.Ports))
 	for ix, port := range dpc.Ports {
@@ -53,6 +55,7 @@ func (m *DpcManager) updateDNS() {
 		m.deviceNetStatus.Ports[ix].IsMgmt = port.IsMgmt
 		m.deviceNetStatus.Ports[ix].IsL3Port = port.IsL3Port
 		m.deviceNetStatus.Ports[ix].Cost = port.Cost
+		m.deviceNetStatus.Ports[ix].Quality = m.portQuality.Quality(port.IfName)
 		m.deviceNetStatus.Ports[ix].ProxyConfig = port.ProxyConfig
 		m.deviceNetStatus.Ports[ix].WirelessCfg = port.WirelessCfg
 		// Set fields from the config...
@@ -67,6 +70,9 @@ func (m *DpcManager) updateDNS() {
 		m.deviceNetStatus.Ports[ix].DNSServers = port.DnsServers
 		m.deviceNetStatus.Ports[ix].NtpServer = port.NtpServer
 		m.deviceNetStatus.Ports[ix].TestResults = port.TestResults
+		if port.Dot1x.IsEnabled() {
+			m.deviceNetStatus.Ports[ix].Dot1xStatus = m.reconcileStatus.Dot1x[port.IfName]
+		}
 		// Do not try to get state data for interface which is in PCIback.
 		ioBundle := m.adapters.LookupIoBundleIfName(port.IfName)
 		if ioBundle != nil && ioBundle.IsPCIBack {
@@ -101,6 +107,16 @@ func (m *DpcManager) updateDNS() {
 			ipAddrs = nil
 		}
 		m.deviceNetStatus.Ports[ix].MacAddr = macAddr.String()
+		// Get neighbors discovered using LLDP/CDP for physical Ethernet ports.
+		if _, discovering := m.neighborDiscovery[port.IfName]; discovering {
+			neighbors, err := m.NetworkMonitor.GetInterfaceNeighbors(ifindex)
+			if err != nil {
+				m.Log.Warnf(
+					"updateDNS: failed to get neighbors for interface %s with index %d: %v",
+					port.IfName, ifindex, err)
+			}
+			m.deviceNetStatus.Ports[ix].Neighbors = neighbors
+		}
 		m.deviceNetStatus.Ports[ix].AddrInfoList = make([]types.AddrInfo, len(ipAddrs))
 		if len(ipAddrs) == 0 {
 			m.Log.Functionf("updateDNS: interface %s has NO IP addresses", port.IfName)
@@ -108,7 +124,8 @@ func (m *DpcManager) updateDNS() {
 		for i, addr := range ipAddrs {
 			m.deviceNetStatus.Ports[ix].AddrInfoList[i].Addr = addr.IP
 		}
-		// Get DNS etc info from dhcpcd. Updates DomainName and DnsServers.
+		// Get DHCP info from the DHCP client. Updates Subnet, NtpServers
+		// and DhcpWpadURL.
 		err = m.getDHCPInfo(&m.deviceNetStatus.Ports[ix])
 		if err != nil && dpc.State != types.DPCStateAsyncWait {
 			m.Log.Error(err)
@@ -171,6 +188,52 @@ func (m *DpcManager) updateDNS() {
 	}
 }
 
+// updateNeighborDiscovery starts LLDP/CDP neighbor discovery for physical
+// Ethernet ports of the current DPC and stops it for ports removed from the DPC,
+// assigned to applications or re-created with a different interface index.
+func (m *DpcManager) updateNeighborDiscovery(dpc *types.DevicePortConfig) {
+	discover := make(map[string]int) // ifName -> ifIndex
+	if dpc != nil {
+		for _, port := range dpc.Ports {
+			if port.L2Type != types.L2LinkTypeNone ||
+				port.WirelessCfg.WType != types.WirelessTypeNone {
+				continue
+			}
+			ioBundle := m.adapters.LookupIoBundleIfName(port.IfName)
+			if ioBundle != nil && ioBundle.IsPCIBack {
+				continue
+			}
+			ifIndex, exists, err := m.NetworkMonitor.GetInterfaceIndex(port.IfName)
+			if !exists || err != nil {
+				continue
+			}
+			discover[port.IfName] = ifIndex
+		}
+	}
+	for ifName, ifIndex := range m.neighborDiscovery {
+		if newIfIndex, keep := discover[ifName]; !keep || newIfIndex != ifIndex {
+			m.NetworkMonitor.StopNeighborDiscovery(ifIndex)
+			delete(m.neighborDiscovery, ifName)
+			m.Log.Noticef("Stopped neighbor discovery for port %s", ifName)
+		}
+	}
+	for ifName, ifIndex := range discover {
+		_, running := m.neighborDiscovery[ifName]
+		// Called also for running discovery to follow changes of bridged interfaces.
+		// Recorded even if it fails, listeners started so far are stopped with the port.
+		err := m.NetworkMonitor.StartNeighborDiscovery(ifIndex)
+		m.neighborDiscovery[ifName] = ifIndex
+		if err != nil {
+			m.Log.Warnf("updateNeighborDiscovery: failed to start for port %s: %v",
+				ifName, err)
+			continue
+		}
+		if !running {
+			m.Log.Noticef("Started neighbor discovery for port %s", ifName)
+		}
+	}
+}
+
 func (m *DpcManager) publishDNS() {
 	err := m.PubDeviceNetworkStatus.Publish("global", m.deviceNetStatus)
 	if err != nil {
@@ -245,6 +308,7 @@ func (m *DpcManager) getDHCPInfo(port *types.NetworkPortStatus) error {
 		port.Subnet = *dhcpInfo.Subnet
 	}
 	port.NtpServers = dhcpInfo.NtpServers
+	port.DhcpWpadURL = dhcpInfo.WpadURL
 	return nil
 }
 
@@ -15,6 +15,7 @@ import (
 	"github.com/lf-edge/eve/pkg/pillar/dpcreconciler"
 	"github.com/lf-edge/eve/pkg/pillar/flextimer"
 	"github.com/lf-edge/eve/pkg/pillar/netmonitor"
+	"github.com/lf-edge/eve/pkg/pillar/portquality"
 	"github.com/lf-edge/eve/pkg/pillar/pubsub"
 	"github.com/lf-edge/eve/pkg/pillar/types"
 	"github.com/lf-edge/eve/pkg/pillar/zedcloud"
@@ -49,6 +50,9 @@ var nilUUID = uuid.UUID{} // used as a constant
 // The reconciler is able to switch from one DPC to another.
 // Lastly, NetworkMonitor is used to monitor network stack for interesting
 // events, such as link state changes, and to collect state information.
+// Additionally, the manager estimates link quality of every port from zedcloud
+// metrics (see pillar/portquality). The estimated quality is used to prefer
+// the healthiest port among management ports of the same cost.
 // Manager publishes device network status (DeviceNetworkStatus struct; abbreviated
 // to DNS), updated on every state change, including a switch to another DPC.
 // DpcManager is a generic state machine, not tied to any particular network stack.
@@ -62,7 +66,11 @@ type DpcManager struct {
 	// Keep nil values to let DpcManager to use default implementations.
 	// It is useful to override for unit testing purposes.
 	WwanWatcher WwanWatcher
-	GeoService  GeolocationService
+	// PubSub is used by the default WwanWatcher to subscribe for the output
+	// of wwan microservice. Not needed if WwanWatcher is overridden.
+	PubSub     *pubsub.PubSub
+	GeoService GeolocationService
+	NTPMonitor NTPMonitor
 
 	// Minimum time that should pass after a DPC verification failure
 	// until the DPC is eligible for another round of verification.
@@ -83,6 +91,12 @@ type DpcManager struct {
 	PubWwanStatus            pubsub.Publication
 	PubWwanMetrics           pubsub.Publication
 	PubWwanLocationInfo      pubsub.Publication
+	// PubPortQualityHistory is used to persist port quality estimates.
+	// Optional, estimates are not persisted if nil.
+	PubPortQualityHistory pubsub.Publication
+	// PubNTPStatus is used to publish the status of the device clock
+	// synchronization. Optional, the status is not published if nil.
+	PubNTPStatus pubsub.Publication
 
 	// Metrics
 	ZedcloudMetrics *zedcloud.AgentMetrics
@@ -93,6 +107,7 @@ type DpcManager struct {
 	globalCfg        types.ConfigItemValueMap
 	hasGlobalCfg     bool
 	radioSilence     types.RadioSilence
+	wlanAPs          []string
 	enableLastResort bool
 	// Boot-time configuration
 	dpclPresentAtBoot bool
@@ -105,6 +120,12 @@ type DpcManager struct {
 	deviceNetStatus types.DeviceNetworkStatus
 	wwanStatus      types.WwanStatus
 	wwanMetrics     types.WwanMetrics
+	// APN profile to start with for each cellular port (key = logical label).
+	apnProfileIdx map[string]int
+	portQuality   *portquality.Estimator
+	ntpStatus     types.NTPStatus
+	// Ports with LLDP/CDP neighbor discovery started (key = ifName, value = ifIndex).
+	neighborDiscovery map[string]int
 
 	// Channels
 	inputCommands chan inputCommand
@@ -155,8 +176,8 @@ const (
 )
 
 // WwanWatcher allows to watch for output coming from wwan microservice.
-// wwan microservice is a shell script and uses files for input/output
-// instead of pubsub.
+// The default implementation subscribes to WwanStatus, WwanMetrics
+// and WwanLocationInfo published by wwan microservice.
 type WwanWatcher interface {
 	Watch(ctx context.Context) (<-chan WwanEvent, error)
 	LoadStatus() (types.WwanStatus, error)
@@ -172,6 +193,13 @@ type GeolocationService interface {
 	GetGeolocationInfo(ipAddr net.IP) (*ipinfo.IPInfo, error)
 }
 
+// NTPMonitor allows to obtain the status of the device clock synchronization.
+type NTPMonitor interface {
+	// GetNTPStatus returns the current status of the clock synchronization
+	// with NTP servers.
+	GetNTPStatus() (types.NTPStatus, error)
+}
+
 type command uint8
 
 const (
@@ -181,14 +209,19 @@ const (
 	commandUpdateGCP
 	commandUpdateAA
 	commandUpdateRS
+	commandUpdateMetrics
+	commandUpdateWlanAPs
 )
 
 type inputCommand struct {
-	cmd command
-	dpc types.DevicePortConfig   // for inputCmdAddDPC
-	gcp types.ConfigItemValueMap // for inputCmdUpdateGCP
-	aa  types.AssignableAdapters // for inputCmdUpdateAA
-	rs  types.RadioSilence       // for inputCmdUpdateRS
+	cmd           command
+	dpc           types.DevicePortConfig   // for inputCmdAddDPC
+	gcp           types.ConfigItemValueMap // for inputCmdUpdateGCP
+	aa            types.AssignableAdapters // for inputCmdUpdateAA
+	rs            types.RadioSilence       // for inputCmdUpdateRS
+	metrics       types.MetricsMap         // for inputCmdUpdateMetrics
+	metricsSource string                   // for inputCmdUpdateMetrics
+	wlanAPs       []string                 // for inputCmdUpdateWlanAPs
 }
 
 type dpcVerify struct {
@@ -196,18 +229,25 @@ type dpcVerify struct {
 	startedAt      time.Time
 	cloudConnWorks bool
 	crucialIfs     map[string]netmonitor.IfAttrs // key = ifName, change triggers restartVerify
+	apnFailovers   map[string]int                // key = logical label of a cellular port
 }
 
 // Init DpcManager
 func (m *DpcManager) Init(ctx context.Context) error {
 	m.dpcVerify.crucialIfs = make(map[string]netmonitor.IfAttrs)
+	m.dpcVerify.apnFailovers = make(map[string]int)
+	m.apnProfileIdx = make(map[string]int)
+	m.neighborDiscovery = make(map[string]int)
 	m.inputCommands = make(chan inputCommand, 10)
 	if m.WwanWatcher == nil {
-		m.WwanWatcher = &wwanWatcher{Log: m.Log}
+		m.WwanWatcher = &wwanWatcher{Log: m.Log, PubSub: m.PubSub, AgentName: m.AgentName}
 	}
 	if m.GeoService == nil {
 		m.GeoService = &geoService{}
 	}
+	if m.NTPMonitor == nil {
+		m.NTPMonitor = &ntpMonitor{log: m.Log}
+	}
 	if m.DpcMinTimeSinceFailure == 0 {
 		m.DpcMinTimeSinceFailure = 5 * time.Minute
 	}
@@ -224,6 +264,7 @@ func (m *DpcManager) Init(ctx context.Context) error {
 	// Ingest persisted list of DPCs. ingestDPCList will return false
 	// to indicate the file is missing in /persist
 	m.dpclPresentAtBoot = m.ingestDPCList()
+	m.ingestPortQualityHistory()
 	return nil
 }
 
@@ -242,11 +283,14 @@ func (m *DpcManager) Run(ctx context.Context) (err error) {
 func (m *DpcManager) run(ctx context.Context) {
 	wdName := m.AgentName + "-DpcManager"
 	stillRunning := time.NewTicker(watchdogPeriod)
+	portQualityTicker := time.NewTicker(portQualityInterval)
+	ntpStatusTicker := time.NewTicker(ntpStatusInterval)
 	m.Watchdog.StillRunning(wdName, warningTime, errorTime)
 	m.Watchdog.RegisterFileWatchdog(wdName)
 
 	// Run initial reconciliation.
 	m.reconcileStatus = m.DpcReconciler.Reconcile(ctx, m.reconcilerArgs())
+	m.updateNTPStatus()
 
 	for {
 		select {
@@ -264,6 +308,12 @@ func (m *DpcManager) run(ctx context.Context) {
 				m.updateAA(ctx, inputCmd.aa)
 			case commandUpdateRS:
 				m.updateRadioSilence(ctx, inputCmd.rs)
+			case commandUpdateMetrics:
+				m.portQuality.UpdateMetrics(inputCmd.metricsSource,
+					inputCmd.metrics, time.Now())
+			case commandUpdateWlanAPs:
+				m.wlanAPs = inputCmd.wlanAPs
+				m.reconcileStatus = m.DpcReconciler.Reconcile(ctx, m.reconcilerArgs())
 			}
 			m.resumeVerifyIfAsyncDone(ctx)
 
@@ -321,6 +371,18 @@ func (m *DpcManager) run(ctx context.Context) {
 			m.Watchdog.CheckMaxTimeTopic(m.AgentName, "PendTimer", start,
 				warningTime, errorTime)
 
+		case <-portQualityTicker.C:
+			start := time.Now()
+			m.updatePortQuality()
+			m.Watchdog.CheckMaxTimeTopic(m.AgentName, "portQualityTicker", start,
+				warningTime, errorTime)
+
+		case <-ntpStatusTicker.C:
+			start := time.Now()
+			m.updateNTPStatus()
+			m.Watchdog.CheckMaxTimeTopic(m.AgentName, "ntpStatusTicker", start,
+				warningTime, errorTime)
+
 		case <-m.geoTimer.C:
 			start := time.Now()
 			m.Log.Trace("GeoTimer at", start)
@@ -379,7 +441,8 @@ func (m *DpcManager) run(ctx context.Context) {
 					}
 				}
 				m.updateDNS()
-			case netmonitor.DNSInfoChange:
+			case netmonitor.DNSInfoChange, netmonitor.DHCPInfoChange,
+				netmonitor.NeighborsChange:
 				m.updateDNS()
 			}
 
@@ -393,6 +456,7 @@ func (m *DpcManager) run(ctx context.Context) {
 				m.Log.Warnf("Undefined event received from WwanWatcher")
 			case WwanEventNewStatus:
 				m.reloadWwanStatus()
+				m.resumeVerifyIfWwanReady(ctx)
 			case WwanEventNewMetrics:
 				m.reloadWwanMetrics()
 			case WwanEventNewLocationInfo:
@@ -411,9 +475,16 @@ func (m *DpcManager) run(ctx context.Context) {
 
 func (m *DpcManager) reconcilerArgs() dpcreconciler.Args {
 	args := dpcreconciler.Args{
-		GCP: m.globalCfg,
-		AA:  m.adapters,
-		RS:  m.radioSilence,
+		GCP:              m.globalCfg,
+		AA:               m.adapters,
+		RS:               m.radioSilence,
+		WlanAccessPoints: m.wlanAPs,
+	}
+	if len(m.apnProfileIdx) > 0 {
+		args.StartApnProfile = make(map[string]int)
+		for label, idx := range m.apnProfileIdx {
+			args.StartApnProfile[label] = idx
+		}
 	}
 	if m.currentDPC() != nil {
 		args.DPC = *m.currentDPC()
@@ -469,6 +540,27 @@ func (m *DpcManager) UpdateRadioSilence(rs types.RadioSilence) {
 	}
 }
 
+// UpdateWlanAccessPoints : update the set of wireless adapters (referenced
+// by logical labels) operating as access points for local network instances.
+// Radio transmission has to be enabled for these adapters (unless radio
+// silence is imposed) even if they are not used as device ports.
+func (m *DpcManager) UpdateWlanAccessPoints(adapters []string) {
+	m.inputCommands <- inputCommand{
+		cmd:     commandUpdateWlanAPs,
+		wlanAPs: adapters,
+	}
+}
+
+// UpdateZedcloudMetrics : process zedcloud metrics published by the given
+// microservice. These are used to estimate link quality of device ports.
+func (m *DpcManager) UpdateZedcloudMetrics(source string, metrics types.MetricsMap) {
+	m.inputCommands <- inputCommand{
+		cmd:           commandUpdateMetrics,
+		metrics:       metrics,
+		metricsSource: source,
+	}
+}
+
 // GetDNS returns device network state information.
 func (m *DpcManager) GetDNS() types.DeviceNetworkStatus {
 	return m.deviceNetStatus
@@ -564,3 +656,12 @@ func (m *DpcManager) resumeVerifyIfAsyncDone(ctx context.Context) {
 		}
 	}
 }
+
+func (m *DpcManager) resumeVerifyIfWwanReady(ctx context.Context) {
+	if dpc := m.currentDPC(); dpc != nil {
+		if dpc.State == types.DPCStateWwanWait && m.wwanConfigApplied() {
+			// The next APN profile was applied, continue verification.
+			m.runVerify(ctx, "wwan config was applied")
+		}
+	}
+}
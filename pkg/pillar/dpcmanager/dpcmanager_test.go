@@ -39,7 +39,9 @@ var (
 	networkMonitor  *netmonitor.MockNetworkMonitor
 	wwanWatcher     *MockWwanWatcher
 	geoService      *MockGeoService
+	ntpMonitor      *MockNTPMonitor
 	dpcReconciler   *dpcrec.LinuxDpcReconciler
+	dot1xReconciler *MockDot1xReconciler
 	dpcManager      *dpcmngr.DpcManager
 	connTester      *conntester.MockConnectivityTester
 	pubDummyDPC     pubsub.Publication // for logging
@@ -48,6 +50,7 @@ var (
 	pubWwwanStatus  pubsub.Publication
 	pubWwwanMetrics pubsub.Publication
 	pubWwanLocInfo  pubsub.Publication
+	pubNTPStatus    pubsub.Publication
 )
 
 func initTest(test *testing.T) *GomegaWithT {
@@ -110,6 +113,14 @@ func initTest(test *testing.T) *GomegaWithT {
 	if err != nil {
 		log.Fatal(err)
 	}
+	pubNTPStatus, err = ps.NewPublication(
+		pubsub.PublicationOptions{
+			AgentName: "test",
+			TopicType: types.NTPStatus{},
+		})
+	if err != nil {
+		log.Fatal(err)
+	}
 	networkMonitor = &netmonitor.MockNetworkMonitor{
 		Log:    logObj,
 		MainRT: syscall.RT_TABLE_MAIN,
@@ -119,8 +130,10 @@ func initTest(test *testing.T) *GomegaWithT {
 		AgentName:      "test",
 		NetworkMonitor: networkMonitor,
 	}
+	dot1xReconciler = &MockDot1xReconciler{DpcReconciler: dpcReconciler}
 	wwanWatcher = &MockWwanWatcher{}
 	geoService = &MockGeoService{}
+	ntpMonitor = &MockNTPMonitor{}
 	connTester = &conntester.MockConnectivityTester{
 		TestDuration: 2 * time.Second,
 	}
@@ -130,9 +143,10 @@ func initTest(test *testing.T) *GomegaWithT {
 		AgentName:                "test",
 		WwanWatcher:              wwanWatcher,
 		GeoService:               geoService,
+		NTPMonitor:               ntpMonitor,
 		DpcMinTimeSinceFailure:   3 * time.Second,
 		NetworkMonitor:           networkMonitor,
-		DpcReconciler:            dpcReconciler,
+		DpcReconciler:            dot1xReconciler,
 		ConnTester:               connTester,
 		PubDummyDevicePortConfig: pubDummyDPC,
 		PubDevicePortConfigList:  pubDPCList,
@@ -140,6 +154,7 @@ func initTest(test *testing.T) *GomegaWithT {
 		PubWwanStatus:            pubWwwanStatus,
 		PubWwanMetrics:           pubWwwanMetrics,
 		PubWwanLocationInfo:      pubWwanLocInfo,
+		PubNTPStatus:             pubNTPStatus,
 		ZedcloudMetrics:          zedcloud.NewAgentMetrics(),
 	}
 	ctx := reconciler.MockRun(context.Background())
@@ -1067,6 +1082,170 @@ func TestDNS(test *testing.T) {
 	t.Expect(eth1State.DefaultRouters[0].String()).To(Equal("172.20.1.1"))
 }
 
+func TestPortNeighbors(test *testing.T) {
+	t := initTest(test)
+
+	// Prepare simulated network stack.
+	eth0 := mockEth0()
+	eth1 := mockEth1()
+	networkMonitor.AddOrUpdateInterface(eth0)
+	networkMonitor.AddOrUpdateInterface(eth1)
+	networkMonitor.UpdateRoutes(append(mockEth0Routes(), mockEth1Routes()...))
+
+	// Apply global config first.
+	dpcManager.UpdateGCP(globalConfig())
+
+	// Apply DPC with single ethernet port.
+	aa := makeAA(selectedIntfs{eth0: true, eth1: true})
+	timePrio1 := time.Now()
+	dpc := makeDPC("zedagent", timePrio1, selectedIntfs{eth0: true})
+	dpcManager.UpdateAA(aa)
+	dpcManager.AddDPC(dpc)
+	t.Eventually(dpcStateCb(0)).Should(Equal(types.DPCStateSuccess))
+	t.Expect(getDNS().Ports).To(HaveLen(1))
+	t.Expect(getDNS().Ports[0].Neighbors).To(BeEmpty())
+	t.Expect(networkMonitor.IsNeighborDiscoveryRunning(eth0.Attrs.IfIndex)).To(BeTrue())
+
+	// Simulate switch advertising itself using LLDP.
+	now := time.Now()
+	eth0.Neighbors = []types.PortNeighbor{
+		{
+			Protocol:   types.NeighborProtocolLLDP,
+			ChassisID:  "00:11:22:33:44:55",
+			PortID:     "Gi1/0/12",
+			SystemName: "plant-switch-3",
+			PortVLAN:   100,
+			MgmtAddrs:  []net.IP{net.ParseIP("10.1.2.3")},
+			LastSeen:   now,
+			ExpiresAt:  now.Add(2 * time.Minute),
+		},
+	}
+	networkMonitor.AddOrUpdateInterface(eth0)
+	t.Eventually(func() []types.PortNeighbor {
+		return getDNS().Ports[0].Neighbors
+	}).Should(HaveLen(1))
+	neighbor := getDNS().Ports[0].Neighbors[0]
+	t.Expect(neighbor.SystemName).To(Equal("plant-switch-3"))
+	t.Expect(neighbor.PortID).To(Equal("Gi1/0/12"))
+	t.Expect(neighbor.PortVLAN).To(BeEquivalentTo(100))
+
+	// Neighbor has expired.
+	eth0.Neighbors = nil
+	networkMonitor.AddOrUpdateInterface(eth0)
+	t.Eventually(func() []types.PortNeighbor {
+		return getDNS().Ports[0].Neighbors
+	}).Should(BeEmpty())
+
+	// Discovery is stopped once the port is removed from DPC.
+	timePrio2 := time.Now()
+	dpc = makeDPC("zedagent", timePrio2, selectedIntfs{eth1: true})
+	dpcManager.AddDPC(dpc)
+	t.Eventually(dpcTimePrioCb(0, timePrio2)).Should(BeTrue())
+	t.Eventually(func() bool {
+		return networkMonitor.IsNeighborDiscoveryRunning(eth0.Attrs.IfIndex)
+	}).Should(BeFalse())
+	t.Eventually(func() bool {
+		return networkMonitor.IsNeighborDiscoveryRunning(eth1.Attrs.IfIndex)
+	}).Should(BeTrue())
+}
+
+func TestDot1xFailure(test *testing.T) {
+	t := initTest(test)
+
+	// Prepare simulated network stack.
+	eth0 := mockEth0()
+	networkMonitor.AddOrUpdateInterface(eth0)
+	networkMonitor.UpdateRoutes(mockEth0Routes())
+
+	// Apply global config first.
+	dpcManager.UpdateGCP(globalConfig())
+
+	// Apply DPC with single ethernet port using 802.1X, which is rejected.
+	dot1xReconciler.SetDot1xStatus("eth0", types.Dot1xStatus{
+		State: types.Dot1xAuthStateFailed,
+		Error: "EAP-TLS rejected by the authenticator",
+	})
+	aa := makeAA(selectedIntfs{eth0: true})
+	timePrio1 := time.Now()
+	dpc := makeDPC("zedagent", timePrio1, selectedIntfs{eth0: true})
+	dpc.Ports[0].Dot1x = types.Dot1xConfig{
+		EAPMethod: types.Dot1xEAPMethodTLS,
+		Identity:  "device-1234",
+	}
+	connTester.SetConnectivityError("zedagent", "eth0",
+		errors.New("failed to connect"))
+	dpcManager.UpdateAA(aa)
+	dpcManager.AddDPC(dpc)
+
+	// The reason for the failure is recorded in DPC status.
+	t.Eventually(dpcStateCb(0)).Should(Equal(types.DPCStateFail))
+	dpc = getDPC(0)
+	t.Expect(dpc.LastError).To(Equal(
+		"eth0: 802.1X authentication failed: EAP-TLS rejected by the authenticator"))
+	eth0Port := dpc.LookupPortByIfName("eth0")
+	t.Expect(eth0Port).ToNot(BeNil())
+	t.Expect(eth0Port.LastError).To(ContainSubstring("802.1X authentication failed"))
+}
+
+func TestClockSkew(test *testing.T) {
+	t := initTest(test)
+
+	// Prepare simulated network stack.
+	eth0 := mockEth0()
+	networkMonitor.AddOrUpdateInterface(eth0)
+
+	// Device clock is not synchronized and the controller certificate
+	// is rejected as not yet valid.
+	ntpMonitor.SetNTPStatus(types.NTPStatus{
+		State:  types.NTPSyncStateUnsynchronized,
+		Source: "ntpd",
+	})
+	now := time.Date(1970, 1, 1, 0, 5, 0, 0, time.UTC)
+	certErr := &types.CertTimeInvalid{
+		IfName:    "eth0",
+		Subject:   "CN=zedcloud.local",
+		NotBefore: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Now:       now,
+	}
+	connTester.SetConnectivityError("zedagent", "eth0",
+		&conntester.RemoteTemporaryFailure{
+			Endpoint:        "fake-url",
+			WrappedErr:      certErr,
+			CertTimeInvalid: certErr,
+		})
+
+	// Apply global config.
+	dpcManager.UpdateGCP(globalConfig())
+
+	// Apply DPC with single ethernet port and NTP servers.
+	aa := makeAA(selectedIntfs{eth0: true, eth1: true})
+	timePrio1 := time.Now()
+	dpc := makeDPC("zedagent", timePrio1, selectedIntfs{eth0: true})
+	dpc.NTPServers = []string{"ntp.plant.example.com", "10.10.0.1"}
+	dpcManager.UpdateAA(aa)
+	dpcManager.AddDPC(dpc)
+
+	// Network config is not to blame, but the failure should be flagged.
+	t.Eventually(testingInProgressCb()).Should(BeTrue())
+	t.Eventually(testingInProgressCb()).Should(BeFalse())
+	t.Eventually(dpcIdxCb()).Should(Equal(0))
+	t.Eventually(dpcStateCb(0)).Should(Equal(types.DPCStateRemoteWait))
+	_, dpcList := getDPCList()
+	t.Expect(dpcList).To(HaveLen(1))
+	t.Expect(dpcList[0].LastError).To(ContainSubstring("device clock is probably skewed"))
+	t.Expect(dpcList[0].LastError).To(ContainSubstring("NTP state: unsynchronized"))
+
+	// NTP servers and the synchronization status are published.
+	t.Eventually(func() []string { return getDNS().NTPServers }).Should(
+		Equal([]string{"ntp.plant.example.com", "10.10.0.1"}))
+	item, err := pubNTPStatus.Get("global")
+	t.Expect(err).ToNot(HaveOccurred())
+	ntpStatus := item.(types.NTPStatus)
+	t.Expect(ntpStatus.State).To(Equal(types.NTPSyncStateUnsynchronized))
+	t.Expect(ntpStatus.Source).To(Equal("ntpd"))
+}
+
 func TestWireless(test *testing.T) {
 	t := initTest(test)
 
@@ -1121,7 +1300,7 @@ func TestWireless(test *testing.T) {
 				PhysAddrs: types.WwanPhysAddrs{
 					Interface: "wwan0",
 				},
-				Apns:             []string{"apn"},
+				ApnProfiles:      []types.WwanApnProfile{{APN: "apn"}},
 				LocationTracking: true,
 			},
 		},
@@ -1301,6 +1480,67 @@ func TestWireless(test *testing.T) {
 	t.Expect(itemDescription(wwan)).To(ContainSubstring("RadioSilence:true"))
 }
 
+func TestApnFailover(test *testing.T) {
+	t := initTest(test)
+
+	// Prepare simulated network stack.
+	wwan0 := mockWwan0()
+	wwan0.IPAddrs = nil
+	networkMonitor.AddOrUpdateInterface(wwan0)
+
+	// Apply global config first.
+	dpcManager.UpdateGCP(globalConfig())
+
+	// Apply DPC with cellular port having two APN profiles.
+	aa := makeAA(selectedIntfs{wwan0: true})
+	dpc := makeDPC("zedagent", time.Now(), selectedIntfs{wwan0: true})
+	dpc.Ports[0].WirelessCfg.Cellular = append(dpc.Ports[0].WirelessCfg.Cellular,
+		types.CellConfig{APN: "backup-apn"})
+	dpcManager.UpdateAA(aa)
+	dpcManager.AddDPC(dpc)
+
+	// Verification will wait for IP address and then fail over
+	// to the second APN profile.
+	wwan := dg.Reference(generic.Wwan{})
+	t.Eventually(testingInProgressCb()).Should(BeTrue())
+	t.Eventually(dpcStateCb(0)).Should(Equal(types.DPCStateIPDNSWait))
+	t.Expect(itemDescription(wwan)).To(ContainSubstring("StartProfile:0"))
+	t.Eventually(dpcStateCb(0)).Should(Equal(types.DPCStateWwanWait))
+	t.Expect(itemDescription(wwan)).To(ContainSubstring("StartProfile:1"))
+	t.Expect(testingInProgressCb()()).To(BeTrue())
+
+	// Simulate working connectivity with the second APN profile.
+	wwan0 = mockWwan0() // with IP
+	networkMonitor.AddOrUpdateInterface(wwan0)
+	expectedWwanConfig := types.WwanConfig{
+		Networks: []types.WwanNetworkConfig{
+			{
+				LogicalLabel: "mock-wwan0",
+				PhysAddrs: types.WwanPhysAddrs{
+					Interface: "wwan0",
+				},
+				ApnProfiles: []types.WwanApnProfile{
+					{APN: "apn"}, {APN: "backup-apn"},
+				},
+				StartProfile:     1,
+				LocationTracking: true,
+			},
+		},
+	}
+	_, wwanCfgHash, err := generic.MarshalWwanConfig(expectedWwanConfig)
+	t.Expect(err).To(BeNil())
+	wwan0Status := mockWwan0Status()
+	wwan0Status.ConfigChecksum = wwanCfgHash
+	wwan0Status.Networks[0].ActiveProfile = types.WwanApnProfile{APN: "backup-apn"}
+	wwan0Status.Networks[0].ActiveProfileIdx = 1
+	wwanWatcher.UpdateStatus(wwan0Status)
+	t.Eventually(testingInProgressCb()).Should(BeFalse())
+	t.Expect(getDPC(0).State).To(Equal(types.DPCStateSuccess))
+	wwanDNS := wirelessStatusFromDNS(types.WirelessTypeCellular)
+	t.Expect(wwanDNS.Cellular.ActiveProfile.APN).To(Equal("backup-apn"))
+	t.Expect(wwanDNS.Cellular.ActiveProfileIdx).To(Equal(1))
+}
+
 func TestAddDPCDuringVerify(test *testing.T) {
 	t := initTest(test)
 
@@ -1470,10 +1710,10 @@ func TestDPCWithReleasedAndRenamedInterface(test *testing.T) {
 
 	// Until AA is updated, DpcManager should ignore the interface
 	// and not configure anything for it.
-	eth0Dhcpcd := dg.Reference(generic.Dhcpcd{AdapterIfName: "eth0"})
-	eth1Dhcpcd := dg.Reference(generic.Dhcpcd{AdapterIfName: "eth1"})
-	t.Consistently(itemIsCreatedCb(eth0Dhcpcd)).Should(BeFalse())
-	t.Consistently(itemIsCreatedCb(eth1Dhcpcd)).Should(BeFalse())
+	eth0DhcpClient := dg.Reference(generic.DhcpClient{AdapterIfName: "eth0"})
+	eth1DhcpClient := dg.Reference(generic.DhcpClient{AdapterIfName: "eth1"})
+	t.Consistently(itemIsCreatedCb(eth0DhcpClient)).Should(BeFalse())
+	t.Consistently(itemIsCreatedCb(eth1DhcpClient)).Should(BeFalse())
 	dns := getDNS()
 	t.Expect(dns.Ports).To(HaveLen(2))
 	t.Expect(dns.Ports[0].Up).To(BeFalse())
@@ -1485,7 +1725,7 @@ func TestDPCWithReleasedAndRenamedInterface(test *testing.T) {
 	networkMonitor.AddOrUpdateInterface(eth1)
 	aa.IoBundleList[1].IsPCIBack = false
 	dpcManager.UpdateAA(aa)
-	t.Eventually(itemIsCreatedCb(eth1Dhcpcd)).Should(BeTrue())
+	t.Eventually(itemIsCreatedCb(eth1DhcpClient)).Should(BeTrue())
 
 	// Simulate event of eth1 receiving IP addresses.
 	eth1 = mockEth1()                             // with IPs
@@ -1896,6 +2136,6 @@ func TestOldDPC(test *testing.T) {
 
 	// DPC manager should have applied L3 config for eth0 even if it was
 	// not marked as L3 port in the old DPC.
-	eth0Dhcpcd := dg.Reference(generic.Dhcpcd{AdapterIfName: "eth0"})
-	t.Expect(itemIsCreated(eth0Dhcpcd)).To(BeTrue())
+	eth0DhcpClient := dg.Reference(generic.DhcpClient{AdapterIfName: "eth0"})
+	t.Expect(itemIsCreated(eth0DhcpClient)).To(BeTrue())
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/eriknordmark/ipinfo"
 
 	dpcmngr "github.com/lf-edge/eve/pkg/pillar/dpcmanager"
+	dpcrec "github.com/lf-edge/eve/pkg/pillar/dpcreconciler"
 	"github.com/lf-edge/eve/pkg/pillar/types"
 )
 
@@ -127,3 +128,62 @@ func (m *MockGeoService) GetGeolocationInfo(ipAddr net.IP) (*ipinfo.IPInfo, erro
 	geoInfo := m.geoInfo[ipAddr.String()]
 	return geoInfo, nil
 }
+
+// MockNTPMonitor allows to simulate the status of the clock synchronization.
+// Can be injected to DpcManager.NTPMonitor in UTs.
+type MockNTPMonitor struct {
+	sync.Mutex
+	status types.NTPStatus
+}
+
+// SetNTPStatus : set simulated status of the clock synchronization.
+func (m *MockNTPMonitor) SetNTPStatus(status types.NTPStatus) {
+	m.Lock()
+	defer m.Unlock()
+	m.status = status
+}
+
+// GetNTPStatus returns the last status submitted via SetNTPStatus.
+func (m *MockNTPMonitor) GetNTPStatus() (types.NTPStatus, error) {
+	m.Lock()
+	defer m.Unlock()
+	return m.status, nil
+}
+
+// MockDot1xReconciler wraps DpcReconciler to simulate the state of 802.1X
+// authentication of wired ports.
+// Can be injected to DpcManager.DpcReconciler in UTs.
+type MockDot1xReconciler struct {
+	sync.Mutex
+	dpcrec.DpcReconciler
+	dot1x map[string]types.Dot1xStatus
+}
+
+// SetDot1xStatus : simulate the state of 802.1X authentication of the port.
+// It is reported only if the port has 802.1X enabled.
+func (m *MockDot1xReconciler) SetDot1xStatus(ifName string, status types.Dot1xStatus) {
+	m.Lock()
+	defer m.Unlock()
+	if m.dot1x == nil {
+		m.dot1x = make(map[string]types.Dot1xStatus)
+	}
+	m.dot1x[ifName] = status
+}
+
+// Reconcile : reconcile using the wrapped DpcReconciler and override
+// the state of 802.1X authentication.
+func (m *MockDot1xReconciler) Reconcile(
+	ctx context.Context, args dpcrec.Args) dpcrec.ReconcileStatus {
+	status := m.DpcReconciler.Reconcile(ctx, args)
+	m.Lock()
+	defer m.Unlock()
+	dot1x := make(map[string]types.Dot1xStatus)
+	for ifName, dot1xStatus := range status.Dot1x {
+		if mockStatus, simulated := m.dot1x[ifName]; simulated {
+			dot1xStatus = mockStatus
+		}
+		dot1x[ifName] = dot1xStatus
+	}
+	status.Dot1x = dot1x
+	return status
+}
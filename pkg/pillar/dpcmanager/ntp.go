@@ -0,0 +1,73 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package dpcmanager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/lf-edge/eve/pkg/pillar/conntester"
+	"github.com/lf-edge/eve/pkg/pillar/devicenetwork"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+const (
+	// How often to refresh the status of the device clock synchronization.
+	ntpStatusInterval = time.Minute
+)
+
+type ntpMonitor struct {
+	log *base.LogObject
+}
+
+// GetNTPStatus returns the status of the device clock synchronization
+// as reported by the NTP client running in the host.
+func (n *ntpMonitor) GetNTPStatus() (types.NTPStatus, error) {
+	return devicenetwork.GetNTPStatus(n.log)
+}
+
+// updateNTPStatus obtains the latest status of the clock synchronization
+// and publishes it if anything has changed.
+func (m *DpcManager) updateNTPStatus() {
+	status, err := m.NTPMonitor.GetNTPStatus()
+	if err != nil {
+		m.Log.Warnf("Failed to get NTP status: %v", err)
+		status = types.NTPStatus{State: types.NTPSyncStateUnknown}
+	}
+	if status.Equal(m.ntpStatus) {
+		return
+	}
+	if status.State != m.ntpStatus.State {
+		m.Log.Noticef("Device clock synchronization state changed from %s to %s "+
+			"(source: %s, stratum: %d, offset: %v)", m.ntpStatus.State, status.State,
+			status.Source, status.Stratum, status.Offset)
+	}
+	m.ntpStatus = status
+	if m.PubNTPStatus != nil {
+		if err = m.PubNTPStatus.Publish(status.Key(), status); err != nil {
+			m.Log.Errorf("Failed to publish NTP status: %v", err)
+		}
+	}
+}
+
+// checkClockSkew returns non-empty error message if the connectivity test
+// failed because the controller certificate was rejected due to a skewed
+// device clock (and not because the certificate is really expired).
+func (m *DpcManager) checkClockSkew(testErr error) string {
+	rtfErr, isRtf := testErr.(*conntester.RemoteTemporaryFailure)
+	if !isRtf || rtfErr.CertTimeInvalid == nil {
+		return ""
+	}
+	certErr := rtfErr.CertTimeInvalid
+	// Refresh the NTP status to make the decision based on the current state.
+	m.updateNTPStatus()
+	if !certErr.NotYetValid() &&
+		m.ntpStatus.State == types.NTPSyncStateSynchronized {
+		// Device clock is synchronized, the certificate is really expired.
+		return ""
+	}
+	return fmt.Sprintf("Controller certificate was rejected, device clock is "+
+		"probably skewed (NTP state: %s): %v", m.ntpStatus.State, certErr)
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package dpcmanager
+
+import (
+	"time"
+
+	"github.com/lf-edge/eve/pkg/pillar/portquality"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+const (
+	// How often to re-evaluate link quality of ports.
+	portQualityInterval = time.Minute
+	// DNS is re-published when the score of a port changes at least by this much.
+	// Smaller changes are published together with other DNS updates.
+	portQualityPublishThreshold = 5
+)
+
+func (m *DpcManager) ingestPortQualityHistory() {
+	var history types.PortQualityHistory
+	if m.PubPortQualityHistory != nil {
+		item, err := m.PubPortQualityHistory.Get("global")
+		if err == nil {
+			history = item.(types.PortQualityHistory)
+			m.Log.Noticef("Loaded port quality history for %d ports",
+				len(history.Ports))
+		}
+	}
+	m.portQuality = portquality.NewEstimator(history)
+}
+
+// updatePortQuality re-evaluates link quality of ports using the latest
+// zedcloud metrics. DNS is re-published only if there was a significant change.
+func (m *DpcManager) updatePortQuality() {
+	now := time.Now()
+	if m.ZedcloudMetrics != nil {
+		// Include connectivity tests run by NIM.
+		m.portQuality.UpdateMetrics(m.AgentName,
+			m.ZedcloudMetrics.GetMetrics(m.Log), now)
+	}
+	var changed bool
+	for i := range m.deviceNetStatus.Ports {
+		port := &m.deviceNetStatus.Ports[i]
+		quality := m.portQuality.Quality(port.IfName)
+		if portQualityChanged(port.Quality, quality) {
+			m.Log.Functionf("Link quality of port %s changed from %d to %d "+
+				"(latency: %dms, loss: %d%%, throughput: %dkbps)", port.IfName,
+				port.Quality.Score, quality.Score, quality.LatencyMs,
+				quality.LossPercent, quality.ThroughputKbps)
+			changed = true
+		}
+	}
+	if changed {
+		for i := range m.deviceNetStatus.Ports {
+			port := &m.deviceNetStatus.Ports[i]
+			port.Quality = m.portQuality.Quality(port.IfName)
+		}
+		m.publishDNS()
+	}
+	if m.portQuality.RecordHistory(now) && m.PubPortQualityHistory != nil {
+		err := m.PubPortQualityHistory.Publish("global", m.portQuality.History())
+		if err != nil {
+			m.Log.Errorf("failed to publish port quality history: %v", err)
+		}
+	}
+}
+
+func portQualityChanged(prev, next types.PortQuality) bool {
+	if prev.IsKnown() != next.IsKnown() {
+		return true
+	}
+	diff := int(prev.Score) - int(next.Score)
+	return diff >= portQualityPublishThreshold || -diff >= portQualityPublishThreshold
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/lf-edge/eve/pkg/pillar/conntester"
@@ -63,6 +64,7 @@ func (m *DpcManager) setupVerify(index int, reason string) {
 	m.dpcList.CurrentIndex = index
 	m.dpcVerify.inProgress = true
 	m.dpcVerify.startedAt = time.Now()
+	m.dpcVerify.apnFailovers = make(map[string]int)
 	m.Log.Functionf("DPC verify: Started testing DPC (index %d): %v",
 		m.dpcList.CurrentIndex, m.dpcList.PortConfigList[m.dpcList.CurrentIndex])
 }
@@ -104,6 +106,9 @@ func (m *DpcManager) runVerify(ctx context.Context, reason string) {
 			// Configuration is not completely applied, some operations are still
 			// running in the background.
 			// Wait until we hear from DPC reconciler or until PendTimer triggers.
+			types.DPCStateWwanWait,
+			// Next APN profile of a cellular port is being applied.
+			// Wait until we hear from wwan microservice or until PendTimer triggers.
 			types.DPCStatePCIWait,
 			// verifyDPC has already published the new DNS for domainmgr.
 			// Wait until we hear from domainmgr or until PendTimer triggers.
@@ -124,6 +129,13 @@ func (m *DpcManager) runVerify(ctx context.Context, reason string) {
 				continue
 			}
 
+			// Before giving up on this DPC, try other APN profiles
+			// of cellular ports.
+			if m.failoverApnProfiles(ctx) {
+				m.pendingDpcTimer = time.NewTimer(m.dpcTestDuration)
+				return
+			}
+
 			// Move to next index (including wrap around).
 			// Skip entries with LastFailed after LastSucceeded and a recent LastFailed
 			// (a minute or less).
@@ -169,7 +181,7 @@ func (m *DpcManager) runVerify(ctx context.Context, reason string) {
 
 	// Did we get a new DPC at index zero?
 	if m.dpcList.PortConfigList[0].IsDPCUntested() {
-		m.Log.Warn("DPC verify: %v: New DPC arrived "+
+		m.Log.Warnf("DPC verify: %v: New DPC arrived "+
 			"or a old working DPC moved up to top of DPC list while network testing "+
 			"was in progress. Restarting DPC verification.", res)
 		m.restartVerify(ctx, "runVerify "+res.String())
@@ -224,6 +236,15 @@ func (m *DpcManager) verifyDPC(ctx context.Context) (status types.DPCState) {
 			"canceling them and continue with verification", elapsed)
 		m.reconcileStatus.CancelAsyncOps()
 	}
+	if dpc.State == types.DPCStateWwanWait && !m.wwanConfigApplied() {
+		if elapsed < waitForIPDNSRetries*m.dpcTestDuration {
+			status = types.DPCStateWwanWait
+			dpc.State = status
+			return status
+		}
+		m.Log.Warnf("DPC verify: wwan config was not applied (waited for %v), "+
+			"continue with verification", elapsed)
+	}
 
 	// Check cloud connectivity.
 	m.updateDNS()
@@ -250,7 +271,13 @@ func (m *DpcManager) verifyDPC(ctx context.Context) (status types.DPCState) {
 
 	_, rtf := err.(*conntester.RemoteTemporaryFailure)
 	if rtf {
-		m.Log.Errorf("DPC verify: remoteTemporaryFailure: %v", err)
+		if errStr := m.checkClockSkew(err); errStr != "" {
+			// Not a problem of the network configuration, but make it visible.
+			m.Log.Errorf("DPC verify: %s", errStr)
+			dpc.RecordFailure(errStr)
+		} else {
+			m.Log.Errorf("DPC verify: remoteTemporaryFailure: %v", err)
+		}
 		// NOTE: We retry until the certificate or ECONNREFUSED is fixed
 		// on the server side.
 		status = types.DPCStateRemoteWait
@@ -304,6 +331,37 @@ func (m *DpcManager) verifyDPC(ctx context.Context) (status types.DPCState) {
 		return status
 	}
 
+	// Check the state of 802.1X authentication.
+	authPending, authFailed := m.checkMgmtPortsDot1x(availablePorts)
+	for ifName, errStr := range authFailed {
+		m.Log.Warnf("DPC verify: %s: %s", ifName, errStr)
+		dpc.RecordPortFailure(ifName, errStr)
+	}
+	if len(authFailed) == len(availablePorts) {
+		var authErrs []string
+		for _, ifName := range availablePorts {
+			authErrs = append(authErrs, fmt.Sprintf("%s: %s", ifName, authFailed[ifName]))
+		}
+		authErr := strings.Join(authErrs, "; ")
+		m.Log.Errorf("DPC verify: 802.1X authentication failed for all "+
+			"available mgmt ports (waited for %v): %s for %+v\n", elapsed, authErr, dpc)
+		dpc.RecordFailure(authErr)
+		status = types.DPCStateFail
+		dpc.State = status
+		return status
+	}
+	if len(authPending) > 0 {
+		if elapsed < waitForIPDNSRetries*m.dpcTestDuration {
+			m.Log.Noticef("DPC verify: 802.1X authentication of ports %v "+
+				"is in progress: will retry (waiting for %v)", authPending, elapsed)
+			status = types.DPCStateIPDNSWait
+			dpc.State = status
+			return status
+		}
+		m.Log.Warnf("DPC verify: 802.1X authentication of ports %v "+
+			"did not complete (waited for %v)", authPending, elapsed)
+	}
+
 	// Check for the availability of IP configuration.
 	if !m.checkIfMgmtPortsHaveIPandDNS() {
 		// Still waiting for IP or DNS.
@@ -354,6 +412,83 @@ func (m *DpcManager) verifyDPC(ctx context.Context) (status types.DPCState) {
 	return status
 }
 
+// checkMgmtPortsDot1x returns management ports (from the given list of available
+// ports) with 802.1X authentication still in progress and those for which
+// the authentication has failed (mapped to the error message).
+func (m *DpcManager) checkMgmtPortsDot1x(
+	availablePorts []string) (pending []string, failed map[string]string) {
+	failed = make(map[string]string)
+	available := make(map[string]struct{})
+	for _, ifName := range availablePorts {
+		available[ifName] = struct{}{}
+	}
+	for _, port := range m.deviceNetStatus.Ports {
+		if !port.IsMgmt || port.Dot1xStatus.State == types.Dot1xAuthStateNone {
+			continue
+		}
+		if _, isAvailable := available[port.IfName]; !isAvailable {
+			continue
+		}
+		switch port.Dot1xStatus.State {
+		case types.Dot1xAuthStateAuthenticated:
+		case types.Dot1xAuthStateFailed:
+			failed[port.IfName] = fmt.Sprintf("802.1X authentication failed: %s",
+				port.Dot1xStatus.Error)
+		default:
+			pending = append(pending, port.IfName)
+		}
+	}
+	return pending, failed
+}
+
+// failoverApnProfiles switches every management cellular port of the current DPC
+// with multiple APN profiles to the next profile, unless all profiles were already
+// tried during this verification. Returns true if the wwan config has changed.
+func (m *DpcManager) failoverApnProfiles(ctx context.Context) bool {
+	dpc := m.currentDPC()
+	var failover bool
+	for _, port := range dpc.Ports {
+		profiles := len(port.WirelessCfg.Cellular)
+		if !port.IsMgmt || port.WirelessCfg.WType != types.WirelessTypeCellular ||
+			profiles < 2 {
+			continue
+		}
+		label := port.Logicallabel
+		if _, exists, _ := m.NetworkMonitor.GetInterfaceIndex(port.IfName); !exists {
+			continue
+		}
+		if m.dpcVerify.apnFailovers[label] >= profiles-1 {
+			m.Log.Warnf("DPC verify: all APN profiles of port %s have failed", label)
+			continue
+		}
+		next := m.apnProfileIdx[label] + 1
+		netStatus, found := m.wwanStatus.LookupNetworkStatus(label)
+		if found && netStatus.Module.OpMode == types.WwanOpModeConnected {
+			// Modem may have connected using other than the start profile.
+			next = netStatus.ActiveProfileIdx + 1
+		}
+		next %= profiles
+		m.Log.Noticef("DPC verify: failing over port %s to APN profile %d (%s)",
+			label, next, port.WirelessCfg.Cellular[next].APN)
+		m.apnProfileIdx[label] = next
+		m.dpcVerify.apnFailovers[label]++
+		failover = true
+	}
+	if !failover {
+		return false
+	}
+	m.dpcVerify.startedAt = time.Now()
+	dpc.State = types.DPCStateWwanWait
+	m.reconcileStatus = m.DpcReconciler.Reconcile(ctx, m.reconcilerArgs())
+	return true
+}
+
+// wwanConfigApplied returns true if the wwan status corresponds
+// to the last submitted wwan config.
+func (m *DpcManager) wwanConfigApplied() bool {
+	return m.wwanStatus.ConfigChecksum == m.reconcileStatus.RS.WwanConfigChecksum
+}
+
 func (m *DpcManager) testConnectivityToCloud(ctx context.Context) error {
 	dpc := m.currentDPC()
 	if dpc == nil {
@@ -405,7 +540,11 @@ func (m *DpcManager) testConnectivityToCloud(ctx context.Context) error {
 			// The fact that cloud replied with a status code shows that the cloud is UP,
 			// but not functioning fully at this time. So, we mark the cloud connectivity
 			// as UP for now.
-			m.Log.Warnf("testConnectivityToCloud: remoteTemporaryFailure: %v", err)
+			if errStr := m.checkClockSkew(err); errStr != "" {
+				m.Log.Errorf("testConnectivityToCloud: %s", errStr)
+			} else {
+				m.Log.Warnf("testConnectivityToCloud: remoteTemporaryFailure: %v", err)
+			}
 			m.dpcVerify.cloudConnWorks = true
 			return nil
 		} else {
@@ -5,63 +5,99 @@ package dpcmanager
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"os"
 	"reflect"
 	"strings"
 	"time"
 
-	"github.com/fsnotify/fsnotify"
 	"github.com/lf-edge/eve/pkg/pillar/base"
-	"github.com/lf-edge/eve/pkg/pillar/devicenetwork"
+	"github.com/lf-edge/eve/pkg/pillar/pubsub"
 	"github.com/lf-edge/eve/pkg/pillar/types"
 )
 
+const (
+	// wwanAgentName : name of the wwan microservice.
+	wwanAgentName = "wwan"
+	// Time limits for event handlers of wwan subscriptions.
+	wwanWarningTime = 40 * time.Second
+	wwanErrorTime   = 3 * time.Minute
+)
+
+// wwanWatcher subscribes to output published by wwan microservice.
 type wwanWatcher struct {
-	Log *base.LogObject
+	Log       *base.LogObject
+	PubSub    *pubsub.PubSub
+	AgentName string
+
+	events         chan WwanEvent
+	subStatus      pubsub.Subscription
+	subMetrics     pubsub.Subscription
+	subLocationInf pubsub.Subscription
 }
 
 func (w *wwanWatcher) Watch(ctx context.Context) (<-chan WwanEvent, error) {
-	fsw, err := fsnotify.NewWatcher()
-	if err != nil {
-		err = fmt.Errorf("failed to create fsnotify watcher for wwan dir: %w", err)
+	if w.PubSub == nil {
+		err := errors.New("wwan watcher requires pubsub")
 		w.Log.Error(err)
 		return nil, err
 	}
-	if err = w.createWwanDir(); err != nil {
-		_ = fsw.Close()
+	// Buffered to ensure that handlers will not block subscriptions
+	// while DpcManager is busy.
+	w.events = make(chan WwanEvent, 10)
+	var err error
+	w.subStatus, err = w.subscribe(types.WwanStatus{}, WwanEventNewStatus)
+	if err != nil {
 		return nil, err
 	}
-	if err = fsw.Add(devicenetwork.RunWwanDir); err != nil {
-		_ = fsw.Close()
+	w.subMetrics, err = w.subscribe(types.WwanMetrics{}, WwanEventNewMetrics)
+	if err != nil {
+		return nil, err
+	}
+	w.subLocationInf, err = w.subscribe(types.WwanLocationInfo{}, WwanEventNewLocationInfo)
+	if err != nil {
+		return nil, err
+	}
+	go w.runWatcher(ctx)
+	return w.events, nil
+}
+
+func (w *wwanWatcher) subscribe(topic interface{}, event WwanEvent) (pubsub.Subscription, error) {
+	sub, err := w.PubSub.NewSubscription(pubsub.SubscriptionOptions{
+		AgentName:   wwanAgentName,
+		MyAgentName: w.AgentName,
+		TopicImpl:   topic,
+		Activate:    false,
+		CreateHandler: func(ctxArg interface{}, key string, statusArg interface{}) {
+			w.events <- event
+		},
+		ModifyHandler: func(ctxArg interface{}, key string, statusArg, oldStatusArg interface{}) {
+			w.events <- event
+		},
+		WarningTime: wwanWarningTime,
+		ErrorTime:   wwanErrorTime,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to subscribe for %T from %s: %w",
+			topic, wwanAgentName, err)
+		w.Log.Error(err)
+		return nil, err
+	}
+	if err = sub.Activate(); err != nil {
 		return nil, err
 	}
-	// Buffered to ensure that runWatcher will not miss fsnotify
-	// notification due to a delayed send.
-	sub := make(chan WwanEvent, 10)
-	go w.runWatcher(ctx, fsw, sub)
 	return sub, nil
 }
 
-func (w *wwanWatcher) runWatcher(ctx context.Context, fsw *fsnotify.Watcher,
-	sub chan WwanEvent) {
+func (w *wwanWatcher) runWatcher(ctx context.Context) {
 	for {
 		select {
-		case event, ok := <-fsw.Events:
-			if !ok {
-				w.Log.Warnf("fsnotify watcher for wwan directory stopped")
-				return
-			}
-			switch event.Name {
-			case devicenetwork.WwanStatusPath:
-				sub <- WwanEventNewStatus
-			case devicenetwork.WwanMetricsPath:
-				sub <- WwanEventNewMetrics
-			case devicenetwork.WwanLocationPath:
-				sub <- WwanEventNewLocationInfo
-			}
+		case change := <-w.subStatus.MsgChan():
+			w.subStatus.ProcessChange(change)
+		case change := <-w.subMetrics.MsgChan():
+			w.subMetrics.ProcessChange(change)
+		case change := <-w.subLocationInf.MsgChan():
+			w.subLocationInf.ProcessChange(change)
 		case <-ctx.Done():
 			return
 		}
@@ -69,76 +105,30 @@ func (w *wwanWatcher) runWatcher(ctx context.Context, fsw *fsnotify.Watcher,
 }
 
 func (w *wwanWatcher) LoadStatus() (status types.WwanStatus, err error) {
-	statusFile, err := os.Open(devicenetwork.WwanStatusPath)
+	obj, err := w.subStatus.Get(status.Key())
 	if err != nil {
-		w.Log.Errorf("Failed to open file %s: %v", devicenetwork.WwanStatusPath, err)
+		w.Log.Errorf("Failed to get wwan status: %v", err)
 		return status, err
 	}
-	defer statusFile.Close()
-	statusBytes, err := ioutil.ReadAll(statusFile)
-	if err != nil {
-		w.Log.Errorf("Failed to read file %s: %v", devicenetwork.WwanStatusPath, err)
-		return status, err
-	}
-	err = json.Unmarshal(statusBytes, &status)
-	if err != nil {
-		w.Log.Errorf("Failed to unmarshall wwan status: %v", err)
-		return status, err
-	}
-	return status, nil
+	return obj.(types.WwanStatus), nil
 }
 
 func (w *wwanWatcher) LoadMetrics() (metrics types.WwanMetrics, err error) {
-	metricsFile, err := os.Open(devicenetwork.WwanMetricsPath)
-	if err != nil {
-		w.Log.Errorf("Failed to open file %s: %v", devicenetwork.WwanMetricsPath, err)
-		return metrics, err
-	}
-	defer metricsFile.Close()
-	metricsBytes, err := ioutil.ReadAll(metricsFile)
-	if err != nil {
-		w.Log.Errorf("Failed to read file %s: %v", devicenetwork.WwanMetricsPath, err)
-		return metrics, err
-	}
-	err = json.Unmarshal(metricsBytes, &metrics)
+	obj, err := w.subMetrics.Get(metrics.Key())
 	if err != nil {
-		w.Log.Errorf("Failed to unmarshall wwan metrics: %v", err)
+		w.Log.Errorf("Failed to get wwan metrics: %v", err)
 		return metrics, err
 	}
-	return metrics, nil
+	return obj.(types.WwanMetrics), nil
 }
 
 func (w *wwanWatcher) LoadLocationInfo() (locInfo types.WwanLocationInfo, err error) {
-	filepath := devicenetwork.WwanLocationPath
-	locFile, err := os.Open(filepath)
+	obj, err := w.subLocationInf.Get(locInfo.Key())
 	if err != nil {
-		w.Log.Errorf("Failed to open file %s: %v", filepath, err)
+		w.Log.Errorf("Failed to get wwan location info: %v", err)
 		return locInfo, err
 	}
-	defer locFile.Close()
-	locBytes, err := ioutil.ReadAll(locFile)
-	if err != nil {
-		w.Log.Errorf("Failed to read file %s: %v", filepath, err)
-		return locInfo, err
-	}
-	err = json.Unmarshal(locBytes, &locInfo)
-	if err != nil {
-		w.Log.Errorf("Failed to unmarshall wwan location data: %v", err)
-		return locInfo, err
-	}
-	return locInfo, nil
-}
-
-func (w *wwanWatcher) createWwanDir() error {
-	if _, err := os.Stat(devicenetwork.RunWwanDir); err != nil {
-		if err = os.MkdirAll(devicenetwork.RunWwanDir, 0700); err != nil {
-			err = fmt.Errorf("failed to create directory %s: %w",
-				devicenetwork.RunWwanDir, err)
-			w.Log.Error(err)
-			return err
-		}
-	}
-	return nil
+	return obj.(types.WwanLocationInfo), nil
 }
 
 // reloadWwanStatus loads the latest state data published by the wwan service.
@@ -30,6 +30,13 @@ type Args struct {
 	AA  types.AssignableAdapters
 	RS  types.RadioSilence
 	GCP types.ConfigItemValueMap
+	// StartApnProfile : index of the APN profile to try first for every
+	// cellular port (key = logical label). Missing entry means zero.
+	// DpcManager increases it to fail over to the next APN profile.
+	StartApnProfile map[string]int
+	// WlanAccessPoints : logical labels of wireless adapters operating
+	// as access points for local network instances (run by zedrouter).
+	WlanAccessPoints []string
 }
 
 // ReconcileStatus : state data related to config reconciliation.
@@ -57,6 +64,9 @@ type ReconcileStatus struct {
 	// Not to be confused with device network status
 	// (which DPC reconciler does not work with).
 	DNS DNSStatus
+	// State of 802.1X authentication of wired ports.
+	// Key is interface name, only ports with 802.1X enabled are included.
+	Dot1x map[string]types.Dot1xStatus
 	// XXX Add more as needed...
 }
 
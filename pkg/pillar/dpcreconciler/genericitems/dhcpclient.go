@@ -0,0 +1,217 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package genericitems
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	"github.com/lf-edge/eve/libs/depgraph"
+	"github.com/lf-edge/eve/libs/reconciler"
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/lf-edge/eve/pkg/pillar/devicenetwork"
+	"github.com/lf-edge/eve/pkg/pillar/dhcpclient"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+// DhcpClient : DHCP (DHCPv4 and DHCPv6) client running for a network adapter
+// (see package dhcpclient). Also used to apply static IP configuration.
+type DhcpClient struct {
+	// AdapterLL : Adapter's logical label.
+	AdapterLL     string
+	AdapterIfName string
+	DhcpConfig    types.DhcpConfig
+}
+
+// Name is based on the adapter interface name (one client per interface).
+func (c DhcpClient) Name() string {
+	return c.AdapterIfName
+}
+
+// Label is more human-readable than name.
+func (c DhcpClient) Label() string {
+	return "DHCP client for " + c.AdapterLL
+}
+
+// Type of the item.
+func (c DhcpClient) Type() string {
+	return DhcpClientTypename
+}
+
+// Equal is a comparison method for two equally-named DhcpClient instances.
+func (c DhcpClient) Equal(other depgraph.Item) bool {
+	c2 := other.(DhcpClient)
+	return reflect.DeepEqual(c.DhcpConfig, c2.DhcpConfig)
+}
+
+// External returns false.
+func (c DhcpClient) External() bool {
+	return false
+}
+
+// String describes the DHCP client config.
+func (c DhcpClient) String() string {
+	return fmt.Sprintf("DHCP Client: %#+v", c)
+}
+
+// Dependencies lists the adapter as the only dependency of the DHCP client.
+func (c DhcpClient) Dependencies() (deps []depgraph.Dependency) {
+	return []depgraph.Dependency{
+		{
+			RequiredItem: depgraph.ItemRef{
+				ItemType: AdapterTypename,
+				ItemName: c.AdapterIfName,
+			},
+			Description: "Network adapter must exist",
+		},
+	}
+}
+
+// DhcpClientConfigurator implements Configurator interface (libs/reconciler)
+// for DHCP client.
+type DhcpClientConfigurator struct {
+	Log *base.LogObject
+
+	sync.Mutex
+	clients map[string]*dhcpclient.Client // key: interface name
+}
+
+// Create starts DHCP client.
+func (c *DhcpClientConfigurator) Create(ctx context.Context, item depgraph.Item) error {
+	client := item.(DhcpClient)
+	ifName := client.AdapterIfName
+	config, err := c.clientConfig(client)
+	if err != nil {
+		c.Log.Error(err)
+		return err
+	}
+	if config == nil {
+		// DT_NONE
+		return nil
+	}
+	c.Lock()
+	defer c.Unlock()
+	if c.clients == nil {
+		c.clients = make(map[string]*dhcpclient.Client)
+	}
+	if _, running := c.clients[ifName]; running {
+		err = fmt.Errorf("DHCP client for interface %s is already running", ifName)
+		c.Log.Error(err)
+		return err
+	}
+	dhcpClient := dhcpclient.NewClient(*config)
+	if err = dhcpClient.Start(); err != nil {
+		err = fmt.Errorf("failed to start DHCP client for interface %s: %w",
+			ifName, err)
+		c.Log.Error(err)
+		return err
+	}
+	c.clients[ifName] = dhcpClient
+	c.Log.Functionf("DHCP client for interface %s is running", ifName)
+	return nil
+}
+
+func (c *DhcpClientConfigurator) clientConfig(client DhcpClient) (*dhcpclient.Config, error) {
+	ifName := client.AdapterIfName
+	dhcpConfig := client.DhcpConfig
+	config := &dhcpclient.Config{
+		Log:        c.Log,
+		IfName:     ifName,
+		LeaseDir:   dhcpclient.DefaultLeaseDir,
+		InfoDir:    dhcpclient.DefaultInfoDir,
+		ResolvConf: filepath.Join(devicenetwork.DhcpResolvConfDir, ifName+".dhcp"),
+	}
+	switch dhcpConfig.Dhcp {
+	case types.DT_NONE:
+		return nil, nil
+
+	case types.DT_CLIENT:
+		switch dhcpConfig.Type {
+		case types.NtIpv4Only:
+			config.IPv4 = true
+		case types.NtIpv6Only:
+			config.IPv6 = true
+		default:
+			config.IPv4 = true
+			config.IPv6 = true
+		}
+		if dhcpConfig.Gateway != nil && dhcpConfig.Gateway.String() == "0.0.0.0" {
+			config.NoGateway = true
+		}
+		if hostname, err := os.Hostname(); err == nil {
+			config.Hostname = hostname
+		}
+		return config, nil
+
+	case types.DT_STATIC:
+		if dhcpConfig.AddrSubnet == "" {
+			return nil, fmt.Errorf(
+				"DHCP config is missing AddrSubnet for interface %s", ifName)
+		}
+		ip, subnet, err := net.ParseCIDR(dhcpConfig.AddrSubnet)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"failed to parse AddrSubnet from DHCP config for interface %s: %v",
+				ifName, err)
+		}
+		lease := &dhcpclient.Lease4{
+			Address:    ip,
+			Mask:       subnet.Mask,
+			DNSServers: dhcpConfig.DnsServers,
+			DomainName: dhcpConfig.DomainName,
+		}
+		if dhcpConfig.Gateway != nil && !dhcpConfig.Gateway.IsUnspecified() {
+			lease.Routers = []net.IP{dhcpConfig.Gateway}
+		}
+		if dhcpConfig.NtpServer != nil && !dhcpConfig.NtpServer.IsUnspecified() {
+			lease.NTPServers = []net.IP{dhcpConfig.NtpServer}
+		}
+		config.IPv4 = true
+		config.StaticIPv4 = lease
+		return config, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported DHCP type: %v", dhcpConfig.Dhcp)
+	}
+}
+
+// Modify is not implemented.
+func (c *DhcpClientConfigurator) Modify(ctx context.Context, oldItem, newItem depgraph.Item) (err error) {
+	return errors.New("not implemented")
+}
+
+// Delete stops DHCP client (the lease is released).
+func (c *DhcpClientConfigurator) Delete(ctx context.Context, item depgraph.Item) error {
+	client := item.(DhcpClient)
+	ifName := client.AdapterIfName
+	c.Lock()
+	dhcpClient, running := c.clients[ifName]
+	delete(c.clients, ifName)
+	c.Unlock()
+	if !running {
+		return nil
+	}
+	done := reconciler.ContinueInBackground(ctx)
+	go func() {
+		err := dhcpClient.Stop()
+		if err != nil {
+			c.Log.Error(err)
+		} else {
+			c.Log.Functionf("DHCP client for interface %s is stopped", ifName)
+		}
+		done(err)
+	}()
+	return nil
+}
+
+// NeedsRecreate returns true because Modify is not implemented.
+func (c *DhcpClientConfigurator) NeedsRecreate(oldItem, newItem depgraph.Item) (recreate bool) {
+	return true
+}
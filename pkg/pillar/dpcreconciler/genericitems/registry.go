@@ -16,7 +16,7 @@ func RegisterItems(log *base.LogObject, registry *reconciler.DefaultRegistry) er
 	}
 	configurators := []configurator{
 		{c: &IOHandleConfigurator{Log: log}, t: IOHandleTypename},
-		{c: &DhcpcdConfigurator{Log: log}, t: DhcpcdTypename},
+		{c: &DhcpClientConfigurator{Log: log}, t: DhcpClientTypename},
 		{c: &ResolvConfConfigurator{Log: log}, t: ResolvConfTypename},
 		{c: &SSHAuthKeysConfigurator{Log: log}, t: SSHAuthKeysTypename},
 		{c: &WwanConfigurator{Log: log}, t: WwanTypename},
@@ -17,8 +17,8 @@ const (
 	// BondTypename : typename for bond interface.
 	// Not implemented in genericitems (implementation specific to network stack).
 	BondTypename = "Bond"
-	// DhcpcdTypename : typename for dhcpcd program (a DHCP and DHCPv6 client).
-	DhcpcdTypename = "DHCP-Client"
+	// DhcpClientTypename : typename for DHCP and DHCPv6 client.
+	DhcpClientTypename = "DHCP-Client"
 	// PhysIfTypename : typename for physical network interfaces.
 	PhysIfTypename = "Physical-Interface"
 	// ResolvConfTypename : typename for singleton item representing resolv.conf.
@@ -9,11 +9,10 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/lf-edge/eve/libs/depgraph"
 	"github.com/lf-edge/eve/pkg/pillar/base"
-	"github.com/lf-edge/eve/pkg/pillar/devicenetwork"
+	"github.com/lf-edge/eve/pkg/pillar/pubsub"
 	"github.com/lf-edge/eve/pkg/pillar/types"
 )
 
@@ -23,9 +22,9 @@ type Wwan struct {
 	Config types.WwanConfig
 }
 
-// Name returns the full path to the wwan config file.
+// Name returns the pubsub key of the wwan config.
 func (w Wwan) Name() string {
-	return devicenetwork.WwanConfigPath
+	return w.Config.Key()
 }
 
 // Label is not defined.
@@ -75,23 +74,26 @@ func (w Wwan) Dependencies() (deps []depgraph.Dependency) {
 // WwanConfigurator implements Configurator interface (libs/reconciler) for WWAN config.
 type WwanConfigurator struct {
 	Log *base.LogObject
-	// LastChecksum : checksum of the last written wwan configuration.
+	// PubWwanConfig : publication of WwanConfig for wwan microservice.
+	// If nil, the config is only logged (used by unit tests).
+	PubWwanConfig pubsub.Publication
+	// LastChecksum : checksum of the last published wwan configuration.
 	LastChecksum string
 }
 
-// Create writes config for wwan microservice.
+// Create publishes config for wwan microservice.
 func (c *WwanConfigurator) Create(ctx context.Context, item depgraph.Item) error {
 	wwan := item.(Wwan)
 	return c.installWwanConfig(wwan.Config)
 }
 
-// Modify writes updated config for wwan microservice.
+// Modify publishes updated config for wwan microservice.
 func (c *WwanConfigurator) Modify(ctx context.Context, oldItem, newItem depgraph.Item) (err error) {
 	wwan := newItem.(Wwan)
 	return c.installWwanConfig(wwan.Config)
 }
 
-// Delete writes empty config for wwan microservice.
+// Delete publishes empty config for wwan microservice.
 func (c *WwanConfigurator) Delete(ctx context.Context, item depgraph.Item) error {
 	return c.installWwanConfig(types.WwanConfig{})
 }
@@ -101,34 +103,27 @@ func (c *WwanConfigurator) NeedsRecreate(oldItem, newItem depgraph.Item) (recrea
 	return false
 }
 
-// Write cellular config into /run/wwan/config.json
+// Publish cellular config for wwan microservice.
 func (c *WwanConfigurator) installWwanConfig(config types.WwanConfig) (err error) {
-	c.Log.Noticef("installWwanConfig: write file %s with config %+v",
-		devicenetwork.WwanConfigPath, config)
-	file, err := os.Create(devicenetwork.WwanConfigPath)
+	c.Log.Noticef("installWwanConfig: publish config %+v", config)
+	_, hash, err := MarshalWwanConfig(config)
 	if err != nil {
-		err = fmt.Errorf("failed to create file %s: %w",
-			devicenetwork.WwanConfigPath, err)
 		c.Log.Error(err)
 		return err
 	}
-	defer file.Close()
-	bytes, hash, err := MarshalWwanConfig(config)
-	if err != nil {
-		c.Log.Error(err)
-		return err
-	}
-	if r, err := file.Write(bytes); err != nil || r != len(bytes) {
-		err = fmt.Errorf("failed to write %d bytes to file %s: %w",
-			len(bytes), file.Name(), err)
-		c.Log.Error(err)
-		return err
+	if c.PubWwanConfig != nil {
+		if err = c.PubWwanConfig.Publish(config.Key(), config); err != nil {
+			err = fmt.Errorf("failed to publish wwan config: %w", err)
+			c.Log.Error(err)
+			return err
+		}
 	}
 	c.LastChecksum = hash
 	return nil
 }
 
-// MarshalWwanConfig is exposed only for unit-testing purposes.
+// MarshalWwanConfig serializes wwan config and computes its checksum.
+// The checksum is reported back by wwan microservice in WwanStatus.
 func MarshalWwanConfig(config types.WwanConfig) (bytes []byte, hash string, err error) {
 	bytes, err = json.MarshalIndent(config, "", "    ")
 	if err != nil {
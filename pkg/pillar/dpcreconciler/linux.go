@@ -59,14 +59,17 @@ import (
 //     |  |                                               +-------------------------------+  |  |
 //     |  |                                               |            IPRules            |  |  |
 //     |  |  +----------------------------------------+   |                               |  |  |
-//     |  |  |               Adapters                 |   | +---------+  +----------+     |  |  |
-//     |  |  |                                        |   | |SrcIPRule|  |SrcIPRule | ... |  |  |
-//     |  |  | +---------+      +---------+           |   | +---------+  +----------+     |  |  |
+//     |  |  |               Adapters                 |   | +---------+  +------------+   |  |  |
+//     |  |  |                                        |   | |SrcIPRule|  |PolicyIPRule|...|  |  |
+//     |  |  | +---------+      +---------+           |   | +---------+  +------------+   |  |  |
 //     |  |  | | Adapter |      | Adapter |  ...      |   +-------------------------------+  |  |
 //     |  |  | +---------+      +---------+           |                                      |  |
 //     |  |  | +------------+   +------------+        |   +-------------------------------+  |  |
 //     |  |  | | DhcpClient |   | DhcpClient | ...    |   |            Routes             |  |  |
 //     |  |  | +------------+   +------------+        |   |                               |  |  |
+//     |  |  | +-----------------+                    |   |                               |  |  |
+//     |  |  | | Dot1xSupplicant | ...                |   |                               |  |  |
+//     |  |  | +-----------------+                    |   |                               |  |  |
 //     |  |  | +------------------------------------+ |   | +-------+  +-------+          |  |  |
 //     |  |  | |            AdapterAddrs            | |   | | Route |  | Route | ...      |  |  |
 //     |  |  | |                                    | |   | +-------+  +-------+          |  |  |
@@ -153,6 +156,9 @@ type LinuxDpcReconciler struct {
 	SubEdgeNodeCert      pubsub.Subscription
 	PubCipherBlockStatus pubsub.Publication
 	CipherMetrics        *cipher.AgentMetrics
+	// WwanConfig is published for wwan microservice.
+	// If nil, the config is not published (e.g. in unit tests).
+	PubWwanConfig pubsub.Publication
 
 	currentState  dg.Graph
 	intendedState dg.Graph
@@ -208,6 +214,7 @@ func (r *LinuxDpcReconciler) init() (startWatcher func()) {
 	r.registry = registry
 	configurator := registry.GetConfigurator(generic.Wwan{})
 	r.wwanConfigurator = configurator.(*generic.WwanConfigurator)
+	r.wwanConfigurator.PubWwanConfig = r.PubWwanConfig
 	r.watcherControl = make(chan watcherCtrl, 10)
 	netEvents := r.NetworkMonitor.WatchEvents(
 		context.Background(), "linux-dpc-reconciler")
@@ -343,6 +350,12 @@ func (r *LinuxDpcReconciler) Reconcile(ctx context.Context, args Args) Reconcile
 		if r.rsChanged(args.RS) {
 			r.addPendingReconcile(WirelessSG, "RS change", false)
 		}
+		if r.startApnProfileChanged(args.StartApnProfile) {
+			r.addPendingReconcile(WirelessSG, "APN profile failover", false)
+		}
+		if r.wlanAPsChanged(args.WlanAccessPoints) {
+			r.addPendingReconcile(WirelessSG, "WLAN access points change", false)
+		}
 	}
 	if r.pendingReconcile.isPending {
 		reconcileSG = r.pendingReconcile.forSubGraph
@@ -351,6 +364,8 @@ func (r *LinuxDpcReconciler) Reconcile(ctx context.Context, args Args) Reconcile
 		newStatus := r.prevStatus
 		newStatus.Error = nil
 		newStatus.FailingItems = nil
+		// Authentication state may change without any config change.
+		newStatus.Dot1x = r.getDot1xStatus(args.DPC)
 		return newStatus
 	}
 	if reconcileSG == GraphName {
@@ -381,7 +396,8 @@ func (r *LinuxDpcReconciler) Reconcile(ctx context.Context, args Args) Reconcile
 		case L3SG:
 			intSG = r.getIntendedL3Cfg(args.DPC)
 		case WirelessSG:
-			intSG = r.getIntendedWirelessCfg(args.DPC, args.AA, args.RS)
+			intSG = r.getIntendedWirelessCfg(args.DPC, args.AA, args.RS,
+				args.StartApnProfile, args.WlanAccessPoints)
 		case ACLsSG:
 			intSG = r.getIntendedACLs(args.DPC, args.GCP)
 		default:
@@ -497,6 +513,7 @@ func (r *LinuxDpcReconciler) Reconcile(ctx context.Context, args Args) Reconcile
 			Error:   dnsError,
 			Servers: resolvConf.DNSServers,
 		},
+		Dot1x: r.getDot1xStatus(args.DPC),
 	}
 
 	// Update the internal state.
@@ -566,6 +583,30 @@ func (r *LinuxDpcReconciler) rsChanged(newRS types.RadioSilence) bool {
 	return r.prevArgs.RS.Imposed != newRS.Imposed
 }
 
+func (r *LinuxDpcReconciler) startApnProfileChanged(newStart map[string]int) bool {
+	if len(r.prevArgs.StartApnProfile) != len(newStart) {
+		return true
+	}
+	for label, index := range newStart {
+		if prevIndex, ok := r.prevArgs.StartApnProfile[label]; !ok || prevIndex != index {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *LinuxDpcReconciler) wlanAPsChanged(newAPs []string) bool {
+	if len(r.prevArgs.WlanAccessPoints) != len(newAPs) {
+		return true
+	}
+	for i := range newAPs {
+		if r.prevArgs.WlanAccessPoints[i] != newAPs[i] {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *LinuxDpcReconciler) gcpChanged(newGCP types.ConfigItemValueMap) bool {
 	prevAuthKeys := r.prevArgs.GCP.GlobalValueString(types.SSHAuthorizedKeys)
 	newAuthKeys := newGCP.GlobalValueString(types.SSHAuthorizedKeys)
@@ -577,6 +618,11 @@ func (r *LinuxDpcReconciler) gcpChanged(newGCP types.ConfigItemValueMap) bool {
 	if prevAllowVNC != newAllowVNC {
 		return true
 	}
+	prevPeerCache := r.prevArgs.GCP.GlobalValueBool(types.DownloadPeerCache)
+	newPeerCache := newGCP.GlobalValueBool(types.DownloadPeerCache)
+	if prevPeerCache != newPeerCache {
+		return true
+	}
 	return false
 }
 
@@ -693,7 +739,8 @@ func (r *LinuxDpcReconciler) updateIntendedState(args Args) {
 	r.intendedState.PutSubGraph(r.getIntendedPhysicalIO(args.DPC))
 	r.intendedState.PutSubGraph(r.getIntendedLogicalIO(args.DPC))
 	r.intendedState.PutSubGraph(r.getIntendedL3Cfg(args.DPC))
-	r.intendedState.PutSubGraph(r.getIntendedWirelessCfg(args.DPC, args.AA, args.RS))
+	r.intendedState.PutSubGraph(r.getIntendedWirelessCfg(
+		args.DPC, args.AA, args.RS, args.StartApnProfile, args.WlanAccessPoints))
 	r.intendedState.PutSubGraph(r.getIntendedACLs(args.DPC, args.GCP))
 }
 
@@ -860,9 +907,21 @@ func (r *LinuxDpcReconciler) getIntendedAdapters(dpc types.DevicePortConfig) dg.
 				Usage:      generic.IOUsageL3Adapter,
 			}, nil)
 		}
+		if port.Dot1x.IsEnabled() &&
+			port.WirelessCfg.WType == types.WirelessTypeNone {
+			intendedAdapters.PutItem(linux.Dot1xSupplicant{
+				AdapterLL:         port.Logicallabel,
+				AdapterIfName:     port.IfName,
+				EAPMethod:         port.Dot1x.EAPMethod,
+				Identity:          port.Dot1x.Identity,
+				AnonymousIdentity: port.Dot1x.AnonymousIdentity,
+				AllowNoCACert:     port.Dot1x.AllowPEAPWithoutCACert,
+				Credentials:       r.getDot1xCredentials(port),
+			}, nil)
+		}
 		if port.Dhcp != types.DT_NONE &&
 			port.WirelessCfg.WType != types.WirelessTypeCellular {
-			intendedAdapters.PutItem(generic.Dhcpcd{
+			intendedAdapters.PutItem(generic.DhcpClient{
 				AdapterLL:     port.Logicallabel,
 				AdapterIfName: port.IfName,
 				DhcpConfig:    port.DhcpConfig,
@@ -884,6 +943,83 @@ func (r *LinuxDpcReconciler) getIntendedAdapters(dpc types.DevicePortConfig) dg.
 	return intendedAdapters
 }
 
+func (r *LinuxDpcReconciler) getDot1xCredentials(
+	port types.NetworkPortConfig) types.EncryptionBlock {
+	decryptAvailable := r.SubControllerCert != nil &&
+		r.SubCipherContext != nil && r.SubEdgeNodeCert != nil
+	if !port.Dot1x.CipherBlockStatus.IsCipher || !decryptAvailable {
+		if !port.Dot1x.CipherBlockStatus.IsCipher {
+			r.Log.Warnf("%s, 802.1X config cipherblock is not present",
+				port.Logicallabel)
+		} else {
+			r.Log.Warnf("%s, context for decryption of 802.1X credentials "+
+				"is not available", port.Logicallabel)
+		}
+		if r.CipherMetrics != nil {
+			r.CipherMetrics.RecordFailure(r.Log, types.NoData)
+		}
+		return types.EncryptionBlock{}
+	}
+	status, decBlock, err := cipher.GetCipherCredentials(
+		&cipher.DecryptCipherContext{
+			Log:               r.Log,
+			AgentName:         r.AgentName,
+			AgentMetrics:      r.CipherMetrics,
+			SubControllerCert: r.SubControllerCert,
+			SubCipherContext:  r.SubCipherContext,
+			SubEdgeNodeCert:   r.SubEdgeNodeCert,
+		},
+		port.Dot1x.CipherBlockStatus)
+	if r.PubCipherBlockStatus != nil {
+		r.PubCipherBlockStatus.Publish(status.Key(), status)
+	}
+	if err != nil {
+		// There is no cleartext fallback for certificates and keys.
+		r.Log.Errorf("%s, 802.1X config cipherblock decryption was unsuccessful: %v",
+			port.Logicallabel, err)
+		if r.CipherMetrics != nil {
+			r.CipherMetrics.RecordFailure(r.Log, types.MissingFallback)
+		}
+		return types.EncryptionBlock{}
+	}
+	r.Log.Functionf("%s, 802.1X config cipherblock decryption was successful",
+		port.Logicallabel)
+	return decBlock
+}
+
+// getDot1xStatus returns the state of 802.1X authentication for every port
+// with 802.1X enabled.
+func (r *LinuxDpcReconciler) getDot1xStatus(
+	dpc types.DevicePortConfig) map[string]types.Dot1xStatus {
+	var status map[string]types.Dot1xStatus
+	for _, port := range dpc.Ports {
+		if !port.IsL3Port || !port.Dot1x.IsEnabled() ||
+			port.WirelessCfg.WType != types.WirelessTypeNone {
+			continue
+		}
+		if status == nil {
+			status = make(map[string]types.Dot1xStatus)
+		}
+		itemRef := dg.Reference(linux.Dot1xSupplicant{AdapterIfName: port.IfName})
+		_, state, _, found := r.currentState.Item(itemRef)
+		switch {
+		case !found:
+			status[port.IfName] = types.Dot1xStatus{
+				State: types.Dot1xAuthStateUnknown,
+				Error: "802.1X supplicant is not running",
+			}
+		case state.WithError() != nil:
+			status[port.IfName] = types.Dot1xStatus{
+				State: types.Dot1xAuthStateFailed,
+				Error: state.WithError().Error(),
+			}
+		default:
+			status[port.IfName] = devicenetwork.GetDot1xStatus(port.IfName)
+		}
+	}
+	return status
+}
+
 func (r *LinuxDpcReconciler) getIntendedSrcIPRules(dpc types.DevicePortConfig) dg.Graph {
 	graphArgs := dg.InitArgs{
 		Name:        IPRulesSG,
@@ -914,6 +1050,13 @@ func (r *LinuxDpcReconciler) getIntendedSrcIPRules(dpc types.DevicePortConfig) d
 				Priority:      devicenetwork.PbrLocalOrigPrio,
 			}, nil)
 		}
+		for _, rule := range port.PolicyRules {
+			intendedRules.PutItem(linux.PolicyIPRule{
+				IPPolicyRule:  rule,
+				AdapterLL:     port.Logicallabel,
+				AdapterIfName: port.IfName,
+			}, nil)
+		}
 	}
 	return intendedRules
 }
@@ -937,6 +1080,27 @@ func (r *LinuxDpcReconciler) getIntendedRoutes(dpc types.DevicePortConfig) dg.Gr
 		// Routes copied from the main table.
 		srcTable := syscall.RT_TABLE_MAIN
 		dstTable := devicenetwork.BaseRTIndex + ifIndex
+		// User-defined static routes.
+		// Routes configured for the main table are also put into the port
+		// table directly, instead of waiting for them to be copied below.
+		staticDsts := make(map[string]struct{})
+		for _, staticRoute := range port.StaticRoutes {
+			rt := r.makeStaticRoute(ifIndex, staticRoute)
+			intendedRoutes.PutItem(linux.Route{
+				Route:         rt,
+				AdapterIfName: port.IfName,
+				AdapterLL:     port.Logicallabel,
+			}, nil)
+			if staticRoute.Table == 0 {
+				rt.Table = dstTable
+				intendedRoutes.PutItem(linux.Route{
+					Route:         rt,
+					AdapterIfName: port.IfName,
+					AdapterLL:     port.Logicallabel,
+				}, nil)
+				staticDsts[staticRoute.Dst.String()] = struct{}{}
+			}
+		}
 		routes, err := r.NetworkMonitor.ListRoutes(netmonitor.RouteFilters{
 			FilterByTable: true,
 			Table:         srcTable,
@@ -949,6 +1113,12 @@ func (r *LinuxDpcReconciler) getIntendedRoutes(dpc types.DevicePortConfig) dg.Gr
 			continue
 		}
 		for _, rt := range routes {
+			if rt.Dst != nil {
+				if _, static := staticDsts[rt.Dst.String()]; static {
+					// Already added above.
+					continue
+				}
+			}
 			rtCopy := rt.Data.(netlink.Route)
 			rtCopy.Table = dstTable
 			// Multiple IPv6 link-locals can't be added to the same
@@ -975,6 +1145,27 @@ func (r *LinuxDpcReconciler) getIntendedRoutes(dpc types.DevicePortConfig) dg.Gr
 	return intendedRoutes
 }
 
+func (r *LinuxDpcReconciler) makeStaticRoute(ifIndex int,
+	staticRoute types.StaticIPRoute) netlink.Route {
+	dst := staticRoute.Dst
+	rt := netlink.Route{
+		LinkIndex: ifIndex,
+		Dst:       &dst,
+		Gw:        staticRoute.Gateway,
+		Priority:  staticRoute.Metric,
+		Table:     staticRoute.Table,
+		Protocol:  syscall.RTPROT_STATIC,
+		Family:    devicenetwork.HostFamily(dst.IP),
+	}
+	if rt.Table == 0 {
+		rt.Table = syscall.RT_TABLE_MAIN
+	}
+	if len(rt.Gw) == 0 {
+		rt.Scope = netlink.SCOPE_LINK
+	}
+	return rt
+}
+
 type portAddr struct {
 	logicalLabel string
 	ifName       string
@@ -1061,7 +1252,8 @@ func (r *LinuxDpcReconciler) getIntendedArps(dpc types.DevicePortConfig) dg.Grap
 }
 
 func (r *LinuxDpcReconciler) getIntendedWirelessCfg(dpc types.DevicePortConfig,
-	aa types.AssignableAdapters, radioSilence types.RadioSilence) dg.Graph {
+	aa types.AssignableAdapters, radioSilence types.RadioSilence,
+	startApnProfile map[string]int, wlanAPs []string) dg.Graph {
 	graphArgs := dg.InitArgs{
 		Name:        WirelessSG,
 		Description: "Configuration for wireless connectivity",
@@ -1069,14 +1261,14 @@ func (r *LinuxDpcReconciler) getIntendedWirelessCfg(dpc types.DevicePortConfig,
 	intendedWirelessCfg := dg.New(graphArgs)
 	rsImposed := radioSilence.Imposed
 	intendedWirelessCfg.PutItem(
-		r.getIntendedWlanConfig(dpc, rsImposed), nil)
+		r.getIntendedWlanConfig(dpc, aa, wlanAPs, rsImposed), nil)
 	intendedWirelessCfg.PutItem(
-		r.getIntendedWwanConfig(dpc, aa, rsImposed), nil)
+		r.getIntendedWwanConfig(dpc, aa, rsImposed, startApnProfile), nil)
 	return intendedWirelessCfg
 }
 
-func (r *LinuxDpcReconciler) getIntendedWlanConfig(
-	dpc types.DevicePortConfig, radioSilence bool) dg.Item {
+func (r *LinuxDpcReconciler) getIntendedWlanConfig(dpc types.DevicePortConfig,
+	aa types.AssignableAdapters, wlanAPs []string, radioSilence bool) dg.Item {
 	var wifiPort *types.NetworkPortConfig
 	for _, portCfg := range dpc.Ports {
 		if portCfg.WirelessCfg.WType == types.WirelessTypeWifi {
@@ -1097,9 +1289,19 @@ func (r *LinuxDpcReconciler) getIntendedWlanConfig(
 			})
 		}
 	}
+	// Wireless adapters operating as access points (run by zedrouter).
+	var accessPoints []string
+	for _, label := range wlanAPs {
+		ib := aa.LookupIoBundleLogicallabel(label)
+		if ib == nil || ib.Type != types.IoNetWLAN || ib.Ifname == "" {
+			continue
+		}
+		accessPoints = append(accessPoints, ib.Ifname)
+	}
 	return linux.Wlan{
-		Config:   wifiConfig,
-		EnableRF: wifiPort != nil && !radioSilence,
+		Config:       wifiConfig,
+		AccessPoints: accessPoints,
+		EnableRF:     (wifiPort != nil || len(accessPoints) > 0) && !radioSilence,
 	}
 }
 
@@ -1161,7 +1363,7 @@ func (r *LinuxDpcReconciler) getWifiCredentials(wifi types.WifiConfig) (types.En
 }
 
 func (r *LinuxDpcReconciler) getIntendedWwanConfig(dpc types.DevicePortConfig,
-	aa types.AssignableAdapters, radioSilence bool) dg.Item {
+	aa types.AssignableAdapters, radioSilence bool, startApnProfile map[string]int) dg.Item {
 	config := types.WwanConfig{RadioSilence: radioSilence, Networks: []types.WwanNetworkConfig{}}
 
 	for _, port := range dpc.Ports {
@@ -1178,7 +1380,7 @@ func (r *LinuxDpcReconciler) getIntendedWwanConfig(dpc types.DevicePortConfig,
 				port.Logicallabel)
 			continue
 		}
-		// XXX Limited to a single APN for now
+		// Probing and location tracking is configured by the first APN profile.
 		cellCfg := port.WirelessCfg.Cellular[0]
 		network := types.WwanNetworkConfig{
 			LogicalLabel: port.Logicallabel,
@@ -1187,18 +1389,65 @@ func (r *LinuxDpcReconciler) getIntendedWwanConfig(dpc types.DevicePortConfig,
 				USB:       ioBundle.UsbAddr,
 				PCI:       ioBundle.PciLong,
 			},
-			Apns: []string{cellCfg.APN},
 			Probe: types.WwanProbe{
 				Disable: cellCfg.DisableProbe,
 				Address: cellCfg.ProbeAddr,
 			},
 			LocationTracking: cellCfg.LocationTracking,
 		}
+		for _, apnCfg := range port.WirelessCfg.Cellular {
+			credentials := r.getCellularCredentials(port.Logicallabel, apnCfg)
+			network.ApnProfiles = append(network.ApnProfiles, types.WwanApnProfile{
+				APN:           apnCfg.APN,
+				SimSlot:       apnCfg.SimSlot,
+				IPType:        apnCfg.IPType,
+				AuthProtocol:  apnCfg.AuthProtocol,
+				Username:      credentials.CellularNetUsername,
+				Password:      credentials.CellularNetPassword,
+				ForbidRoaming: apnCfg.ForbidRoaming,
+			})
+		}
+		network.StartProfile = startApnProfile[port.Logicallabel] % len(network.ApnProfiles)
 		config.Networks = append(config.Networks, network)
 	}
 	return generic.Wwan{Config: config}
 }
 
+// getCellularCredentials decrypts username and password of the APN profile.
+// Unlike with wifi, there are no cleartext credentials to fall back to.
+func (r *LinuxDpcReconciler) getCellularCredentials(logicalLabel string,
+	cellCfg types.CellConfig) (decBlock types.EncryptionBlock) {
+	decryptAvailable := r.SubControllerCert != nil &&
+		r.SubCipherContext != nil && r.SubEdgeNodeCert != nil
+	if !cellCfg.CipherBlockStatus.IsCipher {
+		return decBlock
+	}
+	if !decryptAvailable {
+		r.Log.Warnf("%s (APN %s), context for decryption of APN credentials "+
+			"is not available", logicalLabel, cellCfg.APN)
+		return decBlock
+	}
+	status, decBlock, err := cipher.GetCipherCredentials(
+		&cipher.DecryptCipherContext{
+			Log:               r.Log,
+			AgentName:         r.AgentName,
+			AgentMetrics:      r.CipherMetrics,
+			SubControllerCert: r.SubControllerCert,
+			SubCipherContext:  r.SubCipherContext,
+			SubEdgeNodeCert:   r.SubEdgeNodeCert,
+		},
+		cellCfg.CipherBlockStatus)
+	if r.PubCipherBlockStatus != nil {
+		r.PubCipherBlockStatus.Publish(status.Key(), status)
+	}
+	if err != nil {
+		r.Log.Errorf("%s (APN %s), cellular config cipherblock decryption "+
+			"was unsuccessful: %v", logicalLabel, cellCfg.APN, err)
+		return types.EncryptionBlock{}
+	}
+	return decBlock
+}
+
 func (r *LinuxDpcReconciler) getIntendedACLs(
 	dpc types.DevicePortConfig, gcp types.ConfigItemValueMap) dg.Graph {
 	graphArgs := dg.InitArgs{
@@ -1328,6 +1577,20 @@ func (r *LinuxDpcReconciler) getIntendedACLs(
 	mangleV6Rules := []linux.IptablesRule{
 		markSSHAndGuacamole, markVnc, markIcmpV6,
 	}
+	if gcp.GlobalValueBool(types.DownloadPeerCache) {
+		// Allow other EVE devices to discover and download from the peer cache.
+		markPeerCache := linux.IptablesRule{
+			Args: []string{"-p", "tcp", "--dport", strconv.Itoa(types.PeerCachePort),
+				"-j", "CONNMARK", "--set-mark", iptables.ControlProtocolMarkingIDMap["in_peer_cache"]},
+			Description: "Mark peer cache traffic",
+		}
+		markMDNS := linux.IptablesRule{
+			Args: []string{"-p", "udp", "--dport", strconv.Itoa(types.MDNSPort),
+				"-j", "CONNMARK", "--set-mark", iptables.ControlProtocolMarkingIDMap["in_peer_cache"]},
+			Description: "Mark mDNS traffic",
+		}
+		mangleV4Rules = append(mangleV4Rules, markPeerCache, markMDNS)
+	}
 
 	// Mark incoming traffic not matched by the rules above with the DROP action.
 	const dropIncomingChain = "drop-incoming"
@@ -147,6 +147,19 @@ func TestReconcileWithEmptyArgs(test *testing.T) {
 	ctx = reconciler.MockRun(context.Background())
 	status = dpcReconciler.Reconcile(ctx, dpcrec.Args{GCP: *gcp})
 	t.Expect(status.Error).To(BeNil())
+
+	// Enable peer cache.
+	mangleChain := dg.Reference(linux.IptablesChain{Table: "mangle",
+		ChainName: "PREROUTING-device"})
+	t.Expect(itemDescription(mangleChain)).ToNot(ContainSubstring("Mark peer cache traffic"))
+	gcp = types.DefaultConfigItemValueMap()
+	gcp.SetGlobalValueString(types.SSHAuthorizedKeys, "mock-authorized-key")
+	gcp.SetGlobalValueBool(types.DownloadPeerCache, true)
+	ctx = reconciler.MockRun(context.Background())
+	status = dpcReconciler.Reconcile(ctx, dpcrec.Args{GCP: *gcp})
+	t.Expect(status.Error).To(BeNil())
+	t.Expect(itemDescription(mangleChain)).To(ContainSubstring("Mark peer cache traffic"))
+	t.Expect(itemDescription(mangleChain)).To(ContainSubstring("Mark mDNS traffic"))
 }
 
 func TestSingleEthInterface(test *testing.T) {
@@ -218,7 +231,7 @@ func TestSingleEthInterface(test *testing.T) {
 	t.Expect(itemIsCreated(adapter)).To(BeTrue())
 	adapterAddrs := dg.Reference(generic.AdapterAddrs{AdapterIfName: "eth0"})
 	t.Expect(itemDescription(adapterAddrs)).To(Equal("Adapter mock-eth0 IP addresses: []"))
-	t.Expect(itemIsCreatedWithLabel("dhcpcd for mock-eth0")).To(BeTrue())
+	t.Expect(itemIsCreatedWithLabel("DHCP client for mock-eth0")).To(BeTrue())
 	sshAuthKeys := dg.Reference(generic.SSHAuthKeys{})
 	t.Expect(itemIsCreated(sshAuthKeys)).To(BeTrue())
 	t.Expect(itemDescription(sshAuthKeys)).To(Equal("/run/authorized_keys with keys: mock-authorized-key"))
@@ -327,7 +340,7 @@ func TestSingleEthInterface(test *testing.T) {
 	t.Expect(status.RS.ConfigError).To(BeEmpty())
 	t.Expect(itemCountWithType(generic.AdapterTypename)).To(Equal(0))
 	t.Expect(itemCountWithType(generic.AdapterAddrsTypename)).To(Equal(0))
-	t.Expect(itemCountWithType(generic.DhcpcdTypename)).To(Equal(0))
+	t.Expect(itemCountWithType(generic.DhcpClientTypename)).To(Equal(0))
 }
 
 func TestMultipleEthsSameSubnet(test *testing.T) {
@@ -423,7 +436,7 @@ func TestMultipleEthsSameSubnet(test *testing.T) {
 	t.Expect(itemCountWithType(generic.IOHandleTypename)).To(Equal(2))
 	t.Expect(itemCountWithType(generic.AdapterTypename)).To(Equal(2))
 	t.Expect(itemCountWithType(generic.AdapterAddrsTypename)).To(Equal(2))
-	t.Expect(itemCountWithType(generic.DhcpcdTypename)).To(Equal(2))
+	t.Expect(itemCountWithType(generic.DhcpClientTypename)).To(Equal(2))
 	t.Expect(itemCountWithType(generic.RouteTypename)).To(Equal(0))
 	t.Expect(itemCountWithType(generic.ArpTypename)).To(Equal(0))
 
@@ -574,6 +587,11 @@ func TestWireless(test *testing.T) {
 						{
 							APN: "my-apn",
 						},
+						{
+							APN:           "backup-apn",
+							SimSlot:       2,
+							ForbidRoaming: true,
+						},
 					},
 				},
 			},
@@ -611,8 +629,8 @@ func TestWireless(test *testing.T) {
 	status := dpcReconciler.Reconcile(ctx, dpcrec.Args{GCP: *gcp, DPC: dpc, AA: aa})
 	t.Expect(status.Error).To(BeNil())
 
-	t.Expect(itemIsCreatedWithLabel("dhcpcd for mock-wlan0")).To(BeTrue())
-	t.Expect(itemIsCreatedWithLabel("dhcpcd for mock-wwan0")).To(BeFalse())
+	t.Expect(itemIsCreatedWithLabel("DHCP client for mock-wlan0")).To(BeTrue())
+	t.Expect(itemIsCreatedWithLabel("DHCP client for mock-wwan0")).To(BeFalse())
 	wlan := dg.Reference(linux.Wlan{})
 	t.Expect(itemDescription(wlan)).To(ContainSubstring("SSID:my-ssid"))
 	t.Expect(itemDescription(wlan)).To(ContainSubstring("KeyScheme:1"))
@@ -620,14 +638,16 @@ func TestWireless(test *testing.T) {
 	t.Expect(itemDescription(wlan)).To(ContainSubstring("WifiPassword:my-password"))
 	t.Expect(itemDescription(wlan)).To(ContainSubstring("enable RF: true"))
 	wwan := dg.Reference(generic.Wwan{})
-	t.Expect(itemDescription(wwan)).To(ContainSubstring("Apns:[my-apn]"))
+	t.Expect(itemDescription(wwan)).To(ContainSubstring("ApnProfiles:[APN=my-apn, SIM slot=0"))
+	t.Expect(itemDescription(wwan)).To(ContainSubstring("APN=backup-apn, SIM slot=2"))
+	t.Expect(itemDescription(wwan)).To(ContainSubstring("StartProfile:0"))
 	t.Expect(itemDescription(wwan)).To(ContainSubstring("Interface:wwan0"))
 	t.Expect(itemDescription(wwan)).To(ContainSubstring("LogicalLabel:mock-wwan0"))
 	t.Expect(itemDescription(wwan)).To(ContainSubstring("RadioSilence:false"))
 	t.Expect(itemCountWithType(generic.IOHandleTypename)).To(Equal(2))
 	t.Expect(itemCountWithType(generic.AdapterTypename)).To(Equal(2))
 	t.Expect(itemCountWithType(generic.AdapterAddrsTypename)).To(Equal(2))
-	t.Expect(itemCountWithType(generic.DhcpcdTypename)).To(Equal(1))
+	t.Expect(itemCountWithType(generic.DhcpClientTypename)).To(Equal(1))
 	t.Expect(itemCountWithType(generic.RouteTypename)).To(Equal(0))
 	t.Expect(itemCountWithType(generic.ArpTypename)).To(Equal(0))
 
@@ -649,9 +669,77 @@ func TestWireless(test *testing.T) {
 	t.Expect(itemCountWithType(generic.IOHandleTypename)).To(Equal(2))
 	t.Expect(itemCountWithType(generic.AdapterTypename)).To(Equal(2))
 	t.Expect(itemCountWithType(generic.AdapterAddrsTypename)).To(Equal(2))
-	t.Expect(itemCountWithType(generic.DhcpcdTypename)).To(Equal(1))
+	t.Expect(itemCountWithType(generic.DhcpClientTypename)).To(Equal(1))
 	t.Expect(itemCountWithType(generic.RouteTypename)).To(Equal(0))
 	t.Expect(itemCountWithType(generic.ArpTypename)).To(Equal(0))
+
+	// Fail over to the backup APN profile
+	ctx = reconciler.MockRun(context.Background())
+	status = dpcReconciler.Reconcile(ctx, dpcrec.Args{GCP: *gcp, DPC: dpc, AA: aa, RS: rs,
+		StartApnProfile: map[string]int{"mock-wwan0": 1}})
+	t.Expect(status.Error).To(BeNil())
+	t.Expect(itemDescription(wwan)).To(ContainSubstring("StartProfile:1"))
+	t.Expect(itemDescription(wwan)).To(ContainSubstring("RadioSilence:true"))
+}
+
+func TestWlanAccessPoint(test *testing.T) {
+	t := initTest(test)
+	wlan0Mac := "02:00:00:00:00:01"
+	wlan0 := netmonitor.MockInterface{
+		Attrs: netmonitor.IfAttrs{
+			IfIndex:       1,
+			IfName:        "wlan0",
+			IfType:        "device",
+			WithBroadcast: true,
+			AdminUp:       true,
+			LowerUp:       true,
+		},
+		HwAddr: macAddress(wlan0Mac),
+	}
+	networkMonitor.AddOrUpdateInterface(wlan0)
+	gcp := types.DefaultConfigItemValueMap()
+	aa := types.AssignableAdapters{
+		Initialized: true,
+		IoBundleList: []types.IoBundle{
+			{
+				Type:         types.IoNetWLAN,
+				Phylabel:     "wlan0",
+				Logicallabel: "mock-wlan0",
+				Usage:        evecommon.PhyIoMemberUsage_PhyIoUsageShared,
+				Ifname:       "wlan0",
+				MacAddr:      wlan0Mac,
+			},
+		},
+	}
+
+	// wlan0 is not a device port, RF is disabled.
+	ctx := reconciler.MockRun(context.Background())
+	status := dpcReconciler.Reconcile(ctx, dpcrec.Args{GCP: *gcp, AA: aa})
+	t.Expect(status.Error).To(BeNil())
+	wlan := dg.Reference(linux.Wlan{})
+	t.Expect(itemDescription(wlan)).To(ContainSubstring("access points: []"))
+	t.Expect(itemDescription(wlan)).To(ContainSubstring("enable RF: false"))
+
+	// wlan0 operates as an access point.
+	ctx = reconciler.MockRun(context.Background())
+	status = dpcReconciler.Reconcile(ctx, dpcrec.Args{GCP: *gcp, AA: aa,
+		WlanAccessPoints: []string{"mock-wlan0"}})
+	t.Expect(status.Error).To(BeNil())
+	t.Expect(itemDescription(wlan)).To(ContainSubstring("access points: [wlan0]"))
+	t.Expect(itemDescription(wlan)).To(ContainSubstring("enable RF: true"))
+	t.Expect(itemCountWithType(generic.AdapterTypename)).To(Equal(0))
+
+	// Radio silence applies to access points as well.
+	rs := types.RadioSilence{
+		Imposed:           true,
+		ChangeInProgress:  true,
+		ChangeRequestedAt: time.Now(),
+	}
+	ctx = reconciler.MockRun(context.Background())
+	status = dpcReconciler.Reconcile(ctx, dpcrec.Args{GCP: *gcp, AA: aa, RS: rs,
+		WlanAccessPoints: []string{"mock-wlan0"}})
+	t.Expect(status.Error).To(BeNil())
+	t.Expect(itemDescription(wlan)).To(ContainSubstring("enable RF: false"))
 }
 
 func TestVlansAndBonds(test *testing.T) {
@@ -788,8 +876,8 @@ func TestVlansAndBonds(test *testing.T) {
 	t.Expect(itemCountWithType(generic.VlanTypename)).To(Equal(2))
 	t.Expect(itemCountWithType(generic.AdapterTypename)).To(Equal(2))
 
-	t.Expect(itemIsCreatedWithLabel("dhcpcd for shopfloor-vlan100")).To(BeTrue())
-	t.Expect(itemIsCreatedWithLabel("dhcpcd for shopfloor-vlan200")).To(BeTrue())
+	t.Expect(itemIsCreatedWithLabel("DHCP client for shopfloor-vlan100")).To(BeTrue())
+	t.Expect(itemIsCreatedWithLabel("DHCP client for shopfloor-vlan200")).To(BeTrue())
 
 	bondRef := dg.Reference(linux.Bond{IfName: "bond0"})
 	item, _, _, found := dpcReconciler.GetCurrentState().Item(bondRef)
@@ -822,3 +910,210 @@ func TestVlansAndBonds(test *testing.T) {
 	t.Expect(vlan200.ParentLL).To(BeEquivalentTo("bond-shopfloor"))
 	t.Expect(vlan200.ParentIfName).To(BeEquivalentTo("bond0"))
 }
+
+func TestStaticRoutesAndPolicyRules(test *testing.T) {
+	t := initTest(test)
+	eth0Mac := "02:00:00:00:00:01"
+	eth0 := netmonitor.MockInterface{
+		Attrs: netmonitor.IfAttrs{
+			IfIndex:       1,
+			IfName:        "eth0",
+			IfType:        "device",
+			WithBroadcast: true,
+			AdminUp:       true,
+			LowerUp:       true,
+		},
+		IPAddrs: []*net.IPNet{ipAddress("192.168.10.5/24")},
+		HwAddr:  macAddress(eth0Mac),
+	}
+	networkMonitor.AddOrUpdateInterface(eth0)
+	networkMonitor.UpdateRoutes([]netmonitor.Route{
+		{
+			IfIndex: 1,
+			Dst:     ipSubnet("10.50.0.0/16"),
+			Gw:      net.ParseIP("192.168.10.254"),
+			Table:   syscall.RT_TABLE_MAIN,
+			Data: netlink.Route{
+				LinkIndex: 1,
+				Dst:       ipSubnet("10.50.0.0/16"),
+				Gw:        net.ParseIP("192.168.10.254"),
+				Table:     syscall.RT_TABLE_MAIN,
+				Protocol:  syscall.RTPROT_STATIC,
+			},
+		},
+	})
+	gcp := types.DefaultConfigItemValueMap()
+	dpc := types.DevicePortConfig{
+		Version:      types.DPCIsMgmt,
+		Key:          "zedagent",
+		TimePriority: time.Now(),
+		Ports: []types.NetworkPortConfig{
+			{
+				IfName:       "eth0",
+				Phylabel:     "eth0",
+				Logicallabel: "mock-eth0",
+				IsMgmt:       true,
+				IsL3Port:     true,
+				DhcpConfig: types.DhcpConfig{
+					Dhcp: types.DT_CLIENT,
+					Type: types.NT_IPV4,
+				},
+				IPRoutingConfig: types.IPRoutingConfig{
+					StaticRoutes: []types.StaticIPRoute{
+						{
+							Dst:     *ipSubnet("10.50.0.0/16"),
+							Gateway: net.ParseIP("192.168.10.254"),
+							Metric:  100,
+						},
+						{
+							Dst:     *ipSubnet("172.20.0.0/16"),
+							Gateway: net.ParseIP("192.168.10.253"),
+							Table:   1000,
+						},
+						{
+							// Gateway not in the port subnet.
+							Dst:     *ipSubnet("172.30.0.0/16"),
+							Gateway: net.ParseIP("10.0.0.1"),
+						},
+					},
+					PolicyRules: []types.IPPolicyRule{
+						{
+							Dst: ipSubnet("10.60.0.0/16"),
+						},
+						{
+							Src:      ipSubnet("192.168.10.0/24"),
+							Dst:      ipSubnet("172.20.0.0/16"),
+							Priority: 13000,
+							Table:    1000,
+						},
+					},
+				},
+			},
+		},
+	}
+	aa := types.AssignableAdapters{
+		Initialized: true,
+		IoBundleList: []types.IoBundle{
+			{
+				Type:         types.IoNetEth,
+				Phylabel:     "eth0",
+				Logicallabel: "mock-eth0",
+				Usage:        evecommon.PhyIoMemberUsage_PhyIoUsageMgmtAndApps,
+				Ifname:       "eth0",
+				MacAddr:      eth0Mac,
+				IsPort:       true,
+			},
+		},
+	}
+
+	ctx := reconciler.MockRun(context.Background())
+	status := dpcReconciler.Reconcile(ctx, dpcrec.Args{GCP: *gcp, DPC: dpc, AA: aa})
+	t.Expect(status.Error).To(BeNil())
+	t.Expect(status.FailingItems).To(BeEmpty())
+	t.Expect(itemIsCreatedWithLabel("IP route table 254 dst 10.50.0.0/16 dev mock-eth0 via 192.168.10.254")).To(BeTrue())
+	// Static route for the main table is also added into the port table.
+	t.Expect(itemIsCreatedWithLabel("IP route table 501 dst 10.50.0.0/16 dev mock-eth0 via 192.168.10.254")).To(BeTrue())
+	t.Expect(itemIsCreatedWithLabel("IP route table 1000 dst 172.20.0.0/16 dev mock-eth0 via 192.168.10.253")).To(BeTrue())
+	t.Expect(itemIsCreatedWithLabel("IP route table 1001 dst 172.20.0.0/16 dev mock-eth0 via 192.168.10.253")).To(BeFalse())
+	// Gateway is not reachable - route is pending.
+	t.Expect(itemIsCreatedWithLabel("IP route table 254 dst 172.30.0.0/16 dev mock-eth0 via 10.0.0.1")).To(BeFalse())
+	t.Expect(itemIsCreatedWithLabel("Policy IP rule for mock-eth0 from all to 10.60.0.0/16")).To(BeTrue())
+	t.Expect(itemIsCreatedWithLabel("Policy IP rule for mock-eth0 from 192.168.10.0/24 to 172.20.0.0/16")).To(BeTrue())
+	t.Expect(itemCountWithType(linux.PolicyIPRuleTypename)).To(Equal(2))
+	rule := dg.Reference(linux.PolicyIPRule{
+		AdapterIfName: "eth0",
+		IPPolicyRule:  types.IPPolicyRule{Dst: ipSubnet("10.60.0.0/16")},
+	})
+	t.Expect(itemDescription(rule)).To(ContainSubstring("prio 0 table 0"))
+
+	// Remove static routes and policy rules.
+	eth0Port := dpc.Ports[0]
+	eth0Port.IPRoutingConfig = types.IPRoutingConfig{}
+	dpc.Ports = []types.NetworkPortConfig{eth0Port}
+	networkMonitor.UpdateRoutes(nil)
+	ctx = reconciler.MockRun(context.Background())
+	status = dpcReconciler.Reconcile(ctx, dpcrec.Args{GCP: *gcp, DPC: dpc, AA: aa})
+	t.Expect(status.Error).To(BeNil())
+	t.Expect(status.FailingItems).To(BeEmpty())
+	t.Expect(itemCountWithType(linux.PolicyIPRuleTypename)).To(Equal(0))
+	t.Expect(itemCountWithType(generic.RouteTypename)).To(Equal(0))
+}
+
+func TestDot1x(test *testing.T) {
+	t := initTest(test)
+	eth0Mac := "02:00:00:00:00:01"
+	eth0 := netmonitor.MockInterface{
+		Attrs: netmonitor.IfAttrs{
+			IfIndex:       1,
+			IfName:        "eth0",
+			IfType:        "device",
+			WithBroadcast: true,
+			AdminUp:       true,
+			LowerUp:       true,
+		},
+		IPAddrs: []*net.IPNet{ipAddress("192.168.10.5/24")},
+		HwAddr:  macAddress(eth0Mac),
+	}
+	networkMonitor.AddOrUpdateInterface(eth0)
+	gcp := types.DefaultConfigItemValueMap()
+	dpc := types.DevicePortConfig{
+		Version:      types.DPCIsMgmt,
+		Key:          "zedagent",
+		TimePriority: time.Now(),
+		Ports: []types.NetworkPortConfig{
+			{
+				IfName:       "eth0",
+				Phylabel:     "eth0",
+				Logicallabel: "mock-eth0",
+				IsMgmt:       true,
+				IsL3Port:     true,
+				DhcpConfig: types.DhcpConfig{
+					Dhcp: types.DT_CLIENT,
+					Type: types.NT_IPV4,
+				},
+				Dot1x: types.Dot1xConfig{
+					EAPMethod: types.Dot1xEAPMethodTLS,
+					Identity:  "device-1234",
+				},
+			},
+		},
+	}
+	aa := types.AssignableAdapters{
+		Initialized: true,
+		IoBundleList: []types.IoBundle{
+			{
+				Type:         types.IoNetEth,
+				Phylabel:     "eth0",
+				Logicallabel: "mock-eth0",
+				Usage:        evecommon.PhyIoMemberUsage_PhyIoUsageMgmtAndApps,
+				Ifname:       "eth0",
+				MacAddr:      eth0Mac,
+				IsPort:       true,
+			},
+		},
+	}
+
+	ctx := reconciler.MockRun(context.Background())
+	status := dpcReconciler.Reconcile(ctx, dpcrec.Args{GCP: *gcp, DPC: dpc, AA: aa})
+	t.Expect(status.Error).To(BeNil())
+	t.Expect(status.FailingItems).To(BeEmpty())
+	supplicant := dg.Reference(linux.Dot1xSupplicant{AdapterIfName: "eth0"})
+	t.Expect(itemIsCreated(supplicant)).To(BeTrue())
+	t.Expect(itemDescription(supplicant)).To(ContainSubstring("eap: TLS"))
+	t.Expect(itemDescription(supplicant)).To(ContainSubstring("identity: device-1234"))
+	// wpa_supplicant is not actually running.
+	t.Expect(status.Dot1x).To(HaveKey("eth0"))
+	t.Expect(status.Dot1x["eth0"].State).To(Equal(types.Dot1xAuthStateUnknown))
+
+	// Disable 802.1X.
+	eth0Port := dpc.Ports[0]
+	eth0Port.Dot1x = types.Dot1xConfig{}
+	dpc.Ports = []types.NetworkPortConfig{eth0Port}
+	ctx = reconciler.MockRun(context.Background())
+	status = dpcReconciler.Reconcile(ctx, dpcrec.Args{GCP: *gcp, DPC: dpc, AA: aa})
+	t.Expect(status.Error).To(BeNil())
+	t.Expect(status.FailingItems).To(BeEmpty())
+	t.Expect(itemIsCreated(supplicant)).To(BeFalse())
+	t.Expect(itemCountWithType(linux.Dot1xSupplicantTypename)).To(Equal(0))
+	t.Expect(status.Dot1x).To(BeEmpty())
+}
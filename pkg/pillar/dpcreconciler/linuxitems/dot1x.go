@@ -0,0 +1,314 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package linuxitems
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/lf-edge/eve/libs/depgraph"
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/lf-edge/eve/pkg/pillar/devicenetwork"
+	"github.com/lf-edge/eve/pkg/pillar/dpcreconciler/genericitems"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	fileutils "github.com/lf-edge/eve/pkg/pillar/utils/file"
+)
+
+const (
+	dot1xCACertFilename     = "ca.pem"
+	dot1xClientCertFilename = "client.pem"
+	dot1xClientKeyFilename  = "client.key"
+	// Bit of the bridge group_fwd_mask enabling delivery of frames sent
+	// to the 802.1X PAE group address (01:80:C2:00:00:03).
+	dot1xGroupFwdMask = 0x8
+)
+
+// Dot1xSupplicant : wpa_supplicant performing 802.1X authentication
+// of a wired adapter.
+type Dot1xSupplicant struct {
+	// AdapterLL : logical label of the authenticated adapter.
+	AdapterLL     string
+	AdapterIfName string
+	EAPMethod     types.Dot1xEAPMethod
+	Identity      string
+	// AnonymousIdentity : identity used in the outer phase of PEAP.
+	AnonymousIdentity string
+	// AllowNoCACert : allow PEAP without CA certificate (server is not verified).
+	AllowNoCACert bool
+	// Credentials decrypted from the cipher block.
+	Credentials types.EncryptionBlock
+}
+
+// Name returns the adapter interface name.
+func (s Dot1xSupplicant) Name() string {
+	return s.AdapterIfName
+}
+
+// Label is more human-readable than name.
+func (s Dot1xSupplicant) Label() string {
+	return fmt.Sprintf("802.1X supplicant for %s", s.AdapterLL)
+}
+
+// Type of the item.
+func (s Dot1xSupplicant) Type() string {
+	return Dot1xSupplicantTypename
+}
+
+// Equal compares two supplicant configurations.
+func (s Dot1xSupplicant) Equal(other depgraph.Item) bool {
+	s2 := other.(Dot1xSupplicant)
+	return s.AdapterLL == s2.AdapterLL &&
+		s.EAPMethod == s2.EAPMethod &&
+		s.Identity == s2.Identity &&
+		s.AnonymousIdentity == s2.AnonymousIdentity &&
+		s.AllowNoCACert == s2.AllowNoCACert &&
+		s.Credentials == s2.Credentials
+}
+
+// External returns false.
+func (s Dot1xSupplicant) External() bool {
+	return false
+}
+
+// String describes the supplicant configuration.
+// Credentials are intentionally omitted.
+func (s Dot1xSupplicant) String() string {
+	return fmt.Sprintf("802.1X supplicant: {adapter: %s, ifName: %s, "+
+		"eap: %s, identity: %s, anonymousIdentity: %s, withCACert: %t, "+
+		"withClientCert: %t, allowNoCACert: %t}", s.AdapterLL, s.AdapterIfName,
+		s.EAPMethod, s.Identity, s.AnonymousIdentity,
+		s.Credentials.Dot1xCACert != "", s.Credentials.Dot1xClientCert != "",
+		s.AllowNoCACert)
+}
+
+// Dependencies returns the adapter as the only dependency.
+func (s Dot1xSupplicant) Dependencies() (deps []depgraph.Dependency) {
+	return []depgraph.Dependency{
+		{
+			RequiredItem: depgraph.ItemRef{
+				ItemType: genericitems.AdapterTypename,
+				ItemName: s.AdapterIfName,
+			},
+			Description: "Supplicant runs on the adapter",
+		},
+	}
+}
+
+// Dot1xSupplicantConfigurator implements Configurator interface (libs/reconciler)
+// for wired wpa_supplicant.
+type Dot1xSupplicantConfigurator struct {
+	Log *base.LogObject
+}
+
+// Create installs supplicant configuration and starts wpa_supplicant.
+func (c *Dot1xSupplicantConfigurator) Create(ctx context.Context, item depgraph.Item) error {
+	supplicant := item.(Dot1xSupplicant)
+	if err := checkServerVerification(supplicant); err != nil {
+		c.Log.Error(err)
+		return err
+	}
+	if !supplicant.verifiesServer() {
+		c.Log.Warnf("Running PEAP for %s without CA certificate, "+
+			"the authentication server is not verified", supplicant.AdapterLL)
+	}
+	if err := c.installConfig(supplicant); err != nil {
+		c.Log.Error(err)
+		return err
+	}
+	c.setGroupFwdMask(supplicant.AdapterIfName, dot1xGroupFwdMask)
+	ifName := supplicant.AdapterIfName
+	portDir := devicenetwork.Dot1xPortDir(ifName)
+	args := []string{"-B", "-s", "-Dwired", "-i", ifName,
+		"-c", filepath.Join(portDir, devicenetwork.Dot1xConfFilename),
+		"-P", filepath.Join(portDir, devicenetwork.Dot1xPidFilename)}
+	c.Log.Noticef("Starting wpa_supplicant %v", args)
+	out, err := base.Exec(c.Log, "wpa_supplicant", args...).CombinedOutput()
+	if err != nil {
+		err = fmt.Errorf("failed to start wpa_supplicant for %s: %v, output: %s",
+			ifName, err, out)
+		c.Log.Error(err)
+		return err
+	}
+	return nil
+}
+
+// Modify updates supplicant configuration and makes wpa_supplicant reload it.
+func (c *Dot1xSupplicantConfigurator) Modify(ctx context.Context, oldItem, newItem depgraph.Item) error {
+	supplicant := newItem.(Dot1xSupplicant)
+	if err := c.installConfig(supplicant); err != nil {
+		c.Log.Error(err)
+		return err
+	}
+	return c.signalSupplicant(supplicant.AdapterIfName, syscall.SIGHUP)
+}
+
+// Delete stops wpa_supplicant and removes its configuration.
+func (c *Dot1xSupplicantConfigurator) Delete(ctx context.Context, item depgraph.Item) error {
+	supplicant := item.(Dot1xSupplicant)
+	ifName := supplicant.AdapterIfName
+	err := c.signalSupplicant(ifName, syscall.SIGTERM)
+	if err != nil {
+		// Continue with the cleanup.
+		c.Log.Warn(err)
+	}
+	c.setGroupFwdMask(ifName, 0)
+	if err := os.RemoveAll(devicenetwork.Dot1xPortDir(ifName)); err != nil {
+		err = fmt.Errorf("failed to remove 802.1X config for %s: %v", ifName, err)
+		c.Log.Error(err)
+		return err
+	}
+	return nil
+}
+
+// NeedsRecreate returns true if the new configuration is rejected
+// by checkServerVerification, so that the running supplicant is stopped
+// and Create reports the error.
+func (c *Dot1xSupplicantConfigurator) NeedsRecreate(oldItem, newItem depgraph.Item) (recreate bool) {
+	return checkServerVerification(newItem.(Dot1xSupplicant)) != nil
+}
+
+// verifiesServer returns false if PEAP is configured without CA certificate.
+func (s Dot1xSupplicant) verifiesServer() bool {
+	return s.EAPMethod != types.Dot1xEAPMethodPEAP || s.Credentials.Dot1xCACert != ""
+}
+
+// checkServerVerification rejects PEAP without CA certificate unless it was
+// explicitly allowed. Without CA certificate wpa_supplicant accepts any server
+// and would hand the MSCHAPv2 exchange over to a rogue authenticator.
+func checkServerVerification(s Dot1xSupplicant) error {
+	if s.verifiesServer() || s.AllowNoCACert {
+		return nil
+	}
+	return fmt.Errorf("refusing to run PEAP for %s without CA certificate: "+
+		"the authentication server would not be verified", s.AdapterLL)
+}
+
+// installConfig writes wpa_supplicant.conf and credential files
+// into the port directory.
+func (c *Dot1xSupplicantConfigurator) installConfig(supplicant Dot1xSupplicant) error {
+	ifName := supplicant.AdapterIfName
+	portDir := devicenetwork.Dot1xPortDir(ifName)
+	for _, dir := range []string{devicenetwork.Dot1xCtrlDir, portDir} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("failed to create directory %s: %v", dir, err)
+		}
+	}
+	creds := supplicant.Credentials
+	files := []struct {
+		name    string
+		content string
+	}{
+		{name: dot1xCACertFilename, content: creds.Dot1xCACert},
+		{name: dot1xClientCertFilename, content: creds.Dot1xClientCert},
+		{name: dot1xClientKeyFilename, content: creds.Dot1xClientKey},
+	}
+	for _, file := range files {
+		path := filepath.Join(portDir, file.name)
+		if file.content == "" {
+			_ = os.Remove(path)
+			continue
+		}
+		if err := fileutils.WriteRename(path, []byte(file.content)); err != nil {
+			return fmt.Errorf("failed to write %s: %v", path, err)
+		}
+	}
+	confPath := filepath.Join(portDir, devicenetwork.Dot1xConfFilename)
+	if err := fileutils.WriteRename(confPath, []byte(makeDot1xConfig(supplicant))); err != nil {
+		return fmt.Errorf("failed to write %s: %v", confPath, err)
+	}
+	return nil
+}
+
+// makeDot1xConfig generates wpa_supplicant.conf for wired 802.1X authentication.
+// String values are hex-encoded to avoid any need for escaping.
+func makeDot1xConfig(supplicant Dot1xSupplicant) string {
+	portDir := devicenetwork.Dot1xPortDir(supplicant.AdapterIfName)
+	creds := supplicant.Credentials
+	var sb strings.Builder
+	sb.WriteString("# Automatically generated\n")
+	sb.WriteString(fmt.Sprintf("ctrl_interface=%s\n", devicenetwork.Dot1xCtrlDir))
+	sb.WriteString("ap_scan=0\n")
+	sb.WriteString("network={\n")
+	sb.WriteString("        key_mgmt=IEEE8021X\n")
+	sb.WriteString(fmt.Sprintf("        eap=%s\n", supplicant.EAPMethod))
+	sb.WriteString("        eapol_flags=0\n")
+	if supplicant.Identity != "" {
+		sb.WriteString(fmt.Sprintf("        identity=%s\n",
+			hex.EncodeToString([]byte(supplicant.Identity))))
+	}
+	if creds.Dot1xCACert != "" {
+		sb.WriteString(fmt.Sprintf("        ca_cert=\"%s\"\n",
+			filepath.Join(portDir, dot1xCACertFilename)))
+	}
+	switch supplicant.EAPMethod {
+	case types.Dot1xEAPMethodTLS:
+		if creds.Dot1xClientCert != "" {
+			sb.WriteString(fmt.Sprintf("        client_cert=\"%s\"\n",
+				filepath.Join(portDir, dot1xClientCertFilename)))
+		}
+		if creds.Dot1xClientKey != "" {
+			sb.WriteString(fmt.Sprintf("        private_key=\"%s\"\n",
+				filepath.Join(portDir, dot1xClientKeyFilename)))
+		}
+		if creds.Dot1xPassword != "" {
+			sb.WriteString(fmt.Sprintf("        private_key_passwd=%s\n",
+				hex.EncodeToString([]byte(creds.Dot1xPassword))))
+		}
+	case types.Dot1xEAPMethodPEAP:
+		if supplicant.AnonymousIdentity != "" {
+			sb.WriteString(fmt.Sprintf("        anonymous_identity=%s\n",
+				hex.EncodeToString([]byte(supplicant.AnonymousIdentity))))
+		}
+		if creds.Dot1xPassword != "" {
+			sb.WriteString(fmt.Sprintf("        password=%s\n",
+				hex.EncodeToString([]byte(creds.Dot1xPassword))))
+		}
+		sb.WriteString("        phase1=\"peaplabel=0\"\n")
+		sb.WriteString("        phase2=\"auth=MSCHAPV2\"\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// signalSupplicant sends signal to wpa_supplicant running for the interface.
+func (c *Dot1xSupplicantConfigurator) signalSupplicant(ifName string, sig syscall.Signal) error {
+	pidPath := filepath.Join(devicenetwork.Dot1xPortDir(ifName),
+		devicenetwork.Dot1xPidFilename)
+	pidBytes, err := ioutil.ReadFile(pidPath)
+	if err != nil {
+		return fmt.Errorf("failed to read wpa_supplicant pid for %s: %v", ifName, err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(pidBytes)))
+	if err != nil {
+		return fmt.Errorf("invalid wpa_supplicant pid for %s: %v", ifName, err)
+	}
+	if err = syscall.Kill(pid, sig); err != nil {
+		return fmt.Errorf("failed to send %v to wpa_supplicant of %s (pid %d): %v",
+			sig, ifName, pid, err)
+	}
+	return nil
+}
+
+// setGroupFwdMask configures bridge (if the adapter is a bridge) to deliver
+// EAPOL frames sent to the PAE group address to the bridge interface,
+// where wpa_supplicant is running. Otherwise these link-local frames would
+// be consumed by the bridge port.
+func (c *Dot1xSupplicantConfigurator) setGroupFwdMask(ifName string, mask int) {
+	path := fmt.Sprintf("/sys/class/net/%s/bridge/group_fwd_mask", ifName)
+	if _, err := os.Stat(path); err != nil {
+		// Not a bridge.
+		return
+	}
+	err := ioutil.WriteFile(path, []byte(strconv.Itoa(mask)), 0644)
+	if err != nil {
+		c.Log.Errorf("Failed to set group_fwd_mask of bridge %s: %v", ifName, err)
+	}
+}
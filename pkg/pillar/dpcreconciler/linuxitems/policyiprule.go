@@ -0,0 +1,163 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package linuxitems
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+
+	"github.com/lf-edge/eve/libs/depgraph"
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/lf-edge/eve/pkg/pillar/devicenetwork"
+	"github.com/lf-edge/eve/pkg/pillar/dpcreconciler/genericitems"
+	"github.com/lf-edge/eve/pkg/pillar/netmonitor"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	"github.com/vishvananda/netlink"
+)
+
+// PolicyIPRule : user-defined IP rule for policy-based routing.
+type PolicyIPRule struct {
+	types.IPPolicyRule
+	// AdapterLL : logical label of the adapter for which the rule was configured.
+	AdapterLL     string
+	AdapterIfName string
+}
+
+// Name combines interface name with the rule selectors and priority
+// to construct a unique identifier for the policy IP rule.
+func (r PolicyIPRule) Name() string {
+	return fmt.Sprintf("%s/%s/%s/%d", r.AdapterIfName,
+		ipNetOrAll(r.Src), ipNetOrAll(r.Dst), r.priority())
+}
+
+// Label is more human-readable than name.
+func (r PolicyIPRule) Label() string {
+	return fmt.Sprintf("Policy IP rule for %s from %s to %s",
+		r.AdapterLL, ipNetOrAll(r.Src), ipNetOrAll(r.Dst))
+}
+
+// Type of the item.
+func (r PolicyIPRule) Type() string {
+	return PolicyIPRuleTypename
+}
+
+// Equal is a comparison method for two equally-named policy-IP-rule instances.
+func (r PolicyIPRule) Equal(other depgraph.Item) bool {
+	r2 := other.(PolicyIPRule)
+	return r.Table == r2.Table
+}
+
+// External returns false.
+func (r PolicyIPRule) External() bool {
+	return false
+}
+
+// String describes policy IP rule.
+func (r PolicyIPRule) String() string {
+	return fmt.Sprintf("Policy IP rule: "+
+		"{adapter: %s, ifName: %s, rule: %s}",
+		r.AdapterLL, r.AdapterIfName, r.IPPolicyRule.String())
+}
+
+// Dependencies lists the referenced adapter as the only dependency.
+// Unless the rule selects a specific table, the routing table of the adapter
+// is used, which requires the adapter to exist to get its index.
+func (r PolicyIPRule) Dependencies() (deps []depgraph.Dependency) {
+	return []depgraph.Dependency{
+		{
+			RequiredItem: depgraph.ItemRef{
+				ItemType: genericitems.AdapterTypename,
+				ItemName: r.AdapterIfName,
+			},
+			Description: "Adapter's routing table is used by default",
+		},
+	}
+}
+
+func (r PolicyIPRule) priority() int {
+	if r.Priority == 0 {
+		return types.IPPolicyRuleDefaultPrio
+	}
+	return r.Priority
+}
+
+func ipNetOrAll(ipNet *net.IPNet) string {
+	if ipNet == nil {
+		return "all"
+	}
+	return ipNet.String()
+}
+
+// PolicyIPRuleConfigurator implements Configurator interface (libs/reconciler)
+// for policy IP rules.
+type PolicyIPRuleConfigurator struct {
+	Log            *base.LogObject
+	NetworkMonitor netmonitor.NetworkMonitor
+}
+
+// Create adds the policy IP rule.
+func (c *PolicyIPRuleConfigurator) Create(ctx context.Context, item depgraph.Item) error {
+	rule := item.(PolicyIPRule)
+	netlinkRule, err := c.makeNetlinkRule(rule)
+	if err != nil {
+		return err
+	}
+	return netlink.RuleAdd(netlinkRule)
+}
+
+func (c *PolicyIPRuleConfigurator) makeNetlinkRule(rule PolicyIPRule) (*netlink.Rule, error) {
+	r := netlink.NewRule()
+	if rule.Table != 0 {
+		r.Table = rule.Table
+	} else {
+		ifIdx, exists, err := c.NetworkMonitor.GetInterfaceIndex(rule.AdapterIfName)
+		if err != nil {
+			err = fmt.Errorf("GetInterfaceIndex(%s) failed: %v",
+				rule.AdapterIfName, err)
+			c.Log.Error(err)
+			return nil, err
+		}
+		if !exists {
+			// Dependencies should prevent this.
+			err = fmt.Errorf("missing interface %s", rule.AdapterIfName)
+			c.Log.Error(err)
+			return nil, err
+		}
+		r.Table = devicenetwork.BaseRTIndex + ifIdx
+	}
+	r.Priority = rule.priority()
+	r.Family = syscall.AF_INET
+	if rule.Src != nil {
+		r.Src = rule.Src
+		r.Family = devicenetwork.HostFamily(rule.Src.IP)
+	}
+	if rule.Dst != nil {
+		r.Dst = rule.Dst
+		r.Family = devicenetwork.HostFamily(rule.Dst.IP)
+	}
+	return r, nil
+}
+
+// Modify is not implemented.
+func (c *PolicyIPRuleConfigurator) Modify(_ context.Context, _, _ depgraph.Item) (err error) {
+	return errors.New("not implemented")
+}
+
+// Delete removes the policy IP rule.
+func (c *PolicyIPRuleConfigurator) Delete(ctx context.Context, item depgraph.Item) error {
+	rule := item.(PolicyIPRule)
+	netlinkRule, err := c.makeNetlinkRule(rule)
+	if err != nil {
+		return err
+	}
+	return netlink.RuleDel(netlinkRule)
+}
+
+// NeedsRecreate returns true - Modify is not implemented.
+func (c *PolicyIPRuleConfigurator) NeedsRecreate(oldItem, newItem depgraph.Item) (recreate bool) {
+	return true
+}
@@ -21,9 +21,11 @@ func RegisterItems(log *base.LogObject, registry *reconciler.DefaultRegistry,
 		{c: &AdapterConfigurator{Log: log, NetworkMonitor: monitor}, t: genericitems.AdapterTypename},
 		{c: &ArpConfigurator{Log: log}, t: genericitems.ArpTypename},
 		{c: &BondConfigurator{Log: log, NetworkMonitor: monitor}, t: genericitems.BondTypename},
+		{c: &Dot1xSupplicantConfigurator{Log: log}, t: Dot1xSupplicantTypename},
 		{c: &IptablesChainConfigurator{Log: log}, t: IPtablesChainTypename},
 		{c: &IptablesChainConfigurator{Log: log}, t: IP6tablesChainTypename},
 		{c: &LocalIPRuleConfigurator{Log: log}, t: LocalIPRuleTypename},
+		{c: &PolicyIPRuleConfigurator{Log: log, NetworkMonitor: monitor}, t: PolicyIPRuleTypename},
 		{c: &RouteConfigurator{Log: log}, t: genericitems.RouteTypename},
 		{c: &SrcIPRuleConfigurator{Log: log, NetworkMonitor: monitor}, t: SrcIPRuleTypename},
 		{c: &VlanConfigurator{Log: log, NetworkMonitor: monitor}, t: genericitems.VlanTypename},
@@ -4,12 +4,17 @@
 package linuxitems
 
 const (
+	// Dot1xSupplicantTypename : typename for wpa_supplicant performing
+	// 802.1X authentication of a wired adapter.
+	Dot1xSupplicantTypename = "Dot1x-Supplicant"
 	// IPtablesChainTypename : typename for a single iptables chain (IPv4).
 	IPtablesChainTypename = "Iptables-Chain"
 	// IP6tablesChainTypename : typename for a single ip6tables chain (IPv6).
 	IP6tablesChainTypename = "Ip6tables-Chain"
 	// LocalIPRuleTypename : typename for singleton item representing IP rule for local RT.
 	LocalIPRuleTypename = "Local-IP-Rule"
+	// PolicyIPRuleTypename : typename for user-defined policy-based IP rules.
+	PolicyIPRuleTypename = "Policy-IP-Rule"
 	// SrcIPRuleTypename : typename for source-based IP rules.
 	SrcIPRuleTypename = "Src-IP-Rule"
 )
@@ -22,6 +22,9 @@ import (
 // Note that currently only one WiFi adapter per device is supported.
 type Wlan struct {
 	Config []WifiConfig
+	// AccessPoints : interface names of wireless adapters operating
+	// as access points (hostapd is run by zedrouter).
+	AccessPoints []string
 	// EnableRF : Enable or disable radio transmission.
 	EnableRF bool
 }
@@ -51,6 +54,7 @@ func (w Wlan) Type() string {
 func (w Wlan) Equal(other depgraph.Item) bool {
 	w2 := other.(Wlan)
 	return reflect.DeepEqual(w.Config, w2.Config) &&
+		reflect.DeepEqual(w.AccessPoints, w2.AccessPoints) &&
 		w.EnableRF == w2.EnableRF
 }
 
@@ -61,8 +65,8 @@ func (w Wlan) External() bool {
 
 // String describes the WLAN configuration.
 func (w Wlan) String() string {
-	return fmt.Sprintf("WLAN configuration: %+v, enable RF: %t",
-		w.Config, w.EnableRF)
+	return fmt.Sprintf("WLAN configuration: %+v, access points: %v, enable RF: %t",
+		w.Config, w.AccessPoints, w.EnableRF)
 }
 
 // Dependencies returns nothing.
@@ -80,7 +84,7 @@ type WlanConfigurator struct {
 // Create installs wpa_supplicant.conf.
 func (c *WlanConfigurator) Create(ctx context.Context, item depgraph.Item) error {
 	wlan := item.(Wlan)
-	err := c.installWifiConfig(wlan.Config)
+	err := c.installWifiConfig(wlan.Config, len(wlan.AccessPoints) > 0)
 	if err != nil {
 		return err
 	}
@@ -90,7 +94,7 @@ func (c *WlanConfigurator) Create(ctx context.Context, item depgraph.Item) error
 // Modify updates the content of wpa_supplicant.conf.
 func (c *WlanConfigurator) Modify(ctx context.Context, oldItem, newItem depgraph.Item) error {
 	wlan := newItem.(Wlan)
-	err := c.installWifiConfig(wlan.Config)
+	err := c.installWifiConfig(wlan.Config, len(wlan.AccessPoints) > 0)
 	if err != nil {
 		return err
 	}
@@ -99,7 +103,7 @@ func (c *WlanConfigurator) Modify(ctx context.Context, oldItem, newItem depgraph
 
 // Delete clears previously installed wpa file.
 func (c *WlanConfigurator) Delete(ctx context.Context, item depgraph.Item) error {
-	err := c.installWifiConfig([]WifiConfig{})
+	err := c.installWifiConfig([]WifiConfig{}, false)
 	if err != nil {
 		return err
 	}
@@ -111,7 +115,21 @@ func (c *WlanConfigurator) NeedsRecreate(oldItem, newItem depgraph.Item) (recrea
 	return false
 }
 
-func (c *WlanConfigurator) installWifiConfig(config []WifiConfig) error {
+// installWifiConfig installs wpa_supplicant.conf for the wlan service.
+// If there is no WiFi client configuration but some adapter operates as an access
+// point, the file is removed instead to make the wlan service stop wpa_supplicant,
+// which would otherwise interfere with hostapd.
+func (c *WlanConfigurator) installWifiConfig(config []WifiConfig, haveAPs bool) error {
+	if len(config) == 0 && haveAPs {
+		err := os.Remove(devicenetwork.WpaFilename)
+		if err != nil && !os.IsNotExist(err) {
+			err = fmt.Errorf("failed to remove file %s: %v",
+				devicenetwork.WpaFilename, err)
+			c.Log.Error(err)
+			return err
+		}
+		return nil
+	}
 	if _, err := os.Stat(devicenetwork.RunWlanDir); os.IsNotExist(err) {
 		err = os.Mkdir(devicenetwork.RunWlanDir, 600)
 		if err != nil {
@@ -133,7 +133,7 @@ func Sha256FromECPoint(X, Y *big.Int, pubKey *ecdsa.PublicKey) ([32]byte, error)
 //deriveSessionKey derives a ECDH shared secret based on
 //ECDH private key, and the provided public key
 func deriveSessionKey(X, Y *big.Int, publicKey *ecdsa.PublicKey) ([32]byte, error) {
-	rw, err := tpm2.OpenTPM(TpmDevicePath)
+	rw, err := OpenTPM()
 	if err != nil {
 		return [32]byte{}, fmt.Errorf("TPM open failed: %v", err)
 	}
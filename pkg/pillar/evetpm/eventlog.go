@@ -0,0 +1,162 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package evetpm
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/cshari-zededa/eve-tpm2-tools/eventlog"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	fileutils "github.com/lf-edge/eve/pkg/pillar/utils/file"
+)
+
+//maxEventDescriptionLen limits printable event data in the event description
+const maxEventDescriptionLen = 128
+
+var (
+	//MeasurementLogFile is the TPM measurement log aka TPM event log,
+	//as exported by the kernel. It is not a constant due to test usage
+	MeasurementLogFile = "/sys/kernel/security/tpm0/binary_bios_measurements"
+
+	//SavedMeasurementLogFile is a copy of the measurement log of the last boot
+	//the vault key was unsealed on. It is not a constant due to test usage
+	SavedMeasurementLogFile = types.PersistStatusDir + "/tpm_measurement_log"
+)
+
+//eventTypeNames are the names of events commonly found in EVE measurement logs
+var eventTypeNames = map[eventlog.EventType]string{
+	eventlog.PostCode:                   "EV_POST_CODE",
+	eventlog.Separator:                  "EV_SEPARATOR",
+	eventlog.Action:                     "EV_ACTION",
+	eventlog.EventTag:                   "EV_EVENT_TAG",
+	eventlog.SCRTMContents:              "EV_S_CRTM_CONTENTS",
+	eventlog.SCRTMVersion:               "EV_S_CRTM_VERSION",
+	eventlog.CpuMicrocode:               "EV_CPU_MICROCODE",
+	eventlog.PlatformConfigFlags:        "EV_PLATFORM_CONFIG_FLAGS",
+	eventlog.TableOfDevices:             "EV_TABLE_OF_DEVICES",
+	eventlog.CompactHash:                "EV_COMPACT_HASH",
+	eventlog.Ipl:                        "EV_IPL",
+	eventlog.IplPartitionData:           "EV_IPL_PARTITION_DATA",
+	eventlog.NonhostCode:                "EV_NONHOST_CODE",
+	eventlog.NonhostConfig:              "EV_NONHOST_CONFIG",
+	eventlog.NonhostInfo:                "EV_NONHOST_INFO",
+	eventlog.OmitBootDeviceEvents:       "EV_OMIT_BOOT_DEVICE_EVENTS",
+	eventlog.EFIVariableDriverConfig:    "EV_EFI_VARIABLE_DRIVER_CONFIG",
+	eventlog.EFIVariableBoot:            "EV_EFI_VARIABLE_BOOT",
+	eventlog.EFIBootServicesApplication: "EV_EFI_BOOT_SERVICES_APPLICATION",
+	eventlog.EFIBootServicesDriver:      "EV_EFI_BOOT_SERVICES_DRIVER",
+	eventlog.EFIRuntimeServicesDriver:   "EV_EFI_RUNTIME_SERVICES_DRIVER",
+	eventlog.EFIGPTEvent:                "EV_EFI_GPT_EVENT",
+	eventlog.EFIAction:                  "EV_EFI_ACTION",
+	eventlog.EFIPlatformFirmwareBlob:    "EV_EFI_PLATFORM_FIRMWARE_BLOB",
+	eventlog.EFIHandoffTables:           "EV_EFI_HANDOFF_TABLES",
+	eventlog.EFIHCRTMEvent:              "EV_EFI_HCRTM_EVENT",
+	eventlog.EFIVariableAuthority:       "EV_EFI_VARIABLE_AUTHORITY",
+}
+
+//SaveMeasurementLog keeps a copy of the current measurement log on /persist,
+//as the last known good one. Call it once the vault key is unsealed.
+func SaveMeasurementLog() error {
+	logBytes, err := ioutil.ReadFile(MeasurementLogFile)
+	if err != nil {
+		return err
+	}
+	return fileutils.WriteRename(SavedMeasurementLogFile, logBytes)
+}
+
+//DiffMeasurementLogs compares the current measurement log with the last
+//known good one, and returns events which differ, for PCRs in pcrs.
+//This points at the measurements which prevent unsealing of the vault key.
+func DiffMeasurementLogs(pcrs []int) ([]types.PCREventDiff, error) {
+	saved, err := eventlog.ParseEvents(SavedMeasurementLogFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse saved measurement log: %v", err)
+	}
+	current, err := eventlog.ParseEvents(MeasurementLogFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse measurement log: %v", err)
+	}
+	return diffEvents(saved, current, pcrs), nil
+}
+
+//diffEvents compares events measured into each of pcrs. Events common to
+//the start and to the end of both logs are skipped, the rest is reported,
+//which also covers reordered measurements.
+func diffEvents(saved, current []eventlog.Event, pcrs []int) []types.PCREventDiff {
+	var diffs []types.PCREventDiff
+	for _, pcr := range pcrs {
+		old := pcrEvents(saved, pcr)
+		cur := pcrEvents(current, pcr)
+		start := 0
+		for start < len(old) && start < len(cur) && sameEvent(old[start], cur[start]) {
+			start++
+		}
+		oldEnd, curEnd := len(old), len(cur)
+		for oldEnd > start && curEnd > start && sameEvent(old[oldEnd-1], cur[curEnd-1]) {
+			oldEnd--
+			curEnd--
+		}
+		if oldEnd == start && curEnd == start {
+			continue
+		}
+		diffs = append(diffs, types.PCREventDiff{
+			Index:   pcr,
+			Added:   toMeasuredBootEvents(cur[start:curEnd]),
+			Removed: toMeasuredBootEvents(old[start:oldEnd]),
+		})
+	}
+	return diffs
+}
+
+//pcrEvents returns events extended into PCR pcr
+func pcrEvents(events []eventlog.Event, pcr int) []eventlog.Event {
+	var filtered []eventlog.Event
+	for _, event := range events {
+		if event.Index == pcr && event.Typ != eventlog.NoAction {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
+
+func sameEvent(a, b eventlog.Event) bool {
+	return a.Typ == b.Typ && bytes.Equal(a.Sha256Digest(), b.Sha256Digest())
+}
+
+func toMeasuredBootEvents(events []eventlog.Event) []types.MeasuredBootEvent {
+	var measured []types.MeasuredBootEvent
+	for _, event := range events {
+		measured = append(measured, types.MeasuredBootEvent{
+			Sequence:    uint32(event.Sequence),
+			EventType:   uint32(event.Typ),
+			Digest:      event.Sha256Digest(),
+			Description: describeEvent(event),
+		})
+	}
+	return measured
+}
+
+//describeEvent returns event type name, followed by event data if it
+//is printable, e.g. GRUB commands and kernel command line
+func describeEvent(event eventlog.Event) string {
+	name, ok := eventTypeNames[event.Typ]
+	if !ok {
+		name = fmt.Sprintf("0x%x", uint32(event.Typ))
+	}
+	data := bytes.TrimRight(event.Data, "\x00")
+	if len(data) == 0 {
+		return name
+	}
+	for _, c := range data {
+		if c < 0x20 || c > 0x7e {
+			return name
+		}
+	}
+	if len(data) > maxEventDescriptionLen {
+		return fmt.Sprintf("%s: %s...", name, data[:maxEventDescriptionLen])
+	}
+	return fmt.Sprintf("%s: %s", name, data)
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package evetpm
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/cshari-zededa/eve-tpm2-tools/eventlog"
+)
+
+type testEvent struct {
+	pcr       uint32
+	eventType eventlog.EventType
+	data      string
+}
+
+//writeMeasurementLog writes events in the crypto agile log format,
+//with SHA1 and SHA256 digests, as found in securityfs
+func writeMeasurementLog(t *testing.T, filename string, events []testEvent) {
+	t.Helper()
+	var buf bytes.Buffer
+	write := func(v interface{}) {
+		if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+			t.Fatalf("binary.Write failed: %v", err)
+		}
+	}
+	//Spec ID event, in the legacy format
+	var specID bytes.Buffer
+	specID.WriteString("Spec ID Event03\x00")
+	binary.Write(&specID, binary.LittleEndian, struct {
+		PlatformClass uint32
+		Minor, Major  uint8
+		Errata, Size  uint8
+		NumAlgs       uint32
+		Alg1, Size1   uint16
+		Alg2, Size2   uint16
+		VendorInfo    uint8
+	}{0, 0, 2, 0, 2, 2, 0x4, sha1.Size, 0xb, sha256.Size, 0})
+	write(uint32(0))
+	write(uint32(eventlog.NoAction))
+	write([sha1.Size]byte{})
+	write(uint32(specID.Len()))
+	buf.Write(specID.Bytes())
+
+	for _, event := range events {
+		write(event.pcr)
+		write(uint32(event.eventType))
+		write(uint32(2))
+		write(uint16(0x4))
+		write(sha1.Sum([]byte(event.data)))
+		write(uint16(0xb))
+		write(sha256.Sum256([]byte(event.data)))
+		write(uint32(len(event.data)))
+		buf.WriteString(event.data)
+	}
+	if err := ioutil.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+}
+
+func TestDiffMeasurementLogs(t *testing.T) {
+	dir := t.TempDir()
+	defer func(logFile, savedLogFile string) {
+		MeasurementLogFile, SavedMeasurementLogFile = logFile, savedLogFile
+	}(MeasurementLogFile, SavedMeasurementLogFile)
+	MeasurementLogFile = filepath.Join(dir, "binary_bios_measurements")
+	SavedMeasurementLogFile = filepath.Join(dir, "tpm_measurement_log")
+
+	knownGood := []testEvent{
+		{0, eventlog.SCRTMVersion, "firmware 1.0"},
+		{4, eventlog.EFIBootServicesApplication, "\x01\x02grub"},
+		{8, eventlog.Ipl, "grub_cmd: set rootfs=IMGA"},
+		{8, eventlog.Ipl, "grub_cmd: linux /boot/kernel"},
+		{9, eventlog.Ipl, "/boot/kernel"},
+	}
+	writeMeasurementLog(t, MeasurementLogFile, knownGood)
+	if err := SaveMeasurementLog(); err != nil {
+		t.Fatalf("SaveMeasurementLog failed: %v", err)
+	}
+	diffs, err := DiffMeasurementLogs(DiskKeySealingPCRs.PCRs)
+	if err != nil {
+		t.Fatalf("DiffMeasurementLogs failed: %v", err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("Expected no changes, got %+v", diffs)
+	}
+
+	//boot from the other partition, with a new GRUB
+	writeMeasurementLog(t, MeasurementLogFile, []testEvent{
+		{0, eventlog.SCRTMVersion, "firmware 1.0"},
+		{4, eventlog.EFIBootServicesApplication, "\x01\x02new grub"},
+		{8, eventlog.Ipl, "grub_cmd: set rootfs=IMGB"},
+		{8, eventlog.Ipl, "grub_cmd: linux /boot/kernel"},
+		{9, eventlog.Ipl, "/boot/kernel"},
+		{13, eventlog.Ipl, "extra"},
+	})
+	diffs, err = DiffMeasurementLogs(DiskKeySealingPCRs.PCRs)
+	if err != nil {
+		t.Fatalf("DiffMeasurementLogs failed: %v", err)
+	}
+	if len(diffs) != 3 || diffs[0].Index != 4 || diffs[1].Index != 8 || diffs[2].Index != 13 {
+		t.Fatalf("Unexpected diffs %+v", diffs)
+	}
+	pcr8 := diffs[1]
+	if len(pcr8.Added) != 1 || len(pcr8.Removed) != 1 {
+		t.Fatalf("Unexpected PCR 8 diff %+v", pcr8)
+	}
+	if pcr8.Added[0].Description != "EV_IPL: grub_cmd: set rootfs=IMGB" ||
+		pcr8.Removed[0].Description != "EV_IPL: grub_cmd: set rootfs=IMGA" {
+		t.Errorf("Unexpected PCR 8 diff %+v", pcr8)
+	}
+	digest := sha256.Sum256([]byte("grub_cmd: set rootfs=IMGB"))
+	if !bytes.Equal(pcr8.Added[0].Digest, digest[:]) || pcr8.Added[0].Sequence != 3 {
+		t.Errorf("Unexpected PCR 8 event %+v", pcr8.Added[0])
+	}
+	//binary event data is not printed
+	if diffs[0].Added[0].Description != "EV_EFI_BOOT_SERVICES_APPLICATION" {
+		t.Errorf("Unexpected PCR 4 event %+v", diffs[0].Added[0])
+	}
+	if len(diffs[2].Added) != 1 || len(diffs[2].Removed) != 0 {
+		t.Errorf("Unexpected PCR 13 diff %+v", diffs[2])
+	}
+}
@@ -50,9 +50,6 @@ const (
 	//TpmDeviceKeyHdl is the well known TPM permanent handle for device key
 	TpmDeviceKeyHdl tpmutil.Handle = 0x817FFFFF
 
-	//TpmCredentialsFileName is the file that holds the dynamically created TPM credentials
-	TpmCredentialsFileName = types.IdentityDirname + "/tpm_credential"
-
 	//MaxPasswdLength is the max length allowed for a TPM password
 	MaxPasswdLength = 7 //limit TPM password to this length
 
@@ -89,6 +86,10 @@ var (
 	//on devices without a TPM. It is not a constant due to test usage
 	EcdhKeyFile = types.CertificateDirname + "/ecdh.key.pem"
 
+	//TpmCredentialsFileName is the file that holds the dynamically created
+	//TPM credentials. It is not a constant due to test usage
+	TpmCredentialsFileName = types.IdentityDirname + "/tpm_credential"
+
 	tpmHwInfo        = ""
 	pcrBank256Status = PCRBank256StatusUnknown
 
@@ -184,7 +185,7 @@ func ReadOwnerCrdl() (string, error) {
 //device key in TPM
 func TpmSign(digest []byte) (*big.Int, *big.Int, error) {
 
-	rw, err := tpm2.OpenTPM(TpmDevicePath)
+	rw, err := OpenTPM()
 	if err != nil {
 		return nil, nil, err
 	}
@@ -226,7 +227,7 @@ func IsTpmEnabled() bool {
 
 //GetRandom returns a random []byte of requested length
 func GetRandom(numBytes uint16) ([]byte, error) {
-	rw, err := tpm2.OpenTPM(TpmDevicePath)
+	rw, err := OpenTPM()
 	if err != nil {
 		return nil, err
 	}
@@ -266,7 +267,7 @@ func GetFirmwareVersion(v1 uint32, v2 uint32) string {
 //GetTpmProperty fetches a given property id, and returns it as uint32
 func GetTpmProperty(propID tpm2.TPMProp) (uint32, error) {
 
-	rw, err := tpm2.OpenTPM(TpmDevicePath)
+	rw, err := OpenTPM()
 	if err != nil {
 		return 0, err
 	}
@@ -286,8 +287,7 @@ func GetTpmProperty(propID tpm2.TPMProp) (uint32, error) {
 
 //FetchTpmSwStatus returns states reflecting SW usage of TPM
 func FetchTpmSwStatus() info.HwSecurityModuleStatus {
-	_, err := os.Stat(TpmDevicePath)
-	if err != nil {
+	if !IsTpmPresent() {
 		//No TPM found on this system
 		return info.HwSecurityModuleStatus_NOTFOUND
 	}
@@ -346,8 +346,7 @@ func FetchTpmHwInfo() (string, error) {
 	}
 
 	//Take care of non-TPM platforms
-	_, err := os.Stat(TpmDevicePath)
-	if err != nil {
+	if !IsTpmPresent() {
 		tpmHwInfo = "Not Available"
 		return tpmHwInfo, nil
 	}
@@ -417,7 +416,7 @@ func FetchVaultKey(log *base.LogObject) ([]byte, error) {
 }
 
 func writeDiskKey(key []byte) error {
-	rw, err := tpm2.OpenTPM(TpmDevicePath)
+	rw, err := OpenTPM()
 	if err != nil {
 		return err
 	}
@@ -449,7 +448,7 @@ func writeDiskKey(key []byte) error {
 }
 
 func readDiskKey() ([]byte, error) {
-	rw, err := tpm2.OpenTPM(TpmDevicePath)
+	rw, err := OpenTPM()
 	if err != nil {
 		return nil, err
 	}
@@ -543,7 +542,7 @@ func FetchSealedVaultKey(log *base.LogObject) ([]byte, error) {
 
 //SealDiskKey seals key into TPM2.0, with provided PCRs
 func SealDiskKey(key []byte, pcrSel tpm2.PCRSelection) error {
-	rw, err := tpm2.OpenTPM(TpmDevicePath)
+	rw, err := OpenTPM()
 	if err != nil {
 		return err
 	}
@@ -566,19 +565,9 @@ func SealDiskKey(key []byte, pcrSel tpm2.PCRSelection) error {
 	//  key received from Controller post attestation, in which case, we will
 	//  again get the same key back post-reboot as well
 
-	session, policy, err := PolicyPCRSession(rw, pcrSel)
+	priv, public, err := sealKey(rw, key, pcrSel)
 	if err != nil {
-		return fmt.Errorf("PolicyPCRSession failed: %v", err)
-	}
-
-	//Don't need the handle, we need only the policy for sealing
-	if err := tpm2.FlushContext(rw, session); err != nil {
-		return fmt.Errorf("Unable to flush session handle %v: %v", session, err)
-	}
-
-	priv, public, err := tpm2.Seal(rw, TpmSRKHdl, EmptyPassword, EmptyPassword, policy, key)
-	if err != nil {
-		return fmt.Errorf("Unable to seal key: %v", err)
+		return err
 	}
 
 	// Define space in NV storage and clean up afterwards or subsequent runs will fail.
@@ -621,7 +610,7 @@ func SealDiskKey(key []byte, pcrSel tpm2.PCRSelection) error {
 }
 
 func isSealedKeyPresent() bool {
-	rw, err := tpm2.OpenTPM(TpmDevicePath)
+	rw, err := OpenTPM()
 	if err != nil {
 		return false
 	}
@@ -639,7 +628,7 @@ func isLegacyKeyPresent() bool {
 
 //UnsealDiskKey unseals key from TPM2.0
 func UnsealDiskKey(pcrSel tpm2.PCRSelection) ([]byte, error) {
-	rw, err := tpm2.OpenTPM(TpmDevicePath)
+	rw, err := OpenTPM()
 	if err != nil {
 		return nil, err
 	}
@@ -658,6 +647,31 @@ func UnsealDiskKey(pcrSel tpm2.PCRSelection) ([]byte, error) {
 		return nil, fmt.Errorf("NVReadEx %v failed: %v", TpmSealedDiskPubHdl, err)
 	}
 
+	return unsealKey(rw, pub, priv, pcrSel)
+}
+
+//sealKey seals key under SRK with the policy of provided PCRs,
+//and returns private and public parts of the sealed object
+func sealKey(rw io.ReadWriteCloser, key []byte, pcrSel tpm2.PCRSelection) ([]byte, []byte, error) {
+	session, policy, err := PolicyPCRSession(rw, pcrSel)
+	if err != nil {
+		return nil, nil, fmt.Errorf("PolicyPCRSession failed: %v", err)
+	}
+
+	//Don't need the handle, we need only the policy for sealing
+	if err := tpm2.FlushContext(rw, session); err != nil {
+		return nil, nil, fmt.Errorf("Unable to flush session handle %v: %v", session, err)
+	}
+
+	priv, public, err := tpm2.Seal(rw, TpmSRKHdl, EmptyPassword, EmptyPassword, policy, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to seal key: %v", err)
+	}
+	return priv, public, nil
+}
+
+//unsealKey loads the sealed object and unseals it with the policy of provided PCRs
+func unsealKey(rw io.ReadWriteCloser, pub, priv []byte, pcrSel tpm2.PCRSelection) ([]byte, error) {
 	sealedObjHandle, _, err := tpm2.Load(rw, TpmSRKHdl, "", pub, priv)
 	if err != nil {
 		return nil, fmt.Errorf("Load failed: %v", err)
@@ -677,6 +691,37 @@ func UnsealDiskKey(pcrSel tpm2.PCRSelection) ([]byte, error) {
 	return key, nil
 }
 
+//SealKey seals key into TPM2.0 with provided PCRs, like SealDiskKey, but
+//returns the sealed object to the caller instead of keeping it in NV index
+func SealKey(key []byte, pcrSel tpm2.PCRSelection) ([]byte, error) {
+	rw, err := OpenTPM()
+	if err != nil {
+		return nil, err
+	}
+	defer rw.Close()
+
+	priv, public, err := sealKey(rw, key, pcrSel)
+	if err != nil {
+		return nil, err
+	}
+	return tpmutil.Pack(tpmutil.U16Bytes(public), tpmutil.U16Bytes(priv))
+}
+
+//UnsealKey unseals the sealed object returned by SealKey
+func UnsealKey(sealed []byte, pcrSel tpm2.PCRSelection) ([]byte, error) {
+	var public, priv tpmutil.U16Bytes
+	if _, err := tpmutil.Unpack(sealed, &public, &priv); err != nil {
+		return nil, fmt.Errorf("malformed sealed key: %v", err)
+	}
+	rw, err := OpenTPM()
+	if err != nil {
+		return nil, err
+	}
+	defer rw.Close()
+
+	return unsealKey(rw, public, priv, pcrSel)
+}
+
 //PolicyPCRSession prepares TPM2 Auth Policy session, with PCR as the policy
 func PolicyPCRSession(rw io.ReadWriteCloser, pcrSel tpm2.PCRSelection) (tpmutil.Handle, []byte, error) {
 	session, _, err := tpm2.StartAuthSession(
@@ -752,7 +797,7 @@ func CompareLegacyandSealedKey() SealedKeyType {
 //WipeOutStaleSealedKeyIfAny checks and deletes
 //sealed vault key
 func WipeOutStaleSealedKeyIfAny() error {
-	rw, err := tpm2.OpenTPM(TpmDevicePath)
+	rw, err := OpenTPM()
 	if err != nil {
 		return err
 	}
@@ -787,7 +832,7 @@ func pcrBankSHA256EnabledHelper() bool {
 		return false
 	}
 
-	rw, err := tpm2.OpenTPM(TpmDevicePath)
+	rw, err := OpenTPM()
 	if err != nil {
 		return false
 	}
@@ -0,0 +1,307 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package evetpm
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/lf-edge/eve/pkg/pillar/evetpm/tpmsim"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	testSrkTemplate = tpm2.Public{
+		Type:    tpm2.AlgRSA,
+		NameAlg: tpm2.AlgSHA256,
+		Attributes: tpm2.FlagFixedTPM | tpm2.FlagFixedParent |
+			tpm2.FlagSensitiveDataOrigin | tpm2.FlagUserWithAuth |
+			tpm2.FlagRestricted | tpm2.FlagDecrypt | tpm2.FlagNoDA,
+		RSAParameters: &tpm2.RSAParams{
+			Symmetric: &tpm2.SymScheme{
+				Alg:     tpm2.AlgAES,
+				KeyBits: 128,
+				Mode:    tpm2.AlgCFB,
+			},
+			KeyBits:    2048,
+			ModulusRaw: make([]byte, 256),
+		},
+	}
+	testEccKeyTemplate = tpm2.Public{
+		Type:    tpm2.AlgECC,
+		NameAlg: tpm2.AlgSHA256,
+		Attributes: tpm2.FlagSign | tpm2.FlagNoDA | tpm2.FlagDecrypt |
+			tpm2.FlagSensitiveDataOrigin |
+			tpm2.FlagUserWithAuth,
+		ECCParameters: &tpm2.ECCParams{
+			CurveID: tpm2.CurveNISTP256,
+		},
+	}
+)
+
+//setupSimulator plugs a fresh TPM simulator in as the TPM transport
+func setupSimulator(t *testing.T) *tpmsim.Simulator {
+	t.Helper()
+	sim, err := tpmsim.New()
+	if err != nil {
+		t.Fatalf("Failed to create TPM simulator: %v", err)
+	}
+	SetTpmTransport(sim.Open)
+	t.Cleanup(func() { SetTpmTransport(nil) })
+	return sim
+}
+
+//createPersistentKey creates a primary key from template and makes
+//it persistent at handle, like tpmmgr does on the device
+func createPersistentKey(t *testing.T, handle tpmutil.Handle,
+	template tpm2.Public, password string) *ecdsa.PublicKey {
+	t.Helper()
+	rw, err := OpenTPM()
+	if err != nil {
+		t.Fatalf("OpenTPM failed: %v", err)
+	}
+	defer rw.Close()
+	keyHandle, pub, err := tpm2.CreatePrimary(rw, tpm2.HandleOwner,
+		tpm2.PCRSelection{}, EmptyPassword, password, template)
+	if err != nil {
+		t.Fatalf("CreatePrimary failed: %v", err)
+	}
+	if err := tpm2.EvictControl(rw, EmptyPassword, tpm2.HandleOwner,
+		keyHandle, handle); err != nil {
+		t.Fatalf("EvictControl failed: %v", err)
+	}
+	ecdsaPub, _ := pub.(*ecdsa.PublicKey)
+	return ecdsaPub
+}
+
+func TestSealUnsealWithSimulator(t *testing.T) {
+	setupSimulator(t)
+	createPersistentKey(t, TpmSRKHdl, testSrkTemplate, EmptyPassword)
+
+	if err := TestSealUnseal(); err != nil {
+		t.Fatalf("TestSealUnseal failed: %v", err)
+	}
+	if !isSealedKeyPresent() {
+		t.Errorf("Sealed key is not present after sealing")
+	}
+	if err := WipeOutStaleSealedKeyIfAny(); err != nil {
+		t.Fatalf("WipeOutStaleSealedKeyIfAny failed: %v", err)
+	}
+	if isSealedKeyPresent() {
+		t.Errorf("Sealed key is present after wiping it out")
+	}
+}
+
+func TestUnsealAfterPCRChange(t *testing.T) {
+	sim := setupSimulator(t)
+	createPersistentKey(t, TpmSRKHdl, testSrkTemplate, EmptyPassword)
+
+	//measurements done before sealing are part of the policy
+	if err := sim.ExtendPCR(0, []byte("firmware")); err != nil {
+		t.Fatalf("ExtendPCR failed: %v", err)
+	}
+	key := []byte("0123456789abcdef0123456789abcdef")
+	if err := SealDiskKey(key, DiskKeySealingPCRs); err != nil {
+		t.Fatalf("SealDiskKey failed: %v", err)
+	}
+	unsealed, err := UnsealDiskKey(DiskKeySealingPCRs)
+	if err != nil {
+		t.Fatalf("UnsealDiskKey failed: %v", err)
+	}
+	if !bytes.Equal(key, unsealed) {
+		t.Errorf("want %x, but got %x", key, unsealed)
+	}
+
+	//PCRs not in the sealing set do not matter
+	if err := sim.ExtendPCR(5, []byte("not sealed")); err != nil {
+		t.Fatalf("ExtendPCR failed: %v", err)
+	}
+	if _, err := UnsealDiskKey(DiskKeySealingPCRs); err != nil {
+		t.Errorf("UnsealDiskKey failed after extending PCR 5: %v", err)
+	}
+
+	//a different boot chain must not be able to unseal the key
+	if err := sim.ExtendPCR(7, []byte("secure boot disabled")); err != nil {
+		t.Fatalf("ExtendPCR failed: %v", err)
+	}
+	if _, err := UnsealDiskKey(DiskKeySealingPCRs); err == nil {
+		t.Errorf("UnsealDiskKey succeeded after extending PCR 7")
+	}
+
+	//sealed key survives a reset, and is unsealed again
+	//once the same measurements are replayed
+	sim.Reset()
+	if err := sim.ExtendPCR(0, []byte("firmware")); err != nil {
+		t.Fatalf("ExtendPCR failed: %v", err)
+	}
+	unsealed, err = UnsealDiskKey(DiskKeySealingPCRs)
+	if err != nil {
+		t.Fatalf("UnsealDiskKey failed after reset: %v", err)
+	}
+	if !bytes.Equal(key, unsealed) {
+		t.Errorf("want %x, but got %x", key, unsealed)
+	}
+}
+
+func TestSealKeyWithSimulator(t *testing.T) {
+	sim := setupSimulator(t)
+	createPersistentKey(t, TpmSRKHdl, testSrkTemplate, EmptyPassword)
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	sealed, err := SealKey(key, DiskKeySealingPCRs)
+	if err != nil {
+		t.Fatalf("SealKey failed: %v", err)
+	}
+	if bytes.Contains(sealed, key) {
+		t.Errorf("Sealed key contains the key in clear")
+	}
+	unsealed, err := UnsealKey(sealed, DiskKeySealingPCRs)
+	if err != nil {
+		t.Fatalf("UnsealKey failed: %v", err)
+	}
+	if !bytes.Equal(key, unsealed) {
+		t.Errorf("want %x, but got %x", key, unsealed)
+	}
+	//the sealed object is not kept in NV index
+	if isSealedKeyPresent() {
+		t.Errorf("SealKey stored the key in NV index")
+	}
+
+	if _, err := UnsealKey(sealed[:len(sealed)/2], DiskKeySealingPCRs); err == nil {
+		t.Errorf("UnsealKey succeeded for truncated sealed key")
+	}
+	if err := sim.ExtendPCR(7, []byte("secure boot disabled")); err != nil {
+		t.Fatalf("ExtendPCR failed: %v", err)
+	}
+	if _, err := UnsealKey(sealed, DiskKeySealingPCRs); err == nil {
+		t.Errorf("UnsealKey succeeded after extending PCR 7")
+	}
+}
+
+func TestFetchSealedVaultKey(t *testing.T) {
+	setupSimulator(t)
+	createPersistentKey(t, TpmSRKHdl, testSrkTemplate, EmptyPassword)
+	log := base.NewSourceLogObject(logrus.StandardLogger(), "evetpm_test", 0)
+
+	oldStatus := pcrBank256Status
+	pcrBank256Status = PCRBank256StatusSupported
+	defer func() { pcrBank256Status = oldStatus }()
+
+	//legacy key from an older installation gets cloned into the sealed key
+	legacyKey, err := FetchVaultKey(log)
+	if err != nil {
+		t.Fatalf("FetchVaultKey failed: %v", err)
+	}
+	if len(legacyKey) != vaultKeyLength {
+		t.Fatalf("want %d bytes long key, but got %d", vaultKeyLength, len(legacyKey))
+	}
+	if keyType := CompareLegacyandSealedKey(); keyType != SealedKeyTypeUnprotected {
+		t.Errorf("want %v, but got %v", SealedKeyTypeUnprotected, keyType)
+	}
+	sealedKey, err := FetchSealedVaultKey(log)
+	if err != nil {
+		t.Fatalf("FetchSealedVaultKey failed: %v", err)
+	}
+	if !bytes.Equal(legacyKey, sealedKey) {
+		t.Errorf("Sealed key %x is not cloned from legacy key %x", sealedKey, legacyKey)
+	}
+	if keyType := CompareLegacyandSealedKey(); keyType != SealedKeyTypeReused {
+		t.Errorf("want %v, but got %v", SealedKeyTypeReused, keyType)
+	}
+
+	//once sealed, the same key is returned
+	sealedKey2, err := FetchSealedVaultKey(log)
+	if err != nil {
+		t.Fatalf("FetchSealedVaultKey failed: %v", err)
+	}
+	if !bytes.Equal(sealedKey, sealedKey2) {
+		t.Errorf("want %x, but got %x", sealedKey, sealedKey2)
+	}
+}
+
+func TestTpmSignWithSimulator(t *testing.T) {
+	setupSimulator(t)
+
+	dir, err := ioutil.TempDir("", "evetpm_test")
+	if err != nil {
+		t.Fatalf("TempDir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	oldCredentialsFile := TpmCredentialsFileName
+	TpmCredentialsFileName = filepath.Join(dir, "tpm_credential")
+	defer func() { TpmCredentialsFileName = oldCredentialsFile }()
+	password := "passwd"
+	if err := ioutil.WriteFile(TpmCredentialsFileName, []byte(password), 0644); err != nil {
+		t.Fatalf("Failed to write credentials: %v", err)
+	}
+	pub := createPersistentKey(t, TpmDeviceKeyHdl, testEccKeyTemplate, password)
+
+	digest := sha256.Sum256([]byte("data to sign"))
+	r, s, err := TpmSign(digest[:])
+	if err != nil {
+		t.Fatalf("TpmSign failed: %v", err)
+	}
+	if !ecdsa.Verify(pub, digest[:], r, s) {
+		t.Errorf("Signature verification failed")
+	}
+
+	//wrong credentials must be rejected
+	if err := ioutil.WriteFile(TpmCredentialsFileName, []byte("wrong"), 0644); err != nil {
+		t.Fatalf("Failed to write credentials: %v", err)
+	}
+	if _, _, err := TpmSign(digest[:]); err == nil {
+		t.Errorf("TpmSign succeeded with wrong credentials")
+	}
+}
+
+func TestDeriveSessionKeyWithSimulator(t *testing.T) {
+	setupSimulator(t)
+	ecdhPub := createPersistentKey(t, TpmEcdhKeyHdl, testEccKeyTemplate, EmptyPassword)
+
+	//simulate the controller side, which knows only the public key
+	private, x, y, err := elliptic.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	zx, zy := elliptic.P256().ScalarMult(ecdhPub.X, ecdhPub.Y, private)
+	want, err := Sha256FromECPoint(zx, zy, ecdhPub)
+	if err != nil {
+		t.Fatalf("Sha256FromECPoint failed: %v", err)
+	}
+
+	got, err := deriveSessionKey(x, y, ecdhPub)
+	if err != nil {
+		t.Fatalf("deriveSessionKey failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("want %x, but got %x", want, got)
+	}
+}
+
+func TestFetchTpmHwInfoWithSimulator(t *testing.T) {
+	setupSimulator(t)
+	defer func() { tpmHwInfo = "" }()
+	tpmHwInfo = ""
+
+	if status := FetchTpmSwStatus(); status.String() == "NOTFOUND" {
+		t.Errorf("TPM is not found with simulator transport")
+	}
+	hwInfo, err := FetchTpmHwInfo()
+	if err != nil {
+		t.Fatalf("FetchTpmHwInfo failed: %v", err)
+	}
+	if hwInfo == "Not Available" {
+		t.Errorf("TPM hardware info is not available with simulator transport")
+	}
+}
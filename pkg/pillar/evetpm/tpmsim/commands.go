@@ -0,0 +1,1217 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package tpmsim
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"time"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+const (
+	tagCreationTicket tpmutil.Tag = 0x8021
+	attestMagic                   = 0xff544347
+	maxNonceSize                  = 64
+	maxRandomBytes                = 64
+
+	handleTypeShift           = 24
+	sessionHandleHMAC  uint32 = 0x02000000
+	sessionHandlePol   uint32 = 0x03000000
+	transientHandle    uint32 = 0x80000000
+	ownerPersistentMin        = 0x81000000
+	ownerPersistentMax        = 0x817FFFFF
+	platPersistentMin         = 0x81800000
+	platPersistentMax         = 0x81FFFFFF
+
+	//simulator identity, as reported in TPM properties
+	simManufacturer = 0x53494D00 //"SIM\0"
+	simVendorStr1   = 0x45564520 //"EVE "
+	simVendorStr2   = 0x53494D00 //"SIM\0"
+	simFirmwareVer1 = 0x00010000
+	simFamily       = 0x322E3000 //"2.0\0"
+)
+
+//session is a policy or trial session, started with TPM2_StartAuthSession
+type session struct {
+	sessionType  tpm2.SessionType
+	hashAlg      tpm2.Algorithm
+	nonceTPM     []byte
+	policyDigest []byte
+	//pcrCheck is set by TPM2_PolicyPCR, the PCRs must not
+	//change until the session is used for authorization
+	pcrCheck   bool
+	pcrCounter uint32
+}
+
+func (s *session) reset() {
+	hash, _ := s.hashAlg.Hash()
+	s.policyDigest = make([]byte, hash.Size())
+	s.pcrCheck = false
+}
+
+//nvIndex is an NV index defined with TPM2_NV_DefineSpace
+type nvIndex struct {
+	NameAlg    tpm2.Algorithm
+	Attributes tpm2.NVAttr
+	AuthPolicy []byte
+	AuthValue  []byte
+	Data       []byte
+}
+
+//public returns TPMS_NV_PUBLIC of the index in wire format
+func (nv *nvIndex) public(index tpmutil.Handle) []byte {
+	pub, _ := tpmutil.Pack(index, nv.NameAlg, nv.Attributes,
+		tpmutil.U16Bytes(nv.AuthPolicy), uint16(len(nv.Data)))
+	return pub
+}
+
+//name returns TPM2B_NAME contents of the index
+func (nv *nvIndex) name(index tpmutil.Handle) []byte {
+	digest, err := hashOf(nv.NameAlg, nv.public(index))
+	if err != nil {
+		return nil
+	}
+	name, _ := tpmutil.Pack(nv.NameAlg, tpmutil.RawBytes(digest))
+	return name
+}
+
+//command is a parsed TPM command
+type command struct {
+	tag     tpmutil.Tag
+	code    tpmutil.Command
+	handles []tpmutil.Handle
+	auths   []tpm2.AuthCommand
+	params  *bytes.Buffer
+}
+
+//response is what command handlers return on success
+type response struct {
+	handles []tpmutil.Handle
+	params  []byte
+}
+
+//commandInfo describes the handle area of a command and its handler
+type commandInfo struct {
+	//number of handles in the handle area
+	handles int
+	//whether the first handle needs authorization
+	auth    bool
+	handler func(s *Simulator, c *conn, cmd *command) (*response, tpmRC)
+}
+
+var commands map[tpmutil.Command]commandInfo
+
+func init() {
+	commands = map[tpmutil.Command]commandInfo{
+		tpm2.CmdStartup:          {0, false, (*Simulator).startup},
+		tpm2.CmdShutdown:         {0, false, (*Simulator).startup},
+		tpm2.CmdGetCapability:    {0, false, (*Simulator).getCapability},
+		tpm2.CmdGetRandom:        {0, false, (*Simulator).getRandom},
+		tpm2.CmdCreatePrimary:    {1, true, (*Simulator).createPrimary},
+		tpm2.CmdCreate:           {1, true, (*Simulator).create},
+		tpm2.CmdLoad:             {1, true, (*Simulator).load},
+		tpm2.CmdFlushContext:     {0, false, (*Simulator).flushContext},
+		tpm2.CmdEvictControl:     {2, true, (*Simulator).evictControl},
+		tpm2.CmdReadPublic:       {1, false, (*Simulator).readPublic},
+		tpm2.CmdStartAuthSession: {2, false, (*Simulator).startAuthSession},
+		tpm2.CmdPolicyPCR:        {1, false, (*Simulator).policyPCR},
+		tpm2.CmdPolicyGetDigest:  {1, false, (*Simulator).policyGetDigest},
+		tpm2.CmdUnseal:           {1, true, (*Simulator).unseal},
+		tpm2.CmdSign:             {1, true, (*Simulator).sign},
+		tpm2.CmdQuote:            {1, true, (*Simulator).quote},
+		tpm2.CmdPCRRead:          {0, false, (*Simulator).pcrRead},
+		tpm2.CmdPCRExtend:        {1, true, (*Simulator).pcrExtend},
+		tpm2.CmdDefineSpace:      {1, true, (*Simulator).nvDefineSpace},
+		tpm2.CmdUndefineSpace:    {2, true, (*Simulator).nvUndefineSpace},
+		tpm2.CmdWriteNV:          {2, true, (*Simulator).nvWrite},
+		tpm2.CmdReadNV:           {2, true, (*Simulator).nvRead},
+		tpm2.CmdReadPublicNV:     {1, false, (*Simulator).nvReadPublic},
+		tpm2.CmdECDHKeyGen:       {1, false, (*Simulator).ecdhKeyGen},
+		tpm2.CmdECDHZGen:         {1, true, (*Simulator).ecdhZGen},
+	}
+}
+
+//execute runs a single command and returns the response bytes
+func (s *Simulator) execute(c *conn, cmdBytes []byte) []byte {
+	cmd, info, rc := parseCommand(cmdBytes)
+	if rc != rcSuccess {
+		return errorResponse(rc)
+	}
+	if info.auth {
+		if cmd.tag != tpm2.TagSessions || len(cmd.auths) == 0 {
+			return errorResponse(rcAuthMissing)
+		}
+		if rc := s.authorize(cmd, cmd.handles[0], cmd.auths[0]); rc != rcSuccess {
+			return errorResponse(rc)
+		}
+	}
+	resp, rc := info.handler(s, c, cmd)
+	if rc != rcSuccess {
+		return errorResponse(rc)
+	}
+	return encodeResponse(cmd, resp)
+}
+
+func parseCommand(cmdBytes []byte) (*command, commandInfo, tpmRC) {
+	var info commandInfo
+	var size uint32
+	cmd := &command{}
+	buf := bytes.NewBuffer(cmdBytes)
+	if err := tpmutil.UnpackBuf(buf, &cmd.tag, &size, &cmd.code); err != nil {
+		return nil, info, rcCommandSize
+	}
+	if int(size) != len(cmdBytes) {
+		return nil, info, rcCommandSize
+	}
+	if cmd.tag != tpm2.TagSessions && cmd.tag != tpm2.TagNoSessions {
+		return nil, info, rcTag
+	}
+	info, ok := commands[cmd.code]
+	if !ok {
+		return nil, info, rcCommandCode
+	}
+	for i := 0; i < info.handles; i++ {
+		var h tpmutil.Handle
+		if err := tpmutil.UnpackBuf(buf, &h); err != nil {
+			return nil, info, rcInsufficient
+		}
+		cmd.handles = append(cmd.handles, h)
+	}
+	if cmd.tag == tpm2.TagSessions {
+		var authSize uint32
+		if err := tpmutil.UnpackBuf(buf, &authSize); err != nil {
+			return nil, info, rcAuthSize
+		}
+		if int(authSize) > buf.Len() {
+			return nil, info, rcAuthSize
+		}
+		authBuf := bytes.NewBuffer(buf.Next(int(authSize)))
+		for authBuf.Len() > 0 {
+			var auth tpm2.AuthCommand
+			if err := tpmutil.UnpackBuf(authBuf, &auth); err != nil {
+				return nil, info, rcAuthSize
+			}
+			cmd.auths = append(cmd.auths, auth)
+		}
+	}
+	cmd.params = buf
+	return cmd, info, rcSuccess
+}
+
+func errorResponse(rc tpmRC) []byte {
+	resp, _ := tpmutil.Pack(tpm2.TagNoSessions, uint32(10), uint32(rc))
+	return resp
+}
+
+func encodeResponse(cmd *command, resp *response) []byte {
+	var body bytes.Buffer
+	for _, h := range resp.handles {
+		binary.Write(&body, binary.BigEndian, h)
+	}
+	tag := tpm2.TagNoSessions
+	if cmd.tag == tpm2.TagSessions {
+		tag = tpm2.TagSessions
+		binary.Write(&body, binary.BigEndian, uint32(len(resp.params)))
+		body.Write(resp.params)
+		for _, auth := range cmd.auths {
+			//nonceTPM, session attributes and an empty HMAC
+			ack, _ := tpmutil.Pack(tpmutil.U16Bytes(nil),
+				auth.Attributes&tpm2.AttrContinueSession, tpmutil.U16Bytes(nil))
+			body.Write(ack)
+		}
+	} else {
+		body.Write(resp.params)
+	}
+	out, _ := tpmutil.Pack(tag, uint32(10+body.Len()), uint32(rcSuccess))
+	return append(out, body.Bytes()...)
+}
+
+//unpack reads params of a command, reporting failures on parameter n
+func unpack(buf *bytes.Buffer, n int, elts ...interface{}) tpmRC {
+	if err := tpmutil.UnpackBuf(buf, elts...); err != nil {
+		return rcInsufficient.param(n)
+	}
+	return rcSuccess
+}
+
+func isHierarchy(h tpmutil.Handle) bool {
+	switch h {
+	case tpm2.HandleOwner, tpm2.HandleEndorsement, tpm2.HandlePlatform,
+		tpm2.HandleLockout, tpm2.HandleNull:
+		return true
+	}
+	return false
+}
+
+func isPCR(h tpmutil.Handle) bool {
+	return h < NumPCRs
+}
+
+func isNVIndex(h tpmutil.Handle) bool {
+	return uint32(h)>>handleTypeShift == uint32(tpm2.HandleTypeNVIndex)
+}
+
+//lookupObject returns a loaded transient or persistent object
+func (s *Simulator) lookupObject(h tpmutil.Handle) *object {
+	if obj, ok := s.transient[h]; ok {
+		return obj
+	}
+	if obj, ok := s.state.Persistent[h]; ok {
+		return obj
+	}
+	return nil
+}
+
+//authorize checks authorization of the entity referenced by handle
+func (s *Simulator) authorize(cmd *command, h tpmutil.Handle, auth tpm2.AuthCommand) tpmRC {
+	var authValue, authPolicy []byte
+	userWithAuth := true
+	switch {
+	case isHierarchy(h), isPCR(h):
+		//hierarchy authorizations are always empty in the simulator
+	case isNVIndex(h):
+		nv, ok := s.state.NVIndices[h]
+		if !ok {
+			return rcHandle.handle(1)
+		}
+		authValue, authPolicy = nv.AuthValue, nv.AuthPolicy
+	default:
+		obj := s.lookupObject(h)
+		if obj == nil {
+			return rcHandle.handle(1)
+		}
+		pub, err := obj.public()
+		if err != nil {
+			return rcFailure
+		}
+		authValue, authPolicy = obj.AuthValue, pub.AuthPolicy
+		userWithAuth = pub.Attributes&tpm2.FlagUserWithAuth != 0
+	}
+	if auth.Session == tpm2.HandlePasswordSession {
+		if !userWithAuth {
+			return rcAuthUnavailable
+		}
+		if !hmac.Equal(auth.Auth, authValue) {
+			return rcAuthFail.session(1)
+		}
+		return rcSuccess
+	}
+	sess, ok := s.sessions[auth.Session]
+	if !ok {
+		return rcValue.session(1)
+	}
+	if sess.sessionType != tpm2.SessionPolicy {
+		//HMAC sessions are not implemented, trial
+		//sessions can not be used for authorization
+		return rcAuthType
+	}
+	defer func() {
+		if auth.Attributes&tpm2.AttrContinueSession == 0 {
+			delete(s.sessions, auth.Session)
+		} else {
+			sess.reset()
+		}
+	}()
+	if sess.pcrCheck && sess.pcrCounter != s.pcrUpdateCounter {
+		return rcPCRChanged
+	}
+	if len(authPolicy) == 0 || !bytes.Equal(authPolicy, sess.policyDigest) {
+		return rcPolicyFail.session(1)
+	}
+	return rcSuccess
+}
+
+func (s *Simulator) startup(c *conn, cmd *command) (*response, tpmRC) {
+	return &response{}, rcSuccess
+}
+
+//properties reported by TPM2_GetCapability(TPM_CAP_TPM_PROPERTIES)
+var properties = map[tpm2.TPMProp]uint32{
+	tpm2.FamilyIndicator:      simFamily,
+	tpm2.SpecLevel:            0,
+	tpm2.SpecRevision:         138,
+	tpm2.SpecDayOfYear:        1,
+	tpm2.SpecYear:             2016,
+	tpm2.Manufacturer:         simManufacturer,
+	tpm2.VendorString1:        simVendorStr1,
+	tpm2.VendorString2:        simVendorStr2,
+	tpm2.VendorString3:        0,
+	tpm2.VendorString4:        0,
+	tpm2.VendorTPMType:        0,
+	tpm2.FirmwareVersion1:     simFirmwareVer1,
+	tpm2.FirmwareVersion2:     0,
+	tpm2.InputMaxBufferSize:   maxNVBufferSize,
+	tpm2.PCRCount:             NumPCRs,
+	tpm2.PCRSelectMin:         3,
+	tpm2.NVIndexMax:           maxNVIndexSize,
+	tpm2.CommandMaxSize:       maxCommandSize,
+	tpm2.ResponseMaxSize:      maxCommandSize,
+	tpm2.DigestMaxSize:        sha256.Size * 2,
+	tpm2.NVMaxBufferSize:      maxNVBufferSize,
+	tpm2.PersistentObjectsMin: 7,
+}
+
+func (s *Simulator) getCapability(c *conn, cmd *command) (*response, tpmRC) {
+	var capa tpm2.Capability
+	var property, count uint32
+	if rc := unpack(cmd.params, 1, &capa, &property, &count); rc != rcSuccess {
+		return nil, rc
+	}
+	var out bytes.Buffer
+	moreData := byte(0)
+	switch capa {
+	case tpm2.CapabilityTPMProperties:
+		var props []tpm2.TaggedProperty
+		for prop := tpm2.TPMProp(property); prop <= tpm2.CapabilityMaxBufferSize; prop++ {
+			value, ok := properties[prop]
+			if !ok {
+				continue
+			}
+			if uint32(len(props)) == count {
+				moreData = 1
+				break
+			}
+			props = append(props, tpm2.TaggedProperty{Tag: prop, Value: value})
+		}
+		binary.Write(&out, binary.BigEndian, uint32(len(props)))
+		for _, prop := range props {
+			binary.Write(&out, binary.BigEndian, prop)
+		}
+	case tpm2.CapabilityPCRs:
+		var all []int
+		for i := 0; i < NumPCRs; i++ {
+			all = append(all, i)
+		}
+		binary.Write(&out, binary.BigEndian, uint32(len(pcrBanks)))
+		for _, alg := range pcrBanks {
+			sel, _ := tpmutil.Pack(alg, byte(3), tpmutil.RawBytes(pcrBitmap(all)))
+			out.Write(sel)
+		}
+	case tpm2.CapabilityHandles:
+		var handles []tpmutil.Handle
+		handleType := property >> handleTypeShift
+		switch handleType {
+		case uint32(tpm2.HandleTypePersistent):
+			for h := range s.state.Persistent {
+				handles = append(handles, h)
+			}
+		case uint32(tpm2.HandleTypeNVIndex):
+			for h := range s.state.NVIndices {
+				handles = append(handles, h)
+			}
+		case uint32(tpm2.HandleTypeTransient):
+			for h := range s.transient {
+				handles = append(handles, h)
+			}
+		case uint32(tpm2.HandleTypeHMACSession), uint32(tpm2.HandleTypePolicySession):
+			for h := range s.sessions {
+				if uint32(h)>>handleTypeShift == handleType {
+					handles = append(handles, h)
+				}
+			}
+		default:
+			return nil, rcHandle.param(2)
+		}
+		var selected []tpmutil.Handle
+		for _, h := range sortedHandles(handles) {
+			if uint32(h) < property {
+				continue
+			}
+			if uint32(len(selected)) == count {
+				moreData = 1
+				break
+			}
+			selected = append(selected, h)
+		}
+		binary.Write(&out, binary.BigEndian, uint32(len(selected)))
+		for _, h := range selected {
+			binary.Write(&out, binary.BigEndian, h)
+		}
+	default:
+		return nil, rcValue.param(1)
+	}
+	params, _ := tpmutil.Pack(moreData, capa)
+	return &response{params: append(params, out.Bytes()...)}, rcSuccess
+}
+
+func (s *Simulator) getRandom(c *conn, cmd *command) (*response, tpmRC) {
+	var count uint16
+	if rc := unpack(cmd.params, 1, &count); rc != rcSuccess {
+		return nil, rc
+	}
+	if count > maxRandomBytes {
+		count = maxRandomBytes
+	}
+	random := make([]byte, count)
+	if _, err := rand.Read(random); err != nil {
+		return nil, rcFailure
+	}
+	params, _ := tpmutil.Pack(tpmutil.U16Bytes(random))
+	return &response{params: params}, rcSuccess
+}
+
+//pcrSelection is a single TPMS_PCR_SELECTION
+type pcrSelection struct {
+	hash tpm2.Algorithm
+	pcrs []int
+}
+
+//pcrBitmap converts PCR indices into a TPMS_PCR_SELECTION bitmap
+func pcrBitmap(pcrs []int) []byte {
+	bitmap := make([]byte, 3)
+	for _, pcr := range pcrs {
+		bitmap[pcr/8] |= 1 << (pcr % 8)
+	}
+	return bitmap
+}
+
+//readPCRSelection parses TPML_PCR_SELECTION, returning
+//its wire format and the parsed selections
+func (s *Simulator) readPCRSelection(buf *bytes.Buffer, n int) ([]byte, []pcrSelection, tpmRC) {
+	start := buf.Bytes()
+	var count uint32
+	if rc := unpack(buf, n, &count); rc != rcSuccess {
+		return nil, nil, rc
+	}
+	var sels []pcrSelection
+	for i := uint32(0); i < count; i++ {
+		var sel pcrSelection
+		var size byte
+		if rc := unpack(buf, n, &sel.hash, &size); rc != rcSuccess {
+			return nil, nil, rc
+		}
+		if _, ok := s.pcrs[sel.hash]; !ok {
+			return nil, nil, rcHash.param(n)
+		}
+		bitmap := buf.Next(int(size))
+		if len(bitmap) != int(size) {
+			return nil, nil, rcInsufficient.param(n)
+		}
+		for j, b := range bitmap {
+			for k := 0; k < 8; k++ {
+				if b&(1<<k) == 0 {
+					continue
+				}
+				if 8*j+k >= NumPCRs {
+					return nil, nil, rcValue.param(n)
+				}
+				sel.pcrs = append(sel.pcrs, 8*j+k)
+			}
+		}
+		sels = append(sels, sel)
+	}
+	raw := start[:len(start)-buf.Len()]
+	return append([]byte{}, raw...), sels, rcSuccess
+}
+
+//pcrDigest hashes values of selected PCRs with hash algorithm alg
+func (s *Simulator) pcrDigest(alg tpm2.Algorithm, sels []pcrSelection) ([]byte, tpmRC) {
+	var values [][]byte
+	for _, sel := range sels {
+		for _, pcr := range sel.pcrs {
+			values = append(values, s.pcrs[sel.hash][pcr])
+		}
+	}
+	digest, err := hashOf(alg, values...)
+	if err != nil {
+		return nil, rcHash
+	}
+	return digest, rcSuccess
+}
+
+//readSensitiveCreate parses TPM2B_SENSITIVE_CREATE
+func readSensitiveCreate(buf *bytes.Buffer) ([]byte, []byte, tpmRC) {
+	var sensitive tpmutil.U16Bytes
+	if rc := unpack(buf, 1, &sensitive); rc != rcSuccess {
+		return nil, nil, rc
+	}
+	var userAuth, data tpmutil.U16Bytes
+	if _, err := tpmutil.Unpack(sensitive, &userAuth, &data); err != nil {
+		return nil, nil, rcSize.param(1)
+	}
+	return userAuth, data, rcSuccess
+}
+
+//createObject is the common part of TPM2_Create and TPM2_CreatePrimary.
+//It returns the new object, and TPM2B_CREATION_DATA, creation hash and
+//creation ticket encoded for the response.
+func (s *Simulator) createObject(cmd *command, parentName []byte,
+	hierarchy tpmutil.Handle) (*object, []byte, tpmRC) {
+	userAuth, data, rc := readSensitiveCreate(cmd.params)
+	if rc != rcSuccess {
+		return nil, nil, rc
+	}
+	var publicBytes, outsideInfo tpmutil.U16Bytes
+	if rc := unpack(cmd.params, 2, &publicBytes, &outsideInfo); rc != rcSuccess {
+		return nil, nil, rc
+	}
+	pub, err := tpm2.DecodePublic(publicBytes)
+	if err != nil {
+		return nil, nil, rcValue.param(2)
+	}
+	rawSel, sels, rc := s.readPCRSelection(cmd.params, 4)
+	if rc != rcSuccess {
+		return nil, nil, rc
+	}
+	obj, rc := newObject(pub, userAuth, data)
+	if rc != rcSuccess {
+		return nil, nil, rc.param(2)
+	}
+	obj.Hierarchy = hierarchy
+
+	var pcrDigest []byte
+	if len(sels) != 0 {
+		if pcrDigest, rc = s.pcrDigest(pub.NameAlg, sels); rc != rcSuccess {
+			return nil, nil, rc
+		}
+	}
+	//TPMS_CREATION_DATA
+	creationData, _ := tpmutil.Pack(tpmutil.RawBytes(rawSel),
+		tpmutil.U16Bytes(pcrDigest), byte(0), pub.NameAlg,
+		tpmutil.U16Bytes(parentName), tpmutil.U16Bytes(parentName),
+		outsideInfo)
+	creationHash, _ := hashOf(pub.NameAlg, creationData)
+	name, _ := obj.name()
+	mac := hmac.New(sha256.New, s.state.Proof)
+	mac.Write(creationHash)
+	mac.Write(name)
+	out, _ := tpmutil.Pack(tpmutil.U16Bytes(creationData),
+		tpmutil.U16Bytes(creationHash),
+		tagCreationTicket, hierarchy, tpmutil.U16Bytes(mac.Sum(nil)))
+	return obj, out, rcSuccess
+}
+
+func (s *Simulator) createPrimary(c *conn, cmd *command) (*response, tpmRC) {
+	hierarchy := cmd.handles[0]
+	switch hierarchy {
+	case tpm2.HandleOwner, tpm2.HandleEndorsement, tpm2.HandlePlatform, tpm2.HandleNull:
+	default:
+		return nil, rcHierarchy.handle(1)
+	}
+	parentName, _ := tpmutil.Pack(hierarchy)
+	obj, creation, rc := s.createObject(cmd, parentName, hierarchy)
+	if rc != rcSuccess {
+		return nil, rc
+	}
+	//primary keys get a protection seed, so that they can be parents
+	if obj.isStorageKey() || len(obj.SeedValue) == 0 {
+		obj.SeedValue = make([]byte, protectKeyLength)
+		rand.Read(obj.SeedValue)
+	}
+	h := s.allocHandle(transientHandle)
+	s.transient[h] = obj
+	c.handles[h] = true
+	name, _ := obj.name()
+	params, _ := tpmutil.Pack(tpmutil.U16Bytes(obj.Public))
+	params = append(params, creation...)
+	nameBytes, _ := tpmutil.Pack(tpmutil.U16Bytes(name))
+	params = append(params, nameBytes...)
+	return &response{handles: []tpmutil.Handle{h}, params: params}, rcSuccess
+}
+
+func (s *Simulator) create(c *conn, cmd *command) (*response, tpmRC) {
+	parent := s.lookupObject(cmd.handles[0])
+	if parent == nil {
+		return nil, rcHandle.handle(1)
+	}
+	if !parent.isStorageKey() {
+		return nil, rcType.handle(1)
+	}
+	parentName, _ := parent.name()
+	obj, creation, rc := s.createObject(cmd, parentName, parent.Hierarchy)
+	if rc != rcSuccess {
+		return nil, rc
+	}
+	private, err := parent.wrap(obj)
+	if err != nil {
+		return nil, rcFailure
+	}
+	params, _ := tpmutil.Pack(tpmutil.U16Bytes(private), tpmutil.U16Bytes(obj.Public))
+	params = append(params, creation...)
+	return &response{params: params}, rcSuccess
+}
+
+func (s *Simulator) load(c *conn, cmd *command) (*response, tpmRC) {
+	parent := s.lookupObject(cmd.handles[0])
+	if parent == nil {
+		return nil, rcHandle.handle(1)
+	}
+	if !parent.isStorageKey() {
+		return nil, rcType.handle(1)
+	}
+	var private, public tpmutil.U16Bytes
+	if rc := unpack(cmd.params, 1, &private, &public); rc != rcSuccess {
+		return nil, rc
+	}
+	if _, err := tpm2.DecodePublic(public); err != nil {
+		return nil, rcValue.param(2)
+	}
+	obj, err := parent.unwrap(public, private)
+	if err != nil {
+		return nil, rcIntegrity.param(1)
+	}
+	h := s.allocHandle(transientHandle)
+	s.transient[h] = obj
+	c.handles[h] = true
+	name, _ := obj.name()
+	params, _ := tpmutil.Pack(tpmutil.U16Bytes(name))
+	return &response{handles: []tpmutil.Handle{h}, params: params}, rcSuccess
+}
+
+func (s *Simulator) flushContext(c *conn, cmd *command) (*response, tpmRC) {
+	var h tpmutil.Handle
+	if rc := unpack(cmd.params, 1, &h); rc != rcSuccess {
+		return nil, rc
+	}
+	if !s.flushHandle(h) {
+		return nil, rcHandle.param(1)
+	}
+	delete(c.handles, h)
+	return &response{}, rcSuccess
+}
+
+func (s *Simulator) evictControl(c *conn, cmd *command) (*response, tpmRC) {
+	auth, objHandle := cmd.handles[0], cmd.handles[1]
+	var persistent tpmutil.Handle
+	if rc := unpack(cmd.params, 1, &persistent); rc != rcSuccess {
+		return nil, rc
+	}
+	var min, max tpmutil.Handle
+	switch auth {
+	case tpm2.HandleOwner:
+		min, max = ownerPersistentMin, ownerPersistentMax
+	case tpm2.HandlePlatform:
+		min, max = platPersistentMin, platPersistentMax
+	default:
+		return nil, rcHierarchy.handle(1)
+	}
+	if persistent < min || persistent > max {
+		return nil, rcValue.param(1)
+	}
+	if _, ok := s.state.Persistent[objHandle]; ok {
+		//evict persistent object
+		if objHandle != persistent {
+			return nil, rcHandle.param(1)
+		}
+		delete(s.state.Persistent, objHandle)
+	} else {
+		obj, ok := s.transient[objHandle]
+		if !ok {
+			return nil, rcHandle.handle(2)
+		}
+		if _, ok := s.state.Persistent[persistent]; ok {
+			return nil, rcNVDefined
+		}
+		s.state.Persistent[persistent] = obj.copyObject()
+	}
+	if err := s.saveState(); err != nil {
+		return nil, rcFailure
+	}
+	return &response{}, rcSuccess
+}
+
+func (s *Simulator) readPublic(c *conn, cmd *command) (*response, tpmRC) {
+	obj := s.lookupObject(cmd.handles[0])
+	if obj == nil {
+		return nil, rcHandle.handle(1)
+	}
+	name, err := obj.name()
+	if err != nil {
+		return nil, rcFailure
+	}
+	params, _ := tpmutil.Pack(tpmutil.U16Bytes(obj.Public),
+		tpmutil.U16Bytes(name), tpmutil.U16Bytes(name))
+	return &response{params: params}, rcSuccess
+}
+
+func (s *Simulator) startAuthSession(c *conn, cmd *command) (*response, tpmRC) {
+	if cmd.handles[0] != tpm2.HandleNull {
+		//salted sessions are not supported
+		return nil, rcHandle.handle(1)
+	}
+	if cmd.handles[1] != tpm2.HandleNull {
+		//bound sessions are not supported
+		return nil, rcHandle.handle(2)
+	}
+	var nonceCaller, salt tpmutil.U16Bytes
+	var sessionType tpm2.SessionType
+	var symmetric, hashAlg tpm2.Algorithm
+	if rc := unpack(cmd.params, 1, &nonceCaller, &salt, &sessionType, &symmetric); rc != rcSuccess {
+		return nil, rc
+	}
+	if len(nonceCaller) < 16 || len(nonceCaller) > maxNonceSize {
+		return nil, rcSize.param(1)
+	}
+	if len(salt) != 0 {
+		return nil, rcValue.param(2)
+	}
+	if symmetric != tpm2.AlgNull {
+		return nil, rcSymmetric.param(4)
+	}
+	if rc := unpack(cmd.params, 5, &hashAlg); rc != rcSuccess {
+		return nil, rc
+	}
+	hash, err := hashAlg.Hash()
+	if err != nil {
+		return nil, rcHash.param(5)
+	}
+	var base uint32
+	switch sessionType {
+	case tpm2.SessionHMAC:
+		base = sessionHandleHMAC
+	case tpm2.SessionPolicy, tpm2.SessionTrial:
+		base = sessionHandlePol
+	default:
+		return nil, rcValue.param(3)
+	}
+	sess := &session{
+		sessionType: sessionType,
+		hashAlg:     hashAlg,
+		nonceTPM:    make([]byte, hash.Size()),
+	}
+	if _, err := rand.Read(sess.nonceTPM); err != nil {
+		return nil, rcFailure
+	}
+	sess.reset()
+	h := s.allocHandle(base)
+	s.sessions[h] = sess
+	c.handles[h] = true
+	params, _ := tpmutil.Pack(tpmutil.U16Bytes(sess.nonceTPM))
+	return &response{handles: []tpmutil.Handle{h}, params: params}, rcSuccess
+}
+
+//policySession returns a policy or trial session
+func (s *Simulator) policySession(h tpmutil.Handle) (*session, tpmRC) {
+	sess, ok := s.sessions[h]
+	if !ok || sess.sessionType == tpm2.SessionHMAC {
+		return nil, rcHandle.handle(1)
+	}
+	return sess, rcSuccess
+}
+
+func (s *Simulator) policyPCR(c *conn, cmd *command) (*response, tpmRC) {
+	sess, rc := s.policySession(cmd.handles[0])
+	if rc != rcSuccess {
+		return nil, rc
+	}
+	var expected tpmutil.U16Bytes
+	if rc := unpack(cmd.params, 1, &expected); rc != rcSuccess {
+		return nil, rc
+	}
+	rawSel, sels, rc := s.readPCRSelection(cmd.params, 2)
+	if rc != rcSuccess {
+		return nil, rc
+	}
+	digest := []byte(expected)
+	if sess.sessionType == tpm2.SessionPolicy || len(expected) == 0 {
+		current, rc := s.pcrDigest(sess.hashAlg, sels)
+		if rc != rcSuccess {
+			return nil, rc
+		}
+		if len(expected) != 0 && !bytes.Equal(expected, current) {
+			return nil, rcValue.param(1)
+		}
+		digest = current
+	}
+	code, _ := tpmutil.Pack(tpm2.CmdPolicyPCR)
+	sess.policyDigest, _ = hashOf(sess.hashAlg, sess.policyDigest, code, rawSel, digest)
+	if sess.sessionType == tpm2.SessionPolicy {
+		sess.pcrCheck = true
+		sess.pcrCounter = s.pcrUpdateCounter
+	}
+	return &response{}, rcSuccess
+}
+
+func (s *Simulator) policyGetDigest(c *conn, cmd *command) (*response, tpmRC) {
+	sess, rc := s.policySession(cmd.handles[0])
+	if rc != rcSuccess {
+		return nil, rc
+	}
+	params, _ := tpmutil.Pack(tpmutil.U16Bytes(sess.policyDigest))
+	return &response{params: params}, rcSuccess
+}
+
+func (s *Simulator) unseal(c *conn, cmd *command) (*response, tpmRC) {
+	obj := s.lookupObject(cmd.handles[0])
+	pub, err := obj.public()
+	if err != nil {
+		return nil, rcFailure
+	}
+	if pub.Type != tpm2.AlgKeyedHash || pub.Attributes&(tpm2.FlagSign|tpm2.FlagDecrypt) != 0 {
+		return nil, rcType.handle(1)
+	}
+	params, _ := tpmutil.Pack(tpmutil.U16Bytes(obj.Sensitive))
+	return &response{params: params}, rcSuccess
+}
+
+//readSigScheme parses TPMT_SIG_SCHEME
+func readSigScheme(buf *bytes.Buffer, n int) (*tpm2.SigScheme, tpmRC) {
+	var scheme tpm2.SigScheme
+	if rc := unpack(buf, n, &scheme.Alg); rc != rcSuccess {
+		return nil, rc
+	}
+	if scheme.Alg == tpm2.AlgNull {
+		return nil, rcSuccess
+	}
+	if rc := unpack(buf, n, &scheme.Hash); rc != rcSuccess {
+		return nil, rc
+	}
+	if scheme.Alg.UsesCount() {
+		if rc := unpack(buf, n, &scheme.Count); rc != rcSuccess {
+			return nil, rc
+		}
+	}
+	return &scheme, rcSuccess
+}
+
+func (s *Simulator) sign(c *conn, cmd *command) (*response, tpmRC) {
+	obj := s.lookupObject(cmd.handles[0])
+	var digest tpmutil.U16Bytes
+	if rc := unpack(cmd.params, 1, &digest); rc != rcSuccess {
+		return nil, rc
+	}
+	requested, rc := readSigScheme(cmd.params, 2)
+	if rc != rcSuccess {
+		return nil, rc
+	}
+	var ticket tpm2.Ticket
+	if rc := unpack(cmd.params, 3, &ticket); rc != rcSuccess {
+		return nil, rc
+	}
+	scheme, rc := obj.signScheme(requested)
+	if rc != rcSuccess {
+		return nil, rc.param(2)
+	}
+	if obj.attributes()&tpm2.FlagRestricted != 0 {
+		//restricted keys sign only digests produced by TPM2_Hash,
+		//which comes with a hash check ticket. TPM2_Hash is not
+		//implemented, so there are no valid tickets.
+		return nil, rcTicket.param(3)
+	}
+	sig, rc := obj.sign(scheme, digest)
+	if rc != rcSuccess {
+		return nil, rc.param(1)
+	}
+	return &response{params: sig}, rcSuccess
+}
+
+func (s *Simulator) quote(c *conn, cmd *command) (*response, tpmRC) {
+	obj := s.lookupObject(cmd.handles[0])
+	var qualifyingData tpmutil.U16Bytes
+	if rc := unpack(cmd.params, 1, &qualifyingData); rc != rcSuccess {
+		return nil, rc
+	}
+	if len(qualifyingData) > maxNonceSize {
+		return nil, rcSize.param(1)
+	}
+	requested, rc := readSigScheme(cmd.params, 2)
+	if rc != rcSuccess {
+		return nil, rc
+	}
+	rawSel, sels, rc := s.readPCRSelection(cmd.params, 3)
+	if rc != rcSuccess {
+		return nil, rc
+	}
+	scheme, rc := obj.signScheme(requested)
+	if rc != rcSuccess {
+		return nil, rc.param(2)
+	}
+	pcrDigest, rc := s.pcrDigest(scheme.Hash, sels)
+	if rc != rcSuccess {
+		return nil, rc.param(2)
+	}
+	name, err := obj.name()
+	if err != nil {
+		return nil, rcFailure
+	}
+	//TPMS_ATTEST with TPMS_QUOTE_INFO
+	clock := uint64(time.Since(s.startTime) / time.Millisecond)
+	attest, err := tpmutil.Pack(uint32(attestMagic), tpm2.TagAttestQuote,
+		tpmutil.U16Bytes(name), qualifyingData,
+		tpm2.ClockInfo{Clock: clock, ResetCount: s.state.ResetCount, Safe: 1},
+		uint64(simFirmwareVer1)<<32,
+		tpmutil.RawBytes(rawSel), tpmutil.U16Bytes(pcrDigest))
+	if err != nil {
+		return nil, rcFailure
+	}
+	digest, err := hashOf(scheme.Hash, attest)
+	if err != nil {
+		return nil, rcHash.param(2)
+	}
+	sig, rc := obj.sign(scheme, digest)
+	if rc != rcSuccess {
+		return nil, rc.param(2)
+	}
+	params, _ := tpmutil.Pack(tpmutil.U16Bytes(attest))
+	return &response{params: append(params, sig...)}, rcSuccess
+}
+
+//maxPCRsPerRead is the number of digests returned by a single TPM2_PCR_Read
+const maxPCRsPerRead = 8
+
+func (s *Simulator) pcrRead(c *conn, cmd *command) (*response, tpmRC) {
+	_, sels, rc := s.readPCRSelection(cmd.params, 1)
+	if rc != rcSuccess {
+		return nil, rc
+	}
+	var digests [][]byte
+	var out []byte
+	selCount := 0
+	for _, sel := range sels {
+		var pcrs []int
+		for _, pcr := range sel.pcrs {
+			if len(digests) == maxPCRsPerRead {
+				break
+			}
+			pcrs = append(pcrs, pcr)
+			digests = append(digests, s.pcrs[sel.hash][pcr])
+		}
+		selBytes, _ := tpmutil.Pack(sel.hash, byte(3), tpmutil.RawBytes(pcrBitmap(pcrs)))
+		out = append(out, selBytes...)
+		selCount++
+	}
+	params, _ := tpmutil.Pack(s.pcrUpdateCounter, uint32(selCount))
+	params = append(params, out...)
+	digestCount, _ := tpmutil.Pack(uint32(len(digests)))
+	params = append(params, digestCount...)
+	for _, d := range digests {
+		digest, _ := tpmutil.Pack(tpmutil.U16Bytes(d))
+		params = append(params, digest...)
+	}
+	return &response{params: params}, rcSuccess
+}
+
+func (s *Simulator) pcrExtend(c *conn, cmd *command) (*response, tpmRC) {
+	pcr := cmd.handles[0]
+	if !isPCR(pcr) {
+		return nil, rcValue.handle(1)
+	}
+	var count uint32
+	if rc := unpack(cmd.params, 1, &count); rc != rcSuccess {
+		return nil, rc
+	}
+	type extend struct {
+		alg    tpm2.Algorithm
+		digest []byte
+	}
+	var extends []extend
+	for i := uint32(0); i < count; i++ {
+		var alg tpm2.Algorithm
+		if rc := unpack(cmd.params, 1, &alg); rc != rcSuccess {
+			return nil, rc
+		}
+		hash, err := alg.Hash()
+		if err != nil {
+			return nil, rcHash.param(1)
+		}
+		digest := cmd.params.Next(hash.Size())
+		if len(digest) != hash.Size() {
+			return nil, rcInsufficient.param(1)
+		}
+		extends = append(extends, extend{alg, digest})
+	}
+	for _, e := range extends {
+		//banks not implemented by the simulator are skipped
+		if _, ok := s.pcrs[e.alg]; ok {
+			s.extendPCR(e.alg, int(pcr), e.digest)
+		}
+	}
+	return &response{}, rcSuccess
+}
+
+func (s *Simulator) nvDefineSpace(c *conn, cmd *command) (*response, tpmRC) {
+	switch cmd.handles[0] {
+	case tpm2.HandleOwner, tpm2.HandlePlatform:
+	default:
+		return nil, rcHierarchy.handle(1)
+	}
+	var authValue, publicInfo tpmutil.U16Bytes
+	if rc := unpack(cmd.params, 1, &authValue, &publicInfo); rc != rcSuccess {
+		return nil, rc
+	}
+	var index tpmutil.Handle
+	var dataSize uint16
+	nv := &nvIndex{}
+	var policy tpmutil.U16Bytes
+	if _, err := tpmutil.Unpack(publicInfo, &index, &nv.NameAlg,
+		&nv.Attributes, &policy, &dataSize); err != nil {
+		return nil, rcSize.param(2)
+	}
+	nv.AuthPolicy = policy
+	nv.AuthValue = authValue
+	if !isNVIndex(index) {
+		return nil, rcValue.param(2)
+	}
+	if _, err := nv.NameAlg.Hash(); err != nil {
+		return nil, rcHash.param(2)
+	}
+	if nv.Attributes&tpm2.AttrWritten != 0 {
+		return nil, rcAttributes.param(2)
+	}
+	if nv.Attributes&(tpm2.AttrPPWrite|tpm2.AttrOwnerWrite|tpm2.AttrAuthWrite|tpm2.AttrPolicyWrite) == 0 ||
+		nv.Attributes&(tpm2.AttrPPRead|tpm2.AttrOwnerRead|tpm2.AttrAuthRead|tpm2.AttrPolicyRead) == 0 {
+		return nil, rcAttributes.param(2)
+	}
+	if dataSize > maxNVIndexSize {
+		return nil, rcSize.param(2)
+	}
+	if _, ok := s.state.NVIndices[index]; ok {
+		return nil, rcNVDefined
+	}
+	nv.Data = make([]byte, dataSize)
+	for i := range nv.Data {
+		nv.Data[i] = 0xFF
+	}
+	s.state.NVIndices[index] = nv
+	if err := s.saveState(); err != nil {
+		return nil, rcFailure
+	}
+	return &response{}, rcSuccess
+}
+
+func (s *Simulator) nvUndefineSpace(c *conn, cmd *command) (*response, tpmRC) {
+	switch cmd.handles[0] {
+	case tpm2.HandleOwner, tpm2.HandlePlatform:
+	default:
+		return nil, rcHierarchy.handle(1)
+	}
+	index := cmd.handles[1]
+	if _, ok := s.state.NVIndices[index]; !ok {
+		return nil, rcHandle.handle(2)
+	}
+	delete(s.state.NVIndices, index)
+	if err := s.saveState(); err != nil {
+		return nil, rcFailure
+	}
+	return &response{}, rcSuccess
+}
+
+//nvAccess checks if authHandle may access the NV index
+func (s *Simulator) nvAccess(cmd *command, ownerAttr, authAttr, policyAttr tpm2.NVAttr) (*nvIndex, tpmRC) {
+	authHandle, index := cmd.handles[0], cmd.handles[1]
+	nv, ok := s.state.NVIndices[index]
+	if !ok {
+		return nil, rcHandle.handle(2)
+	}
+	switch {
+	case authHandle == tpm2.HandleOwner && nv.Attributes&ownerAttr != 0:
+	case authHandle == index && cmd.auths[0].Session == tpm2.HandlePasswordSession &&
+		nv.Attributes&authAttr != 0:
+	case authHandle == index && cmd.auths[0].Session != tpm2.HandlePasswordSession &&
+		nv.Attributes&policyAttr != 0:
+	default:
+		return nil, rcNVAuthorization
+	}
+	return nv, rcSuccess
+}
+
+func (s *Simulator) nvWrite(c *conn, cmd *command) (*response, tpmRC) {
+	nv, rc := s.nvAccess(cmd, tpm2.AttrOwnerWrite, tpm2.AttrAuthWrite, tpm2.AttrPolicyWrite)
+	if rc != rcSuccess {
+		return nil, rc
+	}
+	var data tpmutil.U16Bytes
+	var offset uint16
+	if rc := unpack(cmd.params, 1, &data, &offset); rc != rcSuccess {
+		return nil, rc
+	}
+	if len(data) > maxNVBufferSize {
+		return nil, rcValue.param(1)
+	}
+	if int(offset)+len(data) > len(nv.Data) {
+		return nil, rcNVRange
+	}
+	copy(nv.Data[offset:], data)
+	nv.Attributes |= tpm2.AttrWritten
+	if err := s.saveState(); err != nil {
+		return nil, rcFailure
+	}
+	return &response{}, rcSuccess
+}
+
+func (s *Simulator) nvRead(c *conn, cmd *command) (*response, tpmRC) {
+	nv, rc := s.nvAccess(cmd, tpm2.AttrOwnerRead, tpm2.AttrAuthRead, tpm2.AttrPolicyRead)
+	if rc != rcSuccess {
+		return nil, rc
+	}
+	var size, offset uint16
+	if rc := unpack(cmd.params, 1, &size, &offset); rc != rcSuccess {
+		return nil, rc
+	}
+	if nv.Attributes&tpm2.AttrWritten == 0 {
+		return nil, rcNVUninitialized
+	}
+	if size > maxNVBufferSize {
+		return nil, rcValue.param(1)
+	}
+	if int(offset)+int(size) > len(nv.Data) {
+		return nil, rcNVRange
+	}
+	params, _ := tpmutil.Pack(tpmutil.U16Bytes(nv.Data[offset : offset+size]))
+	return &response{params: params}, rcSuccess
+}
+
+func (s *Simulator) nvReadPublic(c *conn, cmd *command) (*response, tpmRC) {
+	index := cmd.handles[0]
+	nv, ok := s.state.NVIndices[index]
+	if !ok {
+		return nil, rcHandle.handle(1)
+	}
+	params, _ := tpmutil.Pack(tpmutil.U16Bytes(nv.public(index)),
+		tpmutil.U16Bytes(nv.name(index)))
+	return &response{params: params}, rcSuccess
+}
+
+func (s *Simulator) ecdhKeyGen(c *conn, cmd *command) (*response, tpmRC) {
+	obj := s.lookupObject(cmd.handles[0])
+	if obj == nil {
+		return nil, rcHandle.handle(1)
+	}
+	key, err := obj.ecdsaKey()
+	if err != nil {
+		return nil, rcKey.handle(1)
+	}
+	ephemeral, err := ecdsa.GenerateKey(key.Curve, rand.Reader)
+	if err != nil {
+		return nil, rcFailure
+	}
+	size := curveBytes(key.Curve)
+	zx, zy := key.Curve.ScalarMult(key.X, key.Y, padBytes(ephemeral.D.Bytes(), size))
+	zPoint, _ := tpmutil.Pack(tpmutil.U16Bytes(padBytes(zx.Bytes(), size)),
+		tpmutil.U16Bytes(padBytes(zy.Bytes(), size)))
+	pubPoint, _ := tpmutil.Pack(tpmutil.U16Bytes(padBytes(ephemeral.X.Bytes(), size)),
+		tpmutil.U16Bytes(padBytes(ephemeral.Y.Bytes(), size)))
+	params, _ := tpmutil.Pack(tpmutil.U16Bytes(zPoint), tpmutil.U16Bytes(pubPoint))
+	return &response{params: params}, rcSuccess
+}
+
+func (s *Simulator) ecdhZGen(c *conn, cmd *command) (*response, tpmRC) {
+	obj := s.lookupObject(cmd.handles[0])
+	attrs := obj.attributes()
+	if attrs&tpm2.FlagDecrypt == 0 || attrs&tpm2.FlagRestricted != 0 {
+		return nil, rcAttributes.handle(1)
+	}
+	var inPoint tpmutil.U16Bytes
+	if rc := unpack(cmd.params, 1, &inPoint); rc != rcSuccess {
+		return nil, rc
+	}
+	var point tpm2.ECPoint
+	if _, err := tpmutil.Unpack(inPoint, &point.XRaw, &point.YRaw); err != nil {
+		return nil, rcSize.param(1)
+	}
+	z, rc := obj.ecdhZ(point.X(), point.Y())
+	if rc != rcSuccess {
+		return nil, rc.param(1)
+	}
+	zPoint, _ := tpmutil.Pack(z.XRaw, z.YRaw)
+	params, _ := tpmutil.Pack(tpmutil.U16Bytes(zPoint))
+	return &response{params: params}, rcSuccess
+}
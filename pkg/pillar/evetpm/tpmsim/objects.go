@@ -0,0 +1,406 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package tpmsim
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"math/big"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+//object is a key or a sealed data object loaded into the simulator
+type object struct {
+	//Public is the TPMT_PUBLIC area of the object, in wire format
+	Public []byte
+	//AuthValue is the object password (userAuth)
+	AuthValue []byte
+	//Sensitive is the private key (ECC scalar or PKCS#1 RSA key),
+	//or the sealed data for keyed hash objects
+	Sensitive []byte
+	//SeedValue protects children of storage keys
+	SeedValue []byte
+	//Hierarchy the object belongs to
+	Hierarchy tpmutil.Handle
+}
+
+var curves = map[tpm2.EllipticCurve]elliptic.Curve{
+	tpm2.CurveNISTP224: elliptic.P224(),
+	tpm2.CurveNISTP256: elliptic.P256(),
+	tpm2.CurveNISTP384: elliptic.P384(),
+	tpm2.CurveNISTP521: elliptic.P521(),
+}
+
+var errUnsupportedKey = errors.New("unsupported key type")
+
+func (o *object) public() (tpm2.Public, error) {
+	return tpm2.DecodePublic(o.Public)
+}
+
+func (o *object) attributes() tpm2.KeyProp {
+	pub, err := o.public()
+	if err != nil {
+		return 0
+	}
+	return pub.Attributes
+}
+
+//isStorageKey tells if the object can be a parent of other objects
+func (o *object) isStorageKey() bool {
+	attrs := o.attributes()
+	return attrs&tpm2.FlagRestricted != 0 && attrs&tpm2.FlagDecrypt != 0 &&
+		len(o.SeedValue) != 0
+}
+
+//name returns TPM2B_NAME contents of the object
+func (o *object) name() ([]byte, error) {
+	pub, err := o.public()
+	if err != nil {
+		return nil, err
+	}
+	name, err := pub.Name()
+	if err != nil {
+		return nil, err
+	}
+	return name.Digest.Encode()
+}
+
+func (o *object) ecdsaKey() (*ecdsa.PrivateKey, error) {
+	pub, err := o.public()
+	if err != nil {
+		return nil, err
+	}
+	if pub.Type != tpm2.AlgECC {
+		return nil, errUnsupportedKey
+	}
+	curve, ok := curves[pub.ECCParameters.CurveID]
+	if !ok {
+		return nil, errUnsupportedKey
+	}
+	key := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(o.Sensitive)}
+	key.Curve = curve
+	key.X, key.Y = pub.ECCParameters.Point.X(), pub.ECCParameters.Point.Y()
+	return key, nil
+}
+
+func (o *object) rsaKey() (*rsa.PrivateKey, error) {
+	pub, err := o.public()
+	if err != nil {
+		return nil, err
+	}
+	if pub.Type != tpm2.AlgRSA {
+		return nil, errUnsupportedKey
+	}
+	return x509.ParsePKCS1PrivateKey(o.Sensitive)
+}
+
+//padBytes left-pads b with zeroes to size bytes
+func padBytes(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	return append(make([]byte, size-len(b)), b...)
+}
+
+func curveBytes(curve elliptic.Curve) int {
+	return (curve.Params().BitSize + 7) / 8
+}
+
+//newObject creates a new object from the template in pub,
+//as TPM2_Create and TPM2_CreatePrimary do
+func newObject(pub tpm2.Public, authValue, data []byte) (*object, tpmRC) {
+	obj := &object{AuthValue: authValue}
+	attrs := pub.Attributes
+	if attrs&tpm2.FlagRestricted != 0 &&
+		attrs&tpm2.FlagSign != 0 && attrs&tpm2.FlagDecrypt != 0 {
+		return nil, rcAttributes
+	}
+	if _, err := pub.NameAlg.Hash(); err != nil {
+		return nil, rcHash
+	}
+	switch pub.Type {
+	case tpm2.AlgECC:
+		if pub.ECCParameters == nil || len(data) != 0 {
+			return nil, rcValue
+		}
+		curve, ok := curves[pub.ECCParameters.CurveID]
+		if !ok {
+			return nil, rcCurve
+		}
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return nil, rcFailure
+		}
+		size := curveBytes(curve)
+		pub.ECCParameters.Point = tpm2.ECPoint{
+			XRaw: padBytes(key.X.Bytes(), size),
+			YRaw: padBytes(key.Y.Bytes(), size),
+		}
+		obj.Sensitive = padBytes(key.D.Bytes(), size)
+	case tpm2.AlgRSA:
+		if pub.RSAParameters == nil || len(data) != 0 {
+			return nil, rcValue
+		}
+		if pub.RSAParameters.Exponent() != 1<<16+1 {
+			return nil, rcValue
+		}
+		switch pub.RSAParameters.KeyBits {
+		case 1024, 2048, 3072, 4096:
+		default:
+			return nil, rcKeySize
+		}
+		key, err := rsa.GenerateKey(rand.Reader, int(pub.RSAParameters.KeyBits))
+		if err != nil {
+			return nil, rcFailure
+		}
+		pub.RSAParameters.ModulusRaw = padBytes(key.N.Bytes(),
+			int(pub.RSAParameters.KeyBits)/8)
+		obj.Sensitive = x509.MarshalPKCS1PrivateKey(key)
+	case tpm2.AlgKeyedHash:
+		if attrs&tpm2.FlagSensitiveDataOrigin != 0 || len(data) == 0 {
+			//we support only sealed data objects
+			return nil, rcAttributes
+		}
+		if pub.KeyedHashParameters == nil {
+			pub.KeyedHashParameters = &tpm2.KeyedHashParams{Alg: tpm2.AlgNull}
+		}
+		if pub.KeyedHashParameters.Alg != tpm2.AlgNull {
+			return nil, rcScheme
+		}
+		obj.Sensitive = data
+		seed := make([]byte, protectKeyLength)
+		if _, err := rand.Read(seed); err != nil {
+			return nil, rcFailure
+		}
+		hash, _ := pub.NameAlg.Hash()
+		h := hash.New()
+		h.Write(seed)
+		h.Write(data)
+		pub.KeyedHashParameters.Unique = h.Sum(nil)
+	default:
+		return nil, rcType
+	}
+	if attrs&tpm2.FlagRestricted != 0 && attrs&tpm2.FlagDecrypt != 0 {
+		obj.SeedValue = make([]byte, protectKeyLength)
+		if _, err := rand.Read(obj.SeedValue); err != nil {
+			return nil, rcFailure
+		}
+	}
+	pubBytes, err := pub.Encode()
+	if err != nil {
+		return nil, rcValue
+	}
+	obj.Public = pubBytes
+	return obj, rcSuccess
+}
+
+//sensitiveArea is what gets protected in TPM2B_PRIVATE
+type sensitiveArea struct {
+	AuthValue tpmutil.U16Bytes
+	SeedValue tpmutil.U16Bytes
+	Sensitive tpmutil.U16Bytes
+}
+
+//wrap protects the sensitive part of obj with the seed value of o,
+//binding it to the object name. The result is opaque to the caller,
+//like TPM2B_PRIVATE.
+func (o *object) wrap(obj *object) ([]byte, error) {
+	aead, err := newAEAD(o.SeedValue)
+	if err != nil {
+		return nil, err
+	}
+	name, err := obj.name()
+	if err != nil {
+		return nil, err
+	}
+	plain, err := tpmutil.Pack(sensitiveArea{
+		AuthValue: obj.AuthValue,
+		SeedValue: obj.SeedValue,
+		Sensitive: obj.Sensitive,
+	})
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plain, name), nil
+}
+
+//unwrap is the reverse of wrap, it returns the object
+//described by publicBytes and the protected private area
+func (o *object) unwrap(publicBytes, private []byte) (*object, error) {
+	obj := &object{Public: publicBytes, Hierarchy: o.Hierarchy}
+	name, err := obj.name()
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(o.SeedValue)
+	if err != nil {
+		return nil, err
+	}
+	if len(private) < aead.NonceSize() {
+		return nil, errors.New("private area is too short")
+	}
+	plain, err := aead.Open(nil, private[:aead.NonceSize()],
+		private[aead.NonceSize():], name)
+	if err != nil {
+		return nil, err
+	}
+	var sensitive sensitiveArea
+	if _, err := tpmutil.Unpack(plain, &sensitive); err != nil {
+		return nil, err
+	}
+	obj.AuthValue = sensitive.AuthValue
+	obj.SeedValue = sensitive.SeedValue
+	obj.Sensitive = sensitive.Sensitive
+	return obj, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+//sign signs digest with the object key, using the given scheme
+func (o *object) sign(scheme *tpm2.SigScheme, digest []byte) ([]byte, tpmRC) {
+	hash, err := scheme.Hash.Hash()
+	if err != nil {
+		return nil, rcHash
+	}
+	if len(digest) != hash.Size() {
+		return nil, rcSize
+	}
+	switch scheme.Alg {
+	case tpm2.AlgECDSA:
+		key, err := o.ecdsaKey()
+		if err != nil {
+			return nil, rcKey
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+		if err != nil {
+			return nil, rcFailure
+		}
+		size := curveBytes(key.Curve)
+		sig, err := tpmutil.Pack(scheme.Alg, scheme.Hash,
+			tpmutil.U16Bytes(padBytes(r.Bytes(), size)),
+			tpmutil.U16Bytes(padBytes(s.Bytes(), size)))
+		if err != nil {
+			return nil, rcFailure
+		}
+		return sig, rcSuccess
+	case tpm2.AlgRSASSA, tpm2.AlgRSAPSS:
+		key, err := o.rsaKey()
+		if err != nil {
+			return nil, rcKey
+		}
+		var sig []byte
+		if scheme.Alg == tpm2.AlgRSASSA {
+			sig, err = rsa.SignPKCS1v15(rand.Reader, key, hash, digest)
+		} else {
+			sig, err = rsa.SignPSS(rand.Reader, key, hash, digest,
+				&rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		if err != nil {
+			return nil, rcFailure
+		}
+		sigBytes, err := tpmutil.Pack(scheme.Alg, scheme.Hash, tpmutil.U16Bytes(sig))
+		if err != nil {
+			return nil, rcFailure
+		}
+		return sigBytes, rcSuccess
+	default:
+		return nil, rcScheme
+	}
+}
+
+//signScheme picks the signing scheme, given the one requested by the caller
+func (o *object) signScheme(requested *tpm2.SigScheme) (*tpm2.SigScheme, tpmRC) {
+	pub, err := o.public()
+	if err != nil {
+		return nil, rcKey
+	}
+	if pub.Attributes&tpm2.FlagSign == 0 {
+		return nil, rcKey
+	}
+	var keyScheme *tpm2.SigScheme
+	switch pub.Type {
+	case tpm2.AlgECC:
+		keyScheme = pub.ECCParameters.Sign
+	case tpm2.AlgRSA:
+		keyScheme = pub.RSAParameters.Sign
+	default:
+		return nil, rcKey
+	}
+	switch {
+	case keyScheme == nil && requested == nil:
+		return nil, rcScheme
+	case keyScheme == nil:
+		return requested, rcSuccess
+	case requested == nil:
+		return keyScheme, rcSuccess
+	case *keyScheme != *requested:
+		return nil, rcScheme
+	default:
+		return keyScheme, rcSuccess
+	}
+}
+
+//ecdhZ multiplies point (x, y) by the object private key
+func (o *object) ecdhZ(x, y *big.Int) (*tpm2.ECPoint, tpmRC) {
+	key, err := o.ecdsaKey()
+	if err != nil {
+		return nil, rcKey
+	}
+	if !key.Curve.IsOnCurve(x, y) {
+		return nil, rcECCPoint
+	}
+	zx, zy := key.Curve.ScalarMult(x, y, o.Sensitive)
+	size := curveBytes(key.Curve)
+	return &tpm2.ECPoint{
+		XRaw: padBytes(zx.Bytes(), size),
+		YRaw: padBytes(zy.Bytes(), size),
+	}, rcSuccess
+}
+
+//hashOf returns digest of data, using hash algorithm alg
+func hashOf(alg tpm2.Algorithm, data ...[]byte) ([]byte, error) {
+	hash, err := alg.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return hashWith(hash, data...), nil
+}
+
+func hashWith(hash crypto.Hash, data ...[]byte) []byte {
+	h := hash.New()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+//copyObject returns a deep copy of o
+func (o *object) copyObject() *object {
+	return &object{
+		Public:    append([]byte{}, o.Public...),
+		AuthValue: append([]byte{}, o.AuthValue...),
+		Sensitive: append([]byte{}, o.Sensitive...),
+		SeedValue: append([]byte{}, o.SeedValue...),
+		Hierarchy: o.Hierarchy,
+	}
+}
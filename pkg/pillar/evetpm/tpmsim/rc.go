@@ -0,0 +1,81 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package tpmsim
+
+//tpmRC is a TPM_RC response code, as defined in
+//TPM 2.0 Library Part 2, section 6.6
+type tpmRC uint32
+
+const (
+	rcSuccess tpmRC = 0x000
+
+	//format-zero response codes
+	rcFailure         tpmRC = 0x101
+	rcAuthType        tpmRC = 0x124
+	rcAuthMissing     tpmRC = 0x125
+	rcPCRChanged      tpmRC = 0x128
+	rcAuthUnavailable tpmRC = 0x12F
+	rcCommandSize     tpmRC = 0x142
+	rcCommandCode     tpmRC = 0x143
+	rcAuthSize        tpmRC = 0x144
+	rcAuthContext     tpmRC = 0x145
+	rcNVRange         tpmRC = 0x146
+	rcNVAuthorization tpmRC = 0x149
+	rcNVUninitialized tpmRC = 0x14A
+	rcNVDefined       tpmRC = 0x14C
+
+	//format-one response codes, these can be qualified
+	//with the handle, parameter or session number
+	rcAttributes   tpmRC = 0x082
+	rcHash         tpmRC = 0x083
+	rcValue        tpmRC = 0x084
+	rcHierarchy    tpmRC = 0x085
+	rcKeySize      tpmRC = 0x087
+	rcType         tpmRC = 0x08A
+	rcHandle       tpmRC = 0x08B
+	rcAuthFail     tpmRC = 0x08E
+	rcScheme       tpmRC = 0x092
+	rcSize         tpmRC = 0x095
+	rcSymmetric    tpmRC = 0x096
+	rcTag          tpmRC = 0x097
+	rcInsufficient tpmRC = 0x09A
+	rcKey          tpmRC = 0x09C
+	rcPolicyFail   tpmRC = 0x09D
+	rcIntegrity    tpmRC = 0x09F
+	rcTicket       tpmRC = 0x0A0
+	rcCurve        tpmRC = 0x0A6
+	rcECCPoint     tpmRC = 0x0A7
+
+	rcFormatOne tpmRC = 0x080
+	rcParameter tpmRC = 0x040
+	rcSessionID tpmRC = 0x800
+)
+
+func (rc tpmRC) isFormatOne() bool {
+	return rc&rcFormatOne != 0
+}
+
+//handle qualifies the response code with handle number n
+func (rc tpmRC) handle(n int) tpmRC {
+	if !rc.isFormatOne() {
+		return rc
+	}
+	return rc | tpmRC(n)<<8
+}
+
+//param qualifies the response code with parameter number n
+func (rc tpmRC) param(n int) tpmRC {
+	if !rc.isFormatOne() {
+		return rc
+	}
+	return rc | rcParameter | tpmRC(n)<<8
+}
+
+//session qualifies the response code with session number n
+func (rc tpmRC) session(n int) tpmRC {
+	if !rc.isFormatOne() {
+		return rc
+	}
+	return rc | rcSessionID | tpmRC(n)<<8
+}
@@ -0,0 +1,287 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package tpmsim implements an in-process TPM 2.0 simulator, covering the
+// subset of the TPM 2.0 command set used by evetpm and tpmmgr.
+// It speaks the TPM wire protocol, so it can be plugged in as a transport
+// for go-tpm (see evetpm.SetTpmTransport), and is meant for unit tests and
+// for running tpmmgr on devices without a TPM.
+// Keys are generated with crypto/rand and kept in memory, there is no attempt
+// to protect them. Persistent objects and NV indices can optionally be kept
+// in a state file, PCRs always start from zero, like after a TPM reset.
+package tpmsim
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+const (
+	//NumPCRs is the number of PCRs in every bank
+	NumPCRs = 24
+
+	maxCommandSize   = 4096
+	maxNVBufferSize  = 1024
+	maxNVIndexSize   = 2048
+	protectKeyLength = 32
+)
+
+//pcrBanks are the PCR banks implemented by the simulator
+var pcrBanks = []tpm2.Algorithm{tpm2.AlgSHA1, tpm2.AlgSHA256}
+
+//persistentState is what survives simulator restarts, when
+//the simulator is backed by a state file
+type persistentState struct {
+	Persistent map[tpmutil.Handle]*object
+	NVIndices  map[tpmutil.Handle]*nvIndex
+	//ResetCount is incremented on every simulated TPM reset
+	ResetCount uint32
+	//Proof is the secret value used for tickets
+	Proof []byte
+}
+
+//Simulator is an in-process TPM 2.0 simulator
+type Simulator struct {
+	sync.Mutex
+	stateFile string
+	state     persistentState
+	pcrs      map[tpm2.Algorithm][][]byte
+	//pcrUpdateCounter is incremented on every PCR change
+	pcrUpdateCounter uint32
+	transient        map[tpmutil.Handle]*object
+	sessions         map[tpmutil.Handle]*session
+	nextHandle       uint32
+	startTime        time.Time
+}
+
+//New creates a simulator whose state lives in memory only
+func New() (*Simulator, error) {
+	return NewWithStateFile("")
+}
+
+//NewWithStateFile creates a simulator which loads persistent objects
+//and NV indices from stateFile, if it exists, and saves them back
+//on every change. Empty stateFile disables persistence.
+func NewWithStateFile(stateFile string) (*Simulator, error) {
+	s := &Simulator{
+		stateFile: stateFile,
+		state: persistentState{
+			Persistent: make(map[tpmutil.Handle]*object),
+			NVIndices:  make(map[tpmutil.Handle]*nvIndex),
+		},
+	}
+	if stateFile != "" {
+		if err := s.loadState(); err != nil {
+			return nil, err
+		}
+	}
+	if len(s.state.Proof) == 0 {
+		s.state.Proof = make([]byte, protectKeyLength)
+		if _, err := rand.Read(s.state.Proof); err != nil {
+			return nil, err
+		}
+	}
+	s.Reset()
+	return s, nil
+}
+
+func (s *Simulator) loadState() error {
+	stateBytes, err := ioutil.ReadFile(s.stateFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("loadState: failed to read %s: %v", s.stateFile, err)
+	}
+	if err := json.Unmarshal(stateBytes, &s.state); err != nil {
+		return fmt.Errorf("loadState: failed to parse %s: %v", s.stateFile, err)
+	}
+	if s.state.Persistent == nil {
+		s.state.Persistent = make(map[tpmutil.Handle]*object)
+	}
+	if s.state.NVIndices == nil {
+		s.state.NVIndices = make(map[tpmutil.Handle]*nvIndex)
+	}
+	return nil
+}
+
+func (s *Simulator) saveState() error {
+	if s.stateFile == "" {
+		return nil
+	}
+	stateBytes, err := json.Marshal(&s.state)
+	if err != nil {
+		return err
+	}
+	tmpFile := s.stateFile + ".tmp"
+	if err := ioutil.WriteFile(tmpFile, stateBytes, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, s.stateFile)
+}
+
+//Reset simulates a TPM reset, as it happens on reboot:
+//PCRs are cleared, transient objects and sessions are flushed.
+//Persistent objects and NV indices are kept.
+func (s *Simulator) Reset() {
+	s.Lock()
+	defer s.Unlock()
+	s.pcrs = make(map[tpm2.Algorithm][][]byte)
+	for _, alg := range pcrBanks {
+		hash, _ := alg.Hash()
+		bank := make([][]byte, NumPCRs)
+		for i := range bank {
+			bank[i] = make([]byte, hash.Size())
+		}
+		s.pcrs[alg] = bank
+	}
+	s.pcrUpdateCounter = 0
+	s.transient = make(map[tpmutil.Handle]*object)
+	s.sessions = make(map[tpmutil.Handle]*session)
+	s.state.ResetCount++
+	s.startTime = time.Now()
+	s.saveState()
+}
+
+//ExtendPCR measures data into the given PCR of all banks,
+//the way firmware and bootloaders record boot events
+func (s *Simulator) ExtendPCR(pcr int, data []byte) error {
+	s.Lock()
+	defer s.Unlock()
+	if pcr < 0 || pcr >= NumPCRs {
+		return fmt.Errorf("invalid PCR index %d", pcr)
+	}
+	for _, alg := range pcrBanks {
+		hash, _ := alg.Hash()
+		h := hash.New()
+		h.Write(data)
+		s.extendPCR(alg, pcr, h.Sum(nil))
+	}
+	return nil
+}
+
+//PCRValue returns the current value of a PCR in the given bank
+func (s *Simulator) PCRValue(alg tpm2.Algorithm, pcr int) ([]byte, error) {
+	s.Lock()
+	defer s.Unlock()
+	bank, ok := s.pcrs[alg]
+	if !ok {
+		return nil, fmt.Errorf("PCR bank 0x%x is not supported", alg)
+	}
+	if pcr < 0 || pcr >= NumPCRs {
+		return nil, fmt.Errorf("invalid PCR index %d", pcr)
+	}
+	return append([]byte{}, bank[pcr]...), nil
+}
+
+func (s *Simulator) extendPCR(alg tpm2.Algorithm, pcr int, digest []byte) {
+	hash, _ := alg.Hash()
+	h := hash.New()
+	h.Write(s.pcrs[alg][pcr])
+	h.Write(digest)
+	s.pcrs[alg][pcr] = h.Sum(nil)
+	s.pcrUpdateCounter++
+}
+
+//Open returns a new connection to the simulator, which can be used
+//as a go-tpm transport. Like with the kernel resource manager
+//(/dev/tpmrm0), transient objects and sessions created over a connection
+//are flushed when the connection is closed.
+func (s *Simulator) Open() (io.ReadWriteCloser, error) {
+	return &conn{
+		sim:     s,
+		handles: make(map[tpmutil.Handle]bool),
+	}, nil
+}
+
+//allocHandle returns the next free handle in the given range
+func (s *Simulator) allocHandle(base uint32) tpmutil.Handle {
+	for {
+		s.nextHandle = (s.nextHandle + 1) & 0xFFFFFF
+		h := tpmutil.Handle(base | s.nextHandle)
+		if _, ok := s.transient[h]; ok {
+			continue
+		}
+		if _, ok := s.sessions[h]; ok {
+			continue
+		}
+		return h
+	}
+}
+
+//flushHandle removes a transient object or a session
+func (s *Simulator) flushHandle(h tpmutil.Handle) bool {
+	if _, ok := s.transient[h]; ok {
+		delete(s.transient, h)
+		return true
+	}
+	if _, ok := s.sessions[h]; ok {
+		delete(s.sessions, h)
+		return true
+	}
+	return false
+}
+
+//sortedHandles sorts handles in ascending order
+func sortedHandles(handles []tpmutil.Handle) []tpmutil.Handle {
+	sort.Slice(handles, func(i, j int) bool { return handles[i] < handles[j] })
+	return handles
+}
+
+//conn is a single connection to the simulator
+type conn struct {
+	sim      *Simulator
+	response bytes.Buffer
+	//handles created over this connection
+	handles map[tpmutil.Handle]bool
+	closed  bool
+}
+
+//Write executes a TPM command, the response is returned by subsequent Read
+func (c *conn) Write(cmd []byte) (int, error) {
+	if c.closed {
+		return 0, os.ErrClosed
+	}
+	if len(cmd) > maxCommandSize {
+		return 0, fmt.Errorf("command size %d exceeds %d", len(cmd), maxCommandSize)
+	}
+	c.sim.Lock()
+	resp := c.sim.execute(c, cmd)
+	c.sim.Unlock()
+	c.response.Reset()
+	c.response.Write(resp)
+	return len(cmd), nil
+}
+
+//Read returns the response to the last command
+func (c *conn) Read(p []byte) (int, error) {
+	if c.closed {
+		return 0, os.ErrClosed
+	}
+	return c.response.Read(p)
+}
+
+//Close flushes all transient objects and sessions created over this connection
+func (c *conn) Close() error {
+	if c.closed {
+		return os.ErrClosed
+	}
+	c.closed = true
+	c.sim.Lock()
+	defer c.sim.Unlock()
+	for h := range c.handles {
+		c.sim.flushHandle(h)
+	}
+	return nil
+}
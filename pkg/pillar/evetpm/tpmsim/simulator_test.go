@@ -0,0 +1,100 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package tpmsim
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+const testNVIndex tpmutil.Handle = 0x1500000
+
+func TestPCRExtend(t *testing.T) {
+	sim, err := New()
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	rw, err := sim.Open()
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer rw.Close()
+
+	digest := sha256.Sum256([]byte("event"))
+	if err := tpm2.PCRExtend(rw, 7, tpm2.AlgSHA256, digest[:], ""); err != nil {
+		t.Fatalf("PCRExtend failed: %v", err)
+	}
+	want := sha256.Sum256(append(make([]byte, sha256.Size), digest[:]...))
+	got, err := tpm2.ReadPCR(rw, 7, tpm2.AlgSHA256)
+	if err != nil {
+		t.Fatalf("ReadPCR failed: %v", err)
+	}
+	if !bytes.Equal(got, want[:]) {
+		t.Errorf("want %x, but got %x", want, got)
+	}
+
+	//SHA1 bank is not touched by a SHA256 only extend
+	sha1PCR, err := sim.PCRValue(tpm2.AlgSHA1, 7)
+	if err != nil {
+		t.Fatalf("PCRValue failed: %v", err)
+	}
+	if !bytes.Equal(sha1PCR, make([]byte, len(sha1PCR))) {
+		t.Errorf("SHA1 PCR 7 changed to %x", sha1PCR)
+	}
+}
+
+func TestStateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tpmsim_test")
+	if err != nil {
+		t.Fatalf("TempDir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "tpm.state")
+
+	sim, err := NewWithStateFile(stateFile)
+	if err != nil {
+		t.Fatalf("NewWithStateFile failed: %v", err)
+	}
+	rw, err := sim.Open()
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	data := []byte("persistent data")
+	if err := tpm2.NVDefineSpace(rw, tpm2.HandleOwner, testNVIndex, "", "", nil,
+		tpm2.AttrOwnerWrite|tpm2.AttrOwnerRead, uint16(len(data))); err != nil {
+		t.Fatalf("NVDefineSpace failed: %v", err)
+	}
+	if _, err := tpm2.NVReadEx(rw, testNVIndex, tpm2.HandleOwner, "", 0); err == nil {
+		t.Errorf("NVReadEx succeeded before NVWrite")
+	}
+	if err := tpm2.NVWrite(rw, tpm2.HandleOwner, testNVIndex, "", data, 0); err != nil {
+		t.Fatalf("NVWrite failed: %v", err)
+	}
+	rw.Close()
+
+	//another simulator instance picks up the state
+	sim, err = NewWithStateFile(stateFile)
+	if err != nil {
+		t.Fatalf("NewWithStateFile failed: %v", err)
+	}
+	rw, err = sim.Open()
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer rw.Close()
+	got, err := tpm2.NVReadEx(rw, testNVIndex, tpm2.HandleOwner, "", 0)
+	if err != nil {
+		t.Fatalf("NVReadEx failed: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("want %q, but got %q", data, got)
+	}
+}
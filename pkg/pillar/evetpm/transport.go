@@ -0,0 +1,44 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package evetpm
+
+import (
+	"io"
+	"os"
+
+	"github.com/google/go-tpm/tpm2"
+)
+
+//TpmTransport opens a connection to a TPM, which is used for
+//a single sequence of commands and closed afterwards
+type TpmTransport func() (io.ReadWriteCloser, error)
+
+//tpmTransport is the transport used by OpenTPM, nil means TpmDevicePath
+var tpmTransport TpmTransport
+
+//SetTpmTransport replaces the TPM device with a custom transport,
+//e.g. an in-process simulator (see evetpm/tpmsim).
+//Passing nil restores the default, which is TpmDevicePath.
+func SetTpmTransport(transport TpmTransport) {
+	tpmTransport = transport
+}
+
+//OpenTPM opens a connection to the TPM, using the transport
+//set with SetTpmTransport, or TpmDevicePath by default
+func OpenTPM() (io.ReadWriteCloser, error) {
+	if tpmTransport != nil {
+		return tpmTransport()
+	}
+	return tpm2.OpenTPM(TpmDevicePath)
+}
+
+//IsTpmPresent returns true if there is a TPM to talk to,
+//either the TPM device or a custom transport
+func IsTpmPresent() bool {
+	if tpmTransport != nil {
+		return true
+	}
+	_, err := os.Stat(TpmDevicePath)
+	return err == nil
+}
@@ -25,7 +25,6 @@ require (
 	github.com/google/gopacket v1.1.19
 	github.com/gorilla/websocket v1.4.2
 	github.com/grandcat/zeroconf v1.0.0
-	github.com/jackwakefield/gopac v1.0.2
 	github.com/jaypipes/ghw v0.8.0
 	github.com/klauspost/compress v1.15.1 // indirect
 	github.com/lf-edge/edge-containers v0.0.0-20220320131500-9d9f95d81e2c
@@ -33,6 +32,7 @@ require (
 	github.com/lf-edge/eve/libs/depgraph v0.0.0-20220129022022-ba04fd269658
 	github.com/lf-edge/eve/libs/reconciler v0.0.0-20220131150115-6941dbe72001
 	github.com/lf-edge/eve/libs/zedUpload v0.0.0-20210120050122-276fea8f6efd
+	github.com/miekg/dns v1.1.41
 	github.com/moby/sys/mountinfo v0.6.0 // indirect
 	github.com/moby/term v0.0.0-20210619224110-3f7ff695adc6 // indirect
 	github.com/onsi/gomega v1.15.0
@@ -43,6 +43,7 @@ require (
 	github.com/prometheus/client_golang v1.12.1 // indirect
 	github.com/prometheus/procfs v0.7.3
 	github.com/rackn/gohai v0.0.0-20190321191141-5053e7f1fa36
+	github.com/robertkrimen/otto v0.0.0-20180617131154-15f95af6e78d
 	github.com/satori/go.uuid v1.2.1-0.20180404165556-75cca531ea76
 	github.com/shirou/gopsutil v0.0.0-20190323131628-2cbc9195c892
 	github.com/sirupsen/logrus v1.8.1
@@ -55,6 +56,8 @@ require (
 	golang.org/x/sys v0.0.0-20220704084225-05e143d24a9e
 	google.golang.org/genproto v0.0.0-20220317150908-0efb43f6373e // indirect
 	google.golang.org/grpc v1.45.0
+	google.golang.org/protobuf v1.27.1
+	gopkg.in/sourcemap.v1 v1.0.5 // indirect
 )
 
 replace github.com/lf-edge/eve/api/go => ../../api/go
@@ -0,0 +1,228 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package imagesig verifies cosign signatures of OCI images. cosign stores
+// the signatures of an image in the same repository, either as a manifest
+// tagged sha256-<digest>.sig or, with the OCI 1.1 referrers API, as
+// manifests referring to the image listed in the index tagged
+// sha256-<digest>. Each layer of a signature manifest is a simple signing
+// payload which names the digest of the image, and the signature of the
+// payload is in the layer annotations.
+package imagesig
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// SimpleSigningMediaType : media type of the signed payload layers
+	SimpleSigningMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	// SignatureAnnotation : layer annotation with the base64 signature
+	SignatureAnnotation = "dev.cosignproject.cosign/signature"
+	// SignatureArtifactType : artifact type of the signature manifests
+	// listed by the referrers index
+	SignatureArtifactType = "application/vnd.dev.cosign.artifact.sig.v1+json"
+	// MaxPayloadSize : payloads are small JSON documents, refuse larger ones
+	MaxPayloadSize = 64 * 1024
+
+	simpleSigningType = "cosign container image signature"
+)
+
+// ErrNoSignature : there is no signature of the image
+var ErrNoSignature = errors.New("image is not signed")
+
+// Signature of the image, the payload is to be fetched separately
+type Signature struct {
+	PayloadDigest string // sha256:<hex>
+	PayloadSize   int64
+	Signature     []byte
+}
+
+type descriptor struct {
+	MediaType    string            `json:"mediaType"`
+	ArtifactType string            `json:"artifactType,omitempty"`
+	Digest       string            `json:"digest"`
+	Size         int64             `json:"size"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+type manifest struct {
+	Layers    []descriptor `json:"layers"`
+	Manifests []descriptor `json:"manifests"`
+}
+
+type simpleSigning struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+// normalizeDigest returns sha256:<lowercase hex> for the digest either
+// with or without the algorithm
+func normalizeDigest(digest string) string {
+	digest = strings.ToLower(digest)
+	if !strings.HasPrefix(digest, "sha256:") {
+		digest = "sha256:" + digest
+	}
+	return digest
+}
+
+// SignatureTag returns the tag of the cosign signature manifest of the image
+func SignatureTag(digest string) string {
+	return strings.Replace(normalizeDigest(digest), ":", "-", 1) + ".sig"
+}
+
+// ReferrersTag returns the tag of the referrers index of the image used by
+// registries without the referrers API
+func ReferrersTag(digest string) string {
+	return strings.Replace(normalizeDigest(digest), ":", "-", 1)
+}
+
+// ParseSignatureManifest returns the signatures in the signature manifest
+func ParseSignatureManifest(b []byte) ([]Signature, error) {
+	var m manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("signature manifest: %v", err)
+	}
+	var sigs []Signature
+	for _, layer := range m.Layers {
+		encoded, ok := layer.Annotations[SignatureAnnotation]
+		if !ok || layer.MediaType != SimpleSigningMediaType {
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("signature of %s: %v", layer.Digest, err)
+		}
+		sigs = append(sigs, Signature{
+			PayloadDigest: layer.Digest,
+			PayloadSize:   layer.Size,
+			Signature:     sig,
+		})
+	}
+	if len(sigs) == 0 {
+		return nil, ErrNoSignature
+	}
+	return sigs, nil
+}
+
+// ParseReferrers returns the digests of the signature manifests in the
+// referrers index
+func ParseReferrers(b []byte) ([]string, error) {
+	var m manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("referrers index: %v", err)
+	}
+	var digests []string
+	for _, desc := range m.Manifests {
+		if desc.ArtifactType == SignatureArtifactType {
+			digests = append(digests, desc.Digest)
+		}
+	}
+	if len(digests) == 0 {
+		return nil, ErrNoSignature
+	}
+	return digests, nil
+}
+
+// ParseKeys parses the PEM public keys or certificates. Certificates are
+// not verified, only their public keys are used.
+func ParseKeys(keys []string) ([]crypto.PublicKey, error) {
+	var pubKeys []crypto.PublicKey
+	for _, key := range keys {
+		rest := []byte(key)
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			switch block.Type {
+			case "PUBLIC KEY":
+				pubKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+				if err != nil {
+					return nil, err
+				}
+				pubKeys = append(pubKeys, pubKey)
+			case "CERTIFICATE":
+				cert, err := x509.ParseCertificate(block.Bytes)
+				if err != nil {
+					return nil, err
+				}
+				pubKeys = append(pubKeys, cert.PublicKey)
+			default:
+				return nil, fmt.Errorf("unsupported PEM block %s", block.Type)
+			}
+		}
+	}
+	if len(pubKeys) == 0 {
+		return nil, errors.New("no public keys")
+	}
+	return pubKeys, nil
+}
+
+// verifySignature verifies the signature of payload by pubKey
+func verifySignature(pubKey crypto.PublicKey, payload, sig []byte) error {
+	hash := sha256.Sum256(payload)
+	switch k := pubKey.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, hash[:], sig) {
+			return errors.New("ecdsa verification failed")
+		}
+		return nil
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, hash[:], sig)
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, payload, sig) {
+			return errors.New("ed25519 verification failed")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported public key type %T", pubKey)
+	}
+}
+
+// Verify verifies that payload of sig is signed by one of pubKeys and is
+// the signature of the image with digest
+func Verify(sig Signature, payload []byte, digest string,
+	pubKeys []crypto.PublicKey) error {
+	payloadHash := sha256.Sum256(payload)
+	if fmt.Sprintf("sha256:%x", payloadHash) != normalizeDigest(sig.PayloadDigest) {
+		return fmt.Errorf("payload does not match digest %s", sig.PayloadDigest)
+	}
+	var err error
+	for _, pubKey := range pubKeys {
+		if err = verifySignature(pubKey, payload, sig.Signature); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("signature not verified by any key: %v", err)
+	}
+	var ss simpleSigning
+	if err := json.Unmarshal(payload, &ss); err != nil {
+		return fmt.Errorf("payload: %v", err)
+	}
+	if ss.Critical.Type != simpleSigningType {
+		return fmt.Errorf("unexpected payload type %q", ss.Critical.Type)
+	}
+	if normalizeDigest(ss.Critical.Image.DockerManifestDigest) != normalizeDigest(digest) {
+		return fmt.Errorf("signature is for image %s",
+			ss.Critical.Image.DockerManifestDigest)
+	}
+	return nil
+}
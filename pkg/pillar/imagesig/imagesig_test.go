@@ -0,0 +1,137 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package imagesig_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"testing"
+
+	"github.com/lf-edge/eve/pkg/pillar/imagesig"
+)
+
+const imageDigest = "sha256:4b2e1f9c7a0d3e6b8f5a2c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e1f"
+
+func publicKeyPEM(t *testing.T, pubKey crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func payloadFor(digest string) []byte {
+	return []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"example.com/app"},`+
+		`"image":{"docker-manifest-digest":"%s"},"type":"cosign container image signature"},`+
+		`"optional":null}`, digest))
+}
+
+// signatureManifest returns the cosign signature manifest of payload
+func signatureManifest(t *testing.T, payload, sig []byte) []byte {
+	hash := sha256.Sum256(payload)
+	b, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"layers": []map[string]interface{}{{
+			"mediaType": imagesig.SimpleSigningMediaType,
+			"digest":    fmt.Sprintf("sha256:%x", hash),
+			"size":      len(payload),
+			"annotations": map[string]string{
+				imagesig.SignatureAnnotation: base64.StdEncoding.EncodeToString(sig),
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestVerify(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := payloadFor(imageDigest)
+	hash := sha256.Sum256(payload)
+	ecSig, err := ecdsa.SignASN1(rand.Reader, ecKey, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	edSig := ed25519.Sign(edKey, payload)
+
+	keys, err := imagesig.ParseKeys([]string{publicKeyPEM(t, &ecKey.PublicKey),
+		publicKeyPEM(t, edPub)})
+	if err != nil {
+		t.Fatalf("ParseKeys failed: %v", err)
+	}
+	otherKeys, err := imagesig.ParseKeys([]string{publicKeyPEM(t, &otherKey.PublicKey)})
+	if err != nil {
+		t.Fatalf("ParseKeys failed: %v", err)
+	}
+
+	for name, sigBytes := range map[string][]byte{"ecdsa": ecSig, "ed25519": edSig} {
+		sigs, err := imagesig.ParseSignatureManifest(signatureManifest(t, payload, sigBytes))
+		if err != nil {
+			t.Fatalf("%s: ParseSignatureManifest failed: %v", name, err)
+		}
+		if len(sigs) != 1 {
+			t.Fatalf("%s: unexpected %d signatures", name, len(sigs))
+		}
+		if err := imagesig.Verify(sigs[0], payload, imageDigest[len("sha256:"):], keys); err != nil {
+			t.Errorf("%s: Verify failed: %v", name, err)
+		}
+		if err := imagesig.Verify(sigs[0], payload, imageDigest, otherKeys); err == nil {
+			t.Errorf("%s: Verify succeeded with other key", name)
+		}
+		otherDigest := "sha256:" + fmt.Sprintf("%x", sha256.Sum256(nil))
+		if err := imagesig.Verify(sigs[0], payload, otherDigest, keys); err == nil {
+			t.Errorf("%s: Verify succeeded for other image", name)
+		}
+		tampered := payloadFor(otherDigest)
+		if err := imagesig.Verify(sigs[0], tampered, otherDigest, keys); err == nil {
+			t.Errorf("%s: Verify succeeded with tampered payload", name)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	if _, err := imagesig.ParseSignatureManifest([]byte(`{"layers":[]}`)); err != imagesig.ErrNoSignature {
+		t.Errorf("unexpected error for manifest without signatures: %v", err)
+	}
+	index := []byte(`{"schemaVersion":2,"manifests":[` +
+		`{"mediaType":"application/vnd.oci.image.manifest.v1+json","artifactType":"application/spdx+json","digest":"sha256:aa","size":1},` +
+		`{"mediaType":"application/vnd.oci.image.manifest.v1+json","artifactType":"` +
+		imagesig.SignatureArtifactType + `","digest":"sha256:bb","size":1}]}`)
+	digests, err := imagesig.ParseReferrers(index)
+	if err != nil {
+		t.Fatalf("ParseReferrers failed: %v", err)
+	}
+	if len(digests) != 1 || digests[0] != "sha256:bb" {
+		t.Errorf("unexpected signature manifests %v", digests)
+	}
+	if tag := imagesig.SignatureTag(imageDigest); tag != "sha256-"+imageDigest[len("sha256:"):]+".sig" {
+		t.Errorf("unexpected signature tag %s", tag)
+	}
+	if _, err := imagesig.ParseKeys([]string{"not a key"}); err == nil {
+		t.Errorf("ParseKeys succeeded without keys")
+	}
+}
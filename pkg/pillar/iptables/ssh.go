@@ -28,4 +28,6 @@ var ControlProtocolMarkingIDMap = map[string]string{
 	// DHCP packets originating from outside
 	// (e.g. DHCP multicast requests from other devices on the same network)
 	"in_dhcp": "10",
+	// Peer cache (HTTPS + mDNS) flows from other EVE devices
+	"in_peer_cache": "11",
 }
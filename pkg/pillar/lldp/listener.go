@@ -0,0 +1,236 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package lldp implements passive listener for LLDP (IEEE 802.1AB)
+// and CDP (Cisco Discovery Protocol) advertisements, used by NIM to learn
+// about switches and other devices directly connected to device ports.
+// EVE itself never transmits any advertisements.
+package lldp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// How often the receive loop wakes up to check for expired neighbors
+	// and for the stop signal.
+	recvTimeout = time.Second
+	// Large enough for any discovery frame (jumbo frames are not expected).
+	recvBufSize = 2048
+	// Upper limit on the number of neighbors remembered per interface.
+	maxNeighbors = 16
+)
+
+// Listener passively listens for LLDP and CDP advertisements received
+// on a network interface and maintains the set of discovered neighbors.
+type Listener struct {
+	log      *base.LogObject
+	ifIndex  int
+	ifName   string
+	onChange func()
+
+	sockets []int
+	stop    chan struct{}
+	wg      sync.WaitGroup
+
+	sync.Mutex
+	neighbors map[string]types.PortNeighbor // key: PortNeighbor.Key()
+}
+
+// NewListener creates listener for the given interface.
+// Interface is identified by index, name is used only for logging purposes
+// (listener keeps running if the interface is renamed).
+// onChange (optional) is called (from a separate Go routine) whenever
+// the set of neighbors or their attributes change.
+func NewListener(log *base.LogObject, ifIndex int, ifName string,
+	onChange func()) *Listener {
+	return &Listener{
+		log:       log,
+		ifIndex:   ifIndex,
+		ifName:    ifName,
+		onChange:  onChange,
+		neighbors: make(map[string]types.PortNeighbor),
+	}
+}
+
+// Start opens packet sockets and starts receiving advertisements.
+func (l *Listener) Start() error {
+	lldpSock, err := openSocket(l.ifIndex, unix.ETH_P_LLDP, LLDPMulticastAddr)
+	if err != nil {
+		return fmt.Errorf("failed to open LLDP socket for %s: %w", l.ifName, err)
+	}
+	// CDP is carried in IEEE 802.3 frames with LLC/SNAP header.
+	cdpSock, err := openSocket(l.ifIndex, unix.ETH_P_802_2, CDPMulticastAddr)
+	if err != nil {
+		unix.Close(lldpSock)
+		return fmt.Errorf("failed to open CDP socket for %s: %w", l.ifName, err)
+	}
+	l.sockets = []int{lldpSock, cdpSock}
+	l.stop = make(chan struct{})
+	for _, sock := range l.sockets {
+		l.wg.Add(1)
+		go l.receive(sock)
+	}
+	l.log.Noticef("Started LLDP/CDP listener for interface %s", l.ifName)
+	return nil
+}
+
+// Stop the listener and close sockets.
+func (l *Listener) Stop() {
+	if l.stop == nil {
+		return
+	}
+	close(l.stop)
+	l.wg.Wait()
+	for _, sock := range l.sockets {
+		unix.Close(sock)
+	}
+	l.sockets = nil
+	l.stop = nil
+	l.log.Noticef("Stopped LLDP/CDP listener for interface %s", l.ifName)
+}
+
+// Neighbors returns currently known (not expired) neighbors,
+// sorted by their keys.
+func (l *Listener) Neighbors() []types.PortNeighbor {
+	l.Lock()
+	defer l.Unlock()
+	now := time.Now()
+	var neighbors []types.PortNeighbor
+	for _, neighbor := range l.neighbors {
+		if neighbor.ExpiresAt.After(now) {
+			neighbors = append(neighbors, neighbor)
+		}
+	}
+	sort.Slice(neighbors, func(i, j int) bool {
+		return neighbors[i].Key() < neighbors[j].Key()
+	})
+	return neighbors
+}
+
+func (l *Listener) receive(sock int) {
+	defer l.wg.Done()
+	buf := make([]byte, recvBufSize)
+	for {
+		select {
+		case <-l.stop:
+			return
+		default:
+		}
+		n, _, err := unix.Recvfrom(sock, buf, 0)
+		now := time.Now()
+		if err != nil {
+			if !errors.Is(err, unix.EAGAIN) && !errors.Is(err, unix.EINTR) {
+				l.log.Warnf("LLDP/CDP listener for %s: recvfrom failed: %v",
+					l.ifName, err)
+				// Avoid busy loop.
+				time.Sleep(recvTimeout)
+			}
+			l.expireNeighbors(now)
+			continue
+		}
+		neighbor, err := ParseFrame(buf[:n], now)
+		if err != nil {
+			if err != ErrNotDiscoveryFrame {
+				l.log.Warnf("LLDP/CDP listener for %s: failed to parse frame: %v",
+					l.ifName, err)
+			}
+			continue
+		}
+		l.updateNeighbor(neighbor, now)
+	}
+}
+
+func (l *Listener) updateNeighbor(neighbor types.PortNeighbor, now time.Time) {
+	key := neighbor.Key()
+	var changed bool
+	l.Lock()
+	prev, known := l.neighbors[key]
+	if !neighbor.ExpiresAt.After(now) {
+		// Neighbor is shutting down.
+		if known {
+			delete(l.neighbors, key)
+			changed = true
+		}
+	} else if known || len(l.neighbors) < maxNeighbors {
+		l.neighbors[key] = neighbor
+		changed = !known || !prev.Equal(neighbor)
+	}
+	l.Unlock()
+	if changed {
+		l.log.Noticef("LLDP/CDP listener for %s: neighbor %s updated: %+v",
+			l.ifName, key, neighbor)
+		l.notify()
+	}
+	l.expireNeighbors(now)
+}
+
+func (l *Listener) expireNeighbors(now time.Time) {
+	var changed bool
+	l.Lock()
+	for key, neighbor := range l.neighbors {
+		if !neighbor.ExpiresAt.After(now) {
+			l.log.Noticef("LLDP/CDP listener for %s: neighbor %s expired",
+				l.ifName, key)
+			delete(l.neighbors, key)
+			changed = true
+		}
+	}
+	l.Unlock()
+	if changed {
+		l.notify()
+	}
+}
+
+func (l *Listener) notify() {
+	if l.onChange != nil {
+		l.onChange()
+	}
+}
+
+func openSocket(ifIndex int, proto uint16, mcastAddr net.HardwareAddr) (int, error) {
+	sock, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW, int(htons(proto)))
+	if err != nil {
+		return -1, err
+	}
+	sockAddr := &unix.SockaddrLinklayer{
+		Protocol: htons(proto),
+		Ifindex:  ifIndex,
+	}
+	if err = unix.Bind(sock, sockAddr); err != nil {
+		unix.Close(sock)
+		return -1, err
+	}
+	mreq := &unix.PacketMreq{
+		Ifindex: int32(ifIndex),
+		Type:    unix.PACKET_MR_MULTICAST,
+		Alen:    uint16(len(mcastAddr)),
+	}
+	copy(mreq.Address[:], mcastAddr)
+	err = unix.SetsockoptPacketMreq(sock, unix.SOL_PACKET,
+		unix.PACKET_ADD_MEMBERSHIP, mreq)
+	if err != nil {
+		unix.Close(sock)
+		return -1, err
+	}
+	timeout := unix.NsecToTimeval(recvTimeout.Nanoseconds())
+	err = unix.SetsockoptTimeval(sock, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &timeout)
+	if err != nil {
+		unix.Close(sock)
+		return -1, err
+	}
+	return sock, nil
+}
+
+func htons(v uint16) uint16 {
+	return (v << 8) | (v >> 8)
+}
@@ -0,0 +1,347 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package lldp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+const (
+	etherTypeLLDP  = 0x88cc
+	etherHeaderLen = 14
+	// Frames with the EtherType/Length field below this value
+	// are IEEE 802.3 frames with an LLC header.
+	etherMaxLength = 1500
+	snapHeaderLen  = 8
+
+	// LLDP TLV types.
+	lldpTLVEnd         = 0
+	lldpTLVChassisID   = 1
+	lldpTLVPortID      = 2
+	lldpTLVTTL         = 3
+	lldpTLVPortDesc    = 4
+	lldpTLVSystemName  = 5
+	lldpTLVSystemDesc  = 6
+	lldpTLVMgmtAddr    = 8
+	lldpTLVOrgSpecific = 127
+	lldpTLVHeaderLen   = 2
+	lldpMaxTLVValueLen = 511
+	// LLDP chassis and port ID subtypes.
+	lldpChassisSubtypeMAC  = 4
+	lldpChassisSubtypeAddr = 5
+	lldpPortSubtypeMAC     = 3
+	lldpPortSubtypeAddr    = 4
+	// IEEE 802.1 organizationally specific TLVs.
+	lldpDot1SubtypePVID     = 1
+	lldpDot1SubtypeVLANName = 3
+	// IANA address family numbers.
+	afiIPv4 = 1
+	afiIPv6 = 2
+
+	// CDP TLV types.
+	cdpTLVDeviceID   = 0x0001
+	cdpTLVAddresses  = 0x0002
+	cdpTLVPortID     = 0x0003
+	cdpTLVVersion    = 0x0005
+	cdpTLVPlatform   = 0x0006
+	cdpTLVNativeVLAN = 0x000a
+	cdpTLVVoIPVLAN   = 0x000e
+	cdpTLVMgmtAddrs  = 0x0016
+	// Other CDP constants.
+	cdpSNAPProtocolID = 0x2000
+	cdpHeaderLen      = 4
+	cdpTLVHeaderLen   = 4
+	cdpProtoTypeNLPID = 1
+	cdpProtoType8022  = 2
+	cdpNLPIDIPv4      = 0xcc
+	cdpVoiceVLANName  = "voice"
+)
+
+var (
+	// LLDPMulticastAddr : nearest bridge group address used by LLDP.
+	LLDPMulticastAddr = net.HardwareAddr{0x01, 0x80, 0xc2, 0x00, 0x00, 0x0e}
+	// CDPMulticastAddr : multicast address used by CDP.
+	CDPMulticastAddr = net.HardwareAddr{0x01, 0x00, 0x0c, 0xcc, 0xcc, 0xcc}
+
+	cdpSNAPHeader = []byte{0xaa, 0xaa, 0x03, 0x00, 0x00, 0x0c}
+	// SNAP header identifying IPv6 in CDP address records.
+	cdpIPv6Proto = []byte{0xaa, 0xaa, 0x03, 0x00, 0x00, 0x00, 0x86, 0xdd}
+
+	// ErrNotDiscoveryFrame is returned by ParseFrame for frames which
+	// are neither LLDP nor CDP.
+	ErrNotDiscoveryFrame = errors.New("not an LLDP or CDP frame")
+)
+
+// ParseFrame parses Ethernet frame carrying LLDP or CDP advertisement.
+// Neighbor with ExpiresAt not after receivedAt announces that the neighbor
+// is shutting down and should be removed.
+func ParseFrame(frame []byte, receivedAt time.Time) (types.PortNeighbor, error) {
+	if len(frame) < etherHeaderLen {
+		return types.PortNeighbor{}, ErrNotDiscoveryFrame
+	}
+	dstMAC := net.HardwareAddr(frame[:6])
+	etherType := binary.BigEndian.Uint16(frame[12:14])
+	payload := frame[etherHeaderLen:]
+	switch {
+	case etherType == etherTypeLLDP:
+		return ParseLLDPDU(payload, receivedAt)
+	case etherType <= etherMaxLength && bytes.Equal(dstMAC, CDPMulticastAddr):
+		if len(payload) < snapHeaderLen ||
+			!bytes.Equal(payload[:len(cdpSNAPHeader)], cdpSNAPHeader) ||
+			binary.BigEndian.Uint16(payload[6:8]) != cdpSNAPProtocolID {
+			return types.PortNeighbor{}, ErrNotDiscoveryFrame
+		}
+		return ParseCDP(payload[snapHeaderLen:], receivedAt)
+	}
+	return types.PortNeighbor{}, ErrNotDiscoveryFrame
+}
+
+// ParseLLDPDU parses LLDP data unit (IEEE 802.1AB).
+func ParseLLDPDU(data []byte, receivedAt time.Time) (types.PortNeighbor, error) {
+	neighbor := types.PortNeighbor{
+		Protocol: types.NeighborProtocolLLDP,
+		LastSeen: receivedAt,
+	}
+	var withTTL bool
+	for len(data) > 0 {
+		if len(data) < lldpTLVHeaderLen {
+			return neighbor, errors.New("truncated LLDP TLV header")
+		}
+		header := binary.BigEndian.Uint16(data[:lldpTLVHeaderLen])
+		tlvType := header >> 9
+		tlvLen := int(header & lldpMaxTLVValueLen)
+		data = data[lldpTLVHeaderLen:]
+		if len(data) < tlvLen {
+			return neighbor, fmt.Errorf("truncated LLDP TLV %d", tlvType)
+		}
+		value := data[:tlvLen]
+		data = data[tlvLen:]
+		switch tlvType {
+		case lldpTLVEnd:
+			data = nil
+		case lldpTLVChassisID:
+			if len(value) < 2 {
+				return neighbor, errors.New("invalid LLDP chassis ID")
+			}
+			neighbor.ChassisID = formatLLDPID(value[0], value[1:],
+				lldpChassisSubtypeMAC, lldpChassisSubtypeAddr)
+		case lldpTLVPortID:
+			if len(value) < 2 {
+				return neighbor, errors.New("invalid LLDP port ID")
+			}
+			neighbor.PortID = formatLLDPID(value[0], value[1:],
+				lldpPortSubtypeMAC, lldpPortSubtypeAddr)
+		case lldpTLVTTL:
+			if len(value) < 2 {
+				return neighbor, errors.New("invalid LLDP TTL")
+			}
+			ttl := time.Duration(binary.BigEndian.Uint16(value)) * time.Second
+			neighbor.ExpiresAt = receivedAt.Add(ttl)
+			withTTL = true
+		case lldpTLVPortDesc:
+			neighbor.PortDescription = printableString(value)
+		case lldpTLVSystemName:
+			neighbor.SystemName = printableString(value)
+		case lldpTLVSystemDesc:
+			neighbor.SystemDescription = printableString(value)
+		case lldpTLVMgmtAddr:
+			// Address string length (including the subtype), subtype, address.
+			if len(value) < 2 || int(value[0]) < 1 || len(value) < int(value[0])+1 {
+				continue
+			}
+			if ip := parseAddr(value[1], value[2:int(value[0])+1]); ip != nil {
+				neighbor.MgmtAddrs = append(neighbor.MgmtAddrs, ip)
+			}
+		case lldpTLVOrgSpecific:
+			parseLLDPOrgSpecific(value, &neighbor)
+		}
+	}
+	if neighbor.ChassisID == "" || neighbor.PortID == "" || !withTTL {
+		return neighbor, errors.New("LLDPDU is missing mandatory TLV")
+	}
+	return neighbor, nil
+}
+
+func parseLLDPOrgSpecific(value []byte, neighbor *types.PortNeighbor) {
+	if len(value) < 4 || !bytes.Equal(value[:3], []byte{0x00, 0x80, 0xc2}) {
+		// Only IEEE 802.1 TLVs are supported.
+		return
+	}
+	subtype := value[3]
+	value = value[4:]
+	switch subtype {
+	case lldpDot1SubtypePVID:
+		if len(value) >= 2 {
+			neighbor.PortVLAN = binary.BigEndian.Uint16(value)
+		}
+	case lldpDot1SubtypeVLANName:
+		if len(value) < 3 || len(value) < 3+int(value[2]) {
+			return
+		}
+		neighbor.VLANs = append(neighbor.VLANs, types.NeighborVLAN{
+			ID:   binary.BigEndian.Uint16(value),
+			Name: printableString(value[3 : 3+int(value[2])]),
+		})
+	}
+}
+
+// formatLLDPID formats chassis or port ID based on its subtype.
+func formatLLDPID(subtype byte, id []byte, macSubtype, addrSubtype byte) string {
+	switch subtype {
+	case macSubtype:
+		if len(id) == 6 {
+			return net.HardwareAddr(id).String()
+		}
+	case addrSubtype:
+		if len(id) > 1 {
+			if ip := parseAddr(id[0], id[1:]); ip != nil {
+				return ip.String()
+			}
+		}
+	}
+	return printableString(id)
+}
+
+func parseAddr(afi byte, addr []byte) net.IP {
+	switch {
+	case afi == afiIPv4 && len(addr) == net.IPv4len:
+		return net.IPv4(addr[0], addr[1], addr[2], addr[3])
+	case afi == afiIPv6 && len(addr) == net.IPv6len:
+		ip := make(net.IP, net.IPv6len)
+		copy(ip, addr)
+		return ip
+	}
+	return nil
+}
+
+// ParseCDP parses CDP packet (following the SNAP header).
+func ParseCDP(data []byte, receivedAt time.Time) (types.PortNeighbor, error) {
+	neighbor := types.PortNeighbor{
+		Protocol: types.NeighborProtocolCDP,
+		LastSeen: receivedAt,
+	}
+	if len(data) < cdpHeaderLen {
+		return neighbor, errors.New("truncated CDP header")
+	}
+	ttl := time.Duration(data[1]) * time.Second
+	neighbor.ExpiresAt = receivedAt.Add(ttl)
+	data = data[cdpHeaderLen:]
+	var platform, version string
+	var addrs, mgmtAddrs []net.IP
+	for len(data) > 0 {
+		if len(data) < cdpTLVHeaderLen {
+			return neighbor, errors.New("truncated CDP TLV header")
+		}
+		tlvType := binary.BigEndian.Uint16(data[:2])
+		tlvLen := int(binary.BigEndian.Uint16(data[2:4]))
+		if tlvLen < cdpTLVHeaderLen || len(data) < tlvLen {
+			return neighbor, fmt.Errorf("invalid length of CDP TLV %d", tlvType)
+		}
+		value := data[cdpTLVHeaderLen:tlvLen]
+		data = data[tlvLen:]
+		switch tlvType {
+		case cdpTLVDeviceID:
+			neighbor.ChassisID = printableString(value)
+			neighbor.SystemName = neighbor.ChassisID
+		case cdpTLVPortID:
+			neighbor.PortID = printableString(value)
+		case cdpTLVAddresses:
+			addrs = parseCDPAddrs(value)
+		case cdpTLVMgmtAddrs:
+			mgmtAddrs = parseCDPAddrs(value)
+		case cdpTLVVersion:
+			version = printableString(value)
+		case cdpTLVPlatform:
+			platform = printableString(value)
+		case cdpTLVNativeVLAN:
+			if len(value) >= 2 {
+				neighbor.PortVLAN = binary.BigEndian.Uint16(value)
+			}
+		case cdpTLVVoIPVLAN:
+			if len(value) >= 3 {
+				neighbor.VLANs = append(neighbor.VLANs, types.NeighborVLAN{
+					ID:   binary.BigEndian.Uint16(value[1:3]),
+					Name: cdpVoiceVLANName,
+				})
+			}
+		}
+	}
+	neighbor.MgmtAddrs = mgmtAddrs
+	if len(neighbor.MgmtAddrs) == 0 {
+		neighbor.MgmtAddrs = addrs
+	}
+	var desc []string
+	for _, item := range []string{platform, version} {
+		if item != "" {
+			desc = append(desc, item)
+		}
+	}
+	neighbor.SystemDescription = strings.Join(desc, "\n")
+	if neighbor.ChassisID == "" || neighbor.PortID == "" {
+		return neighbor, errors.New("CDP packet is missing device or port ID")
+	}
+	return neighbor, nil
+}
+
+// parseCDPAddrs parses value of the CDP Addresses TLV.
+func parseCDPAddrs(value []byte) (addrs []net.IP) {
+	if len(value) < 4 {
+		return nil
+	}
+	count := int(binary.BigEndian.Uint32(value[:4]))
+	value = value[4:]
+	for i := 0; i < count; i++ {
+		if len(value) < 2 {
+			return addrs
+		}
+		protoType := value[0]
+		protoLen := int(value[1])
+		if len(value) < 2+protoLen+2 {
+			return addrs
+		}
+		proto := value[2 : 2+protoLen]
+		addrLen := int(binary.BigEndian.Uint16(value[2+protoLen:]))
+		value = value[2+protoLen+2:]
+		if len(value) < addrLen {
+			return addrs
+		}
+		addr := value[:addrLen]
+		value = value[addrLen:]
+		switch {
+		case protoType == cdpProtoTypeNLPID && protoLen == 1 &&
+			proto[0] == cdpNLPIDIPv4:
+			if ip := parseAddr(afiIPv4, addr); ip != nil {
+				addrs = append(addrs, ip)
+			}
+		case protoType == cdpProtoType8022 && bytes.Equal(proto, cdpIPv6Proto):
+			if ip := parseAddr(afiIPv6, addr); ip != nil {
+				addrs = append(addrs, ip)
+			}
+		}
+	}
+	return addrs
+}
+
+// printableString converts advertised text into a printable string,
+// dropping trailing NUL characters and escaping non-printable bytes.
+func printableString(value []byte) string {
+	value = bytes.TrimRight(value, "\x00")
+	var sb strings.Builder
+	for _, b := range value {
+		if (b < 0x20 && b != '\n' && b != '\t') || b >= 0x7f {
+			sb.WriteString(fmt.Sprintf("\\x%02x", b))
+			continue
+		}
+		sb.WriteByte(b)
+	}
+	return sb.String()
+}
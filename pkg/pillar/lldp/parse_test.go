@@ -0,0 +1,163 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package lldp_test
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/lf-edge/eve/pkg/pillar/lldp"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+var srcMAC = []byte{0x00, 0x1b, 0x21, 0x00, 0x00, 0x01}
+
+func lldpTLV(tlvType int, value ...byte) []byte {
+	header := make([]byte, 2)
+	binary.BigEndian.PutUint16(header, uint16(tlvType<<9|len(value)))
+	return append(header, value...)
+}
+
+func lldpFrame(tlvs ...[]byte) []byte {
+	frame := append([]byte{}, lldp.LLDPMulticastAddr...)
+	frame = append(frame, srcMAC...)
+	frame = append(frame, 0x88, 0xcc)
+	for _, tlv := range tlvs {
+		frame = append(frame, tlv...)
+	}
+	return append(frame, lldpTLV(0)...)
+}
+
+func cdpTLV(tlvType int, value ...byte) []byte {
+	tlv := make([]byte, 4)
+	binary.BigEndian.PutUint16(tlv, uint16(tlvType))
+	binary.BigEndian.PutUint16(tlv[2:], uint16(len(value)+4))
+	return append(tlv, value...)
+}
+
+func cdpFrame(ttl byte, tlvs ...[]byte) []byte {
+	payload := []byte{0xaa, 0xaa, 0x03, 0x00, 0x00, 0x0c, 0x20, 0x00}
+	// CDP version 2, TTL, checksum (not verified).
+	payload = append(payload, 0x02, ttl, 0x00, 0x00)
+	for _, tlv := range tlvs {
+		payload = append(payload, tlv...)
+	}
+	frame := append([]byte{}, lldp.CDPMulticastAddr...)
+	frame = append(frame, srcMAC...)
+	length := make([]byte, 2)
+	binary.BigEndian.PutUint16(length, uint16(len(payload)))
+	frame = append(frame, length...)
+	return append(frame, payload...)
+}
+
+func TestParseLLDP(test *testing.T) {
+	t := NewGomegaWithT(test)
+	now := time.Now()
+	frame := lldpFrame(
+		// Chassis ID: MAC address.
+		lldpTLV(1, 0x04, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55),
+		// Port ID: interface name.
+		lldpTLV(2, append([]byte{0x05}, "Gi1/0/12"...)...),
+		// TTL: 120 seconds.
+		lldpTLV(3, 0x00, 0x78),
+		lldpTLV(4, []byte("uplink to gateway")...),
+		lldpTLV(5, []byte("plant-switch-3")...),
+		lldpTLV(6, []byte("Cisco IOS Software")...),
+		// Management address: IPv4 10.1.2.3.
+		lldpTLV(8, 0x05, 0x01, 10, 1, 2, 3, 0x02, 0x00, 0x00, 0x00, 0x01, 0x00),
+		// IEEE 802.1 Port VLAN ID: 100.
+		lldpTLV(127, 0x00, 0x80, 0xc2, 0x01, 0x00, 0x64),
+		// IEEE 802.1 VLAN name: 200 "cameras".
+		lldpTLV(127, append([]byte{0x00, 0x80, 0xc2, 0x03, 0x00, 0xc8, 0x07},
+			"cameras"...)...),
+	)
+	neighbor, err := lldp.ParseFrame(frame, now)
+	t.Expect(err).ToNot(HaveOccurred())
+	t.Expect(neighbor.Protocol).To(Equal(types.NeighborProtocolLLDP))
+	t.Expect(neighbor.ChassisID).To(Equal("00:11:22:33:44:55"))
+	t.Expect(neighbor.PortID).To(Equal("Gi1/0/12"))
+	t.Expect(neighbor.PortDescription).To(Equal("uplink to gateway"))
+	t.Expect(neighbor.SystemName).To(Equal("plant-switch-3"))
+	t.Expect(neighbor.SystemDescription).To(Equal("Cisco IOS Software"))
+	t.Expect(neighbor.MgmtAddrs).To(HaveLen(1))
+	t.Expect(neighbor.MgmtAddrs[0].Equal(net.ParseIP("10.1.2.3"))).To(BeTrue())
+	t.Expect(neighbor.PortVLAN).To(BeEquivalentTo(100))
+	t.Expect(neighbor.VLANs).To(Equal([]types.NeighborVLAN{{ID: 200, Name: "cameras"}}))
+	t.Expect(neighbor.LastSeen).To(Equal(now))
+	t.Expect(neighbor.ExpiresAt).To(Equal(now.Add(120 * time.Second)))
+
+	// Shutdown LLDPDU (TTL=0).
+	frame = lldpFrame(
+		lldpTLV(1, 0x04, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55),
+		lldpTLV(2, append([]byte{0x05}, "Gi1/0/12"...)...),
+		lldpTLV(3, 0x00, 0x00),
+	)
+	neighbor, err = lldp.ParseFrame(frame, now)
+	t.Expect(err).ToNot(HaveOccurred())
+	t.Expect(neighbor.ExpiresAt.After(now)).To(BeFalse())
+
+	// Missing mandatory TTL TLV.
+	frame = lldpFrame(
+		lldpTLV(1, 0x04, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55),
+		lldpTLV(2, append([]byte{0x05}, "Gi1/0/12"...)...),
+	)
+	_, err = lldp.ParseFrame(frame, now)
+	t.Expect(err).To(HaveOccurred())
+
+	// Truncated TLV.
+	frame = lldpFrame(lldpTLV(1, 0x04, 0x00, 0x11))
+	frame = frame[:len(frame)-4]
+	_, err = lldp.ParseFrame(frame, now)
+	t.Expect(err).To(HaveOccurred())
+}
+
+func TestParseCDP(test *testing.T) {
+	t := NewGomegaWithT(test)
+	now := time.Now()
+	frame := cdpFrame(180,
+		cdpTLV(0x0001, []byte("plant-switch-3.example.com")...),
+		// Addresses: one IPv4 address 10.1.2.3.
+		cdpTLV(0x0002, 0x00, 0x00, 0x00, 0x01, 0x01, 0x01, 0xcc, 0x00, 0x04,
+			10, 1, 2, 3),
+		cdpTLV(0x0003, []byte("GigabitEthernet1/0/12")...),
+		cdpTLV(0x0005, []byte("Cisco IOS Software, Version 15.2")...),
+		cdpTLV(0x0006, []byte("cisco WS-C2960X-48FPD-L")...),
+		cdpTLV(0x000a, 0x00, 0x64),
+		cdpTLV(0x000e, 0x01, 0x01, 0x2c),
+	)
+	neighbor, err := lldp.ParseFrame(frame, now)
+	t.Expect(err).ToNot(HaveOccurred())
+	t.Expect(neighbor.Protocol).To(Equal(types.NeighborProtocolCDP))
+	t.Expect(neighbor.ChassisID).To(Equal("plant-switch-3.example.com"))
+	t.Expect(neighbor.SystemName).To(Equal("plant-switch-3.example.com"))
+	t.Expect(neighbor.PortID).To(Equal("GigabitEthernet1/0/12"))
+	t.Expect(neighbor.SystemDescription).To(Equal(
+		"cisco WS-C2960X-48FPD-L\nCisco IOS Software, Version 15.2"))
+	t.Expect(neighbor.MgmtAddrs).To(HaveLen(1))
+	t.Expect(neighbor.MgmtAddrs[0].Equal(net.ParseIP("10.1.2.3"))).To(BeTrue())
+	t.Expect(neighbor.PortVLAN).To(BeEquivalentTo(100))
+	t.Expect(neighbor.VLANs).To(Equal([]types.NeighborVLAN{{ID: 300, Name: "voice"}}))
+	t.Expect(neighbor.ExpiresAt).To(Equal(now.Add(180 * time.Second)))
+}
+
+func TestParseOtherFrames(test *testing.T) {
+	t := NewGomegaWithT(test)
+	now := time.Now()
+	// STP BPDU (IEEE 802.3 with LLC, but not CDP).
+	frame := []byte{0x01, 0x80, 0xc2, 0x00, 0x00, 0x00}
+	frame = append(frame, srcMAC...)
+	frame = append(frame, 0x00, 0x26, 0x42, 0x42, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00)
+	_, err := lldp.ParseFrame(frame, now)
+	t.Expect(err).To(Equal(lldp.ErrNotDiscoveryFrame))
+	// IPv4 packet.
+	frame = append([]byte{}, srcMAC...)
+	frame = append(frame, srcMAC...)
+	frame = append(frame, 0x08, 0x00, 0x45, 0x00)
+	_, err = lldp.ParseFrame(frame, now)
+	t.Expect(err).To(Equal(lldp.ErrNotDiscoveryFrame))
+}
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"net"
 	"os"
-	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -16,7 +15,10 @@ import (
 	"github.com/fsnotify/fsnotify"
 	"github.com/lf-edge/eve/pkg/pillar/base"
 	"github.com/lf-edge/eve/pkg/pillar/devicenetwork"
+	"github.com/lf-edge/eve/pkg/pillar/dhcpclient"
+	"github.com/lf-edge/eve/pkg/pillar/lldp"
 	"github.com/lf-edge/eve/pkg/pillar/netclone"
+	"github.com/lf-edge/eve/pkg/pillar/types"
 	"github.com/vishvananda/netlink"
 )
 
@@ -40,6 +42,11 @@ type LinuxNetworkMonitor struct {
 	ifIndexToDNS   map[int]DNSInfo
 	ifIndexToDHCP  map[int]DHCPInfo
 	ifIndexToGWs   map[int][]net.IP
+
+	// LLDP/CDP listeners running for interfaces with neighbor discovery
+	// started (key = ifIndex), by physical interface (key = ifIndex).
+	// Not part of the cache.
+	lldpListeners map[int]map[int]*lldp.Listener
 }
 
 type ifAddrs struct {
@@ -59,6 +66,7 @@ func (m *LinuxNetworkMonitor) init() {
 		m.Log.Fatal("Already initialized")
 	}
 	m.initCache()
+	m.lldpListeners = make(map[int]map[int]*lldp.Listener)
 	go m.watcher()
 	m.initialized = true
 }
@@ -227,7 +235,7 @@ func (m *LinuxNetworkMonitor) parseDNSInfo(resolvConf string) (info DNSInfo) {
 }
 
 // GetInterfaceDHCPInfo returns DHCP info for the interface obtained
-// from dhcpcd.
+// from the DHCP client.
 func (m *LinuxNetworkMonitor) GetInterfaceDHCPInfo(ifIndex int) (info DHCPInfo, err error) {
 	m.Lock()
 	defer m.Unlock()
@@ -241,68 +249,43 @@ func (m *LinuxNetworkMonitor) GetInterfaceDHCPInfo(ifIndex int) (info DHCPInfo,
 	if err != nil {
 		return info, err
 	}
-	ifName := attrs.IfName
-	// XXX Getting error -1 unless we add argument -4.
-	// XXX Add IPv6 support.
-	m.Log.Functionf("Calling dhcpcd -U -4 %s\n", ifName)
-	stdoutStderr, err := base.Exec(m.Log, "dhcpcd", "-U", "-4", ifName).CombinedOutput()
+	infoFile := dhcpclient.InfoFile(dhcpclient.DefaultInfoDir, attrs.IfName)
+	clientInfo, err := dhcpclient.ReadInfo(infoFile)
 	if err != nil {
-		if strings.Contains(string(stdoutStderr), "dhcp_dump: No such file or directory") {
-			// DHCP is not configured for this interface. Return empty DHCPInfo.
-			err = nil
-		} else {
-			err = fmt.Errorf("dhcpcd -U failed: %s: %s", string(stdoutStderr), err)
+		if os.IsNotExist(err) {
+			// DHCP is not running or has not obtained lease yet for this interface.
+			// Return empty DHCPInfo.
+			return info, nil
 		}
-		return
+		return info, fmt.Errorf("failed to read DHCP info from %s: %w", infoFile, err)
 	}
-	m.Log.Tracef("dhcpcd -U got %v\n", string(stdoutStderr))
-	lines := strings.Split(string(stdoutStderr), "\n")
-	var masklen int
-	var subnet net.IP
-	for _, line := range lines {
-		items := strings.Split(line, "=")
-		if len(items) != 2 {
-			continue
-		}
-		m.Log.Tracef("Got <%s> <%s>\n", items[0], items[1])
-		switch items[0] {
-		case "network_number":
-			network := trimQuotes(items[1])
-			m.Log.Functionf("GetDhcpInfo(%s) network_number %s\n", ifName,
-				network)
-			ip := net.ParseIP(network)
-			if ip == nil {
-				m.Log.Errorf("Failed to parse %s\n", network)
-				continue
-			}
-			subnet = ip
-		case "subnet_cidr":
-			str := trimQuotes(items[1])
-			m.Log.Functionf("GetDhcpInfo(%s) subnet_cidr %s\n", ifName,
-				str)
-			masklen, err = strconv.Atoi(str)
-			if err != nil {
-				m.Log.Errorf("Failed to parse masklen %s\n", str)
-				continue
-			}
-		case "ntp_servers":
-			str := trimQuotes(items[1])
-			m.Log.Functionf("GetDhcpInfo(%s) ntp_servers %s\n", ifName,
-				str)
-			servers := strings.Split(str, " ")
-			for _, server := range servers {
-				ip := net.ParseIP(server)
-				if ip != nil {
-					info.NtpServers = append(info.NtpServers, ip)
-				}
-			}
-		}
-	}
-	info.Subnet = &net.IPNet{IP: subnet, Mask: net.CIDRMask(masklen, 32)}
+	info = dhcpInfoFromClient(clientInfo)
 	m.ifIndexToDHCP[ifIndex] = info
 	return info, nil
 }
 
+func dhcpInfoFromClient(clientInfo dhcpclient.Info) (info DHCPInfo) {
+	if lease := clientInfo.IPv4; lease != nil {
+		info.Subnet = lease.Subnet()
+		info.NtpServers = append(info.NtpServers, lease.NTPServers...)
+		info.DomainName = lease.DomainName
+		info.Routers = lease.Routers
+		for _, route := range lease.StaticRoutes {
+			info.StaticRoutes = append(info.StaticRoutes, DHCPRoute{
+				Dst: route.Dst,
+				Gw:  route.Gateway,
+			})
+		}
+		info.VendorSpecificInfo = lease.VendorInfo
+		info.WpadURL = lease.WPADURL
+	}
+	if lease := clientInfo.IPv6; lease != nil {
+		info.NtpServers = append(info.NtpServers, lease.NTPServers...)
+		info.IPv6Addr = lease.Address
+	}
+	return info
+}
+
 // GetInterfaceDefaultGWs return a list of IP addresses of default gateways
 // used by the given interface. This is based on routes from the main routing table.
 func (m *LinuxNetworkMonitor) GetInterfaceDefaultGWs(ifIndex int) (gws []net.IP, err error) {
@@ -337,6 +320,103 @@ func (m *LinuxNetworkMonitor) GetInterfaceDefaultGWs(ifIndex int) (gws []net.IP,
 	return gws, nil
 }
 
+// StartNeighborDiscovery starts LLDP/CDP listeners on physical interfaces -
+// either the interface itself, or, in case of a bridge, on the bridged physical
+// interfaces (where link-local discovery frames are received and consumed).
+// Listeners of interfaces which are no longer bridged are stopped.
+func (m *LinuxNetworkMonitor) StartNeighborDiscovery(ifIndex int) error {
+	m.Lock()
+	if !m.initialized {
+		m.init()
+	}
+	physIfs, err := m.getPhysicalInterfaces(ifIndex)
+	if err != nil {
+		m.Unlock()
+		return err
+	}
+	listeners := m.lldpListeners[ifIndex]
+	if listeners == nil {
+		listeners = make(map[int]*lldp.Listener)
+		m.lldpListeners[ifIndex] = listeners
+	}
+	var stale []*lldp.Listener
+	for physIfIndex, listener := range listeners {
+		if _, stillBridged := physIfs[physIfIndex]; !stillBridged {
+			stale = append(stale, listener)
+			delete(listeners, physIfIndex)
+		}
+	}
+	for physIfIndex, physIfName := range physIfs {
+		if _, running := listeners[physIfIndex]; running {
+			continue
+		}
+		listener := lldp.NewListener(m.Log, physIfIndex, physIfName, func() {
+			m.Lock()
+			m.publishEvent(NeighborsChange{IfIndex: ifIndex})
+			m.Unlock()
+		})
+		if err = listener.Start(); err != nil {
+			break
+		}
+		listeners[physIfIndex] = listener
+	}
+	m.Unlock()
+	// Stop outside of the monitor lock - listener may be waiting
+	// to publish NeighborsChange.
+	for _, listener := range stale {
+		listener.Stop()
+	}
+	return err
+}
+
+// getPhysicalInterfaces returns the interface itself if it is physical,
+// or the bridged physical interfaces in case of a bridge (ifIndex -> ifName).
+func (m *LinuxNetworkMonitor) getPhysicalInterfaces(ifIndex int) (map[int]string, error) {
+	attrs, err := m.getInterfaceAttrs(ifIndex)
+	if err != nil {
+		return nil, err
+	}
+	physIfs := make(map[int]string)
+	switch attrs.IfType {
+	case "device":
+		physIfs[ifIndex] = attrs.IfName
+	case "bridge":
+		links, err := netlink.LinkList()
+		if err != nil {
+			return nil, err
+		}
+		for _, link := range links {
+			if link.Attrs().MasterIndex == ifIndex && link.Type() == "device" {
+				physIfs[link.Attrs().Index] = link.Attrs().Name
+			}
+		}
+	}
+	return physIfs, nil
+}
+
+// StopNeighborDiscovery stops LLDP/CDP listeners started for the interface.
+func (m *LinuxNetworkMonitor) StopNeighborDiscovery(ifIndex int) {
+	m.Lock()
+	listeners := m.lldpListeners[ifIndex]
+	delete(m.lldpListeners, ifIndex)
+	m.Unlock()
+	for _, listener := range listeners {
+		listener.Stop()
+	}
+}
+
+// GetInterfaceNeighbors returns neighbors discovered using LLDP and CDP
+// by listeners started with StartNeighborDiscovery.
+func (m *LinuxNetworkMonitor) GetInterfaceNeighbors(ifIndex int) (
+	neighbors []types.PortNeighbor, err error) {
+	m.Lock()
+	defer m.Unlock()
+	for _, listener := range m.lldpListeners[ifIndex] {
+		neighbors = append(neighbors, listener.Neighbors()...)
+	}
+	return neighbors, nil
+}
+
 // ListRoutes returns routes currently present in the routing tables.
 // The set of routes to list can be filtered.
 // ListRoutes is not backed by the cache.
@@ -412,6 +492,16 @@ func (m *LinuxNetworkMonitor) watcher() {
 			m.Log.Fatal(err)
 		}
 	}
+	dhcpWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		m.Log.Fatal(err)
+	}
+	if err = m.createDir(dhcpclient.DefaultInfoDir); err != nil {
+		m.Log.Fatal(err)
+	}
+	if err = dhcpWatcher.Add(dhcpclient.DefaultInfoDir); err != nil {
+		m.Log.Fatal(err)
+	}
 	// Remember previously published IfChange notifications to avoid
 	// spurious events.
 	lastIfChange := make(map[int]IfChange)
@@ -453,7 +543,25 @@ func (m *LinuxNetworkMonitor) watcher() {
 				delete(m.ifIndexToDNS, ifIndex)
 				delete(m.ifIndexToDHCP, ifIndex)
 			}
+			var lldpListeners []*lldp.Listener
+			if deleted {
+				for _, listener := range m.lldpListeners[ifIndex] {
+					lldpListeners = append(lldpListeners, listener)
+				}
+				delete(m.lldpListeners, ifIndex)
+				for _, listeners := range m.lldpListeners {
+					if listener, found := listeners[ifIndex]; found {
+						lldpListeners = append(lldpListeners, listener)
+						delete(listeners, ifIndex)
+					}
+				}
+			}
 			m.Unlock()
+			// Stop outside of the monitor lock - listener may be waiting
+			// to publish NeighborsChange.
+			for _, listener := range lldpListeners {
+				listener.Stop()
+			}
 
 		case addrUpdate, ok := <-addrChan:
 			if !ok {
@@ -470,7 +578,6 @@ func (m *LinuxNetworkMonitor) watcher() {
 			m.publishEvent(event)
 			// Remove cached addresses for this interface.
 			delete(m.ifIndexToAddrs, addrUpdate.LinkIndex)
-			delete(m.ifIndexToDHCP, addrUpdate.LinkIndex)
 			m.Unlock()
 
 		case routeChange, ok := <-routeChan:
@@ -524,6 +631,40 @@ func (m *LinuxNetworkMonitor) watcher() {
 				m.publishEvent(event)
 				m.Unlock()
 			}
+
+		case dhcpChange := <-dhcpWatcher.Events:
+			switch dhcpChange.Op {
+			case fsnotify.Create, fsnotify.Remove, fsnotify.Write:
+				ifName := dhcpclient.InfoFileToIfName(dhcpChange.Name)
+				if ifName == "" || strings.HasPrefix(ifName, ".") {
+					// Not an info file or a temporary file.
+					continue
+				}
+				link, err := netlink.LinkByName(ifName)
+				if err != nil {
+					continue
+				}
+				ifIndex := link.Attrs().Index
+				event := DHCPInfoChange{
+					IfIndex: ifIndex,
+				}
+				if dhcpChange.Op != fsnotify.Remove {
+					clientInfo, err := dhcpclient.ReadInfo(dhcpChange.Name)
+					if err != nil {
+						// File may be already replaced or removed, wait for the next event.
+						continue
+					}
+					event.Info = dhcpInfoFromClient(clientInfo)
+				}
+				m.Lock()
+				if dhcpChange.Op == fsnotify.Remove {
+					delete(m.ifIndexToDHCP, ifIndex)
+				} else {
+					m.ifIndexToDHCP[ifIndex] = event.Info
+				}
+				m.publishEvent(event)
+				m.Unlock()
+			}
 		}
 	}
 }
@@ -606,19 +747,6 @@ func (m *LinuxNetworkMonitor) createDir(dirname string) error {
 	return nil
 }
 
-// Remove single or double quotes.
-func trimQuotes(str string) string {
-	if len(str) < 2 {
-		return str
-	}
-	c := str[len(str)-1]
-	if (c == '"' || c == '\'') && str[0] == c {
-		return str[1 : len(str)-1]
-	} else {
-		return str
-	}
-}
-
 func ipNetFromNetlinkAddr(addr netlink.Addr) *net.IPNet {
 	// For interfaces with a peer (like Point-to-Point, which in EVE is used for wwan),
 	// we must take mask from the peer.
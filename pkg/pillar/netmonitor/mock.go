@@ -12,6 +12,7 @@ import (
 	"sync"
 
 	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/lf-edge/eve/pkg/pillar/types"
 )
 
 // MockNetworkMonitor is used for unit testing.
@@ -23,6 +24,8 @@ type MockNetworkMonitor struct {
 	eventSubs  []subscriber
 	interfaces map[int]MockInterface // key = ifIndex
 	routes     []Route
+	// Interfaces with neighbor discovery started (key = ifIndex).
+	neighborDiscovery map[int]bool
 }
 
 // MockInterface : a simulated network interface and its state.
@@ -32,6 +35,8 @@ type MockInterface struct {
 	HwAddr  net.HardwareAddr
 	DNS     DNSInfo
 	DHCP    DHCPInfo
+	// Neighbors discovered using LLDP/CDP.
+	Neighbors []types.PortNeighbor
 }
 
 // AddOrUpdateInterface : allows to simulate an event of interface being added
@@ -94,6 +99,19 @@ func (m *MockNetworkMonitor) AddOrUpdateInterface(mockIf MockInterface) {
 			Info:    mockIf.DNS,
 		})
 	}
+	// Interface DHCP change event.
+	if !existed || !reflect.DeepEqual(mockIf.DHCP, prev.DHCP) {
+		m.publishEvent(DHCPInfoChange{
+			IfIndex: ifIndex,
+			Info:    mockIf.DHCP,
+		})
+	}
+	// Interface neighbors change event.
+	if existed && !types.EqualPortNeighbors(mockIf.Neighbors, prev.Neighbors) {
+		m.publishEvent(NeighborsChange{
+			IfIndex: ifIndex,
+		})
+	}
 }
 
 // DelInterface : allows to simulate an event of removed interface.
@@ -109,6 +127,7 @@ func (m *MockNetworkMonitor) DelInterface(ifName string) {
 	}
 	mockIf := m.interfaces[ifIndex]
 	delete(m.interfaces, ifIndex)
+	delete(m.neighborDiscovery, ifIndex)
 	for _, ipAddr := range mockIf.IPAddrs {
 		m.publishEvent(AddrChange{
 			IfIndex:   ifIndex,
@@ -120,6 +139,10 @@ func (m *MockNetworkMonitor) DelInterface(ifName string) {
 		IfIndex: ifIndex,
 		Info:    DNSInfo{},
 	})
+	m.publishEvent(DHCPInfoChange{
+		IfIndex: ifIndex,
+		Info:    DHCPInfo{},
+	})
 	m.publishEvent(IfChange{
 		Attrs:   mockIf.Attrs,
 		Deleted: true,
@@ -245,6 +268,50 @@ func (m *MockNetworkMonitor) GetInterfaceDHCPInfo(ifIndex int) (DHCPInfo, error)
 	return mockIf.DHCP, nil
 }
 
+// StartNeighborDiscovery marks neighbor discovery as running for the mock interface.
+func (m *MockNetworkMonitor) StartNeighborDiscovery(ifIndex int) error {
+	m.Lock()
+	defer m.Unlock()
+	if _, exists := m.interfaces[ifIndex]; !exists {
+		return m.ifNotFoundErr(ifIndex)
+	}
+	if m.neighborDiscovery == nil {
+		m.neighborDiscovery = make(map[int]bool)
+	}
+	m.neighborDiscovery[ifIndex] = true
+	return nil
+}
+
+// StopNeighborDiscovery marks neighbor discovery as stopped for the mock interface.
+func (m *MockNetworkMonitor) StopNeighborDiscovery(ifIndex int) {
+	m.Lock()
+	defer m.Unlock()
+	delete(m.neighborDiscovery, ifIndex)
+}
+
+// IsNeighborDiscoveryRunning returns true if neighbor discovery was started
+// (and not stopped) for the mock interface.
+func (m *MockNetworkMonitor) IsNeighborDiscoveryRunning(ifIndex int) bool {
+	m.Lock()
+	defer m.Unlock()
+	return m.neighborDiscovery[ifIndex]
+}
+
+// GetInterfaceNeighbors returns neighbors configured for the mock interface
+// if neighbor discovery is running.
+func (m *MockNetworkMonitor) GetInterfaceNeighbors(ifIndex int) ([]types.PortNeighbor, error) {
+	m.Lock()
+	defer m.Unlock()
+	mockIf, exists := m.interfaces[ifIndex]
+	if !exists {
+		return nil, m.ifNotFoundErr(ifIndex)
+	}
+	if !m.neighborDiscovery[ifIndex] {
+		return nil, nil
+	}
+	return mockIf.Neighbors, nil
+}
+
 // GetInterfaceDefaultGWs returns default gateways associated with the mock interface.
 func (m *MockNetworkMonitor) GetInterfaceDefaultGWs(ifIndex int) (gws []net.IP, err error) {
 	m.Lock()
@@ -6,6 +6,8 @@ package netmonitor
 import (
 	"context"
 	"net"
+
+	"github.com/lf-edge/eve/pkg/pillar/types"
 )
 
 // NetworkMonitor should allow to:
@@ -40,6 +42,19 @@ type NetworkMonitor interface {
 	// used by the given interface. Includes both statically configured GWs as well as
 	// those assigned by DHCP.
 	GetInterfaceDefaultGWs(ifIndex int) ([]net.IP, error)
+	// StartNeighborDiscovery : start discovering devices directly connected
+	// to the given interface using link-layer discovery protocols (LLDP, CDP).
+	// For a bridge, discovery runs on the bridged physical interfaces.
+	// Calling it again for the same interface updates the set of bridged
+	// interfaces to listen on.
+	StartNeighborDiscovery(ifIndex int) error
+	// StopNeighborDiscovery : stop discovery started for the given interface
+	// and forget discovered neighbors.
+	StopNeighborDiscovery(ifIndex int)
+	// GetInterfaceNeighbors : return devices directly connected to the given
+	// interface, as discovered since StartNeighborDiscovery was called.
+	// Returns nothing if the discovery is not running.
+	GetInterfaceNeighbors(ifIndex int) ([]types.PortNeighbor, error)
 	// ListRoutes returns routes currently present in the routing tables.
 	// The set of routes to list can be filtered.
 	ListRoutes(filters RouteFilters) ([]Route, error)
@@ -125,6 +140,22 @@ type DNSInfoChange struct {
 
 func (e DNSInfoChange) isNetworkEvent() {}
 
+// DHCPInfoChange : DHCP information for interface has changed.
+type DHCPInfoChange struct {
+	IfIndex int
+	Info    DHCPInfo
+}
+
+func (e DHCPInfoChange) isNetworkEvent() {}
+
+// NeighborsChange : set of neighbors of the interface discovered using
+// link-layer discovery protocols has changed.
+type NeighborsChange struct {
+	IfIndex int
+}
+
+func (e NeighborsChange) isNetworkEvent() {}
+
 // IfAttrs : interface attributes.
 type IfAttrs struct {
 	// Index of the interface
@@ -169,6 +200,27 @@ type DNSInfo struct {
 
 // DHCPInfo : DHCP information associated with an interface.
 type DHCPInfo struct {
-	Subnet     *net.IPNet
+	Subnet *net.IPNet
+	// NTP servers received from DHCPv4 and DHCPv6 servers.
 	NtpServers []net.IP
+	// Domain name received from DHCPv4 server (option 15).
+	DomainName string
+	// Routers received from DHCPv4 server (option 3).
+	Routers []net.IP
+	// Classless static routes received from DHCPv4 server (option 121).
+	StaticRoutes []DHCPRoute
+	// Vendor-specific information received from DHCPv4 server (option 43).
+	VendorSpecificInfo []byte
+	// URL of the proxy auto-configuration file received from DHCPv4 server
+	// (option 252).
+	WpadURL string
+	// IPv6 address leased from DHCPv6 server.
+	IPv6Addr net.IP
+}
+
+// DHCPRoute : static route received from DHCP server.
+type DHCPRoute struct {
+	Dst *net.IPNet
+	// Gw is unspecified (0.0.0.0) for routes to on-link destinations.
+	Gw net.IP
 }
@@ -0,0 +1,255 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package portquality implements a continuous link quality estimator
+// for device ports.
+// Estimates are built from zedcloud metrics (see types.MetricsMap) published
+// by EVE microservices. This covers requests sent to the controller,
+// downloads of images and remote connectivity probes performed by zedrouter.
+// For every port, the estimator tracks exponentially weighted moving
+// averages of request latency, ratio of failed requests and throughput
+// of bulk transfers, and combines them into a single score between 0 and 100.
+package portquality
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+const (
+	// Weight of a single request (or probe) in the moving averages.
+	// Multiple requests reported together are weighted accordingly.
+	sampleWeight = 0.05
+	// Weight of a single throughput measurement in the moving average.
+	throughputWeight = 0.3
+	// Requests transferring on average at least this many bytes are considered
+	// as bulk transfers. These are used to estimate throughput, not latency.
+	bulkTransferBytes = 256 << 10
+	// Latency up to goodLatency does not decrease the score,
+	// latency of badLatency or more is considered unusable.
+	goodLatency = 100 * time.Millisecond
+	badLatency  = 3 * time.Second
+	// Throughput at or above goodThroughputKbps does not decrease the score.
+	goodThroughputKbps = 10000
+	// Weights of latency and throughput in the score.
+	latencyScoreWeight    = 0.75
+	throughputScoreWeight = 0.25
+	// Scores are recorded into the history at most once per historyInterval.
+	historyInterval = time.Hour
+	// Maximum number of scores recorded in the history of a port.
+	maxHistoryLen = 7 * 24
+)
+
+// Sample : requests and probes made out of a port since the previous sample.
+type Sample struct {
+	Successes uint64
+	Failures  uint64
+	// Number of successful requests with measured latency
+	// and the total time they took.
+	LatencyCount uint64
+	LatencySum   time.Duration
+	// Bytes moved by bulk transfers and the time they took.
+	BulkBytes uint64
+	BulkTime  time.Duration
+}
+
+// Estimator estimates link quality of device ports.
+// It is not thread-safe.
+type Estimator struct {
+	ports map[string]*types.PortQualityEstimate // key: ifName
+	// Last metrics received from every source, used to compute increments.
+	lastMetrics map[string]types.MetricsMap // key: source (agent name)
+}
+
+// NewEstimator creates a new estimator, starting with the (persisted)
+// state of estimates.
+func NewEstimator(history types.PortQualityHistory) *Estimator {
+	e := &Estimator{
+		ports:       make(map[string]*types.PortQualityEstimate),
+		lastMetrics: make(map[string]types.MetricsMap),
+	}
+	for i := range history.Ports {
+		estimate := history.Ports[i]
+		e.ports[estimate.IfName] = &estimate
+	}
+	return e
+}
+
+// UpdateMetrics processes new zedcloud metrics received from the given source
+// (agent). Metrics are cumulative, estimates are updated with increments
+// since the last metrics received from the same source.
+func (e *Estimator) UpdateMetrics(source string, metrics types.MetricsMap, now time.Time) {
+	prevMetrics := e.lastMetrics[source]
+	for ifName, ifMetrics := range metrics {
+		if ifName == "" {
+			continue
+		}
+		prev := prevMetrics[ifName]
+		if ifMetrics.SuccessCount < prev.SuccessCount ||
+			ifMetrics.FailureCount < prev.FailureCount {
+			// Source was restarted and the counters were reset.
+			prev = types.ZedcloudMetric{}
+		}
+		sample := Sample{
+			Successes: ifMetrics.SuccessCount - prev.SuccessCount,
+			Failures:  ifMetrics.FailureCount - prev.FailureCount,
+		}
+		for url, counters := range ifMetrics.URLCounters {
+			prevCounters := prev.URLCounters[url]
+			msgs := counters.SentMsgCount - prevCounters.SentMsgCount
+			timeSpent := counters.TotalTimeSpent - prevCounters.TotalTimeSpent
+			bytes := counters.SentByteCount - prevCounters.SentByteCount +
+				counters.RecvByteCount - prevCounters.RecvByteCount
+			if msgs <= 0 || timeSpent < 0 || bytes < 0 {
+				continue
+			}
+			if bytes/msgs >= bulkTransferBytes {
+				sample.BulkBytes += uint64(bytes)
+				sample.BulkTime += time.Duration(timeSpent) * time.Millisecond
+			} else {
+				sample.LatencyCount += uint64(msgs)
+				sample.LatencySum += time.Duration(timeSpent) * time.Millisecond
+			}
+		}
+		e.AddSample(ifName, sample, now)
+	}
+	e.lastMetrics[source] = metrics
+}
+
+// AddSample updates the estimate for the given port.
+func (e *Estimator) AddSample(ifName string, sample Sample, now time.Time) {
+	requests := sample.Successes + sample.Failures
+	if requests == 0 && sample.LatencyCount == 0 && sample.BulkTime == 0 {
+		return
+	}
+	estimate, exists := e.ports[ifName]
+	if !exists {
+		estimate = &types.PortQualityEstimate{IfName: ifName}
+		e.ports[ifName] = estimate
+	}
+	firstSample := estimate.Samples == 0
+	if requests > 0 {
+		lossRatio := float64(sample.Failures) / float64(requests)
+		if firstSample {
+			estimate.LossRatio = lossRatio
+		} else {
+			estimate.LossRatio = ewma(estimate.LossRatio, lossRatio,
+				batchWeight(requests))
+		}
+	}
+	if sample.LatencyCount > 0 {
+		latencyMs := float64(sample.LatencySum/time.Millisecond) /
+			float64(sample.LatencyCount)
+		if firstSample || estimate.LatencyMs == 0 {
+			estimate.LatencyMs = latencyMs
+		} else {
+			estimate.LatencyMs = ewma(estimate.LatencyMs, latencyMs,
+				batchWeight(sample.LatencyCount))
+		}
+	}
+	if sample.BulkTime > 0 {
+		kbps := float64(sample.BulkBytes) * 8 / 1000 / sample.BulkTime.Seconds()
+		if estimate.ThroughputKbps == 0 {
+			estimate.ThroughputKbps = kbps
+		} else {
+			estimate.ThroughputKbps = ewma(estimate.ThroughputKbps, kbps,
+				throughputWeight)
+		}
+	}
+	estimate.Samples += requests
+	estimate.LastUpdate = now
+}
+
+// Quality returns the current quality estimate for the given port.
+func (e *Estimator) Quality(ifName string) types.PortQuality {
+	estimate, exists := e.ports[ifName]
+	if !exists || estimate.Samples == 0 {
+		return types.PortQuality{}
+	}
+	return types.PortQuality{
+		Score:          Score(*estimate),
+		LatencyMs:      uint32(math.Round(estimate.LatencyMs)),
+		LossPercent:    uint8(math.Round(estimate.LossRatio * 100)),
+		ThroughputKbps: uint64(math.Round(estimate.ThroughputKbps)),
+		Samples:        estimate.Samples,
+		LastUpdate:     estimate.LastUpdate,
+	}
+}
+
+// RecordHistory records current scores of all ports into their history
+// if at least historyInterval has passed since the last record.
+// Returns true if the history was updated.
+func (e *Estimator) RecordHistory(now time.Time) (updated bool) {
+	for _, estimate := range e.ports {
+		if estimate.Samples == 0 {
+			continue
+		}
+		if histLen := len(estimate.History); histLen > 0 {
+			lastRecord := estimate.History[histLen-1].Timestamp
+			if now.Sub(lastRecord) < historyInterval {
+				continue
+			}
+		}
+		estimate.History = append(estimate.History, types.PortQualityScore{
+			Timestamp: now,
+			Score:     Score(*estimate),
+		})
+		if len(estimate.History) > maxHistoryLen {
+			estimate.History = estimate.History[len(estimate.History)-maxHistoryLen:]
+		}
+		updated = true
+	}
+	return updated
+}
+
+// History returns the state of all estimates, suitable for persisting.
+func (e *Estimator) History() types.PortQualityHistory {
+	var history types.PortQualityHistory
+	for _, estimate := range e.ports {
+		estimateCopy := *estimate
+		estimateCopy.History = append([]types.PortQualityScore{}, estimate.History...)
+		history.Ports = append(history.Ports, estimateCopy)
+	}
+	sort.Slice(history.Ports, func(i, j int) bool {
+		return history.Ports[i].IfName < history.Ports[j].IfName
+	})
+	return history
+}
+
+// Score combines the estimated loss ratio, latency and throughput into a single
+// number between 0 (unusable) and 100 (perfect link).
+// Ports without throughput measurement are not penalized.
+func Score(estimate types.PortQualityEstimate) uint8 {
+	latency := time.Duration(estimate.LatencyMs * float64(time.Millisecond))
+	latencyFactor := 1.0
+	if latency > goodLatency {
+		latencyFactor = 1 - float64(latency-goodLatency)/float64(badLatency-goodLatency)
+	}
+	throughputFactor := 1.0
+	if estimate.ThroughputKbps > 0 && estimate.ThroughputKbps < goodThroughputKbps {
+		// Logarithmic scale: 1kbps -> 0, 100kbps -> 0.5, 10Mbps -> 1
+		throughputFactor = math.Log10(estimate.ThroughputKbps) /
+			math.Log10(goodThroughputKbps)
+	}
+	score := (1 - clamp(estimate.LossRatio)) *
+		(latencyScoreWeight*clamp(latencyFactor) +
+			throughputScoreWeight*clamp(throughputFactor))
+	return uint8(math.Round(100 * score))
+}
+
+// batchWeight returns weight for a batch of n requests equivalent to updating
+// the moving average n-times with sampleWeight.
+func batchWeight(n uint64) float64 {
+	return 1 - math.Pow(1-sampleWeight, float64(n))
+}
+
+func ewma(average, value, weight float64) float64 {
+	return (1-weight)*average + weight*value
+}
+
+func clamp(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
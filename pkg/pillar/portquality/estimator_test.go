@@ -0,0 +1,177 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package portquality_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/lf-edge/eve/pkg/pillar/portquality"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+const (
+	configURL   = "controller/api/v2/edgeDevice/config"
+	downloadURL = "datastore/image.qcow2"
+)
+
+func ifMetrics(successes, failures uint64, requestsTimeMs int64,
+	downloads int64, downloadBytes, downloadTimeMs int64) types.ZedcloudMetric {
+	return types.ZedcloudMetric{
+		SuccessCount: successes + uint64(downloads),
+		FailureCount: failures,
+		URLCounters: map[string]types.UrlcloudMetrics{
+			configURL: {
+				SentMsgCount:   int64(successes),
+				SentByteCount:  int64(successes) * 100,
+				RecvMsgCount:   int64(successes),
+				RecvByteCount:  int64(successes) * 1000,
+				TotalTimeSpent: requestsTimeMs,
+			},
+			downloadURL: {
+				SentMsgCount:   downloads,
+				RecvMsgCount:   downloads,
+				RecvByteCount:  downloadBytes,
+				TotalTimeSpent: downloadTimeMs,
+			},
+		},
+	}
+}
+
+func TestPerfectLink(test *testing.T) {
+	t := NewGomegaWithT(test)
+	e := portquality.NewEstimator(types.PortQualityHistory{})
+	t.Expect(e.Quality("eth0").IsKnown()).To(BeFalse())
+
+	now := time.Now()
+	e.UpdateMetrics("zedagent", types.MetricsMap{
+		"eth0": ifMetrics(10, 0, 10*50, 0, 0, 0),
+	}, now)
+	quality := e.Quality("eth0")
+	t.Expect(quality.IsKnown()).To(BeTrue())
+	t.Expect(quality.Score).To(BeEquivalentTo(100))
+	t.Expect(quality.LatencyMs).To(BeEquivalentTo(50))
+	t.Expect(quality.LossPercent).To(BeZero())
+	t.Expect(quality.ThroughputKbps).To(BeZero())
+	t.Expect(quality.Samples).To(BeEquivalentTo(10))
+	t.Expect(quality.LastUpdate).To(Equal(now))
+	t.Expect(e.Quality("eth1").IsKnown()).To(BeFalse())
+}
+
+func TestIncrementalUpdates(test *testing.T) {
+	t := NewGomegaWithT(test)
+	e := portquality.NewEstimator(types.PortQualityHistory{})
+	now := time.Now()
+	e.UpdateMetrics("zedagent", types.MetricsMap{
+		"eth0": ifMetrics(10, 0, 10*50, 0, 0, 0),
+	}, now)
+	// No new requests - nothing changes.
+	e.UpdateMetrics("zedagent", types.MetricsMap{
+		"eth0": ifMetrics(10, 0, 10*50, 0, 0, 0),
+	}, now.Add(time.Minute))
+	t.Expect(e.Quality("eth0").Samples).To(BeEquivalentTo(10))
+	t.Expect(e.Quality("eth0").LastUpdate).To(Equal(now))
+
+	// 20 requests failed.
+	e.UpdateMetrics("zedagent", types.MetricsMap{
+		"eth0": ifMetrics(10, 20, 10*50, 0, 0, 0),
+	}, now.Add(2*time.Minute))
+	quality := e.Quality("eth0")
+	t.Expect(quality.Samples).To(BeEquivalentTo(30))
+	t.Expect(quality.LossPercent).To(BeNumerically(">", 50))
+	t.Expect(quality.Score).To(BeNumerically("<", 50))
+
+	// Link recovers.
+	e.UpdateMetrics("zedagent", types.MetricsMap{
+		"eth0": ifMetrics(110, 20, 110*50, 0, 0, 0),
+	}, now.Add(3*time.Minute))
+	quality = e.Quality("eth0")
+	t.Expect(quality.LossPercent).To(BeNumerically("<", 5))
+	t.Expect(quality.Score).To(BeNumerically(">", 95))
+
+	// Source restarted and counters were reset.
+	e.UpdateMetrics("zedagent", types.MetricsMap{
+		"eth0": ifMetrics(0, 100, 0, 0, 0, 0),
+	}, now.Add(4*time.Minute))
+	quality = e.Quality("eth0")
+	t.Expect(quality.Samples).To(BeEquivalentTo(230))
+	t.Expect(quality.LossPercent).To(BeNumerically(">", 95))
+}
+
+func TestMultipleSources(test *testing.T) {
+	t := NewGomegaWithT(test)
+	e := portquality.NewEstimator(types.PortQualityHistory{})
+	now := time.Now()
+	e.UpdateMetrics("zedagent", types.MetricsMap{
+		"eth0": ifMetrics(10, 0, 10*50, 0, 0, 0),
+		"eth1": ifMetrics(10, 0, 10*1000, 0, 0, 0),
+	}, now)
+	// Download of 10MB which took 40 seconds (2Mbps).
+	e.UpdateMetrics("downloader", types.MetricsMap{
+		"eth0": ifMetrics(0, 0, 0, 1, 10000000, 40000),
+	}, now)
+	eth0 := e.Quality("eth0")
+	t.Expect(eth0.Samples).To(BeEquivalentTo(11))
+	// Download is not used to estimate latency.
+	t.Expect(eth0.LatencyMs).To(BeEquivalentTo(50))
+	t.Expect(eth0.ThroughputKbps).To(BeEquivalentTo(2000))
+	t.Expect(eth0.Score).To(BeNumerically("<", 100))
+	t.Expect(eth0.Score).To(BeNumerically(">", 90))
+	eth1 := e.Quality("eth1")
+	t.Expect(eth1.LatencyMs).To(BeEquivalentTo(1000))
+	t.Expect(eth1.Score).To(BeNumerically("<", eth0.Score))
+}
+
+func TestScore(test *testing.T) {
+	t := NewGomegaWithT(test)
+	t.Expect(portquality.Score(types.PortQualityEstimate{
+		LatencyMs: 20,
+	})).To(BeEquivalentTo(100))
+	t.Expect(portquality.Score(types.PortQualityEstimate{
+		LatencyMs: 20,
+		LossRatio: 1,
+	})).To(BeZero())
+	t.Expect(portquality.Score(types.PortQualityEstimate{
+		LatencyMs: 5000,
+	})).To(BeEquivalentTo(25))
+	t.Expect(portquality.Score(types.PortQualityEstimate{
+		LatencyMs:      5000,
+		ThroughputKbps: 1,
+	})).To(BeZero())
+	t.Expect(portquality.Score(types.PortQualityEstimate{
+		LatencyMs:      20,
+		LossRatio:      0.5,
+		ThroughputKbps: 100,
+	})).To(BeEquivalentTo(44))
+}
+
+func TestHistory(test *testing.T) {
+	t := NewGomegaWithT(test)
+	e := portquality.NewEstimator(types.PortQualityHistory{})
+	now := time.Now()
+	t.Expect(e.RecordHistory(now)).To(BeFalse())
+	e.UpdateMetrics("zedagent", types.MetricsMap{
+		"eth0": ifMetrics(10, 0, 10*50, 0, 0, 0),
+	}, now)
+	t.Expect(e.RecordHistory(now)).To(BeTrue())
+	t.Expect(e.RecordHistory(now.Add(time.Minute))).To(BeFalse())
+	t.Expect(e.RecordHistory(now.Add(time.Hour))).To(BeTrue())
+
+	history := e.History()
+	t.Expect(history.Ports).To(HaveLen(1))
+	t.Expect(history.Ports[0].IfName).To(Equal("eth0"))
+	t.Expect(history.Ports[0].History).To(HaveLen(2))
+	t.Expect(history.Ports[0].History[1].Score).To(BeEquivalentTo(100))
+
+	// Estimator restored from the persisted history.
+	e = portquality.NewEstimator(history)
+	quality := e.Quality("eth0")
+	t.Expect(quality.Samples).To(BeEquivalentTo(10))
+	t.Expect(quality.Score).To(BeEquivalentTo(100))
+	t.Expect(e.RecordHistory(now.Add(90 * time.Minute))).To(BeFalse())
+	t.Expect(e.RecordHistory(now.Add(2 * time.Hour))).To(BeTrue())
+	t.Expect(e.History().Ports[0].History).To(HaveLen(3))
+}
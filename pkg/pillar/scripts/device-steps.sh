@@ -17,13 +17,14 @@ ZTMPDIR=/run/global
 DPCDIR=$ZTMPDIR/DevicePortConfig
 FIRSTBOOTFILE=$ZTMPDIR/first-boot
 FIRSTBOOT=
-AGENTS0="zedagent ledmanager nim nodeagent domainmgr loguploader"
+AGENTS0="zedagent ledmanager nim wwan nodeagent domainmgr loguploader"
 AGENTS1="zedmanager zedrouter downloader verifier baseosmgr wstunnelclient volumemgr watcher zfsmanager"
 AGENTS="$AGENTS0 $AGENTS1"
 TPM_DEVICE_PATH="/dev/tpmrm0"
 SECURITYFSPATH=/sys/kernel/security
 PATH=$BINDIR:$PATH
 TPMINFOTEMPFILE=/var/tmp/tpminfo.txt
+BUNDLEDIR=$PERSISTDIR/bundle
 DISKSPACE_RECOVERY_LIMIT=70
 
 echo "$(date -Ins -u) Starting device-steps.sh"
@@ -64,12 +65,15 @@ wait_for_touch() {
 
 INPUTFILE=/run/nim/DeviceNetworkStatus/global.json
 DEFAULT_NTPSERVER=pool.ntp.org
-# Return one line with all the NTP servers for all the ports
+NTPD_RUNDIR=/run/ntpd
+NTPD_HOOK=$BINDIR/ntpd-hook.sh
+# Return one line with all the NTP servers; statically configured servers
+# first, followed by servers received from DHCP for all the ports
 get_ntp_servers() {
     if [ ! -f "$INPUTFILE" ];  then
         return
     fi
-    res=
+    res=$(jq -r '.NTPServers // [] | .[]' < $INPUTFILE)
     i=0
     while true; do
         portInfo=$(jq -c .Ports[$i] < $INPUTFILE)
@@ -82,8 +86,11 @@ get_ntp_servers() {
         i=$((i + 1))
     done
     out=
-    # Make uniform whitespace separator
+    # Make uniform whitespace separator and skip duplicates
     for r in $res; do
+        case " $out " in
+            *" $r "*) continue;;
+        esac
         if [ -z "$out" ]; then
             out="$r"
         else
@@ -93,15 +100,32 @@ get_ntp_servers() {
     echo "$out"
 }
 
-# Return one (the first) ntp server with default if none
-get_ntp_server() {
+# Return all the ntp servers with default if none
+get_ntp_server_list() {
     res=$(get_ntp_servers)
-    one="$DEFAULT_NTPSERVER"
-    for first in $res; do
-        one=$first
-        break
+    if [ -z "$res" ]; then
+        res="$DEFAULT_NTPSERVER"
+    fi
+    echo "$res"
+}
+
+# Start ntpd with the given (space separated) list of servers and extra options.
+# The list is recorded for pillar, which also reads the synchronization status
+# recorded by the ntpd hook.
+start_ntpd() {
+    servers="$1"
+    shift
+    peers=
+    for server in $servers; do
+        peers="$peers -p $server"
     done
-    echo "$one"
+    mkdir -p "$NTPD_RUNDIR"
+    echo "$servers" | tr ' ' '\n' > "$NTPD_RUNDIR/servers"
+    echo "$(date -Ins -u) ntpd $*$peers -S $NTPD_HOOK"
+    # shellcheck disable=SC2086
+    /usr/sbin/ntpd "$@" $peers -S "$NTPD_HOOK"
+    ret_code=$?
+    echo "$(date -Ins -u) ntpd: $ret_code"
 }
 
 mkdir -p $ZTMPDIR
@@ -330,6 +354,26 @@ access_usb() {
             $BINDIR/hardwaremodel -f -o "$IDENTITYDIR/hardwaremodel.txt"
             sync
         fi
+        if [ -d /mnt/eve-bundle ]; then
+            # Report the state of the last update bundle, and copy a new
+            # bundle for zedagent to verify and apply
+            [ ! -f $BUNDLEDIR/status.json ] || cp $BUNDLEDIR/status.json /mnt/eve-bundle/status.json
+            if [ -f /mnt/eve-bundle/manifest ] &&
+               ! cmp -s /mnt/eve-bundle/manifest $BUNDLEDIR/applied &&
+               ! cmp -s /mnt/eve-bundle/manifest $BUNDLEDIR/staged; then
+                echo "$(date -Ins -u) Copying update bundle from $SPECIAL"
+                rm -rf $BUNDLEDIR/incoming $BUNDLEDIR/incoming.tmp
+                mkdir -p $BUNDLEDIR/incoming.tmp
+                if cp /mnt/eve-bundle/manifest $BUNDLEDIR/incoming.tmp/ &&
+                   { [ ! -d /mnt/eve-bundle/blobs ] || cp -r /mnt/eve-bundle/blobs $BUNDLEDIR/incoming.tmp/; }; then
+                    mv $BUNDLEDIR/incoming.tmp $BUNDLEDIR/incoming
+                else
+                    echo "$(date -Ins -u) Copying update bundle failed"
+                    rm -rf $BUNDLEDIR/incoming.tmp
+                fi
+            fi
+            sync
+        fi
         if [ -d /mnt/dump ]; then
             echo "$(date -Ins -u) Dumping diagnostics to USB stick"
             # Check if it fits without clobbering an existing tar file
@@ -348,7 +392,7 @@ access_usb() {
     fi
 }
 
-# Read any usb.json with DevicePortConfig, and deposit our identity
+# Read any usb.json with DevicePortConfig and update bundle, and deposit our identity
 access_usb
 
 # Update our local /etc/hosts with entries comming from /config
@@ -363,6 +407,12 @@ wait_for_touch nim
 # Add nim to watchdog
 touch "$WATCHDOG_FILE/nim.touch"
 
+# Manage cellular modems
+echo "$(date -Ins -u) Starting wwan"
+$BINDIR/wwan &
+wait_for_touch wwan
+touch "$WATCHDOG_FILE/wwan.touch"
+
 # Print diag output forever on changes
 # NOTE: it is safe to do either kill -STOP or an outright
 # kill -9 on the following cat process if you want to stop
@@ -393,21 +443,15 @@ if [ ! -s "$DEVICE_CERT_NAME" ] || [ $RTC = 0 ] || [ -n "$FIRSTBOOT" ]; then
 
     # We need to try our best to setup time *before* we generate the certifiacte.
     # Otherwise the cert may have start date in the future or in 1970
-    # Did NIM get some NTP servers from DHCP? Pick the first one we find.
+    # Did NIM get some NTP servers from the configuration or from DHCP?
     # Otherwise we use the default
-    NTPSERVER=$(get_ntp_server)
+    NTPSERVERS=$(get_ntp_server_list)
     echo "$(date -Ins -u) Check for NTP config"
     if [ -f /usr/sbin/ntpd ]; then
         # Wait until synchronized and force the clock to be set from ntp
-        echo "$(date -Ins -u) ntpd -q -n -g -p $NTPSERVER"
-        /usr/sbin/ntpd -q -n -g -p "$NTPSERVER"
-        ret_code=$?
-        echo "$(date -Ins -u) ntpd: $ret_code"
+        start_ntpd "$NTPSERVERS" -q -n -g
         # Run ntpd to keep it in sync.
-        echo "$(date -Ins -u) ntpd -p $NTPSERVER"
-        /usr/sbin/ntpd -p "$NTPSERVER"
-        ret_code=$?
-        echo "$(date -Ins -u) ntpd: $ret_code"
+        start_ntpd "$NTPSERVERS"
         # Add ndpd to watchdog
         touch "$WATCHDOG_PID/ntpd.pid"
     else
@@ -429,17 +473,15 @@ if [ ! -s "$DEVICE_CERT_NAME" ] || [ $RTC = 0 ] || [ -n "$FIRSTBOOT" ]; then
     fi
 else
     # Start ntpd before network is up. Assumes it will synchronize later.
-    # Did NIM get some NTP servers from DHCP? Otherwise use default.
+    # Did NIM get some NTP servers from the configuration or from DHCP?
+    # Otherwise use default.
     # If DHCP isn't done we don't get a server here. So we recheck
     # at the end of device-steps.sh
-    NTPSERVER=$(get_ntp_server)
+    NTPSERVERS=$(get_ntp_server_list)
     if [ -f /usr/sbin/ntpd ]; then
         # Run ntpd to keep it in sync. Allow a large initial jump in case clock
         # had drifted more than 1000 seconds while the device was powered off
-        echo "$(date -Ins -u) ntpd -g -p $NTPSERVER"
-        /usr/sbin/ntpd -g -p "$NTPSERVER"
-        ret_code=$?
-        echo "$(date -Ins -u) ntpd: $ret_code"
+        start_ntpd "$NTPSERVERS" -g
         # Add ndpd to watchdog
         touch "$WATCHDOG_PID/ntpd.pid"
     else
@@ -571,12 +613,12 @@ echo "$(date -Ins -u) Done starting EVE version: $(cat /run/eve-release)"
 # and dump any diag information
 while true; do
     access_usb
-    # Check if NTP server changed
+    # Check if NTP servers changed
     # Note that this really belongs in a separate ntpd container
-    ns=$(get_ntp_server)
-    if [ -n "$ns" ] && [ "$ns" != "$NTPSERVER" ] && [ -f /run/ntpd.pid ]; then
-        echo "$(date -Ins -u) NTP server changed from $NTPSERVER to $ns"
-        NTPSERVER="$ns"
+    ns=$(get_ntp_server_list)
+    if [ -n "$ns" ] && [ "$ns" != "$NTPSERVERS" ] && [ -f /run/ntpd.pid ]; then
+        echo "$(date -Ins -u) NTP servers changed from $NTPSERVERS to $ns"
+        NTPSERVERS="$ns"
         ntpd_pid="$(cat /run/ntpd.pid)"
         kill "$ntpd_pid"
         # Wait for it to go away before restarting
@@ -584,10 +626,7 @@ while true; do
             echo "$(date -Ins -u) NTP server $ntpd_pid still running"
             sleep 3
         done
-        echo "$(date -Ins -u) ntpd -g -p $NTPSERVER"
-        /usr/sbin/ntpd -g -p "$NTPSERVER"
-        ret_code=$?
-        echo "$(date -Ins -u) ntpd: $ret_code"
+        start_ntpd "$NTPSERVERS" -g
     fi
     sleep 300
 done
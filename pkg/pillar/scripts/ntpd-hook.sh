@@ -0,0 +1,24 @@
+#!/bin/sh
+#
+# Copyright (c) 2022 Zededa, Inc.
+# SPDX-License-Identifier: Apache-2.0
+#
+# Executed by ntpd (-S option) after stepping the clock, after stratum change
+# and periodically every 11 minutes, with the event passed as the argument
+# ("step", "stratum", "periodic" or "unsync") and the synchronization status
+# passed in environment variables.
+# The status is recorded for pillar, see devicenetwork/ntp.go.
+
+STATUS_DIR=/run/ntpd
+STATUS_FILE="$STATUS_DIR/status"
+
+mkdir -p "$STATUS_DIR"
+cat > "$STATUS_FILE.tmp" <<EOT
+event=$1
+stratum=$stratum
+offset=$offset
+poll_interval=$poll_interval
+freq_drift_ppm=$freq_drift_ppm
+timestamp=$(date +%s)
+EOT
+mv "$STATUS_FILE.tmp" "$STATUS_FILE"
@@ -18,6 +18,8 @@ type BlobStatus struct {
 	DatastoreID uuid.UUID
 	// RelativeURL URL relative to the root of the datastore
 	RelativeURL string
+	// ChunkIndexURL URL of the chunk index relative to the root of the datastore, if any
+	ChunkIndexURL string
 	// Sha256 the sha of the blob
 	Sha256 string
 	// Size size of the expected download
@@ -44,6 +46,8 @@ type BlobStatus struct {
 	CurrentSize            int64 // current total downloaded size as reported by the downloader
 	// Progress percentage downloaded 0-100, defined by CurrentSize/TotalSize
 	Progress uint
+	// DownloadPriority the highest priority of the content trees using the blob
+	DownloadPriority int32
 	// ErrorAndTimeWithSource provide common error handling capabilities
 	ErrorAndTimeWithSource
 }
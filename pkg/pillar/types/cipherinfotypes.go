@@ -138,9 +138,16 @@ func (status CipherBlockStatus) LogKey() string {
 // api/proto/config/acipherinfo.proto - EncryptionBlock
 // Always need to keep these two consistent.
 type EncryptionBlock struct {
-	DsAPIKey          string
-	DsPassword        string
-	WifiUserName      string // If the authentication type is EAP
-	WifiPassword      string
-	ProtectedUserData string
+	DsAPIKey            string
+	DsPassword          string
+	WifiUserName        string // If the authentication type is EAP
+	WifiPassword        string
+	ProtectedUserData   string
+	CellularNetUsername string // If the cellular APN requires authentication
+	CellularNetPassword string
+	// 802.1X credentials of a wired port.
+	Dot1xPassword   string // PEAP password or passphrase of the EAP-TLS private key
+	Dot1xClientKey  string // PEM-encoded private key for EAP-TLS
+	Dot1xClientCert string // PEM-encoded client certificate for EAP-TLS
+	Dot1xCACert     string // PEM-encoded CA certificate(s) of the authentication server
 }
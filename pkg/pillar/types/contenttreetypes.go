@@ -24,6 +24,14 @@ type ContentTreeConfig struct {
 	MaxDownloadSize   uint64
 	GenerationCounter int64
 	DisplayName       string
+	// ChunkIndexURL relative URL of the chunk index of the image, if any
+	ChunkIndexURL string
+	// SignatureKeys PEM public keys or certificates of the image signers;
+	// if set the container image must be signed by one of them
+	SignatureKeys []string
+	// DownloadPriority higher is downloaded first; above zero the download
+	// ignores the download windows
+	DownloadPriority int32
 }
 
 // Key is content info UUID which will be unique
@@ -122,6 +130,14 @@ type ContentTreeStatus struct {
 	NameIsURL    bool
 	// Blobs the sha256 hashes of the blobs that are in this tree, the first of which always is the root
 	Blobs []string
+	// SignatureKeys copied from ContentTreeConfig
+	SignatureKeys []string
+	// SignatureVerified is set once the signature of the image is verified
+	SignatureVerified bool
+	// SignatureError is set if the image is not signed by SignatureKeys
+	SignatureError string
+	// DownloadPriority copied from ContentTreeConfig
+	DownloadPriority int32
 
 	ErrorAndTimeWithSource
 }
@@ -38,6 +38,8 @@ var ReportDirPaths = []string{
 var AppPersistPaths = []string{
 	VolumeEncryptedDirName,
 	VolumeClearDirName,
+	VolumeEncryptedSnapshotDirName,
+	VolumeClearSnapshotDirName,
 	SealedDirName + "/downloader",
 	SealedDirName + "/verifier",
 }
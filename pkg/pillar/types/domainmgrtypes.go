@@ -43,6 +43,10 @@ type DomainConfig struct {
 
 	// MetaDataType for select type of metadata service for app
 	MetaDataType MetaDataType
+
+	// LocalEnvVariables set via local profile server override
+	// the environment variables from the cloud-init user data
+	LocalEnvVariables map[string]string
 }
 
 // MetaDataType of metadata service for app
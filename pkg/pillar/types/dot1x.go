@@ -0,0 +1,88 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// Dot1xEAPMethod : EAP method used for 802.1X authentication of a wired port.
+type Dot1xEAPMethod uint8
+
+const (
+	// Dot1xEAPMethodNone : 802.1X authentication is disabled.
+	Dot1xEAPMethodNone Dot1xEAPMethod = iota
+	// Dot1xEAPMethodTLS : EAP-TLS, mutual authentication using certificates.
+	Dot1xEAPMethodTLS
+	// Dot1xEAPMethodPEAP : PEAP with MSCHAPv2 inner authentication.
+	Dot1xEAPMethodPEAP
+)
+
+// String returns the name of the EAP method as used by wpa_supplicant.
+func (m Dot1xEAPMethod) String() string {
+	switch m {
+	case Dot1xEAPMethodTLS:
+		return "TLS"
+	case Dot1xEAPMethodPEAP:
+		return "PEAP"
+	}
+	return ""
+}
+
+// Dot1xConfig : 802.1X authentication configuration of a wired port.
+type Dot1xConfig struct {
+	EAPMethod Dot1xEAPMethod
+	// Identity presented to the authentication server.
+	Identity string
+	// AnonymousIdentity : identity used in the outer phase of PEAP.
+	AnonymousIdentity string
+	// AllowPEAPWithoutCACert : run PEAP even if no CA certificate is configured,
+	// i.e. without verifying the authentication server.
+	AllowPEAPWithoutCACert bool
+	// CipherBlockStatus carries encrypted password, client certificate
+	// with private key and CA certificate(s) (see EncryptionBlock.Dot1x*).
+	CipherBlockStatus
+}
+
+// IsEnabled returns true if the port should authenticate using 802.1X.
+func (c Dot1xConfig) IsEnabled() bool {
+	return c.EAPMethod != Dot1xEAPMethodNone
+}
+
+// Dot1xAuthState : state of 802.1X authentication of a port.
+type Dot1xAuthState uint8
+
+const (
+	// Dot1xAuthStateNone : 802.1X is not configured for the port.
+	Dot1xAuthStateNone Dot1xAuthState = iota
+	// Dot1xAuthStateUnknown : state of the supplicant is not available.
+	Dot1xAuthStateUnknown
+	// Dot1xAuthStateAuthenticating : authentication is in progress.
+	Dot1xAuthStateAuthenticating
+	// Dot1xAuthStateAuthenticated : port is authorized.
+	Dot1xAuthStateAuthenticated
+	// Dot1xAuthStateFailed : authentication was rejected or has failed.
+	Dot1xAuthStateFailed
+)
+
+// String returns a human-readable name of the authentication state.
+func (s Dot1xAuthState) String() string {
+	switch s {
+	case Dot1xAuthStateNone:
+		return "none"
+	case Dot1xAuthStateUnknown:
+		return "unknown"
+	case Dot1xAuthStateAuthenticating:
+		return "authenticating"
+	case Dot1xAuthStateAuthenticated:
+		return "authenticated"
+	case Dot1xAuthStateFailed:
+		return "failed"
+	}
+	return "invalid"
+}
+
+// Dot1xStatus : status of 802.1X authentication of a port.
+type Dot1xStatus struct {
+	State Dot1xAuthState
+	// Error describes why the authentication failed or why the state
+	// is not known.
+	Error string
+}
@@ -22,6 +22,12 @@ type DownloaderConfig struct {
 	Size        uint64 // In bytes
 	FinalObjDir string // final Object Store
 	RefCount    uint
+	// ChunkIndexURL if set, the file is assembled from chunks
+	// listed in the chunk index; same rules as for Name apply
+	ChunkIndexURL string
+	// Priority higher is downloaded first when the bandwidth is limited;
+	// above zero the download ignores the download windows
+	Priority int32
 }
 
 func (config DownloaderConfig) Key() string {
@@ -111,6 +117,15 @@ type DownloaderStatus struct {
 	Progress      uint      // In percent i.e., 0-100, given by CurrentSize/ExpectedSize
 	ModTime       time.Time
 	ContentType   string // content-type header, if provided
+	// Mirror is the registry mirror which served the download, if any
+	Mirror string
+	// Scheduled is set while the download waits for a download window
+	// which opens at ScheduledTime
+	Scheduled     bool
+	ScheduledTime time.Time
+	// Paused is set if the download was stopped at the end of a download
+	// window; it resumes from where it stopped in the next window
+	Paused bool
 	// ErrorAndTime provides SetErrorNow() and ClearError()
 	ErrorAndTime
 	RetryCount int
@@ -0,0 +1,195 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DownloadWindow is a daily UTC time window in which large downloads may
+// run. A window with End before Start runs past midnight into the next day.
+type DownloadWindow struct {
+	Days  [7]bool       // indexed by time.Weekday
+	Start time.Duration // since midnight
+	End   time.Duration // since midnight, up to 24h
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ParseDownloadWindows parses the comma-separated list of windows in the
+// form "[Day[-Day] ]HH:MM-HH:MM", e.g. "Mon-Fri 22:00-06:00, Sat 00:00-24:00".
+// Without days the window applies to every day. An empty string means no
+// windows i.e., downloads may run at any time.
+func ParseDownloadWindows(s string) ([]DownloadWindow, error) {
+	var windows []DownloadWindow
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		w, err := parseDownloadWindow(item)
+		if err != nil {
+			return nil, fmt.Errorf("download window %q: %v", item, err)
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+func parseDownloadWindow(s string) (DownloadWindow, error) {
+	var w DownloadWindow
+	fields := strings.Fields(s)
+	var times string
+	switch len(fields) {
+	case 1:
+		for i := range w.Days {
+			w.Days[i] = true
+		}
+		times = fields[0]
+	case 2:
+		if err := parseWeekdays(fields[0], &w.Days); err != nil {
+			return w, err
+		}
+		times = fields[1]
+	default:
+		return w, fmt.Errorf("expected [Day[-Day] ]HH:MM-HH:MM")
+	}
+	parts := strings.Split(times, "-")
+	if len(parts) != 2 {
+		return w, fmt.Errorf("expected HH:MM-HH:MM, got %s", times)
+	}
+	var err error
+	if w.Start, err = parseTimeOfDay(parts[0]); err != nil {
+		return w, err
+	}
+	if w.End, err = parseTimeOfDay(parts[1]); err != nil {
+		return w, err
+	}
+	if w.Start == w.End {
+		return w, fmt.Errorf("empty window %s", times)
+	}
+	if w.Start == 24*time.Hour {
+		return w, fmt.Errorf("window can not start at 24:00")
+	}
+	return w, nil
+}
+
+// parseWeekdays parses "Day" or "Day-Day", the range can wrap around
+// the end of the week e.g., "Sat-Sun"
+func parseWeekdays(s string, days *[7]bool) error {
+	parts := strings.Split(s, "-")
+	if len(parts) > 2 {
+		return fmt.Errorf("bad day range %s", s)
+	}
+	first, ok := weekdayNames[strings.ToLower(parts[0])]
+	if !ok {
+		return fmt.Errorf("unknown day %s", parts[0])
+	}
+	last := first
+	if len(parts) == 2 {
+		last, ok = weekdayNames[strings.ToLower(parts[1])]
+		if !ok {
+			return fmt.Errorf("unknown day %s", parts[1])
+		}
+	}
+	for d := first; ; d = (d + 1) % 7 {
+		days[d] = true
+		if d == last {
+			break
+		}
+	}
+	return nil
+}
+
+// parseTimeOfDay parses HH:MM into the duration since midnight
+func parseTimeOfDay(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("expected HH:MM, got %s", s)
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("bad hours in %s", s)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || minutes < 0 || minutes > 59 {
+		return 0, fmt.Errorf("bad minutes in %s", s)
+	}
+	d := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute
+	if hours < 0 || d > 24*time.Hour {
+		return 0, fmt.Errorf("bad hours in %s", s)
+	}
+	return d, nil
+}
+
+// occurrence returns the start and end of the window on the day of base,
+// which must be midnight UTC. ok is false if the window is not on that day.
+func (w DownloadWindow) occurrence(base time.Time) (start, end time.Time, ok bool) {
+	if !w.Days[base.Weekday()] {
+		return start, end, false
+	}
+	length := w.End - w.Start
+	if length < 0 {
+		length += 24 * time.Hour
+	}
+	start = base.Add(w.Start)
+	return start, start.Add(length), true
+}
+
+// midnightUTC returns the midnight UTC of the day of t plus days
+func midnightUTC(t time.Time, days int) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day()+days, 0, 0, 0, 0, time.UTC)
+}
+
+// InDownloadWindows returns true if large downloads may run at t; that is
+// always the case if there are no windows
+func InDownloadWindows(windows []DownloadWindow, t time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+	for _, w := range windows {
+		// a window which started yesterday can run past midnight
+		for days := -1; days <= 0; days++ {
+			start, end, ok := w.occurrence(midnightUTC(t, days))
+			if ok && !t.Before(start) && t.Before(end) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NextDownloadWindow returns the time at or after t when large downloads
+// may run
+func NextDownloadWindow(windows []DownloadWindow, t time.Time) time.Time {
+	if InDownloadWindows(windows, t) {
+		return t
+	}
+	var next time.Time
+	for _, w := range windows {
+		for days := 0; days <= 7; days++ {
+			start, _, ok := w.occurrence(midnightUTC(t, days))
+			if !ok || start.Before(t) {
+				continue
+			}
+			if next.IsZero() || start.Before(next) {
+				next = start
+			}
+			break
+		}
+	}
+	return next
+}
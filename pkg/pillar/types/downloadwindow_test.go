@@ -0,0 +1,64 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDownloadWindows(t *testing.T) {
+	windows, err := ParseDownloadWindows("")
+	assert.NoError(t, err)
+	assert.Empty(t, windows)
+
+	windows, err = ParseDownloadWindows("Mon-Fri 22:00-06:00, sat-sun 00:00-24:00,12:30-13:00")
+	assert.NoError(t, err)
+	assert.Len(t, windows, 3)
+	assert.Equal(t, [7]bool{false, true, true, true, true, true, false}, windows[0].Days)
+	assert.Equal(t, 22*time.Hour, windows[0].Start)
+	assert.Equal(t, 6*time.Hour, windows[0].End)
+	assert.Equal(t, [7]bool{true, false, false, false, false, false, true}, windows[1].Days)
+	assert.Equal(t, 24*time.Hour, windows[1].End)
+	assert.Equal(t, [7]bool{true, true, true, true, true, true, true}, windows[2].Days)
+	assert.Equal(t, 12*time.Hour+30*time.Minute, windows[2].Start)
+
+	for _, bad := range []string{
+		"22:00",
+		"22:00-25:00",
+		"10:00-10:00",
+		"24:00-02:00",
+		"10:60-11:00",
+		"Mon-Foo 10:00-11:00",
+		"Mon 10:00-11:00 extra",
+	} {
+		_, err := ParseDownloadWindows(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestDownloadWindows(t *testing.T) {
+	windows, err := ParseDownloadWindows("Mon-Fri 22:00-06:00")
+	assert.NoError(t, err)
+	// 2022-03-04 is a Friday
+	friday := time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC)
+
+	assert.True(t, InDownloadWindows(nil, friday))
+	assert.True(t, InDownloadWindows(windows, friday.Add(3*time.Hour)))
+	assert.False(t, InDownloadWindows(windows, friday.Add(12*time.Hour)))
+	// Friday night runs into Saturday morning
+	assert.True(t, InDownloadWindows(windows, friday.Add(23*time.Hour)))
+	assert.True(t, InDownloadWindows(windows, friday.Add(29*time.Hour)))
+	assert.False(t, InDownloadWindows(windows, friday.Add(30*time.Hour)))
+
+	now := friday.Add(3 * time.Hour)
+	assert.Equal(t, now, NextDownloadWindow(windows, now))
+	assert.Equal(t, friday.Add(22*time.Hour),
+		NextDownloadWindow(windows, friday.Add(12*time.Hour)))
+	// nothing on the weekend, next is Monday night
+	assert.Equal(t, friday.AddDate(0, 0, 3).Add(22*time.Hour),
+		NextDownloadWindow(windows, friday.Add(36*time.Hour)))
+}
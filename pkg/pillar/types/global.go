@@ -164,6 +164,8 @@ const (
 	AppContainerStatsInterval GlobalSettingKey = "timer.appcontainer.stats.interval"
 	// VaultReadyCutOffTime global setting key
 	VaultReadyCutOffTime GlobalSettingKey = "timer.vault.ready.cutoff"
+	// VaultKeyRotationInterval global setting key, 0 disables key rotation
+	VaultKeyRotationInterval GlobalSettingKey = "timer.vault.key.rotation"
 	// LogRemainToSendMBytes Max gzip log files remain on device to be sent in Mbytes
 	LogRemainToSendMBytes GlobalSettingKey = "newlog.gzipfiles.ondisk.maxmegabytes"
 
@@ -175,6 +177,14 @@ const (
 	// ports for image downloads.
 	DownloadMaxPortCost GlobalSettingKey = "network.download.max.cost"
 
+	// DownloadWindowMinSize global setting key; downloads of at least
+	// this many MBytes only run in the download windows
+	DownloadWindowMinSize GlobalSettingKey = "network.download.window.minsize"
+
+	// DownloadMaxBandwidth global setting key limits the download rate on
+	// each management port in kbit/s; zero means unlimited
+	DownloadMaxBandwidth GlobalSettingKey = "network.download.max.bandwidth"
+
 	// Bool Items
 	// UsbAccess global setting key
 	UsbAccess GlobalSettingKey = "debug.enable.usb"
@@ -190,6 +200,9 @@ const (
 	IgnoreDiskCheckForApps GlobalSettingKey = "storage.apps.ignore.disk.check"
 	// AllowLogFastupload global setting key
 	AllowLogFastupload GlobalSettingKey = "newlog.allow.fastupload"
+	// DownloadPeerCache global setting key enables sharing of verified
+	// blobs with other EVE devices on the same LAN (and fetching from them).
+	DownloadPeerCache GlobalSettingKey = "network.download.peer.cache"
 
 	// TriState Items
 	// NetworkFallbackAnyEth global setting key
@@ -205,6 +218,9 @@ const (
 	DefaultLogLevel GlobalSettingKey = "debug.default.loglevel"
 	// DefaultRemoteLogLevel global setting key
 	DefaultRemoteLogLevel GlobalSettingKey = "debug.default.remote.loglevel"
+	// DownloadWindows global setting key; comma-separated list of the
+	// UTC time windows in which large downloads may run
+	DownloadWindows GlobalSettingKey = "network.download.windows"
 
 	// XXX Temporary flag to disable RFC 3442 classless static route usage
 	DisableDHCPAllOnesNetMask GlobalSettingKey = "debug.disable.dhcp.all-ones.netmask"
@@ -755,6 +771,7 @@ func NewConfigItemSpecMap() ConfigItemSpecMap {
 	configItemSpecMap.AddIntItem(Dom0MinDiskUsagePercent, 20, 20, 80)
 	configItemSpecMap.AddIntItem(AppContainerStatsInterval, 300, 1, 0xFFFFFFFF)
 	configItemSpecMap.AddIntItem(VaultReadyCutOffTime, 300, 60, 0xFFFFFFFF)
+	configItemSpecMap.AddIntItem(VaultKeyRotationInterval, 0, 0, 0xFFFFFFFF)
 	// Dom0DiskUsageMaxBytes - Default is 2GB, min is 100MB
 	configItemSpecMap.AddIntItem(Dom0DiskUsageMaxBytes, 2*1024*1024*1024,
 		100*1024*1024, 0xFFFFFFFF)
@@ -764,6 +781,8 @@ func NewConfigItemSpecMap() ConfigItemSpecMap {
 	// LogRemainToSendMBytes - Default is 2 Gbytes, minimum is 10 Mbytes
 	configItemSpecMap.AddIntItem(LogRemainToSendMBytes, 2048, 10, 0xFFFFFFFF)
 	configItemSpecMap.AddIntItem(DownloadMaxPortCost, 0, 0, 255)
+	configItemSpecMap.AddIntItem(DownloadWindowMinSize, 100, 0, 0xFFFFFFFF)
+	configItemSpecMap.AddIntItem(DownloadMaxBandwidth, 0, 0, 0xFFFFFFFF)
 
 	// Add Bool Items
 	configItemSpecMap.AddBoolItem(UsbAccess, true) // Controller likely default to false
@@ -772,6 +791,7 @@ func NewConfigItemSpecMap() ConfigItemSpecMap {
 	configItemSpecMap.AddBoolItem(IgnoreMemoryCheckForApps, false)
 	configItemSpecMap.AddBoolItem(IgnoreDiskCheckForApps, false)
 	configItemSpecMap.AddBoolItem(AllowLogFastupload, false)
+	configItemSpecMap.AddBoolItem(DownloadPeerCache, false)
 	configItemSpecMap.AddBoolItem(DisableDHCPAllOnesNetMask, false)
 	configItemSpecMap.AddBoolItem(ProcessCloudInitMultiPart, false)
 
@@ -783,6 +803,7 @@ func NewConfigItemSpecMap() ConfigItemSpecMap {
 	configItemSpecMap.AddStringItem(SSHAuthorizedKeys, "", blankValidator)
 	configItemSpecMap.AddStringItem(DefaultLogLevel, "info", parseLevel)
 	configItemSpecMap.AddStringItem(DefaultRemoteLogLevel, "info", parseLevel)
+	configItemSpecMap.AddStringItem(DownloadWindows, "", parseDownloadWindows)
 
 	// Add Agent Settings
 	configItemSpecMap.AddAgentSettingStringItem(LogLevel, "info", parseLevel)
@@ -800,6 +821,13 @@ func parseLevel(level string) error {
 	return err
 }
 
+// parseDownloadWindows - Wrapper that ignores the windows returned by
+// ParseDownloadWindows
+func parseDownloadWindows(s string) error {
+	_, err := ParseDownloadWindows(s)
+	return err
+}
+
 // blankValidator - A validator that accepts any string
 func blankValidator(s string) error {
 	return nil
@@ -170,10 +170,13 @@ func TestNewConfigItemSpecMap(t *testing.T) {
 		Dom0MinDiskUsagePercent,
 		AppContainerStatsInterval,
 		VaultReadyCutOffTime,
+		VaultKeyRotationInterval,
 		Dom0DiskUsageMaxBytes,
 		ForceFallbackCounter,
 		LogRemainToSendMBytes,
 		DownloadMaxPortCost,
+		DownloadWindowMinSize,
+		DownloadMaxBandwidth,
 		// Bool Items
 		UsbAccess,
 		VgaAccess,
@@ -182,6 +185,7 @@ func TestNewConfigItemSpecMap(t *testing.T) {
 		IgnoreMemoryCheckForApps,
 		IgnoreDiskCheckForApps,
 		AllowLogFastupload,
+		DownloadPeerCache,
 		// TriState Items
 		NetworkFallbackAnyEth,
 		MaintenanceMode,
@@ -189,6 +193,7 @@ func TestNewConfigItemSpecMap(t *testing.T) {
 		SSHAuthorizedKeys,
 		DefaultLogLevel,
 		DefaultRemoteLogLevel,
+		DownloadWindows,
 		DisableDHCPAllOnesNetMask,
 		ProcessCloudInitMultiPart,
 		EdgeViewToken,
@@ -0,0 +1,113 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"net"
+)
+
+const (
+	// IPPolicyRuleDefaultPrio : default priority of user-defined IP rules.
+	// Rules are evaluated after those for application traffic (PbrNat*Prio)
+	// and for locally owned destinations (PbrLocalDestPrio), but before
+	// the source-based rules of device ports (PbrLocalOrigPrio).
+	IPPolicyRuleDefaultPrio = 14000
+	// MaxUplinkWeight : maximum weight of a port in the weighted load-balancing.
+	MaxUplinkWeight = 256
+)
+
+// IPRoutingConfig : user-defined IP routing configuration of a device port.
+type IPRoutingConfig struct {
+	StaticRoutes []StaticIPRoute
+	PolicyRules  []IPPolicyRule
+}
+
+// StaticIPRoute : user-defined IP route of a device port.
+type StaticIPRoute struct {
+	Dst net.IPNet
+	// Gateway is nil for on-link route.
+	Gateway net.IP
+	Metric  int
+	// Table : routing table to install the route into.
+	// Zero means the main table, from where the route is mirrored
+	// into the routing table of the port.
+	Table int
+}
+
+// String describes the route.
+func (r StaticIPRoute) String() string {
+	return fmt.Sprintf("%s via %v metric %d table %d",
+		r.Dst.String(), r.Gateway, r.Metric, r.Table)
+}
+
+// IPPolicyRule : user-defined IP rule selecting traffic to route using
+// the routing table of a device port.
+type IPPolicyRule struct {
+	// Src is nil to match any source.
+	Src *net.IPNet
+	// Dst is nil to match any destination.
+	Dst *net.IPNet
+	// Priority : zero means IPPolicyRuleDefaultPrio.
+	Priority int
+	// Table : zero means the routing table of the port.
+	Table int
+}
+
+// String describes the rule.
+func (r IPPolicyRule) String() string {
+	src, dst := "all", "all"
+	if r.Src != nil {
+		src = r.Src.String()
+	}
+	if r.Dst != nil {
+		dst = r.Dst.String()
+	}
+	return fmt.Sprintf("from %s to %s prio %d table %d",
+		src, dst, r.Priority, r.Table)
+}
+
+// UplinkLBMode : mode of load-balancing of application traffic across
+// multiple uplinks of a local network instance.
+type UplinkLBMode uint8
+
+const (
+	// UplinkLBModeNone : traffic is routed via a single uplink.
+	UplinkLBModeNone UplinkLBMode = iota
+	// UplinkLBModeECMP : equal-cost multi-path routing across all usable
+	// uplinks.
+	UplinkLBModeECMP
+	// UplinkLBModeWeighted : multi-path routing with traffic split according
+	// to port weights.
+	UplinkLBModeWeighted
+)
+
+// UplinkLoadBalancing : configuration of a multi-WAN local network instance.
+type UplinkLoadBalancing struct {
+	Mode UplinkLBMode
+	// Weights used with UplinkLBModeWeighted.
+	// Key is port logical label, ports not listed have weight 1.
+	Weights map[string]uint32
+}
+
+// IsEnabled returns true if traffic should be load-balanced across
+// multiple uplinks.
+func (lb UplinkLoadBalancing) IsEnabled() bool {
+	return lb.Mode != UplinkLBModeNone
+}
+
+// PortWeight returns weight of the given port.
+func (lb UplinkLoadBalancing) PortWeight(logicalLabel string) int {
+	if lb.Mode != UplinkLBModeWeighted {
+		return 1
+	}
+	weight, ok := lb.Weights[logicalLabel]
+	if !ok || weight == 0 {
+		return 1
+	}
+	if weight > MaxUplinkWeight {
+		return MaxUplinkWeight
+	}
+	return int(weight)
+}
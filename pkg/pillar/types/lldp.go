@@ -0,0 +1,113 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// NeighborProtocol : link-layer discovery protocol used to learn about
+// a port neighbor.
+type NeighborProtocol uint8
+
+const (
+	// NeighborProtocolUnknown : protocol is not known.
+	NeighborProtocolUnknown NeighborProtocol = iota
+	// NeighborProtocolLLDP : IEEE 802.1AB Link Layer Discovery Protocol.
+	NeighborProtocolLLDP
+	// NeighborProtocolCDP : Cisco Discovery Protocol.
+	NeighborProtocolCDP
+)
+
+// String returns the protocol name.
+func (p NeighborProtocol) String() string {
+	switch p {
+	case NeighborProtocolLLDP:
+		return "LLDP"
+	case NeighborProtocolCDP:
+		return "CDP"
+	}
+	return "unknown"
+}
+
+// NeighborVLAN : VLAN advertised by a port neighbor.
+type NeighborVLAN struct {
+	ID   uint16
+	Name string
+}
+
+// PortNeighbor : network device (typically a switch) directly connected
+// to a device port, as learned from LLDP or CDP advertisements.
+type PortNeighbor struct {
+	Protocol NeighborProtocol
+	// ChassisID identifies the neighbor device (usually its MAC address).
+	ChassisID string
+	// PortID identifies the port of the neighbor device where our port
+	// is plugged into.
+	PortID            string
+	PortDescription   string
+	SystemName        string
+	SystemDescription string
+	// PortVLAN : untagged (native) VLAN of the neighbor port.
+	// Zero if not advertised.
+	PortVLAN uint16
+	// VLANs configured on the neighbor port.
+	VLANs     []NeighborVLAN
+	MgmtAddrs []net.IP
+	// LastSeen : time when the last advertisement was received.
+	LastSeen time.Time
+	// ExpiresAt : time when the neighbor information should be discarded
+	// unless refreshed by another advertisement.
+	ExpiresAt time.Time
+}
+
+// Key uniquely identifies the neighbor among all neighbors of a port.
+func (n PortNeighbor) Key() string {
+	return fmt.Sprintf("%s/%s/%s", n.Protocol, n.ChassisID, n.PortID)
+}
+
+// Equal compares two neighbors, ignoring timestamps.
+func (n PortNeighbor) Equal(n2 PortNeighbor) bool {
+	if n.Protocol != n2.Protocol ||
+		n.ChassisID != n2.ChassisID ||
+		n.PortID != n2.PortID ||
+		n.PortDescription != n2.PortDescription ||
+		n.SystemName != n2.SystemName ||
+		n.SystemDescription != n2.SystemDescription ||
+		n.PortVLAN != n2.PortVLAN {
+		return false
+	}
+	if len(n.VLANs) != len(n2.VLANs) {
+		return false
+	}
+	for i := range n.VLANs {
+		if n.VLANs[i] != n2.VLANs[i] {
+			return false
+		}
+	}
+	if len(n.MgmtAddrs) != len(n2.MgmtAddrs) {
+		return false
+	}
+	for i := range n.MgmtAddrs {
+		if !n.MgmtAddrs[i].Equal(n2.MgmtAddrs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// EqualPortNeighbors compares two lists of port neighbors, ignoring timestamps.
+func EqualPortNeighbors(list1, list2 []PortNeighbor) bool {
+	if len(list1) != len(list2) {
+		return false
+	}
+	for i := range list1 {
+		if !list1[i].Equal(list2[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,24 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+const (
+	// LocalBundleDir : update bundles applied without the controller,
+	// from a USB stick or from the local profile server.
+	LocalBundleDir = PersistDir + "/bundle"
+	// LocalBundleIncomingDir : where a bundle found on a USB stick is
+	// copied at boot, in the same layout as on the stick.
+	LocalBundleIncomingDir = LocalBundleDir + "/incoming"
+	// LocalBundleBlobsDir : verified images of the staged bundle, named
+	// by their sha256. The downloader takes the images from here instead
+	// of the datastore.
+	LocalBundleBlobsDir = LocalBundleDir + "/blobs"
+	// LocalBundleManifestFile : signed manifest in a bundle directory
+	LocalBundleManifestFile = "manifest"
+	// LocalBundleBlobsSubdir : images in a bundle directory
+	LocalBundleBlobsSubdir = "blobs"
+	// LocalBundleMediaTypeSuffix : suffix of the file, next to an image
+	// in LocalBundleBlobsDir, with the media type of the image.
+	LocalBundleMediaTypeSuffix = ".mediatype"
+)
@@ -17,6 +17,8 @@ const (
 	PersistStatusDir = PersistDir + "/status"
 	// CertificateDirname - Location of certificates
 	CertificateDirname = PersistDir + "/certs"
+	// CheckpointDirname - Location of config checkpoint
+	CheckpointDirname = PersistDir + "/checkpoint"
 	// SealedDirName - directory sealed under TPM PCRs
 	SealedDirName = PersistDir + "/vault"
 	// VolumeEncryptedDirName - sealed directory used to store volumes
@@ -25,6 +27,12 @@ const (
 	ClearDirName = PersistDir + "/clear"
 	// VolumeClearDirName - Not encrypted directory used to store volumes
 	VolumeClearDirName = ClearDirName + "/volumes"
+	// VolumeEncryptedSnapshotDirName - sealed directory used to store
+	// snapshots of volumes taken on request of local profile server
+	VolumeEncryptedSnapshotDirName = SealedDirName + "/snapshots"
+	// VolumeClearSnapshotDirName - Not encrypted directory used to store
+	// snapshots of volumes taken on request of local profile server
+	VolumeClearSnapshotDirName = ClearDirName + "/snapshots"
 	// PersistDebugDir - Location for service specific debug/traces
 	PersistDebugDir = PersistDir + "/agentdebug"
 	// PersistInstallerDir - location for installer output
@@ -0,0 +1,110 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// NTPSyncState : state of the device clock synchronization.
+type NTPSyncState uint8
+
+const (
+	// NTPSyncStateUnknown : synchronization state is not known.
+	NTPSyncStateUnknown NTPSyncState = iota
+	// NTPSyncStateSynchronized : device clock is synchronized with NTP server(s).
+	NTPSyncStateSynchronized
+	// NTPSyncStateUnsynchronized : device clock is not synchronized.
+	NTPSyncStateUnsynchronized
+)
+
+// String returns the state name.
+func (s NTPSyncState) String() string {
+	switch s {
+	case NTPSyncStateSynchronized:
+		return "synchronized"
+	case NTPSyncStateUnsynchronized:
+		return "unsynchronized"
+	}
+	return "unknown"
+}
+
+// NTPStatus : status of the device clock synchronization, published by NIM.
+type NTPStatus struct {
+	State NTPSyncState
+	// Source : NTP client from which the status was obtained
+	// ("ntpd", "chrony" or "kernel" if only the kernel clock state is known).
+	Source string
+	// Servers : NTP servers used by the NTP client.
+	Servers []string
+	// Stratum of the device clock. Zero if not known.
+	Stratum uint8
+	// Offset : estimated offset of the device clock from the NTP time.
+	Offset time.Duration
+	// PollInterval : current polling interval of the NTP client.
+	PollInterval time.Duration
+	// LastUpdate : time when the status was last updated by the NTP client.
+	LastUpdate time.Time
+}
+
+// Key is used for pubsub
+func (s NTPStatus) Key() string {
+	return "global"
+}
+
+// MostlyEqual compares two NTP statuses, ignoring the clock offset
+// and the time of the last update, which change continuously.
+func (s NTPStatus) MostlyEqual(s2 NTPStatus) bool {
+	if s.State != s2.State || s.Source != s2.Source ||
+		s.Stratum != s2.Stratum || s.PollInterval != s2.PollInterval {
+		return false
+	}
+	return equalStringLists(s.Servers, s2.Servers)
+}
+
+// Equal compares two NTP statuses.
+func (s NTPStatus) Equal(s2 NTPStatus) bool {
+	return s.MostlyEqual(s2) && s.Offset == s2.Offset &&
+		s.LastUpdate.Equal(s2.LastUpdate)
+}
+
+// CertTimeInvalid is returned when a server certificate was rejected because
+// the current time is outside of its validity period. Unless the certificate
+// is really expired, this is caused by a skewed device clock.
+type CertTimeInvalid struct {
+	IfName    string
+	Subject   string
+	NotBefore time.Time
+	NotAfter  time.Time
+	// Now : device time when the certificate was validated.
+	Now time.Time
+}
+
+// Error message.
+func (e *CertTimeInvalid) Error() string {
+	return fmt.Sprintf("cert failure for Subject %s NotBefore %v NotAfter %v "+
+		"(current device time %v)", e.Subject, e.NotBefore, e.NotAfter, e.Now)
+}
+
+// NotYetValid returns true if the certificate was rejected because
+// the device clock is behind the start of the certificate validity period.
+// This is a clear indication of a clock skew.
+func (e *CertTimeInvalid) NotYetValid() bool {
+	return e.Now.Before(e.NotBefore)
+}
+
+// equalStringLists compares two lists of strings, order matters.
+// Nil and empty list are considered equal.
+func equalStringLists(list1, list2 []string) bool {
+	if len(list1) != len(list2) {
+		return false
+	}
+	for i := range list1 {
+		if list1[i] != list2[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+const (
+	// PeerCachePort : TCP port on which the downloader serves verified
+	// blobs to other EVE devices on the same LAN (when DownloadPeerCache
+	// is enabled).
+	PeerCachePort = 8488
+	// PeerCacheService : mDNS service type advertised by devices
+	// with the peer cache enabled.
+	PeerCacheService = "_eve-peercache._tcp"
+	// MDNSPort : UDP port used by multicast DNS.
+	MDNSPort = 5353
+)
+
+// PeerCacheConfig : device certificates of the peers with which blobs
+// may be shared, as vouched for by the controller.
+type PeerCacheConfig struct {
+	// DeviceCertsPEM : PEM device certificates of the peers.
+	DeviceCertsPEM [][]byte
+}
+
+// Key : there is a single PeerCacheConfig.
+func (config PeerCacheConfig) Key() string {
+	return "global"
+}
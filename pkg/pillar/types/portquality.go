@@ -0,0 +1,100 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"sort"
+	"time"
+)
+
+// PortQuality : link quality estimated by NIM for a device port.
+// The estimate is based on controller requests, downloads and probes
+// made by EVE microservices out of the port (see pillar/portquality).
+type PortQuality struct {
+	// Score between 0 (unusable) and 100 (perfect link).
+	// Valid only if Samples is non-zero.
+	Score uint8
+	// Average request latency.
+	LatencyMs uint32
+	// Percentage of failed requests and probes.
+	LossPercent uint8
+	// Throughput measured from bulk transfers. Zero if not measured yet.
+	ThroughputKbps uint64
+	// Number of requests and probes the estimate is based on.
+	Samples    uint64
+	LastUpdate time.Time
+}
+
+// IsKnown returns true if there is at least one sample to estimate
+// the port quality from.
+func (q PortQuality) IsKnown() bool {
+	return q.Samples > 0
+}
+
+const (
+	// PortQualityBandWidth : ports with scores falling into the same band
+	// are considered equally healthy. This prevents the port selection
+	// from flapping between ports because of small changes in their scores.
+	PortQualityBandWidth = 20
+	// PortQualityUnknownScore : score assumed for a port without samples.
+	PortQualityUnknownScore = 50
+)
+
+// band returns the quality band of the port.
+func (q PortQuality) band() int {
+	score := int(q.Score)
+	if !q.IsKnown() {
+		score = PortQualityUnknownScore
+	}
+	if score >= 100 {
+		// Make the top band as wide as the others.
+		score = 99
+	}
+	return score / PortQualityBandWidth
+}
+
+// sortPortsByQuality sorts (in-place) the given interface names
+// by the estimated link quality, the healthiest port first.
+// The sort is stable, i.e. ports of the same quality band keep their
+// relative order (which is used for round-robin within the same cost).
+func sortPortsByQuality(globalStatus DeviceNetworkStatus, ifnameList []string) {
+	bands := make(map[string]int)
+	for _, port := range globalStatus.Ports {
+		bands[port.IfName] = port.Quality.band()
+	}
+	sort.SliceStable(ifnameList, func(i, j int) bool {
+		return bands[ifnameList[i]] > bands[ifnameList[j]]
+	})
+}
+
+// PortQualityEstimate : state of the link quality estimator for one port.
+// Values are exponentially weighted moving averages.
+type PortQualityEstimate struct {
+	IfName         string
+	LatencyMs      float64
+	LossRatio      float64 // between 0 and 1
+	ThroughputKbps float64 // zero if not measured yet
+	Samples        uint64
+	LastUpdate     time.Time
+	// Scores recorded periodically, the oldest first.
+	History []PortQualityScore
+}
+
+// PortQualityScore : score of a port recorded at the given time.
+type PortQualityScore struct {
+	Timestamp time.Time
+	Score     uint8
+}
+
+// PortQualityHistory : state of the port quality estimator for all ports.
+// Published by NIM as persistent under the key "global" so that
+// the estimates and their history survive reboots.
+type PortQualityHistory struct {
+	Ports []PortQualityEstimate
+}
+
+// Key is used for pubsub
+func (h PortQualityHistory) Key() string {
+	return "global"
+}
@@ -19,6 +19,9 @@ type ResolveConfig struct {
 	DatastoreID uuid.UUID
 	Name        string
 	Counter     uint32
+	// SignatureKeys if set the resolver also verifies that the image
+	// is signed by one of these PEM public keys or certificates
+	SignatureKeys []string
 }
 
 // Key : DatastoreID, name and sequence counter are used
@@ -78,6 +81,14 @@ type ResolveStatus struct {
 	ErrorAndTime
 	// We save the original error when we do a retry
 	OrigError string
+	// SignatureVerified is set if ResolveConfig.SignatureKeys are set and
+	// the image is signed by one of them
+	SignatureVerified bool
+	// SignatureError is set if the image is not signed by any of
+	// ResolveConfig.SignatureKeys; the error is also in ErrorAndTime
+	SignatureError string
+	// SignatureKeys copied from the ResolveConfig this status is for
+	SignatureKeys []string
 }
 
 // Key : DatastoreID, name and sequence counter are used
@@ -16,10 +16,29 @@ type VaultStatus struct {
 	Status             info.DataSecAtRestStatus
 	PCRStatus          info.PCRStatus
 	ConversionComplete bool
+	//PCREventDiffs is set when the vault key could not be unsealed,
+	//and lists measurements changed since the last successful unseal
+	PCREventDiffs []PCREventDiff
 	// ErrorAndTime provides SetErrorNow() and ClearError()
 	ErrorAndTime
 }
 
+//MeasuredBootEvent is an event from the TPM measurement log
+type MeasuredBootEvent struct {
+	Sequence    uint32 //Sequence number of the event in the measurement log
+	EventType   uint32
+	Digest      []byte //SHA256 digest extended into the PCR
+	Description string //Event type name and printable event data
+}
+
+//PCREventDiff holds measurements of a PCR which differ between
+//the current and the last known good measurement log
+type PCREventDiff struct {
+	Index   int
+	Added   []MeasuredBootEvent //Events of the current boot only
+	Removed []MeasuredBootEvent //Events of the last known good boot only
+}
+
 // VaultConfig represents vault key to be used
 type VaultConfig struct {
 	TpmKeyOnly bool
@@ -79,6 +98,18 @@ func (status VaultStatus) LogKey() string {
 	return string(base.VaultStatusLogType) + "-" + status.Key()
 }
 
+//VaultKeyEscrowStatus is published by zedagent once Controller acknowledged
+//storing the vault key sent in EncryptedVaultKeyFromDevice
+type VaultKeyEscrowStatus struct {
+	Name         string
+	DigestSha256 []byte // of the stored vault key
+}
+
+//Key returns name of the vault corresponding to this object
+func (status VaultKeyEscrowStatus) Key() string {
+	return status.Name
+}
+
 //EncryptedVaultKeyFromDevice is published by vaultmgr towards Controller (through zedagent)
 type EncryptedVaultKeyFromDevice struct {
 	Name              string
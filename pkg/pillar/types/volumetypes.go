@@ -25,8 +25,11 @@ type VolumeConfig struct {
 	GenerationCounter       int64
 	LocalGenerationCounter  int64
 	Encrypted               bool
+	IsolatedKey             bool      // encrypted with its own key, see VolumeKeyState
+	AppInstanceID           uuid.UUID // app instance using the volume, it owns the isolated key
 	DisplayName             string
 	HasNoAppReferences      bool
+	LocalSnapshotCounter    uint32 // Snapshots requested via local profile server
 }
 
 // Key is volume UUID which will be unique
@@ -108,6 +111,33 @@ const (
 	VolumeSubStateDeleting
 )
 
+// VolumeKeyState is the state of the key of the volume with IsolatedKey,
+// it should be in sync with info api
+type VolumeKeyState uint8
+
+// Enum of VolumeKeyState variants
+const (
+	VolumeKeyStateUnspecified VolumeKeyState = iota // volume uses the key of the vault, if any
+	VolumeKeyStateLoaded                            // key is unsealed and loaded, volume is accessible
+	VolumeKeyStateError                             // key cannot be created, unsealed or loaded
+	VolumeKeyStateDestroyed                         // key is destroyed together with the volume
+)
+
+func (state VolumeKeyState) String() string {
+	switch state {
+	case VolumeKeyStateUnspecified:
+		return "unspecified"
+	case VolumeKeyStateLoaded:
+		return "loaded"
+	case VolumeKeyStateError:
+		return "error"
+	case VolumeKeyStateDestroyed:
+		return "destroyed"
+	default:
+		return fmt.Sprintf("unknown(%d)", state)
+	}
+}
+
 // VolumeStatus is response from volumemgr about status of volumes
 type VolumeStatus struct {
 	VolumeID                uuid.UUID
@@ -118,6 +148,9 @@ type VolumeStatus struct {
 	GenerationCounter       int64
 	LocalGenerationCounter  int64
 	Encrypted               bool
+	IsolatedKey             bool
+	AppInstanceID           uuid.UUID
+	KeyState                VolumeKeyState
 	DisplayName             string
 	State                   SwState
 	SubState                volumeSubState
@@ -133,6 +166,13 @@ type VolumeStatus struct {
 	PreReboot               bool // Was volume last use prior to device reboot?
 	ReferenceName           string
 	WWN                     string
+	LocalSnapshotCounter    uint32    // Last handled VolumeConfig.LocalSnapshotCounter
+	LocalSnapshotInprogress bool      // Local snapshot is being taken
+	LocalSnapshotTime       time.Time // When the last local snapshot was taken
+	LocalSnapshotError      string    // Set if the last local snapshot failed
+	// SignatureError is set if the image of the content tree is not
+	// signed by any of the required keys
+	SignatureError string
 
 	ErrorAndTimeWithSource
 }
@@ -440,6 +480,7 @@ type VolumeCreatePending struct {
 	LocalGenerationCounter int64
 	ContentFormat          zconfig.Format
 	Encrypted              bool
+	IsolatedKey            bool
 }
 
 // Key : VolumeCreatePending unique key
@@ -478,6 +519,7 @@ func VolumeCreatePendingFromVolumeStatus(status VolumeStatus) VolumeCreatePendin
 		LocalGenerationCounter: status.LocalGenerationCounter,
 		ContentFormat:          status.ContentFormat,
 		Encrypted:              status.Encrypted,
+		IsolatedKey:            status.IsolatedKey,
 	}
 }
 
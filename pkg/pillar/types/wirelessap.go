@@ -0,0 +1,94 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+)
+
+const (
+	// DefaultWifiAPChannel : channel used by access point if not configured.
+	DefaultWifiAPChannel = 6
+	// MaxWifiSSIDLen : maximum length of SSID in bytes.
+	MaxWifiSSIDLen = 32
+	// MinWifiPassphraseLen : minimum length of WPA passphrase.
+	MinWifiPassphraseLen = 8
+	// MaxWifiPassphraseLen : maximum length of WPA passphrase.
+	MaxWifiPassphraseLen = 63
+)
+
+// WirelessAccessPoint : wireless (WiFi) adapter operating as an access point
+// bridged into a local network instance.
+// Only WPA2-PSK is supported.
+type WirelessAccessPoint struct {
+	// Adapter : logical label of the wireless adapter.
+	Adapter string
+	SSID    string
+	// Channel : WiFi channel, zero means DefaultWifiAPChannel.
+	Channel uint32
+	// CountryCode : ISO/IEC 3166-1 country code selecting the regulatory domain.
+	CountryCode string
+	// HiddenSSID : do not broadcast SSID.
+	HiddenSSID bool
+	// CipherBlockStatus carries encrypted WPA passphrase
+	// (see EncryptionBlock.WifiPassword).
+	CipherBlockStatus
+}
+
+// IsEnabled returns true if the access point is configured.
+func (ap WirelessAccessPoint) IsEnabled() bool {
+	return ap.Adapter != ""
+}
+
+// GetChannel returns the configured channel or the default one.
+func (ap WirelessAccessPoint) GetChannel() uint32 {
+	if ap.Channel == 0 {
+		return DefaultWifiAPChannel
+	}
+	return ap.Channel
+}
+
+// Is5GHz returns true if the configured channel is from the 5GHz band.
+func (ap WirelessAccessPoint) Is5GHz() bool {
+	return ap.GetChannel() > 14
+}
+
+// Validate checks the access point configuration (except for the encrypted
+// passphrase, see ValidateWifiPassphrase).
+func (ap WirelessAccessPoint) Validate() error {
+	if len(ap.SSID) == 0 || len(ap.SSID) > MaxWifiSSIDLen {
+		return fmt.Errorf("invalid SSID length %d (expected 1-%d bytes)",
+			len(ap.SSID), MaxWifiSSIDLen)
+	}
+	channel := ap.GetChannel()
+	if (channel > 14 && channel < 32) || channel > 177 {
+		return fmt.Errorf("invalid WiFi channel %d", channel)
+	}
+	if ap.CountryCode != "" {
+		if len(ap.CountryCode) != 2 ||
+			!isUpperLetter(ap.CountryCode[0]) || !isUpperLetter(ap.CountryCode[1]) {
+			return fmt.Errorf("invalid country code %q", ap.CountryCode)
+		}
+	}
+	return nil
+}
+
+// ValidateWifiPassphrase checks that the passphrase is usable with WPA-PSK,
+// i.e. it consists of 8-63 printable ASCII characters.
+func ValidateWifiPassphrase(passphrase string) error {
+	if len(passphrase) < MinWifiPassphraseLen || len(passphrase) > MaxWifiPassphraseLen {
+		return fmt.Errorf("invalid WPA passphrase length %d (expected %d-%d characters)",
+			len(passphrase), MinWifiPassphraseLen, MaxWifiPassphraseLen)
+	}
+	for i := 0; i < len(passphrase); i++ {
+		if passphrase[i] < 32 || passphrase[i] > 126 {
+			return fmt.Errorf("WPA passphrase contains non-printable character")
+		}
+	}
+	return nil
+}
+
+func isUpperLetter(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
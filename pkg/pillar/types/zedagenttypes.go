@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	zconfig "github.com/lf-edge/eve/api/go/config"
 	"github.com/lf-edge/eve/pkg/pillar/base"
 	uuid "github.com/satori/go.uuid"
 )
@@ -26,6 +27,51 @@ type BaseOsConfig struct {
 	ContentTreeConfigList []ContentTreeConfig
 	RetryCount            int32
 	Activate              bool
+	// Deltas from older releases; used instead of ContentTreeConfigList
+	// if one of them applies to the current partition
+	Deltas []BaseOsDelta
+	// Checks which have to pass in the testing window of this BaseOs
+	HealthGates BaseOsHealthGates
+}
+
+// BaseOsDelta : binary patch which reconstructs the image of BaseOsConfig
+// from the image of an older release (copy of zconfig.BaseOSDelta)
+type BaseOsDelta struct {
+	Patch        ContentTreeConfig
+	Format       zconfig.BaseOSDeltaFormat
+	BaseVersion  string
+	BaseSize     uint64
+	BaseSha256   string
+	TargetSha256 string
+}
+
+// BaseOsHealthGates : checks which have to pass in the testing window
+// after the device boots the BaseOs (copy of zconfig.BaseOSHealthGates)
+type BaseOsHealthGates struct {
+	AppInstances     []uuid.UUID
+	NetworkInstances []uuid.UUID
+	HTTPProbes       []BaseOsHTTPProbe
+	Scripts          []BaseOsScriptCheck
+}
+
+// Empty returns true if no health gates are defined
+func (gates BaseOsHealthGates) Empty() bool {
+	return len(gates.AppInstances) == 0 && len(gates.NetworkInstances) == 0 &&
+		len(gates.HTTPProbes) == 0 && len(gates.Scripts) == 0
+}
+
+// BaseOsHTTPProbe : HTTP(S) GET request sent from the network instance
+type BaseOsHTTPProbe struct {
+	NetworkInstance uuid.UUID
+	URL             string
+	ExpectedStatus  int // Any 2xx if zero
+}
+
+// BaseOsScriptCheck : command executed in the container of the app instance
+type BaseOsScriptCheck struct {
+	AppInstance uuid.UUID
+	Command     []string
+	Timeout     time.Duration // Default if zero
 }
 
 func (config BaseOsConfig) Key() string {
@@ -90,6 +136,15 @@ type BaseOsStatus struct {
 	PartitionLabel        string
 	PartitionDevice       string // From zboot
 	PartitionState        string // From zboot
+	// DeltaBaseVersion is set while the image is installed from
+	// the delta against that version
+	DeltaBaseVersion string
+	// DeltaFailed is set once installation from a delta failed;
+	// the full image is used from then on
+	DeltaFailed bool
+	// FailedHealthGate describes the health gate which failed in the
+	// testing window and caused the fallback from this BaseOs
+	FailedHealthGate string
 	// Mininum state across all steps/StorageStatus.
 	// Error* set implies error.
 	State SwState
@@ -198,6 +253,19 @@ type DatastoreConfig struct {
 	Dpath     string // depending on DsType, it could be bucket or path
 	Region    string
 	DsCertPEM [][]byte // cert chain used for the datastore
+	// Mirrors of the registry of an OCI datastore, tried in order
+	Mirrors []RegistryMirror
+
+	// CipherBlockStatus, for encrypted credentials
+	CipherBlockStatus
+}
+
+// RegistryMirror is a mirror of the OCI registry of a datastore, e.g. a
+// pull-through cache on the site
+type RegistryMirror struct {
+	Fqdn    string   // host[:port] of the mirror
+	Dpath   string   // prepended to the repository
+	CertPEM [][]byte // cert chain used for the mirror
 
 	// CipherBlockStatus, for encrypted credentials
 	CipherBlockStatus
@@ -433,6 +501,7 @@ type NodeAgentStatus struct {
 	RebootImage                string
 	LocalMaintenanceMode       bool                  //enter Maintenance Mode
 	LocalMaintenanceModeReason MaintenanceModeReason //reason for Maintenance Mode
+	FailedHealthGate           string                // From last reboot, if it was a fallback
 }
 
 // Key :
@@ -487,6 +556,9 @@ const (
 	ConfigGetFail
 	ConfigGetTemporaryFail
 	ConfigGetReadSaved
+	// ConfigGetLocalBundle : Controller is not reachable and the config
+	// is from an update bundle, which stands in for the controller
+	ConfigGetLocalBundle
 )
 
 //DeviceOperation is an operation on device
@@ -645,15 +717,23 @@ type LocalCommands struct {
 	AppCommands map[string]*LocalAppCommand // key: app UUID
 	// Counters for locally issued app commands.
 	AppCounters map[string]*LocalAppCounters // key: app UUID
+	// App configuration changed by locally issued app commands.
+	AppConfigs map[string]*LocalAppConfig // key: app UUID
 	// Local volume generation counters.
 	VolumeGenCounters map[string]int64 // key: volume UUID
+	// Locally issued volume commands.
+	// For every volume there is entry only for the last command.
+	VolumeCommands map[string]*LocalVolumeCommand // key: volume UUID
+	// Local volume snapshot counters.
+	VolumeSnapshotCounters map[string]uint32 // key: volume UUID
 }
 
 // Empty : returns true if there were no commands triggered locally
 // (for currently deployed apps and volumes).
 func (lc *LocalCommands) Empty() bool {
 	return len(lc.AppCommands) == 0 && len(lc.AppCounters) == 0 &&
-		len(lc.VolumeGenCounters) == 0
+		len(lc.AppConfigs) == 0 && len(lc.VolumeGenCounters) == 0 &&
+		len(lc.VolumeCommands) == 0 && len(lc.VolumeSnapshotCounters) == 0
 }
 
 // AppCommand : application command requested to run by a local server.
@@ -667,6 +747,14 @@ const (
 	AppCommandRestart
 	// AppCommandPurge : purge application with ALL of its volumes.
 	AppCommandPurge
+	// AppCommandStop : stop application until AppCommandStart.
+	AppCommandStop
+	// AppCommandStart : start application stopped by AppCommandStop.
+	AppCommandStart
+	// AppCommandSetEnv : set environment variables and restart application.
+	AppCommandSetEnv
+	// AppCommandConsoleLog : submit console output of application.
+	AppCommandConsoleLog
 	// TODO : purge for a single or a subset of volumes.
 )
 
@@ -683,6 +771,8 @@ type LocalAppCommand struct {
 	// LastCompletedTimestamp : (server) timestamp of the last command completed for this app.
 	// If Completed is true, then this happens to be the same as LocalServerTimestamp.
 	LastCompletedTimestamp uint64
+	// Error : why the command was rejected, reported to the local server.
+	Error string
 }
 
 // LocalAppCounters : counters for locally issued application commands.
@@ -695,6 +785,40 @@ type LocalAppCounters struct {
 	PurgeCmd AppInstanceOpsCmd
 }
 
+// LocalAppConfig : application configuration changed by locally issued commands.
+type LocalAppConfig struct {
+	// Stopped is set by AppCommandStop and cleared by AppCommandStart.
+	Stopped bool
+	// EnvVariables : environment variables set by AppCommandSetEnv.
+	EnvVariables map[string]string
+}
+
+// VolumeCommand : volume command requested to run by a local server.
+type VolumeCommand uint8
+
+// Integer values are in-sync with proto enum VolumeCommand_Command.
+const (
+	// VolumeCommandUnspecified : command was not specified (invalid input).
+	VolumeCommandUnspecified VolumeCommand = iota
+	// VolumeCommandSnapshot : take a snapshot of the volume.
+	VolumeCommandSnapshot
+)
+
+// LocalVolumeCommand : A volume command requested from a local server.
+type LocalVolumeCommand struct {
+	// Command to execute.
+	Command VolumeCommand
+	// LocalServerTimestamp : timestamp made by the local server when the request was created.
+	LocalServerTimestamp uint64
+	// SnapshotCounter : local snapshot counter of the volume requested by this command.
+	SnapshotCounter uint32
+	// Completed is set to true by zedagent once the command completes.
+	Completed bool
+	// LastCompletedTimestamp : (server) timestamp of the last command completed for this volume.
+	// If Completed is true, then this happens to be the same as LocalServerTimestamp.
+	LastCompletedTimestamp uint64
+}
+
 // DevCommand : application command requested to run by a local server.
 type DevCommand uint8
 
@@ -62,7 +62,9 @@ type AppInstanceConfig struct {
 	PurgeCmd            AppInstanceOpsCmd
 	LocalRestartCmd     AppInstanceOpsCmd
 	LocalPurgeCmd       AppInstanceOpsCmd
-	HasLocalServer      bool // Set if localServerAddr matches
+	LocalStopped        bool              // Stopped via local profile server
+	LocalEnvVariables   map[string]string // Set via local profile server
+	HasLocalServer      bool              // Set if localServerAddr matches
 	// XXX: to be deprecated, use CipherBlockStatus instead
 	CloudInitUserData *string `json:"pubsub-large-CloudInitUserData"`
 	RemoteConsole     bool
@@ -299,6 +301,9 @@ type AppAndImageToHash struct {
 	ImageID      uuid.UUID
 	Hash         string
 	PurgeCounter uint32
+	// SignatureVerified is set once the signature of the image with Hash
+	// is verified, hence it need not be verified again after a reboot
+	SignatureVerified bool
 }
 
 // Key is used for pubsub
@@ -442,6 +442,9 @@ const (
 	// DPCStateAsyncWait : waiting for some config operations to finalize which are
 	// running asynchronously in the background.
 	DPCStateAsyncWait
+	// DPCStateWwanWait : waiting for the wwan microservice to apply the next
+	// APN profile of a cellular port.
+	DPCStateWwanWait
 )
 
 // String returns the string name
@@ -465,6 +468,8 @@ func (status DPCState) String() string {
 		return "DPC_REMOTE_WAIT"
 	case DPCStateAsyncWait:
 		return "DPC_ASYNC_WAIT"
+	case DPCStateWwanWait:
+		return "DPC_WWAN_WAIT"
 	default:
 		return fmt.Sprintf("Unknown status %d", status)
 	}
@@ -484,6 +489,9 @@ type DevicePortConfig struct {
 	LastIPAndDNS time.Time // Time when we got some IP addresses and DNS
 
 	Ports []NetworkPortConfig
+	// NTPServers : statically configured NTP servers (hostnames or IP addresses).
+	// These are preferred over NTP servers received from DHCP.
+	NTPServers []string
 }
 
 // PubKey is used for pubsub. Key string plus TimePriority
@@ -826,6 +834,9 @@ func (config *DevicePortConfig) MostlyEqual(config2 *DevicePortConfig) bool {
 	if config.Key != config2.Key {
 		return false
 	}
+	if !equalStringLists(config.NTPServers, config2.NTPServers) {
+		return false
+	}
 	if len(config.Ports) != len(config2.Ports) {
 		return false
 	}
@@ -841,7 +852,9 @@ func (config *DevicePortConfig) MostlyEqual(config2 *DevicePortConfig) bool {
 		}
 		if !reflect.DeepEqual(p1.DhcpConfig, p2.DhcpConfig) ||
 			!reflect.DeepEqual(p1.ProxyConfig, p2.ProxyConfig) ||
-			!reflect.DeepEqual(p1.WirelessCfg, p2.WirelessCfg) {
+			!reflect.DeepEqual(p1.WirelessCfg, p2.WirelessCfg) ||
+			!reflect.DeepEqual(p1.IPRoutingConfig, p2.IPRoutingConfig) ||
+			!reflect.DeepEqual(p1.Dot1x, p2.Dot1x) {
 			return false
 		}
 	}
@@ -952,10 +965,12 @@ type ProxyConfig struct {
 	Exceptions string
 	Pacfile    string
 	// If Enable is set we use WPAD. If the URL is not set we try
-	// the various DNS suffixes until we can download a wpad.dat file
+	// the URL received from DHCP (option 252) and then the various
+	// DNS suffixes until we can download a wpad.dat file
 	NetworkProxyEnable bool   // Enable WPAD
 	NetworkProxyURL    string // Complete URL i.e., with /wpad.dat
-	WpadURL            string // The URL determined from DNS
+	DhcpWpadURL        string // The URL received from DHCP
+	WpadURL            string // The URL determined from DHCP or DNS
 	// List of certs which will be added to TLS trust
 	ProxyCertPEM [][]byte `json:"pubsub-large-ProxyCertPEM"`
 }
@@ -987,18 +1002,31 @@ type WifiConfig struct {
 }
 
 // CellConfig - Cellular part of the configure
+// Multiple entries in WirelessConfig.Cellular are APN profiles of the same
+// modem, tried in the given order. ProbeAddr, DisableProbe and LocationTracking
+// are taken from the first entry.
 type CellConfig struct {
 	APN          string // LTE APN
 	ProbeAddr    string
 	DisableProbe bool
 	// Enable to get location info from the GNSS receiver of the LTE modem.
 	LocationTracking bool
+	// SIM slot to use with this APN profile, numbered from 1.
+	// Zero means the slot which is currently selected in the modem.
+	SimSlot      uint8
+	IPType       WwanIPType
+	AuthProtocol WwanAuthProtocol
+	// Do not use this APN profile while the modem is roaming.
+	ForbidRoaming bool
+
+	// CipherBlockStatus, for encrypted APN credentials
+	CipherBlockStatus
 }
 
 // WirelessConfig - wireless structure
 type WirelessConfig struct {
 	WType    WirelessType // Wireless Type
-	Cellular []CellConfig // LTE APN
+	Cellular []CellConfig // LTE APN profiles
 	Wifi     []WifiConfig // Wifi Config params
 }
 
@@ -1132,6 +1160,10 @@ type NetworkPortConfig struct {
 	ProxyConfig
 	L2LinkConfig
 	WirelessCfg WirelessConfig
+	// User-defined static routes and policy routing rules
+	IPRoutingConfig
+	// 802.1X authentication of a wired port
+	Dot1x Dot1xConfig
 	// TestResults - Errors from parsing plus success/failure from testing
 	TestResults
 }
@@ -1157,8 +1189,15 @@ type NetworkPortStatus struct {
 	DefaultRouters []net.IP
 	WirelessCfg    WirelessConfig
 	WirelessStatus WirelessStatus
+	// Dot1xStatus : state of 802.1X authentication of a wired port.
+	Dot1xStatus Dot1xStatus
+	// Neighbors : devices connected to the (physical) port as discovered
+	// using LLDP and CDP.
+	Neighbors []PortNeighbor
 	ProxyConfig
 	L2LinkConfig
+	// Quality is the estimated link quality of the port.
+	Quality PortQuality
 	// TestResults provides recording of failure and success
 	TestResults
 }
@@ -1196,6 +1235,8 @@ type DeviceNetworkStatus struct {
 	CurrentIndex int                     // For logs
 	RadioSilence RadioSilence            // The actual state of the radio-silence mode
 	Ports        []NetworkPortStatus
+	// NTPServers : statically configured NTP servers (from DevicePortConfig).
+	NTPServers []string
 }
 
 // Key is used for pubsub
@@ -1366,13 +1407,18 @@ func (status DeviceNetworkStatus) MostlyEqual(status2 DeviceNetworkStatus) bool
 		}
 
 		if !reflect.DeepEqual(p1.ProxyConfig, p2.ProxyConfig) ||
-			!reflect.DeepEqual(p1.WirelessStatus, p2.WirelessStatus) {
+			!reflect.DeepEqual(p1.WirelessStatus, p2.WirelessStatus) ||
+			p1.Dot1xStatus != p2.Dot1xStatus ||
+			!EqualPortNeighbors(p1.Neighbors, p2.Neighbors) {
 			return false
 		}
 	}
 	if !reflect.DeepEqual(status.RadioSilence, status2.RadioSilence) {
 		return false
 	}
+	if !equalStringLists(status.NTPServers, status2.NTPServers) {
+		return false
+	}
 	return true
 }
 
@@ -1452,28 +1498,31 @@ func rotate(arr []string, amount int) []string {
 }
 
 // GetMgmtPortsSortedCost returns all management ports sorted by port cost
-// rotation causes rotation/round-robin within each cost
+// and then by the estimated link quality.
+// rotation causes rotation/round-robin within each cost and quality band
 func GetMgmtPortsSortedCost(globalStatus DeviceNetworkStatus, rotation int) []string {
 	return getMgmtPortsSortedCostImpl(globalStatus, rotation,
 		PortCostMax, false)
 }
 
 // GetMgmtPortsSortedCostWithoutFailed returns all management ports sorted by
-// port cost ignoring ports with failures.
-// rotation causes rotation/round-robin within each cost
+// port cost and then by the estimated link quality, ignoring ports with failures.
+// rotation causes rotation/round-robin within each cost and quality band
 func GetMgmtPortsSortedCostWithoutFailed(globalStatus DeviceNetworkStatus, rotation int) []string {
 	return getMgmtPortsSortedCostImpl(globalStatus, rotation,
 		PortCostMax, true)
 }
 
 // getMgmtPortsSortedCostImpl returns all management ports sorted by port cost
-// up to and including the maxCost
+// up to and including the maxCost. Ports with the same cost are sorted
+// by the estimated link quality.
 func getMgmtPortsSortedCostImpl(globalStatus DeviceNetworkStatus, rotation int, maxCost uint8, dropFailed bool) []string {
 	ifnameList := []string{}
 	costList := getPortCostListImpl(globalStatus, maxCost)
 	for _, cost := range costList {
-		ifnameList = append(ifnameList,
-			getMgmtPortsImpl(globalStatus, rotation, true, cost, dropFailed)...)
+		ports := getMgmtPortsImpl(globalStatus, rotation, true, cost, dropFailed)
+		sortPortsByQuality(globalStatus, ports)
+		ifnameList = append(ifnameList, ports...)
 	}
 	return ifnameList
 }
@@ -2115,6 +2164,8 @@ type NetworkXObjectConfig struct {
 	DnsNameToIPList []DnsNameToIP // Used for DNS and ACL ipset
 	Proxy           *ProxyConfig
 	WirelessCfg     WirelessConfig
+	IPRouting       IPRoutingConfig
+	Dot1x           Dot1xConfig
 	// Any errrors from the parser
 	// ErrorAndTime provides SetErrorNow() and ClearError()
 	ErrorAndTime
@@ -2235,6 +2286,10 @@ type NetworkInstanceInfo struct {
 
 	// IP address on which the meta-data server listens
 	MetaDataServerIP string
+
+	// Uplink ports across which the traffic is currently load-balanced
+	// (see NetworkInstanceConfig.UplinkLoadBalancing).
+	LoadBalancedUplinks []string
 }
 
 func (instanceInfo *NetworkInstanceInfo) IsVifInBridge(
@@ -2507,6 +2562,12 @@ type NetworkInstanceConfig struct {
 	DhcpRange       IpRange
 	DnsNameToIPList []DnsNameToIP // Used for DNS and ACL ipset
 
+	// Load-balancing of app traffic across multiple uplinks
+	UplinkLoadBalancing UplinkLoadBalancing
+
+	// Wireless access point bridged into the (local) network instance
+	AccessPoint WirelessAccessPoint
+
 	// For other network services - Proxy / StrongSwan etc..
 	OpaqueConfig string
 
@@ -2593,6 +2654,11 @@ type NetworkInstanceStatus struct {
 
 	Server4Running bool // Did we start the server?
 
+	// AccessPointIfName : interface name of the wireless adapter running
+	// as an access point (with hostapd) for the network instance.
+	// Empty if the access point is not running.
+	AccessPointIfName string
+
 	NetworkInstanceInfo
 
 	OpaqueStatus string
@@ -3300,6 +3366,11 @@ type WwanConfig struct {
 	Networks     []WwanNetworkConfig `json:"networks"`
 }
 
+// Key is used for pubsub
+func (wc WwanConfig) Key() string {
+	return "global"
+}
+
 // Equal compares two instances of WwanConfig for equality.
 func (wc WwanConfig) Equal(wc2 WwanConfig) bool {
 	if wc.RadioSilence != wc2.RadioSilence {
@@ -3328,18 +3399,71 @@ type WwanNetworkConfig struct {
 	// Logical label in PhysicalIO.
 	LogicalLabel string        `json:"logical-label"`
 	PhysAddrs    WwanPhysAddrs `json:"physical-addrs"`
-	// XXX Multiple APNs are not yet supported.
-	Apns  []string  `json:"apns"`
-	Probe WwanProbe `json:"probe"`
+	// APN profiles are tried in the given order (starting with StartProfile
+	// and wrapping around) until a connection is established.
+	ApnProfiles []WwanApnProfile `json:"apn-profiles"`
+	// Index of the APN profile to try first.
+	// Increased by nim to fail over to the next profile when the controller
+	// is not reachable using the current one.
+	StartProfile int       `json:"start-profile"`
+	Probe        WwanProbe `json:"probe"`
 	// Some LTE modems have GNSS receiver integrated and can be used
 	// for device location tracking.
 	// Enable this option to have location info periodically obtained
-	// from this modem and published into /run/wwan/location.json by the wwan
+	// from this modem and published as WwanLocationInfo by the wwan
 	// microservice. This is further distributed to the controller and
 	// to applications by zedagent.
 	LocationTracking bool `json:"location-tracking"`
 }
 
+// WwanApnProfile : settings used to establish a data connection.
+type WwanApnProfile struct {
+	APN string `json:"apn"`
+	// SIM slot to use, numbered from 1.
+	// Zero means the slot which is currently selected in the modem.
+	SimSlot       uint8            `json:"sim-slot"`
+	IPType        WwanIPType       `json:"ip-type"`
+	AuthProtocol  WwanAuthProtocol `json:"auth-protocol"`
+	Username      string           `json:"username,omitempty"`
+	Password      string           `json:"password,omitempty"`
+	ForbidRoaming bool             `json:"forbid-roaming"`
+}
+
+// String describes the APN profile without revealing the password.
+func (p WwanApnProfile) String() string {
+	return fmt.Sprintf("APN=%s, SIM slot=%d, IP type=%s, auth=%s, user=%s, "+
+		"forbid roaming=%t", p.APN, p.SimSlot, p.IPType, p.AuthProtocol,
+		p.Username, p.ForbidRoaming)
+}
+
+// WwanIPType : type of IP addressing requested for a cellular connection.
+type WwanIPType string
+
+const (
+	// WwanIPTypeUnspecified : use the default of the modem
+	WwanIPTypeUnspecified WwanIPType = ""
+	// WwanIPTypeIPv4 : request IPv4 address only
+	WwanIPTypeIPv4 WwanIPType = "ipv4"
+	// WwanIPTypeIPv6 : request IPv6 address only
+	WwanIPTypeIPv6 WwanIPType = "ipv6"
+	// WwanIPTypeIPv4AndIPv6 : request dual-stack connection
+	WwanIPTypeIPv4AndIPv6 WwanIPType = "ipv4v6"
+)
+
+// WwanAuthProtocol : authentication protocol used to connect to an APN.
+type WwanAuthProtocol string
+
+const (
+	// WwanAuthProtocolNone : no authentication
+	WwanAuthProtocolNone WwanAuthProtocol = ""
+	// WwanAuthProtocolPAP : Password Authentication Protocol
+	WwanAuthProtocolPAP WwanAuthProtocol = "pap"
+	// WwanAuthProtocolCHAP : Challenge-Handshake Authentication Protocol
+	WwanAuthProtocolCHAP WwanAuthProtocol = "chap"
+	// WwanAuthProtocolPAPAndCHAP : either PAP or CHAP
+	WwanAuthProtocolPAPAndCHAP WwanAuthProtocol = "pap-and-chap"
+)
+
 // WwanProbe : cellular connectivity verification probe.
 type WwanProbe struct {
 	Disable bool `json:"disable"`
@@ -3359,21 +3483,16 @@ func (wnc WwanNetworkConfig) Equal(wnc2 WwanNetworkConfig) bool {
 		wnc.Probe.Disable != wnc2.Probe.Disable {
 		return false
 	}
-	if wnc.LocationTracking != wnc2.LocationTracking {
+	if wnc.LocationTracking != wnc2.LocationTracking ||
+		wnc.StartProfile != wnc2.StartProfile {
 		return false
 	}
-	if len(wnc.Apns) != len(wnc2.Apns) {
+	// The order of APN profiles matters.
+	if len(wnc.ApnProfiles) != len(wnc2.ApnProfiles) {
 		return false
 	}
-	for _, apn1 := range wnc.Apns {
-		var found bool
-		for _, apn2 := range wnc2.Apns {
-			if apn1 == apn2 {
-				found = true
-				break
-			}
-		}
-		if !found {
+	for i := range wnc.ApnProfiles {
+		if wnc.ApnProfiles[i] != wnc2.ApnProfiles[i] {
 			return false
 		}
 	}
@@ -3398,10 +3517,16 @@ type WwanPhysAddrs struct {
 // WwanStatus is published by the wwan service and consumed by nim.
 type WwanStatus struct {
 	Networks []WwanNetworkStatus `json:"networks"`
-	// MD5 checksum of the corresponding WwanConfig (as config.json).
+	// SHA256 checksum of the corresponding WwanConfig
+	// (see genericitems.MarshalWwanConfig).
 	ConfigChecksum string `json:"config-checksum,omitempty"`
 }
 
+// Key is used for pubsub
+func (ws WwanStatus) Key() string {
+	return "global"
+}
+
 // LookupNetworkStatus returns status corresponding to the given cellular network.
 func (ws WwanStatus) LookupNetworkStatus(logicalLabel string) (WwanNetworkStatus, bool) {
 	for _, status := range ws.Networks {
@@ -3459,6 +3584,11 @@ type WwanNetworkStatus struct {
 	ConfigError  string         `json:"config-error"`
 	ProbeError   string         `json:"probe-error"`
 	Providers    []WwanProvider `json:"providers"`
+	// APN profile of the established data connection (without the password)
+	// and its index in WwanNetworkConfig.ApnProfiles.
+	// ActiveProfile.APN is empty if the modem is not connected.
+	ActiveProfile    WwanApnProfile `json:"active-profile"`
+	ActiveProfileIdx int            `json:"active-profile-index"`
 }
 
 // WwanCellModule contains cellular module specs.
@@ -519,6 +519,51 @@ func TestGetMgmtPortsSortedCost(t *testing.T) {
 			rotate:        1,
 			expectedValue: []string{"port4", "port2", "port3", "port1"},
 		},
+		"Test quality": {
+			deviceNetworkStatus: DeviceNetworkStatus{
+				Version: DPCIsMgmt,
+				Ports: []NetworkPortStatus{
+					{IfName: "port1",
+						IsMgmt:  true,
+						Cost:    0,
+						Quality: PortQuality{Score: 30, Samples: 10}},
+					{IfName: "port2",
+						IsMgmt: true,
+						Cost:   0},
+					{IfName: "port3",
+						IsMgmt:  true,
+						Cost:    0,
+						Quality: PortQuality{Score: 95, Samples: 10}},
+					{IfName: "port4",
+						IsMgmt:  true,
+						Cost:    1,
+						Quality: PortQuality{Score: 100, Samples: 10}},
+				},
+			},
+			expectedValue: []string{"port3", "port2", "port1", "port4"},
+		},
+		"Test quality rotate": {
+			deviceNetworkStatus: DeviceNetworkStatus{
+				Version: DPCIsMgmt,
+				Ports: []NetworkPortStatus{
+					{IfName: "port1",
+						IsMgmt:  true,
+						Cost:    0,
+						Quality: PortQuality{Score: 85, Samples: 10}},
+					{IfName: "port2",
+						IsMgmt:  true,
+						Cost:    0,
+						Quality: PortQuality{Score: 10, Samples: 10}},
+					{IfName: "port3",
+						IsMgmt:  true,
+						Cost:    0,
+						Quality: PortQuality{Score: 92, Samples: 10}},
+				},
+			},
+			// port1 and port3 are in the same quality band.
+			rotate:        2,
+			expectedValue: []string{"port3", "port1", "port2"},
+		},
 		"Test duplicates some management": {
 			deviceNetworkStatus: DeviceNetworkStatus{
 				Version: DPCIsMgmt,
@@ -6,10 +6,13 @@ package utils
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/lf-edge/eve/pkg/pillar/base"
 	"github.com/lf-edge/eve/pkg/pillar/diskmetrics"
+	"github.com/lf-edge/eve/pkg/pillar/types"
 	"github.com/lf-edge/eve/pkg/pillar/zfs"
 )
 
@@ -27,6 +30,16 @@ func GetVolumeSize(log *base.LogObject, name string) (uint64, uint64, string, bo
 		size, err := diskmetrics.SizeFromDir(log, name)
 		return size, size, "CONTAINER", false, err
 	}
+	if info.Mode()&os.ModeDevice != 0 && !strings.HasPrefix(name, types.ZVolDevicePrefix) {
+		//e.g. dm-crypt device of volume with isolated key
+		size, err := getBlockDeviceSize(name)
+		if err != nil {
+			errStr := fmt.Sprintf("GetVolumeSize/getBlockDeviceSize failed for %s: %v",
+				name, err)
+			return 0, 0, "", false, errors.New(errStr)
+		}
+		return size, size, "raw", false, nil
+	}
 	if info.Mode()&os.ModeDevice != 0 {
 		//Assume this is zfs device
 		imgInfo, err := zfs.GetZFSVolumeInfo(log, name)
@@ -47,3 +60,17 @@ func GetVolumeSize(log *base.LogObject, name string) (uint64, uint64, string, bo
 	return imgInfo.ActualSize, imgInfo.VirtualSize, imgInfo.Format,
 		imgInfo.DirtyFlag, nil
 }
+
+//getBlockDeviceSize returns size of block device in bytes
+func getBlockDeviceSize(name string) (uint64, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(size), nil
+}
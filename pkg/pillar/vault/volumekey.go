@@ -0,0 +1,338 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	etpm "github.com/lf-edge/eve/pkg/pillar/evetpm"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	fileutils "github.com/lf-edge/eve/pkg/pillar/utils/file"
+)
+
+//volumeKeyLen is the length of raw keys of volumes, as used by zfs and cryptsetup
+const volumeKeyLen = 32
+
+//recoveryKeyLabel is used to derive the key wrapping recovery copies
+//of app keys from the vault key
+const recoveryKeyLabel = "eve-volume-key-recovery"
+
+var (
+	//VolumeKeysDir keeps keys of app instances, which are used by volumes
+	//with isolated key. It is inside of the default vault, so the keys
+	//are not available unless the vault is unlocked.
+	//It is not a constant due to test usage
+	VolumeKeysDir = types.SealedDirName + "/volume-keys"
+
+	//VolumeKeysStageDir is where keys are staged for zfs and cryptsetup,
+	//it must be on tmpfs. It is not a constant due to test usage
+	VolumeKeysStageDir = "/run/volume-keys"
+
+	//isTpmEnabled, sealVolumeKey, unsealVolumeKey, getVaultKey and
+	//getRandomVolumeKey are not functions due to test usage
+	isTpmEnabled  = etpm.IsTpmEnabled
+	sealVolumeKey = func(key []byte) ([]byte, error) {
+		return etpm.SealKey(key, etpm.DiskKeySealingPCRs)
+	}
+	unsealVolumeKey = func(sealed []byte) ([]byte, error) {
+		return etpm.UnsealKey(sealed, etpm.DiskKeySealingPCRs)
+	}
+	getVaultKey = func() ([]byte, error) {
+		return etpm.UnsealDiskKey(etpm.DiskKeySealingPCRs)
+	}
+	getRandomVolumeKey = func() ([]byte, error) { return etpm.GetRandom(volumeKeyLen) }
+)
+
+//appKeyName returns name of the file with the key of the app instance,
+//sealed under the vault PCR policy
+func appKeyName(appInstance string) string {
+	return appInstance + ".key"
+}
+
+//appRecoveryKeyName returns name of the file with the key of the app
+//instance wrapped with the vault key, which is used to reseal the key
+//once PCRs change, e.g. after update of EVE
+func appRecoveryKeyName(appInstance string) string {
+	return appInstance + ".recovery"
+}
+
+//volumeAppName returns name of the file with the app instance, key of which
+//is used by the volume with volumeKey, which is VolumeStatus.Key()
+func volumeAppName(volumeKey string) string {
+	return volumeKey + ".app"
+}
+
+//CreateVolumeKey returns the key of appInstance for the volume with
+//volumeKey, which is VolumeStatus.Key(), and records that the volume uses it.
+//The key of the app instance is generated and sealed using TPM if it does
+//not exist yet, so all volumes of the app instance share it.
+func CreateVolumeKey(volumeKey, appInstance string) ([]byte, error) {
+	if !isTpmEnabled() {
+		return nil, fmt.Errorf("TPM is required for volumes with isolated key")
+	}
+	if appInstance == "" {
+		return nil, fmt.Errorf("volume %s with isolated key is not used by any app instance",
+			volumeKey)
+	}
+	if err := os.MkdirAll(VolumeKeysDir, 0700); err != nil {
+		return nil, err
+	}
+	key, err := getAppKey(appInstance)
+	if os.IsNotExist(err) {
+		key, err = createAppKey(appInstance)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key of %s for %s: %v",
+			appInstance, volumeKey, err)
+	}
+	appFile := filepath.Join(VolumeKeysDir, volumeAppName(volumeKey))
+	if err := fileutils.WriteRename(appFile, []byte(appInstance)); err != nil {
+		return nil, fmt.Errorf("failed to save app instance of %s: %v", volumeKey, err)
+	}
+	return key, nil
+}
+
+//GetVolumeKey returns the key of the volume with volumeKey
+func GetVolumeKey(volumeKey string) ([]byte, error) {
+	appInstance, err := volumeAppInstance(volumeKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read app instance of %s: %v", volumeKey, err)
+	}
+	key, err := getAppKey(appInstance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key of %s for %s: %v",
+			appInstance, volumeKey, err)
+	}
+	return key, nil
+}
+
+//DestroyVolumeKey removes the record that the volume with volumeKey uses
+//the key of its app instance, and removes the key of the app instance
+//once no volume uses it. After that, data encrypted with the key cannot
+//be decrypted on this device: the sealed key can only be unsealed by
+//this TPM, and the recovery copy only with the vault key.
+//Note that removing the files does not guarantee that their content is
+//erased from the storage.
+//It is not an error if the volume does not use any key.
+func DestroyVolumeKey(volumeKey string) error {
+	appInstance, err := volumeAppInstance(volumeKey)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read app instance of %s: %v", volumeKey, err)
+	}
+	appFile := filepath.Join(VolumeKeysDir, volumeAppName(volumeKey))
+	if err := os.Remove(appFile); err != nil {
+		return fmt.Errorf("failed to remove app instance of %s: %v", volumeKey, err)
+	}
+	inUse, err := isAppKeyInUse(appInstance)
+	if err != nil || inUse {
+		return err
+	}
+	for _, name := range []string{appKeyName(appInstance), appRecoveryKeyName(appInstance)} {
+		err := os.Remove(filepath.Join(VolumeKeysDir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to destroy key of %s: %v", appInstance, err)
+		}
+	}
+	return nil
+}
+
+//volumeAppInstance returns the app instance, key of which is used
+//by the volume with volumeKey
+func volumeAppInstance(volumeKey string) (string, error) {
+	appInstance, err := ioutil.ReadFile(filepath.Join(VolumeKeysDir, volumeAppName(volumeKey)))
+	if err != nil {
+		return "", err
+	}
+	return string(appInstance), nil
+}
+
+//isAppKeyInUse returns true if any volume uses the key of appInstance
+func isAppKeyInUse(appInstance string) (bool, error) {
+	files, err := ioutil.ReadDir(VolumeKeysDir)
+	if err != nil {
+		return false, err
+	}
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".app") {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(VolumeKeysDir, file.Name()))
+		if err != nil {
+			return false, err
+		}
+		if string(content) == appInstance {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+//createAppKey generates a new key of appInstance and saves it sealed,
+//and wrapped with the vault key for recovery
+func createAppKey(appInstance string) ([]byte, error) {
+	key, err := getRandomVolumeKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %v", err)
+	}
+	if err := saveRecoveryKey(appInstance, key); err != nil {
+		return nil, err
+	}
+	if err := saveSealedKey(appInstance, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+//getAppKey unseals the key of appInstance. If it cannot be unsealed,
+//e.g. because PCRs changed after update of EVE, the key is recovered using
+//the vault key, which vaultmgr reseals once the controller releases it,
+//and sealed again under the current PCRs.
+//It returns an error satisfying os.IsNotExist if there is no key.
+func getAppKey(appInstance string) ([]byte, error) {
+	sealed, err := ioutil.ReadFile(filepath.Join(VolumeKeysDir, appKeyName(appInstance)))
+	if err != nil {
+		return nil, err
+	}
+	key, unsealErr := unsealVolumeKey(sealed)
+	if unsealErr == nil {
+		//rewrap the recovery copy if the vault key changed, it is
+		//tried again on the next load if the vault key is not available
+		if _, err := readRecoveryKey(appInstance); err != nil {
+			_ = saveRecoveryKey(appInstance, key)
+		}
+		return key, nil
+	}
+	key, err = readRecoveryKey(appInstance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unseal key: %v, and to recover it: %v",
+			unsealErr, err)
+	}
+	if err := saveSealedKey(appInstance, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func saveSealedKey(appInstance string, key []byte) error {
+	sealed, err := sealVolumeKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to seal key: %v", err)
+	}
+	keyFile := filepath.Join(VolumeKeysDir, appKeyName(appInstance))
+	if err := fileutils.WriteRename(keyFile, sealed); err != nil {
+		return fmt.Errorf("failed to save sealed key: %v", err)
+	}
+	return nil
+}
+
+//recoveryCipher returns AEAD with the key derived from the vault key
+func recoveryCipher() (cipher.AEAD, error) {
+	vaultKey, err := getVaultKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vault key: %v", err)
+	}
+	mac := hmac.New(sha256.New, vaultKey)
+	mac.Write([]byte(recoveryKeyLabel))
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func saveRecoveryKey(appInstance string, key []byte) error {
+	aead, err := recoveryCipher()
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	wrapped := aead.Seal(nonce, nonce, key, []byte(appInstance))
+	keyFile := filepath.Join(VolumeKeysDir, appRecoveryKeyName(appInstance))
+	if err := fileutils.WriteRename(keyFile, wrapped); err != nil {
+		return fmt.Errorf("failed to save recovery key: %v", err)
+	}
+	return nil
+}
+
+func readRecoveryKey(appInstance string) ([]byte, error) {
+	wrapped, err := ioutil.ReadFile(filepath.Join(VolumeKeysDir, appRecoveryKeyName(appInstance)))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := recoveryCipher()
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, fmt.Errorf("malformed recovery key")
+	}
+	nonce, wrapped := wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():]
+	return aead.Open(nil, nonce, wrapped, []byte(appInstance))
+}
+
+//StageVolumeKey writes key of the volume with volumeKey into a file
+//in VolumeKeysStageDir, to pass it to zfs or cryptsetup, and returns
+//the file name. Call UnstageVolumeKey once the key is used.
+func StageVolumeKey(volumeKey string, key []byte) (string, error) {
+	if err := os.MkdirAll(VolumeKeysStageDir, 0700); err != nil {
+		return "", err
+	}
+	keyFile := filepath.Join(VolumeKeysStageDir, volumeKeyName(volumeKey))
+	if err := ioutil.WriteFile(keyFile, key, 0600); err != nil {
+		return "", fmt.Errorf("failed to stage key for %s: %v", volumeKey, err)
+	}
+	return keyFile, nil
+}
+
+//volumeKeyName returns name of the staged key file for the volume with
+//volumeKey, which is VolumeStatus.Key()
+func volumeKeyName(volumeKey string) string {
+	return volumeKey + ".key"
+}
+
+//UnstageVolumeKey overwrites and removes keyFile returned by StageVolumeKey
+func UnstageVolumeKey(keyFile string) error {
+	return clearFile(keyFile)
+}
+
+//clearFile overwrites filename with zeroes, and removes it.
+//It is meant for files on tmpfs, there is no guarantee that the content
+//is erased from other storage.
+func clearFile(filename string) error {
+	info, err := os.Stat(filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filename, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(make([]byte, info.Size()))
+	if err == nil {
+		err = f.Sync()
+	}
+	f.Close()
+	if err != nil {
+		return err
+	}
+	return os.Remove(filename)
+}
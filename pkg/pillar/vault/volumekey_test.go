@@ -0,0 +1,230 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package vault
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	testApp1 = "e1b8b0a1-6a3a-4c2a-9d0c-1b3f5e0c9a11"
+	testApp2 = "0a4f3c1e-58b2-4d7e-a1f0-6c2d9e8b7a55"
+)
+
+//fakeTpm stands in for sealing under PCRs and for the sealed vault key
+type fakeTpm struct {
+	pcr      byte
+	vaultKey []byte
+}
+
+//seal prepends the PCR value and xors the key with it
+func (tpm *fakeTpm) seal(key []byte) ([]byte, error) {
+	sealed := []byte{tpm.pcr}
+	for _, b := range key {
+		sealed = append(sealed, b^0x5a^tpm.pcr)
+	}
+	return sealed, nil
+}
+
+func (tpm *fakeTpm) unseal(sealed []byte) ([]byte, error) {
+	if len(sealed) == 0 || sealed[0] != tpm.pcr {
+		return nil, errors.New("PCR policy check failed")
+	}
+	key := make([]byte, 0, len(sealed)-1)
+	for _, b := range sealed[1:] {
+		key = append(key, b^0x5a^tpm.pcr)
+	}
+	return key, nil
+}
+
+func setupVolumeKeys(t *testing.T) *fakeTpm {
+	t.Helper()
+	dir := t.TempDir()
+	oldKeysDir, oldStageDir := VolumeKeysDir, VolumeKeysStageDir
+	oldTpmEnabled, oldRandom := isTpmEnabled, getRandomVolumeKey
+	oldSeal, oldUnseal, oldVaultKey := sealVolumeKey, unsealVolumeKey, getVaultKey
+	t.Cleanup(func() {
+		VolumeKeysDir, VolumeKeysStageDir = oldKeysDir, oldStageDir
+		isTpmEnabled, getRandomVolumeKey = oldTpmEnabled, oldRandom
+		sealVolumeKey, unsealVolumeKey, getVaultKey = oldSeal, oldUnseal, oldVaultKey
+	})
+	VolumeKeysDir = filepath.Join(dir, "volume-keys")
+	VolumeKeysStageDir = filepath.Join(dir, "stage")
+	isTpmEnabled = func() bool { return true }
+	tpm := &fakeTpm{pcr: 1, vaultKey: []byte("vault key")}
+	sealVolumeKey = tpm.seal
+	unsealVolumeKey = tpm.unseal
+	getVaultKey = func() ([]byte, error) { return tpm.vaultKey, nil }
+	counter := byte(0)
+	getRandomVolumeKey = func() ([]byte, error) {
+		counter++
+		return bytes.Repeat([]byte{counter}, volumeKeyLen), nil
+	}
+	return tpm
+}
+
+func TestVolumeKeyLifecycle(t *testing.T) {
+	setupVolumeKeys(t)
+	volume1 := testApp1 + "-volume1#0"
+	volume2 := testApp1 + "-volume2#0"
+	volume3 := testApp2 + "-volume3#0"
+
+	key, err := CreateVolumeKey(volume1, testApp1)
+	if err != nil {
+		t.Fatalf("CreateVolumeKey failed: %v", err)
+	}
+	//the key must not be kept in clear
+	for _, name := range []string{appKeyName(testApp1), appRecoveryKeyName(testApp1)} {
+		saved, err := ioutil.ReadFile(filepath.Join(VolumeKeysDir, name))
+		if err != nil {
+			t.Fatalf("ReadFile failed: %v", err)
+		}
+		if bytes.Contains(saved, key) {
+			t.Errorf("Volume key is saved in clear in %s", name)
+		}
+	}
+	got, err := GetVolumeKey(volume1)
+	if err != nil {
+		t.Fatalf("GetVolumeKey failed: %v", err)
+	}
+	if !bytes.Equal(got, key) {
+		t.Errorf("want %x, but got %x", key, got)
+	}
+
+	//volumes of the same app instance share its key
+	got, err = CreateVolumeKey(volume2, testApp1)
+	if err != nil {
+		t.Fatalf("CreateVolumeKey failed: %v", err)
+	}
+	if !bytes.Equal(got, key) {
+		t.Errorf("want %x, but got %x", key, got)
+	}
+	//other app instances get their own key
+	otherKey, err := CreateVolumeKey(volume3, testApp2)
+	if err != nil {
+		t.Fatalf("CreateVolumeKey failed: %v", err)
+	}
+	if bytes.Equal(otherKey, key) {
+		t.Errorf("App instances share the key")
+	}
+
+	keyFile, err := StageVolumeKey(volume1, key)
+	if err != nil {
+		t.Fatalf("StageVolumeKey failed: %v", err)
+	}
+	staged, err := ioutil.ReadFile(keyFile)
+	if err != nil || !bytes.Equal(staged, key) {
+		t.Errorf("Unexpected staged key %x, error %v", staged, err)
+	}
+	if err := UnstageVolumeKey(keyFile); err != nil {
+		t.Fatalf("UnstageVolumeKey failed: %v", err)
+	}
+	if _, err := os.Stat(keyFile); !os.IsNotExist(err) {
+		t.Errorf("Staged key is not removed: %v", err)
+	}
+
+	//the key of the app instance is kept while any volume uses it
+	if err := DestroyVolumeKey(volume1); err != nil {
+		t.Fatalf("DestroyVolumeKey failed: %v", err)
+	}
+	if _, err := GetVolumeKey(volume1); err == nil {
+		t.Errorf("GetVolumeKey succeeded after DestroyVolumeKey")
+	}
+	got, err = GetVolumeKey(volume2)
+	if err != nil || !bytes.Equal(got, key) {
+		t.Errorf("Unexpected key %x of the other volume, error %v", got, err)
+	}
+	if err := DestroyVolumeKey(volume2); err != nil {
+		t.Fatalf("DestroyVolumeKey failed: %v", err)
+	}
+	for _, name := range []string{appKeyName(testApp1), appRecoveryKeyName(testApp1)} {
+		if _, err := os.Stat(filepath.Join(VolumeKeysDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s is not removed with the last volume: %v", name, err)
+		}
+	}
+	if _, err := GetVolumeKey(volume3); err != nil {
+		t.Errorf("GetVolumeKey failed for other app instance: %v", err)
+	}
+	//destroying it again is not an error
+	if err := DestroyVolumeKey(volume2); err != nil {
+		t.Errorf("DestroyVolumeKey failed for destroyed key: %v", err)
+	}
+	//a new volume of the app instance gets a new key
+	newKey, err := CreateVolumeKey(volume1, testApp1)
+	if err != nil {
+		t.Fatalf("CreateVolumeKey failed: %v", err)
+	}
+	if bytes.Equal(newKey, key) {
+		t.Errorf("Destroyed key is reused")
+	}
+}
+
+func TestVolumeKeyRecovery(t *testing.T) {
+	tpm := setupVolumeKeys(t)
+	volume := testApp1 + "-volume#0"
+
+	key, err := CreateVolumeKey(volume, testApp1)
+	if err != nil {
+		t.Fatalf("CreateVolumeKey failed: %v", err)
+	}
+
+	//PCRs changed, e.g. after update of EVE
+	tpm.pcr = 2
+	got, err := GetVolumeKey(volume)
+	if err != nil {
+		t.Fatalf("GetVolumeKey failed after PCR change: %v", err)
+	}
+	if !bytes.Equal(got, key) {
+		t.Errorf("want %x, but got %x", key, got)
+	}
+	//the key is sealed again under the current PCRs
+	sealed, err := ioutil.ReadFile(filepath.Join(VolumeKeysDir, appKeyName(testApp1)))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if _, err := tpm.unseal(sealed); err != nil {
+		t.Errorf("Key is not resealed: %v", err)
+	}
+
+	//the vault key is rotated, the recovery copy is rewrapped on load
+	tpm.vaultKey = []byte("new vault key")
+	if _, err := GetVolumeKey(volume); err != nil {
+		t.Fatalf("GetVolumeKey failed after vault key change: %v", err)
+	}
+	tpm.pcr = 3
+	got, err = GetVolumeKey(volume)
+	if err != nil {
+		t.Fatalf("GetVolumeKey failed after PCR change: %v", err)
+	}
+	if !bytes.Equal(got, key) {
+		t.Errorf("want %x, but got %x", key, got)
+	}
+
+	//neither the PCRs nor the vault key match
+	tpm.pcr = 4
+	tpm.vaultKey = []byte("other vault key")
+	if _, err := GetVolumeKey(volume); err == nil {
+		t.Errorf("GetVolumeKey succeeded with wrong PCRs and vault key")
+	}
+}
+
+func TestCreateVolumeKeyWithoutTpm(t *testing.T) {
+	setupVolumeKeys(t)
+	isTpmEnabled = func() bool { return false }
+	if _, err := CreateVolumeKey("volume#0", testApp1); err == nil {
+		t.Errorf("CreateVolumeKey succeeded without TPM")
+	}
+}
+
+func TestCreateVolumeKeyWithoutApp(t *testing.T) {
+	setupVolumeKeys(t)
+	if _, err := CreateVolumeKey("volume#0", ""); err == nil {
+		t.Errorf("CreateVolumeKey succeeded without app instance")
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package wwan
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// atCommandTimeout : how long to wait for the response to an AT command.
+const atCommandTimeout = 2 * time.Second
+
+var errATTimeout = errors.New("timeout waiting for response to AT command")
+
+// sendATCommand sends Hayes (AT) command to the serial port of a modem
+// and returns the response.
+func sendATCommand(ttyPath, cmd string) (string, error) {
+	tty, err := os.OpenFile(ttyPath, os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return "", err
+	}
+	defer tty.Close()
+	if err = setRawMode(tty); err != nil {
+		return "", fmt.Errorf("failed to set raw mode for %s: %w", ttyPath, err)
+	}
+	if _, err = tty.Write([]byte(cmd + "\r\n")); err != nil {
+		return "", err
+	}
+	var resp []byte
+	buf := make([]byte, 256)
+	deadline := time.Now().Add(atCommandTimeout)
+	if err = tty.SetReadDeadline(deadline); err != nil {
+		return "", err
+	}
+	for {
+		n, err := tty.Read(buf)
+		resp = append(resp, buf[:n]...)
+		if bytes.Contains(resp, []byte("OK")) || bytes.Contains(resp, []byte("ERROR")) {
+			return string(resp), nil
+		}
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return string(resp), fmt.Errorf("%s from %s: %w", cmd, ttyPath, errATTimeout)
+			}
+			return string(resp), err
+		}
+	}
+}
+
+// setRawMode disables echo and line processing of the terminal.
+func setRawMode(tty *os.File) error {
+	rawConn, err := tty.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var termErr error
+	err = rawConn.Control(func(fd uintptr) {
+		termios, err := unix.IoctlGetTermios(int(fd), unix.TCGETS)
+		if err != nil {
+			termErr = err
+			return
+		}
+		termios.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP |
+			unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+		termios.Oflag &^= unix.OPOST
+		termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+		termios.Cflag &^= unix.CSIZE | unix.PARENB
+		termios.Cflag |= unix.CS8
+		termios.Cc[unix.VMIN] = 1
+		termios.Cc[unix.VTIME] = 0
+		termErr = unix.IoctlSetTermios(int(fd), unix.TCSETS, termios)
+	})
+	if err != nil {
+		return err
+	}
+	return termErr
+}
+
+// findATPort returns the serial port of the modem which responds
+// to AT commands.
+func findATPort(modem ModemDevice) (string, error) {
+	for _, tty := range sysGetModemTTYs(modem.PhysAddrs.USB) {
+		ttyPath := filepath.Join(devDir, tty)
+		if resp, err := sendATCommand(ttyPath, "AT"); err == nil &&
+			bytes.Contains([]byte(resp), []byte("OK")) {
+			return ttyPath, nil
+		}
+	}
+	return "", fmt.Errorf("AT port is not available for modem %s", modem.CdcDev)
+}
+
+// switchToMBIM reconfigures Sierra Wireless modem to use MBIM
+// and resets it.
+func switchToMBIM(modem ModemDevice) error {
+	atPort, err := findATPort(modem)
+	if err != nil {
+		return err
+	}
+	for _, cmd := range []string{"+++", `AT!ENTERCND="A710"`, "AT!USBCOMP=1,1,100D", "AT!RESET"} {
+		// Not all commands are confirmed, e.g. the modem may reset
+		// before responding to AT!RESET.
+		resp, err := sendATCommand(atPort, cmd)
+		if err != nil && !errors.Is(err, errATTimeout) {
+			return fmt.Errorf("command %s failed: %w", cmd, err)
+		}
+		if bytes.Contains([]byte(resp), []byte("ERROR")) {
+			return fmt.Errorf("command %s failed: %s", cmd, resp)
+		}
+	}
+	return nil
+}
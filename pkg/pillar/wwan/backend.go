@@ -0,0 +1,160 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package wwan
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/lf-edge/eve/pkg/pillar/devicenetwork"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	fileutils "github.com/lf-edge/eve/pkg/pillar/utils/file"
+	"github.com/vishvananda/netlink"
+)
+
+// ModemBackend finds modems and opens control connections to them.
+type ModemBackend interface {
+	// FindModems returns all modems currently present in the system.
+	FindModems() ([]ModemDevice, error)
+	// OpenModem opens control connection to the given modem.
+	OpenModem(device ModemDevice) (Modem, error)
+	// SwitchToMBIM reconfigures the modem to use MBIM and resets it.
+	SwitchToMBIM(device ModemDevice) error
+}
+
+// NetworkConfigurator applies IP settings obtained from modems
+// and verifies connectivity.
+type NetworkConfigurator interface {
+	// ApplyIPSettings assigns IP configuration to the wwan interface.
+	ApplyIPSettings(iface string, settings IPSettings) error
+	// Probe checks connectivity to the given address via the wwan interface.
+	Probe(iface, address string) error
+}
+
+// LinuxModemBackend finds modems in sysfs and talks to them over
+// their control devices.
+type LinuxModemBackend struct{}
+
+// FindModems returns all QMI and MBIM modems found in sysfs.
+func (LinuxModemBackend) FindModems() ([]ModemDevice, error) {
+	return findModems()
+}
+
+// OpenModem opens the control device of the modem.
+func (LinuxModemBackend) OpenModem(device ModemDevice) (Modem, error) {
+	transport, err := OpenDeviceTransport(device.ControlDevicePath())
+	if err != nil {
+		return nil, err
+	}
+	switch device.Protocol {
+	case types.WwanCtrlProtQMI:
+		return NewQmiModem(transport, device.PhysAddrs.Interface), nil
+	case types.WwanCtrlProtMBIM:
+		return NewMbimModem(transport), nil
+	}
+	transport.Close()
+	return nil, fmt.Errorf("unsupported control protocol %q of modem %s",
+		device.Protocol, device.CdcDev)
+}
+
+// SwitchToMBIM reconfigures the modem using AT commands.
+func (LinuxModemBackend) SwitchToMBIM(device ModemDevice) error {
+	return switchToMBIM(device)
+}
+
+// probeTimeout : timeout (in seconds) for the connectivity probe.
+const probeTimeout = 20
+
+// wwanRouteMetric : metric of the default route via the wwan interface,
+// low priority to not interfere with routes installed by nim.
+const wwanRouteMetric = 65000
+
+// LinuxNetworkConfigurator configures wwan interfaces using netlink.
+type LinuxNetworkConfigurator struct {
+	Log *base.LogObject
+}
+
+// ApplyIPSettings assigns IP address, MTU and default route to the interface
+// and publishes DNS servers for nim as <iface>.dhcp under the resolv.conf
+// directory. Settings without IPv4 address (IPv6-only connection) only bring
+// the interface up.
+func (c *LinuxNetworkConfigurator) ApplyIPSettings(iface string, settings IPSettings) error {
+	link, err := netlink.LinkByName(iface)
+	if err != nil {
+		return fmt.Errorf("failed to get link %s: %w", iface, err)
+	}
+	if settings.MTU != 0 {
+		if err = netlink.LinkSetMTU(link, int(settings.MTU)); err != nil {
+			return fmt.Errorf("failed to set MTU %d for %s: %w",
+				settings.MTU, iface, err)
+		}
+	}
+	if err = netlink.LinkSetUp(link); err != nil {
+		return fmt.Errorf("failed to set link %s up: %w", iface, err)
+	}
+	var newAddr *netlink.Addr
+	if settings.Address != nil {
+		newAddr = &netlink.Addr{IPNet: &net.IPNet{IP: settings.Address, Mask: settings.Mask}}
+	}
+	addrs, err := netlink.AddrList(link, netlink.FAMILY_V4)
+	if err != nil {
+		return fmt.Errorf("failed to list addresses of %s: %w", iface, err)
+	}
+	for _, addr := range addrs {
+		if newAddr != nil && addr.IPNet.String() == newAddr.IPNet.String() {
+			continue
+		}
+		if err = netlink.AddrDel(link, &addr); err != nil {
+			c.Log.Warnf("Failed to remove obsolete address %s from %s: %v",
+				addr.IPNet, iface, err)
+		}
+	}
+	if newAddr != nil {
+		if err = netlink.AddrReplace(link, newAddr); err != nil {
+			return fmt.Errorf("failed to assign address %s to %s: %w",
+				newAddr.IPNet, iface, err)
+		}
+	}
+	if settings.Gateway != nil {
+		route := &netlink.Route{
+			LinkIndex: link.Attrs().Index,
+			Gw:        settings.Gateway,
+			Priority:  wwanRouteMetric,
+			Flags:     int(netlink.FLAG_ONLINK),
+		}
+		if err = netlink.RouteReplace(route); err != nil {
+			return fmt.Errorf("failed to add default route via %s: %w",
+				settings.Gateway, err)
+		}
+	}
+	return c.writeResolvConf(iface, settings.DNS)
+}
+
+func (c *LinuxNetworkConfigurator) writeResolvConf(iface string, servers []net.IP) error {
+	if err := os.MkdirAll(devicenetwork.WwanResolvConfDir, 0755); err != nil {
+		return err
+	}
+	var sb strings.Builder
+	for _, server := range servers {
+		sb.WriteString(fmt.Sprintf("nameserver %s\n", server))
+	}
+	filename := filepath.Join(devicenetwork.WwanResolvConfDir, iface+".dhcp")
+	return fileutils.WriteRename(filename, []byte(sb.String()))
+}
+
+// Probe sends ICMP echo requests to the address via the interface.
+func (c *LinuxNetworkConfigurator) Probe(iface, address string) error {
+	timeout := fmt.Sprintf("%d", probeTimeout)
+	out, err := base.Exec(c.Log, "ping", "-W", timeout, "-w", timeout,
+		"-c", "3", "-I", iface, address).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to ping %s via %s: %s",
+			address, iface, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,381 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package wwan
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+// FakeModem simulates a cellular modem. It is used with FakeModemBackend
+// in unit tests.
+type FakeModem struct {
+	sync.Mutex
+	Module types.WwanCellModule
+	// State and identity of the SIM card in the selected slot.
+	SimState   SimState
+	SimCard    types.WwanSimCard
+	Registered bool
+	Provider   types.WwanProvider
+	// APNs accepted by the network, connection attempts with other APNs fail.
+	ValidApns []string
+	// SIM cards in slots which are not selected (key = slot number).
+	// Nil for modems with a single SIM slot.
+	SimSlots    map[uint8]FakeSimCard
+	IPSettings  IPSettings
+	SignalInfo  types.WwanSignalInfo
+	PacketStats types.WwanPacketStats
+	// Location is nil for modems without GNSS receiver.
+	Location *types.WwanLocationInfo
+
+	radioOn         bool
+	simSlot         uint8
+	connectedApn    string
+	tracking        bool
+	closed          bool
+	connectAttempts []string
+}
+
+// FakeSimCard is a SIM card inserted in a slot of FakeModem which is
+// not selected. Selecting the slot swaps the card with the SIM state,
+// SIM card identity and valid APNs of the modem.
+type FakeSimCard struct {
+	State     SimState
+	Card      types.WwanSimCard
+	ValidApns []string
+}
+
+// RadioOn returns true if the radio of the modem is enabled.
+func (f *FakeModem) RadioOn() bool {
+	f.Lock()
+	defer f.Unlock()
+	return f.radioOn
+}
+
+// ConnectedApn returns APN of the current data connection (empty if not connected).
+func (f *FakeModem) ConnectedApn() string {
+	f.Lock()
+	defer f.Unlock()
+	return f.connectedApn
+}
+
+// ConnectAttempts returns APNs used in all connection attempts so far.
+func (f *FakeModem) ConnectAttempts() []string {
+	f.Lock()
+	defer f.Unlock()
+	return append([]string{}, f.connectAttempts...)
+}
+
+// SimSlot returns the selected SIM slot.
+func (f *FakeModem) SimSlot() uint8 {
+	f.Lock()
+	defer f.Unlock()
+	if f.simSlot == 0 {
+		return 1
+	}
+	return f.simSlot
+}
+
+// IsTracking returns true if location tracking is running.
+func (f *FakeModem) IsTracking() bool {
+	f.Lock()
+	defer f.Unlock()
+	return f.tracking
+}
+
+// SetSimState changes the state of the SIM card.
+func (f *FakeModem) SetSimState(state SimState) {
+	f.Lock()
+	defer f.Unlock()
+	f.SimState = state
+	if state != SimStateReady {
+		f.connectedApn = ""
+	}
+}
+
+// DropConnection simulates loss of the data connection.
+func (f *FakeModem) DropConnection() {
+	f.Lock()
+	defer f.Unlock()
+	f.connectedApn = ""
+}
+
+func (f *FakeModem) checkOpened() error {
+	if f.closed {
+		return errors.New("modem is closed")
+	}
+	return nil
+}
+
+func (f *FakeModem) registered() bool {
+	return f.radioOn && f.Registered && f.SimState == SimStateReady
+}
+
+// GetModuleInfo returns the simulated module info.
+func (f *FakeModem) GetModuleInfo() (types.WwanCellModule, error) {
+	f.Lock()
+	defer f.Unlock()
+	return f.Module, f.checkOpened()
+}
+
+// GetOpMode returns the operating mode of the simulated modem.
+func (f *FakeModem) GetOpMode() (types.WwanOpMode, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.checkOpened(); err != nil {
+		return types.WwanOpModeUnspecified, err
+	}
+	switch {
+	case !f.radioOn:
+		return types.WwanOpModeRadioOff, nil
+	case f.connectedApn != "":
+		return types.WwanOpModeConnected, nil
+	default:
+		return types.WwanOpModeOnline, nil
+	}
+}
+
+// SetRadio enables or disables the simulated radio.
+func (f *FakeModem) SetRadio(on bool) error {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.checkOpened(); err != nil {
+		return err
+	}
+	f.radioOn = on
+	if !on {
+		f.connectedApn = ""
+	}
+	return nil
+}
+
+// GetSimStatus returns the simulated SIM status.
+func (f *FakeModem) GetSimStatus() (SimStatus, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.checkOpened(); err != nil {
+		return SimStatus{}, err
+	}
+	status := SimStatus{State: f.SimState}
+	if f.SimState == SimStateReady {
+		status.Card = f.SimCard
+	}
+	return status, nil
+}
+
+// GetRegistration returns the simulated registration state.
+func (f *FakeModem) GetRegistration() (Registration, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.checkOpened(); err != nil {
+		return Registration{}, err
+	}
+	if !f.registered() {
+		return Registration{}, nil
+	}
+	return Registration{Registered: true, Provider: f.Provider}, nil
+}
+
+// SelectSimSlot swaps the SIM card of the modem with the card in the slot.
+func (f *FakeModem) SelectSimSlot(slot uint8) error {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.checkOpened(); err != nil {
+		return err
+	}
+	if f.SimSlots == nil {
+		return ErrNotSupported
+	}
+	current := f.simSlot
+	if current == 0 {
+		current = 1
+	}
+	if slot == current {
+		return nil
+	}
+	selected, ok := f.SimSlots[slot]
+	if !ok {
+		return fmt.Errorf("invalid SIM slot %d", slot)
+	}
+	f.SimSlots[current] = FakeSimCard{
+		State:     f.SimState,
+		Card:      f.SimCard,
+		ValidApns: f.ValidApns,
+	}
+	delete(f.SimSlots, slot)
+	f.SimState = selected.State
+	f.SimCard = selected.Card
+	f.ValidApns = selected.ValidApns
+	f.simSlot = slot
+	f.connectedApn = ""
+	return nil
+}
+
+// Connect succeeds only if the modem is registered and the APN is valid.
+func (f *FakeModem) Connect(profile types.WwanApnProfile) error {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.checkOpened(); err != nil {
+		return err
+	}
+	apn := profile.APN
+	f.connectAttempts = append(f.connectAttempts, apn)
+	if !f.registered() {
+		return errors.New("not registered")
+	}
+	for _, validApn := range f.ValidApns {
+		if apn == validApn {
+			f.connectedApn = apn
+			return nil
+		}
+	}
+	return fmt.Errorf("call failed: APN %s rejected", apn)
+}
+
+// Disconnect stops the simulated data connection.
+func (f *FakeModem) Disconnect() error {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.checkOpened(); err != nil {
+		return err
+	}
+	f.connectedApn = ""
+	return nil
+}
+
+// IsConnected returns true if the simulated data connection is established.
+func (f *FakeModem) IsConnected() (bool, error) {
+	f.Lock()
+	defer f.Unlock()
+	return f.connectedApn != "", f.checkOpened()
+}
+
+// GetIPSettings returns the simulated IP settings if connected.
+func (f *FakeModem) GetIPSettings() (IPSettings, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.checkOpened(); err != nil {
+		return IPSettings{}, err
+	}
+	if f.connectedApn == "" {
+		return IPSettings{}, nil
+	}
+	return f.IPSettings, nil
+}
+
+// GetSignalInfo returns the simulated signal info.
+func (f *FakeModem) GetSignalInfo() (types.WwanSignalInfo, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.checkOpened(); err != nil {
+		return types.WwanSignalInfo{}, err
+	}
+	if !f.radioOn {
+		return types.WwanSignalInfo{
+			RSSI: UnavailableSignalMetric,
+			RSRQ: UnavailableSignalMetric,
+			RSRP: UnavailableSignalMetric,
+			SNR:  UnavailableSignalMetric,
+		}, nil
+	}
+	return f.SignalInfo, nil
+}
+
+// GetPacketStats returns the simulated packet statistics.
+func (f *FakeModem) GetPacketStats() (types.WwanPacketStats, error) {
+	f.Lock()
+	defer f.Unlock()
+	return f.PacketStats, f.checkOpened()
+}
+
+// StartLocationTracking fails for modems without GNSS receiver.
+func (f *FakeModem) StartLocationTracking() error {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.checkOpened(); err != nil {
+		return err
+	}
+	if f.Location == nil {
+		return ErrNotSupported
+	}
+	f.tracking = true
+	return nil
+}
+
+// StopLocationTracking stops the simulated location tracking.
+func (f *FakeModem) StopLocationTracking() error {
+	f.Lock()
+	defer f.Unlock()
+	f.tracking = false
+	return f.checkOpened()
+}
+
+// GetLocation returns the simulated location if tracking is running.
+func (f *FakeModem) GetLocation() (types.WwanLocationInfo, bool, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.checkOpened(); err != nil {
+		return types.WwanLocationInfo{}, false, err
+	}
+	if !f.tracking || f.Location == nil {
+		return types.WwanLocationInfo{}, false, nil
+	}
+	return *f.Location, true, nil
+}
+
+// Close marks the simulated control connection as closed.
+func (f *FakeModem) Close() error {
+	f.Lock()
+	defer f.Unlock()
+	f.closed = true
+	f.tracking = false
+	return nil
+}
+
+// FakeModemBackend provides FakeModems to Manager.
+type FakeModemBackend struct {
+	sync.Mutex
+	Devices []ModemDevice
+	// Key: name of the control device.
+	Modems map[string]*FakeModem
+	// Control devices of modems switched to MBIM.
+	SwitchedToMBIM []string
+}
+
+// FindModems returns the simulated modem devices.
+func (b *FakeModemBackend) FindModems() ([]ModemDevice, error) {
+	b.Lock()
+	defer b.Unlock()
+	return append([]ModemDevice{}, b.Devices...), nil
+}
+
+// OpenModem returns the FakeModem of the device.
+func (b *FakeModemBackend) OpenModem(device ModemDevice) (Modem, error) {
+	b.Lock()
+	defer b.Unlock()
+	modem, ok := b.Modems[device.CdcDev]
+	if !ok {
+		return nil, fmt.Errorf("no such device: %s", device.CdcDev)
+	}
+	modem.Lock()
+	modem.closed = false
+	modem.Unlock()
+	return modem, nil
+}
+
+// SwitchToMBIM records the switch and changes the protocol of the device.
+func (b *FakeModemBackend) SwitchToMBIM(device ModemDevice) error {
+	b.Lock()
+	defer b.Unlock()
+	b.SwitchedToMBIM = append(b.SwitchedToMBIM, device.CdcDev)
+	for i := range b.Devices {
+		if b.Devices[i].CdcDev == device.CdcDev {
+			b.Devices[i].Protocol = types.WwanCtrlProtMBIM
+		}
+	}
+	return nil
+}
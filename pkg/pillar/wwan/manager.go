@@ -0,0 +1,583 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package wwan
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+const (
+	// DefaultApn : APN used when none is configured.
+	DefaultApn = "internet"
+	// DefaultProbeAddr : address probed when none is configured.
+	DefaultProbeAddr = "8.8.8.8"
+)
+
+// Parameters of waiting for a modem to reach the desired state.
+// Not constants due to test usage.
+var (
+	waitInterval    = 6 * time.Second
+	waitAttempts    = 10
+	probeRetryDelay = 3 * time.Second
+)
+
+// Sierra Wireless EM7565 is known to be unreliable with QMI.
+const em7565Model = "EM7565"
+
+// Manager applies WwanConfig to cellular modems and collects their
+// status, metrics and location info.
+// Manager is not safe for concurrent use, it is expected to be driven
+// by a single goroutine of the wwan microservice.
+type Manager struct {
+	Log     *base.LogObject
+	Backend ModemBackend
+	Network NetworkConfigurator
+
+	config   types.WwanConfig
+	checksum string
+	// Opened modems, key = name of the control device.
+	modems map[string]*managedModem
+	// Modem used for location tracking (can be nil).
+	tracker *managedModem
+	// Logical label of the modem used for location tracking.
+	trackerLabel string
+}
+
+type managedModem struct {
+	device ModemDevice
+	modem  Modem
+	// SIM slot selected by Manager (0 if not selected yet).
+	simSlot uint8
+	// Index of the APN profile used by the data connection (-1 if not connected).
+	activeProfile int
+}
+
+// ApplyConfig (re)connects all configured modems using the new config
+// and returns the resulting status.
+func (m *Manager) ApplyConfig(config types.WwanConfig, checksum string) types.WwanStatus {
+	m.config = config
+	m.checksum = checksum
+	return m.processModems(true)
+}
+
+// Probe verifies connectivity of all configured modems, reconnects those
+// where the probe fails, and returns the updated status.
+func (m *Manager) Probe() types.WwanStatus {
+	return m.processModems(false)
+}
+
+// CollectMetrics returns packet statistics and signal info of all modems.
+func (m *Manager) CollectMetrics() types.WwanMetrics {
+	var metrics types.WwanMetrics
+	devices := m.findModems()
+	labels := m.logicalLabels(devices)
+	for _, device := range devices {
+		mm, err := m.openModem(device)
+		if err != nil {
+			continue
+		}
+		netMetrics := types.WwanNetworkMetrics{
+			LogicalLabel: labels[device.CdcDev],
+			PhysAddrs:    device.PhysAddrs,
+		}
+		if netMetrics.PacketStats, err = mm.modem.GetPacketStats(); err != nil {
+			m.Log.Warnf("Failed to get packet stats from modem %s: %v",
+				device.CdcDev, err)
+		}
+		if netMetrics.SignalInfo, err = mm.modem.GetSignalInfo(); err != nil {
+			m.Log.Warnf("Failed to get signal info from modem %s: %v",
+				device.CdcDev, err)
+			netMetrics.SignalInfo = types.WwanSignalInfo{
+				RSSI: UnavailableSignalMetric,
+				RSRQ: UnavailableSignalMetric,
+				RSRP: UnavailableSignalMetric,
+				SNR:  UnavailableSignalMetric,
+			}
+		}
+		metrics.Networks = append(metrics.Networks, netMetrics)
+	}
+	return metrics
+}
+
+// GetLocation returns new location info obtained from the modem selected
+// for location tracking, if there is any.
+func (m *Manager) GetLocation() (types.WwanLocationInfo, bool) {
+	if m.tracker == nil {
+		return types.WwanLocationInfo{}, false
+	}
+	locInfo, ok, err := m.tracker.modem.GetLocation()
+	if err != nil {
+		m.Log.Warnf("Failed to get location from modem %s: %v",
+			m.tracker.device.CdcDev, err)
+		return types.WwanLocationInfo{}, false
+	}
+	if !ok {
+		return types.WwanLocationInfo{}, false
+	}
+	locInfo.LogicalLabel = m.trackerLabel
+	return locInfo, true
+}
+
+// Close closes control connections to all modems.
+func (m *Manager) Close() {
+	for cdcDev := range m.modems {
+		m.closeModem(cdcDev)
+	}
+}
+
+func (m *Manager) processModems(configChanged bool) types.WwanStatus {
+	status := types.WwanStatus{ConfigChecksum: m.checksum}
+	devices := m.findModems()
+	used := make(map[string]bool)
+	var tracker *managedModem
+	var trackerLabel string
+	for _, netCfg := range m.config.Networks {
+		netStatus := types.WwanNetworkStatus{
+			LogicalLabel: netCfg.LogicalLabel,
+			PhysAddrs:    netCfg.PhysAddrs,
+		}
+		device, found := lookupModem(devices, netCfg.PhysAddrs)
+		if !found {
+			netStatus.ConfigError = fmt.Sprintf(
+				"failed to find modem for interface=%s, USB=%s, PCI=%s",
+				orAny(netCfg.PhysAddrs.Interface), orAny(netCfg.PhysAddrs.USB),
+				orAny(netCfg.PhysAddrs.PCI))
+			status.Networks = append(status.Networks, netStatus)
+			continue
+		}
+		used[device.CdcDev] = true
+		netStatus.PhysAddrs = device.PhysAddrs
+		mm, err := m.openModem(device)
+		if err != nil {
+			netStatus.ConfigError = err.Error()
+			status.Networks = append(status.Networks, netStatus)
+			continue
+		}
+		if m.switchToPreferredProtocol(mm) {
+			// Modem is restarting, status will be reported once it is back.
+			continue
+		}
+		if netCfg.LocationTracking && tracker == nil {
+			tracker = mm
+			trackerLabel = netCfg.LogicalLabel
+		}
+		if m.config.RadioSilence {
+			if err = m.disableRadio(mm); err != nil {
+				netStatus.ConfigError = err.Error()
+			}
+		} else if configChanged || m.probe(mm, netCfg, &netStatus) != nil {
+			if err = m.connect(mm, netCfg); err != nil {
+				netStatus.ConfigError = err.Error()
+			}
+			if !netCfg.Probe.Disable {
+				time.Sleep(probeRetryDelay)
+			}
+			m.probe(mm, netCfg, &netStatus)
+		}
+		m.collectStatus(mm, &netStatus)
+		profiles := apnProfiles(netCfg)
+		if netStatus.Module.OpMode == types.WwanOpModeConnected &&
+			mm.activeProfile >= 0 && mm.activeProfile < len(profiles) {
+			profile := profiles[mm.activeProfile]
+			profile.Password = ""
+			netStatus.ActiveProfile = profile
+			netStatus.ActiveProfileIdx = mm.activeProfile
+		}
+		status.Networks = append(status.Networks, netStatus)
+	}
+	// Modems not configured by the controller are kept with radio off.
+	for _, device := range devices {
+		if used[device.CdcDev] {
+			continue
+		}
+		netStatus := types.WwanNetworkStatus{PhysAddrs: device.PhysAddrs}
+		mm, err := m.openModem(device)
+		if err != nil {
+			netStatus.ConfigError = err.Error()
+			status.Networks = append(status.Networks, netStatus)
+			continue
+		}
+		if err = m.disableRadio(mm); err != nil {
+			netStatus.ConfigError = err.Error()
+		}
+		m.collectStatus(mm, &netStatus)
+		status.Networks = append(status.Networks, netStatus)
+	}
+	if configChanged {
+		m.updateLocationTracking(tracker, trackerLabel)
+	}
+	return status
+}
+
+// findModems returns modems present in the system and closes connections
+// to modems which are gone (or changed the control protocol).
+func (m *Manager) findModems() []ModemDevice {
+	devices, err := m.Backend.FindModems()
+	if err != nil {
+		m.Log.Errorf("Failed to find modems: %v", err)
+	}
+	for cdcDev, mm := range m.modems {
+		var found bool
+		for _, device := range devices {
+			if device == mm.device {
+				found = true
+				break
+			}
+		}
+		if !found {
+			m.Log.Noticef("Modem %s is gone", mm.device)
+			m.closeModem(cdcDev)
+		}
+	}
+	return devices
+}
+
+func lookupModem(devices []ModemDevice, addrs types.WwanPhysAddrs) (ModemDevice, bool) {
+	for _, device := range devices {
+		if device.Matches(addrs) {
+			return device, true
+		}
+	}
+	return ModemDevice{}, false
+}
+
+func orAny(addr string) string {
+	if addr == "" {
+		return "<any>"
+	}
+	return addr
+}
+
+// logicalLabels maps control devices of configured modems to logical labels.
+// Unmanaged modems are not included.
+func (m *Manager) logicalLabels(devices []ModemDevice) map[string]string {
+	labels := make(map[string]string)
+	for _, netCfg := range m.config.Networks {
+		if device, ok := lookupModem(devices, netCfg.PhysAddrs); ok {
+			if _, duplicate := labels[device.CdcDev]; !duplicate {
+				labels[device.CdcDev] = netCfg.LogicalLabel
+			}
+		}
+	}
+	return labels
+}
+
+// openModem returns already opened control connection to the modem,
+// or opens a new one.
+func (m *Manager) openModem(device ModemDevice) (*managedModem, error) {
+	if mm, ok := m.modems[device.CdcDev]; ok {
+		// Make sure that the connection is still usable.
+		if _, err := mm.modem.GetOpMode(); err == nil {
+			return mm, nil
+		}
+		m.Log.Warnf("Re-opening control connection to modem %s", device)
+		m.closeModem(device.CdcDev)
+	}
+	modem, err := m.Backend.OpenModem(device)
+	if err != nil {
+		err = fmt.Errorf("failed to open modem %s: %w", device.CdcDev, err)
+		m.Log.Error(err)
+		return nil, err
+	}
+	m.Log.Noticef("Opened control connection to modem %s", device)
+	if m.modems == nil {
+		m.modems = make(map[string]*managedModem)
+	}
+	mm := &managedModem{device: device, modem: modem, activeProfile: -1}
+	m.modems[device.CdcDev] = mm
+	return mm, nil
+}
+
+func (m *Manager) closeModem(cdcDev string) {
+	mm, ok := m.modems[cdcDev]
+	if !ok {
+		return
+	}
+	if m.tracker == mm {
+		m.tracker = nil
+		m.trackerLabel = ""
+	}
+	if err := mm.modem.Close(); err != nil {
+		m.Log.Warnf("Failed to close modem %s: %v", cdcDev, err)
+	}
+	delete(m.modems, cdcDev)
+}
+
+// switchToPreferredProtocol switches modems known to misbehave with QMI
+// to MBIM. Returns true if the modem was reconfigured and is restarting.
+func (m *Manager) switchToPreferredProtocol(mm *managedModem) bool {
+	if mm.device.Protocol != types.WwanCtrlProtQMI {
+		return false
+	}
+	module, err := mm.modem.GetModuleInfo()
+	if err != nil || module.Model != em7565Model {
+		return false
+	}
+	m.Log.Noticef("Switching modem %s from QMI to MBIM", mm.device)
+	if err = m.Backend.SwitchToMBIM(mm.device); err != nil {
+		m.Log.Errorf("Failed to switch modem %s to MBIM: %v", mm.device, err)
+		return false
+	}
+	m.closeModem(mm.device.CdcDev)
+	return true
+}
+
+// waitFor polls the condition until it is satisfied or the number
+// of attempts is exhausted.
+func waitFor(what string, cond func() (bool, string)) error {
+	var state string
+	for i := 0; i < waitAttempts; i++ {
+		var done bool
+		if done, state = cond(); done {
+			return nil
+		}
+		time.Sleep(waitInterval)
+	}
+	if state != "" {
+		return fmt.Errorf("timeout waiting for %s (%s)", what, state)
+	}
+	return fmt.Errorf("timeout waiting for %s", what)
+}
+
+func (m *Manager) disableRadio(mm *managedModem) error {
+	mm.activeProfile = -1
+	if opMode, err := mm.modem.GetOpMode(); err == nil && opMode == types.WwanOpModeRadioOff {
+		return nil
+	}
+	m.Log.Noticef("Disabling radio of modem %s", mm.device)
+	if err := mm.modem.SetRadio(false); err != nil {
+		return fmt.Errorf("failed to disable radio: %w", err)
+	}
+	return waitFor("radio to turn off", func() (bool, string) {
+		opMode, err := mm.modem.GetOpMode()
+		if err != nil {
+			return false, err.Error()
+		}
+		return opMode == types.WwanOpModeRadioOff, "mode: " + string(opMode)
+	})
+}
+
+// apnProfiles returns configured APN profiles or the default profile
+// if there are none.
+func apnProfiles(netCfg types.WwanNetworkConfig) []types.WwanApnProfile {
+	if len(netCfg.ApnProfiles) == 0 {
+		return []types.WwanApnProfile{{APN: DefaultApn}}
+	}
+	return netCfg.ApnProfiles
+}
+
+// connect (re)establishes data connection, trying configured APN profiles
+// in order, starting with netCfg.StartProfile.
+func (m *Manager) connect(mm *managedModem, netCfg types.WwanNetworkConfig) error {
+	modem := mm.modem
+	profiles := apnProfiles(netCfg)
+	start := netCfg.StartProfile
+	if start < 0 || start >= len(profiles) {
+		start = 0
+	}
+	var apns []string
+	for _, profile := range profiles {
+		apns = append(apns, profile.APN)
+	}
+	m.Log.Noticef("Restarting connection of modem %s (APNs: %s, starting with %s)",
+		mm.device, strings.Join(apns, ", "), apns[start])
+	mm.activeProfile = -1
+	if err := modem.Disconnect(); err != nil {
+		m.Log.Warnf("Failed to stop connection of modem %s: %v", mm.device, err)
+	}
+	if err := modem.SetRadio(true); err != nil {
+		return fmt.Errorf("failed to enable radio: %w", err)
+	}
+	var errs []string
+	// SIM card is prepared whenever the next profile uses a different slot.
+	var simErr error
+	simSlot := -1
+	for i := range profiles {
+		index := (start + i) % len(profiles)
+		profile := profiles[index]
+		if int(profile.SimSlot) != simSlot {
+			simSlot = int(profile.SimSlot)
+			if simErr = m.prepareSim(mm, profile.SimSlot); simErr != nil {
+				m.Log.Warnf("Failed to prepare SIM card of modem %s: %v",
+					mm.device, simErr)
+				if profile.SimSlot != 0 {
+					simErr = fmt.Errorf("SIM slot %d: %w", profile.SimSlot, simErr)
+				}
+				errs = append(errs, simErr.Error())
+			}
+		}
+		if simErr != nil {
+			// Already reported.
+			continue
+		}
+		err := m.connectAPN(mm, profile)
+		if err == nil {
+			mm.activeProfile = index
+			return nil
+		}
+		m.Log.Warnf("Failed to connect modem %s using APN %s: %v",
+			mm.device, profile.APN, err)
+		errs = append(errs, fmt.Sprintf("APN %s: %v", profile.APN, err))
+		if err = modem.Disconnect(); err != nil {
+			m.Log.Warnf("Failed to stop connection of modem %s: %v",
+				mm.device, err)
+		}
+	}
+	return errors.New(strings.Join(errs, "; "))
+}
+
+// prepareSim selects the SIM slot (unless zero) and waits for the SIM card
+// to be ready and for the modem to register to the network.
+func (m *Manager) prepareSim(mm *managedModem, slot uint8) error {
+	modem := mm.modem
+	if slot != 0 && slot != mm.simSlot {
+		m.Log.Noticef("Selecting SIM slot %d of modem %s", slot, mm.device)
+		if err := modem.SelectSimSlot(slot); err != nil {
+			return fmt.Errorf("failed to select SIM slot: %w", err)
+		}
+		mm.simSlot = slot
+	}
+	err := waitFor("SIM card to be ready", func() (bool, string) {
+		sim, err := modem.GetSimStatus()
+		if err != nil {
+			return false, err.Error()
+		}
+		return sim.State == SimStateReady, "state: " + sim.State.String()
+	})
+	if err != nil {
+		return err
+	}
+	return waitFor("network registration", func() (bool, string) {
+		reg, err := modem.GetRegistration()
+		if err != nil {
+			return false, err.Error()
+		}
+		return reg.Registered, ""
+	})
+}
+
+func (m *Manager) connectAPN(mm *managedModem, profile types.WwanApnProfile) error {
+	modem := mm.modem
+	if profile.ForbidRoaming {
+		reg, err := modem.GetRegistration()
+		if err != nil {
+			return fmt.Errorf("failed to get registration: %w", err)
+		}
+		if reg.Provider.Roaming {
+			return errors.New("roaming is forbidden for this APN profile")
+		}
+	}
+	if err := modem.Connect(profile); err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	err := waitFor("data connection", func() (bool, string) {
+		connected, err := modem.IsConnected()
+		if err != nil {
+			return false, err.Error()
+		}
+		return connected, ""
+	})
+	if err != nil {
+		return err
+	}
+	// IPv6 addresses are configured by the kernel from router advertisements.
+	var settings IPSettings
+	if profile.IPType != types.WwanIPTypeIPv6 {
+		err = waitFor("IP configuration", func() (bool, string) {
+			var err error
+			settings, err = modem.GetIPSettings()
+			if err != nil {
+				return false, err.Error()
+			}
+			return settings.Valid(), ""
+		})
+		if err != nil {
+			return err
+		}
+	}
+	iface := mm.device.PhysAddrs.Interface
+	if err = m.Network.ApplyIPSettings(iface, settings); err != nil {
+		return fmt.Errorf("failed to apply IP settings (%s): %w", settings, err)
+	}
+	m.Log.Noticef("Modem %s connected using APN %s (%s)", mm.device, profile.APN, settings)
+	return nil
+}
+
+// probe verifies connectivity and records the outcome in the network status.
+func (m *Manager) probe(mm *managedModem, netCfg types.WwanNetworkConfig,
+	netStatus *types.WwanNetworkStatus) error {
+	netStatus.ProbeError = ""
+	if netCfg.Probe.Disable {
+		return nil
+	}
+	address := netCfg.Probe.Address
+	if address == "" {
+		address = DefaultProbeAddr
+	}
+	err := m.Network.Probe(mm.device.PhysAddrs.Interface, address)
+	if err != nil {
+		netStatus.ProbeError = err.Error()
+	}
+	return err
+}
+
+func (m *Manager) collectStatus(mm *managedModem, netStatus *types.WwanNetworkStatus) {
+	modem := mm.modem
+	module, err := modem.GetModuleInfo()
+	if err != nil {
+		m.Log.Warnf("Failed to get module info from modem %s: %v", mm.device, err)
+	}
+	module.ControlProtocol = mm.device.Protocol
+	if module.OpMode, err = modem.GetOpMode(); err != nil {
+		m.Log.Warnf("Failed to get operating mode of modem %s: %v", mm.device, err)
+		module.OpMode = types.WwanOpModeUnrecognized
+	}
+	netStatus.Module = module
+	netStatus.SimCards = nil
+	if sim, err := modem.GetSimStatus(); err != nil {
+		m.Log.Warnf("Failed to get SIM status from modem %s: %v", mm.device, err)
+	} else if sim.State == SimStateReady {
+		netStatus.SimCards = []types.WwanSimCard{sim.Card}
+	}
+	netStatus.Providers = nil
+	if reg, err := modem.GetRegistration(); err != nil {
+		m.Log.Warnf("Failed to get registration from modem %s: %v", mm.device, err)
+	} else if reg.Registered {
+		provider := reg.Provider
+		provider.CurrentServing = true
+		netStatus.Providers = []types.WwanProvider{provider}
+	}
+}
+
+func (m *Manager) updateLocationTracking(tracker *managedModem, label string) {
+	if m.tracker == tracker {
+		m.trackerLabel = label
+		return
+	}
+	if m.tracker != nil {
+		if err := m.tracker.modem.StopLocationTracking(); err != nil {
+			m.Log.Warnf("Failed to stop location tracking on modem %s: %v",
+				m.tracker.device, err)
+		}
+		m.tracker = nil
+		m.trackerLabel = ""
+	}
+	if tracker == nil {
+		return
+	}
+	if err := tracker.modem.StartLocationTracking(); err != nil {
+		m.Log.Errorf("Failed to start location tracking on modem %s: %v",
+			tracker.device, err)
+		return
+	}
+	m.Log.Noticef("Started location tracking on modem %s", tracker.device)
+	m.tracker = tracker
+	m.trackerLabel = label
+}
@@ -0,0 +1,505 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package wwan
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	"github.com/sirupsen/logrus"
+)
+
+type fakeNetwork struct {
+	sync.Mutex
+	applied map[string]IPSettings
+	// Interfaces where probing fails.
+	unreachable map[string]bool
+}
+
+func (n *fakeNetwork) ApplyIPSettings(iface string, settings IPSettings) error {
+	n.Lock()
+	defer n.Unlock()
+	n.applied[iface] = settings
+	return nil
+}
+
+func (n *fakeNetwork) Probe(iface, address string) error {
+	n.Lock()
+	defer n.Unlock()
+	if n.unreachable[iface] {
+		return errors.New("destination unreachable")
+	}
+	if _, ok := n.applied[iface]; !ok {
+		return errors.New("interface not configured")
+	}
+	return nil
+}
+
+var testIPSettings = IPSettings{
+	Address: net.ParseIP("10.1.2.3"),
+	Mask:    net.CIDRMask(30, 32),
+	Gateway: net.ParseIP("10.1.2.4"),
+	DNS:     []net.IP{net.ParseIP("10.1.1.1")},
+	MTU:     1500,
+}
+
+func newTestModem() *FakeModem {
+	return &FakeModem{
+		Module: types.WwanCellModule{
+			IMEI:     "353533101772021",
+			Model:    "EG25",
+			Revision: "EG25GGBR07A08M2G",
+		},
+		SimState: SimStateReady,
+		SimCard: types.WwanSimCard{
+			ICCID: "89012703578345957137",
+			IMSI:  "310180933695713",
+		},
+		Registered: true,
+		Provider:   types.WwanProvider{PLMN: "310-180", Description: "AT&T"},
+		ValidApns:  []string{"internet"},
+		IPSettings: testIPSettings,
+		SignalInfo: types.WwanSignalInfo{
+			RSSI: -67,
+			RSRQ: -11,
+			RSRP: -98,
+			SNR:  4,
+		},
+		PacketStats: types.WwanPacketStats{RxBytes: 1000, RxPackets: 10, TxBytes: 500, TxPackets: 5},
+	}
+}
+
+var testDevice = ModemDevice{
+	CdcDev:   "cdc-wdm0",
+	Protocol: types.WwanCtrlProtQMI,
+	PhysAddrs: types.WwanPhysAddrs{
+		Interface: "wwan0",
+		USB:       "1:3",
+		PCI:       "0000:00:15.0",
+	},
+}
+
+func newTestManager(t *testing.T, modems map[ModemDevice]*FakeModem) (*Manager, *FakeModemBackend, *fakeNetwork) {
+	waitInterval = time.Millisecond
+	waitAttempts = 3
+	probeRetryDelay = 0
+	logger := logrus.StandardLogger()
+	log := base.NewSourceLogObject(logger, "wwan", 0)
+	backend := &FakeModemBackend{Modems: make(map[string]*FakeModem)}
+	for device, modem := range modems {
+		backend.Devices = append(backend.Devices, device)
+		backend.Modems[device.CdcDev] = modem
+	}
+	network := &fakeNetwork{
+		applied:     make(map[string]IPSettings),
+		unreachable: make(map[string]bool),
+	}
+	manager := &Manager{Log: log, Backend: backend, Network: network}
+	t.Cleanup(manager.Close)
+	return manager, backend, network
+}
+
+func testConfig(apns ...string) types.WwanConfig {
+	var profiles []types.WwanApnProfile
+	for _, apn := range apns {
+		profiles = append(profiles, types.WwanApnProfile{APN: apn})
+	}
+	return testProfilesConfig(profiles...)
+}
+
+func testProfilesConfig(profiles ...types.WwanApnProfile) types.WwanConfig {
+	return types.WwanConfig{
+		Networks: []types.WwanNetworkConfig{
+			{
+				LogicalLabel: "lte",
+				PhysAddrs:    types.WwanPhysAddrs{USB: "1:3"},
+				ApnProfiles:  profiles,
+			},
+		},
+	}
+}
+
+func TestConnect(t *testing.T) {
+	modem := newTestModem()
+	manager, _, network := newTestManager(t, map[ModemDevice]*FakeModem{testDevice: modem})
+	status := manager.ApplyConfig(testConfig(), "checksum1")
+	if status.ConfigChecksum != "checksum1" {
+		t.Errorf("unexpected config checksum: %s", status.ConfigChecksum)
+	}
+	netStatus, ok := status.LookupNetworkStatus("lte")
+	if !ok {
+		t.Fatalf("missing status for lte network: %+v", status)
+	}
+	if netStatus.ConfigError != "" || netStatus.ProbeError != "" {
+		t.Errorf("unexpected errors: %s, %s", netStatus.ConfigError, netStatus.ProbeError)
+	}
+	if netStatus.PhysAddrs != testDevice.PhysAddrs {
+		t.Errorf("unexpected phys addrs: %+v", netStatus.PhysAddrs)
+	}
+	if netStatus.Module.IMEI != modem.Module.IMEI ||
+		netStatus.Module.ControlProtocol != types.WwanCtrlProtQMI ||
+		netStatus.Module.OpMode != types.WwanOpModeConnected {
+		t.Errorf("unexpected module status: %+v", netStatus.Module)
+	}
+	if len(netStatus.SimCards) != 1 || netStatus.SimCards[0].ICCID != modem.SimCard.ICCID {
+		t.Errorf("unexpected SIM cards: %+v", netStatus.SimCards)
+	}
+	if len(netStatus.Providers) != 1 || !netStatus.Providers[0].CurrentServing ||
+		netStatus.Providers[0].PLMN != "310-180" {
+		t.Errorf("unexpected providers: %+v", netStatus.Providers)
+	}
+	if modem.ConnectedApn() != DefaultApn {
+		t.Errorf("expected connection with default APN, got %q", modem.ConnectedApn())
+	}
+	if settings := network.applied["wwan0"]; !settings.Address.Equal(testIPSettings.Address) {
+		t.Errorf("IP settings were not applied: %v", settings)
+	}
+}
+
+func TestSimStates(t *testing.T) {
+	testMatrix := map[string]struct {
+		simState       SimState
+		expectedError  string
+		expectSimCards bool
+	}{
+		"SIM card is ready": {
+			simState:       SimStateReady,
+			expectSimCards: true,
+		},
+		"SIM card is absent": {
+			simState:      SimStateAbsent,
+			expectedError: "timeout waiting for SIM card to be ready (state: absent)",
+		},
+		"SIM card is locked": {
+			simState:      SimStateLocked,
+			expectedError: "timeout waiting for SIM card to be ready (state: locked)",
+		},
+		"SIM card is not initialized": {
+			simState:      SimStateInitializing,
+			expectedError: "timeout waiting for SIM card to be ready (state: initializing)",
+		},
+	}
+	for test, tt := range testMatrix {
+		t.Logf("Running test case %s", test)
+		modem := newTestModem()
+		modem.SimState = tt.simState
+		manager, _, _ := newTestManager(t, map[ModemDevice]*FakeModem{testDevice: modem})
+		status := manager.ApplyConfig(testConfig("internet"), "")
+		netStatus, _ := status.LookupNetworkStatus("lte")
+		if netStatus.ConfigError != tt.expectedError {
+			t.Errorf("TEST CASE \"%s\" FAILED - unexpected config error: %q",
+				test, netStatus.ConfigError)
+		}
+		if tt.expectSimCards != (len(netStatus.SimCards) > 0) {
+			t.Errorf("TEST CASE \"%s\" FAILED - unexpected SIM cards: %+v",
+				test, netStatus.SimCards)
+		}
+		if tt.expectedError != "" && netStatus.ProbeError == "" {
+			t.Errorf("TEST CASE \"%s\" FAILED - expected probe error", test)
+		}
+	}
+}
+
+func TestApnFailover(t *testing.T) {
+	modem := newTestModem()
+	modem.ValidApns = []string{"backup.apn"}
+	manager, _, _ := newTestManager(t, map[ModemDevice]*FakeModem{testDevice: modem})
+	status := manager.ApplyConfig(testConfig("primary.apn", "backup.apn"), "")
+	netStatus, _ := status.LookupNetworkStatus("lte")
+	if netStatus.ConfigError != "" || netStatus.ProbeError != "" {
+		t.Errorf("unexpected errors: %s, %s", netStatus.ConfigError, netStatus.ProbeError)
+	}
+	if modem.ConnectedApn() != "backup.apn" {
+		t.Errorf("expected connection with backup APN, got %q", modem.ConnectedApn())
+	}
+	attempts := modem.ConnectAttempts()
+	if strings.Join(attempts, ",") != "primary.apn,backup.apn" {
+		t.Errorf("unexpected connection attempts: %v", attempts)
+	}
+
+	// No APN is accepted.
+	modem.Lock()
+	modem.ValidApns = nil
+	modem.Unlock()
+	status = manager.ApplyConfig(testConfig("primary.apn", "backup.apn"), "")
+	netStatus, _ = status.LookupNetworkStatus("lte")
+	if !strings.Contains(netStatus.ConfigError, "APN primary.apn") ||
+		!strings.Contains(netStatus.ConfigError, "APN backup.apn") {
+		t.Errorf("unexpected config error: %q", netStatus.ConfigError)
+	}
+	if modem.ConnectedApn() != "" {
+		t.Errorf("unexpected connection with APN %q", modem.ConnectedApn())
+	}
+}
+
+func TestStartProfile(t *testing.T) {
+	modem := newTestModem()
+	modem.ValidApns = []string{"primary.apn", "backup.apn"}
+	manager, _, _ := newTestManager(t, map[ModemDevice]*FakeModem{testDevice: modem})
+	config := testConfig("primary.apn", "backup.apn")
+	status := manager.ApplyConfig(config, "")
+	netStatus, _ := status.LookupNetworkStatus("lte")
+	if netStatus.ActiveProfile.APN != "primary.apn" || netStatus.ActiveProfileIdx != 0 {
+		t.Errorf("unexpected active profile: %d, %+v",
+			netStatus.ActiveProfileIdx, netStatus.ActiveProfile)
+	}
+	// Fail over to the backup APN.
+	config.Networks[0].StartProfile = 1
+	status = manager.ApplyConfig(config, "")
+	netStatus, _ = status.LookupNetworkStatus("lte")
+	if modem.ConnectedApn() != "backup.apn" {
+		t.Errorf("expected connection with backup APN, got %q", modem.ConnectedApn())
+	}
+	if netStatus.ActiveProfile.APN != "backup.apn" || netStatus.ActiveProfileIdx != 1 {
+		t.Errorf("unexpected active profile: %d, %+v",
+			netStatus.ActiveProfileIdx, netStatus.ActiveProfile)
+	}
+	// Wrap around to the primary APN if the backup APN is rejected.
+	modem.Lock()
+	modem.ValidApns = []string{"primary.apn"}
+	modem.Unlock()
+	status = manager.ApplyConfig(config, "")
+	netStatus, _ = status.LookupNetworkStatus("lte")
+	attempts := modem.ConnectAttempts()
+	if strings.Join(attempts, ",") != "primary.apn,backup.apn,backup.apn,primary.apn" {
+		t.Errorf("unexpected connection attempts: %v", attempts)
+	}
+	if netStatus.ConfigError != "" || netStatus.ActiveProfileIdx != 0 {
+		t.Errorf("unexpected status: %s, %d", netStatus.ConfigError,
+			netStatus.ActiveProfileIdx)
+	}
+	// Active profile is not reported without connection.
+	config.RadioSilence = true
+	status = manager.ApplyConfig(config, "")
+	netStatus, _ = status.LookupNetworkStatus("lte")
+	if netStatus.ActiveProfile.APN != "" {
+		t.Errorf("unexpected active profile: %+v", netStatus.ActiveProfile)
+	}
+}
+
+func TestSimSlotFailover(t *testing.T) {
+	modem := newTestModem()
+	modem.SimState = SimStateAbsent
+	modem.SimSlots = map[uint8]FakeSimCard{
+		2: {
+			State:     SimStateReady,
+			Card:      types.WwanSimCard{ICCID: "89014103211118510720"},
+			ValidApns: []string{"sim2.apn"},
+		},
+	}
+	manager, _, _ := newTestManager(t, map[ModemDevice]*FakeModem{testDevice: modem})
+	status := manager.ApplyConfig(testProfilesConfig(
+		types.WwanApnProfile{APN: "internet", SimSlot: 1},
+		types.WwanApnProfile{APN: "sim2.apn", SimSlot: 2}), "")
+	netStatus, _ := status.LookupNetworkStatus("lte")
+	if netStatus.ConfigError != "" {
+		t.Errorf("unexpected config error: %s", netStatus.ConfigError)
+	}
+	if modem.SimSlot() != 2 || modem.ConnectedApn() != "sim2.apn" {
+		t.Errorf("expected connection using the second SIM slot: %d, %q",
+			modem.SimSlot(), modem.ConnectedApn())
+	}
+	if netStatus.ActiveProfile.SimSlot != 2 || netStatus.ActiveProfileIdx != 1 {
+		t.Errorf("unexpected active profile: %+v", netStatus.ActiveProfile)
+	}
+	if len(netStatus.SimCards) != 1 || netStatus.SimCards[0].ICCID != "89014103211118510720" {
+		t.Errorf("unexpected SIM cards: %+v", netStatus.SimCards)
+	}
+
+	// Modem with a single SIM slot.
+	modem = newTestModem()
+	manager, _, _ = newTestManager(t, map[ModemDevice]*FakeModem{testDevice: modem})
+	status = manager.ApplyConfig(testProfilesConfig(
+		types.WwanApnProfile{APN: "internet", SimSlot: 2}), "")
+	netStatus, _ = status.LookupNetworkStatus("lte")
+	if netStatus.ConfigError != "SIM slot 2: failed to select SIM slot: operation not supported" {
+		t.Errorf("unexpected config error: %q", netStatus.ConfigError)
+	}
+}
+
+func TestApnProfileSettings(t *testing.T) {
+	modem := newTestModem()
+	modem.Provider.Roaming = true
+	modem.ValidApns = []string{"home.apn", "roaming.apn"}
+	manager, _, _ := newTestManager(t, map[ModemDevice]*FakeModem{testDevice: modem})
+	status := manager.ApplyConfig(testProfilesConfig(
+		types.WwanApnProfile{APN: "home.apn", ForbidRoaming: true},
+		types.WwanApnProfile{
+			APN:          "roaming.apn",
+			AuthProtocol: types.WwanAuthProtocolCHAP,
+			Username:     "user",
+			Password:     "secret",
+		}), "")
+	netStatus, _ := status.LookupNetworkStatus("lte")
+	if netStatus.ConfigError != "" {
+		t.Errorf("unexpected config error: %s", netStatus.ConfigError)
+	}
+	// Profile which forbids roaming is skipped.
+	attempts := modem.ConnectAttempts()
+	if strings.Join(attempts, ",") != "roaming.apn" {
+		t.Errorf("unexpected connection attempts: %v", attempts)
+	}
+	// Password is not reported.
+	if netStatus.ActiveProfile.Username != "user" || netStatus.ActiveProfile.Password != "" {
+		t.Errorf("unexpected active profile: %+v", netStatus.ActiveProfile)
+	}
+}
+
+func TestReconnectOnProbeFailure(t *testing.T) {
+	modem := newTestModem()
+	manager, _, network := newTestManager(t, map[ModemDevice]*FakeModem{testDevice: modem})
+	manager.ApplyConfig(testConfig("internet"), "")
+	if len(modem.ConnectAttempts()) != 1 {
+		t.Fatalf("unexpected connection attempts: %v", modem.ConnectAttempts())
+	}
+	// Probe succeeds - no reconnect.
+	status := manager.Probe()
+	netStatus, _ := status.LookupNetworkStatus("lte")
+	if netStatus.ProbeError != "" || len(modem.ConnectAttempts()) != 1 {
+		t.Errorf("unexpected reconnect: %s, %v", netStatus.ProbeError, modem.ConnectAttempts())
+	}
+	// Connection is lost.
+	modem.DropConnection()
+	network.Lock()
+	network.unreachable["wwan0"] = true
+	network.Unlock()
+	status = manager.Probe()
+	netStatus, _ = status.LookupNetworkStatus("lte")
+	if netStatus.ProbeError == "" {
+		t.Errorf("expected probe error")
+	}
+	if len(modem.ConnectAttempts()) != 2 || modem.ConnectedApn() != "internet" {
+		t.Errorf("expected reconnect: %v", modem.ConnectAttempts())
+	}
+}
+
+func TestSignalMetrics(t *testing.T) {
+	unmanagedDevice := ModemDevice{
+		CdcDev:    "cdc-wdm1",
+		Protocol:  types.WwanCtrlProtMBIM,
+		PhysAddrs: types.WwanPhysAddrs{Interface: "wwan1", USB: "1:4"},
+	}
+	modem := newTestModem()
+	unmanagedModem := newTestModem()
+	manager, _, _ := newTestManager(t, map[ModemDevice]*FakeModem{
+		testDevice:      modem,
+		unmanagedDevice: unmanagedModem,
+	})
+	manager.ApplyConfig(testConfig("internet"), "")
+	metrics := manager.CollectMetrics()
+	if len(metrics.Networks) != 2 {
+		t.Fatalf("unexpected metrics: %+v", metrics)
+	}
+	netMetrics, ok := metrics.LookupNetworkMetrics("lte")
+	if !ok {
+		t.Fatalf("missing metrics for lte network: %+v", metrics)
+	}
+	if netMetrics.SignalInfo != modem.SignalInfo {
+		t.Errorf("unexpected signal info: %+v", netMetrics.SignalInfo)
+	}
+	if netMetrics.PacketStats != modem.PacketStats {
+		t.Errorf("unexpected packet stats: %+v", netMetrics.PacketStats)
+	}
+	// Unmanaged modem has radio off and therefore no signal.
+	netMetrics, ok = metrics.LookupNetworkMetrics("")
+	if !ok || netMetrics.PhysAddrs != unmanagedDevice.PhysAddrs {
+		t.Fatalf("missing metrics for unmanaged modem: %+v", metrics)
+	}
+	if netMetrics.SignalInfo.RSSI != UnavailableSignalMetric {
+		t.Errorf("unexpected signal info: %+v", netMetrics.SignalInfo)
+	}
+}
+
+func TestRadioSilence(t *testing.T) {
+	modem := newTestModem()
+	manager, _, _ := newTestManager(t, map[ModemDevice]*FakeModem{testDevice: modem})
+	manager.ApplyConfig(testConfig("internet"), "")
+	if !modem.RadioOn() || modem.ConnectedApn() == "" {
+		t.Fatalf("modem is not connected")
+	}
+	config := testConfig("internet")
+	config.RadioSilence = true
+	status := manager.ApplyConfig(config, "")
+	netStatus, _ := status.LookupNetworkStatus("lte")
+	if netStatus.ConfigError != "" {
+		t.Errorf("unexpected config error: %s", netStatus.ConfigError)
+	}
+	if modem.RadioOn() || netStatus.Module.OpMode != types.WwanOpModeRadioOff {
+		t.Errorf("radio is not off: %s", netStatus.Module.OpMode)
+	}
+	// Probing does not turn the radio back on.
+	manager.Probe()
+	if modem.RadioOn() {
+		t.Errorf("radio was enabled during radio silence")
+	}
+	config.RadioSilence = false
+	status = manager.ApplyConfig(config, "")
+	netStatus, _ = status.LookupNetworkStatus("lte")
+	if !modem.RadioOn() || netStatus.Module.OpMode != types.WwanOpModeConnected {
+		t.Errorf("modem did not reconnect after radio silence: %s", netStatus.Module.OpMode)
+	}
+}
+
+func TestMissingModem(t *testing.T) {
+	manager, _, _ := newTestManager(t, map[ModemDevice]*FakeModem{testDevice: newTestModem()})
+	config := testConfig("internet")
+	config.Networks[0].PhysAddrs.USB = "2:1"
+	status := manager.ApplyConfig(config, "")
+	netStatus, _ := status.LookupNetworkStatus("lte")
+	if netStatus.ConfigError != "failed to find modem for interface=<any>, USB=2:1, PCI=<any>" {
+		t.Errorf("unexpected config error: %q", netStatus.ConfigError)
+	}
+	// The modem present is not configured and is therefore kept with radio off.
+	if len(status.Networks) != 2 || status.Networks[1].LogicalLabel != "" ||
+		status.Networks[1].Module.OpMode != types.WwanOpModeRadioOff {
+		t.Errorf("unexpected status: %+v", status.Networks)
+	}
+}
+
+func TestSwitchToMBIM(t *testing.T) {
+	modem := newTestModem()
+	modem.Module.Model = em7565Model
+	manager, backend, _ := newTestManager(t, map[ModemDevice]*FakeModem{testDevice: modem})
+	status := manager.ApplyConfig(testConfig("internet"), "")
+	if len(backend.SwitchedToMBIM) != 1 || len(status.Networks) != 0 {
+		t.Fatalf("modem was not switched to MBIM: %v, %+v",
+			backend.SwitchedToMBIM, status.Networks)
+	}
+	status = manager.ApplyConfig(testConfig("internet"), "")
+	netStatus, _ := status.LookupNetworkStatus("lte")
+	if netStatus.Module.ControlProtocol != types.WwanCtrlProtMBIM ||
+		netStatus.Module.OpMode != types.WwanOpModeConnected {
+		t.Errorf("unexpected module status: %+v", netStatus.Module)
+	}
+}
+
+func TestLocationTracking(t *testing.T) {
+	modem := newTestModem()
+	modem.Location = &types.WwanLocationInfo{Latitude: 48.15, Longitude: 17.11}
+	manager, _, _ := newTestManager(t, map[ModemDevice]*FakeModem{testDevice: modem})
+	if _, ok := manager.GetLocation(); ok {
+		t.Errorf("unexpected location info before tracking is enabled")
+	}
+	config := testConfig("internet")
+	config.Networks[0].LocationTracking = true
+	manager.ApplyConfig(config, "")
+	locInfo, ok := manager.GetLocation()
+	if !ok || locInfo.LogicalLabel != "lte" || locInfo.Latitude != 48.15 {
+		t.Errorf("unexpected location info: %+v", locInfo)
+	}
+	config.Networks[0].LocationTracking = false
+	manager.ApplyConfig(config, "")
+	if modem.IsTracking() {
+		t.Errorf("location tracking was not stopped")
+	}
+	if _, ok = manager.GetLocation(); ok {
+		t.Errorf("unexpected location info after tracking is disabled")
+	}
+}
@@ -0,0 +1,317 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// MBIM (Mobile Broadband Interface Model) control messages, as defined
+// by the USB-IF MBIM specification v1.0, start with a header:
+//
+//	message type (u32), message length (u32), transaction ID (u32)
+//
+// Commands and their completions additionally carry the fragment header,
+// the UUID of the device service, command ID, command type or status
+// and the information buffer. Variable-size fields of information buffers
+// are referenced by (offset, size) pairs, relative to the start of the buffer.
+// All integers are little-endian, strings are UTF-16LE.
+
+package wwan
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf16"
+)
+
+// MBIM message types.
+const (
+	mbimOpenMsg           = 0x00000001
+	mbimCloseMsg          = 0x00000002
+	mbimCommandMsg        = 0x00000003
+	mbimOpenDoneMsg       = 0x80000001
+	mbimCloseDoneMsg      = 0x80000002
+	mbimCommandDoneMsg    = 0x80000003
+	mbimFunctionErrorMsg  = 0x80000004
+	mbimIndicateStatusMsg = 0x80000007
+)
+
+const (
+	mbimHeaderSize         = 12
+	mbimCommandHeaderSize  = 48
+	mbimMaxControlTransfer = 4096
+	mbimCommandTypeQuery   = 0
+	mbimCommandTypeSet     = 1
+)
+
+// UUID of the Basic Connect device service (a289cc33-bcbb-8b4f-b6b0-133ec2aae6df).
+var mbimUUIDBasicConnect = []byte{
+	0xa2, 0x89, 0xcc, 0x33, 0xbc, 0xbb, 0x8b, 0x4f,
+	0xb6, 0xb0, 0x13, 0x3e, 0xc2, 0xaa, 0xe6, 0xdf}
+
+// defaultMbimTimeout : how long to wait for a command to complete.
+// Not a constant due to test usage.
+var defaultMbimTimeout = 30 * time.Second
+
+// mbimStatus is a status code of a completed command.
+type mbimStatus uint32
+
+const (
+	mbimStatusBusy                   mbimStatus = 1
+	mbimStatusFailure                mbimStatus = 2
+	mbimStatusSimNotInserted         mbimStatus = 3
+	mbimStatusBadSim                 mbimStatus = 4
+	mbimStatusPinRequired            mbimStatus = 5
+	mbimStatusNotRegistered          mbimStatus = 7
+	mbimStatusNoDeviceSupport        mbimStatus = 9
+	mbimStatusNoDataClassAvailable   mbimStatus = 11
+	mbimStatusPacketServiceDetached  mbimStatus = 12
+	mbimStatusMaxActivatedContexts   mbimStatus = 13
+	mbimStatusNotInitialized         mbimStatus = 14
+	mbimStatusContextNotActivated    mbimStatus = 16
+	mbimStatusInvalidAccessString    mbimStatus = 18
+	mbimStatusInvalidUserNamePwd     mbimStatus = 19
+	mbimStatusRadioPowerOff          mbimStatus = 20
+	mbimStatusInvalidParameters      mbimStatus = 21
+	mbimStatusOperationNotAllowed    mbimStatus = 28
+	mbimStatusFunctionNotImplemented mbimStatus = 0xFFFFFFFF
+)
+
+func (s mbimStatus) Error() string {
+	var name string
+	switch s {
+	case mbimStatusBusy:
+		name = "busy"
+	case mbimStatusFailure:
+		name = "failure"
+	case mbimStatusSimNotInserted:
+		name = "SIM not inserted"
+	case mbimStatusBadSim:
+		name = "bad SIM"
+	case mbimStatusPinRequired:
+		name = "PIN required"
+	case mbimStatusNotRegistered:
+		name = "not registered"
+	case mbimStatusNoDeviceSupport:
+		name = "no device support"
+	case mbimStatusNoDataClassAvailable:
+		name = "data class not available"
+	case mbimStatusPacketServiceDetached:
+		name = "packet service detached"
+	case mbimStatusMaxActivatedContexts:
+		name = "max activated contexts"
+	case mbimStatusNotInitialized:
+		name = "not initialized"
+	case mbimStatusContextNotActivated:
+		name = "context not activated"
+	case mbimStatusInvalidAccessString:
+		name = "invalid access string"
+	case mbimStatusInvalidUserNamePwd:
+		name = "invalid user name or password"
+	case mbimStatusRadioPowerOff:
+		name = "radio power off"
+	case mbimStatusInvalidParameters:
+		name = "invalid parameters"
+	case mbimStatusOperationNotAllowed:
+		name = "operation not allowed"
+	case mbimStatusFunctionNotImplemented:
+		name = "function not implemented"
+	default:
+		name = "unknown status"
+	}
+	return fmt.Sprintf("MBIM status %d (%s)", uint32(s), name)
+}
+
+// mbimClient sends MBIM commands of the Basic Connect service over
+// the transport and waits for their completion.
+type mbimClient struct {
+	transport Transport
+	timeout   time.Duration
+	lastTxnID uint32
+	opened    bool
+}
+
+func newMbimClient(transport Transport) *mbimClient {
+	return &mbimClient{transport: transport, timeout: defaultMbimTimeout}
+}
+
+func (c *mbimClient) nextTxnID() uint32 {
+	c.lastTxnID++
+	if c.lastTxnID == 0 {
+		c.lastTxnID = 1
+	}
+	return c.lastTxnID
+}
+
+func mbimHeader(msgType, length, txnID uint32) []byte {
+	return appendU32(appendU32(appendU32(nil, msgType), length), txnID)
+}
+
+// waitFor waits for the message of the given type and transaction ID.
+// Returned data include the header.
+func (c *mbimClient) waitFor(msgType, txnID uint32) ([]byte, error) {
+	deadline := time.Now().Add(c.timeout)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, fmt.Errorf("timeout waiting for MBIM message 0x%x", msgType)
+		}
+		data, err := c.transport.Recv(remaining)
+		if err != nil {
+			if errors.Is(err, errRecvTimeout) {
+				continue
+			}
+			return nil, err
+		}
+		r := &byteReader{data: data}
+		typ, length, txn := r.u32(), r.u32(), r.u32()
+		if r.err != nil || int(length) != len(data) || txn != txnID {
+			// Indications, garbage or late responses.
+			continue
+		}
+		switch typ {
+		case msgType:
+			return data, nil
+		case mbimFunctionErrorMsg:
+			return nil, fmt.Errorf("MBIM function error: %d", r.u32())
+		}
+	}
+}
+
+// open opens the MBIM function, must be called before any command.
+func (c *mbimClient) open() error {
+	txnID := c.nextTxnID()
+	msg := mbimHeader(mbimOpenMsg, mbimHeaderSize+4, txnID)
+	msg = appendU32(msg, mbimMaxControlTransfer)
+	if err := c.transport.Send(msg); err != nil {
+		return err
+	}
+	resp, err := c.waitFor(mbimOpenDoneMsg, txnID)
+	if err != nil {
+		return fmt.Errorf("failed to open MBIM function: %w", err)
+	}
+	r := &byteReader{data: resp[mbimHeaderSize:]}
+	if status := mbimStatus(r.u32()); r.err != nil || status != 0 {
+		return fmt.Errorf("failed to open MBIM function: %v", status)
+	}
+	c.opened = true
+	return nil
+}
+
+func (c *mbimClient) close() error {
+	if !c.opened {
+		return nil
+	}
+	c.opened = false
+	txnID := c.nextTxnID()
+	if err := c.transport.Send(mbimHeader(mbimCloseMsg, mbimHeaderSize, txnID)); err != nil {
+		return err
+	}
+	_, err := c.waitFor(mbimCloseDoneMsg, txnID)
+	return err
+}
+
+// command sends a query (set=false) or set command of the Basic Connect
+// service and returns the information buffer of the completion.
+func (c *mbimClient) command(cid uint32, set bool, buffer []byte) ([]byte, error) {
+	if !c.opened {
+		if err := c.open(); err != nil {
+			return nil, err
+		}
+	}
+	cmdType := uint32(mbimCommandTypeQuery)
+	if set {
+		cmdType = mbimCommandTypeSet
+	}
+	txnID := c.nextTxnID()
+	msg := mbimHeader(mbimCommandMsg, uint32(mbimCommandHeaderSize+len(buffer)), txnID)
+	msg = appendU32(msg, 1) // total fragments
+	msg = appendU32(msg, 0) // current fragment
+	msg = append(msg, mbimUUIDBasicConnect...)
+	msg = appendU32(msg, cid)
+	msg = appendU32(msg, cmdType)
+	msg = appendU32(msg, uint32(len(buffer)))
+	msg = append(msg, buffer...)
+	if err := c.transport.Send(msg); err != nil {
+		return nil, err
+	}
+	resp, err := c.waitFor(mbimCommandDoneMsg, txnID)
+	if err != nil {
+		return nil, err
+	}
+	r := &byteReader{data: resp[mbimHeaderSize:]}
+	r.bytes(8)  // fragment header
+	r.bytes(16) // service UUID
+	respCid, status := r.u32(), mbimStatus(r.u32())
+	infoBuffer := r.bytes(int(r.u32()))
+	if r.err != nil {
+		return nil, fmt.Errorf("malformed MBIM command completion: %w", r.err)
+	}
+	if respCid != cid {
+		return nil, fmt.Errorf("MBIM command completion for CID %d instead of %d",
+			respCid, cid)
+	}
+	if status != 0 {
+		return infoBuffer, status
+	}
+	return infoBuffer, nil
+}
+
+// mbimString decodes UTF-16LE string referenced by (offset, size)
+// located at fieldOffset of the information buffer.
+func mbimString(buffer []byte, fieldOffset int) string {
+	r := &byteReader{data: buffer, off: fieldOffset}
+	offset, size := int(r.u32()), int(r.u32())
+	if r.err != nil || size == 0 || offset+size > len(buffer) {
+		return ""
+	}
+	r = &byteReader{data: buffer[offset : offset+size]}
+	var chars []uint16
+	for i := 0; i < size/2; i++ {
+		chars = append(chars, r.u16())
+	}
+	return string(utf16.Decode(chars))
+}
+
+// mbimBufferBuilder builds information buffers with a fixed-size part
+// followed by variable-size data.
+type mbimBufferBuilder struct {
+	fixed []byte
+	data  []byte
+	// offsets of (offset, size) pairs in fixed, which reference data
+	// and have to be shifted by the final size of the fixed part.
+	refs []int
+}
+
+func (b *mbimBufferBuilder) u32(v uint32) {
+	b.fixed = appendU32(b.fixed, v)
+}
+
+func (b *mbimBufferBuilder) raw(v []byte) {
+	b.fixed = append(b.fixed, v...)
+}
+
+func (b *mbimBufferBuilder) str(s string) {
+	if s == "" {
+		b.u32(0)
+		b.u32(0)
+		return
+	}
+	var encoded []byte
+	for _, c := range utf16.Encode([]rune(s)) {
+		encoded = appendU16(encoded, c)
+	}
+	b.refs = append(b.refs, len(b.fixed))
+	b.u32(uint32(len(b.data)))
+	b.u32(uint32(len(encoded)))
+	b.data = append(b.data, encoded...)
+	for len(b.data)%4 != 0 {
+		b.data = append(b.data, 0)
+	}
+}
+
+func (b *mbimBufferBuilder) bytes() []byte {
+	buffer := append([]byte{}, b.fixed...)
+	for _, ref := range b.refs {
+		offset := (&byteReader{data: buffer, off: ref}).u32()
+		copy(buffer[ref:], appendU32(nil, offset+uint32(len(b.fixed))))
+	}
+	return append(buffer, b.data...)
+}
@@ -0,0 +1,423 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package wwan
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+// Basic Connect commands used by mbimModem.
+const (
+	mbimCidDeviceCaps            = 1
+	mbimCidSubscriberReadyStatus = 2
+	mbimCidRadioState            = 3
+	mbimCidRegisterState         = 9
+	mbimCidPacketService         = 10
+	mbimCidSignalState           = 11
+	mbimCidConnect               = 12
+	mbimCidIPConfiguration       = 15
+	mbimCidPacketStatistics      = 18
+)
+
+// Subscriber ready states.
+const (
+	mbimReadyStateNotInitialized = 0
+	mbimReadyStateInitialized    = 1
+	mbimReadyStateSimNotInserted = 2
+	mbimReadyStateDeviceLocked   = 6
+)
+
+// Register states.
+const (
+	mbimRegisterStateHome    = 3
+	mbimRegisterStateRoaming = 4
+	mbimRegisterStatePartner = 5
+)
+
+const (
+	mbimRadioOff                = 0
+	mbimRadioOn                 = 1
+	mbimActivationStateActive   = 1
+	mbimActivationCmdDeactivate = 0
+	mbimActivationCmdActivate   = 1
+	mbimPacketServiceAttach     = 0
+	mbimRssiUnknown             = 99
+	mbimIPv4ConfigAddress       = 0x1
+	mbimIPv4ConfigGateway       = 0x2
+	mbimIPv4ConfigDNS           = 0x4
+	mbimIPv4ConfigMTU           = 0x8
+	mbimIPConfigurationSize     = 60
+)
+
+// Authentication protocols and context IP types.
+const (
+	mbimAuthProtocolNone = 0
+	mbimAuthProtocolPAP  = 1
+	mbimAuthProtocolCHAP = 2
+	mbimIPTypeDefault    = 0
+	mbimIPTypeIPv4       = 1
+	mbimIPTypeIPv6       = 2
+	mbimIPTypeIPv4AndV6  = 3
+)
+
+// UUID of the Internet context type (7e5e2a7e-4e6f-7272-736b-656e7e5e2a7e).
+var mbimUUIDContextInternet = []byte{
+	0x7e, 0x5e, 0x2a, 0x7e, 0x4e, 0x6f, 0x72, 0x72,
+	0x73, 0x6b, 0x65, 0x6e, 0x7e, 0x5e, 0x2a, 0x7e}
+
+// mbimModem implements Modem using the MBIM protocol.
+// The data connection always uses session 0.
+type mbimModem struct {
+	client *mbimClient
+}
+
+// NewMbimModem returns Modem controlled using MBIM over the given transport.
+func NewMbimModem(transport Transport) Modem {
+	return &mbimModem{client: newMbimClient(transport)}
+}
+
+// GetModuleInfo returns IMEI, model and revision of the modem.
+func (m *mbimModem) GetModuleInfo() (module types.WwanCellModule, err error) {
+	module.ControlProtocol = types.WwanCtrlProtMBIM
+	caps, err := m.client.command(mbimCidDeviceCaps, false, nil)
+	if err != nil {
+		return module, fmt.Errorf("failed to query device caps: %w", err)
+	}
+	// Device ID, firmware info and hardware info follow 8 fixed
+	// u32 fields and the custom data class.
+	module.IMEI = mbimString(caps, 40)
+	module.Revision = mbimString(caps, 48)
+	module.Model = mbimString(caps, 56)
+	return module, nil
+}
+
+// GetOpMode returns the operating mode of the modem.
+// MBIM does not allow to tell if the modem is offline.
+func (m *mbimModem) GetOpMode() (types.WwanOpMode, error) {
+	state, err := m.client.command(mbimCidRadioState, false, nil)
+	if err != nil {
+		return types.WwanOpModeUnspecified, fmt.Errorf("failed to query radio state: %w", err)
+	}
+	r := &byteReader{data: state}
+	hwState, swState := r.u32(), r.u32()
+	if r.err != nil {
+		return types.WwanOpModeUnspecified, fmt.Errorf("malformed radio state: %w", r.err)
+	}
+	if hwState == mbimRadioOff || swState == mbimRadioOff {
+		return types.WwanOpModeRadioOff, nil
+	}
+	connected, err := m.IsConnected()
+	if err != nil {
+		return types.WwanOpModeUnspecified, err
+	}
+	if connected {
+		return types.WwanOpModeConnected, nil
+	}
+	return types.WwanOpModeOnline, nil
+}
+
+// SetRadio enables or disables radio transmission.
+func (m *mbimModem) SetRadio(on bool) error {
+	state := uint32(mbimRadioOff)
+	if on {
+		state = mbimRadioOn
+	}
+	if _, err := m.client.command(mbimCidRadioState, true, appendU32(nil, state)); err != nil {
+		return fmt.Errorf("failed to set radio state: %w", err)
+	}
+	return nil
+}
+
+// GetSimStatus returns the state of the SIM card.
+func (m *mbimModem) GetSimStatus() (status SimStatus, err error) {
+	ready, err := m.client.command(mbimCidSubscriberReadyStatus, false, nil)
+	if err != nil {
+		var mbimErr mbimStatus
+		if errors.As(err, &mbimErr) {
+			switch mbimErr {
+			case mbimStatusSimNotInserted:
+				status.State = SimStateAbsent
+				return status, nil
+			case mbimStatusPinRequired:
+				status.State = SimStateLocked
+				return status, nil
+			case mbimStatusBadSim:
+				status.State = SimStateError
+				return status, nil
+			case mbimStatusNotInitialized:
+				status.State = SimStateInitializing
+				return status, nil
+			}
+		}
+		return status, fmt.Errorf("failed to query subscriber ready status: %w", err)
+	}
+	r := &byteReader{data: ready}
+	state := r.u32()
+	if r.err != nil {
+		return status, fmt.Errorf("malformed subscriber ready status: %w", r.err)
+	}
+	switch state {
+	case mbimReadyStateInitialized:
+		status.State = SimStateReady
+		status.Card.IMSI = mbimString(ready, 4)
+		// Remove padding that modems may add.
+		status.Card.ICCID = strings.TrimRight(mbimString(ready, 12), "Ff")
+	case mbimReadyStateNotInitialized:
+		status.State = SimStateInitializing
+	case mbimReadyStateSimNotInserted:
+		status.State = SimStateAbsent
+	case mbimReadyStateDeviceLocked:
+		status.State = SimStateLocked
+	default:
+		status.State = SimStateError
+	}
+	return status, nil
+}
+
+// GetRegistration returns the network registration state and the serving provider.
+func (m *mbimModem) GetRegistration() (reg Registration, err error) {
+	state, err := m.client.command(mbimCidRegisterState, false, nil)
+	if err != nil {
+		return reg, fmt.Errorf("failed to query register state: %w", err)
+	}
+	r := &byteReader{data: state}
+	r.u32() // network error
+	regState := r.u32()
+	if r.err != nil {
+		return reg, fmt.Errorf("malformed register state: %w", r.err)
+	}
+	switch regState {
+	case mbimRegisterStateHome, mbimRegisterStateRoaming, mbimRegisterStatePartner:
+		reg.Registered = true
+	default:
+		return reg, nil
+	}
+	reg.Provider.CurrentServing = true
+	reg.Provider.Roaming = regState != mbimRegisterStateHome
+	if providerID := mbimString(state, 20); len(providerID) > 3 {
+		// Put dash between MCC and MNC.
+		reg.Provider.PLMN = providerID[:3] + "-" + providerID[3:]
+	}
+	reg.Provider.Description = mbimString(state, 28)
+	return reg, nil
+}
+
+func (m *mbimModem) connectBuffer(activate bool, profile types.WwanApnProfile) []byte {
+	var b mbimBufferBuilder
+	b.u32(0) // session ID
+	if activate {
+		b.u32(mbimActivationCmdActivate)
+	} else {
+		b.u32(mbimActivationCmdDeactivate)
+	}
+	authProtocol := uint32(mbimAuthProtocolNone)
+	switch profile.AuthProtocol {
+	case types.WwanAuthProtocolPAP:
+		authProtocol = mbimAuthProtocolPAP
+	case types.WwanAuthProtocolCHAP, types.WwanAuthProtocolPAPAndCHAP:
+		// MBIM does not allow to offer both, prefer the more secure one.
+		authProtocol = mbimAuthProtocolCHAP
+	}
+	ipType := uint32(mbimIPTypeDefault)
+	switch profile.IPType {
+	case types.WwanIPTypeIPv4:
+		ipType = mbimIPTypeIPv4
+	case types.WwanIPTypeIPv6:
+		ipType = mbimIPTypeIPv6
+	case types.WwanIPTypeIPv4AndIPv6:
+		ipType = mbimIPTypeIPv4AndV6
+	}
+	b.str(profile.APN)
+	if authProtocol != mbimAuthProtocolNone {
+		b.str(profile.Username)
+		b.str(profile.Password)
+	} else {
+		b.str("")
+		b.str("")
+	}
+	b.u32(0) // no compression
+	b.u32(authProtocol)
+	b.u32(ipType)
+	b.raw(mbimUUIDContextInternet)
+	return b.bytes()
+}
+
+// SelectSimSlot is not supported, slot mapping requires Microsoft
+// extensions of MBIM, which are not implemented.
+func (m *mbimModem) SelectSimSlot(slot uint8) error {
+	return ErrNotSupported
+}
+
+// Connect attaches to the packet service and activates the Internet
+// context with the given APN profile.
+func (m *mbimModem) Connect(profile types.WwanApnProfile) error {
+	apn := profile.APN
+	_, err := m.client.command(mbimCidPacketService, true,
+		appendU32(nil, mbimPacketServiceAttach))
+	if err != nil {
+		return fmt.Errorf("failed to attach packet service: %w", err)
+	}
+	resp, err := m.client.command(mbimCidConnect, true, m.connectBuffer(true, profile))
+	if err != nil {
+		return fmt.Errorf("failed to connect with APN %s: %w", apn, err)
+	}
+	r := &byteReader{data: resp}
+	r.u32() // session ID
+	if state := r.u32(); r.err == nil && state != mbimActivationStateActive {
+		return fmt.Errorf("failed to connect with APN %s: activation state %d", apn, state)
+	}
+	return nil
+}
+
+// Disconnect deactivates the Internet context.
+func (m *mbimModem) Disconnect() error {
+	_, err := m.client.command(mbimCidConnect, true, m.connectBuffer(false, types.WwanApnProfile{}))
+	if err != nil && !errors.Is(err, mbimStatusContextNotActivated) {
+		return fmt.Errorf("failed to disconnect: %w", err)
+	}
+	return nil
+}
+
+// IsConnected returns true if the Internet context is activated.
+func (m *mbimModem) IsConnected() (bool, error) {
+	resp, err := m.client.command(mbimCidConnect, false, m.connectBuffer(false, types.WwanApnProfile{}))
+	if err != nil {
+		if errors.Is(err, mbimStatusContextNotActivated) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to query connection state: %w", err)
+	}
+	r := &byteReader{data: resp}
+	r.u32() // session ID
+	state := r.u32()
+	if r.err != nil {
+		return false, fmt.Errorf("malformed connection state: %w", r.err)
+	}
+	return state == mbimActivationStateActive, nil
+}
+
+// GetIPSettings returns IPv4 configuration of the data connection.
+func (m *mbimModem) GetIPSettings() (settings IPSettings, err error) {
+	resp, err := m.client.command(mbimCidIPConfiguration, false,
+		make([]byte, mbimIPConfigurationSize))
+	if err != nil {
+		return settings, fmt.Errorf("failed to query IP configuration: %w", err)
+	}
+	return parseMbimIPConfiguration(resp)
+}
+
+func parseMbimIPConfiguration(resp []byte) (settings IPSettings, err error) {
+	r := &byteReader{data: resp}
+	r.u32() // session ID
+	available := r.u32()
+	r.u32() // IPv6 configuration available
+	addrCount, addrOffset := r.u32(), int(r.u32())
+	r.bytes(8) // IPv6 addresses
+	gwOffset := int(r.u32())
+	r.u32() // IPv6 gateway
+	dnsCount, dnsOffset := r.u32(), int(r.u32())
+	r.bytes(8) // IPv6 DNS servers
+	mtu := r.u32()
+	if r.err != nil {
+		return settings, fmt.Errorf("malformed IP configuration: %w", r.err)
+	}
+	ipAt := func(offset int) net.IP {
+		if offset <= 0 || offset+4 > len(resp) {
+			return nil
+		}
+		return net.IPv4(resp[offset], resp[offset+1], resp[offset+2], resp[offset+3]).To4()
+	}
+	if available&mbimIPv4ConfigAddress != 0 && addrCount > 0 {
+		// The first address element: on-link prefix length and the address.
+		r = &byteReader{data: resp, off: addrOffset}
+		prefixLen := r.u32()
+		if r.err == nil && prefixLen <= 32 {
+			settings.Mask = net.CIDRMask(int(prefixLen), 32)
+			settings.Address = ipAt(addrOffset + 4)
+		}
+	}
+	if available&mbimIPv4ConfigGateway != 0 {
+		settings.Gateway = ipAt(gwOffset)
+	}
+	if available&mbimIPv4ConfigDNS != 0 {
+		for i := 0; i < int(dnsCount); i++ {
+			if dns := ipAt(dnsOffset + 4*i); dns != nil {
+				settings.DNS = append(settings.DNS, dns)
+			}
+		}
+	}
+	if available&mbimIPv4ConfigMTU != 0 {
+		settings.MTU = mtu
+	}
+	return settings, nil
+}
+
+// GetSignalInfo returns signal strength. MBIM only reports RSSI.
+func (m *mbimModem) GetSignalInfo() (types.WwanSignalInfo, error) {
+	info := types.WwanSignalInfo{
+		RSSI: UnavailableSignalMetric,
+		RSRQ: UnavailableSignalMetric,
+		RSRP: UnavailableSignalMetric,
+		SNR:  UnavailableSignalMetric,
+	}
+	resp, err := m.client.command(mbimCidSignalState, false, nil)
+	if err != nil {
+		return info, fmt.Errorf("failed to query signal state: %w", err)
+	}
+	r := &byteReader{data: resp}
+	rssi := r.u32()
+	if r.err == nil && rssi != mbimRssiUnknown {
+		// See table 10-58 (MBIM_SIGNAL_STATE_INFO) of the MBIM specification.
+		info.RSSI = -113 + 2*int32(rssi)
+	}
+	return info, nil
+}
+
+// GetPacketStats returns packet statistics. Errors are counted as drops.
+func (m *mbimModem) GetPacketStats() (stats types.WwanPacketStats, err error) {
+	resp, err := m.client.command(mbimCidPacketStatistics, false, nil)
+	if err != nil {
+		return stats, fmt.Errorf("failed to query packet statistics: %w", err)
+	}
+	r := &byteReader{data: resp}
+	inDiscards, inErrors := r.u32(), r.u32()
+	stats.RxBytes, stats.RxPackets = r.u64(), r.u64()
+	stats.TxBytes, stats.TxPackets = r.u64(), r.u64()
+	outErrors, outDiscards := r.u32(), r.u32()
+	if r.err != nil {
+		return types.WwanPacketStats{}, fmt.Errorf("malformed packet statistics: %w", r.err)
+	}
+	stats.RxDrops = uint64(inDiscards) + uint64(inErrors)
+	stats.TxDrops = uint64(outDiscards) + uint64(outErrors)
+	return stats, nil
+}
+
+// StartLocationTracking is not supported with MBIM.
+func (m *mbimModem) StartLocationTracking() error {
+	return ErrNotSupported
+}
+
+// StopLocationTracking is not supported with MBIM.
+func (m *mbimModem) StopLocationTracking() error {
+	return ErrNotSupported
+}
+
+// GetLocation is not supported with MBIM.
+func (m *mbimModem) GetLocation() (types.WwanLocationInfo, bool, error) {
+	return types.WwanLocationInfo{}, false, ErrNotSupported
+}
+
+// Close closes the MBIM function and the transport.
+func (m *mbimModem) Close() error {
+	err := m.client.close()
+	if closeErr := m.client.transport.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package wwan manages cellular modems: it applies WwanConfig submitted
+// by nim and produces WwanStatus, WwanMetrics and WwanLocationInfo.
+// Modems are controlled using QMI or MBIM, spoken directly over the control
+// device of the modem (/dev/cdc-wdmX) through a pluggable Transport.
+package wwan
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+// ErrNotSupported is returned by Modem when the operation is not
+// supported by the control protocol or by the modem itself.
+var ErrNotSupported = errors.New("operation not supported")
+
+// Modem is a control connection to a cellular modem.
+// Implementations are not expected to be safe for concurrent use.
+type Modem interface {
+	// GetModuleInfo returns IMEI, model and firmware revision of the modem.
+	// OpMode is not filled in, use GetOpMode.
+	GetModuleInfo() (types.WwanCellModule, error)
+	// GetOpMode returns the operating mode of the modem.
+	GetOpMode() (types.WwanOpMode, error)
+	// SetRadio enables or disables radio transmission.
+	SetRadio(on bool) error
+	// GetSimStatus returns the state of the SIM card and its identity
+	// (if it can be read).
+	GetSimStatus() (SimStatus, error)
+	// GetRegistration returns the network registration state.
+	GetRegistration() (Registration, error)
+	// SelectSimSlot switches the modem to the SIM card inserted in the given
+	// slot (numbered from 1).
+	SelectSimSlot(slot uint8) error
+	// Connect starts a data connection using the given APN profile.
+	// SIM slot and roaming restriction of the profile are handled by Manager.
+	Connect(profile types.WwanApnProfile) error
+	// Disconnect stops the data connection, if any.
+	Disconnect() error
+	// IsConnected returns true if the data connection is established.
+	IsConnected() (bool, error)
+	// GetIPSettings returns IP configuration of the data connection.
+	GetIPSettings() (IPSettings, error)
+	// GetSignalInfo returns signal strength information.
+	// Unavailable metrics are set to UnavailableSignalMetric.
+	GetSignalInfo() (types.WwanSignalInfo, error)
+	// GetPacketStats returns packet statistics of the data connection.
+	GetPacketStats() (types.WwanPacketStats, error)
+	// StartLocationTracking starts periodic location reports from the GNSS
+	// receiver of the modem.
+	StartLocationTracking() error
+	// StopLocationTracking stops location reports.
+	StopLocationTracking() error
+	// GetLocation returns the most recent location report, if there is
+	// any new since the last call.
+	GetLocation() (types.WwanLocationInfo, bool, error)
+	// Close releases resources allocated in the modem and closes the transport.
+	Close() error
+}
+
+// UnavailableSignalMetric represents unspecified/unavailable signal metric.
+const UnavailableSignalMetric = int32(0x7FFFFFFF)
+
+// SimState : state of a SIM card.
+type SimState uint8
+
+const (
+	// SimStateUnknown : state of the SIM card is not known
+	SimStateUnknown SimState = iota
+	// SimStateAbsent : SIM card is not inserted
+	SimStateAbsent
+	// SimStateInitializing : SIM card is present but not yet initialized
+	SimStateInitializing
+	// SimStateLocked : SIM card requires PIN or PUK
+	SimStateLocked
+	// SimStateReady : SIM card is ready to use
+	SimStateReady
+	// SimStateError : SIM card is unusable
+	SimStateError
+)
+
+// String returns human-readable description of the SIM state.
+func (s SimState) String() string {
+	switch s {
+	case SimStateAbsent:
+		return "absent"
+	case SimStateInitializing:
+		return "initializing"
+	case SimStateLocked:
+		return "locked"
+	case SimStateReady:
+		return "ready"
+	case SimStateError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// SimStatus : state and identity of a SIM card.
+type SimStatus struct {
+	State SimState
+	// Card is filled in only if the SIM card is ready.
+	Card types.WwanSimCard
+}
+
+// Registration : network registration state of a modem.
+type Registration struct {
+	Registered bool
+	// Provider is the current serving provider, valid if Registered is true.
+	Provider types.WwanProvider
+}
+
+// IPSettings : IP configuration of a data connection.
+type IPSettings struct {
+	Address net.IP
+	Mask    net.IPMask
+	Gateway net.IP
+	DNS     []net.IP
+	MTU     uint32
+}
+
+// Valid returns true if the settings contain IPv4 address.
+func (s IPSettings) Valid() bool {
+	return s.Address != nil && s.Address.To4() != nil && !s.Address.IsUnspecified()
+}
+
+// String describes IP settings.
+func (s IPSettings) String() string {
+	return fmt.Sprintf("address=%v, mask=%v, gateway=%v, dns=%v, mtu=%d",
+		s.Address, net.IP(s.Mask), s.Gateway, s.DNS, s.MTU)
+}
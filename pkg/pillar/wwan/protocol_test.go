@@ -0,0 +1,235 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package wwan
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+// scriptedTransport answers every sent message using the handler.
+type scriptedTransport struct {
+	handler func(msg []byte) [][]byte
+	queue   [][]byte
+}
+
+func (t *scriptedTransport) Send(msg []byte) error {
+	t.queue = append(t.queue, t.handler(msg)...)
+	return nil
+}
+
+func (t *scriptedTransport) Recv(timeout time.Duration) ([]byte, error) {
+	if len(t.queue) == 0 {
+		return nil, errRecvTimeout
+	}
+	msg := t.queue[0]
+	t.queue = t.queue[1:]
+	return msg, nil
+}
+
+func (t *scriptedTransport) Close() error {
+	return nil
+}
+
+func qmiResult(err qmiError) qmiTLV {
+	result := uint16(0)
+	if err != 0 {
+		result = 1
+	}
+	return qmiTLV{typ: qmiTLVResult, value: appendU16(appendU16(nil, result), uint16(err))}
+}
+
+// newQmiTestModem returns QMI modem answering requests with the given responses
+// (key = message ID). Client IDs are allocated by the simulated CTL service.
+func newQmiTestModem(t *testing.T, responses map[uint16][]qmiTLV) Modem {
+	defaultQmiTimeout = 100 * time.Millisecond
+	transport := &scriptedTransport{}
+	transport.handler = func(data []byte) [][]byte {
+		req, err := decodeQmiMessage(data)
+		if err != nil {
+			t.Fatalf("failed to decode QMI request: %v", err)
+		}
+		resp := qmiMessage{
+			service:  req.service,
+			clientID: req.clientID,
+			txnID:    req.txnID,
+			msgID:    req.msgID,
+			flags:    qmiFlagResponse,
+		}
+		if req.isCtl() {
+			resp.flags = qmiCtlFlagResponse
+			service, _ := req.tlv(0x01)
+			resp.tlvs = []qmiTLV{qmiResult(0), {typ: 0x01, value: []byte{service[0], 7}}}
+			return [][]byte{resp.encode()}
+		}
+		if req.clientID != 7 {
+			t.Errorf("unexpected client ID: %d", req.clientID)
+		}
+		// Unrelated indication received before the response should be skipped.
+		ind := qmiMessage{service: req.service, clientID: req.clientID,
+			flags: qmiFlagIndication, msgID: 0x0001}
+		tlvs, ok := responses[req.msgID]
+		if !ok {
+			tlvs = []qmiTLV{qmiResult(qmiErrInternal)}
+		}
+		resp.tlvs = tlvs
+		return [][]byte{ind.encode(), resp.encode()}
+	}
+	return NewQmiModem(transport, "wwan0")
+}
+
+func TestQmiCodec(t *testing.T) {
+	msg := qmiMessage{
+		service:  qmiServiceNAS,
+		clientID: 3,
+		flags:    qmiFlagResponse,
+		txnID:    0x1234,
+		msgID:    qmiNasGetSignalInfo,
+		tlvs: []qmiTLV{
+			qmiResult(qmiErrNoEffect),
+			{typ: 0x10, value: []byte{1, 2, 3}},
+		},
+	}
+	decoded, err := decodeQmiMessage(msg.encode())
+	if err != nil {
+		t.Fatalf("failed to decode QMI message: %v", err)
+	}
+	if decoded.service != msg.service || decoded.clientID != msg.clientID ||
+		decoded.txnID != msg.txnID || decoded.msgID != msg.msgID ||
+		len(decoded.tlvs) != 2 || !decoded.isResponse() {
+		t.Errorf("unexpected decoded message: %+v", decoded)
+	}
+	var qmiErr qmiError
+	if err = decoded.result(); !errors.As(err, &qmiErr) || qmiErr != qmiErrNoEffect {
+		t.Errorf("unexpected result: %v", err)
+	}
+	if _, err = decodeQmiMessage(msg.encode()[:10]); err == nil {
+		t.Errorf("truncated message was decoded")
+	}
+}
+
+func TestQmiModem(t *testing.T) {
+	lteSignal := []byte{0xBD}                             // RSSI -67
+	lteSignal = append(lteSignal, 0xF5)                   // RSRQ -11
+	lteSignal = appendU16(lteSignal, uint16(0xFFFF-98+1)) // RSRP -98
+	lteSignal = appendU16(lteSignal, 42)                  // SNR 4.2
+	modem := newQmiTestModem(t, map[uint16][]qmiTLV{
+		qmiDmsGetIDs:        {qmiResult(0), {typ: 0x11, value: []byte("353533101772021")}},
+		qmiDmsGetModel:      {qmiResult(0), {typ: 0x01, value: []byte("EG25")}},
+		qmiDmsGetRevision:   {qmiResult(0), {typ: 0x01, value: []byte("EG25GGBR07A08M2G")}},
+		qmiNasGetSignalInfo: {qmiResult(0), {typ: 0x14, value: lteSignal}},
+		qmiUimSwitchSlot:    {qmiResult(qmiErrNoEffect)},
+	})
+	defer modem.Close()
+	module, err := modem.GetModuleInfo()
+	if err != nil {
+		t.Fatalf("failed to get module info: %v", err)
+	}
+	if module.IMEI != "353533101772021" || module.Model != "EG25" ||
+		module.Revision != "EG25GGBR07A08M2G" ||
+		module.ControlProtocol != types.WwanCtrlProtQMI {
+		t.Errorf("unexpected module info: %+v", module)
+	}
+	signal, err := modem.GetSignalInfo()
+	if err != nil {
+		t.Fatalf("failed to get signal info: %v", err)
+	}
+	expected := types.WwanSignalInfo{RSSI: -67, RSRQ: -11, RSRP: -98, SNR: 4}
+	if signal != expected {
+		t.Errorf("unexpected signal info: %+v", signal)
+	}
+	// Error reported by the modem.
+	if _, err = modem.GetPacketStats(); err == nil {
+		t.Errorf("expected error from GetPacketStats")
+	}
+	// SIM slot is already selected.
+	if err = modem.SelectSimSlot(1); err != nil {
+		t.Errorf("unexpected error from SelectSimSlot: %v", err)
+	}
+}
+
+// newMbimTestModem returns MBIM modem answering commands with the given
+// information buffers (key = CID).
+func newMbimTestModem(t *testing.T, responses map[uint32][]byte) Modem {
+	defaultMbimTimeout = 100 * time.Millisecond
+	transport := &scriptedTransport{}
+	transport.handler = func(data []byte) [][]byte {
+		r := &byteReader{data: data}
+		msgType, _, txnID := r.u32(), r.u32(), r.u32()
+		switch msgType {
+		case mbimOpenMsg:
+			return [][]byte{appendU32(mbimHeader(mbimOpenDoneMsg, mbimHeaderSize+4, txnID), 0)}
+		case mbimCloseMsg:
+			return [][]byte{appendU32(mbimHeader(mbimCloseDoneMsg, mbimHeaderSize+4, txnID), 0)}
+		case mbimCommandMsg:
+			r.bytes(8 + 16)
+			cid := r.u32()
+			status := uint32(0)
+			buffer, ok := responses[cid]
+			if !ok {
+				status = uint32(mbimStatusNoDeviceSupport)
+			}
+			resp := mbimHeader(mbimCommandDoneMsg,
+				uint32(mbimCommandHeaderSize+len(buffer)), txnID)
+			resp = appendU32(appendU32(resp, 1), 0)
+			resp = append(resp, mbimUUIDBasicConnect...)
+			resp = appendU32(appendU32(resp, cid), status)
+			resp = appendU32(resp, uint32(len(buffer)))
+			return [][]byte{append(resp, buffer...)}
+		}
+		t.Fatalf("unexpected MBIM message type: 0x%x", msgType)
+		return nil
+	}
+	return NewMbimModem(transport)
+}
+
+func TestMbimModem(t *testing.T) {
+	var caps mbimBufferBuilder
+	for i := 0; i < 10; i++ {
+		caps.u32(0)
+	}
+	caps.str("353533101772021")
+	caps.str("SWI9X50C_01.08.04.00")
+	caps.str("EM7565")
+	modem := newMbimTestModem(t, map[uint32][]byte{
+		mbimCidDeviceCaps:       caps.bytes(),
+		mbimCidSignalState:      appendU32(appendU32(nil, 23), 0), // RSSI -67
+		mbimCidRadioState:       appendU32(appendU32(nil, mbimRadioOn), mbimRadioOff),
+		mbimCidPacketStatistics: nil,
+	})
+	defer modem.Close()
+	module, err := modem.GetModuleInfo()
+	if err != nil {
+		t.Fatalf("failed to get module info: %v", err)
+	}
+	if module.IMEI != "353533101772021" || module.Model != "EM7565" ||
+		module.Revision != "SWI9X50C_01.08.04.00" {
+		t.Errorf("unexpected module info: %+v", module)
+	}
+	signal, err := modem.GetSignalInfo()
+	if err != nil {
+		t.Fatalf("failed to get signal info: %v", err)
+	}
+	if signal.RSSI != -67 || signal.RSRP != UnavailableSignalMetric {
+		t.Errorf("unexpected signal info: %+v", signal)
+	}
+	opMode, err := modem.GetOpMode()
+	if err != nil || opMode != types.WwanOpModeRadioOff {
+		t.Errorf("unexpected operating mode: %s (err: %v)", opMode, err)
+	}
+	var status mbimStatus
+	if _, err = modem.GetRegistration(); !errors.As(err, &status) ||
+		status != mbimStatusNoDeviceSupport {
+		t.Errorf("unexpected error from GetRegistration: %v", err)
+	}
+	if _, _, err = modem.GetLocation(); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("unexpected error from GetLocation: %v", err)
+	}
+	if err = modem.SelectSimSlot(2); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("unexpected error from SelectSimSlot: %v", err)
+	}
+}
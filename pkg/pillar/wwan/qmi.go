@@ -0,0 +1,353 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// QMI (Qualcomm MSM Interface) messages are carried in QMUX frames:
+//
+//	QMUX header: I/F type (0x01), length (u16), control flags, service, client ID
+//	SDU header:  control flags, transaction ID (u8 for CTL, u16 otherwise),
+//	             message ID (u16), length of TLVs (u16)
+//	TLVs:        type (u8), length (u16), value
+//
+// All integers are little-endian.
+
+package wwan
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	qmuxIfType     = 0x01
+	qmuxHeaderSize = 6
+)
+
+// QMI services used by the wwan service.
+const (
+	qmiServiceCTL = 0x00
+	qmiServiceWDS = 0x01
+	qmiServiceDMS = 0x02
+	qmiServiceNAS = 0x03
+	qmiServiceUIM = 0x0B
+	qmiServiceLOC = 0x10
+)
+
+// SDU control flags.
+const (
+	qmiCtlFlagResponse   = 0x01
+	qmiCtlFlagIndication = 0x02
+	qmiFlagResponse      = 0x02
+	qmiFlagIndication    = 0x04
+)
+
+// QMI CTL messages.
+const (
+	qmiCtlGetClientID     = 0x0022
+	qmiCtlReleaseClientID = 0x0023
+)
+
+// TLV with the result of a request, included in every response.
+const qmiTLVResult = 0x02
+
+// defaultQmiTimeout : how long to wait for a response to a QMI request.
+// Not a constant due to test usage.
+var defaultQmiTimeout = 20 * time.Second
+
+type qmiTLV struct {
+	typ   uint8
+	value []byte
+}
+
+type qmiMessage struct {
+	service  uint8
+	clientID uint8
+	flags    uint8
+	txnID    uint16
+	msgID    uint16
+	tlvs     []qmiTLV
+}
+
+// qmiError is an error code returned in the result TLV.
+type qmiError uint16
+
+const (
+	qmiErrMalformedMessage   qmiError = 0x0001
+	qmiErrNoMemory           qmiError = 0x0002
+	qmiErrInternal           qmiError = 0x0003
+	qmiErrAborted            qmiError = 0x0004
+	qmiErrClientIDsExhausted qmiError = 0x0005
+	qmiErrCallFailed         qmiError = 0x000E
+	qmiErrNoEffect           qmiError = 0x001A
+)
+
+func (e qmiError) Error() string {
+	var name string
+	switch e {
+	case qmiErrMalformedMessage:
+		name = "malformed message"
+	case qmiErrNoMemory:
+		name = "no memory"
+	case qmiErrInternal:
+		name = "internal error"
+	case qmiErrAborted:
+		name = "aborted"
+	case qmiErrClientIDsExhausted:
+		name = "client IDs exhausted"
+	case qmiErrCallFailed:
+		name = "call failed"
+	case qmiErrNoEffect:
+		name = "no effect"
+	default:
+		name = "unknown error"
+	}
+	return fmt.Sprintf("QMI error 0x%04x (%s)", uint16(e), name)
+}
+
+func (m qmiMessage) isCtl() bool {
+	return m.service == qmiServiceCTL
+}
+
+func (m qmiMessage) isResponse() bool {
+	if m.isCtl() {
+		return m.flags&qmiCtlFlagResponse != 0
+	}
+	return m.flags&qmiFlagResponse != 0
+}
+
+func (m qmiMessage) isIndication() bool {
+	if m.isCtl() {
+		return m.flags&qmiCtlFlagIndication != 0
+	}
+	return m.flags&qmiFlagIndication != 0
+}
+
+// tlv returns value of the TLV with the given type.
+func (m qmiMessage) tlv(typ uint8) ([]byte, bool) {
+	for _, tlv := range m.tlvs {
+		if tlv.typ == typ {
+			return tlv.value, true
+		}
+	}
+	return nil, false
+}
+
+// result returns error reported in the result TLV, if any.
+func (m qmiMessage) result() error {
+	value, ok := m.tlv(qmiTLVResult)
+	if !ok {
+		return errors.New("QMI response without result")
+	}
+	r := &byteReader{data: value}
+	result, code := r.u16(), r.u16()
+	if r.err != nil {
+		return fmt.Errorf("malformed QMI result: %w", r.err)
+	}
+	if result != 0 {
+		return qmiError(code)
+	}
+	return nil
+}
+
+func (m qmiMessage) encode() []byte {
+	var tlvs []byte
+	for _, tlv := range m.tlvs {
+		tlvs = append(tlvs, tlv.typ)
+		tlvs = appendU16(tlvs, uint16(len(tlv.value)))
+		tlvs = append(tlvs, tlv.value...)
+	}
+	var sdu []byte
+	sdu = append(sdu, m.flags)
+	if m.isCtl() {
+		sdu = append(sdu, uint8(m.txnID))
+	} else {
+		sdu = appendU16(sdu, m.txnID)
+	}
+	sdu = appendU16(sdu, m.msgID)
+	sdu = appendU16(sdu, uint16(len(tlvs)))
+	sdu = append(sdu, tlvs...)
+	msg := []byte{qmuxIfType}
+	msg = appendU16(msg, uint16(qmuxHeaderSize-1+len(sdu)))
+	msg = append(msg, 0x00, m.service, m.clientID)
+	return append(msg, sdu...)
+}
+
+func decodeQmiMessage(data []byte) (qmiMessage, error) {
+	var m qmiMessage
+	r := &byteReader{data: data}
+	if ifType := r.u8(); ifType != qmuxIfType {
+		return m, fmt.Errorf("unexpected QMUX I/F type: 0x%x", ifType)
+	}
+	length := int(r.u16())
+	if r.err == nil && length+1 != len(data) {
+		return m, fmt.Errorf("QMUX length mismatch: %d vs. %d", length+1, len(data))
+	}
+	_ = r.u8() // QMUX control flags
+	m.service = r.u8()
+	m.clientID = r.u8()
+	m.flags = r.u8()
+	if m.isCtl() {
+		m.txnID = uint16(r.u8())
+	} else {
+		m.txnID = r.u16()
+	}
+	m.msgID = r.u16()
+	tlvsLen := int(r.u16())
+	tlvs := &byteReader{data: r.bytes(tlvsLen)}
+	if r.err != nil {
+		return m, fmt.Errorf("malformed QMI message: %w", r.err)
+	}
+	for tlvs.off < len(tlvs.data) {
+		typ := tlvs.u8()
+		value := tlvs.bytes(int(tlvs.u16()))
+		if tlvs.err != nil {
+			return m, fmt.Errorf("malformed QMI TLV: %w", tlvs.err)
+		}
+		m.tlvs = append(m.tlvs, qmiTLV{typ: typ, value: value})
+	}
+	return m, nil
+}
+
+// qmiClient sends QMI requests over the transport and waits for responses.
+// Client IDs are allocated on demand, once for every service.
+type qmiClient struct {
+	transport Transport
+	timeout   time.Duration
+	clientIDs map[uint8]uint8
+	lastTxnID uint16
+	// indication is called for every indication received.
+	indication func(qmiMessage)
+}
+
+func newQmiClient(transport Transport) *qmiClient {
+	return &qmiClient{
+		transport: transport,
+		timeout:   defaultQmiTimeout,
+		clientIDs: make(map[uint8]uint8),
+	}
+}
+
+func (c *qmiClient) nextTxnID(service uint8) uint16 {
+	c.lastTxnID++
+	if service == qmiServiceCTL {
+		c.lastTxnID &= 0xFF
+	}
+	if c.lastTxnID == 0 {
+		c.lastTxnID = 1
+	}
+	return c.lastTxnID
+}
+
+func (c *qmiClient) clientID(service uint8) (uint8, error) {
+	if service == qmiServiceCTL {
+		return 0, nil
+	}
+	if cid, ok := c.clientIDs[service]; ok {
+		return cid, nil
+	}
+	resp, err := c.request(qmiServiceCTL, qmiCtlGetClientID, qmiTLV{typ: 0x01, value: []byte{service}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate client ID for QMI service 0x%x: %w",
+			service, err)
+	}
+	value, _ := resp.tlv(0x01)
+	if len(value) != 2 || value[0] != service {
+		return 0, fmt.Errorf("unexpected client ID allocated for QMI service 0x%x: %v",
+			service, value)
+	}
+	c.clientIDs[service] = value[1]
+	return value[1], nil
+}
+
+// request sends a request and returns the matching response.
+// The response is returned also together with an error reported by the modem,
+// so that the caller can inspect additional TLVs.
+func (c *qmiClient) request(service uint8, msgID uint16, tlvs ...qmiTLV) (qmiMessage, error) {
+	cid, err := c.clientID(service)
+	if err != nil {
+		return qmiMessage{}, err
+	}
+	req := qmiMessage{
+		service:  service,
+		clientID: cid,
+		txnID:    c.nextTxnID(service),
+		msgID:    msgID,
+		tlvs:     tlvs,
+	}
+	if err = c.transport.Send(req.encode()); err != nil {
+		return qmiMessage{}, err
+	}
+	deadline := time.Now().Add(c.timeout)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return qmiMessage{}, fmt.Errorf("timeout waiting for response to QMI message 0x%04x (service 0x%x)",
+				msgID, service)
+		}
+		resp, err := c.recv(remaining)
+		if err != nil {
+			if errors.Is(err, errRecvTimeout) {
+				continue
+			}
+			return qmiMessage{}, err
+		}
+		if resp.isResponse() && resp.service == service && resp.clientID == cid &&
+			resp.txnID == req.txnID && resp.msgID == msgID {
+			return resp, resp.result()
+		}
+	}
+}
+
+// recv receives the next message, indications are passed to the indication
+// callback (and returned as well).
+func (c *qmiClient) recv(timeout time.Duration) (qmiMessage, error) {
+	for {
+		data, err := c.transport.Recv(timeout)
+		if err != nil {
+			return qmiMessage{}, err
+		}
+		msg, err := decodeQmiMessage(data)
+		if err != nil {
+			// Not for us or garbage, skip it.
+			continue
+		}
+		if msg.isIndication() && c.indication != nil {
+			c.indication(msg)
+		}
+		return msg, nil
+	}
+}
+
+// poll processes all messages received within the timeout.
+func (c *qmiClient) poll(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil
+		}
+		if _, err := c.recv(remaining); err != nil {
+			if errors.Is(err, errRecvTimeout) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// close releases all allocated client IDs.
+func (c *qmiClient) close() error {
+	var errs []string
+	for service, cid := range c.clientIDs {
+		_, err := c.request(qmiServiceCTL, qmiCtlReleaseClientID,
+			qmiTLV{typ: 0x01, value: []byte{service, cid}})
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		delete(c.clientIDs, service)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to release QMI client IDs: %v", errs)
+	}
+	return nil
+}
@@ -0,0 +1,714 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package wwan
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net"
+	"path/filepath"
+	"time"
+
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	"github.com/vishvananda/netlink"
+)
+
+// QMI messages used by qmiModem.
+const (
+	qmiWdsReset                   = 0x0000
+	qmiWdsStartNetwork            = 0x0020
+	qmiWdsStopNetwork             = 0x0021
+	qmiWdsGetPacketServiceStatus  = 0x0022
+	qmiWdsGetPacketStatistics     = 0x0024
+	qmiWdsGetCurrentSettings      = 0x002D
+	qmiWdsSetIPFamily             = 0x004D
+	qmiDmsGetModel                = 0x0022
+	qmiDmsGetRevision             = 0x0023
+	qmiDmsGetIDs                  = 0x0025
+	qmiDmsGetOperatingMode        = 0x002D
+	qmiDmsSetOperatingMode        = 0x002E
+	qmiNasGetServingSystem        = 0x0024
+	qmiNasGetSignalInfo           = 0x004F
+	qmiUimReadTransparent         = 0x0020
+	qmiUimGetCardStatus           = 0x002F
+	qmiUimSwitchSlot              = 0x0046
+	qmiLocRegisterEvents          = 0x0021
+	qmiLocStart                   = 0x0022
+	qmiLocStop                    = 0x0023
+	qmiLocPositionReportIndMsgID  = 0x0024
+	qmiLocEventPositionReportMask = 0x01
+	qmiLocSessionID               = 1
+)
+
+// DMS operating modes.
+const (
+	qmiOpModeOnline             = 0
+	qmiOpModeLowPower           = 1
+	qmiOpModeOffline            = 3
+	qmiOpModePersistentLowPower = 6
+	qmiOpModeModeOnlyLowPower   = 7
+)
+
+// WDS connection status.
+const qmiPacketStatusConnected = 2
+
+// WDS authentication preference bits and IP family preferences.
+const (
+	qmiAuthPrefPAP         = 0x01
+	qmiAuthPrefCHAP        = 0x02
+	qmiIPFamilyIPv4        = 4
+	qmiIPFamilyIPv6        = 6
+	qmiIPFamilyUnspecified = 8
+)
+
+// NAS registration state.
+const qmiRegStateRegistered = 1
+
+// UIM card and application states.
+const (
+	qmiCardStateAbsent        = 0
+	qmiCardStatePresent       = 1
+	qmiAppStatePinRequired    = 2
+	qmiAppStatePukRequired    = 3
+	qmiAppStatePin1Blocked    = 5
+	qmiAppStateIllegal        = 6
+	qmiAppStateReady          = 7
+	qmiSessionPrimaryGW       = 0x00
+	qmiUimFileIDICCID         = 0x2FE2
+	qmiUimFileIDIMSI          = 0x6F07
+	qmiUimPathMF              = 0x3F00
+	qmiUimPathADF             = 0x7FFF
+	qmiUnavailableStatCounter = 0xFFFFFFFF
+)
+
+// unknownLocationValue is used for location attributes not included
+// in the position report.
+const unknownLocationValue = -32768
+
+// sysClassNet : not a constant due to test usage
+var sysClassNet = "/sys/class/net"
+
+// qmiModem implements Modem using the QMI protocol.
+type qmiModem struct {
+	client *qmiClient
+	// Network interface of the modem.
+	iface string
+	// Packet data handle of the data connection started by Connect.
+	pdh uint32
+	// The latest location report not yet returned by GetLocation.
+	location    types.WwanLocationInfo
+	hasLocation bool
+}
+
+// NewQmiModem returns Modem controlled using QMI over the given transport.
+// iface is the name of the network interface of the modem.
+func NewQmiModem(transport Transport, iface string) Modem {
+	m := &qmiModem{client: newQmiClient(transport), iface: iface}
+	m.client.indication = m.handleIndication
+	return m
+}
+
+func qmiString(msg qmiMessage, typ uint8) string {
+	value, _ := msg.tlv(typ)
+	return string(value)
+}
+
+func qmiU8(msg qmiMessage, typ uint8) (uint8, bool) {
+	value, ok := msg.tlv(typ)
+	if !ok || len(value) < 1 {
+		return 0, false
+	}
+	return value[0], true
+}
+
+// GetModuleInfo returns IMEI, model and revision of the modem.
+func (m *qmiModem) GetModuleInfo() (module types.WwanCellModule, err error) {
+	module.ControlProtocol = types.WwanCtrlProtQMI
+	resp, err := m.client.request(qmiServiceDMS, qmiDmsGetIDs)
+	if err != nil {
+		return module, fmt.Errorf("failed to get modem IDs: %w", err)
+	}
+	module.IMEI = qmiString(resp, 0x11)
+	resp, err = m.client.request(qmiServiceDMS, qmiDmsGetModel)
+	if err != nil {
+		return module, fmt.Errorf("failed to get modem model: %w", err)
+	}
+	module.Model = qmiString(resp, 0x01)
+	resp, err = m.client.request(qmiServiceDMS, qmiDmsGetRevision)
+	if err != nil {
+		return module, fmt.Errorf("failed to get modem revision: %w", err)
+	}
+	module.Revision = qmiString(resp, 0x01)
+	return module, nil
+}
+
+// GetOpMode returns the operating mode of the modem.
+func (m *qmiModem) GetOpMode() (types.WwanOpMode, error) {
+	resp, err := m.client.request(qmiServiceDMS, qmiDmsGetOperatingMode)
+	if err != nil {
+		return types.WwanOpModeUnspecified, fmt.Errorf("failed to get operating mode: %w", err)
+	}
+	mode, ok := qmiU8(resp, 0x01)
+	if !ok {
+		return types.WwanOpModeUnspecified, errors.New("operating mode is missing in the response")
+	}
+	switch mode {
+	case qmiOpModeOnline:
+		connected, err := m.IsConnected()
+		if err != nil {
+			return types.WwanOpModeUnspecified, err
+		}
+		if connected {
+			return types.WwanOpModeConnected, nil
+		}
+		return types.WwanOpModeOnline, nil
+	case qmiOpModeOffline:
+		return types.WwanOpModeOffline, nil
+	case qmiOpModeLowPower, qmiOpModePersistentLowPower, qmiOpModeModeOnlyLowPower:
+		return types.WwanOpModeRadioOff, nil
+	default:
+		return types.WwanOpModeUnrecognized, nil
+	}
+}
+
+func (m *qmiModem) setOpMode(mode uint8) error {
+	_, err := m.client.request(qmiServiceDMS, qmiDmsSetOperatingMode,
+		qmiTLV{typ: 0x01, value: []byte{mode}})
+	if err != nil && !errors.Is(err, qmiErrNoEffect) {
+		return fmt.Errorf("failed to set operating mode %d: %w", mode, err)
+	}
+	return nil
+}
+
+// SetRadio enables or disables radio transmission.
+func (m *qmiModem) SetRadio(on bool) error {
+	if on {
+		return m.setOpMode(qmiOpModeOnline)
+	}
+	return m.setOpMode(qmiOpModePersistentLowPower)
+}
+
+// GetSimStatus returns the state of the (first) SIM card.
+func (m *qmiModem) GetSimStatus() (status SimStatus, err error) {
+	resp, err := m.client.request(qmiServiceUIM, qmiUimGetCardStatus)
+	if err != nil {
+		return status, fmt.Errorf("failed to get card status: %w", err)
+	}
+	value, ok := resp.tlv(0x10)
+	if !ok {
+		return status, errors.New("card status is missing in the response")
+	}
+	status.State, err = parseQmiCardStatus(value)
+	if err != nil {
+		return status, err
+	}
+	if status.State != SimStateReady {
+		return status, nil
+	}
+	iccid, err := m.readTransparent(qmiUimFileIDICCID, qmiUimPathMF)
+	if err != nil {
+		return status, fmt.Errorf("failed to read ICCID: %w", err)
+	}
+	status.Card.ICCID = decodeICCID(iccid)
+	imsi, err := m.readTransparent(qmiUimFileIDIMSI, qmiUimPathMF, qmiUimPathADF)
+	if err != nil {
+		return status, fmt.Errorf("failed to read IMSI: %w", err)
+	}
+	status.Card.IMSI = decodeIMSI(imsi)
+	return status, nil
+}
+
+// parseQmiCardStatus returns state of the first application
+// of the first card.
+func parseQmiCardStatus(value []byte) (SimState, error) {
+	r := &byteReader{data: value}
+	r.bytes(8) // indexes of primary and secondary GW and 1x applications
+	cards := r.u8()
+	if r.err != nil {
+		return SimStateUnknown, fmt.Errorf("malformed card status: %w", r.err)
+	}
+	if cards == 0 {
+		return SimStateAbsent, nil
+	}
+	cardState := r.u8()
+	r.bytes(4) // UPIN state and retries, error code
+	apps := r.u8()
+	var appState uint8
+	if apps > 0 {
+		r.u8() // application type
+		appState = r.u8()
+	}
+	if r.err != nil {
+		return SimStateUnknown, fmt.Errorf("malformed card status: %w", r.err)
+	}
+	switch cardState {
+	case qmiCardStateAbsent:
+		return SimStateAbsent, nil
+	case qmiCardStatePresent:
+	default:
+		return SimStateError, nil
+	}
+	if apps == 0 {
+		return SimStateInitializing, nil
+	}
+	switch appState {
+	case qmiAppStateReady:
+		return SimStateReady, nil
+	case qmiAppStatePinRequired, qmiAppStatePukRequired, qmiAppStatePin1Blocked:
+		return SimStateLocked, nil
+	case qmiAppStateIllegal:
+		return SimStateError, nil
+	default:
+		return SimStateInitializing, nil
+	}
+}
+
+// readTransparent reads the whole content of the elementary file
+// with the given ID and path.
+func (m *qmiModem) readTransparent(fileID uint16, path ...uint16) ([]byte, error) {
+	session := []byte{qmiSessionPrimaryGW, 0}
+	file := appendU16(nil, fileID)
+	file = append(file, uint8(2*len(path)))
+	for _, p := range path {
+		file = appendU16(file, p)
+	}
+	readInfo := appendU16(appendU16(nil, 0), 0)
+	resp, err := m.client.request(qmiServiceUIM, qmiUimReadTransparent,
+		qmiTLV{typ: 0x01, value: session},
+		qmiTLV{typ: 0x02, value: file},
+		qmiTLV{typ: 0x03, value: readInfo})
+	if err != nil {
+		return nil, err
+	}
+	if sw, ok := resp.tlv(0x10); ok && len(sw) == 2 && sw[0] != 0x90 && sw[0] != 0x91 {
+		return nil, fmt.Errorf("card returned SW1=0x%x SW2=0x%x", sw[0], sw[1])
+	}
+	value, ok := resp.tlv(0x11)
+	if !ok {
+		return nil, errors.New("read result is missing in the response")
+	}
+	r := &byteReader{data: value}
+	data := r.bytes(int(r.u16()))
+	if r.err != nil {
+		return nil, fmt.Errorf("malformed read result: %w", r.err)
+	}
+	return data, nil
+}
+
+// decodeICCID decodes ICCID stored as swapped BCD digits
+// (ETSI TS 102 221, section 13.2).
+func decodeICCID(data []byte) string {
+	var digits []byte
+	for _, b := range data {
+		for _, nibble := range []byte{b & 0x0F, b >> 4} {
+			if nibble > 9 {
+				continue
+			}
+			digits = append(digits, '0'+nibble)
+		}
+	}
+	return string(digits)
+}
+
+// decodeIMSI decodes IMSI stored as length followed by swapped BCD digits,
+// where the first digit is replaced with parity (3GPP TS 31.102, section 4.2.2).
+func decodeIMSI(data []byte) string {
+	if len(data) < 2 {
+		return ""
+	}
+	length := int(data[0])
+	if length > len(data)-1 {
+		length = len(data) - 1
+	}
+	var digits []byte
+	for i, b := range data[1 : 1+length] {
+		nibbles := []byte{b & 0x0F, b >> 4}
+		if i == 0 {
+			// skip parity
+			nibbles = nibbles[1:]
+		}
+		for _, nibble := range nibbles {
+			if nibble > 9 {
+				continue
+			}
+			digits = append(digits, '0'+nibble)
+		}
+	}
+	return string(digits)
+}
+
+// GetRegistration returns the network registration state and the serving provider.
+func (m *qmiModem) GetRegistration() (reg Registration, err error) {
+	resp, err := m.client.request(qmiServiceNAS, qmiNasGetServingSystem)
+	if err != nil {
+		return reg, fmt.Errorf("failed to get serving system: %w", err)
+	}
+	state, ok := qmiU8(resp, 0x01)
+	if !ok {
+		return reg, errors.New("registration state is missing in the response")
+	}
+	reg.Registered = state == qmiRegStateRegistered
+	if !reg.Registered {
+		return reg, nil
+	}
+	reg.Provider.CurrentServing = true
+	if roaming, ok := qmiU8(resp, 0x10); ok {
+		reg.Provider.Roaming = roaming == 0
+	}
+	if value, ok := resp.tlv(0x12); ok {
+		r := &byteReader{data: value}
+		mcc, mnc := r.u16(), r.u16()
+		desc := r.bytes(int(r.u8()))
+		if r.err == nil {
+			reg.Provider.PLMN = fmt.Sprintf("%03d-%02d", mcc, mnc)
+			reg.Provider.Description = string(desc)
+		}
+	}
+	return reg, nil
+}
+
+// setRawIP switches the network interface to the raw-IP mode,
+// which is expected by most modems.
+func (m *qmiModem) setRawIP() error {
+	if m.iface == "" {
+		return nil
+	}
+	rawIPPath := filepath.Join(sysClassNet, m.iface, "qmi", "raw_ip")
+	if value, err := ioutil.ReadFile(rawIPPath); err == nil && len(value) > 0 && value[0] == 'Y' {
+		return nil
+	}
+	link, err := netlink.LinkByName(m.iface)
+	if err != nil {
+		return err
+	}
+	if err = netlink.LinkSetDown(link); err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(rawIPPath, []byte("Y"), 0644); err != nil {
+		return err
+	}
+	return netlink.LinkSetUp(link)
+}
+
+// SelectSimSlot maps the physical SIM slot to the first logical slot.
+func (m *qmiModem) SelectSimSlot(slot uint8) error {
+	_, err := m.client.request(qmiServiceUIM, qmiUimSwitchSlot,
+		qmiTLV{typ: 0x01, value: []byte{1}},
+		qmiTLV{typ: 0x02, value: appendU32(nil, uint32(slot))})
+	if err != nil && !errors.Is(err, qmiErrNoEffect) {
+		return fmt.Errorf("failed to switch to SIM slot %d: %w", slot, err)
+	}
+	return nil
+}
+
+// Connect starts the data connection using the given APN profile.
+// QMI requires a separate WDS session for every IP family, therefore
+// dual-stack is left to the default of the modem (which is usually IPv4).
+func (m *qmiModem) Connect(profile types.WwanApnProfile) error {
+	if err := m.setRawIP(); err != nil {
+		return fmt.Errorf("failed to enable raw-IP mode for %s: %w", m.iface, err)
+	}
+	// Reset WDS client state, response is not important.
+	_, _ = m.client.request(qmiServiceWDS, qmiWdsReset)
+	family := uint8(qmiIPFamilyIPv4)
+	if profile.IPType == types.WwanIPTypeIPv6 {
+		family = qmiIPFamilyIPv6
+	}
+	// IP family of the client determines which session is queried
+	// by IsConnected and GetIPSettings.
+	_, err := m.client.request(qmiServiceWDS, qmiWdsSetIPFamily,
+		qmiTLV{typ: 0x01, value: []byte{family}})
+	if err != nil && !errors.Is(err, qmiErrNoEffect) {
+		return fmt.Errorf("failed to set IP family %d: %w", family, err)
+	}
+	tlvs := []qmiTLV{{typ: 0x14, value: []byte(profile.APN)}}
+	switch profile.IPType {
+	case types.WwanIPTypeIPv4:
+		tlvs = append(tlvs, qmiTLV{typ: 0x19, value: []byte{qmiIPFamilyIPv4}})
+	case types.WwanIPTypeIPv6:
+		tlvs = append(tlvs, qmiTLV{typ: 0x19, value: []byte{qmiIPFamilyIPv6}})
+	case types.WwanIPTypeIPv4AndIPv6:
+		tlvs = append(tlvs, qmiTLV{typ: 0x19, value: []byte{qmiIPFamilyUnspecified}})
+	}
+	var authPref uint8
+	switch profile.AuthProtocol {
+	case types.WwanAuthProtocolPAP:
+		authPref = qmiAuthPrefPAP
+	case types.WwanAuthProtocolCHAP:
+		authPref = qmiAuthPrefCHAP
+	case types.WwanAuthProtocolPAPAndCHAP:
+		authPref = qmiAuthPrefPAP | qmiAuthPrefCHAP
+	}
+	if authPref != 0 {
+		tlvs = append(tlvs, qmiTLV{typ: 0x16, value: []byte{authPref}},
+			qmiTLV{typ: 0x17, value: []byte(profile.Username)},
+			qmiTLV{typ: 0x18, value: []byte(profile.Password)})
+	}
+	apn := profile.APN
+	resp, err := m.client.request(qmiServiceWDS, qmiWdsStartNetwork, tlvs...)
+	if err != nil {
+		if value, ok := resp.tlv(0x10); ok && len(value) == 2 {
+			r := &byteReader{data: value}
+			return fmt.Errorf("failed to start network with APN %s: %w (call end reason %d)",
+				apn, err, r.u16())
+		}
+		return fmt.Errorf("failed to start network with APN %s: %w", apn, err)
+	}
+	value, ok := resp.tlv(0x01)
+	if !ok || len(value) != 4 {
+		return errors.New("packet data handle is missing in the response")
+	}
+	m.pdh = (&byteReader{data: value}).u32()
+	return nil
+}
+
+// Disconnect stops the data connection.
+// If the packet data handle is not known or the modem refuses to stop
+// the connection, the modem is reset into the low-power mode and back online.
+func (m *qmiModem) Disconnect() error {
+	if m.pdh != 0 {
+		_, err := m.client.request(qmiServiceWDS, qmiWdsStopNetwork,
+			qmiTLV{typ: 0x01, value: appendU32(nil, m.pdh)})
+		m.pdh = 0
+		if err == nil {
+			return nil
+		}
+	} else {
+		connected, err := m.IsConnected()
+		if err == nil && !connected {
+			return nil
+		}
+	}
+	if err := m.setOpMode(qmiOpModeLowPower); err != nil {
+		return err
+	}
+	time.Sleep(time.Second)
+	return m.setOpMode(qmiOpModeOnline)
+}
+
+// IsConnected returns true if the packet data session is connected.
+func (m *qmiModem) IsConnected() (bool, error) {
+	resp, err := m.client.request(qmiServiceWDS, qmiWdsGetPacketServiceStatus)
+	if err != nil {
+		return false, fmt.Errorf("failed to get packet service status: %w", err)
+	}
+	status, ok := qmiU8(resp, 0x01)
+	if !ok {
+		return false, errors.New("connection status is missing in the response")
+	}
+	return status == qmiPacketStatusConnected, nil
+}
+
+func qmiIPv4(msg qmiMessage, typ uint8) net.IP {
+	value, ok := msg.tlv(typ)
+	if !ok || len(value) != 4 {
+		return nil
+	}
+	v := (&byteReader{data: value}).u32()
+	return net.IPv4(byte(v>>24), byte(v>>16), byte(v>>8), byte(v)).To4()
+}
+
+// GetIPSettings returns IPv4 configuration of the data connection.
+func (m *qmiModem) GetIPSettings() (settings IPSettings, err error) {
+	// Request DNS (0x0010), IP address (0x0100), gateway (0x0200) and MTU (0x2000).
+	resp, err := m.client.request(qmiServiceWDS, qmiWdsGetCurrentSettings,
+		qmiTLV{typ: 0x10, value: appendU32(nil, 0x2310)})
+	if err != nil {
+		return settings, fmt.Errorf("failed to get current settings: %w", err)
+	}
+	settings.Address = qmiIPv4(resp, 0x1E)
+	settings.Gateway = qmiIPv4(resp, 0x20)
+	if mask := qmiIPv4(resp, 0x21); mask != nil {
+		settings.Mask = net.IPMask(mask)
+	}
+	for _, typ := range []uint8{0x15, 0x16} {
+		if dns := qmiIPv4(resp, typ); dns != nil && !dns.IsUnspecified() {
+			settings.DNS = append(settings.DNS, dns)
+		}
+	}
+	if value, ok := resp.tlv(0x29); ok && len(value) == 4 {
+		settings.MTU = (&byteReader{data: value}).u32()
+	}
+	return settings, nil
+}
+
+// GetSignalInfo returns signal strength of the current radio technology.
+func (m *qmiModem) GetSignalInfo() (types.WwanSignalInfo, error) {
+	info := types.WwanSignalInfo{
+		RSSI: UnavailableSignalMetric,
+		RSRQ: UnavailableSignalMetric,
+		RSRP: UnavailableSignalMetric,
+		SNR:  UnavailableSignalMetric,
+	}
+	resp, err := m.client.request(qmiServiceNAS, qmiNasGetSignalInfo)
+	if err != nil {
+		return info, fmt.Errorf("failed to get signal info: %w", err)
+	}
+	if value, ok := resp.tlv(0x14); ok {
+		// LTE: RSSI, RSRQ, RSRP and SNR (in 0.1 dB)
+		r := &byteReader{data: value}
+		rssi, rsrq, rsrp, snr := int8(r.u8()), int8(r.u8()), int16(r.u16()), int16(r.u16())
+		if r.err == nil {
+			info.RSSI = int32(rssi)
+			info.RSRQ = int32(rsrq)
+			info.RSRP = int32(rsrp)
+			info.SNR = int32(math.Round(float64(snr) / 10))
+		}
+		return info, nil
+	}
+	// WCDMA or GSM: only RSSI
+	for _, typ := range []uint8{0x13, 0x12} {
+		if value, ok := resp.tlv(typ); ok && len(value) > 0 {
+			info.RSSI = int32(int8(value[0]))
+			break
+		}
+	}
+	return info, nil
+}
+
+// GetPacketStats returns packet statistics of the data connection.
+func (m *qmiModem) GetPacketStats() (stats types.WwanPacketStats, err error) {
+	// TX/RX packets OK (0x03), TX/RX bytes (0xC0), TX/RX dropped (0x300)
+	resp, err := m.client.request(qmiServiceWDS, qmiWdsGetPacketStatistics,
+		qmiTLV{typ: 0x01, value: appendU32(nil, 0x3C3)})
+	if err != nil {
+		return stats, fmt.Errorf("failed to get packet statistics: %w", err)
+	}
+	counter := func(typ uint8) uint64 {
+		value, ok := resp.tlv(typ)
+		r := &byteReader{data: value}
+		switch {
+		case !ok:
+			return 0
+		case len(value) == 8:
+			return r.u64()
+		default:
+			v := r.u32()
+			if v == qmiUnavailableStatCounter {
+				return 0
+			}
+			return uint64(v)
+		}
+	}
+	stats.TxPackets = counter(0x10)
+	stats.RxPackets = counter(0x11)
+	stats.TxBytes = counter(0x19)
+	stats.RxBytes = counter(0x1A)
+	stats.TxDrops = counter(0x1D)
+	stats.RxDrops = counter(0x1E)
+	return stats, nil
+}
+
+// StartLocationTracking starts periodic position reports.
+func (m *qmiModem) StartLocationTracking() error {
+	_, err := m.client.request(qmiServiceLOC, qmiLocRegisterEvents,
+		qmiTLV{typ: 0x01, value: appendU64(nil, qmiLocEventPositionReportMask)})
+	if err != nil {
+		return fmt.Errorf("failed to register for position reports: %w", err)
+	}
+	_, err = m.client.request(qmiServiceLOC, qmiLocStart,
+		qmiTLV{typ: 0x01, value: []byte{qmiLocSessionID}},
+		// periodic fixes
+		qmiTLV{typ: 0x10, value: appendU32(nil, 1)},
+		// minimal interval between reports in milliseconds
+		qmiTLV{typ: 0x13, value: appendU32(nil, 1000)})
+	if err != nil {
+		return fmt.Errorf("failed to start location session: %w", err)
+	}
+	return nil
+}
+
+// StopLocationTracking stops position reports.
+func (m *qmiModem) StopLocationTracking() error {
+	_, err := m.client.request(qmiServiceLOC, qmiLocStop,
+		qmiTLV{typ: 0x01, value: []byte{qmiLocSessionID}})
+	if err != nil {
+		return fmt.Errorf("failed to stop location session: %w", err)
+	}
+	m.hasLocation = false
+	return nil
+}
+
+// GetLocation returns the latest position report received.
+func (m *qmiModem) GetLocation() (types.WwanLocationInfo, bool, error) {
+	if err := m.client.poll(10 * time.Millisecond); err != nil {
+		return types.WwanLocationInfo{}, false, err
+	}
+	if !m.hasLocation {
+		return types.WwanLocationInfo{}, false, nil
+	}
+	m.hasLocation = false
+	return m.location, true, nil
+}
+
+func (m *qmiModem) handleIndication(msg qmiMessage) {
+	if msg.service != qmiServiceLOC || msg.msgID != qmiLocPositionReportIndMsgID {
+		return
+	}
+	m.location = parseQmiPositionReport(msg)
+	m.hasLocation = true
+}
+
+func qmiLocReliability(msg qmiMessage, typ uint8) types.LocReliability {
+	value, ok := msg.tlv(typ)
+	if !ok || len(value) != 4 {
+		return types.LocReliabilityUnspecified
+	}
+	switch (&byteReader{data: value}).u32() {
+	case 1:
+		return types.LocReliabilityVeryLow
+	case 2:
+		return types.LocReliabilityLow
+	case 3:
+		return types.LocReliabilityMedium
+	case 4:
+		return types.LocReliabilityHigh
+	default:
+		return types.LocReliabilityUnspecified
+	}
+}
+
+func parseQmiPositionReport(msg qmiMessage) types.WwanLocationInfo {
+	loc := types.WwanLocationInfo{
+		Latitude:              unknownLocationValue,
+		Longitude:             unknownLocationValue,
+		Altitude:              unknownLocationValue,
+		HorizontalUncertainty: unknownLocationValue,
+		VerticalUncertainty:   unknownLocationValue,
+	}
+	f64 := func(typ uint8, dst *float64) {
+		if value, ok := msg.tlv(typ); ok && len(value) == 8 {
+			*dst = (&byteReader{data: value}).f64()
+		}
+	}
+	f32 := func(typ uint8, dst *float32) {
+		if value, ok := msg.tlv(typ); ok && len(value) == 4 {
+			*dst = (&byteReader{data: value}).f32()
+		}
+	}
+	f64(0x10, &loc.Latitude)
+	f64(0x11, &loc.Longitude)
+	f32(0x12, &loc.HorizontalUncertainty)
+	var altitude float32 = unknownLocationValue
+	f32(0x1B, &altitude)
+	loc.Altitude = float64(altitude)
+	f32(0x1C, &loc.VerticalUncertainty)
+	loc.HorizontalReliability = qmiLocReliability(msg, 0x17)
+	loc.VerticalReliability = qmiLocReliability(msg, 0x1E)
+	if value, ok := msg.tlv(0x25); ok && len(value) == 8 {
+		loc.UTCTimestamp = (&byteReader{data: value}).u64()
+	}
+	return loc
+}
+
+// Close releases QMI client IDs and closes the transport.
+func (m *qmiModem) Close() error {
+	err := m.client.close()
+	if closeErr := m.client.transport.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
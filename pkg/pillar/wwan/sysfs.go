@@ -0,0 +1,165 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package wwan
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+// Not constants due to test usage.
+var (
+	sysClassUsbmisc  = "/sys/class/usbmisc"
+	sysBusUsbDevices = "/sys/bus/usb/devices"
+	devDir           = "/dev"
+)
+
+// ModemDevice is a cellular modem found in sysfs.
+type ModemDevice struct {
+	// Name of the control device, e.g. cdc-wdm0.
+	CdcDev    string
+	Protocol  types.WwanCtrlProt
+	PhysAddrs types.WwanPhysAddrs
+}
+
+// ControlDevicePath returns path to the control device of the modem.
+func (d ModemDevice) ControlDevicePath() string {
+	return filepath.Join(devDir, d.CdcDev)
+}
+
+// Matches returns true if the modem has the given physical addresses.
+// Undefined addresses match any modem.
+func (d ModemDevice) Matches(addrs types.WwanPhysAddrs) bool {
+	if addrs.Interface != "" && addrs.Interface != d.PhysAddrs.Interface {
+		return false
+	}
+	if addrs.USB != "" && addrs.USB != d.PhysAddrs.USB {
+		return false
+	}
+	if addrs.PCI != "" && addrs.PCI != d.PhysAddrs.PCI {
+		return false
+	}
+	return true
+}
+
+// String describes the modem.
+func (d ModemDevice) String() string {
+	return fmt.Sprintf("%s (protocol=%s, interface=%s, USB=%s, PCI=%s)",
+		d.CdcDev, d.Protocol, d.PhysAddrs.Interface, d.PhysAddrs.USB, d.PhysAddrs.PCI)
+}
+
+// findModems returns all QMI and MBIM modems found in sysfs,
+// sorted by the name of the control device.
+func findModems() ([]ModemDevice, error) {
+	entries, err := ioutil.ReadDir(sysClassUsbmisc)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var modems []ModemDevice
+	for _, entry := range entries {
+		sysDev := filepath.Join(sysClassUsbmisc, entry.Name())
+		protocol := sysGetModemProtocol(sysDev)
+		if protocol == types.WwanCtrlProtUnspecified {
+			continue
+		}
+		modems = append(modems, ModemDevice{
+			CdcDev:   entry.Name(),
+			Protocol: protocol,
+			PhysAddrs: types.WwanPhysAddrs{
+				Interface: sysGetModemInterface(sysDev),
+				USB:       sysGetModemAddr(sysDev, "usb"),
+				PCI:       sysGetModemAddr(sysDev, "pci"),
+			},
+		})
+	}
+	sort.Slice(modems, func(i, j int) bool {
+		return modems[i].CdcDev < modems[j].CdcDev
+	})
+	return modems, nil
+}
+
+func sysGetModemProtocol(sysDev string) types.WwanCtrlProt {
+	module, err := os.Readlink(filepath.Join(sysDev, "device", "driver", "module"))
+	if err != nil {
+		return types.WwanCtrlProtUnspecified
+	}
+	switch filepath.Base(module) {
+	case "cdc_mbim":
+		return types.WwanCtrlProtMBIM
+	case "qmi_wwan":
+		return types.WwanCtrlProtQMI
+	}
+	return types.WwanCtrlProtUnspecified
+}
+
+func sysGetModemInterface(sysDev string) string {
+	entries, err := ioutil.ReadDir(filepath.Join(sysDev, "device", "net"))
+	if err != nil || len(entries) == 0 {
+		return ""
+	}
+	return entries[0].Name()
+}
+
+// sysGetModemAddr returns USB address in the format <BUS>:<PORT>
+// or PCI address of the modem, depending on the subsystem.
+func sysGetModemAddr(sysDev, subsystem string) string {
+	devPath, err := filepath.EvalSymlinks(filepath.Join(sysDev, "device"))
+	if err != nil {
+		return ""
+	}
+	for devPath != "/" && devPath != "." {
+		link, err := os.Readlink(filepath.Join(devPath, "subsystem"))
+		if err != nil {
+			return ""
+		}
+		if filepath.Base(link) != subsystem {
+			devPath = filepath.Dir(devPath)
+			continue
+		}
+		name := filepath.Base(devPath)
+		if subsystem == "usb" {
+			// e.g. 1-2.3:1.4 -> 1:2.3
+			return strings.Replace(strings.Split(name, ":")[0], "-", ":", 1)
+		}
+		return name
+	}
+	return ""
+}
+
+// sysGetModemTTYs returns serial ports of the USB modem.
+func sysGetModemTTYs(usbAddr string) []string {
+	if usbAddr == "" {
+		return nil
+	}
+	// Convert USB address to <bus>-<port> as used in sysfs.
+	prefix := strings.Replace(usbAddr, ":", "-", 1)
+	usbIntfs, _ := filepath.Glob(filepath.Join(sysBusUsbDevices, prefix+"*"))
+	var ttys []string
+	for _, usbIntf := range usbIntfs {
+		ttyEntries, _ := filepath.Glob(filepath.Join(usbIntf, "tty*"))
+		for _, tty := range ttyEntries {
+			name := filepath.Base(tty)
+			if name == "tty" {
+				// ACM devices have the tty directory instead.
+				subEntries, _ := ioutil.ReadDir(tty)
+				for _, entry := range subEntries {
+					ttys = append(ttys, entry.Name())
+				}
+				continue
+			}
+			ttys = append(ttys, name)
+		}
+	}
+	sort.Strings(ttys)
+	return ttys
+}
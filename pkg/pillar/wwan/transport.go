@@ -0,0 +1,77 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package wwan
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Transport carries control messages between the host and a modem.
+// Every Send and Recv transfers one complete message (QMUX or MBIM).
+type Transport interface {
+	Send(msg []byte) error
+	// Recv waits up to timeout for the next message from the modem.
+	Recv(timeout time.Duration) ([]byte, error)
+	Close() error
+}
+
+// errRecvTimeout is returned by Transport.Recv when no message arrives in time.
+var errRecvTimeout = errors.New("timeout waiting for message from modem")
+
+// maxControlMsgSize is the largest control message we expect from a modem.
+const maxControlMsgSize = 16 << 10
+
+// deviceTransport is a Transport over the control character device
+// of the modem, provided by qmi_wwan or cdc_mbim (/dev/cdc-wdmX).
+// cdc-wdm delivers one complete message per read.
+type deviceTransport struct {
+	file *os.File
+	buf  []byte
+}
+
+// OpenDeviceTransport opens the modem control device.
+func OpenDeviceTransport(path string) (Transport, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	return &deviceTransport{file: file, buf: make([]byte, maxControlMsgSize)}, nil
+}
+
+// Send writes message to the control device.
+func (t *deviceTransport) Send(msg []byte) error {
+	n, err := t.file.Write(msg)
+	if err != nil {
+		return err
+	}
+	if n != len(msg) {
+		return fmt.Errorf("short write to %s: %d/%d", t.file.Name(), n, len(msg))
+	}
+	return nil
+}
+
+// Recv reads one message from the control device.
+func (t *deviceTransport) Recv(timeout time.Duration) ([]byte, error) {
+	if err := t.file.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	n, err := t.file.Read(t.buf)
+	if err != nil {
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return nil, errRecvTimeout
+		}
+		return nil, err
+	}
+	msg := make([]byte, n)
+	copy(msg, t.buf[:n])
+	return msg, nil
+}
+
+// Close closes the control device.
+func (t *deviceTransport) Close() error {
+	return t.file.Close()
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+// Installation of the image reconstructed from a binary patch against
+// the image in the current partition.
+
+package zboot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	zconfig "github.com/lf-edge/eve/api/go/config"
+	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/lf-edge/eve/pkg/pillar/bspatch"
+	"github.com/lf-edge/eve/pkg/pillar/types"
+)
+
+// IsDeltaFormatSupported returns true if we can apply patches in the format
+func IsDeltaFormatSupported(format zconfig.BaseOSDeltaFormat) bool {
+	return format == zconfig.BaseOSDeltaFormat_BASE_OS_DELTA_FORMAT_BSDIFF
+}
+
+// sha256Section computes sha256 of the first size bytes of r
+func sha256Section(r io.ReaderAt, size int64) (string, error) {
+	h := sha256.New()
+	n, err := io.Copy(h, io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return "", err
+	}
+	if n != size {
+		return "", fmt.Errorf("read %d bytes out of %d", n, size)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// WriteDeltaToPartition reconstructs the image from the patch in CAS
+// and the image in the current partition, and writes it to partition
+// partName. Both the image in the current partition and the reconstructed
+// image are checked against sha256 from the delta; the caller is expected
+// to fall back to the full image on error.
+func WriteDeltaToPartition(log *base.LogObject, patchImage string,
+	partName string, delta types.BaseOsDelta) error {
+
+	if !IsDeltaFormatSupported(delta.Format) {
+		return fmt.Errorf("unsupported delta format %s", delta.Format)
+	}
+	devName, err := getOtherPartitionDevName(partName)
+	if err != nil {
+		log.Errorf("WriteDeltaToPartition failed %s\n", err)
+		return err
+	}
+	curDevName := GetCurrentPartitionDevName()
+	if curDevName == "" {
+		return fmt.Errorf("null devname for current partition")
+	}
+	log.Functionf("WriteDeltaToPartition %s, %s: %v from %s\n",
+		partName, devName, patchImage, delta.BaseVersion)
+
+	// The image of the current partition has to be the one the patch
+	// was made against.
+	cur, err := os.Open(curDevName)
+	if err != nil {
+		return fmt.Errorf("failed to open current partition %s: %v",
+			curDevName, err)
+	}
+	defer cur.Close()
+	baseSha, err := sha256Section(cur, int64(delta.BaseSize))
+	if err != nil {
+		return fmt.Errorf("failed to read current partition %s: %v",
+			curDevName, err)
+	}
+	if baseSha != delta.BaseSha256 {
+		return fmt.Errorf("current partition %s does not match delta base %s: sha256 %s, expected %s",
+			curDevName, delta.BaseVersion, baseSha, delta.BaseSha256)
+	}
+
+	patch, err := ioutil.TempFile(types.PersistDir, "baseos-delta-")
+	if err != nil {
+		return fmt.Errorf("failed to create patch file: %v", err)
+	}
+	defer os.Remove(patch.Name())
+	defer patch.Close()
+	if err := pullImage(log, patchImage, patch); err != nil {
+		return err
+	}
+	info, err := patch.Stat()
+	if err != nil {
+		return err
+	}
+
+	unmountDev(log, devName)
+	f, err := os.OpenFile(devName, os.O_RDWR, 0644)
+	if err != nil {
+		errStr := fmt.Sprintf("error writing to partition device at %s: %v", devName, err)
+		log.Error(errStr)
+		return errors.New(errStr)
+	}
+	defer f.Close()
+	size, err := bspatch.Apply(cur, int64(delta.BaseSize), patch, info.Size(), f)
+	if err != nil {
+		return fmt.Errorf("failed to apply delta from %s: %v",
+			delta.BaseVersion, err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync %s: %v", devName, err)
+	}
+
+	// Read back what ended up in the partition.
+	targetSha, err := sha256Section(f, size)
+	if err != nil {
+		return fmt.Errorf("failed to read back %s: %v", devName, err)
+	}
+	if targetSha != delta.TargetSha256 {
+		return fmt.Errorf("image reconstructed from delta %s has sha256 %s, expected %s",
+			delta.BaseVersion, targetSha, delta.TargetSha256)
+	}
+	log.Noticef("WriteDeltaToPartition %s: reconstructed %d bytes from %s",
+		partName, size, delta.BaseVersion)
+	return nil
+}
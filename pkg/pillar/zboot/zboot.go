@@ -358,25 +358,59 @@ func GetOtherPartitionDevName() string {
 // WriteToPartition write the image to partition partName
 func WriteToPartition(log *base.LogObject, image string, partName string) error {
 
-	var (
-		casClient cas.CAS
-		err       error
-	)
+	devName, err := getOtherPartitionDevName(partName)
+	if err != nil {
+		log.Errorf("WriteToPartition failed %s\n", err)
+		return err
+	}
 
-	if !IsOtherPartition(partName) {
-		errStr := fmt.Sprintf("not other partition %s", partName)
-		log.Errorf("WriteToPartition failed %s\n", errStr)
+	log.Functionf("WriteToPartition %s, %s: %v\n", partName, devName, image)
+
+	unmountDev(log, devName)
+	// create a writer for the file where we want
+	// Avoid holding the lock since this can take a long time.
+	f, err := os.OpenFile(devName,
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		errStr := fmt.Sprintf("error writing to partition device at %s: %v", devName, err)
+		log.Error(errStr)
 		return errors.New(errStr)
 	}
+	defer f.Close()
 
+	return pullImage(log, image, f)
+}
+
+// getOtherPartitionDevName returns the device of partName and checks that
+// it is the other partition.
+func getOtherPartitionDevName(partName string) (string, error) {
+	if !IsOtherPartition(partName) {
+		return "", fmt.Errorf("not other partition %s", partName)
+	}
 	devName := GetPartitionDevname(partName)
 	if devName == "" {
-		errStr := fmt.Sprintf("null devname for partition %s", partName)
-		log.Errorf("WriteToPartition failed %s\n", errStr)
-		return errors.New(errStr)
+		return "", fmt.Errorf("null devname for partition %s", partName)
 	}
+	return devName, nil
+}
 
-	log.Functionf("WriteToPartition %s, %s: %v\n", partName, devName, image)
+// unmountDev makes sure we have nothing mounted on devName
+func unmountDev(log *base.LogObject, devName string) {
+	for {
+		if err := syscall.Unmount(devName, 0); err != nil {
+			break
+		}
+		log.Warnf("Successfully umounted %s", devName)
+	}
+}
+
+// pullImage writes the content of the image from CAS to f
+func pullImage(log *base.LogObject, image string, f *os.File) error {
+
+	var (
+		casClient cas.CAS
+		err       error
+	)
 
 	// use the edge-containers library to extract the data we need
 	puller := registry.Puller{
@@ -399,24 +433,6 @@ func WriteToPartition(log *base.LogObject, image string, partName string) error
 		return errors.New(errStr)
 	}
 
-	// Make sure we have nothing mounted on the target
-	for {
-		if err := syscall.Unmount(devName, 0); err != nil {
-			break
-		}
-		log.Warnf("Successfully umounted %s", devName)
-	}
-	// create a writer for the file where we want
-	// Avoid holding the lock since this can take a long time.
-	f, err := os.OpenFile(devName,
-		os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		errStr := fmt.Sprintf("error writing to partition device at %s: %v", devName, err)
-		log.Error(errStr)
-		return errors.New(errStr)
-	}
-	defer f.Close()
-
 	if _, _, err := puller.Pull(&registry.FilesTarget{Root: f, AcceptHash: true}, 0, false, os.Stderr, resolver); err != nil {
 		errStr := fmt.Sprintf("error pulling %s from containerd: %v", image, err)
 		log.Error(errStr)
@@ -16,6 +16,7 @@ import (
 
 	"github.com/lf-edge/eve/pkg/pillar/agentlog"
 	"github.com/lf-edge/eve/pkg/pillar/base"
+	"github.com/lf-edge/eve/pkg/pillar/cmd/attestverify"
 	"github.com/lf-edge/eve/pkg/pillar/cmd/baseosmgr"
 	"github.com/lf-edge/eve/pkg/pillar/cmd/client"
 	"github.com/lf-edge/eve/pkg/pillar/cmd/command"
@@ -39,6 +40,7 @@ import (
 	"github.com/lf-edge/eve/pkg/pillar/cmd/waitforaddr"
 	"github.com/lf-edge/eve/pkg/pillar/cmd/watcher"
 	"github.com/lf-edge/eve/pkg/pillar/cmd/wstunnelclient"
+	"github.com/lf-edge/eve/pkg/pillar/cmd/wwan"
 	"github.com/lf-edge/eve/pkg/pillar/cmd/zedagent"
 	"github.com/lf-edge/eve/pkg/pillar/cmd/zedmanager"
 	"github.com/lf-edge/eve/pkg/pillar/cmd/zedrouter"
@@ -74,6 +76,7 @@ type entrypoint struct {
 
 var (
 	entrypoints = map[string]entrypoint{
+		"attestverify":     {f: attestverify.Run, inline: inlineAlways},
 		"client":           {f: client.Run, inline: inlineAlways},
 		"command":          {f: command.Run},
 		"diag":             {f: diag.Run, inline: inlineUnlessService},
@@ -101,6 +104,7 @@ var (
 		"upgradeconverter": {f: upgradeconverter.Run, inline: inlineAlways},
 		"watcher":          {f: watcher.Run},
 		"zfsmanager":       {f: zfsmanager.Run},
+		"wwan":             {f: wwan.Run},
 	}
 	logger *logrus.Logger
 	log    *base.LogObject
@@ -99,6 +99,35 @@ func verifyAuthentication(ctx *ZedCloudContext, c []byte, skipVerify bool) ([]by
 	return data, senderSt, nil
 }
 
+// VerifyAuthContainer verifies the signature of an AuthContainer which
+// did not come from the controller over the network (e.g. an update bundle
+// from a USB stick), and returns its payload
+func VerifyAuthContainer(ctx *ZedCloudContext, c []byte) ([]byte, error) {
+	data, _, err := verifyAuthentication(ctx, c, false)
+	return data, err
+}
+
+// VerifyAuthContainerWithCert verifies the signature of an AuthContainer
+// against the given controller signing certificate (PEM) instead of the one
+// of the device, and returns its payload
+func VerifyAuthContainerWithCert(ctx *ZedCloudContext, c []byte, certBytes []byte) ([]byte, error) {
+	block, _ := pem.Decode(certBytes)
+	if block == nil {
+		return nil, errors.New("VerifyAuthContainerWithCert: certificate decode fail")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("VerifyAuthContainerWithCert: certificate parse fail, %v", err)
+	}
+	certCtx := &ZedCloudContext{
+		serverSigningCert:     cert,
+		serverSigningCertHash: ComputeSha(certBytes),
+		log:                   ctx.log,
+	}
+	data, _, err := verifyAuthentication(certCtx, c, false)
+	return data, err
+}
+
 func getServerSigingCert(ctx *ZedCloudContext) error {
 	certBytes, err := ioutil.ReadFile(types.ServerSigningCertFileName)
 	if err != nil {
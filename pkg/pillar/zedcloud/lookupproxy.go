@@ -16,6 +16,12 @@ import (
 	"github.com/lf-edge/eve/pkg/pillar/zedpac"
 )
 
+// pacEngine evaluates PAC files of all ports. It caches compiled PAC files
+// and proxies returned for URLs.
+var pacEngine = zedpac.NewEngine(zedpac.EngineOptions{})
+
+// LookupProxy returns proxy to use for the given URL on the given port.
+// Returns nil if the URL should be accessed directly.
 func LookupProxy(log *base.LogObject, status *types.DeviceNetworkStatus, ifname string,
 	rawUrl string) (*url.URL, error) {
 
@@ -47,8 +53,10 @@ func LookupProxy(log *base.LogObject, status *types.DeviceNetworkStatus, ifname
 				log.Errorf(errStr)
 				return nil, errors.New(errStr)
 			}
-			proxyString, err := zedpac.Find_proxy_sync(
-				string(pacFile), rawUrl, u.Host)
+			// DNS helper functions of the PAC file use resolvers
+			// and addresses of the port.
+			proxyString, err := pacEngine.FindProxy(zedpac.NewPortInfo(port),
+				string(pacFile), rawUrl, u.Hostname())
 			if err != nil {
 				errStr := fmt.Sprintf("LookupProxy: PAC file could not find proxy for %s: %s",
 					rawUrl, err)
@@ -52,6 +52,9 @@ type ZedCloudContext struct {
 	NetworkSendTimeout  uint32 // In seconds
 	V2API               bool   // XXX Needed?
 	AgentName           string // the agent process name
+	// AuthContainerFunc is called with the envelope of a response whose
+	// signature was verified, which allows to keep the signature
+	AuthContainerFunc func(url string, authContainer []byte)
 	// V2 related items
 	PrevCertPEM           [][]byte // cached proxy certs for later comparison
 	onBoardCert           *tls.Certificate
@@ -134,8 +137,9 @@ func GetContextForAllIntfFunctions(ctx *ZedCloudContext) (context.Context, conte
 	return context.WithTimeout(context.Background(), maxWaitDuration)
 }
 
-// Tries all interfaces (free first) until one succeeds. interation arg
-// ensure load spreading across multiple interfaces.
+// Tries all interfaces (free first) until one succeeds. Among interfaces
+// of the same cost, those with a better estimated link quality are tried first.
+// interation arg ensure load spreading across multiple interfaces.
 // Returns response for first success. Caller can not use resp.Body but can
 // use []byte contents return.
 // If bailOnHTTPErr is set we immediately return when we get a 4xx or 5xx error without trying the other interfaces.
@@ -584,7 +588,18 @@ func SendOnIntf(workContext context.Context, ctx *ZedCloudContext, destURL strin
 				// We assume we reached the controller here
 				log.Errorf("client.Do fail: certFailure")
 				senderStatus = types.SenderStatusCertInvalid
-				if cert != nil {
+				if cert != nil && isCertTimeInvalid(err) {
+					// Either expired certificate or skewed device clock.
+					cerr := &types.CertTimeInvalid{
+						IfName:    intf,
+						Subject:   cert.Subject.String(),
+						NotBefore: cert.NotBefore,
+						NotAfter:  cert.NotAfter,
+						Now:       time.Now(),
+					}
+					log.Error(cerr)
+					attempt.Err = cerr
+				} else if cert != nil {
 					errStr := fmt.Sprintf("cert failure for Subject %s NotBefore %v NotAfter %v",
 						cert.Subject, cert.NotBefore,
 						cert.NotAfter)
@@ -726,6 +741,9 @@ func SendOnIntf(workContext context.Context, ctx *ZedCloudContext, destURL strin
 					}
 					log.Tracef("SendOnIntf verify auth ok, len content/content2 %d/%d, url %s",
 						len(contents), len(contents2), reqUrl)
+					if ctx.AuthContainerFunc != nil && !isCerts {
+						ctx.AuthContainerFunc(reqUrl, contents)
+					}
 				} else {
 					contents2 = contents
 				}
@@ -959,6 +977,20 @@ func isCertFailure(err error) (bool, *x509.Certificate) {
 	return true, e1.Cert
 }
 
+// isCertTimeInvalid returns true if the certificate was rejected because
+// the current time is outside of its validity period.
+func isCertTimeInvalid(err error) bool {
+	e0, ok := err.(*url.Error)
+	if !ok {
+		return false
+	}
+	e1, ok := e0.Err.(x509.CertificateInvalidError)
+	if !ok {
+		return false
+	}
+	return e1.Reason == x509.Expired
+}
+
 func isCertUnknownAuthority(err error) bool {
 	e0, ok := err.(*url.Error)
 	if !ok {
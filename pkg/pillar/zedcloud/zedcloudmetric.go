@@ -115,6 +115,15 @@ func (am *AgentMetrics) Publish(log *base.LogObject, publication pubsub.Publicat
 	return publication.Publish(key, am.metrics)
 }
 
+// GetMetrics returns a copy of the recorded metrics.
+func (am *AgentMetrics) GetMetrics(log *base.LogObject) types.MetricsMap {
+	release := am.acquire(log)
+	defer release()
+	metrics := make(types.MetricsMap)
+	am.metrics.AddInto(metrics)
+	return metrics
+}
+
 // GetURLsWithSubstr returns URLs containing the given substring.
 func (am *AgentMetrics) GetURLsWithSubstr(log *base.LogObject, substr string) (set []string) {
 	release := am.acquire(log)
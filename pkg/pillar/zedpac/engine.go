@@ -0,0 +1,237 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package zedpac
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/robertkrimen/otto"
+)
+
+const (
+	// DefaultTimeout : default limit for the execution of a PAC script.
+	DefaultTimeout = 10 * time.Second
+	// DefaultResultTTL : default time for which the proxy returned for URL
+	// is cached.
+	DefaultResultTTL = 5 * time.Minute
+	// DefaultMaxResults : default limit for the number of cached results
+	// per port.
+	DefaultMaxResults = 256
+)
+
+// EngineOptions : options for Engine.
+// Zero values are replaced with defaults.
+type EngineOptions struct {
+	// Timeout limits the execution of the PAC script (top-level code
+	// and every call to FindProxyForURL), including DNS queries.
+	Timeout time.Duration
+	// ResultTTL : how long is the result of FindProxyForURL cached.
+	ResultTTL time.Duration
+	// MaxResults : maximum number of results cached for a single port.
+	MaxResults int
+}
+
+// Engine evaluates PAC scripts for device ports.
+// Every script is compiled only once and loaded into a VM for every port
+// which uses it, with helper functions bound to the port (see PortInfo).
+// Results of FindProxyForURL are cached per port with a TTL. A port is
+// given a new VM (and an empty cache) whenever its PAC script, addresses
+// or DNS servers change.
+// Engine is thread-safe.
+type Engine struct {
+	sync.Mutex
+	opts EngineOptions
+	// Compiled PAC scripts, key: SHA-256 of the script.
+	scripts map[[sha256.Size]byte]*otto.Script
+	// key: interface name
+	ports map[string]*portRuntime
+	// Used to compile scripts, not thread-safe.
+	compiler     *otto.Otto
+	compilerLock sync.Mutex
+	// Can be overridden by unit tests.
+	now func() time.Time
+}
+
+// portRuntime : PAC script loaded for a port.
+type portRuntime struct {
+	sync.Mutex
+	port       PortInfo
+	scriptHash [sha256.Size]byte
+	rt         *runtime
+	// Error returned when the script was loaded.
+	loadErr error
+	// Cached results of FindProxyForURL, key: url + host.
+	results map[string]pacResult
+}
+
+type pacResult struct {
+	proxy   string
+	err     error
+	expires time.Time
+}
+
+// NewEngine creates a new PAC engine.
+func NewEngine(opts EngineOptions) *Engine {
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.ResultTTL == 0 {
+		opts.ResultTTL = DefaultResultTTL
+	}
+	if opts.MaxResults == 0 {
+		opts.MaxResults = DefaultMaxResults
+	}
+	return &Engine{
+		opts:     opts,
+		scripts:  make(map[[sha256.Size]byte]*otto.Script),
+		ports:    make(map[string]*portRuntime),
+		compiler: otto.New(),
+		now:      time.Now,
+	}
+}
+
+// FindProxy evaluates FindProxyForURL from the PAC script for the given URL
+// and host, with helper functions bound to the given port.
+func (e *Engine) FindProxy(port PortInfo, pac, url, host string) (string, error) {
+	pr, err := e.getPortRuntime(port, pac)
+	if err != nil {
+		return "", err
+	}
+	pr.Lock()
+	defer pr.Unlock()
+	if pr.loadErr != nil {
+		return "", pr.loadErr
+	}
+	now := e.now()
+	key := url + " " + host
+	if result, cached := pr.results[key]; cached && now.Before(result.expires) {
+		return result.proxy, result.err
+	}
+	proxy, err := pr.rt.findProxyForURL(url, host, e.opts.Timeout)
+	if pr.rt.halted {
+		// Do not cache timeouts, next call will get a new VM.
+		e.removePortRuntime(pr)
+		return "", err
+	}
+	if len(pr.results) >= e.opts.MaxResults {
+		pr.purgeExpired(now)
+	}
+	if len(pr.results) >= e.opts.MaxResults {
+		pr.results = make(map[string]pacResult)
+	}
+	pr.results[key] = pacResult{
+		proxy:   proxy,
+		err:     err,
+		expires: now.Add(e.opts.ResultTTL),
+	}
+	return proxy, err
+}
+
+// Flush removes all cached scripts and results.
+func (e *Engine) Flush() {
+	e.Lock()
+	defer e.Unlock()
+	e.scripts = make(map[[sha256.Size]byte]*otto.Script)
+	e.ports = make(map[string]*portRuntime)
+}
+
+// getPortRuntime returns runtime of the port with the PAC script loaded.
+// A new runtime is created if the script or the port configuration changed.
+func (e *Engine) getPortRuntime(port PortInfo, pac string) (*portRuntime, error) {
+	hash := sha256.Sum256([]byte(pac))
+	e.Lock()
+	pr := e.ports[port.IfName]
+	script := e.scripts[hash]
+	e.Unlock()
+	if pr != nil && pr.scriptHash == hash && pr.port.Equal(port) {
+		return pr, nil
+	}
+	// Compile and load the script without holding the engine lock,
+	// both may take long and should not block other ports.
+	var err error
+	if script == nil {
+		script, err = e.compileScript(pac)
+		if err != nil {
+			return nil, err
+		}
+	}
+	pr = &portRuntime{
+		port:       port,
+		scriptHash: hash,
+		results:    make(map[string]pacResult),
+	}
+	pr.rt, err = newRuntime(port)
+	if err != nil {
+		return nil, err
+	}
+	// Top-level code may also call helpers and therefore should be evaluated
+	// with the runtime bound to the port.
+	if err = pr.rt.load(script, e.opts.Timeout); err != nil {
+		if pr.rt.halted {
+			// Possibly just a slow DNS server, try again next time.
+			return nil, err
+		}
+		pr.loadErr = fmt.Errorf("invalid proxy auto-configuration file: %v", err)
+	}
+	e.Lock()
+	defer e.Unlock()
+	// The port may have been given the same script concurrently.
+	if current := e.ports[port.IfName]; current != nil &&
+		current.scriptHash == hash && current.port.Equal(port) {
+		return current, nil
+	}
+	e.scripts[hash] = script
+	e.ports[port.IfName] = pr
+	e.removeUnusedScripts()
+	return pr, nil
+}
+
+// compileScript compiles PAC script.
+func (e *Engine) compileScript(pac string) (*otto.Script, error) {
+	e.compilerLock.Lock()
+	defer e.compilerLock.Unlock()
+	script, err := e.compiler.Compile("", pac)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy auto-configuration file: %v", err)
+	}
+	return script, nil
+}
+
+// removeUnusedScripts removes compiled scripts no longer used by any port.
+// Must be called with the engine locked.
+func (e *Engine) removeUnusedScripts() {
+	for hash := range e.scripts {
+		var used bool
+		for _, pr := range e.ports {
+			if pr.scriptHash == hash {
+				used = true
+				break
+			}
+		}
+		if !used {
+			delete(e.scripts, hash)
+		}
+	}
+}
+
+// removePortRuntime removes runtime which can no longer be used.
+func (e *Engine) removePortRuntime(pr *portRuntime) {
+	e.Lock()
+	defer e.Unlock()
+	if e.ports[pr.port.IfName] == pr {
+		delete(e.ports, pr.port.IfName)
+		e.removeUnusedScripts()
+	}
+}
+
+func (pr *portRuntime) purgeExpired(now time.Time) {
+	for key, result := range pr.results {
+		if !now.Before(result.expires) {
+			delete(pr.results, key)
+		}
+	}
+}
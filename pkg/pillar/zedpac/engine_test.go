@@ -0,0 +1,196 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package zedpac
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	. "github.com/onsi/gomega"
+)
+
+const counterPac = `
+var calls = 0;
+function FindProxyForURL(url, host) {
+    calls++;
+    return "PROXY proxy" + calls + ":8080";
+}`
+
+// startDNSServer starts DNS server on localhost answering A queries
+// using the given records.
+func startDNSServer(t *GomegaWithT, records map[string]string) (stop func()) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	t.Expect(err).ToNot(HaveOccurred())
+	_, dnsPort, _ = net.SplitHostPort(conn.LocalAddr().String())
+	handler := func(w dns.ResponseWriter, req *dns.Msg) {
+		resp := new(dns.Msg)
+		resp.SetReply(req)
+		for _, q := range req.Question {
+			ip, exists := records[strings.TrimSuffix(q.Name, ".")]
+			if !exists || q.Qtype != dns.TypeA {
+				continue
+			}
+			resp.Answer = append(resp.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeA,
+					Class: dns.ClassINET, Ttl: 60},
+				A: net.ParseIP(ip),
+			})
+		}
+		if len(resp.Answer) == 0 {
+			resp.Rcode = dns.RcodeNameError
+		}
+		_ = w.WriteMsg(resp)
+	}
+	server := &dns.Server{PacketConn: conn, Handler: dns.HandlerFunc(handler)}
+	go func() { _ = server.ActivateAndServe() }()
+	return func() {
+		_ = server.Shutdown()
+		dnsPort = "53"
+	}
+}
+
+func TestEngineCache(test *testing.T) {
+	t := NewGomegaWithT(test)
+	e := NewEngine(EngineOptions{ResultTTL: time.Minute})
+	now := time.Now()
+	e.now = func() time.Time { return now }
+	eth0 := PortInfo{IfName: "eth0", Addrs: []net.IP{net.ParseIP("192.168.1.10")}}
+	eth1 := PortInfo{IfName: "eth1", Addrs: []net.IP{net.ParseIP("10.10.5.20")}}
+
+	proxy, err := e.FindProxy(eth0, counterPac, "http://a.com/x", "a.com")
+	t.Expect(err).ToNot(HaveOccurred())
+	t.Expect(proxy).To(Equal("PROXY proxy1:8080"))
+	// Result is cached.
+	proxy, _ = e.FindProxy(eth0, counterPac, "http://a.com/x", "a.com")
+	t.Expect(proxy).To(Equal("PROXY proxy1:8080"))
+	proxy, _ = e.FindProxy(eth0, counterPac, "http://b.com/x", "b.com")
+	t.Expect(proxy).To(Equal("PROXY proxy2:8080"))
+	// Script is compiled once but every port has its own VM.
+	proxy, _ = e.FindProxy(eth1, counterPac, "http://a.com/x", "a.com")
+	t.Expect(proxy).To(Equal("PROXY proxy1:8080"))
+	t.Expect(e.scripts).To(HaveLen(1))
+
+	// Cached result expires.
+	now = now.Add(2 * time.Minute)
+	proxy, _ = e.FindProxy(eth0, counterPac, "http://a.com/x", "a.com")
+	t.Expect(proxy).To(Equal("PROXY proxy3:8080"))
+
+	// Port address changed - VM and cache are re-created.
+	eth0.Addrs = []net.IP{net.ParseIP("192.168.1.11")}
+	proxy, _ = e.FindProxy(eth0, counterPac, "http://a.com/x", "a.com")
+	t.Expect(proxy).To(Equal("PROXY proxy1:8080"))
+
+	// PAC script changed.
+	const directPac = `function FindProxyForURL(url, host) { return "DIRECT"; }`
+	proxy, _ = e.FindProxy(eth0, directPac, "http://a.com/x", "a.com")
+	t.Expect(proxy).To(Equal("DIRECT"))
+	t.Expect(e.scripts).To(HaveLen(2))
+	proxy, _ = e.FindProxy(eth1, directPac, "http://a.com/x", "a.com")
+	t.Expect(proxy).To(Equal("DIRECT"))
+	t.Expect(e.scripts).To(HaveLen(1))
+
+	// Invalid PAC script.
+	_, err = e.FindProxy(eth0, "function FindProxyForURL(", "http://a.com/x", "a.com")
+	t.Expect(err).To(HaveOccurred())
+	t.Expect(err.Error()).To(ContainSubstring("invalid proxy auto-configuration file"))
+}
+
+func TestEngineTimeout(test *testing.T) {
+	t := NewGomegaWithT(test)
+	e := NewEngine(EngineOptions{Timeout: 200 * time.Millisecond})
+	eth0 := PortInfo{IfName: "eth0"}
+	const loopPac = `
+function FindProxyForURL(url, host) {
+    if (host == "loop.com") {
+        while (true) {}
+    }
+    return "DIRECT";
+}`
+	start := time.Now()
+	_, err := e.FindProxy(eth0, loopPac, "http://loop.com/", "loop.com")
+	t.Expect(err).To(HaveOccurred())
+	t.Expect(err.Error()).To(ContainSubstring("timed out"))
+	t.Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+	// Engine recovers with a new VM.
+	proxy, err := e.FindProxy(eth0, loopPac, "http://a.com/", "a.com")
+	t.Expect(err).ToNot(HaveOccurred())
+	t.Expect(proxy).To(Equal("DIRECT"))
+
+	// Infinite loop in the top-level code.
+	_, err = e.FindProxy(eth0, "while (true) {}", "http://a.com/", "a.com")
+	t.Expect(err).To(HaveOccurred())
+	t.Expect(err.Error()).To(ContainSubstring("timed out"))
+}
+
+func TestEnginePortHelpers(test *testing.T) {
+	t := NewGomegaWithT(test)
+	stop := startDNSServer(t, map[string]string{
+		"intranet.example.com": "10.1.2.3",
+		"www.example.com":      "93.184.216.34",
+	})
+	defer stop()
+	e := NewEngine(EngineOptions{Timeout: 5 * time.Second})
+	eth0 := PortInfo{
+		IfName:     "eth0",
+		Addrs:      []net.IP{net.ParseIP("127.0.0.1")},
+		DNSServers: []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	const pac = `
+function FindProxyForURL(url, host) {
+    if (!isResolvable(host))
+        return "PROXY unresolvable:8080";
+    if (isInNet(host, "10.0.0.0", "255.0.0.0"))
+        return "DIRECT";
+    return "PROXY " + myIpAddress() + ":3128; PROXY " + dnsResolve(host) + ":80";
+}`
+	proxy, err := e.FindProxy(eth0, pac, "http://intranet.example.com/", "intranet.example.com")
+	t.Expect(err).ToNot(HaveOccurred())
+	t.Expect(proxy).To(Equal("DIRECT"))
+	proxy, err = e.FindProxy(eth0, pac, "http://www.example.com/", "www.example.com")
+	t.Expect(err).ToNot(HaveOccurred())
+	t.Expect(proxy).To(Equal("PROXY 127.0.0.1:3128; PROXY 93.184.216.34:80"))
+	proxy, err = e.FindProxy(eth0, pac, "http://unknown.example.com/", "unknown.example.com")
+	t.Expect(err).ToNot(HaveOccurred())
+	t.Expect(proxy).To(Equal("PROXY unresolvable:8080"))
+}
+
+func TestEngineShExpMatch(test *testing.T) {
+	t := NewGomegaWithT(test)
+	e := NewEngine(EngineOptions{})
+	eth0 := PortInfo{IfName: "eth0"}
+	const pac = `
+function FindProxyForURL(url, host) {
+    return shExpMatch(url, host) ? "DIRECT" : "PROXY proxy:8080";
+}`
+	// Host argument carries the shell expression.
+	testMatrix := []struct {
+		str     string
+		shexp   string
+		matches bool
+	}{
+		{str: "www.example.com", shexp: "*.example.com", matches: true},
+		{str: "example.com", shexp: "*.example.com", matches: false},
+		{str: "wwwxexample.com", shexp: "www.example.com", matches: false},
+		{str: "host1.example.com", shexp: "host?.example.com", matches: true},
+		{str: "host.example.com", shexp: "host?.example.com", matches: false},
+		{str: "host12.example.com", shexp: "host?.example.com", matches: false},
+		{str: "example.com", shexp: "(*.example.com|example.com)", matches: true},
+		{str: "a+b.example.com", shexp: "a+b.example.com", matches: true},
+		{str: "aab.example.com", shexp: "a+b.example.com", matches: false},
+		{str: "http://example.com/x?y=[1]", shexp: "http://example.com/x?y=[1]", matches: true},
+		{str: "http://example.com/$1", shexp: "*/$1", matches: true},
+	}
+	for _, tc := range testMatrix {
+		expected := "PROXY proxy:8080"
+		if tc.matches {
+			expected = "DIRECT"
+		}
+		proxy, err := e.FindProxy(eth0, pac, tc.str, tc.shexp)
+		t.Expect(err).ToNot(HaveOccurred())
+		t.Expect(proxy).To(Equal(expected), "shExpMatch(%q, %q)", tc.str, tc.shexp)
+	}
+}
@@ -1,30 +1,12 @@
-// Copyright 2014 Jack Wakefield
-//
-// Licensed under the Apache License, Version 2.0 (the "License");
-// you may not use this file except in compliance with the License.
-// You may obtain a copy of the License at
-//
-//     http://www.apache.org/licenses/LICENSE-2.0
-//
-// Unless required by applicable law or agreed to in writing, software
-// distributed under the License is distributed on an "AS IS" BASIS,
-// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
-// See the License for the specific language governing permissions and
-// limitations under the License.
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
 
-package gopac
+package zedpac
 
-import (
-	"net"
-	"os"
-	"regexp"
-	"strings"
-
-	"github.com/robertkrimen/otto"
-)
-
-// https://lxr.mozilla.org/seamonkey/source/netwerk/base/src/nsProxyAutoConfig.js
-var javascriptUtils string = `
+// PAC helper functions implemented in JavaScript.
+// Adopted from Mozilla's nsProxyAutoConfig.js (as previously vendored
+// with github.com/jackwakefield/gopac).
+const pacUtils = `
  var wdays = {SUN: 0, MON: 1, TUE: 2, WED: 3, THU: 4, FRI: 5, SAT: 6};
  var months = {JAN: 0, FEB: 1, MAR: 2, APR: 3, MAY: 4, JUN: 5, JUL: 6, AUG: 7, SEP: 8, OCT: 9, NOV: 10, DEC: 11}
  function weekdayRange() {
@@ -178,103 +160,3 @@ var javascriptUtils string = `
      }
      return ((date1 <= date) && (date <= date2));
 }`
-
-// isPlainHostName return true if there is no domain name in the host.
-func isPlainHostName(host string) bool {
-	return strings.Index(host, ".") == -1
-}
-
-// dnsDomainIs return true if the host is valid for the domain.
-func dnsDomainIs(host, domain string) bool {
-	if len(host) < len(domain) {
-		return false
-	}
-
-	return strings.HasSuffix(host, domain)
-}
-
-// localHostOrDomainIs returns true if the host matches the specified hostdom,
-// or if there is no domain name part in the host, but the unqualified hostdom
-// matches.
-func localHostOrDomainIs(host, hostdom string) bool {
-	if host == hostdom {
-		return true
-	}
-
-	return strings.LastIndex(hostdom, host+".") == 0
-}
-
-// isResolvable returns true if the host is resolvable.
-func isResolvable(host string) bool {
-	if len(host) == 0 {
-		return false
-	}
-
-	if _, err := net.ResolveIPAddr("ip4", host); err != nil {
-		return false
-	}
-
-	return true
-}
-
-// isInNet returns true if the IP address of the host matches the specified IP
-// address pattern.
-// mask is the pattern informing which parts of the IP address to match against.
-// 0 means ignore, 255 means match.
-func isInNet(host, pattern, mask string) bool {
-	if len(host) == 0 {
-		return false
-	}
-
-	address, err := net.ResolveIPAddr("ip4", host)
-
-	if err != nil {
-		return false
-	}
-
-	maskIp := net.IPMask(net.ParseIP(mask))
-	return address.IP.Mask(maskIp).String() == pattern
-}
-
-// dnsResolve returns the IP address of the host.
-func dnsResolve(host string) string {
-	address, err := net.ResolveIPAddr("ip4", host)
-
-	if err != nil {
-		return ""
-	}
-
-	return address.String()
-}
-
-// myIpAddress returns the IP address of the host machine.
-func myIpAddress() otto.Value {
-	hostname, err := os.Hostname()
-
-	if err != nil {
-		return otto.UndefinedValue()
-	}
-
-	address := dnsResolve(hostname)
-
-	if value, err := otto.ToValue(address); err == nil {
-		return value
-	}
-
-	return otto.UndefinedValue()
-}
-
-// dnsDomainLevels returns the number of domain levels in the host.
-func dnsDomainLevels(host string) int {
-	return strings.Count(host, ".")
-}
-
-// shExpMatch returns true if the string matches the specified shell expression.
-func shExpMatch(str, shexp string) bool {
-	shexp = strings.Replace(shexp, ".", "\\.", -1)
-	shexp = strings.Replace(shexp, "?", ".?", -1)
-	shexp = strings.Replace(shexp, "*", ".*", -1)
-	matched, err := regexp.MatchString("^"+shexp+"$", str)
-
-	return err == nil && matched
-}
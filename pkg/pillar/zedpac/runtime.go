@@ -0,0 +1,394 @@
+// Copyright (c) 2022 Zededa, Inc.
+// SPDX-License-Identifier: Apache-2.0
+
+package zedpac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lf-edge/eve/pkg/pillar/types"
+	"github.com/robertkrimen/otto"
+)
+
+// Port on which DNS servers are contacted. Changed by unit tests.
+var dnsPort = "53"
+
+// Timeout applied to a single DNS query if the evaluation deadline
+// leaves more time than that.
+const dnsQueryTimeout = 5 * time.Second
+
+// errHalt is used to interrupt evaluation of a script which runs for too long.
+var errHalt = errors.New("PAC script execution timed out")
+
+// PortInfo : network configuration of the port for which a PAC script
+// is evaluated. DNS helper functions (dnsResolve, isResolvable, isInNet)
+// query DNS servers of the port from the port's own addresses and myIpAddress
+// returns an address of the port.
+type PortInfo struct {
+	IfName string
+	// Unicast addresses assigned to the port.
+	Addrs []net.IP
+	// DNS servers of the port. If empty, the system resolver is used.
+	DNSServers []net.IP
+}
+
+// NewPortInfo returns PortInfo for the given port.
+func NewPortInfo(port types.NetworkPortStatus) PortInfo {
+	info := PortInfo{
+		IfName:     port.IfName,
+		DNSServers: port.DNSServers,
+	}
+	for _, addrInfo := range port.AddrInfoList {
+		if addrInfo.Addr.IsLinkLocalUnicast() {
+			continue
+		}
+		info.Addrs = append(info.Addrs, addrInfo.Addr)
+	}
+	return info
+}
+
+// Equal compares two instances of PortInfo.
+func (p PortInfo) Equal(p2 PortInfo) bool {
+	return p.IfName == p2.IfName &&
+		equalIPs(p.Addrs, p2.Addrs) &&
+		equalIPs(p.DNSServers, p2.DNSServers)
+}
+
+func equalIPs(ips1, ips2 []net.IP) bool {
+	if len(ips1) != len(ips2) {
+		return false
+	}
+	for i := range ips1 {
+		if !ips1[i].Equal(ips2[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sourceAddr returns address of the port to use when talking to the given
+// remote IP. Returns nil if the port has no address of the same IP version.
+func (p PortInfo) sourceAddr(remote net.IP) net.IP {
+	remoteIsV4 := remote.To4() != nil
+	for _, addr := range p.Addrs {
+		if (addr.To4() != nil) == remoteIsV4 {
+			return addr
+		}
+	}
+	return nil
+}
+
+// runtime : JavaScript VM with a PAC script loaded and helper functions
+// bound to a network port.
+// It is not thread-safe.
+type runtime struct {
+	vm   *otto.Otto
+	port PortInfo
+	// Deadline of the evaluation in progress.
+	// DNS queries made by helper functions do not extend beyond it.
+	deadline time.Time
+	// Set when the evaluation was interrupted. The state of the VM is then
+	// undefined and the runtime should not be used anymore.
+	halted bool
+}
+
+func newRuntime(port PortInfo) (*runtime, error) {
+	rt := &runtime{
+		vm:   otto.New(),
+		port: port,
+	}
+	helpers := map[string]func(otto.FunctionCall) otto.Value{
+		"isPlainHostName":     rt.isPlainHostName,
+		"dnsDomainIs":         rt.dnsDomainIs,
+		"localHostOrDomainIs": rt.localHostOrDomainIs,
+		"isResolvable":        rt.isResolvable,
+		"isInNet":             rt.isInNet,
+		"dnsResolve":          rt.dnsResolve,
+		"myIpAddress":         rt.myIpAddress,
+		"dnsDomainLevels":     rt.dnsDomainLevels,
+		"shExpMatch":          rt.shExpMatch,
+	}
+	for name, helper := range helpers {
+		if err := rt.vm.Set(name, helper); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := rt.vm.Run(pacUtils); err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+// load runs the top-level code of a PAC script.
+func (rt *runtime) load(script interface{}, timeout time.Duration) error {
+	_, err := rt.runWithTimeout(timeout, func() (otto.Value, error) {
+		return rt.vm.Run(script)
+	})
+	return err
+}
+
+// findProxyForURL calls FindProxyForURL defined by the loaded PAC script.
+func (rt *runtime) findProxyForURL(url, host string,
+	timeout time.Duration) (string, error) {
+	value, err := rt.runWithTimeout(timeout, func() (otto.Value, error) {
+		return rt.vm.Call("FindProxyForURL", nil, url, host)
+	})
+	if err != nil {
+		return "", err
+	}
+	return value.ToString()
+}
+
+// runWithTimeout interrupts the VM if run does not finish within the timeout.
+func (rt *runtime) runWithTimeout(timeout time.Duration,
+	run func() (otto.Value, error)) (value otto.Value, err error) {
+	if rt.halted {
+		return value, errHalt
+	}
+	rt.deadline = time.Now().Add(timeout)
+	defer func() {
+		if caught := recover(); caught != nil {
+			if caught == errHalt {
+				rt.halted = true
+				err = fmt.Errorf("%v (after %v)", errHalt, timeout)
+				return
+			}
+			panic(caught)
+		}
+	}()
+	// The buffer prevents blocking of the timer.
+	rt.vm.Interrupt = make(chan func(), 1)
+	interrupt := rt.vm.Interrupt
+	timer := time.AfterFunc(timeout, func() {
+		interrupt <- func() {
+			panic(errHalt)
+		}
+	})
+	defer timer.Stop()
+	return run()
+}
+
+// lookupIPv4 resolves the host to an IPv4 address using DNS servers
+// of the port. Returns nil if the host cannot be resolved.
+func (rt *runtime) lookupIPv4(host string) net.IP {
+	if host == "" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.To4()
+	}
+	deadline := rt.deadline
+	if maxDeadline := time.Now().Add(dnsQueryTimeout); deadline.IsZero() ||
+		deadline.After(maxDeadline) {
+		deadline = maxDeadline
+	}
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	if len(rt.port.DNSServers) == 0 {
+		return firstIPv4(net.DefaultResolver.LookupIP(ctx, "ip4", host))
+	}
+	for _, server := range rt.port.DNSServers {
+		if ctx.Err() != nil {
+			break
+		}
+		resolver := rt.portResolver(server)
+		if ip := firstIPv4(resolver.LookupIP(ctx, "ip4", host)); ip != nil {
+			return ip
+		}
+	}
+	return nil
+}
+
+// portResolver returns resolver which sends all queries to the given server
+// from an address of the port.
+func (rt *runtime) portResolver(server net.IP) *net.Resolver {
+	serverAddr := net.JoinHostPort(server.String(), dnsPort)
+	srcIP := rt.port.sourceAddr(server)
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			dialer := net.Dialer{}
+			if srcIP != nil {
+				switch network {
+				case "udp", "udp4", "udp6":
+					dialer.LocalAddr = &net.UDPAddr{IP: srcIP}
+				case "tcp", "tcp4", "tcp6":
+					dialer.LocalAddr = &net.TCPAddr{IP: srcIP}
+				}
+			}
+			return dialer.DialContext(ctx, network, serverAddr)
+		},
+	}
+}
+
+func firstIPv4(ips []net.IP, err error) net.IP {
+	if err != nil {
+		return nil
+	}
+	for _, ip := range ips {
+		if ip4 := ip.To4(); ip4 != nil {
+			return ip4
+		}
+	}
+	return nil
+}
+
+// myIPv4 returns IPv4 address of the port.
+// Without port addresses, the device hostname is resolved instead.
+func (rt *runtime) myIPv4() net.IP {
+	for _, addr := range rt.port.Addrs {
+		if ip4 := addr.To4(); ip4 != nil {
+			return ip4
+		}
+	}
+	if len(rt.port.Addrs) == 0 {
+		if hostname, err := os.Hostname(); err == nil {
+			if ip := rt.lookupIPv4(hostname); ip != nil {
+				return ip
+			}
+		}
+	}
+	return net.IPv4(127, 0, 0, 1).To4()
+}
+
+func (rt *runtime) toValue(value interface{}) otto.Value {
+	if v, err := rt.vm.ToValue(value); err == nil {
+		return v
+	}
+	return otto.UndefinedValue()
+}
+
+// stringArgs returns the first n arguments of the call converted to strings.
+func stringArgs(call otto.FunctionCall, n int) (args []string, ok bool) {
+	for i := 0; i < n; i++ {
+		arg, err := call.Argument(i).ToString()
+		if err != nil {
+			return nil, false
+		}
+		args = append(args, arg)
+	}
+	return args, true
+}
+
+// isPlainHostName returns true if there is no domain name in the host.
+func (rt *runtime) isPlainHostName(call otto.FunctionCall) otto.Value {
+	args, ok := stringArgs(call, 1)
+	if !ok {
+		return otto.UndefinedValue()
+	}
+	return rt.toValue(!strings.Contains(args[0], "."))
+}
+
+// dnsDomainIs returns true if the host belongs to the domain.
+func (rt *runtime) dnsDomainIs(call otto.FunctionCall) otto.Value {
+	args, ok := stringArgs(call, 2)
+	if !ok {
+		return otto.UndefinedValue()
+	}
+	return rt.toValue(strings.HasSuffix(args[0], args[1]))
+}
+
+// localHostOrDomainIs returns true if the host matches hostdom exactly,
+// or if the host has no domain name part and matches the unqualified hostdom.
+func (rt *runtime) localHostOrDomainIs(call otto.FunctionCall) otto.Value {
+	args, ok := stringArgs(call, 2)
+	if !ok {
+		return otto.UndefinedValue()
+	}
+	host, hostdom := args[0], args[1]
+	return rt.toValue(host == hostdom || strings.HasPrefix(hostdom, host+"."))
+}
+
+// isResolvable returns true if the host can be resolved using DNS servers
+// of the port.
+func (rt *runtime) isResolvable(call otto.FunctionCall) otto.Value {
+	args, ok := stringArgs(call, 1)
+	if !ok {
+		return otto.UndefinedValue()
+	}
+	return rt.toValue(rt.lookupIPv4(args[0]) != nil)
+}
+
+// isInNet returns true if the IP address of the host (resolved using DNS
+// servers of the port) matches the pattern under the mask.
+func (rt *runtime) isInNet(call otto.FunctionCall) otto.Value {
+	args, ok := stringArgs(call, 3)
+	if !ok {
+		return otto.UndefinedValue()
+	}
+	pattern := net.ParseIP(args[1]).To4()
+	mask := net.ParseIP(args[2]).To4()
+	ip := rt.lookupIPv4(args[0])
+	if ip == nil || pattern == nil || mask == nil {
+		return rt.toValue(false)
+	}
+	return rt.toValue(ip.Mask(net.IPMask(mask)).Equal(pattern))
+}
+
+// dnsResolve returns the IPv4 address of the host resolved using DNS servers
+// of the port, or an empty string if the host cannot be resolved.
+func (rt *runtime) dnsResolve(call otto.FunctionCall) otto.Value {
+	args, ok := stringArgs(call, 1)
+	if !ok {
+		return otto.UndefinedValue()
+	}
+	ip := rt.lookupIPv4(args[0])
+	if ip == nil {
+		return rt.toValue("")
+	}
+	return rt.toValue(ip.String())
+}
+
+// myIpAddress returns the IPv4 address of the port.
+func (rt *runtime) myIpAddress(call otto.FunctionCall) otto.Value {
+	return rt.toValue(rt.myIPv4().String())
+}
+
+// dnsDomainLevels returns the number of domain levels in the host.
+func (rt *runtime) dnsDomainLevels(call otto.FunctionCall) otto.Value {
+	args, ok := stringArgs(call, 1)
+	if !ok {
+		return otto.UndefinedValue()
+	}
+	return rt.toValue(strings.Count(args[0], "."))
+}
+
+// shExpMatch returns true if the string matches the shell expression.
+func (rt *runtime) shExpMatch(call otto.FunctionCall) otto.Value {
+	args, ok := stringArgs(call, 2)
+	if !ok {
+		return otto.UndefinedValue()
+	}
+	matched, err := regexp.MatchString(shExpToRegexp(args[1]), args[0])
+	return rt.toValue(err == nil && matched)
+}
+
+// shExpToRegexp converts shell expression into regular expression.
+// Besides the wildcards "*" and "?", alternatives "(a|b)" are supported,
+// like in the original Netscape implementation. All other characters
+// are matched literally.
+func shExpToRegexp(shexp string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, c := range shexp {
+		switch c {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		case '(', '|', ')':
+			sb.WriteRune(c)
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
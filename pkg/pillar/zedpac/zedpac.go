@@ -5,15 +5,19 @@ package zedpac
 
 import (
 	"fmt"
-
-	"github.com/jackwakefield/gopac"
 )
 
+// Find_proxy_sync evaluates the PAC script without caching and with helper
+// functions using the system resolver. Use Engine to evaluate PAC scripts
+// for a device port.
 func Find_proxy_sync(pac, url, host string) (string, error) {
-	parser := new(gopac.Parser)
-	if err := parser.ParseBytes([]byte(pac)); err != nil {
+	rt, err := newRuntime(PortInfo{})
+	if err != nil {
+		return "", err
+	}
+	if err := rt.load(pac, DefaultTimeout); err != nil {
 		return "", fmt.Errorf("invalid proxy auto-configuration file: %v", err)
 	}
 
-	return parser.FindProxy(url, host)
+	return rt.findProxyForURL(url, host, DefaultTimeout)
 }
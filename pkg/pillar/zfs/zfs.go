@@ -69,10 +69,10 @@ func GetZfsStatusStr(log *base.LogObject, pool string) string {
 	return strings.Join(status, " ")
 }
 
-//DestroyDataset removes dataset from zfs
+//DestroyDataset removes dataset from zfs along with its snapshots
 //it runs 3 times in case of errors (we can hit dataset is busy)
 func DestroyDataset(log *base.LogObject, dataset string) (string, error) {
-	args := []string{"destroy", dataset}
+	args := []string{"destroy", "-r", dataset}
 	var err error
 	var stdoutStderr []byte
 	tries := 0
@@ -125,11 +125,10 @@ func GetDatasetOption(log *base.LogObject, dataset string, option string) (strin
 	return strings.TrimSpace(string(stdoutStderr)), nil
 }
 
-//CreateVolumeDataset creates dataset of zvol type in zfs
-func CreateVolumeDataset(log *base.LogObject, dataset string, size uint64, compression string) (string, error) {
+func getCreateVolumeDatasetParams(dataset string, size uint64, compression string) []string {
 	alignedSize := alignUpToBlockSize(size)
 
-	args := []string{"create", "-p",
+	return []string{"create", "-p",
 		"-V", strconv.FormatUint(alignedSize, 10),
 		"-o", "volmode=dev",
 		"-o", fmt.Sprintf("compression=%s", compression),
@@ -137,7 +136,53 @@ func CreateVolumeDataset(log *base.LogObject, dataset string, size uint64, compr
 		"-o", "logbias=throughput",
 		"-o", "redundant_metadata=most",
 		dataset}
+}
+
+//CreateVolumeDataset creates dataset of zvol type in zfs
+func CreateVolumeDataset(log *base.LogObject, dataset string, size uint64, compression string) (string, error) {
+	args := getCreateVolumeDatasetParams(dataset, size, compression)
+
+	stdoutStderr, err := base.Exec(log, types.ZFSBinary, args...).CombinedOutput()
+	if err != nil {
+		return string(stdoutStderr), err
+	}
+	return string(stdoutStderr), nil
+}
+
+//CreateEncryptedVolumeDataset creates dataset of zvol type in zfs, which is
+//encryption root with its own raw key read from keyFile. The key stays
+//loaded until reboot, after that use LoadDatasetKey with the same key
+func CreateEncryptedVolumeDataset(log *base.LogObject, dataset string, size uint64, compression string, keyFile string) (string, error) {
+	args := getCreateVolumeDatasetParams(dataset, size, compression)
+	args = append(args[:len(args)-1],
+		"-o", "encryption=aes-256-gcm",
+		"-o", "keyformat=raw",
+		"-o", "keylocation=file://"+keyFile,
+		dataset)
 
+	stdoutStderr, err := base.Exec(log, types.ZFSBinary, args...).CombinedOutput()
+	if err != nil {
+		return string(stdoutStderr), err
+	}
+	//keyFile is temporary, do not let zfs look for it
+	args = []string{"set", "keylocation=prompt", dataset}
+	stdoutStderr, err = base.Exec(log, types.ZFSBinary, args...).CombinedOutput()
+	if err != nil {
+		return string(stdoutStderr), err
+	}
+	return string(stdoutStderr), nil
+}
+
+//LoadDatasetKey loads the key of dataset from keyFile, if not yet loaded
+func LoadDatasetKey(log *base.LogObject, dataset string, keyFile string) (string, error) {
+	keyStatus, err := GetDatasetOption(log, dataset, "keystatus")
+	if err != nil {
+		return keyStatus, err
+	}
+	if keyStatus == "available" {
+		return "", nil
+	}
+	args := []string{"load-key", "-L", "file://" + keyFile, dataset}
 	stdoutStderr, err := base.Exec(log, types.ZFSBinary, args...).CombinedOutput()
 	if err != nil {
 		return string(stdoutStderr), err
@@ -166,6 +211,35 @@ func GetVolumesInDataset(log *base.LogObject, dataset string) ([]string, error)
 	return lines, nil
 }
 
+//CreateSnapshot creates snapshot in the format dataset@name
+func CreateSnapshot(log *base.LogObject, snapshot string) (string, error) {
+	args := []string{"snapshot", snapshot}
+	stdoutStderr, err := base.Exec(log, types.ZFSBinary, args...).CombinedOutput()
+	return string(stdoutStderr), err
+}
+
+//GetSnapshots obtains snapshots list of dataset
+func GetSnapshots(log *base.LogObject, dataset string) ([]string, error) {
+	args := []string{"list", "-H",
+		"-o", "name",
+		"-t", "snapshot",
+		"-d", "1",
+		dataset}
+	stdoutStderr, err := base.Exec(log, types.ZFSBinary, args...).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("GetSnapshots: output=%s error=%s", stdoutStderr, err)
+	}
+	var lines []string
+	sc := bufio.NewScanner(bytes.NewReader(stdoutStderr))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
 //GetDatasetByDevice returns dataset for provided device path
 func GetDatasetByDevice(device string) string {
 	if !strings.HasPrefix(device, types.ZVolDevicePrefix) {
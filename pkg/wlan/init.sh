@@ -26,5 +26,9 @@ while true ; do
         killall -s HUP $wpaproc
       fi
     fi
+  elif [ -n "$(pgrep -x "wpa_supplicant")" ]; then
+    # Configuration was removed (e.g. wlan0 operates as an access point).
+    killall $wpaproc
+    filetime=0
   fi
 done